@@ -52,6 +52,8 @@ type (
 			NumEpisodesWatched int             `json:"num_episodes_watched"`
 			Score              int             `json:"score"`
 			UpdatedAt          string          `json:"updated_at"`
+			StartDate          string          `json:"start_date,omitempty"`
+			FinishDate         string          `json:"finish_date,omitempty"`
 		} `json:"list_status"`
 	}
 )
@@ -83,19 +85,26 @@ func (w *Wrapper) GetAnimeCollection() ([]*AnimeListEntry, error) {
 	reqUrl := fmt.Sprintf("%s/users/@me/animelist?fields=list_status&limit=1000", ApiBaseURL)
 
 	type response struct {
-		Data []*AnimeListEntry `json:"data"`
+		Data   []*AnimeListEntry `json:"data"`
+		Paging Paging            `json:"paging"`
 	}
 
-	var data response
-	err := w.doQuery("GET", reqUrl, nil, "application/json", &data)
-	if err != nil {
-		w.logger.Error().Err(err).Msg("mal: Failed to get anime collection")
-		return nil, err
+	var ret []*AnimeListEntry
+	// Follow the pages until the whole list is fetched
+	for reqUrl != "" {
+		var data response
+		err := w.doQuery("GET", reqUrl, nil, "application/json", &data)
+		if err != nil {
+			w.logger.Error().Err(err).Msg("mal: Failed to get anime collection")
+			return nil, err
+		}
+		ret = append(ret, data.Data...)
+		reqUrl = data.Paging.Next
 	}
 
-	w.logger.Info().Msg("mal: Fetched anime collection")
+	w.logger.Info().Int("count", len(ret)).Msg("mal: Fetched anime collection")
 
-	return data.Data, nil
+	return ret, nil
 }
 
 type AnimeListProgressParams struct {
@@ -138,6 +147,10 @@ type AnimeListStatusParams struct {
 	IsRewatching       *bool
 	NumEpisodesWatched *int
 	Score              *int
+	NumTimesRewatched  *int
+	// Dates are formatted as YYYY-MM-DD
+	StartDate  *string
+	FinishDate *string
 }
 
 func (w *Wrapper) UpdateAnimeListStatus(opts *AnimeListStatusParams, mId int) error {
@@ -159,6 +172,15 @@ func (w *Wrapper) UpdateAnimeListStatus(opts *AnimeListStatusParams, mId int) er
 	if opts.Score != nil {
 		urlData.Set("score", fmt.Sprintf("%d", *opts.Score))
 	}
+	if opts.NumTimesRewatched != nil {
+		urlData.Set("num_times_rewatched", fmt.Sprintf("%d", *opts.NumTimesRewatched))
+	}
+	if opts.StartDate != nil {
+		urlData.Set("start_date", *opts.StartDate)
+	}
+	if opts.FinishDate != nil {
+		urlData.Set("finish_date", *opts.FinishDate)
+	}
 	encodedData := urlData.Encode()
 
 	err := w.doMutation("PATCH", reqUrl, encodedData)
@@ -51,6 +51,8 @@ type (
 			NumChaptersRead int             `json:"num_chapters_read"`
 			Score           int             `json:"score"`
 			UpdatedAt       string          `json:"updated_at"`
+			StartDate       string          `json:"start_date,omitempty"`
+			FinishDate      string          `json:"finish_date,omitempty"`
 		} `json:"list_status"`
 	}
 )
@@ -82,19 +84,26 @@ func (w *Wrapper) GetMangaCollection() ([]*MangaListEntry, error) {
 	reqUrl := fmt.Sprintf("%s/users/@me/mangalist?fields=list_status&limit=1000", ApiBaseURL)
 
 	type response struct {
-		Data []*MangaListEntry `json:"data"`
+		Data   []*MangaListEntry `json:"data"`
+		Paging Paging            `json:"paging"`
 	}
 
-	var data response
-	err := w.doQuery("GET", reqUrl, nil, "application/json", &data)
-	if err != nil {
-		w.logger.Error().Err(err).Msg("mal: Failed to get manga collection")
-		return nil, err
+	var ret []*MangaListEntry
+	// Follow the pages until the whole list is fetched
+	for reqUrl != "" {
+		var data response
+		err := w.doQuery("GET", reqUrl, nil, "application/json", &data)
+		if err != nil {
+			w.logger.Error().Err(err).Msg("mal: Failed to get manga collection")
+			return nil, err
+		}
+		ret = append(ret, data.Data...)
+		reqUrl = data.Paging.Next
 	}
 
-	w.logger.Info().Msg("mal: Fetched manga collection")
+	w.logger.Info().Int("count", len(ret)).Msg("mal: Fetched manga collection")
 
-	return data.Data, nil
+	return ret, nil
 }
 
 type MangaListProgressParams struct {
@@ -137,6 +146,10 @@ type MangaListStatusParams struct {
 	IsRereading     *bool
 	NumChaptersRead *int
 	Score           *int
+	NumTimesReread  *int
+	// Dates are formatted as YYYY-MM-DD
+	StartDate  *string
+	FinishDate *string
 }
 
 func (w *Wrapper) UpdateMangaListStatus(opts *MangaListStatusParams, mId int) error {
@@ -158,6 +171,15 @@ func (w *Wrapper) UpdateMangaListStatus(opts *MangaListStatusParams, mId int) er
 	if opts.Score != nil {
 		urlData.Set("score", fmt.Sprintf("%d", *opts.Score))
 	}
+	if opts.NumTimesReread != nil {
+		urlData.Set("num_times_reread", fmt.Sprintf("%d", *opts.NumTimesReread))
+	}
+	if opts.StartDate != nil {
+		urlData.Set("start_date", *opts.StartDate)
+	}
+	if opts.FinishDate != nil {
+		urlData.Set("finish_date", *opts.FinishDate)
+	}
 	encodedData := urlData.Encode()
 
 	err := w.doMutation("PATCH", reqUrl, encodedData)
@@ -9,6 +9,12 @@ type (
 		ExpiresAt    time.Time
 	}
 
+	// Paging links to the previous and next pages of a list, empty if there are none
+	Paging struct {
+		Previous string `json:"previous,omitempty"`
+		Next     string `json:"next,omitempty"`
+	}
+
 	MediaType       string
 	MediaStatus     string
 	MediaListStatus string
@@ -142,10 +142,11 @@ func VerifyMALAuth(malInfo *models.Mal, db *db.Database, logger *zerolog.Logger)
 			ID:        1,
 			UpdatedAt: time.Now(),
 		},
-		Username:       "",
-		AccessToken:    ret.AccessToken,
-		RefreshToken:   ret.RefreshToken,
-		TokenExpiresAt: time.Now().Add(time.Duration(ret.ExpiresIn) * time.Second),
+		Username:          "",
+		AccessToken:       ret.AccessToken,
+		RefreshToken:      ret.RefreshToken,
+		TokenExpiresAt:    time.Now().Add(time.Duration(ret.ExpiresIn) * time.Second),
+		IsAccountPlatform: malInfo.IsAccountPlatform,
 	}
 
 	_, err = db.UpsertMalInfo(&updatedMalInfo)
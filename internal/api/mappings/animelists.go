@@ -1,9 +1,11 @@
 package mappings
 
 import (
+	"fmt"
 	"github.com/goccy/go-json"
 	"net/http"
 	"seanime/internal/util"
+	"time"
 )
 
 type (
@@ -92,3 +94,85 @@ func (i *ReducedAnimeListItem) GetTvdbID() (int, bool) {
 		return 0, false
 	}
 }
+
+type (
+
+	// AnimeListResponse is the response from the full anime list API.
+	// It is used to find the IDs of an AniList anime on other databases.
+	AnimeListResponse struct {
+		itemsByAnilistID map[int]*AnimeListItem
+		itemsByMalID     map[int]*AnimeListItem
+		Count            int
+	}
+	AnimeListItem struct {
+		AnilistID int `json:"anilist_id,omitempty"`
+		MalID     int `json:"mal_id,omitempty"`
+		KitsuID   int `json:"kitsu_id,omitempty"`
+		SimklID   int `json:"simkl_id,omitempty"`
+	}
+)
+
+func GetAnimeLists() (resp *AnimeListResponse, err error) {
+	// The full list is a large file, give it more time than the usual API requests
+	client := http.Client{Timeout: 2 * time.Minute}
+
+	req, err := http.NewRequest("GET", "https://raw.githubusercontent.com/Fribb/anime-lists/master/anime-list-full.json", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, fmt.Errorf("request failed with status code: %d", res.StatusCode)
+	}
+
+	var items []*AnimeListItem
+	if err := json.NewDecoder(res.Body).Decode(&items); err != nil {
+		return nil, err
+	}
+
+	itemsByAnilistID := make(map[int]*AnimeListItem)
+	itemsByMalID := make(map[int]*AnimeListItem)
+	for _, item := range items {
+		if item.AnilistID == 0 {
+			continue
+		}
+		itemsByAnilistID[item.AnilistID] = item
+		if item.MalID != 0 {
+			itemsByMalID[item.MalID] = item
+		}
+	}
+
+	return &AnimeListResponse{
+		itemsByAnilistID: itemsByAnilistID,
+		itemsByMalID:     itemsByMalID,
+		Count:            len(items),
+	}, nil
+}
+
+// FindFromAnilistID will return the IDs of the anime with the given AniList ID.
+// If the AniList ID is not found, the second return value will be false.
+func (i *AnimeListResponse) FindFromAnilistID(anilistID int) (*AnimeListItem, bool) {
+	if i == nil {
+		return nil, false
+	}
+
+	item, ok := i.itemsByAnilistID[anilistID]
+	return item, ok
+}
+
+// FindFromMalID will return the IDs of the anime with the given MyAnimeList ID.
+// Only anime that have an AniList ID are indexed.
+func (i *AnimeListResponse) FindFromMalID(malID int) (*AnimeListItem, bool) {
+	if i == nil {
+		return nil, false
+	}
+
+	item, ok := i.itemsByMalID[malID]
+	return item, ok
+}
@@ -44,3 +44,22 @@ func TestGetReducedAnimeLists(t *testing.T) {
 	}
 
 }
+
+func TestGetAnimeLists(t *testing.T) {
+
+	res, err := GetAnimeLists()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("Anime list count: %d", res.Count)
+
+	// Sousou no Frieren
+	item, ok := res.FindFromAnilistID(154587)
+	if !ok {
+		t.Fatalf("anime not found")
+	}
+
+	assert.Equal(t, 52991, item.MalID, "MAL ID should match expected value")
+	assert.NotZero(t, item.KitsuID, "Kitsu ID should be set")
+}
@@ -137,6 +137,8 @@ func (m *Manager) UpdateWatchHistoryItem(opts *UpdateWatchHistoryItemOptions) (e
 		WatchHistoryItem: i,
 	})
 
+	m.notifyWatchHistoryItemUpdated(i)
+
 	// If the item was added, check if we need to remove the oldest item
 	if added {
 		_ = m.trimWatchHistoryItems()
@@ -345,7 +347,9 @@ func (m *Manager) UpdateExternalPlayerEpisodeWatchHistoryItem(currentTime, durat
 	}
 
 	// Save the i
-	_ = m.fileCacher.Set(*m.watchHistoryFileCacheBucket, strconv.Itoa(opts.MediaId), i)
+	if err := m.fileCacher.Set(*m.watchHistoryFileCacheBucket, strconv.Itoa(opts.MediaId), i); err == nil {
+		m.notifyWatchHistoryItemUpdated(i)
+	}
 
 	// If the item was added, check if we need to remove the oldest item
 	if added {
@@ -404,8 +408,8 @@ func (m *Manager) trimWatchHistoryItems() error {
 		return fmt.Errorf("continuity: Failed to get watch history items: %w", err)
 	}
 
-	// If there are too many items, remove the oldest one
-	if len(items) > MaxWatchHistoryItems {
+	// If there are too many items, remove the oldest ones
+	for len(items) > MaxWatchHistoryItems {
 		var oldestKey string
 		for key := range items {
 			if oldestKey == "" || items[key].TimeUpdated.Before(items[oldestKey].TimeUpdated) {
@@ -416,6 +420,7 @@ func (m *Manager) trimWatchHistoryItems() error {
 		if err != nil {
 			return fmt.Errorf("continuity: Failed to remove oldest watch history item: %w", err)
 		}
+		delete(items, oldestKey)
 	}
 
 	return nil
@@ -4,6 +4,7 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/samber/mo"
 	"seanime/internal/database/db"
+	"seanime/internal/database/models"
 	"seanime/internal/util/filecache"
 	"sync"
 	"time"
@@ -21,9 +22,18 @@ type (
 		fileCacher                  *filecache.Cacher
 		db                          *db.Database
 		watchHistoryFileCacheBucket *filecache.Bucket
+		// ID of the profile whose watch history and watch events are used
+		profileId uint
 
 		externalPlayerEpisodeDetails mo.Option[*ExternalPlayerEpisodeDetails]
 
+		// Called when the playback of this instance updates a watch history item
+		onWatchHistoryItemUpdated func(item *WatchHistoryItem)
+
+		// Watch events being recorded, keyed by player, media and episode
+		activeWatchEvents map[string]*activeWatchEvent
+		watchEventsMu     sync.Mutex
+
 		logger   *zerolog.Logger
 		settings *Settings
 		mu       sync.RWMutex
@@ -61,10 +71,12 @@ func NewManager(opts *NewManagerOptions) *Manager {
 		logger:                      opts.Logger,
 		db:                          opts.Database,
 		watchHistoryFileCacheBucket: &watchHistoryFileCacheBucket,
+		profileId:                   models.DefaultProfileID,
 		settings: &Settings{
 			WatchContinuityEnabled: false,
 		},
 		externalPlayerEpisodeDetails: mo.None[*ExternalPlayerEpisodeDetails](),
+		activeWatchEvents:            make(map[string]*activeWatchEvent),
 	}
 
 	ret.logger.Info().Msg("continuity: Initialized manager")
@@ -0,0 +1,50 @@
+package continuity
+
+import (
+	"fmt"
+	"seanime/internal/database/models"
+	"seanime/internal/util/filecache"
+	"time"
+
+	"github.com/samber/mo"
+)
+
+// watchHistoryBucketName returns the name of the bucket holding the watch history of the profile.
+func watchHistoryBucketName(profileId uint) string {
+	if profileId == models.DefaultProfileID {
+		return WatchHistoryBucketName
+	}
+	return fmt.Sprintf("%s_%d", WatchHistoryBucketName, profileId)
+}
+
+// NewProfileManager returns a Manager using the watch history and watch events of the profile.
+// The settings are copied from this Manager and should be set from the playback preferences of the profile.
+func (m *Manager) NewProfileManager(profileId uint) *Manager {
+	if profileId == 0 || profileId == models.DefaultProfileID {
+		return m
+	}
+
+	watchHistoryFileCacheBucket := filecache.NewBucket(watchHistoryBucketName(profileId), time.Hour*24*99999)
+
+	settings := *m.GetSettings()
+
+	return &Manager{
+		fileCacher:                   m.fileCacher,
+		logger:                       m.logger,
+		db:                           m.db,
+		watchHistoryFileCacheBucket:  &watchHistoryFileCacheBucket,
+		profileId:                    profileId,
+		settings:                     &settings,
+		externalPlayerEpisodeDetails: mo.None[*ExternalPlayerEpisodeDetails](),
+		activeWatchEvents:            make(map[string]*activeWatchEvent),
+	}
+}
+
+// DeleteProfileData removes the watch history of a profile.
+// The watch events are deleted with the profile.
+func (m *Manager) DeleteProfileData(profileId uint) error {
+	if profileId == 0 || profileId == models.DefaultProfileID {
+		return nil
+	}
+	return m.fileCacher.Remove(watchHistoryBucketName(profileId))
+}
@@ -0,0 +1,135 @@
+package continuity
+
+import (
+	"errors"
+	"fmt"
+	"seanime/internal/util"
+	"seanime/internal/util/filecache"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// WatchHistoryExportVersion is incremented when the export format changes.
+const WatchHistoryExportVersion = 1
+
+type (
+	// WatchHistoryExport is the portable format of the watch history.
+	// It is used to move resume positions between Seanime instances.
+	WatchHistoryExport struct {
+		Version    int                 `json:"version"`
+		ExportedAt time.Time           `json:"exportedAt"`
+		Items      []*WatchHistoryItem `json:"items"`
+	}
+)
+
+// SetWatchHistoryItemUpdatedCallback sets the function called when the playback of this instance updates a watch history item.
+// Items merged from another instance do not trigger it.
+func (m *Manager) SetWatchHistoryItemUpdatedCallback(f func(item *WatchHistoryItem)) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onWatchHistoryItemUpdated = f
+}
+
+// GetWatchHistoryItems returns all the items of the watch history, including the ones that are not resumable anymore.
+func (m *Manager) GetWatchHistoryItems() (ret []*WatchHistoryItem, err error) {
+	defer util.HandlePanicInModuleWithError("continuity/GetWatchHistoryItems", &err)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	items, err := filecache.GetAll[*WatchHistoryItem](m.fileCacher, *m.watchHistoryFileCacheBucket)
+	if err != nil {
+		return nil, fmt.Errorf("continuity: Failed to get watch history items: %w", err)
+	}
+
+	ret = make([]*WatchHistoryItem, 0, len(items))
+	for _, item := range items {
+		if item != nil {
+			ret = append(ret, item)
+		}
+	}
+	slices.SortFunc(ret, func(a, b *WatchHistoryItem) int {
+		return b.TimeUpdated.Compare(a.TimeUpdated)
+	})
+
+	return ret, nil
+}
+
+// MergeWatchHistoryItems saves the items that are more recent than the ones in the watch history (last writer wins by TimeUpdated).
+// It returns the items that were saved.
+func (m *Manager) MergeWatchHistoryItems(items []*WatchHistoryItem) (ret []*WatchHistoryItem, err error) {
+	defer util.HandlePanicInModuleWithError("continuity/MergeWatchHistoryItems", &err)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ret = make([]*WatchHistoryItem, 0)
+	for _, item := range items {
+		if item == nil || item.MediaId == 0 || item.TimeUpdated.IsZero() {
+			continue
+		}
+
+		var current *WatchHistoryItem
+		found, _ := m.fileCacher.Get(*m.watchHistoryFileCacheBucket, strconv.Itoa(item.MediaId), &current)
+		if found && current != nil && !item.TimeUpdated.After(current.TimeUpdated) {
+			continue
+		}
+
+		err = m.fileCacher.Set(*m.watchHistoryFileCacheBucket, strconv.Itoa(item.MediaId), item)
+		if err != nil {
+			return ret, fmt.Errorf("continuity: Failed to save watch history item: %w", err)
+		}
+		ret = append(ret, item)
+	}
+
+	if len(ret) > 0 {
+		_ = m.trimWatchHistoryItems()
+		m.logger.Debug().Int("count", len(ret)).Msg("continuity: Merged watch history items")
+	}
+
+	return ret, nil
+}
+
+// ExportWatchHistory returns the watch history in the portable format.
+func (m *Manager) ExportWatchHistory() (*WatchHistoryExport, error) {
+	items, err := m.GetWatchHistoryItems()
+	if err != nil {
+		return nil, err
+	}
+
+	return &WatchHistoryExport{
+		Version:    WatchHistoryExportVersion,
+		ExportedAt: time.Now(),
+		Items:      items,
+	}, nil
+}
+
+// ImportWatchHistory merges an exported watch history and returns the number of items that were saved.
+func (m *Manager) ImportWatchHistory(export *WatchHistoryExport) (int, error) {
+	if export == nil {
+		return 0, errors.New("continuity: No watch history to import")
+	}
+	if export.Version > WatchHistoryExportVersion {
+		return 0, fmt.Errorf("continuity: Unsupported watch history export version %d", export.Version)
+	}
+
+	merged, err := m.MergeWatchHistoryItems(export.Items)
+	return len(merged), err
+}
+
+// notifyWatchHistoryItemUpdated calls the callback in a goroutine, the caller should hold the lock.
+func (m *Manager) notifyWatchHistoryItemUpdated(item *WatchHistoryItem) {
+	if m.onWatchHistoryItemUpdated == nil || item == nil {
+		return
+	}
+	f := m.onWatchHistoryItemUpdated
+	itemCopy := *item
+	go func() {
+		defer util.HandlePanicInModuleThen("continuity/notifyWatchHistoryItemUpdated", func() {})
+		f(&itemCopy)
+	}()
+}
@@ -0,0 +1,60 @@
+package continuity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeWatchHistoryItems(t *testing.T) {
+	manager := GetMockManager(t, nil)
+
+	now := time.Now()
+
+	err := manager.UpdateWatchHistoryItem(&UpdateWatchHistoryItemOptions{
+		MediaId:       1,
+		EpisodeNumber: 3,
+		CurrentTime:   100,
+		Duration:      1400,
+	})
+	require.NoError(t, err)
+
+	merged, err := manager.MergeWatchHistoryItems([]*WatchHistoryItem{
+		// Older than the local item, ignored
+		{MediaId: 1, EpisodeNumber: 2, CurrentTime: 500, Duration: 1400, TimeUpdated: now.Add(-time.Hour)},
+		// New item
+		{MediaId: 2, EpisodeNumber: 1, CurrentTime: 300, Duration: 1400, TimeUpdated: now.Add(-time.Minute)},
+		// No timestamp, ignored
+		{MediaId: 3, EpisodeNumber: 1, CurrentTime: 300, Duration: 1400},
+	})
+	require.NoError(t, err)
+	require.Len(t, merged, 1)
+	require.Equal(t, 2, merged[0].MediaId)
+
+	// More recent than the local item, replaces it
+	merged, err = manager.MergeWatchHistoryItems([]*WatchHistoryItem{
+		{MediaId: 1, EpisodeNumber: 4, CurrentTime: 200, Duration: 1400, TimeUpdated: now.Add(time.Minute)},
+	})
+	require.NoError(t, err)
+	require.Len(t, merged, 1)
+
+	items, err := manager.GetWatchHistoryItems()
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	// Most recent first
+	require.Equal(t, 1, items[0].MediaId)
+	require.Equal(t, 4, items[0].EpisodeNumber)
+
+	// Round trip through the export format
+	export, err := manager.ExportWatchHistory()
+	require.NoError(t, err)
+
+	other := GetMockManager(t, nil)
+	count, err := other.ImportWatchHistory(export)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	_, err = other.ImportWatchHistory(&WatchHistoryExport{Version: WatchHistoryExportVersion + 1})
+	require.Error(t, err)
+}
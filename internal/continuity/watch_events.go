@@ -0,0 +1,212 @@
+package continuity
+
+import (
+	"fmt"
+	"seanime/internal/database/models"
+	"seanime/internal/util"
+	"time"
+)
+
+const (
+	LocalWatchSource   WatchSource = "local"
+	TorrentWatchSource WatchSource = "torrent"
+	DebridWatchSource  WatchSource = "debrid"
+	OnlineWatchSource  WatchSource = "online"
+)
+
+const (
+	// WatchEventCompletedRatio is the completion ratio above which an episode is considered watched.
+	WatchEventCompletedRatio = 0.8
+	// An event is closed when no progress has been reported for this long.
+	watchEventIdleTimeout = 10 * time.Minute
+	// Progress updates are written to the database at most once per interval.
+	watchEventSaveInterval = 30 * time.Second
+)
+
+type (
+	// WatchSource is where the episode is played from.
+	WatchSource string
+
+	// TrackWatchEventOptions is a progress report of the player.
+	TrackWatchEventOptions struct {
+		MediaId       int         `json:"mediaId"`
+		EpisodeNumber int         `json:"episodeNumber"`
+		CurrentTime   float64     `json:"currentTime"`
+		Duration      float64     `json:"duration"`
+		Player        string      `json:"player"`
+		Source        WatchSource `json:"source"`
+		// Client playing the episode, used to tell apart the players of different clients (e.g. native player)
+		ClientId string `json:"clientId,omitempty"`
+	}
+
+	activeWatchEvent struct {
+		event        *models.WatchEvent
+		clientId     string
+		lastPosition float64
+		lastUpdate   time.Time
+		lastSave     time.Time
+	}
+)
+
+// TrackWatchEvent records the progress of the episode being played in the watch event log.
+// A new event is started when the player, client, media or episode changes, or when the previous event went idle.
+//   - Only the playback time that elapsed between two reports is counted, seeking forward does not add watched time.
+func (m *Manager) TrackWatchEvent(opts *TrackWatchEventOptions) {
+	defer util.HandlePanicInModuleThen("continuity/TrackWatchEvent", func() {})
+
+	if m == nil || m.db == nil || opts == nil || opts.MediaId == 0 {
+		return
+	}
+
+	m.watchEventsMu.Lock()
+	defer m.watchEventsMu.Unlock()
+
+	now := time.Now()
+	m.closeIdleWatchEvents(now)
+
+	key := watchEventKey(opts.Player, opts.ClientId, opts.MediaId, opts.EpisodeNumber)
+
+	a, found := m.activeWatchEvents[key]
+	if !found {
+		rewatch, err := m.db.HasCompletedWatchEvent(m.profileId, opts.MediaId, opts.EpisodeNumber)
+		if err != nil {
+			m.logger.Warn().Err(err).Msg("continuity: Failed to check previous watch events")
+		}
+		a = &activeWatchEvent{
+			event: &models.WatchEvent{
+				ProfileID:     m.profileId,
+				MediaID:       opts.MediaId,
+				EpisodeNumber: opts.EpisodeNumber,
+				StartedAt:     now,
+				EndedAt:       now,
+				Duration:      opts.Duration,
+				Player:        opts.Player,
+				Source:        string(opts.Source),
+				Rewatch:       rewatch,
+			},
+			clientId:     opts.ClientId,
+			lastPosition: opts.CurrentTime,
+			lastUpdate:   now,
+		}
+		m.activeWatchEvents[key] = a
+	} else {
+		a.event.WatchedSeconds += watchedSecondsBetween(a.lastPosition, opts.CurrentTime, now.Sub(a.lastUpdate))
+		a.event.EndedAt = now
+		if opts.Duration > 0 {
+			a.event.Duration = opts.Duration
+		}
+		a.lastPosition = opts.CurrentTime
+		a.lastUpdate = now
+	}
+
+	justCompleted := false
+	if !a.event.Completed && a.event.Duration > 0 && opts.CurrentTime/a.event.Duration >= WatchEventCompletedRatio {
+		a.event.Completed = true
+		justCompleted = true
+	}
+
+	if a.event.ID == 0 || justCompleted || now.Sub(a.lastSave) >= watchEventSaveInterval {
+		m.saveWatchEvent(a, now)
+	}
+}
+
+// EndWatchEvents closes the events being recorded for the player.
+// This should be called when the player is closed or playback stops.
+//   - clientId: Only the events of this client are closed, all the events of the player are closed if empty
+func (m *Manager) EndWatchEvents(player string, clientId string) {
+	defer util.HandlePanicInModuleThen("continuity/EndWatchEvents", func() {})
+
+	if m == nil || m.db == nil {
+		return
+	}
+
+	m.watchEventsMu.Lock()
+	defer m.watchEventsMu.Unlock()
+
+	now := time.Now()
+	for key, a := range m.activeWatchEvents {
+		if a.event.Player != player || (clientId != "" && a.clientId != clientId) {
+			continue
+		}
+		m.saveWatchEvent(a, now)
+		delete(m.activeWatchEvents, key)
+	}
+}
+
+// GetWatchEvents returns the recorded events that started within the time range, most recent first.
+// If mediaId is 0, the events of all media are returned.
+func (m *Manager) GetWatchEvents(mediaId int, from time.Time, to time.Time) (ret []*models.WatchEvent, err error) {
+	defer util.HandlePanicInModuleWithError("continuity/GetWatchEvents", &err)
+
+	m.flushWatchEvents()
+
+	ret, err = m.db.GetWatchEvents(m.profileId, mediaId, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("continuity: Failed to get watch events: %w", err)
+	}
+
+	return ret, nil
+}
+
+func (m *Manager) DeleteWatchEvent(id uint) error {
+	m.watchEventsMu.Lock()
+	for key, a := range m.activeWatchEvents {
+		if a.event.ID == id {
+			delete(m.activeWatchEvents, key)
+		}
+	}
+	m.watchEventsMu.Unlock()
+
+	return m.db.DeleteWatchEvent(m.profileId, id)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// flushWatchEvents writes the unsaved progress of the active events.
+func (m *Manager) flushWatchEvents() {
+	m.watchEventsMu.Lock()
+	defer m.watchEventsMu.Unlock()
+
+	now := time.Now()
+	m.closeIdleWatchEvents(now)
+	for _, a := range m.activeWatchEvents {
+		if a.lastSave.Before(a.lastUpdate) {
+			m.saveWatchEvent(a, now)
+		}
+	}
+}
+
+// closeIdleWatchEvents saves and removes the events that have not received progress for a while.
+func (m *Manager) closeIdleWatchEvents(now time.Time) {
+	for key, a := range m.activeWatchEvents {
+		if now.Sub(a.lastUpdate) < watchEventIdleTimeout {
+			continue
+		}
+		if a.lastSave.Before(a.lastUpdate) {
+			m.saveWatchEvent(a, now)
+		}
+		delete(m.activeWatchEvents, key)
+	}
+}
+
+func (m *Manager) saveWatchEvent(a *activeWatchEvent, now time.Time) {
+	if err := m.db.SaveWatchEvent(a.event); err != nil {
+		m.logger.Error().Err(err).Msg("continuity: Failed to save watch event")
+		return
+	}
+	a.lastSave = now
+}
+
+func watchEventKey(player string, clientId string, mediaId int, episodeNumber int) string {
+	return fmt.Sprintf("%s:%s:%d:%d", player, clientId, mediaId, episodeNumber)
+}
+
+// watchedSecondsBetween returns the playback time between two progress reports.
+// Backward seeks count as nothing and forward seeks are capped to the wall-clock time that elapsed.
+func watchedSecondsBetween(prevPosition float64, position float64, elapsed time.Duration) float64 {
+	delta := position - prevPosition
+	if delta <= 0 {
+		return 0
+	}
+	return min(delta, elapsed.Seconds())
+}
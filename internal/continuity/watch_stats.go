@@ -0,0 +1,171 @@
+package continuity
+
+import (
+	"cmp"
+	"fmt"
+	"seanime/internal/database/models"
+	"seanime/internal/util"
+	"slices"
+	"time"
+)
+
+type (
+	// WatchStats aggregates the watch event log.
+	WatchStats struct {
+		TotalSeconds float64 `json:"totalSeconds"`
+		EventCount   int     `json:"eventCount"`
+		// Number of completed episodes
+		CompletedEpisodes int `json:"completedEpisodes"`
+		// Number of completed episodes that had already been completed before
+		Rewatches int `json:"rewatches"`
+		// Time watched per day, oldest first
+		Days []*WatchStatsPeriod `json:"days"`
+		// Time watched per week (starting on Monday), oldest first
+		Weeks []*WatchStatsPeriod `json:"weeks"`
+		// Time watched per series, most watched first
+		Series []*WatchStatsSeries `json:"series"`
+		// Time watched per genre, most watched first
+		Genres []*WatchStatsGroup `json:"genres"`
+		// Time watched per source, most watched first
+		Sources []*WatchStatsGroup `json:"sources"`
+	}
+
+	WatchStatsPeriod struct {
+		// Start of the period, in the server's time zone
+		Start   time.Time `json:"start"`
+		Seconds float64   `json:"seconds"`
+	}
+
+	WatchStatsSeries struct {
+		MediaId int     `json:"mediaId"`
+		Seconds float64 `json:"seconds"`
+		// Number of distinct episodes completed
+		CompletedEpisodes int       `json:"completedEpisodes"`
+		Rewatches         int       `json:"rewatches"`
+		LastWatchedAt     time.Time `json:"lastWatchedAt"`
+	}
+
+	WatchStatsGroup struct {
+		Name    string  `json:"name"`
+		Seconds float64 `json:"seconds"`
+	}
+)
+
+// GetWatchStats aggregates the watch events that started within the time range.
+// genres maps media IDs to their genres, media without genres are not counted in WatchStats.Genres.
+func (m *Manager) GetWatchStats(from time.Time, to time.Time, genres map[int][]string) (ret *WatchStats, err error) {
+	defer util.HandlePanicInModuleWithError("continuity/GetWatchStats", &err)
+
+	events, err := m.GetWatchEvents(0, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWatchStats(events, genres, time.Local), nil
+}
+
+// NewWatchStats aggregates the events, days and weeks are computed in the provided location.
+func NewWatchStats(events []*models.WatchEvent, genres map[int][]string, loc *time.Location) *WatchStats {
+	ret := &WatchStats{
+		Days:    make([]*WatchStatsPeriod, 0),
+		Weeks:   make([]*WatchStatsPeriod, 0),
+		Series:  make([]*WatchStatsSeries, 0),
+		Genres:  make([]*WatchStatsGroup, 0),
+		Sources: make([]*WatchStatsGroup, 0),
+	}
+
+	days := make(map[time.Time]*WatchStatsPeriod)
+	weeks := make(map[time.Time]*WatchStatsPeriod)
+	series := make(map[int]*WatchStatsSeries)
+	completedEpisodes := make(map[string]struct{})
+	genreGroups := make(map[string]*WatchStatsGroup)
+	sourceGroups := make(map[string]*WatchStatsGroup)
+
+	for _, event := range events {
+		ret.TotalSeconds += event.WatchedSeconds
+		ret.EventCount++
+
+		day := startOfDay(event.StartedAt.In(loc))
+		if _, ok := days[day]; !ok {
+			days[day] = &WatchStatsPeriod{Start: day}
+		}
+		days[day].Seconds += event.WatchedSeconds
+
+		week := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		if _, ok := weeks[week]; !ok {
+			weeks[week] = &WatchStatsPeriod{Start: week}
+		}
+		weeks[week].Seconds += event.WatchedSeconds
+
+		s, ok := series[event.MediaID]
+		if !ok {
+			s = &WatchStatsSeries{MediaId: event.MediaID}
+			series[event.MediaID] = s
+		}
+		s.Seconds += event.WatchedSeconds
+		if event.EndedAt.After(s.LastWatchedAt) {
+			s.LastWatchedAt = event.EndedAt
+		}
+		if event.Completed {
+			ret.CompletedEpisodes++
+			episodeKey := fmt.Sprintf("%d:%d", event.MediaID, event.EpisodeNumber)
+			if _, ok := completedEpisodes[episodeKey]; !ok {
+				completedEpisodes[episodeKey] = struct{}{}
+				s.CompletedEpisodes++
+			}
+			if event.Rewatch {
+				ret.Rewatches++
+				s.Rewatches++
+			}
+		}
+
+		for _, genre := range genres[event.MediaID] {
+			addToWatchStatsGroup(genreGroups, genre, event.WatchedSeconds)
+		}
+
+		addToWatchStatsGroup(sourceGroups, cmp.Or(event.Source, "unknown"), event.WatchedSeconds)
+	}
+
+	for _, d := range days {
+		ret.Days = append(ret.Days, d)
+	}
+	for _, w := range weeks {
+		ret.Weeks = append(ret.Weeks, w)
+	}
+	for _, s := range series {
+		ret.Series = append(ret.Series, s)
+	}
+	for _, g := range genreGroups {
+		ret.Genres = append(ret.Genres, g)
+	}
+	for _, g := range sourceGroups {
+		ret.Sources = append(ret.Sources, g)
+	}
+
+	sortPeriods := func(a, b *WatchStatsPeriod) int {
+		return a.Start.Compare(b.Start)
+	}
+	sortGroups := func(a, b *WatchStatsGroup) int {
+		return cmp.Or(cmp.Compare(b.Seconds, a.Seconds), cmp.Compare(a.Name, b.Name))
+	}
+	slices.SortFunc(ret.Days, sortPeriods)
+	slices.SortFunc(ret.Weeks, sortPeriods)
+	slices.SortFunc(ret.Genres, sortGroups)
+	slices.SortFunc(ret.Sources, sortGroups)
+	slices.SortFunc(ret.Series, func(a, b *WatchStatsSeries) int {
+		return cmp.Or(cmp.Compare(b.Seconds, a.Seconds), cmp.Compare(a.MediaId, b.MediaId))
+	})
+
+	return ret
+}
+
+func addToWatchStatsGroup(groups map[string]*WatchStatsGroup, name string, seconds float64) {
+	if _, ok := groups[name]; !ok {
+		groups[name] = &WatchStatsGroup{Name: name}
+	}
+	groups[name].Seconds += seconds
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
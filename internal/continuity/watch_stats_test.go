@@ -0,0 +1,178 @@
+package continuity
+
+import (
+	"path/filepath"
+	"seanime/internal/database/db"
+	"seanime/internal/database/models"
+	"seanime/internal/util"
+	"seanime/internal/util/filecache"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchedSecondsBetween(t *testing.T) {
+	// Regular playback
+	assert.Equal(t, 10., watchedSecondsBetween(100, 110, 10*time.Second))
+	// Paused
+	assert.Equal(t, 0., watchedSecondsBetween(100, 100, 30*time.Second))
+	// Seeking backward
+	assert.Equal(t, 0., watchedSecondsBetween(100, 20, 5*time.Second))
+	// Seeking forward only counts the elapsed time
+	assert.Equal(t, 5., watchedSecondsBetween(100, 400, 5*time.Second))
+}
+
+func TestNewWatchStats(t *testing.T) {
+	loc := time.UTC
+	// Wednesday
+	day1 := time.Date(2024, 5, 15, 20, 0, 0, 0, loc)
+	// Following Monday
+	day2 := time.Date(2024, 5, 20, 21, 0, 0, 0, loc)
+
+	events := []*models.WatchEvent{
+		{MediaID: 1, EpisodeNumber: 1, StartedAt: day1, EndedAt: day1.Add(25 * time.Minute), WatchedSeconds: 1400, Source: "local", Completed: true},
+		{MediaID: 1, EpisodeNumber: 2, StartedAt: day1.Add(30 * time.Minute), EndedAt: day1.Add(40 * time.Minute), WatchedSeconds: 600, Source: "torrent"},
+		{MediaID: 1, EpisodeNumber: 1, StartedAt: day2, EndedAt: day2.Add(25 * time.Minute), WatchedSeconds: 1400, Source: "local", Completed: true, Rewatch: true},
+		{MediaID: 2, EpisodeNumber: 5, StartedAt: day2.Add(time.Hour), EndedAt: day2.Add(2 * time.Hour), WatchedSeconds: 1000, Source: "online", Completed: true},
+	}
+	genres := map[int][]string{
+		1: {"Action", "Drama"},
+		2: {"Action"},
+	}
+
+	stats := NewWatchStats(events, genres, loc)
+
+	assert.Equal(t, 4400., stats.TotalSeconds)
+	assert.Equal(t, 4, stats.EventCount)
+	assert.Equal(t, 3, stats.CompletedEpisodes)
+	assert.Equal(t, 1, stats.Rewatches)
+
+	require.Len(t, stats.Days, 2)
+	assert.Equal(t, time.Date(2024, 5, 15, 0, 0, 0, 0, loc), stats.Days[0].Start)
+	assert.Equal(t, 2000., stats.Days[0].Seconds)
+	assert.Equal(t, 2400., stats.Days[1].Seconds)
+
+	require.Len(t, stats.Weeks, 2)
+	assert.Equal(t, time.Date(2024, 5, 13, 0, 0, 0, 0, loc), stats.Weeks[0].Start)
+	assert.Equal(t, time.Date(2024, 5, 20, 0, 0, 0, 0, loc), stats.Weeks[1].Start)
+
+	require.Len(t, stats.Series, 2)
+	assert.Equal(t, 1, stats.Series[0].MediaId)
+	assert.Equal(t, 3400., stats.Series[0].Seconds)
+	// Episode 1 was completed twice
+	assert.Equal(t, 1, stats.Series[0].CompletedEpisodes)
+	assert.Equal(t, 1, stats.Series[0].Rewatches)
+
+	require.Len(t, stats.Genres, 2)
+	assert.Equal(t, &WatchStatsGroup{Name: "Action", Seconds: 4400}, stats.Genres[0])
+	assert.Equal(t, &WatchStatsGroup{Name: "Drama", Seconds: 3400}, stats.Genres[1])
+
+	require.Len(t, stats.Sources, 3)
+	assert.Equal(t, &WatchStatsGroup{Name: "local", Seconds: 2800}, stats.Sources[0])
+	assert.Equal(t, &WatchStatsGroup{Name: "online", Seconds: 1000}, stats.Sources[1])
+	assert.Equal(t, &WatchStatsGroup{Name: "torrent", Seconds: 600}, stats.Sources[2])
+}
+
+func TestWatchEventsOfConcurrentClients(t *testing.T) {
+	logger := util.NewLogger()
+	database, err := db.NewDatabase(t.TempDir(), "seanime-test", logger)
+	require.NoError(t, err)
+
+	cacher, err := filecache.NewCacher(filepath.Join(t.TempDir(), "cache"))
+	require.NoError(t, err)
+
+	manager := NewManager(&NewManagerOptions{
+		FileCacher: cacher,
+		Logger:     logger,
+		Database:   database,
+	})
+
+	track := func(clientId string, currentTime float64) {
+		manager.TrackWatchEvent(&TrackWatchEventOptions{
+			MediaId:       1,
+			EpisodeNumber: 1,
+			CurrentTime:   currentTime,
+			Duration:      1000,
+			Player:        "native",
+			Source:        LocalWatchSource,
+			ClientId:      clientId,
+		})
+	}
+
+	// Both clients play the same episode with the native player
+	track("a", 100)
+	track("b", 100)
+	track("b", 500)
+
+	// Client a closes its player
+	manager.EndWatchEvents("native", "a")
+
+	// Client b keeps watching and completes the episode in the same session
+	track("b", 900)
+
+	events, err := manager.GetWatchEvents(0, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	completed := lo.Filter(events, func(e *models.WatchEvent, _ int) bool { return e.Completed })
+	require.Len(t, completed, 1)
+	assert.False(t, completed[0].Rewatch)
+}
+
+func TestWatchEventsOfProfiles(t *testing.T) {
+	logger := util.NewLogger()
+	database, err := db.NewDatabase(t.TempDir(), "seanime-test", logger)
+	require.NoError(t, err)
+
+	cacher, err := filecache.NewCacher(filepath.Join(t.TempDir(), "cache"))
+	require.NoError(t, err)
+
+	manager := NewManager(&NewManagerOptions{
+		FileCacher: cacher,
+		Logger:     logger,
+		Database:   database,
+	})
+	profileManager := manager.NewProfileManager(2)
+
+	track := func(m *Manager, currentTime float64) {
+		m.TrackWatchEvent(&TrackWatchEventOptions{
+			MediaId:       1,
+			EpisodeNumber: 1,
+			CurrentTime:   currentTime,
+			Duration:      1000,
+			Player:        "native",
+			Source:        LocalWatchSource,
+			ClientId:      "a",
+		})
+	}
+
+	// The main profile completes the episode
+	track(manager, 100)
+	track(manager, 900)
+	manager.EndWatchEvents("native", "a")
+
+	// The episode is not a rewatch for the other profile
+	track(profileManager, 100)
+
+	from, to := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+
+	events, err := profileManager.GetWatchEvents(0, from, to)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.False(t, events[0].Rewatch)
+	assert.Equal(t, uint(2), events[0].ProfileID)
+
+	events, err = manager.GetWatchEvents(0, from, to)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.True(t, events[0].Completed)
+
+	// The main profile cannot delete the events of the other profile
+	require.NoError(t, manager.DeleteWatchEvent(2))
+	events, err = profileManager.GetWatchEvents(0, from, to)
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+}
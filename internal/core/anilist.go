@@ -26,8 +26,12 @@ func (a *App) GetUserAnilistToken() string {
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
 // UpdatePlatform changes the current platform to the provided one.
+// The list mutations of online platforms go through the outbox.
 func (a *App) UpdatePlatform(platform platform.Platform) {
-	a.AnilistPlatform = platform
+	a.AnilistPlatform = a.OutboxManager.Wrap(platform)
+	if a.ScrobblerManager != nil {
+		a.ScrobblerManager.SetPlatform(a.AnilistPlatform)
+	}
 }
 
 // UpdateAnilistClientToken will update the Anilist Client Wrapper token.
@@ -35,6 +39,9 @@ func (a *App) UpdatePlatform(platform platform.Platform) {
 func (a *App) UpdateAnilistClientToken(token string) {
 	a.AnilistClient = anilist.NewAnilistClient(token)
 	a.AnilistPlatform.SetAnilistClient(a.AnilistClient) // Update Anilist Client Wrapper in Platform
+	if a.ListSyncManager != nil {
+		a.ListSyncManager.SetAnilistClient(a.AnilistClient)
+	}
 }
 
 // GetAnimeCollection returns the user's Anilist collection if it in the cache, otherwise it queries Anilist for the user's collection.
@@ -65,6 +72,9 @@ func (a *App) RefreshAnimeCollection() (*anilist.AnimeCollection, error) {
 	// Save the collection to LocalManager
 	a.LocalManager.SetAnimeCollection(ret)
 
+	// Save the collection to OfflineDownloader
+	a.OfflineDownloader.SetAnimeCollection(ret)
+
 	// Save the collection to DirectStreamManager
 	a.DirectStreamManager.SetAnimeCollection(ret)
 
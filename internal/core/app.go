@@ -12,6 +12,7 @@ import (
 	debrid_client "seanime/internal/debrid/client"
 	"seanime/internal/directstream"
 	discordrpc_presence "seanime/internal/discordrpc/presence"
+	"seanime/internal/dlna"
 	"seanime/internal/doh"
 	"seanime/internal/events"
 	"seanime/internal/extension_playground"
@@ -20,28 +21,39 @@ import (
 	"seanime/internal/library/autodownloader"
 	"seanime/internal/library/autoscanner"
 	"seanime/internal/library/fillermanager"
+	"seanime/internal/library/offlinedownloader"
 	"seanime/internal/library/playbackmanager"
+	"seanime/internal/library/playbackqueue"
 	"seanime/internal/library/scanner"
+	"seanime/internal/listsync"
 	"seanime/internal/local"
 	"seanime/internal/manga"
 	"seanime/internal/mediaplayers/iina"
+	"seanime/internal/mediaplayers/kodi"
 	"seanime/internal/mediaplayers/mediaplayer"
 	"seanime/internal/mediaplayers/mpchc"
 	"seanime/internal/mediaplayers/mpv"
+	"seanime/internal/mediaplayers/upnp"
 	"seanime/internal/mediaplayers/vlc"
 	"seanime/internal/mediastream"
 	"seanime/internal/nakama"
 	"seanime/internal/nativeplayer"
 	"seanime/internal/onlinestream"
+	"seanime/internal/outbox"
 	"seanime/internal/platforms/anilist_platform"
+	"seanime/internal/platforms/mal_platform"
 	"seanime/internal/platforms/offline_platform"
 	"seanime/internal/platforms/platform"
 	"seanime/internal/platforms/simulated_platform"
 	"seanime/internal/plugin"
+	"seanime/internal/profile"
 	"seanime/internal/report"
+	"seanime/internal/scrobbler"
+	"seanime/internal/skipsegments"
 	"seanime/internal/torrent_clients/torrent_client"
 	"seanime/internal/torrents/torrent"
 	"seanime/internal/torrentstream"
+	"seanime/internal/trackpreference"
 	"seanime/internal/updater"
 	"seanime/internal/user"
 	"seanime/internal/util"
@@ -67,6 +79,7 @@ type (
 		FillerManager                 *fillermanager.FillerManager
 		WSEventManager                *events.WSEventManager
 		AutoDownloader                *autodownloader.AutoDownloader
+		OfflineDownloader             *offlinedownloader.OfflineDownloader
 		ExtensionRepository           *extension_repo.Repository
 		ExtensionPlaygroundRepository *extension_playground.PlaygroundRepository
 		DirectStreamManager           *directstream.Manager
@@ -76,6 +89,8 @@ type (
 			MpcHc *mpchc.MpcHc
 			Mpv   *mpv.Mpv
 			Iina  *iina.Iina
+			Kodi  *kodi.Kodi
+			Upnp  *upnp.Renderer
 		}
 		MediaPlayerRepository           *mediaplayer.Repository
 		Version                         string
@@ -89,6 +104,14 @@ type (
 		DiscordPresence                 *discordrpc_presence.Presence
 		MangaDownloader                 *manga.Downloader
 		ContinuityManager               *continuity.Manager
+		ProfileManager                  *profile.Manager
+		SkipSegmentsManager             *skipsegments.Manager
+		TrackPreferenceManager          *trackpreference.Manager
+		ListSyncManager                 *listsync.Manager
+		ScrobblerManager                *scrobbler.Manager
+		OutboxManager                   *outbox.Manager
+		DlnaServer                      *dlna.Server
+		PlaybackQueue                   *playbackqueue.Manager
 		Cleanups                        []func()
 		OnRefreshAnilistCollectionFuncs map[string]func()
 		OnFlushLogs                     func()
@@ -117,7 +140,8 @@ type (
 		ServerReady        bool // Whether the Anilist data from the first request has been fetched
 		isOffline          *bool
 		NakamaManager      *nakama.Manager
-		ServerPasswordHash string // SHA-256 hash of the server password
+		ServerPasswordHash string               // SHA-256 hash of the server password
+		profileModules     *profileModulesStore // Modules of the profiles selected by clients, other than the main profile
 	}
 )
 
@@ -296,11 +320,22 @@ func NewApp(configOpts *ConfigOptions, selfupdater *updater.SelfUpdater) *App {
 	activePlatform := anilistPlatform
 	if cfg.Server.Offline {
 		activePlatform = offlinePlatform
+	} else if isMalAccountPlatform(database) {
+		logger.Info().Msg("app: Using MyAnimeList as the account platform")
+		activePlatform = mal_platform.NewMalPlatform(database, anilistCW, logger)
 	} else if !anilistCW.IsAuthenticated() {
 		logger.Warn().Msg("app: Anilist client is not authenticated, using simulated platform")
 		activePlatform = simulatedPlatform
 	}
 
+	// Queue the list mutations that cannot reach the account platform
+	outboxManager := outbox.NewManager(&outbox.NewManagerOptions{
+		Logger:         logger,
+		Database:       database,
+		WSEventManager: wsEventManager,
+	})
+	activePlatform = outboxManager.Wrap(activePlatform)
+
 	// Initialize online streaming repository
 	onlinestreamRepository := onlinestream.NewRepository(&onlinestream.NewRepositoryOptions{
 		Logger:           logger,
@@ -322,6 +357,7 @@ func NewApp(configOpts *ConfigOptions, selfupdater *updater.SelfUpdater) *App {
 		AnilistClient:                 anilistCW,
 		AnilistPlatform:               activePlatform,
 		OfflinePlatform:               offlinePlatform,
+		OutboxManager:                 outboxManager,
 		LocalManager:                  localManager,
 		WSEventManager:                wsEventManager,
 		Logger:                        logger,
@@ -339,10 +375,18 @@ func NewApp(configOpts *ConfigOptions, selfupdater *updater.SelfUpdater) *App {
 		MangaDownloader:               nil, // Initialized in App.initModulesOnce
 		PlaybackManager:               nil, // Initialized in App.initModulesOnce
 		AutoDownloader:                nil, // Initialized in App.initModulesOnce
+		OfflineDownloader:             nil, // Initialized in App.initModulesOnce
 		AutoScanner:                   nil, // Initialized in App.initModulesOnce
 		MediastreamRepository:         nil, // Initialized in App.initModulesOnce
 		TorrentstreamRepository:       nil, // Initialized in App.initModulesOnce
 		ContinuityManager:             nil, // Initialized in App.initModulesOnce
+		ProfileManager:                nil, // Initialized in App.initModulesOnce
+		SkipSegmentsManager:           nil, // Initialized in App.initModulesOnce
+		TrackPreferenceManager:        nil, // Initialized in App.initModulesOnce
+		ListSyncManager:               nil, // Initialized in App.initModulesOnce
+		ScrobblerManager:              nil, // Initialized in App.initModulesOnce
+		DlnaServer:                    nil, // Initialized in App.initModulesOnce
+		PlaybackQueue:                 nil, // Initialized in App.initModulesOnce
 		DebridClientRepository:        nil, // Initialized in App.initModulesOnce
 		DirectStreamManager:           nil, // Initialized in App.initModulesOnce
 		NativePlayer:                  nil, // Initialized in App.initModulesOnce
@@ -364,6 +408,7 @@ func NewApp(configOpts *ConfigOptions, selfupdater *updater.SelfUpdater) *App {
 		HookManager:                     hookManager,
 		isOffline:                       &isOffline,
 		ServerPasswordHash:              serverPasswordHash,
+		profileModules:                  &profileModulesStore{modules: make(map[uint]*profileModules)},
 	}
 
 	// Run database migrations if version has changed
@@ -0,0 +1,46 @@
+package core
+
+import (
+	"seanime/internal/database/db"
+	"seanime/internal/platforms/anilist_platform"
+	"seanime/internal/platforms/mal_platform"
+	"seanime/internal/platforms/simulated_platform"
+)
+
+// IsMalAccountPlatform returns true if MyAnimeList is connected and its lists are used instead of the AniList collections.
+func (a *App) IsMalAccountPlatform() bool {
+	return isMalAccountPlatform(a.Database)
+}
+
+func isMalAccountPlatform(database *db.Database) bool {
+	malInfo, err := database.GetMalInfo()
+	return err == nil && malInfo.AccessToken != "" && malInfo.IsAccountPlatform
+}
+
+// UseMalPlatformIfSelected changes the platform to the MyAnimeList platform if MyAnimeList is the account platform.
+// It does nothing in offline mode and returns true if the platform was changed.
+func (a *App) UseMalPlatformIfSelected() bool {
+	if *a.IsOffline() || !a.IsMalAccountPlatform() {
+		return false
+	}
+	a.UpdatePlatform(mal_platform.NewMalPlatform(a.Database, a.AnilistClient, a.Logger))
+	return true
+}
+
+// UseAnilistPlatform changes the platform back to the AniList platform, or the simulated platform if the user is not logged in to AniList.
+// It does nothing in offline mode.
+func (a *App) UseAnilistPlatform() error {
+	if *a.IsOffline() {
+		return nil
+	}
+	if a.AnilistClient.IsAuthenticated() {
+		a.UpdatePlatform(anilist_platform.NewAnilistPlatform(a.AnilistClient, a.Logger))
+		return nil
+	}
+	simulatedPlatform, err := simulated_platform.NewSimulatedPlatform(a.LocalManager, a.AnilistClient, a.Logger)
+	if err != nil {
+		return err
+	}
+	a.UpdatePlatform(simulatedPlatform)
+	return nil
+}
@@ -10,28 +10,39 @@ import (
 	debrid_client "seanime/internal/debrid/client"
 	"seanime/internal/directstream"
 	discordrpc_presence "seanime/internal/discordrpc/presence"
+	"seanime/internal/dlna"
 	"seanime/internal/events"
 	"seanime/internal/library/anime"
 	"seanime/internal/library/autodownloader"
 	"seanime/internal/library/autoscanner"
 	"seanime/internal/library/fillermanager"
+	"seanime/internal/library/offlinedownloader"
 	"seanime/internal/library/playbackmanager"
+	"seanime/internal/library/playbackqueue"
+	"seanime/internal/listsync"
 	"seanime/internal/manga"
 	"seanime/internal/mediaplayers/iina"
+	"seanime/internal/mediaplayers/kodi"
 	"seanime/internal/mediaplayers/mediaplayer"
 	"seanime/internal/mediaplayers/mpchc"
 	"seanime/internal/mediaplayers/mpv"
+	"seanime/internal/mediaplayers/upnp"
 	"seanime/internal/mediaplayers/vlc"
 	"seanime/internal/mediastream"
 	"seanime/internal/nakama"
 	"seanime/internal/nativeplayer"
 	"seanime/internal/notifier"
+	"seanime/internal/platforms/platform"
 	"seanime/internal/plugin"
+	"seanime/internal/profile"
+	"seanime/internal/scrobbler"
+	"seanime/internal/skipsegments"
 	"seanime/internal/torrent_clients/qbittorrent"
 	"seanime/internal/torrent_clients/torrent_client"
 	"seanime/internal/torrent_clients/transmission"
 	"seanime/internal/torrents/torrent"
 	"seanime/internal/torrentstream"
+	"seanime/internal/trackpreference"
 	"seanime/internal/user"
 
 	"github.com/cli/browser"
@@ -93,20 +104,84 @@ func (a *App) initModulesOnce() {
 		Database:   a.Database,
 	})
 
+	// +---------------------+
+	// |       Profiles      |
+	// +---------------------+
+
+	a.ProfileManager = profile.NewManager(&profile.NewManagerOptions{
+		Database: a.Database,
+		Logger:   a.Logger,
+	})
+
+	// +---------------------+
+	// |    Skip Segments    |
+	// +---------------------+
+
+	a.SkipSegmentsManager = skipsegments.NewManager(&skipsegments.NewManagerOptions{
+		Logger:   a.Logger,
+		Database: a.Database,
+	})
+
+	// +---------------------+
+	// |  Track Preferences  |
+	// +---------------------+
+
+	a.TrackPreferenceManager = trackpreference.NewManager(&trackpreference.NewManagerOptions{
+		Logger:   a.Logger,
+		Database: a.Database,
+	})
+
+	// +---------------------+
+	// |      List Sync      |
+	// +---------------------+
+
+	a.ListSyncManager = listsync.NewManager(&listsync.NewManagerOptions{
+		Logger:        a.Logger,
+		Database:      a.Database,
+		AnilistClient: a.AnilistClient,
+		RefreshAnimeCollectionFunc: func() {
+			_, _ = a.RefreshAnimeCollection()
+		},
+		RefreshMangaCollectionFunc: func() {
+			_, _ = a.RefreshMangaCollection()
+		},
+	})
+
+	// +---------------------+
+	// |       Outbox        |
+	// +---------------------+
+
+	a.OutboxManager.SetRefreshCollectionFuncs(func() {
+		_, _ = a.RefreshAnimeCollection()
+	}, func() {
+		_, _ = a.RefreshMangaCollection()
+	})
+
+	// +---------------------+
+	// |      Scrobbler      |
+	// +---------------------+
+
+	a.ScrobblerManager = scrobbler.NewManager(&scrobbler.NewManagerOptions{
+		Logger:   a.Logger,
+		Database: a.Database,
+		Platform: a.AnilistPlatform,
+	})
+
 	// +---------------------+
 	// |   Playback Manager  |
 	// +---------------------+
 
 	// Playback Manager
 	a.PlaybackManager = playbackmanager.New(&playbackmanager.NewPlaybackManagerOptions{
-		Logger:            a.Logger,
-		WSEventManager:    a.WSEventManager,
-		Platform:          a.AnilistPlatform,
-		MetadataProvider:  a.MetadataProvider,
-		Database:          a.Database,
-		DiscordPresence:   a.DiscordPresence,
-		IsOffline:         a.IsOffline(),
-		ContinuityManager: a.ContinuityManager,
+		Logger:              a.Logger,
+		WSEventManager:      a.WSEventManager,
+		Platform:            a.AnilistPlatform,
+		MetadataProvider:    a.MetadataProvider,
+		Database:            a.Database,
+		DiscordPresence:     a.DiscordPresence,
+		IsOffline:           a.IsOffline(),
+		ContinuityManager:   a.ContinuityManager,
+		SkipSegmentsManager: a.SkipSegmentsManager,
 		RefreshAnimeCollectionFunc: func() {
 			_, _ = a.RefreshAnimeCollection()
 		},
@@ -153,6 +228,21 @@ func (a *App) initModulesOnce() {
 	// This is run in a goroutine
 	a.AutoDownloader.Start()
 
+	// +---------------------+
+	// | Offline Downloader  |
+	// +---------------------+
+
+	a.OfflineDownloader = offlinedownloader.New(&offlinedownloader.NewOfflineDownloaderOptions{
+		Logger:                  a.Logger,
+		LocalManager:            a.LocalManager,
+		Database:                a.Database,
+		TorrentRepository:       a.TorrentRepository,
+		TorrentClientRepository: a.TorrentClientRepository,
+		DebridClientRepository:  a.DebridClientRepository,
+		WSEventManager:          a.WSEventManager,
+		IsOffline:               a.IsOffline(),
+	})
+
 	// +---------------------+
 	// |   Auto Scanner      |
 	// +---------------------+
@@ -191,9 +281,10 @@ func (a *App) initModulesOnce() {
 	// +---------------------+
 
 	a.MediastreamRepository = mediastream.NewRepository(&mediastream.NewRepositoryOptions{
-		Logger:         a.Logger,
-		WSEventManager: a.WSEventManager,
-		FileCacher:     a.FileCacher,
+		Logger:            a.Logger,
+		WSEventManager:    a.WSEventManager,
+		FileCacher:        a.FileCacher,
+		ExternalSubtitles: a.GetExternalSubtitles,
 	})
 
 	a.AddCleanupFunction(func() {
@@ -209,6 +300,8 @@ func (a *App) initModulesOnce() {
 		Logger:         a.Logger,
 	})
 
+	a.DebridClientRepository.SetNativePlayer(a.NativePlayer)
+
 	// +---------------------+
 	// |   Direct Stream     |
 	// +---------------------+
@@ -223,8 +316,39 @@ func (a *App) initModulesOnce() {
 		RefreshAnimeCollectionFunc: func() {
 			_, _ = a.RefreshAnimeCollection()
 		},
-		IsOffline:    a.IsOffline(),
-		NativePlayer: a.NativePlayer,
+		GetClientModulesFunc: func(clientId string) (platform.Platform, *continuity.Manager) {
+			scope := a.GetUserScope(clientId)
+			return scope.GetPlatform(), scope.GetContinuityManager()
+		},
+		IsOffline:              a.IsOffline(),
+		NativePlayer:           a.NativePlayer,
+		SkipSegmentsManager:    a.SkipSegmentsManager,
+		TrackPreferenceManager: a.TrackPreferenceManager,
+		MediastreamRepository:  a.MediastreamRepository,
+	})
+
+	// +---------------------+
+	// |     DLNA Server     |
+	// +---------------------+
+
+	a.DlnaServer = dlna.NewServer(&dlna.NewServerOptions{
+		Logger:            a.Logger,
+		Database:          a.Database,
+		Platform:          a.AnilistPlatform,
+		MetadataProvider:  a.MetadataProvider,
+		ContinuityManager: a.ContinuityManager,
+		WSEventManager:    a.WSEventManager,
+		RefreshAnimeCollectionFunc: func() {
+			animeCollection, err := a.RefreshAnimeCollection()
+			if err == nil {
+				a.WSEventManager.SendEvent(events.RefreshedAnilistAnimeCollection, animeCollection)
+			}
+		},
+		ServerUrl: a.GetNetworkServerUrl,
+	})
+
+	a.AddCleanupFunction(func() {
+		a.DlnaServer.Shutdown()
 	})
 
 	// +---------------------+
@@ -245,6 +369,26 @@ func (a *App) initModulesOnce() {
 		NativePlayer:        a.NativePlayer,
 	})
 
+	// +---------------------+
+	// |   Playback Queue    |
+	// +---------------------+
+
+	a.PlaybackQueue = playbackqueue.NewManager(&playbackqueue.NewManagerOptions{
+		Logger:                  a.Logger,
+		WSEventManager:          a.WSEventManager,
+		Platform:                a.AnilistPlatform,
+		Database:                a.Database,
+		PlaybackManager:         a.PlaybackManager,
+		TorrentstreamRepository: a.TorrentstreamRepository,
+		DebridClientRepository:  a.DebridClientRepository,
+		DirectStreamManager:     a.DirectStreamManager,
+		NativePlayer:            a.NativePlayer,
+	})
+
+	a.AddCleanupFunction(func() {
+		a.PlaybackQueue.Shutdown()
+	})
+
 	plugin.GlobalAppContext.SetModulesPartial(plugin.AppContextModules{
 		MediaPlayerRepository: a.MediaPlayerRepository,
 		PlaybackManager:       a.PlaybackManager,
@@ -261,6 +405,7 @@ func (a *App) initModulesOnce() {
 		PlaybackManager:         a.PlaybackManager,
 		TorrentstreamRepository: a.TorrentstreamRepository,
 		DebridClientRepository:  a.DebridClientRepository,
+		ContinuityManager:       a.ContinuityManager,
 		Platform:                a.AnilistPlatform,
 		ServerHost:              a.Config.Server.Host,
 		ServerPort:              a.Config.Server.Port,
@@ -358,6 +503,8 @@ func (a *App) InitOrRefreshModules() {
 		}
 		a.MediaPlayer.Mpv = mpv.New(a.Logger, settings.MediaPlayer.MpvSocket, settings.MediaPlayer.MpvPath, settings.MediaPlayer.MpvArgs)
 		a.MediaPlayer.Iina = iina.New(a.Logger, settings.MediaPlayer.IinaSocket, settings.MediaPlayer.IinaPath, settings.MediaPlayer.IinaArgs)
+		a.MediaPlayer.Kodi = kodi.New(a.Logger, settings.MediaPlayer.Host, settings.MediaPlayer.KodiPort, settings.MediaPlayer.KodiUsername, settings.MediaPlayer.KodiPassword)
+		a.MediaPlayer.Upnp = upnp.New(a.Logger, settings.MediaPlayer.UpnpRendererLocation)
 
 		// Set media player repository
 		a.MediaPlayerRepository = mediaplayer.NewRepository(&mediaplayer.NewRepositoryOptions{
@@ -367,8 +514,15 @@ func (a *App) InitOrRefreshModules() {
 			MpcHc:             a.MediaPlayer.MpcHc,
 			Mpv:               a.MediaPlayer.Mpv, // Socket
 			Iina:              a.MediaPlayer.Iina,
+			Kodi:              a.MediaPlayer.Kodi,
+			Upnp:              a.MediaPlayer.Upnp,
 			WSEventManager:    a.WSEventManager,
 			ContinuityManager: a.ContinuityManager,
+			TrackPreferences:  a.TrackPreferenceManager,
+			LocalFileUrl:      a.GetNetworkMediaPlayerFileUrl,
+			ServerUrl:         a.GetNetworkServerUrl,
+			ExternalSubtitles: a.GetExternalSubtitles,
+			ExtensionBank:     a.ExtensionRepository.GetExtensionBank(),
 		})
 
 		a.PlaybackManager.SetMediaPlayerRepository(a.MediaPlayerRepository)
@@ -382,6 +536,12 @@ func (a *App) InitOrRefreshModules() {
 		})
 
 		a.TorrentstreamRepository.SetMediaPlayerRepository(a.MediaPlayerRepository)
+
+		a.DlnaServer.SetSettings(&dlna.Settings{
+			Enabled:            settings.MediaPlayer.DlnaServerEnabled,
+			FriendlyName:       settings.MediaPlayer.DlnaServerName,
+			AutoUpdateProgress: a.Settings.GetLibrary().AutoUpdateProgress,
+		})
 	} else {
 		a.Logger.Warn().Msg("app: Did not initialize media player module, no settings found")
 	}
@@ -444,6 +604,7 @@ func (a *App) InitOrRefreshModules() {
 
 		// Set AutoDownloader qBittorrent client
 		a.AutoDownloader.SetTorrentClientRepository(a.TorrentClientRepository)
+		a.OfflineDownloader.SetTorrentClientRepository(a.TorrentClientRepository)
 
 		plugin.GlobalAppContext.SetModulesPartial(plugin.AppContextModules{
 			TorrentClientRepository: a.TorrentClientRepository,
@@ -462,6 +623,12 @@ func (a *App) InitOrRefreshModules() {
 		a.AutoDownloader.SetSettings(settings.AutoDownloader, settings.Library.TorrentProvider)
 	}
 
+	// +---------------------+
+	// |      List Sync      |
+	// +---------------------+
+
+	a.ListSyncManager.SetSettings(settings.GetListSync())
+
 	// +---------------------+
 	// |   Library Watcher   |
 	// +---------------------+
@@ -489,6 +656,7 @@ func (a *App) InitOrRefreshModules() {
 		a.ContinuityManager.SetSettings(&continuity.Settings{
 			WatchContinuityEnabled: settings.Library.EnableWatchContinuity,
 		})
+		a.refreshProfileContinuitySettings()
 	}
 
 	if settings.Manga != nil {
@@ -564,19 +732,21 @@ func (a *App) InitOrRefreshTorrentstreamSettings() {
 			BaseModel: models.BaseModel{
 				ID: 1,
 			},
-			Enabled:             false,
-			AutoSelect:          true,
-			PreferredResolution: "",
-			DisableIPV6:         false,
-			DownloadDir:         "",
-			AddToLibrary:        false,
-			TorrentClientHost:   "",
-			TorrentClientPort:   43213,
-			StreamingServerHost: "0.0.0.0",
-			StreamingServerPort: 43214,
-			IncludeInLibrary:    false,
-			StreamUrlAddress:    "",
-			SlowSeeding:         false,
+			Enabled:              false,
+			AutoSelect:           true,
+			PreferredResolution:  "",
+			DisableIPV6:          false,
+			DownloadDir:          "",
+			AddToLibrary:         false,
+			TorrentClientHost:    "",
+			TorrentClientPort:    43213,
+			StreamingServerHost:  "0.0.0.0",
+			StreamingServerPort:  43214,
+			IncludeInLibrary:     false,
+			StreamUrlAddress:     "",
+			SlowSeeding:          false,
+			CacheMaxSize:         0,
+			PreBufferNextEpisode: false,
 		})
 		if err != nil {
 			a.Logger.Error().Err(err).Msg("app: Failed to initialize mediastream module")
@@ -620,6 +790,7 @@ func (a *App) InitOrRefreshDebridSettings() {
 			IncludeDebridStreamInLibrary: false,
 			StreamAutoSelect:             false,
 			StreamPreferredResolution:    "",
+			StreamPreBufferNextEpisode:   false,
 		})
 		if err != nil {
 			a.Logger.Error().Err(err).Msg("app: Failed to initialize debrid module")
@@ -658,6 +829,8 @@ func (a *App) InitOrRefreshAnilistData() {
 
 	// Set username to Anilist platform
 	a.AnilistPlatform.SetUsername(currUser.Viewer.Name)
+	a.ListSyncManager.SetUsername(currUser.Viewer.Name)
+	a.TrackPreferenceManager.SetUsername(currUser.Viewer.Name)
 
 	a.Logger.Info().Msg("app: Authenticated to AniList")
 
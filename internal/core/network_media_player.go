@@ -0,0 +1,61 @@
+package core
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"seanime/internal/util"
+	"strings"
+)
+
+// GetNetworkServerUrl returns the URL at which network media players (Kodi, DLNA renderers) can reach the server.
+// If it is not set in the settings, the LAN address of the machine is used.
+func (a *App) GetNetworkServerUrl() string {
+	if a.Settings != nil && a.Settings.MediaPlayer != nil && a.Settings.MediaPlayer.NetworkServerUrl != "" {
+		return strings.TrimSuffix(a.Settings.MediaPlayer.NetworkServerUrl, "/")
+	}
+
+	host := a.Config.Server.Host
+	if host == "" || host == "0.0.0.0" || host == "127.0.0.1" || host == "localhost" {
+		if ip, ok := getOutboundIP(); ok {
+			host = ip
+		}
+	}
+
+	return fmt.Sprintf("http://%s", net.JoinHostPort(host, fmt.Sprintf("%d", a.Config.Server.Port)))
+}
+
+// GetNetworkMediaPlayerFileUrl returns the URL at which network media players can fetch a local file.
+// The file must be in a library directory.
+func (a *App) GetNetworkMediaPlayerFileUrl(path string) (string, error) {
+	const endpoint = "/api/v1/mediastream/file"
+
+	ret := a.GetNetworkServerUrl() + endpoint + "?path=" + url.QueryEscape(util.Base64EncodeStr(path))
+
+	// The player cannot send the password header
+	if a.Config.Server.Password != "" {
+		token, err := a.GetServerPasswordHMACAuth().GenerateToken(endpoint)
+		if err != nil {
+			return "", err
+		}
+		ret += "&token=" + url.QueryEscape(token)
+	}
+
+	return ret, nil
+}
+
+// getOutboundIP returns the IP address of the interface used to reach the local network.
+func getOutboundIP() (string, bool) {
+	// No packet is sent, this only selects the interface
+	conn, err := net.Dial("udp", "192.168.0.1:9")
+	if err != nil {
+		return "", false
+	}
+	defer conn.Close()
+
+	addr, ok := conn.LocalAddr().(*net.UDPAddr)
+	if !ok || addr.IP.IsLoopback() {
+		return "", false
+	}
+	return addr.IP.String(), true
+}
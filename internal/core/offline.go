@@ -19,10 +19,12 @@ func (a *App) SetOfflineMode(enabled bool) {
 
 	// Update the platform and metadata provider
 	if enabled {
-		a.AnilistPlatform, _ = offline_platform.NewOfflinePlatform(a.LocalManager, a.AnilistClient, a.Logger)
+		offlinePlatform, _ := offline_platform.NewOfflinePlatform(a.LocalManager, a.AnilistClient, a.Logger)
+		a.UpdatePlatform(offlinePlatform)
 		a.MetadataProvider = a.LocalManager.GetOfflineMetadataProvider()
 	} else {
-		a.AnilistPlatform = anilist_platform.NewAnilistPlatform(a.AnilistClient, a.Logger)
+		a.UpdatePlatform(anilist_platform.NewAnilistPlatform(a.AnilistClient, a.Logger))
+		a.UseMalPlatformIfSelected()
 		a.MetadataProvider = metadata.NewProvider(&metadata.NewProviderImplOptions{
 			Logger:     a.Logger,
 			FileCacher: a.FileCacher,
@@ -0,0 +1,155 @@
+package core
+
+import (
+	"seanime/internal/api/anilist"
+	"seanime/internal/continuity"
+	"seanime/internal/database/models"
+	"seanime/internal/platforms/anilist_platform"
+	"seanime/internal/platforms/platform"
+	"seanime/internal/platforms/simulated_platform"
+	"seanime/internal/user"
+	"sync"
+)
+
+type (
+	// profileModules are the modules of a profile other than the main profile.
+	// They are created when a client of the profile makes its first request.
+	profileModules struct {
+		anilistClient     anilist.AnilistClient
+		platform          platform.Platform
+		user              *user.User
+		continuityManager *continuity.Manager
+	}
+
+	profileModulesStore struct {
+		mu      sync.Mutex
+		modules map[uint]*profileModules
+	}
+)
+
+// getProfileModules returns the modules of the profile, creating them if needed.
+func (a *App) getProfileModules(profileId uint) (*profileModules, error) {
+	a.profileModules.mu.Lock()
+	defer a.profileModules.mu.Unlock()
+
+	if pm, ok := a.profileModules.modules[profileId]; ok {
+		return pm, nil
+	}
+
+	pm, err := a.newProfileModules(profileId, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	a.profileModules.modules[profileId] = pm
+
+	return pm, nil
+}
+
+// refreshProfileAccount recreates the AniList client, the platform and the user of the profile after its account changed.
+// The continuity manager is kept so that the watch events being recorded are not lost.
+func (a *App) refreshProfileAccount(profileId uint) error {
+	a.profileModules.mu.Lock()
+	defer a.profileModules.mu.Unlock()
+
+	var continuityManager *continuity.Manager
+	if pm, ok := a.profileModules.modules[profileId]; ok {
+		continuityManager = pm.continuityManager
+	}
+
+	pm, err := a.newProfileModules(profileId, continuityManager)
+	if err != nil {
+		return err
+	}
+
+	a.profileModules.modules[profileId] = pm
+
+	return nil
+}
+
+// removeProfileModules drops the modules of a deleted profile.
+func (a *App) removeProfileModules(profileId uint) {
+	a.profileModules.mu.Lock()
+	defer a.profileModules.mu.Unlock()
+
+	delete(a.profileModules.modules, profileId)
+}
+
+// refreshProfileContinuitySettings updates the continuity settings of the profiles, it should be called after the settings are updated.
+func (a *App) refreshProfileContinuitySettings() {
+	a.profileModules.mu.Lock()
+	defer a.profileModules.mu.Unlock()
+
+	for profileId, pm := range a.profileModules.modules {
+		pm.continuityManager.SetSettings(a.getProfileContinuitySettings(profileId))
+	}
+}
+
+func (a *App) newProfileModules(profileId uint, continuityManager *continuity.Manager) (*profileModules, error) {
+	p, err := a.ProfileManager.GetProfile(profileId)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &profileModules{
+		anilistClient: anilist.NewAnilistClient(p.Token),
+		user:          user.NewSimulatedUser(),
+	}
+
+	if p.Username != "" && p.Token != "" {
+		if u, err := user.NewUser(&models.Account{Username: p.Username, Token: p.Token, Viewer: p.Viewer}); err == nil {
+			ret.user = u
+		} else {
+			a.Logger.Error().Err(err).Msg("app: Failed to create user from the account of the profile")
+		}
+	}
+
+	if ret.anilistClient.IsAuthenticated() {
+		ret.platform = anilist_platform.NewAnilistPlatform(ret.anilistClient, a.Logger)
+		ret.platform.SetUsername(ret.user.Viewer.Name)
+	} else {
+		ret.platform, err = simulated_platform.NewSimulatedPlatform(a.LocalManager.GetProfileManager(profileId), ret.anilistClient, a.Logger)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if continuityManager == nil {
+		continuityManager = a.ContinuityManager.NewProfileManager(profileId)
+		continuityManager.SetSettings(a.getProfileContinuitySettings(profileId))
+	}
+	ret.continuityManager = continuityManager
+
+	return ret, nil
+}
+
+// getProfileContinuitySettings returns the continuity settings of the profile, which are the ones of the main profile
+// until the profile changes its playback preferences.
+func (a *App) getProfileContinuitySettings(profileId uint) *continuity.Settings {
+	if prefs := a.ProfileManager.GetPlaybackPreferences(profileId); prefs != nil {
+		return &continuity.Settings{
+			WatchContinuityEnabled: prefs.EnableWatchContinuity,
+		}
+	}
+	settings := *a.ContinuityManager.GetSettings()
+	return &settings
+}
+
+// DeleteProfile deletes a profile with its watch history, watch events and simulated collections.
+// The clients using the profile go back to the main profile.
+func (a *App) DeleteProfile(profileId uint, pin string) error {
+	if err := a.ProfileManager.DeleteProfile(profileId, pin); err != nil {
+		return err
+	}
+
+	a.removeProfileModules(profileId)
+
+	if err := a.ContinuityManager.DeleteProfileData(profileId); err != nil {
+		a.Logger.Warn().Err(err).Msg("app: Failed to delete the watch history of the profile")
+	}
+	if err := a.LocalManager.DeleteSimulatedCollections(profileId); err != nil {
+		a.Logger.Warn().Err(err).Msg("app: Failed to delete the simulated collections of the profile")
+	}
+
+	return nil
+}
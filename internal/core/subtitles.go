@@ -0,0 +1,16 @@
+package core
+
+import (
+	"seanime/internal/database/db_bridge"
+	"seanime/internal/library/filesystem"
+)
+
+// GetExternalSubtitles returns the external subtitle files of the video at the given path.
+// The files found by the scanner are used for library files, the directory is only searched
+// for files that are not in the library or that were scanned before subtitle discovery was added.
+func (a *App) GetExternalSubtitles(path string) []*filesystem.ExternalSubtitle {
+	if lf, ok := db_bridge.GetLocalFileByPath(a.Database, path); ok && lf.Subtitles != nil {
+		return lf.Subtitles
+	}
+	return filesystem.FindExternalSubtitles(path)
+}
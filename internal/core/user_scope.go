@@ -0,0 +1,216 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"seanime/internal/api/anilist"
+	"seanime/internal/continuity"
+	"seanime/internal/database/models"
+	"seanime/internal/platforms/platform"
+	"seanime/internal/profile"
+	"seanime/internal/user"
+)
+
+// UserScope gives access to the data that belongs to the user of a client:
+// the account, the platform and its collections, the watch continuity and the theme.
+// Handlers should go through the scope of the request instead of the app's modules.
+//
+// The scope is the one of the profile selected by the client. The main profile uses the app's modules,
+// other profiles use their own modules, which do not update the modules running in the background.
+// The library, torrent clients, caches and the modules running in the background are shared and are accessed through the App.
+type UserScope struct {
+	app       *App
+	profileId uint
+	// nil for the main profile
+	modules *profileModules
+}
+
+// GetUserScope returns the scope of the user of the client.
+// The main profile is used in offline mode or if the profile of the client cannot be loaded.
+func (a *App) GetUserScope(clientId string) *UserScope {
+	main := &UserScope{app: a, profileId: models.DefaultProfileID}
+
+	if *a.IsOffline() || a.ProfileManager == nil {
+		return main
+	}
+
+	profileId := a.ProfileManager.GetClientProfileId(clientId)
+	if profileId == models.DefaultProfileID {
+		return main
+	}
+
+	modules, err := a.getProfileModules(profileId)
+	if err != nil {
+		a.Logger.Error().Err(err).Uint("profileId", profileId).Msg("app: Failed to load the modules of the profile")
+		return main
+	}
+
+	return &UserScope{app: a, profileId: profileId, modules: modules}
+}
+
+// GetProfileId returns the ID of the profile of the scope.
+func (s *UserScope) GetProfileId() uint {
+	return s.profileId
+}
+
+// IsMainProfile returns true if the scope uses the app's modules.
+func (s *UserScope) IsMainProfile() bool {
+	return s.modules == nil
+}
+
+func (s *UserScope) GetPlatform() platform.Platform {
+	if s.modules != nil {
+		return s.modules.platform
+	}
+	return s.app.AnilistPlatform
+}
+
+func (s *UserScope) GetAnilistClient() anilist.AnilistClient {
+	if s.modules != nil {
+		return s.modules.anilistClient
+	}
+	return s.app.AnilistClient
+}
+
+// GetUser returns the logged-in user or a simulated one.
+func (s *UserScope) GetUser() *user.User {
+	if s.modules != nil {
+		return s.modules.user
+	}
+	return s.app.GetUser()
+}
+
+func (s *UserScope) GetUserAnilistToken() string {
+	if s.modules != nil {
+		if s.modules.user.Token == user.SimulatedUserToken {
+			return ""
+		}
+		return s.modules.user.Token
+	}
+	return s.app.GetUserAnilistToken()
+}
+
+// GetAccount returns the AniList account of the profile.
+func (s *UserScope) GetAccount() (*models.Account, error) {
+	if s.modules == nil {
+		return s.app.Database.GetAccount()
+	}
+	p, err := s.app.ProfileManager.GetProfile(s.profileId)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Account{
+		Username: p.Username,
+		Token:    p.Token,
+		Viewer:   p.Viewer,
+	}, nil
+}
+
+// SetAccount saves the AniList account of a profile other than the main profile and recreates its platform.
+// An empty account logs the profile out.
+func (s *UserScope) SetAccount(acc *models.Account) error {
+	if s.modules == nil {
+		return errors.New("the account of the main profile is stored in the Account row")
+	}
+	if err := s.app.ProfileManager.SetAccount(s.profileId, acc); err != nil {
+		return err
+	}
+	return s.app.refreshProfileAccount(s.profileId)
+}
+
+func (s *UserScope) GetAnimeCollection(bypassCache bool) (*anilist.AnimeCollection, error) {
+	if s.modules != nil {
+		return s.modules.platform.GetAnimeCollection(context.Background(), bypassCache)
+	}
+	return s.app.GetAnimeCollection(bypassCache)
+}
+
+func (s *UserScope) GetRawAnimeCollection(bypassCache bool) (*anilist.AnimeCollection, error) {
+	if s.modules != nil {
+		return s.modules.platform.GetRawAnimeCollection(context.Background(), bypassCache)
+	}
+	return s.app.GetRawAnimeCollection(bypassCache)
+}
+
+// RefreshAnimeCollection queries the platform for the collection.
+// Only the collection of the main profile is passed to the modules running in the background.
+func (s *UserScope) RefreshAnimeCollection() (*anilist.AnimeCollection, error) {
+	if s.modules != nil {
+		return s.modules.platform.RefreshAnimeCollection(context.Background())
+	}
+	return s.app.RefreshAnimeCollection()
+}
+
+func (s *UserScope) GetMangaCollection(bypassCache bool) (*anilist.MangaCollection, error) {
+	if s.modules != nil {
+		return s.modules.platform.GetMangaCollection(context.Background(), bypassCache)
+	}
+	return s.app.GetMangaCollection(bypassCache)
+}
+
+func (s *UserScope) GetRawMangaCollection(bypassCache bool) (*anilist.MangaCollection, error) {
+	if s.modules != nil {
+		return s.modules.platform.GetRawMangaCollection(context.Background(), bypassCache)
+	}
+	return s.app.GetRawMangaCollection(bypassCache)
+}
+
+func (s *UserScope) RefreshMangaCollection() (*anilist.MangaCollection, error) {
+	if s.modules != nil {
+		return s.modules.platform.RefreshMangaCollection(context.Background())
+	}
+	return s.app.RefreshMangaCollection()
+}
+
+func (s *UserScope) GetContinuityManager() *continuity.Manager {
+	if s.modules != nil {
+		return s.modules.continuityManager
+	}
+	return s.app.ContinuityManager
+}
+
+// GetTheme returns the theme of the profile, profiles that have not changed their theme use the theme of the main profile.
+func (s *UserScope) GetTheme() (*models.Theme, error) {
+	if s.modules != nil {
+		if theme := s.app.ProfileManager.GetTheme(s.profileId); theme != nil {
+			return theme, nil
+		}
+	}
+	return s.app.Database.GetTheme()
+}
+
+func (s *UserScope) UpsertTheme(theme *models.Theme) (*models.Theme, error) {
+	if s.modules != nil {
+		if err := s.app.ProfileManager.SaveTheme(s.profileId, theme); err != nil {
+			return nil, err
+		}
+		return theme, nil
+	}
+	return s.app.Database.UpsertTheme(theme)
+}
+
+// ApplyPlaybackPreferences sets the playback preferences of the profile in the settings.
+// Profiles that have not changed their playback preferences use the settings of the main profile.
+func (s *UserScope) ApplyPlaybackPreferences(settings *models.Settings) {
+	if s.modules == nil || settings == nil {
+		return
+	}
+	if prefs := s.app.ProfileManager.GetPlaybackPreferences(s.profileId); prefs != nil {
+		prefs.Apply(settings)
+	}
+}
+
+// SavePlaybackPreferences saves the playback preferences contained in the settings for a profile other than the main profile.
+func (s *UserScope) SavePlaybackPreferences(settings *models.Settings) error {
+	if s.modules == nil {
+		return errors.New("the playback preferences of the main profile are stored in the settings")
+	}
+	prefs := profile.GetPlaybackPreferences(settings)
+	if err := s.app.ProfileManager.SavePlaybackPreferences(s.profileId, prefs); err != nil {
+		return err
+	}
+	s.modules.continuityManager.SetSettings(&continuity.Settings{
+		WatchContinuityEnabled: prefs.EnableWatchContinuity,
+	})
+	return nil
+}
@@ -48,6 +48,7 @@ func RunJobs(app *core.App) {
 					continue
 				}
 				SyncLocalDataJob(ctx)
+				DownloadOfflineEpisodesJob(ctx)
 			}
 		}
 	}()
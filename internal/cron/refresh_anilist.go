@@ -40,3 +40,16 @@ func SyncLocalDataJob(c *JobCtx) {
 		_ = c.App.LocalManager.SynchronizeLocal()
 	}
 }
+
+func DownloadOfflineEpisodesJob(c *JobCtx) {
+	defer func() {
+		if r := recover(); r != nil {
+		}
+	}()
+
+	if c.App.OfflineDownloader == nil || c.App.GetUser().IsSimulated {
+		return
+	}
+
+	_ = c.App.OfflineDownloader.Run()
+}
@@ -91,6 +91,17 @@ func migrateTables(db *gorm.DB) error {
 		&models.DebridSettings{},
 		&models.DebridTorrentItem{},
 		&models.PluginData{},
+		&models.SkipSegment{},
+		&models.QueuePlaylist{},
+		&models.TorrentstreamCacheEntry{},
+		&models.TrackPreference{},
+		&models.ListSyncEntry{},
+		&models.TrackerAccount{},
+		&models.ScrobbleLog{},
+		&models.ScrobbleRetry{},
+		&models.OutboxMutation{},
+		&models.WatchEvent{},
+		&models.Profile{},
 		//&models.MangaChapterContainer{},
 	)
 	if err != nil {
@@ -0,0 +1,32 @@
+package db
+
+import (
+	"seanime/internal/database/models"
+
+	"gorm.io/gorm/clause"
+)
+
+func (db *Database) GetListSyncEntries() ([]*models.ListSyncEntry, error) {
+	var res []*models.ListSyncEntry
+	err := db.gormdb.Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// UpsertListSyncEntries saves the state of the synchronized entries, replacing their previous state.
+func (db *Database) UpsertListSyncEntries(entries []*models.ListSyncEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	err := db.gormdb.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "media_type"}, {Name: "mal_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"updated_at", "anilist_id", "status", "progress", "score"}),
+	}).CreateInBatches(entries, 100).Error
+	if err != nil {
+		db.Logger.Error().Err(err).Msg("db: Failed to save list sync entries")
+		return err
+	}
+	return nil
+}
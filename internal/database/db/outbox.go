@@ -0,0 +1,48 @@
+package db
+
+import (
+	"seanime/internal/database/models"
+)
+
+func (db *Database) GetOutboxMutations() ([]*models.OutboxMutation, error) {
+	var res []*models.OutboxMutation
+	err := db.gormdb.Order("id ASC").Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// HasOutboxMutations returns true if mutations are queued for the platform.
+func (db *Database) HasOutboxMutations(platform string) (bool, error) {
+	var count int64
+	err := db.gormdb.Model(&models.OutboxMutation{}).Where("platform = ?", platform).Count(&count).Error
+	return count > 0, err
+}
+
+// GetOutboxMutation returns the queued mutation of the media, nil if there is none.
+func (db *Database) GetOutboxMutation(platform string, mediaId int) (*models.OutboxMutation, error) {
+	var res []*models.OutboxMutation
+	err := db.gormdb.Where("platform = ? AND media_id = ?", platform, mediaId).Limit(1).Find(&res).Error
+	if err != nil || len(res) == 0 {
+		return nil, err
+	}
+	return res[0], nil
+}
+
+func (db *Database) GetOutboxMutationByID(id uint) (*models.OutboxMutation, error) {
+	var res models.OutboxMutation
+	err := db.gormdb.First(&res, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (db *Database) SaveOutboxMutation(mutation *models.OutboxMutation) error {
+	return db.gormdb.Save(mutation).Error
+}
+
+func (db *Database) DeleteOutboxMutation(id uint) error {
+	return db.gormdb.Delete(&models.OutboxMutation{}, id).Error
+}
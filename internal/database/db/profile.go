@@ -0,0 +1,36 @@
+package db
+
+import (
+	"seanime/internal/database/models"
+)
+
+func (db *Database) GetProfiles() ([]*models.Profile, error) {
+	var res []*models.Profile
+	err := db.gormdb.Order("id ASC").Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (db *Database) GetProfile(id uint) (*models.Profile, error) {
+	var res models.Profile
+	err := db.gormdb.First(&res, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (db *Database) SaveProfile(profile *models.Profile) error {
+	return db.gormdb.Save(profile).Error
+}
+
+// DeleteProfile deletes the profile and its watch events.
+func (db *Database) DeleteProfile(id uint) error {
+	err := db.gormdb.Where("profile_id = ?", id).Delete(&models.WatchEvent{}).Error
+	if err != nil {
+		return err
+	}
+	return db.gormdb.Delete(&models.Profile{}, id).Error
+}
@@ -0,0 +1,37 @@
+package db
+
+import (
+	"seanime/internal/database/models"
+)
+
+func (db *Database) GetQueuePlaylists() ([]*models.QueuePlaylist, error) {
+	var res []*models.QueuePlaylist
+	err := db.gormdb.Order("updated_at desc").Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (db *Database) GetQueuePlaylist(id uint) (*models.QueuePlaylist, error) {
+	var res models.QueuePlaylist
+	err := db.gormdb.First(&res, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// SaveQueuePlaylist creates or updates a queue playlist.
+func (db *Database) SaveQueuePlaylist(playlist *models.QueuePlaylist) (*models.QueuePlaylist, error) {
+	err := db.gormdb.Save(playlist).Error
+	if err != nil {
+		db.Logger.Error().Err(err).Msg("db: Failed to save queue playlist")
+		return nil, err
+	}
+	return playlist, nil
+}
+
+func (db *Database) DeleteQueuePlaylist(id uint) error {
+	return db.gormdb.Delete(&models.QueuePlaylist{}, id).Error
+}
@@ -0,0 +1,105 @@
+package db
+
+import (
+	"errors"
+	"seanime/internal/database/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Number of log entries kept per tracker
+const scrobbleLogLimit = 200
+
+func (db *Database) GetTrackerAccounts() ([]*models.TrackerAccount, error) {
+	var res []*models.TrackerAccount
+	err := db.gormdb.Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (db *Database) GetTrackerAccount(tracker string) (*models.TrackerAccount, error) {
+	var res models.TrackerAccount
+	err := db.gormdb.Where("tracker = ?", tracker).First(&res).Error
+	if err != nil && errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New(tracker + " not connected")
+	} else if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (db *Database) UpsertTrackerAccount(account *models.TrackerAccount) (*models.TrackerAccount, error) {
+	err := db.gormdb.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tracker"}},
+		DoUpdates: clause.AssignmentColumns([]string{"updated_at", "username", "user_id", "access_token", "refresh_token", "token_expires_at", "client_id", "client_secret"}),
+	}).Create(account).Error
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+func (db *Database) DeleteTrackerAccount(tracker string) error {
+	return db.gormdb.Where("tracker = ?", tracker).Delete(&models.TrackerAccount{}).Error
+}
+
+// InsertScrobbleLog saves the log entry and removes the oldest entries of the tracker.
+func (db *Database) InsertScrobbleLog(log *models.ScrobbleLog) error {
+	err := db.gormdb.Create(log).Error
+	if err != nil {
+		return err
+	}
+	err = db.gormdb.Delete(&models.ScrobbleLog{}, "tracker = ? AND id NOT IN (SELECT id FROM scrobble_logs WHERE tracker = ? ORDER BY id DESC LIMIT ?)", log.Tracker, log.Tracker, scrobbleLogLimit).Error
+	if err != nil {
+		db.Logger.Error().Err(err).Msg("database: Failed to delete old scrobble log entries")
+	}
+	return nil
+}
+
+// GetScrobbleLogs returns the log entries of the tracker, most recent first.
+func (db *Database) GetScrobbleLogs(tracker string) ([]*models.ScrobbleLog, error) {
+	var res []*models.ScrobbleLog
+	err := db.gormdb.Where("tracker = ?", tracker).Order("id DESC").Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetScrobbleRetries returns the failed updates of all trackers, oldest first.
+func (db *Database) GetScrobbleRetries() ([]*models.ScrobbleRetry, error) {
+	var res []*models.ScrobbleRetry
+	err := db.gormdb.Order("id ASC").Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// HasScrobbleRetries returns true if failed updates of the media are waiting to be sent to the tracker.
+func (db *Database) HasScrobbleRetries(tracker string, mediaId int) (bool, error) {
+	var count int64
+	err := db.gormdb.Model(&models.ScrobbleRetry{}).Where("tracker = ? AND media_id = ?", tracker, mediaId).Count(&count).Error
+	return count > 0, err
+}
+
+func (db *Database) CountScrobbleRetries(tracker string) (int, error) {
+	var count int64
+	err := db.gormdb.Model(&models.ScrobbleRetry{}).Where("tracker = ?", tracker).Count(&count).Error
+	return int(count), err
+}
+
+func (db *Database) SaveScrobbleRetry(retry *models.ScrobbleRetry) error {
+	return db.gormdb.Save(retry).Error
+}
+
+func (db *Database) DeleteScrobbleRetry(id uint) error {
+	return db.gormdb.Delete(&models.ScrobbleRetry{}, id).Error
+}
+
+func (db *Database) DeleteScrobbleRetries(tracker string) error {
+	return db.gormdb.Where("tracker = ?", tracker).Delete(&models.ScrobbleRetry{}).Error
+}
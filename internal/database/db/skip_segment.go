@@ -0,0 +1,48 @@
+package db
+
+import (
+	"seanime/internal/database/models"
+)
+
+// GetSkipSegments returns the stored skip segments of an episode.
+func (db *Database) GetSkipSegments(mediaId int, episodeNumber int) ([]*models.SkipSegment, error) {
+	var res []*models.SkipSegment
+	err := db.gormdb.Where("media_id = ? AND episode_number = ?", mediaId, episodeNumber).Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetSkipSegmentsByMediaId returns the stored skip segments of all the episodes of a media.
+func (db *Database) GetSkipSegmentsByMediaId(mediaId int) ([]*models.SkipSegment, error) {
+	var res []*models.SkipSegment
+	err := db.gormdb.Where("media_id = ?", mediaId).Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// UpsertSkipSegment saves a skip segment.
+// An episode can only have one segment of each type per source, the previous one is replaced.
+func (db *Database) UpsertSkipSegment(segment *models.SkipSegment) (*models.SkipSegment, error) {
+	err := db.gormdb.
+		Where("media_id = ? AND episode_number = ? AND type = ? AND source = ?", segment.MediaID, segment.EpisodeNumber, segment.Type, segment.Source).
+		Delete(&models.SkipSegment{}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	segment.ID = 0
+	err = db.gormdb.Create(segment).Error
+	if err != nil {
+		db.Logger.Error().Err(err).Msg("db: Failed to save skip segment")
+		return nil, err
+	}
+	return segment, nil
+}
+
+func (db *Database) DeleteSkipSegment(id uint) error {
+	return db.gormdb.Delete(&models.SkipSegment{}, id).Error
+}
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"seanime/internal/database/models"
+)
+
+func (db *Database) GetTorrentstreamCacheEntries() ([]*models.TorrentstreamCacheEntry, error) {
+	var res []*models.TorrentstreamCacheEntry
+	err := db.gormdb.Order("last_watched_at desc").Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (db *Database) GetTorrentstreamCacheEntry(id uint) (*models.TorrentstreamCacheEntry, error) {
+	var res models.TorrentstreamCacheEntry
+	err := db.gormdb.First(&res, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// GetCompleteTorrentstreamCacheEntry returns the most recently watched complete file of an episode.
+func (db *Database) GetCompleteTorrentstreamCacheEntry(mediaId int, episodeNumber int) (*models.TorrentstreamCacheEntry, error) {
+	var res models.TorrentstreamCacheEntry
+	err := db.gormdb.Where("media_id = ? AND episode_number = ? AND complete = ?", mediaId, episodeNumber, true).
+		Order("last_watched_at desc").
+		First(&res).Error
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// UpsertTorrentstreamCacheEntry saves an entry, replacing the entry of the same file.
+func (db *Database) UpsertTorrentstreamCacheEntry(entry *models.TorrentstreamCacheEntry) (*models.TorrentstreamCacheEntry, error) {
+	var existing models.TorrentstreamCacheEntry
+	err := db.gormdb.Where("info_hash = ? AND file_path = ?", entry.InfoHash, entry.FilePath).Limit(1).Find(&existing).Error
+	if err != nil {
+		return nil, err
+	}
+	entry.ID = existing.ID
+	entry.CreatedAt = existing.CreatedAt
+	// Completion is only set once
+	entry.Complete = entry.Complete || existing.Complete
+
+	err = db.gormdb.Save(entry).Error
+	if err != nil {
+		db.Logger.Error().Err(err).Msg("db: Failed to save torrent stream cache entry")
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (db *Database) SetTorrentstreamCacheEntryComplete(infoHash string, filePath string) error {
+	return db.gormdb.Model(&models.TorrentstreamCacheEntry{}).
+		Where("info_hash = ? AND file_path = ?", infoHash, filePath).
+		Update("complete", true).Error
+}
+
+func (db *Database) DeleteTorrentstreamCacheEntry(id uint) error {
+	return db.gormdb.Delete(&models.TorrentstreamCacheEntry{}, id).Error
+}
+
+func (db *Database) DeleteTorrentstreamCacheEntriesByInfoHash(infoHash string) error {
+	return db.gormdb.Where("info_hash = ?", infoHash).Delete(&models.TorrentstreamCacheEntry{}).Error
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"errors"
+	"seanime/internal/database/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (db *Database) GetTrackPreferences() ([]*models.TrackPreference, error) {
+	var res []*models.TrackPreference
+	err := db.gormdb.Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetTrackPreference returns the track preference of a media, 0 for the default preference.
+// An empty username returns the preference that applies to every user.
+func (db *Database) GetTrackPreference(username string, mediaId int) (*models.TrackPreference, bool) {
+	var res models.TrackPreference
+	err := db.gormdb.Where("username = ? AND media_id = ?", username, mediaId).First(&res).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			db.Logger.Error().Err(err).Msg("db: Failed to get track preference")
+		}
+		return nil, false
+	}
+	return &res, true
+}
+
+// UpsertTrackPreference saves the track preference of a media, replacing the previous one.
+func (db *Database) UpsertTrackPreference(pref *models.TrackPreference) (*models.TrackPreference, error) {
+	pref.ID = 0
+	err := db.gormdb.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "username"}, {Name: "media_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"updated_at", "audio_languages", "subtitle_languages", "subtitle_type", "avoid_forced_subtitles", "disable_subtitles"}),
+	}).Create(pref).Error
+	if err != nil {
+		db.Logger.Error().Err(err).Msg("db: Failed to save track preference")
+		return nil, err
+	}
+	// Read it back since the ID is not set when the previous preference is updated
+	saved, found := db.GetTrackPreference(pref.Username, pref.MediaID)
+	if !found {
+		return nil, errors.New("track preference not found")
+	}
+	return saved, nil
+}
+
+func (db *Database) DeleteTrackPreference(username string, mediaId int) error {
+	return db.gormdb.Where("username = ? AND media_id = ?", username, mediaId).Delete(&models.TrackPreference{}).Error
+}
@@ -0,0 +1,41 @@
+package db
+
+import (
+	"seanime/internal/database/models"
+	"time"
+)
+
+func (db *Database) SaveWatchEvent(event *models.WatchEvent) error {
+	return db.gormdb.Save(event).Error
+}
+
+// GetWatchEvents returns the events that started within the time range, most recent first.
+// If mediaId is 0, the events of all media are returned.
+func (db *Database) GetWatchEvents(profileId uint, mediaId int, from time.Time, to time.Time) ([]*models.WatchEvent, error) {
+	var res []*models.WatchEvent
+	query := db.gormdb.Where("profile_id = ? AND started_at >= ? AND started_at < ?", profileId, from, to)
+	if mediaId != 0 {
+		query = query.Where("media_id = ?", mediaId)
+	}
+	err := query.Order("started_at DESC").Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// HasCompletedWatchEvent returns true if the episode has been completed in a previous event.
+func (db *Database) HasCompletedWatchEvent(profileId uint, mediaId int, episodeNumber int) (bool, error) {
+	var count int64
+	err := db.gormdb.Model(&models.WatchEvent{}).
+		Where("profile_id = ? AND media_id = ? AND episode_number = ? AND completed = ?", profileId, mediaId, episodeNumber, true).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (db *Database) DeleteWatchEvent(profileId uint, id uint) error {
+	return db.gormdb.Where("profile_id = ?", profileId).Delete(&models.WatchEvent{}, id).Error
+}
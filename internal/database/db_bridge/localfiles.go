@@ -6,11 +6,45 @@ import (
 	"seanime/internal/database/db"
 	"seanime/internal/database/models"
 	"seanime/internal/library/anime"
+	"seanime/internal/util"
+	"sync"
 )
 
 var CurrLocalFilesDbId uint
 var CurrLocalFiles mo.Option[[]*anime.LocalFile]
 
+// currLocalFilesByPath indexes the cached local files by normalized path.
+var currLocalFilesByPath = make(map[string]*anime.LocalFile)
+var currLocalFilesByPathMu sync.RWMutex
+
+// setCurrLocalFiles caches the local files and indexes them by path.
+func setCurrLocalFiles(lfs []*anime.LocalFile, id uint) {
+	byPath := make(map[string]*anime.LocalFile, len(lfs))
+	for _, lf := range lfs {
+		byPath[lf.GetNormalizedPath()] = lf
+	}
+
+	currLocalFilesByPathMu.Lock()
+	currLocalFilesByPath = byPath
+	currLocalFilesByPathMu.Unlock()
+
+	CurrLocalFiles = mo.Some(lfs)
+	CurrLocalFilesDbId = id
+}
+
+// GetLocalFileByPath returns the local file at the given path.
+// The local files are only read from the database if they are not cached yet.
+func GetLocalFileByPath(db *db.Database, path string) (*anime.LocalFile, bool) {
+	if _, _, err := GetLocalFiles(db); err != nil {
+		return nil, false
+	}
+
+	currLocalFilesByPathMu.RLock()
+	defer currLocalFilesByPathMu.RUnlock()
+	lf, ok := currLocalFilesByPath[util.NormalizePath(path)]
+	return lf, ok
+}
+
 // GetLocalFiles will return the latest local files and the id of the entry.
 func GetLocalFiles(db *db.Database) ([]*anime.LocalFile, uint, error) {
 
@@ -34,8 +68,7 @@ func GetLocalFiles(db *db.Database) ([]*anime.LocalFile, uint, error) {
 
 	db.Logger.Debug().Msg("db: Local files retrieved")
 
-	CurrLocalFiles = mo.Some(lfs)
-	CurrLocalFilesDbId = res.ID
+	setCurrLocalFiles(lfs, res.ID)
 
 	return lfs, res.ID, nil
 }
@@ -65,8 +98,7 @@ func SaveLocalFiles(db *db.Database, lfsId uint, lfs []*anime.LocalFile) ([]*ani
 		return lfs, nil
 	}
 
-	CurrLocalFiles = mo.Some(retLfs)
-	CurrLocalFilesDbId = ret.ID
+	setCurrLocalFiles(retLfs, ret.ID)
 
 	return retLfs, nil
 }
@@ -89,8 +121,7 @@ func InsertLocalFiles(db *db.Database, lfs []*anime.LocalFile) ([]*anime.LocalFi
 		return nil, err
 	}
 
-	CurrLocalFiles = mo.Some(lfs)
-	CurrLocalFilesDbId = ret.ID
+	setCurrLocalFiles(lfs, ret.ID)
 
 	return lfs, nil
 
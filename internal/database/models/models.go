@@ -26,6 +26,31 @@ type Account struct {
 	Viewer   []byte `gorm:"column:viewer" json:"viewer"`
 }
 
+// +---------------------+
+// |       Profile       |
+// +---------------------+
+
+// DefaultProfileID is the ID of the main profile.
+// Its account, theme and settings are the Account, Theme and Settings rows,
+// and it owns the watch history, watch events and simulated collections created before profiles existed.
+const DefaultProfileID uint = 1
+
+// Profile holds the data of one person using the server.
+// The library, torrent clients, caches and other settings are shared by all profiles.
+type Profile struct {
+	BaseModel
+	Name string `gorm:"column:name" json:"name"`
+	// Bcrypt hash of the PIN, empty if the profile is not protected
+	PinHash string `gorm:"column:pin_hash" json:"-"`
+	// AniList account, unused for the main profile
+	Username string `gorm:"column:username" json:"username"`
+	Token    string `gorm:"column:token" json:"-"`
+	Viewer   []byte `gorm:"column:viewer" json:"-"`
+	// Marshalled Theme and profile.PlaybackPreferences, unused for the main profile
+	Theme    []byte `gorm:"column:theme" json:"-"`
+	Playback []byte `gorm:"column:playback" json:"-"`
+}
+
 // +---------------------+
 // |     LocalFiles      |
 // +---------------------+
@@ -164,7 +189,7 @@ type MangaSettings struct {
 }
 
 type MediaPlayerSettings struct {
-	Default     string `gorm:"column:default_player" json:"defaultPlayer"` // "vlc" or "mpc-hc"
+	Default     string `gorm:"column:default_player" json:"defaultPlayer"` // "vlc", "mpc-hc", "mpv", "iina", "kodi" or "upnp"
 	Host        string `gorm:"column:player_host" json:"host"`
 	VlcUsername string `gorm:"column:vlc_username" json:"vlcUsername"`
 	VlcPassword string `gorm:"column:vlc_password" json:"vlcPassword"`
@@ -178,6 +203,17 @@ type MediaPlayerSettings struct {
 	IinaSocket  string `gorm:"column:iina_socket" json:"iinaSocket"`
 	IinaPath    string `gorm:"column:iina_path" json:"iinaPath"`
 	IinaArgs    string `gorm:"column:iina_args" json:"iinaArgs"`
+	// Kodi JSON-RPC (web server), uses Host
+	KodiPort     int    `gorm:"column:kodi_port" json:"kodiPort"`
+	KodiUsername string `gorm:"column:kodi_username" json:"kodiUsername"`
+	KodiPassword string `gorm:"column:kodi_password" json:"kodiPassword"`
+	// Device description URL of the UPnP/DLNA renderer
+	UpnpRendererLocation string `gorm:"column:upnp_renderer_location" json:"upnpRendererLocation"`
+	// URL at which network players (Kodi, DLNA renderers) can reach the server, detected if empty
+	NetworkServerUrl string `gorm:"column:network_server_url" json:"networkServerUrl"`
+	// Expose the library as a DLNA media server on the local network
+	DlnaServerEnabled bool   `gorm:"column:dlna_server_enabled" json:"dlnaServerEnabled"`
+	DlnaServerName    string `gorm:"column:dlna_server_name" json:"dlnaServerName"`
 }
 
 type TorrentSettings struct {
@@ -230,6 +266,8 @@ type Mal struct {
 	AccessToken    string    `gorm:"column:access_token" json:"accessToken"`
 	RefreshToken   string    `gorm:"column:refresh_token" json:"refreshToken"`
 	TokenExpiresAt time.Time `gorm:"column:token_expires_at" json:"tokenExpiresAt"`
+	// The MyAnimeList lists are used instead of the AniList collections
+	IsAccountPlatform bool `gorm:"column:is_account_platform" json:"isAccountPlatform"`
 }
 
 // +---------------------+
@@ -347,6 +385,14 @@ type PlaylistEntry struct {
 	Value []byte `gorm:"column:value" json:"value"`
 }
 
+// QueuePlaylist is a saved playback queue.
+// Unlike PlaylistEntry, it can contain local files, torrent, debrid and online streams.
+type QueuePlaylist struct {
+	BaseModel
+	Name  string `gorm:"column:name" json:"name"`
+	Value []byte `gorm:"column:value" json:"value"` // JSON-encoded items
+}
+
 // +------------------------+
 // | Chapter Download Queue |
 // +------------------------+
@@ -380,6 +426,10 @@ type MediastreamSettings struct {
 	FfprobePath                   string `gorm:"column:ffprobe_path" json:"ffprobePath"`
 	// v2.2+
 	TranscodeHwAccelCustomSettings string `gorm:"column:transcode_hw_accel_custom_settings" json:"transcodeHwAccelCustomSettings"`
+	// Generate seek preview thumbnails for local files
+	TrickplayEnabled bool `gorm:"column:trickplay_enabled" json:"trickplayEnabled"`
+	// Number of seconds between two thumbnails
+	TrickplayInterval int `gorm:"column:trickplay_interval" json:"trickplayInterval"`
 
 	//TranscodeTempDir              string `gorm:"column:transcode_temp_dir" json:"transcodeTempDir"` // DEPRECATED
 }
@@ -406,6 +456,10 @@ type TorrentstreamSettings struct {
 	StreamUrlAddress string `gorm:"column:stream_url_address" json:"streamUrlAddress"`
 	// v2.7+
 	SlowSeeding bool `gorm:"column:slow_seeding" json:"slowSeeding"`
+	// Maximum size of the streaming cache in GB, 0 disables the cache
+	CacheMaxSize int `gorm:"column:cache_max_size" json:"cacheMaxSize"`
+	// Download the beginning of the next episode near the end of the current one
+	PreBufferNextEpisode bool `gorm:"column:pre_buffer_next_episode" json:"preBufferNextEpisode"`
 }
 
 type TorrentstreamHistory struct {
@@ -414,6 +468,167 @@ type TorrentstreamHistory struct {
 	Torrent []byte `gorm:"column:torrent" json:"torrent"`
 }
 
+// TorrentstreamCacheEntry is a streamed episode kept in the download directory.
+type TorrentstreamCacheEntry struct {
+	BaseModel
+	InfoHash      string    `gorm:"column:info_hash;index" json:"infoHash"`
+	MediaId       int       `gorm:"column:media_id" json:"mediaId"`
+	EpisodeNumber int       `gorm:"column:episode_number" json:"episodeNumber"`
+	FilePath      string    `gorm:"column:file_path" json:"filePath"` // Path of the file in the torrent
+	Size          int64     `gorm:"column:size" json:"size"`
+	Complete      bool      `gorm:"column:complete" json:"complete"`
+	LastWatchedAt time.Time `gorm:"column:last_watched_at" json:"lastWatchedAt"`
+	Metainfo      []byte    `gorm:"column:metainfo" json:"-"` // Bencoded metainfo, used to add the torrent without fetching it
+}
+
+// +---------------------+
+// |    Skip Segments    |
+// +---------------------+
+
+// SkipSegment is a skippable range of an episode (opening, ending, etc.)
+// Only user-submitted and detected segments are stored, chapter-based segments are derived on the fly.
+type SkipSegment struct {
+	BaseModel
+	MediaID       int     `gorm:"column:media_id;index" json:"mediaId"`
+	EpisodeNumber int     `gorm:"column:episode_number" json:"episodeNumber"`
+	Type          string  `gorm:"column:type" json:"type"`
+	Source        string  `gorm:"column:source" json:"source"`
+	StartTime     float64 `gorm:"column:start_time" json:"startTime"`
+	EndTime       float64 `gorm:"column:end_time" json:"endTime"`
+}
+
+// +---------------------+
+// |  Track Preference   |
+// +---------------------+
+
+// TrackPreference holds the rules used to select the audio and subtitle tracks of an episode.
+// The preference with MediaID 0 is the default one, the others override it for a media.
+// Preferences with a username only apply when that AniList user is logged in.
+type TrackPreference struct {
+	BaseModel
+	// Empty if the preference applies to every user
+	Username string `gorm:"column:username;uniqueIndex:idx_track_preference" json:"username"`
+	MediaID  int    `gorm:"column:media_id;uniqueIndex:idx_track_preference" json:"mediaId"`
+	// Comma-separated language codes sorted by priority
+	AudioLanguages    string `gorm:"column:audio_languages" json:"audioLanguages"`
+	SubtitleLanguages string `gorm:"column:subtitle_languages" json:"subtitleLanguages"`
+	// "full", "signs" or empty
+	SubtitleType         string `gorm:"column:subtitle_type" json:"subtitleType"`
+	AvoidForcedSubtitles bool   `gorm:"column:avoid_forced_subtitles" json:"avoidForcedSubtitles"`
+	DisableSubtitles     bool   `gorm:"column:disable_subtitles" json:"disableSubtitles"`
+}
+
+// +---------------------+
+// |      List Sync      |
+// +---------------------+
+
+// ListSyncEntry is the state of a list entry after the last synchronization between AniList and MyAnimeList.
+// It is used to find out which side changed since then.
+type ListSyncEntry struct {
+	BaseModel
+	// "anime" or "manga"
+	MediaType string `gorm:"column:media_type;uniqueIndex:idx_list_sync_entry" json:"mediaType"`
+	MalID     int    `gorm:"column:mal_id;uniqueIndex:idx_list_sync_entry" json:"malId"`
+	AnilistID int    `gorm:"column:anilist_id" json:"anilistId"`
+	// AniList list status
+	Status   string `gorm:"column:status" json:"status"`
+	Progress int    `gorm:"column:progress" json:"progress"`
+	// Score out of 10
+	Score int `gorm:"column:score" json:"score"`
+}
+
+// +---------------------+
+// |      Scrobbler      |
+// +---------------------+
+
+// TrackerAccount holds the tokens of a tracker account that list updates are mirrored to.
+type TrackerAccount struct {
+	BaseModel
+	// "kitsu", "shikimori" or "simkl"
+	Tracker  string `gorm:"column:tracker;uniqueIndex" json:"tracker"`
+	Username string `gorm:"column:username" json:"username"`
+	// ID of the user on the tracker, used by trackers that need it to find list entries
+	UserID         string    `gorm:"column:user_id" json:"userId"`
+	AccessToken    string    `gorm:"column:access_token" json:"-"`
+	RefreshToken   string    `gorm:"column:refresh_token" json:"-"`
+	TokenExpiresAt time.Time `gorm:"column:token_expires_at" json:"tokenExpiresAt"`
+	// Credentials of the OAuth application, used to refresh the tokens
+	ClientID     string `gorm:"column:client_id" json:"-"`
+	ClientSecret string `gorm:"column:client_secret" json:"-"`
+}
+
+// ScrobbleLog records an attempt to mirror a list update to a tracker.
+type ScrobbleLog struct {
+	BaseModel
+	Tracker   string `gorm:"column:tracker;index" json:"tracker"`
+	MediaID   int    `gorm:"column:media_id" json:"mediaId"`
+	MediaType string `gorm:"column:media_type" json:"mediaType"`
+	// AniList list status
+	Status   string `gorm:"column:status" json:"status"`
+	Progress int    `gorm:"column:progress" json:"progress"`
+	Attempt  int    `gorm:"column:attempt" json:"attempt"`
+	// Empty if the update succeeded
+	Error string `gorm:"column:error" json:"error"`
+}
+
+// ScrobbleRetry is a failed update waiting to be sent again to a tracker.
+type ScrobbleRetry struct {
+	BaseModel
+	Tracker string `gorm:"column:tracker;index" json:"tracker"`
+	MediaID int    `gorm:"column:media_id" json:"mediaId"`
+	// JSON-encoded scrobbler.Update
+	Value         []byte    `gorm:"column:value" json:"value"`
+	Attempts      int       `gorm:"column:attempts" json:"attempts"`
+	NextAttemptAt time.Time `gorm:"column:next_attempt_at" json:"nextAttemptAt"`
+}
+
+// +---------------------+
+// |       Outbox        |
+// +---------------------+
+
+// OutboxMutation is a list entry change waiting to be sent to the account platform.
+// Changes made to the same entry are merged into a single mutation.
+type OutboxMutation struct {
+	BaseModel
+	// "anilist" or "mal"
+	Platform string `gorm:"column:platform;uniqueIndex:idx_outbox_mutation" json:"platform"`
+	MediaID  int    `gorm:"column:media_id;uniqueIndex:idx_outbox_mutation" json:"mediaId"`
+	// JSON-encoded outbox.Mutation
+	Value []byte `gorm:"column:value" json:"value"`
+	// "pending", "failed" or "conflict"
+	Status        string    `gorm:"column:status" json:"status"`
+	Attempts      int       `gorm:"column:attempts" json:"attempts"`
+	NextAttemptAt time.Time `gorm:"column:next_attempt_at" json:"nextAttemptAt"`
+	Error         string    `gorm:"column:error" json:"error"`
+}
+
+// +---------------------+
+// |     Watch Event     |
+// +---------------------+
+
+// WatchEvent records one viewing session of an episode.
+// Unlike the continuity watch history, events are never merged or trimmed.
+type WatchEvent struct {
+	BaseModel
+	ProfileID     uint      `gorm:"column:profile_id;index;default:1" json:"profileId"`
+	MediaID       int       `gorm:"column:media_id;index" json:"mediaId"`
+	EpisodeNumber int       `gorm:"column:episode_number" json:"episodeNumber"`
+	StartedAt     time.Time `gorm:"column:started_at;index" json:"startedAt"`
+	EndedAt       time.Time `gorm:"column:ended_at" json:"endedAt"`
+	// Seconds of the episode actually played, seeking and pausing are not counted
+	WatchedSeconds float64 `gorm:"column:watched_seconds" json:"watchedSeconds"`
+	// Duration of the episode in seconds
+	Duration float64 `gorm:"column:duration" json:"duration"`
+	// e.g. "mpv", "vlc", "native", "onlinestream"
+	Player string `gorm:"column:player" json:"player"`
+	// "local", "torrent", "debrid" or "online"
+	Source string `gorm:"column:source" json:"source"`
+	// Whether the episode was played past the completion threshold
+	Completed bool `gorm:"column:completed" json:"completed"`
+	// Whether the episode had already been completed before this event
+	Rewatch bool `gorm:"column:rewatch" json:"rewatch"`
+}
+
 // +---------------------+
 // |        Filler       |
 // +---------------------+
@@ -470,6 +685,8 @@ type DebridSettings struct {
 	IncludeDebridStreamInLibrary bool   `gorm:"column:include_debrid_stream_in_library" json:"includeDebridStreamInLibrary"`
 	StreamAutoSelect             bool   `gorm:"column:stream_auto_select" json:"streamAutoSelect"`
 	StreamPreferredResolution    string `gorm:"column:stream_preferred_resolution" json:"streamPreferredResolution"`
+	// Add the next episode to the debrid service near the end of the current one
+	StreamPreBufferNextEpisode bool `gorm:"column:stream_pre_buffer_next_episode" json:"streamPreBufferNextEpisode"`
 }
 
 type DebridTorrentItem struct {
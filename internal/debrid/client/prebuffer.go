@@ -0,0 +1,232 @@
+package debrid_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"seanime/internal/debrid/debrid"
+	hibiketorrent "seanime/internal/extension/hibike/torrent"
+	"seanime/internal/nativeplayer"
+	"seanime/internal/util"
+	"seanime/internal/util/torrentutil"
+	"strings"
+	"sync"
+)
+
+var errNoNextEpisode = errors.New("debridstream: no next episode")
+
+type (
+	// preBuffer holds the next episode, added to the debrid service while the current one is streaming.
+	// Only the streams played by the media player are pre-buffered, so there is one client at a time.
+	preBuffer struct {
+		mu sync.Mutex
+		// Key of the stream that triggered the pre-buffering, it is only triggered once per stream
+		triggeredBy string
+		stream      *preBufferedStream
+		cancelFunc  context.CancelFunc
+	}
+
+	preBufferedStream struct {
+		ClientId      string
+		MediaId       int
+		EpisodeNumber int
+		Torrent       *hibiketorrent.AnimeTorrent
+		FileId        string
+		TorrentItemId string
+		// Empty until the debrid service has the file ready
+		StreamUrl string
+	}
+)
+
+// onPlaybackCompletion is called with the completion of the stream.
+func (s *StreamManager) onPlaybackCompletion(opts *StartStreamOptions, completion float64) {
+	if completion >= torrentutil.PreBufferThreshold {
+		s.preBufferNextEpisode(opts)
+	}
+}
+
+// listenToNativePlayerEvents pre-buffers the next episode of the streams played by the native player.
+func (s *StreamManager) listenToNativePlayerEvents(subscriber *nativeplayer.Subscriber) {
+	defer util.HandlePanicInModuleThen("debridstream/listenToNativePlayerEvents", func() {})
+
+	for event := range subscriber.Events() {
+		status, ok := event.(*nativeplayer.VideoStatusEvent)
+		if !ok {
+			continue
+		}
+		cs, ok := s.streams.Get(status.GetClientId())
+		if !ok || cs.opts == nil || cs.opts.PlaybackType != PlaybackTypeNativePlayer {
+			continue
+		}
+		if torrentutil.ShouldPreBufferNextEpisode(status.Status.CurrentTime, status.Status.Duration) {
+			s.preBufferNextEpisode(cs.opts)
+		}
+	}
+}
+
+// preBufferNextEpisode selects the torrent of the next episode, adds it to the debrid service and resolves the stream URL,
+// so that playing the next episode starts instantly.
+// If the current episode was manually selected from a batch, the next episode is selected from the same torrent.
+func (s *StreamManager) preBufferNextEpisode(opts *StartStreamOptions) {
+	if !s.repository.settings.StreamPreBufferNextEpisode {
+		return
+	}
+
+	key := fmt.Sprintf("%s-%d-%d", opts.ClientId, opts.MediaId, opts.EpisodeNumber)
+	s.preBuffer.mu.Lock()
+	if s.preBuffer.triggeredBy == key {
+		s.preBuffer.mu.Unlock()
+		return
+	}
+	s.preBuffer.triggeredBy = key
+	if s.preBuffer.cancelFunc != nil {
+		s.preBuffer.cancelFunc()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.preBuffer.cancelFunc = cancel
+	s.preBuffer.stream = nil
+	s.preBuffer.mu.Unlock()
+
+	go func() {
+		defer util.HandlePanicInModuleThen("debridstream/preBufferNextEpisode", func() {})
+
+		err := s.resolveNextEpisode(ctx, opts)
+		if err != nil && !errors.Is(err, errNoNextEpisode) && !errors.Is(err, context.Canceled) {
+			s.repository.logger.Warn().Err(err).Msg("debridstream: Could not pre-buffer the next episode")
+		}
+	}()
+}
+
+func (s *StreamManager) resolveNextEpisode(ctx context.Context, opts *StartStreamOptions) error {
+	provider, err := s.repository.GetProvider()
+	if err != nil {
+		return err
+	}
+
+	media, _, err := s.getMediaInfo(ctx, opts.MediaId)
+	if err != nil {
+		return err
+	}
+
+	episodeNumber, ok := torrentutil.NextEpisodeNumber(opts.EpisodeNumber, media.GetCurrentEpisodeCount())
+	if !ok {
+		return errNoNextEpisode
+	}
+
+	var selectedTorrent *hibiketorrent.AnimeTorrent
+	var fileId string
+	if opts.AutoSelect {
+		selectedTorrent, fileId, err = s.repository.findBestTorrent(provider, media, episodeNumber)
+	} else {
+		// Manually selected torrents need the user, unless the next episode is in the same batch
+		if opts.Torrent == nil || !opts.Torrent.IsBatch {
+			return errNoNextEpisode
+		}
+		t := *opts.Torrent
+		selectedTorrent, fileId, err = s.repository.findBestTorrentFromManualSelection(provider, &t, media, episodeNumber, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	torrentItemId, err := provider.AddTorrent(debrid.AddTorrentOptions{
+		MagnetLink:   selectedTorrent.MagnetLink,
+		InfoHash:     selectedTorrent.InfoHash,
+		SelectFileId: fileId,
+	})
+	if err != nil {
+		return err
+	}
+
+	stream := &preBufferedStream{
+		ClientId:      opts.ClientId,
+		MediaId:       opts.MediaId,
+		EpisodeNumber: episodeNumber,
+		Torrent:       selectedTorrent,
+		FileId:        fileId,
+		TorrentItemId: torrentItemId,
+	}
+	s.setPreBufferedStream(ctx, stream)
+
+	s.repository.logger.Info().Str("torrent", selectedTorrent.Name).Msgf("debridstream: Pre-buffering episode %d", episodeNumber)
+
+	itemCh := make(chan debrid.TorrentItem, 1)
+	go func() {
+		for range itemCh {
+		}
+	}()
+
+	// Blocks until the file is ready on the debrid service
+	streamUrl, err := provider.GetTorrentStreamUrl(ctx, debrid.StreamTorrentOptions{
+		ID:     torrentItemId,
+		FileId: fileId,
+	}, itemCh)
+	close(itemCh)
+	if err != nil {
+		return err
+	}
+
+	if canStream, reason := CanStream(streamUrl); !canStream {
+		return fmt.Errorf("cannot stream the file: %s", reason)
+	}
+
+	s.preBuffer.mu.Lock()
+	if s.preBuffer.stream == stream {
+		stream.StreamUrl = streamUrl
+	}
+	s.preBuffer.mu.Unlock()
+
+	s.repository.logger.Debug().Msgf("debridstream: Next episode %d is ready to stream", episodeNumber)
+
+	return nil
+}
+
+func (s *StreamManager) setPreBufferedStream(ctx context.Context, stream *preBufferedStream) {
+	s.preBuffer.mu.Lock()
+	defer s.preBuffer.mu.Unlock()
+	// Not replaced if the pre-buffering has been cancelled
+	if ctx.Err() == nil {
+		s.preBuffer.stream = stream
+	}
+}
+
+// takePreBufferedStream returns the pre-buffered episode if it is the one requested by the client.
+// If the stream URL is not resolved yet, the torrent added to the debrid service is reused.
+// The pre-buffered episode of another client is kept.
+func (s *StreamManager) takePreBufferedStream(opts *StartStreamOptions) (*preBufferedStream, bool) {
+	s.preBuffer.mu.Lock()
+	if s.preBuffer.stream != nil && s.preBuffer.stream.ClientId != opts.ClientId {
+		s.preBuffer.mu.Unlock()
+		return nil, false
+	}
+	stream := s.preBuffer.stream
+	s.preBuffer.stream = nil
+	s.preBuffer.triggeredBy = ""
+	if s.preBuffer.cancelFunc != nil {
+		s.preBuffer.cancelFunc()
+		s.preBuffer.cancelFunc = nil
+	}
+	s.preBuffer.mu.Unlock()
+
+	if stream == nil || stream.MediaId != opts.MediaId || stream.EpisodeNumber != opts.EpisodeNumber {
+		return nil, false
+	}
+
+	// Manual selection of another torrent or file
+	if !opts.AutoSelect {
+		if opts.Torrent == nil || !strings.EqualFold(opts.Torrent.InfoHash, stream.Torrent.InfoHash) {
+			return nil, false
+		}
+		if opts.FileIndex != nil || (opts.FileId != "" && opts.FileId != stream.FileId) {
+			return nil, false
+		}
+	}
+
+	s.repository.logger.Info().Str("torrent", stream.Torrent.Name).Msg("debridstream: Streaming pre-buffered episode")
+
+	return stream, true
+}
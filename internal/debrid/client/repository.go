@@ -13,6 +13,7 @@ import (
 	"seanime/internal/debrid/torbox"
 	"seanime/internal/events"
 	"seanime/internal/library/playbackmanager"
+	"seanime/internal/nativeplayer"
 	"seanime/internal/platforms/platform"
 	"seanime/internal/torrents/torrent"
 	"seanime/internal/util/result"
@@ -36,11 +37,12 @@ type (
 		downloadLoopCancelFunc context.CancelFunc
 		torrentRepository      *torrent.Repository
 
-		playbackManager    *playbackmanager.PlaybackManager
-		streamManager      *StreamManager
-		completeAnimeCache *anilist.CompleteAnimeCache
-		metadataProvider   metadata.Provider
-		platform           platform.Platform
+		playbackManager        *playbackmanager.PlaybackManager
+		nativePlayerSubscriber *nativeplayer.Subscriber
+		streamManager          *StreamManager
+		completeAnimeCache     *anilist.CompleteAnimeCache
+		metadataProvider       metadata.Provider
+		platform               platform.Platform
 
 		previousStreamOptions mo.Option[*StartStreamOptions]
 	}
@@ -80,6 +82,15 @@ func NewRepository(opts *NewRepositoryOptions) (ret *Repository) {
 	return
 }
 
+// SetNativePlayer subscribes to the native player events, the next episode of the streams it plays is pre-buffered.
+func (r *Repository) SetNativePlayer(nativePlayer *nativeplayer.NativePlayer) {
+	if r.nativePlayerSubscriber != nil {
+		return
+	}
+	r.nativePlayerSubscriber = nativePlayer.Subscribe("debridstream")
+	go r.streamManager.listenToNativePlayerEvents(r.nativePlayerSubscriber)
+}
+
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
 func (r *Repository) startOrStopDownloadLoop() {
@@ -232,12 +243,23 @@ func (r *Repository) CancelDownload(itemID string) error {
 	return nil
 }
 
+// IsDownloading returns true if the files of the torrent item are being downloaded locally.
+func (r *Repository) IsDownloading(itemID string) bool {
+	_, found := r.ctxMap.Get(itemID)
+	return found
+}
+
 func (r *Repository) StartStream(ctx context.Context, opts *StartStreamOptions) error {
 	return r.streamManager.startStream(ctx, opts)
 }
 
+// GetStreamURL returns the URL of the last started stream.
 func (r *Repository) GetStreamURL() (string, bool) {
-	return r.streamManager.currentStreamUrl, r.streamManager.currentStreamUrl != ""
+	opts, ok := r.previousStreamOptions.Get()
+	if !ok {
+		return "", false
+	}
+	return r.streamManager.getStreamUrl(opts.ClientId)
 }
 
 func (r *Repository) CancelStream(opts *CancelStreamOptions) {
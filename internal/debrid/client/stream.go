@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"seanime/internal/continuity"
 	"seanime/internal/database/db_bridge"
 	"seanime/internal/debrid/debrid"
 	"seanime/internal/events"
@@ -12,6 +13,7 @@ import (
 	"seanime/internal/library/playbackmanager"
 	"seanime/internal/mediaplayers/mediaplayer"
 	"seanime/internal/util"
+	"seanime/internal/util/result"
 	"strconv"
 	"sync"
 	"time"
@@ -21,13 +23,25 @@ import (
 
 type (
 	StreamManager struct {
-		repository            *Repository
-		currentTorrentItemId  string
-		downloadCtxCancelFunc context.CancelFunc
-
-		currentStreamUrl string
+		repository *Repository
+		// Streams of each client, several clients can stream at the same time
+		streams *result.Map[string, *clientStream]
 
+		// The media player is shared by all clients
 		playbackSubscriberCtxCancelFunc context.CancelFunc
+
+		// Next episode added to the debrid service in advance
+		preBuffer *preBuffer
+	}
+
+	// clientStream is the stream of a client.
+	clientStream struct {
+		clientId              string
+		opts                  *StartStreamOptions
+		torrentItemId         string
+		downloadCtxCancelFunc context.CancelFunc
+		// Empty until the stream is sent to the player
+		streamUrl string
 	}
 
 	StreamPlaybackType string
@@ -56,6 +70,8 @@ type (
 	CancelStreamOptions struct {
 		// Whether to remove the torrent from the debrid service
 		RemoveTorrent bool `json:"removeTorrent"`
+		// Client whose stream is cancelled, all streams are cancelled if empty
+		ClientId string `json:"clientId"`
 	}
 )
 
@@ -68,8 +84,36 @@ const (
 
 func NewStreamManager(repository *Repository) *StreamManager {
 	return &StreamManager{
-		repository:           repository,
-		currentTorrentItemId: "",
+		repository: repository,
+		streams:    result.NewResultMap[string, *clientStream](),
+		preBuffer:  &preBuffer{},
+	}
+}
+
+// sendStreamState sends the state of the stream to the client streaming it.
+// The state is sent to all clients if the client is unknown.
+func (s *StreamManager) sendStreamState(clientId string, state StreamState) {
+	if clientId == "" {
+		s.repository.wsEventManager.SendEvent(events.DebridStreamState, state)
+		return
+	}
+	s.repository.wsEventManager.SendEventTo(clientId, events.DebridStreamState, state)
+}
+
+// getStreamUrl returns the URL of the stream of the client.
+func (s *StreamManager) getStreamUrl(clientId string) (string, bool) {
+	cs, ok := s.streams.Get(clientId)
+	if !ok || cs.streamUrl == "" {
+		return "", false
+	}
+	return cs.streamUrl, true
+}
+
+// cancelDownload stops waiting for the stream URL.
+func (cs *clientStream) cancelDownload() {
+	if cs.downloadCtxCancelFunc != nil {
+		cs.downloadCtxCancelFunc()
+		cs.downloadCtxCancelFunc = nil
 	}
 }
 
@@ -94,13 +138,13 @@ func (s *StreamManager) startStream(ctx context.Context, opts *StartStreamOption
 		Any("playbackType", opts.PlaybackType).
 		Int("mediaId", opts.MediaId).Msgf("debridstream: Starting stream for episode %s", opts.AniDBEpisode)
 
-	// Cancel the download context if it's running
-	if s.downloadCtxCancelFunc != nil {
-		s.downloadCtxCancelFunc()
-		s.downloadCtxCancelFunc = nil
+	// Cancel the download context of the previous stream of the client if it's running
+	// Streams of other clients are not affected
+	if previous, ok := s.streams.Get(opts.ClientId); ok {
+		previous.cancelDownload()
 	}
 
-	if s.playbackSubscriberCtxCancelFunc != nil {
+	if opts.PlaybackType == PlaybackTypeDefault && s.playbackSubscriberCtxCancelFunc != nil {
 		s.playbackSubscriberCtxCancelFunc()
 		s.playbackSubscriberCtxCancelFunc = nil
 	}
@@ -124,9 +168,13 @@ func (s *StreamManager) startStream(ctx context.Context, opts *StartStreamOption
 	selectedTorrent := opts.Torrent
 	fileId := opts.FileId
 
-	if opts.AutoSelect {
+	preBuffered, isPreBuffered := s.takePreBufferedStream(opts)
+	if isPreBuffered {
+		selectedTorrent = preBuffered.Torrent
+		fileId = preBuffered.FileId
+	} else if opts.AutoSelect {
 
-		s.repository.wsEventManager.SendEvent(events.DebridStreamState, StreamState{
+		s.sendStreamState(opts.ClientId, StreamState{
 			Status:      StreamStatusDownloading,
 			TorrentName: "-",
 			Message:     "Selecting best torrent...",
@@ -134,7 +182,7 @@ func (s *StreamManager) startStream(ctx context.Context, opts *StartStreamOption
 
 		st, fi, err := s.repository.findBestTorrent(provider, media, opts.EpisodeNumber)
 		if err != nil {
-			s.repository.wsEventManager.SendEvent(events.DebridStreamState, StreamState{
+			s.sendStreamState(opts.ClientId, StreamState{
 				Status:      StreamStatusFailed,
 				TorrentName: "-",
 				Message:     fmt.Sprintf("Failed to select best torrent, %v", err),
@@ -149,7 +197,7 @@ func (s *StreamManager) startStream(ctx context.Context, opts *StartStreamOption
 			return fmt.Errorf("debridstream: Failed to start stream, no torrent provided")
 		}
 
-		s.repository.wsEventManager.SendEvent(events.DebridStreamState, StreamState{
+		s.sendStreamState(opts.ClientId, StreamState{
 			Status:      StreamStatusDownloading,
 			TorrentName: selectedTorrent.Name,
 			Message:     "Analyzing selected torrent...",
@@ -163,7 +211,7 @@ func (s *StreamManager) startStream(ctx context.Context, opts *StartStreamOption
 			}
 			st, fi, err := s.repository.findBestTorrentFromManualSelection(provider, selectedTorrent, media, opts.EpisodeNumber, chosenFileIndex)
 			if err != nil {
-				s.repository.wsEventManager.SendEvent(events.DebridStreamState, StreamState{
+				s.sendStreamState(opts.ClientId, StreamState{
 					Status:      StreamStatusFailed,
 					TorrentName: selectedTorrent.Name,
 					Message:     fmt.Sprintf("Failed to analyze torrent, %v", err),
@@ -179,33 +227,44 @@ func (s *StreamManager) startStream(ctx context.Context, opts *StartStreamOption
 		return fmt.Errorf("debridstream: Failed to start stream, no torrent provided")
 	}
 
-	s.repository.wsEventManager.SendEvent(events.DebridStreamState, StreamState{
-		Status:      StreamStatusDownloading,
-		TorrentName: selectedTorrent.Name,
-		Message:     "Adding torrent...",
-	})
-
-	// Add the torrent to the debrid service
-	torrentItemId, err := provider.AddTorrent(debrid.AddTorrentOptions{
-		MagnetLink:   selectedTorrent.MagnetLink,
-		InfoHash:     selectedTorrent.InfoHash,
-		SelectFileId: fileId, // RD-only, download only the selected file
-	})
-	if err != nil {
-		s.repository.wsEventManager.SendEvent(events.DebridStreamState, StreamState{
-			Status:      StreamStatusFailed,
+	var torrentItemId string
+	if isPreBuffered {
+		// The torrent has already been added to the debrid service
+		torrentItemId = preBuffered.TorrentItemId
+	} else {
+		s.sendStreamState(opts.ClientId, StreamState{
+			Status:      StreamStatusDownloading,
 			TorrentName: selectedTorrent.Name,
-			Message:     fmt.Sprintf("Failed to add torrent, %v", err),
+			Message:     "Adding torrent...",
 		})
-		return fmt.Errorf("debridstream: Failed to add torrent: %w", err)
-	}
 
-	time.Sleep(1 * time.Second)
+		// Add the torrent to the debrid service
+		torrentItemId, err = provider.AddTorrent(debrid.AddTorrentOptions{
+			MagnetLink:   selectedTorrent.MagnetLink,
+			InfoHash:     selectedTorrent.InfoHash,
+			SelectFileId: fileId, // RD-only, download only the selected file
+		})
+		if err != nil {
+			s.sendStreamState(opts.ClientId, StreamState{
+				Status:      StreamStatusFailed,
+				TorrentName: selectedTorrent.Name,
+				Message:     fmt.Sprintf("Failed to add torrent, %v", err),
+			})
+			return fmt.Errorf("debridstream: Failed to add torrent: %w", err)
+		}
 
-	// Save the current torrent item id
-	s.currentTorrentItemId = torrentItemId
+		time.Sleep(1 * time.Second)
+	}
+
+	// Save the torrent item id of the client's stream
 	ctx, cancelCtx := context.WithCancel(context.Background())
-	s.downloadCtxCancelFunc = cancelCtx
+	cs := &clientStream{
+		clientId:              opts.ClientId,
+		opts:                  opts,
+		torrentItemId:         torrentItemId,
+		downloadCtxCancelFunc: cancelCtx,
+	}
+	s.streams.Set(opts.ClientId, cs)
 
 	readyCh := make(chan struct{})
 	readyOnce := sync.Once{}
@@ -221,15 +280,12 @@ func (s *StreamManager) startStream(ctx context.Context, opts *StartStreamOption
 
 		defer func() {
 			// Cancel the context
-			if s.downloadCtxCancelFunc != nil {
-				s.downloadCtxCancelFunc()
-				s.downloadCtxCancelFunc = nil
-			}
+			cs.cancelDownload()
 		}()
 
 		s.repository.logger.Debug().Msg("debridstream: Listening to torrent status")
 
-		s.repository.wsEventManager.SendEvent(events.DebridStreamState, StreamState{
+		s.sendStreamState(opts.ClientId, StreamState{
 			Status:      StreamStatusDownloading,
 			TorrentName: selectedTorrent.Name,
 			Message:     fmt.Sprintf("Downloading torrent..."),
@@ -239,7 +295,7 @@ func (s *StreamManager) startStream(ctx context.Context, opts *StartStreamOption
 
 		go func() {
 			for item := range itemCh {
-				s.repository.wsEventManager.SendEvent(events.DebridStreamState, StreamState{
+				s.sendStreamState(opts.ClientId, StreamState{
 					Status:      StreamStatusDownloading,
 					TorrentName: item.Name,
 					Message:     fmt.Sprintf("Downloading torrent: %d%%", item.CompletionPercentage),
@@ -249,10 +305,16 @@ func (s *StreamManager) startStream(ctx context.Context, opts *StartStreamOption
 
 		// Await the stream URL
 		// For Torbox, this will wait until the entire torrent is downloaded
-		streamUrl, err := provider.GetTorrentStreamUrl(ctx, debrid.StreamTorrentOptions{
-			ID:     torrentItemId,
-			FileId: fileId,
-		}, itemCh)
+		var streamUrl string
+		var err error
+		if isPreBuffered && preBuffered.StreamUrl != "" {
+			streamUrl = preBuffered.StreamUrl
+		} else {
+			streamUrl, err = provider.GetTorrentStreamUrl(ctx, debrid.StreamTorrentOptions{
+				ID:     torrentItemId,
+				FileId: fileId,
+			}, itemCh)
+		}
 
 		go func() {
 			close(itemCh)
@@ -267,7 +329,7 @@ func (s *StreamManager) startStream(ctx context.Context, opts *StartStreamOption
 		if err != nil {
 			s.repository.logger.Err(err).Msg("debridstream: Failed to get stream URL")
 			if !errors.Is(err, context.Canceled) {
-				s.repository.wsEventManager.SendEvent(events.DebridStreamState, StreamState{
+				s.sendStreamState(opts.ClientId, StreamState{
 					Status:      StreamStatusFailed,
 					TorrentName: selectedTorrent.Name,
 					Message:     fmt.Sprintf("Failed to get stream URL, %v", err),
@@ -288,7 +350,7 @@ func (s *StreamManager) startStream(ctx context.Context, opts *StartStreamOption
 		// Default prevented, we check if we can stream the file
 		if skipCheckEvent.DefaultPrevented {
 			s.repository.logger.Debug().Msg("debridstream: Stream URL received, checking stream file")
-			s.repository.wsEventManager.SendEvent(events.DebridStreamState, StreamState{
+			s.sendStreamState(opts.ClientId, StreamState{
 				Status:      StreamStatusDownloading,
 				TorrentName: selectedTorrent.Name,
 				Message:     "Checking stream file...",
@@ -308,7 +370,7 @@ func (s *StreamManager) startStream(ctx context.Context, opts *StartStreamOption
 						if retries >= skipCheckEvent.Retries {
 							s.repository.logger.Error().Msg("debridstream: Cannot stream the file")
 
-							s.repository.wsEventManager.SendEvent(events.DebridStreamState, StreamState{
+							s.sendStreamState(opts.ClientId, StreamState{
 								Status:      StreamStatusFailed,
 								TorrentName: selectedTorrent.Name,
 								Message:     fmt.Sprintf("Cannot stream this file: %s", reason),
@@ -316,7 +378,7 @@ func (s *StreamManager) startStream(ctx context.Context, opts *StartStreamOption
 							return
 						}
 						s.repository.logger.Warn().Msg("debridstream: Rechecking stream file in 8 seconds")
-						s.repository.wsEventManager.SendEvent(events.DebridStreamState, StreamState{
+						s.sendStreamState(opts.ClientId, StreamState{
 							Status:      StreamStatusDownloading,
 							TorrentName: selectedTorrent.Name,
 							Message:     "Checking stream file...",
@@ -333,7 +395,7 @@ func (s *StreamManager) startStream(ctx context.Context, opts *StartStreamOption
 		s.repository.logger.Debug().Msg("debridstream: Stream is ready")
 
 		// Signal to the client that the torrent is ready to stream
-		s.repository.wsEventManager.SendEvent(events.DebridStreamState, StreamState{
+		s.sendStreamState(opts.ClientId, StreamState{
 			Status:      StreamStatusReady,
 			TorrentName: selectedTorrent.Name,
 			Message:     "Ready to stream the file",
@@ -368,19 +430,19 @@ func (s *StreamManager) startStream(ctx context.Context, opts *StartStreamOption
 			return
 		}
 
-		s.currentStreamUrl = streamUrl
+		cs.streamUrl = streamUrl
 
 		switch playbackType {
 		case PlaybackTypeNone:
 			// No playback type selected, just signal to the client that the stream is ready
-			s.repository.wsEventManager.SendEvent(events.DebridStreamState, StreamState{
+			s.sendStreamState(opts.ClientId, StreamState{
 				Status:      StreamStatusReady,
 				TorrentName: selectedTorrent.Name,
 				Message:     "External player link sent",
 			})
 		case PlaybackTypeNoneAndAwait:
 			// No playback type selected, just signal to the client that the stream is ready
-			s.repository.wsEventManager.SendEvent(events.DebridStreamState, StreamState{
+			s.sendStreamState(opts.ClientId, StreamState{
 				Status:      StreamStatusReady,
 				TorrentName: selectedTorrent.Name,
 				Message:     "External player link sent",
@@ -400,9 +462,10 @@ func (s *StreamManager) startStream(ctx context.Context, opts *StartStreamOption
 			// Sends the stream to the media player
 			// DEVNOTE: Events are handled by the torrentstream.Repository module
 			err = s.repository.playbackManager.StartStreamingUsingMediaPlayer(windowTitle, &playbackmanager.StartPlayingOptions{
-				Payload:   streamUrl,
-				UserAgent: opts.UserAgent,
-				ClientId:  opts.ClientId,
+				Payload:     streamUrl,
+				UserAgent:   opts.UserAgent,
+				ClientId:    opts.ClientId,
+				WatchSource: continuity.DebridWatchSource,
 			}, media, aniDbEpisode)
 			if err != nil {
 				go s.repository.playbackManager.UnsubscribeFromPlaybackStatus("debridstream")
@@ -411,7 +474,7 @@ func (s *StreamManager) startStream(ctx context.Context, opts *StartStreamOption
 					s.playbackSubscriberCtxCancelFunc = nil
 				}
 				// Failed to start the stream, we'll drop the torrents and stop the server
-				s.repository.wsEventManager.SendEvent(events.DebridStreamState, StreamState{
+				s.sendStreamState(opts.ClientId, StreamState{
 					Status:      StreamStatusFailed,
 					TorrentName: selectedTorrent.Name,
 					Message:     fmt.Sprintf("Failed to send the stream to the media player, %v", err),
@@ -429,16 +492,23 @@ func (s *StreamManager) startStream(ctx context.Context, opts *StartStreamOption
 						s.playbackSubscriberCtxCancelFunc = nil
 					}
 				}()
-				select {
-				case <-playbackSubscriberCtx.Done():
-					s.repository.playbackManager.UnsubscribeFromPlaybackStatus("debridstream")
-					s.currentStreamUrl = ""
-				case event := <-playbackSubscriber.EventCh:
-					s.repository.logger.Debug().Msgf("debridstream: Playback status received: %v", event)
-					switch event.(type) {
-					case mediaplayer.StreamingTrackingStoppedEvent:
-						go s.repository.playbackManager.UnsubscribeFromPlaybackStatus("debridstream")
-						s.currentStreamUrl = ""
+				for {
+					select {
+					case <-playbackSubscriberCtx.Done():
+						s.repository.playbackManager.UnsubscribeFromPlaybackStatus("debridstream")
+						cs.streamUrl = ""
+						return
+					case event := <-playbackSubscriber.EventCh:
+						switch e := event.(type) {
+						case playbackmanager.PlaybackStatusChangedEvent:
+							// Pre-buffer the next episode near the end of the current one
+							s.onPlaybackCompletion(opts, e.Status.CompletionPercentage)
+						case mediaplayer.StreamingTrackingStoppedEvent:
+							s.repository.logger.Debug().Msgf("debridstream: Playback status received: %v", event)
+							go s.repository.playbackManager.UnsubscribeFromPlaybackStatus("debridstream")
+							cs.streamUrl = ""
+							return
+						}
 					}
 				}
 			}()
@@ -457,7 +527,7 @@ func (s *StreamManager) startStream(ctx context.Context, opts *StartStreamOption
 
 			// Signal to the client that the torrent has started playing (remove loading status)
 			// We can't know for sure
-			s.repository.wsEventManager.SendEvent(events.DebridStreamState, StreamState{
+			s.sendStreamState(opts.ClientId, StreamState{
 				Status:      StreamStatusReady,
 				TorrentName: selectedTorrent.Name,
 				Message:     "External player link sent",
@@ -471,7 +541,7 @@ func (s *StreamManager) startStream(ctx context.Context, opts *StartStreamOption
 		}()
 	}(ctx)
 
-	s.repository.wsEventManager.SendEvent(events.DebridStreamState, StreamState{
+	s.sendStreamState(opts.ClientId, StreamState{
 		Status:      StreamStatusStarted,
 		TorrentName: selectedTorrent.Name,
 		Message:     "Stream started",
@@ -486,26 +556,34 @@ func (s *StreamManager) startStream(ctx context.Context, opts *StartStreamOption
 	return nil
 }
 
+// cancelStream cancels the stream of the client, or all streams if no client is given.
 func (s *StreamManager) cancelStream(opts *CancelStreamOptions) {
-	if s.downloadCtxCancelFunc != nil {
-		s.downloadCtxCancelFunc()
-		s.downloadCtxCancelFunc = nil
+	var streams []*clientStream
+	if opts.ClientId == "" {
+		streams = s.streams.Values()
+		s.streams.Clear()
+	} else if cs, ok := s.streams.Get(opts.ClientId); ok {
+		streams = append(streams, cs)
+		s.streams.Delete(opts.ClientId)
 	}
 
-	s.currentStreamUrl = ""
+	for _, cs := range streams {
+		cs.cancelDownload()
+		cs.streamUrl = ""
 
-	if opts.RemoveTorrent && s.currentTorrentItemId != "" {
-		// Remove the torrent from the debrid service
-		provider, err := s.repository.GetProvider()
-		if err != nil {
-			s.repository.logger.Err(err).Msg("debridstream: Failed to remove torrent")
-			return
-		}
+		if opts.RemoveTorrent && cs.torrentItemId != "" {
+			// Remove the torrent from the debrid service
+			provider, err := s.repository.GetProvider()
+			if err != nil {
+				s.repository.logger.Err(err).Msg("debridstream: Failed to remove torrent")
+				return
+			}
 
-		// Remove the torrent from the debrid service
-		err = provider.DeleteTorrent(s.currentTorrentItemId)
-		if err != nil {
-			s.repository.logger.Err(err).Msg("debridstream: Failed to remove torrent")
+			// Remove the torrent from the debrid service
+			err = provider.DeleteTorrent(cs.torrentItemId)
+			if err != nil {
+				s.repository.logger.Err(err).Msg("debridstream: Failed to remove torrent")
+			}
 		}
 	}
 }
@@ -10,14 +10,13 @@ import (
 	"path/filepath"
 	"seanime/internal/api/anilist"
 	"seanime/internal/library/anime"
+	"seanime/internal/library/filesystem"
 	"seanime/internal/mkvparser"
 	"seanime/internal/nativeplayer"
 	"seanime/internal/util"
 	httputil "seanime/internal/util/http"
-	"seanime/internal/util/result"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/samber/mo"
 )
 
@@ -92,7 +91,7 @@ func (s *LocalFileStream) LoadPlaybackInfo() (ret *nativeplayer.PlaybackInfo, er
 		}
 		_, _ = fr.Seek(0, io.SeekStart)
 
-		id := uuid.New().String()
+		id := s.id
 
 		var entryListData *anime.EntryListData
 		if animeCollection, ok := s.manager.animeCollection.Get(); ok {
@@ -114,6 +113,11 @@ func (s *LocalFileStream) LoadPlaybackInfo() (ret *nativeplayer.PlaybackInfo, er
 			EntryListData:     entryListData,
 		}
 
+		// Reference the seek preview thumbnails, they are generated in the background if missing
+		if trickplayUrl := s.manager.mediastreamRepository.GetTrickplayUrl(s.localFile.Path); trickplayUrl != "" {
+			playbackInfo.TrickplayUrl = "{{SERVER_URL}}" + trickplayUrl
+		}
+
 		// If the content type is an EBML content type, we can create a metadata parser
 		if isEbmlContent(s.LoadContentType()) {
 
@@ -133,7 +137,14 @@ func (s *LocalFileStream) LoadPlaybackInfo() (ret *nativeplayer.PlaybackInfo, er
 				return
 			}
 
-			playbackInfo.MkvMetadata = metadata
+			// Load the external subtitle files found by the scanner
+			// Files scanned before subtitle discovery was added have none stored
+			externalSubs := s.localFile.Subtitles
+			if externalSubs == nil {
+				externalSubs = filesystem.FindExternalSubtitles(s.localFile.Path)
+			}
+
+			playbackInfo.MkvMetadata = s.addExternalSubtitleTracks(metadata, externalSubs)
 			playbackInfo.MkvMetadataParser = mo.Some(parser)
 		}
 
@@ -144,7 +155,7 @@ func (s *LocalFileStream) LoadPlaybackInfo() (ret *nativeplayer.PlaybackInfo, er
 }
 
 func (s *LocalFileStream) GetAttachmentByName(filename string) (*mkvparser.AttachmentInfo, bool) {
-	return getAttachmentByName(s.manager.playbackCtx, s, filename)
+	return getAttachmentByName(s.playbackCtx, s, filename)
 }
 
 func (s *LocalFileStream) GetStreamHandler() http.Handler {
@@ -181,7 +192,7 @@ func ServeLocalFile(w http.ResponseWriter, r *http.Request, lfStream *LocalFileS
 		lfStream.serveContentCancelFunc()
 	}
 
-	ct, cancel := context.WithCancel(lfStream.manager.playbackCtx)
+	ct, cancel := context.WithCancel(lfStream.playbackCtx)
 	lfStream.serveContentCancelFunc = cancel
 
 	reader, err := lfStream.newReader()
@@ -228,7 +239,7 @@ func ServeLocalFile(w http.ResponseWriter, r *http.Request, lfStream *LocalFileS
 			http.Error(w, "Failed to create subtitle reader", http.StatusInternalServerError)
 			return
 		}
-		lfStream.StartSubtitleStream(lfStream, lfStream.manager.playbackCtx, subReader, ranges[0].Start)
+		lfStream.StartSubtitleStream(lfStream, lfStream.playbackCtx, subReader, ranges[0].Start)
 	}
 
 	serveContentRange(w, r, ct, reader, lfStream.localFile.Path, size, playbackInfo.MimeType, ranges)
@@ -303,19 +314,13 @@ func (m *Manager) PlayLocalFile(ctx context.Context, opts PlayLocalFileOptions)
 	}
 
 	stream := &LocalFileStream{
-		localFile: lf,
-		BaseStream: BaseStream{
-			manager:               m,
-			logger:                m.Logger,
-			clientId:              opts.ClientId,
-			filename:              filepath.Base(lf.Path),
-			media:                 media,
-			episode:               episode,
-			episodeCollection:     episodeCollection,
-			subtitleEventCache:    result.NewResultMap[string, *mkvparser.SubtitleEvent](),
-			activeSubtitleStreams: result.NewResultMap[string, *SubtitleStream](),
-		},
+		localFile:  lf,
+		BaseStream: newBaseStream(m, opts.ClientId),
 	}
+	stream.filename = filepath.Base(lf.Path)
+	stream.media = media
+	stream.episode = episode
+	stream.episodeCollection = episodeCollection
 
 	m.loadStream(stream)
 
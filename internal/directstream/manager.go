@@ -8,9 +8,12 @@ import (
 	discordrpc_presence "seanime/internal/discordrpc/presence"
 	"seanime/internal/events"
 	"seanime/internal/library/anime"
+	"seanime/internal/mediastream"
 	"seanime/internal/mkvparser"
 	"seanime/internal/nativeplayer"
 	"seanime/internal/platforms/platform"
+	"seanime/internal/skipsegments"
+	"seanime/internal/trackpreference"
 	"seanime/internal/util/result"
 	"sync"
 	"time"
@@ -33,19 +36,22 @@ type (
 		discordPresence            *discordrpc_presence.Presence
 		platform                   platform.Platform
 		refreshAnimeCollectionFunc func() // This function is called to refresh the AniList collection
+		// Returns the platform and the continuity manager of the profile selected by the client
+		getClientModulesFunc func(clientId string) (platform.Platform, *continuity.Manager)
 
 		nativePlayer           *nativeplayer.NativePlayer
 		nativePlayerSubscriber *nativeplayer.Subscriber
+		skipSegmentsManager    *skipsegments.Manager
+		trackPreferenceManager *trackpreference.Manager
+		mediastreamRepository  *mediastream.Repository
 
 		// --------- Playback Context -------- //
 
-		playbackMu            sync.Mutex
-		playbackCtx           context.Context
-		playbackCtxCancelFunc context.CancelFunc
+		playbackMu sync.Mutex
 
 		// ---------- Playback State ---------- //
 
-		currentStream mo.Option[Stream] // The current stream being played
+		streams *result.Map[string, Stream] // The streams being played, by client ID
 
 		// \/ Stream playback
 		// This is set by [SetStreamEpisodeCollection]
@@ -74,8 +80,12 @@ type (
 		DiscordPresence            *discordrpc_presence.Presence
 		Platform                   platform.Platform
 		RefreshAnimeCollectionFunc func()
+		GetClientModulesFunc       func(clientId string) (platform.Platform, *continuity.Manager)
 		IsOffline                  *bool
 		NativePlayer               *nativeplayer.NativePlayer
+		SkipSegmentsManager        *skipsegments.Manager
+		TrackPreferenceManager     *trackpreference.Manager
+		MediastreamRepository      *mediastream.Repository
 	}
 )
 
@@ -88,9 +98,13 @@ func NewManager(options NewManagerOptions) *Manager {
 		discordPresence:            options.DiscordPresence,
 		platform:                   options.Platform,
 		refreshAnimeCollectionFunc: options.RefreshAnimeCollectionFunc,
+		getClientModulesFunc:       options.GetClientModulesFunc,
 		isOffline:                  options.IsOffline,
-		currentStream:              mo.None[Stream](),
+		streams:                    result.NewResultMap[string, Stream](),
 		nativePlayer:               options.NativePlayer,
+		skipSegmentsManager:        options.SkipSegmentsManager,
+		trackPreferenceManager:     options.TrackPreferenceManager,
+		mediastreamRepository:      options.MediastreamRepository,
 		parserCache:                result.NewCache[string, *mkvparser.MetadataParser](),
 	}
 
@@ -105,6 +119,14 @@ func (m *Manager) SetAnimeCollection(ac *anilist.AnimeCollection) {
 	m.animeCollection = mo.Some(ac)
 }
 
+// getClientModules returns the platform and the continuity manager used to track the playback of the client.
+func (m *Manager) getClientModules(clientId string) (platform.Platform, *continuity.Manager) {
+	if m.getClientModulesFunc == nil {
+		return m.platform, m.continuityManager
+	}
+	return m.getClientModulesFunc(clientId)
+}
+
 func (m *Manager) SetSettings(s *Settings) {
 	m.settings = s
 }
@@ -8,7 +8,7 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-// ServeEchoStream is a proxy to the current stream.
+// ServeEchoStream is a proxy to the stream identified by the "id" query parameter.
 // It sits in between the player and the real stream (whether it's a local file, torrent, or http stream).
 //
 // If this is an EBML stream, it gets the range request from the player, processes it to stream the correct subtitles, and serves the video.
@@ -17,10 +17,10 @@ func (m *Manager) ServeEchoStream() http.Handler {
 	return m.getStreamHandler()
 }
 
-// ServeEchoAttachments serves the attachments loaded into memory from the current stream.
+// ServeEchoAttachments serves the attachments loaded into memory from a stream.
+// The stream is identified by the "id" query parameter, it can be omitted if only one stream is being played.
 func (m *Manager) ServeEchoAttachments(c echo.Context) error {
-	// Get the current stream
-	stream, ok := m.currentStream.Get()
+	stream, ok := m.getStream(c.QueryParam("id"))
 	if !ok {
 		return errors.New("no stream")
 	}
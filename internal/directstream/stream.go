@@ -2,6 +2,7 @@ package directstream
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,11 +13,12 @@ import (
 	"seanime/internal/library/anime"
 	"seanime/internal/mkvparser"
 	"seanime/internal/nativeplayer"
+	"seanime/internal/skipsegments"
 	"seanime/internal/util/result"
 	"sync"
 
+	"github.com/google/uuid"
 	"github.com/rs/zerolog"
-	"github.com/samber/mo"
 )
 
 // Stream is the common interface for all stream types.
@@ -54,11 +56,13 @@ type Stream interface {
 	GetSubtitleEventCache() *result.Map[string, *mkvparser.SubtitleEvent]
 	// OnSubtitleFileUploaded is called when a subtitle file is uploaded.
 	OnSubtitleFileUploaded(filename string, content string)
+	// base returns the state shared by all stream types.
+	base() *BaseStream
 }
 
 func (m *Manager) getStreamHandler() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		stream, ok := m.currentStream.Get()
+		stream, ok := m.getStream(r.URL.Query().Get("id"))
 		if !ok {
 			http.Error(w, "no stream", http.StatusInternalServerError)
 			return
@@ -67,23 +71,38 @@ func (m *Manager) getStreamHandler() http.Handler {
 	})
 }
 
+// getStream returns the stream with the given ID.
+// If no ID is given, the stream is returned only if it is the only one being played.
+func (m *Manager) getStream(id string) (ret Stream, found bool) {
+	if id == "" {
+		streams := m.streams.Values()
+		if len(streams) != 1 {
+			return nil, false
+		}
+		return streams[0], true
+	}
+
+	m.streams.Range(func(_ string, stream Stream) bool {
+		if stream.base().id == id {
+			ret, found = stream, true
+			return false
+		}
+		return true
+	})
+	return
+}
+
 func (m *Manager) PrepareNewStream(clientId string, step string) {
 	m.prepareNewStream(clientId, step)
 }
 
 func (m *Manager) prepareNewStream(clientId string, step string) {
-	// Cancel the previous playback
-	if m.playbackCtxCancelFunc != nil {
-		m.Logger.Trace().Msgf("directstream: Cancelling previous playback")
-		m.playbackCtxCancelFunc()
-		m.playbackCtxCancelFunc = nil
-	}
-
-	// Clear the current stream if it exists
-	if stream, ok := m.currentStream.Get(); ok {
-		m.Logger.Debug().Msgf("directstream: Terminating previous stream before preparing new stream")
+	// Clear the previous stream of the client if it exists
+	// Streams of other clients are not affected
+	if stream, ok := m.streams.Get(clientId); ok {
+		m.Logger.Debug().Str("clientId", clientId).Msgf("directstream: Terminating previous stream before preparing new stream")
 		stream.Terminate()
-		m.currentStream = mo.None[Stream]()
+		m.streams.Delete(clientId)
 	}
 
 	m.Logger.Debug().Msgf("directstream: Signaling native player that a new stream is starting")
@@ -91,18 +110,13 @@ func (m *Manager) prepareNewStream(clientId string, step string) {
 	m.nativePlayer.OpenAndAwait(clientId, step)
 }
 
-// loadStream loads a new stream and cancels the previous one.
+// loadStream loads a new stream and cancels the previous one of the same client.
 // Caller should use mutex to lock the manager.
 func (m *Manager) loadStream(stream Stream) {
 	m.prepareNewStream(stream.ClientId(), "Loading stream...")
 
-	m.Logger.Debug().Msgf("directstream: Loading stream")
-	m.currentStream = mo.Some(stream)
-
-	// Create a new context
-	ctx, cancel := context.WithCancel(context.Background())
-	m.playbackCtx = ctx
-	m.playbackCtxCancelFunc = cancel
+	m.Logger.Debug().Str("clientId", stream.ClientId()).Msgf("directstream: Loading stream")
+	m.streams.Set(stream.ClientId(), stream)
 
 	m.Logger.Debug().Msgf("directstream: Loading content type")
 	// Load the content type
@@ -125,6 +139,20 @@ func (m *Manager) loadStream(stream Stream) {
 		return
 	}
 
+	// Get the skip segments, the chapters of the file are used if there are no stored segments
+	skipSegmentsOpts := &skipsegments.GetSegmentsOptions{
+		MediaId:       stream.Media().GetID(),
+		EpisodeNumber: stream.Episode().GetEpisodeNumber(),
+	}
+	if playbackInfo.MkvMetadata != nil {
+		skipSegmentsOpts.Chapters = playbackInfo.MkvMetadata.Chapters
+		skipSegmentsOpts.Duration = playbackInfo.MkvMetadata.Duration
+	}
+	playbackInfo.SkipSegments = m.skipSegmentsManager.GetSegments(skipSegmentsOpts)
+
+	// Select the audio and subtitle tracks matching the track preference of the media
+	playbackInfo.TrackSelection = m.trackPreferenceManager.SelectMkvTracks(stream.Media().GetID(), playbackInfo.MkvMetadata)
+
 	// Shut the mkv parser logger
 	//parser, ok := playbackInfo.MkvMetadataParser.Get()
 	//if ok {
@@ -144,14 +172,11 @@ func (m *Manager) listenToNativePlayerEvents() {
 		for {
 			select {
 			case event := <-m.nativePlayerSubscriber.Events():
-				cs, ok := m.currentStream.Get()
+				cs, ok := m.streams.Get(event.GetClientId())
 				if !ok {
 					continue
 				}
 
-				if event.GetClientId() != "" && event.GetClientId() != cs.ClientId() {
-					continue
-				}
 				switch event := event.(type) {
 				case *nativeplayer.VideoPausedEvent:
 					m.Logger.Debug().Msgf("directstream: Video paused")
@@ -191,11 +216,11 @@ func (m *Manager) listenToNativePlayerEvents() {
 							cs.StreamError(fmt.Errorf("failed to create subtitle reader: %w", err))
 							return
 						}
-						lfStream.StartSubtitleStream(lfStream, m.playbackCtx, subReader, 0)
+						lfStream.StartSubtitleStream(lfStream, lfStream.playbackCtx, subReader, 0)
 					} else if ts, ok := cs.(*TorrentStream); ok {
 						subReader := ts.file.NewReader()
 						subReader.SetResponsive()
-						ts.StartSubtitleStream(ts, m.playbackCtx, subReader, 0)
+						ts.StartSubtitleStream(ts, ts.playbackCtx, subReader, 0)
 					}
 
 					// Discord
@@ -212,7 +237,7 @@ func (m *Manager) listenToNativePlayerEvents() {
 					}
 				case *nativeplayer.VideoErrorEvent:
 					m.Logger.Debug().Msgf("directstream: Video error, Error: %s", event.Error)
-					cs.StreamError(fmt.Errorf(event.Error))
+					cs.StreamError(errors.New(event.Error))
 
 					// Discord
 					if m.discordPresence != nil && !*m.isOffline {
@@ -224,19 +249,32 @@ func (m *Manager) listenToNativePlayerEvents() {
 				case *nativeplayer.VideoTerminatedEvent:
 					m.Logger.Debug().Msgf("directstream: Video terminated")
 					cs.Terminate()
+					_, continuityManager := m.getClientModules(event.GetClientId())
+					continuityManager.EndWatchEvents(NativePlayerName, event.GetClientId())
 
 					// Discord
 					if m.discordPresence != nil && !*m.isOffline {
 						go m.discordPresence.Close()
 					}
 				case *nativeplayer.VideoStatusEvent:
-					_ = m.continuityManager.UpdateWatchHistoryItem(&continuity.UpdateWatchHistoryItemOptions{
+					// The history item and the watch event are keyed by the progress number, like the other players
+					_, continuityManager := m.getClientModules(event.GetClientId())
+					_ = continuityManager.UpdateWatchHistoryItem(&continuity.UpdateWatchHistoryItemOptions{
 						CurrentTime:   event.Status.CurrentTime,
 						Duration:      event.Status.Duration,
 						MediaId:       cs.Media().GetID(),
-						EpisodeNumber: cs.Episode().GetEpisodeNumber(),
+						EpisodeNumber: cs.Episode().GetProgressNumber(),
 						Kind:          continuity.MediastreamKind,
 					})
+					continuityManager.TrackWatchEvent(&continuity.TrackWatchEventOptions{
+						MediaId:       cs.Media().GetID(),
+						EpisodeNumber: cs.Episode().GetProgressNumber(),
+						CurrentTime:   event.Status.CurrentTime,
+						Duration:      event.Status.Duration,
+						Player:        NativePlayerName,
+						Source:        getWatchSource(cs.Type()),
+						ClientId:      event.GetClientId(),
+					})
 
 					// Discord
 					if m.discordPresence != nil && !*m.isOffline {
@@ -246,12 +284,13 @@ func (m *Manager) listenToNativePlayerEvents() {
 					m.Logger.Debug().Msgf("directstream: Video completed")
 
 					if baseStream, ok := cs.(*BaseStream); ok {
+						clientPlatform, _ := m.getClientModules(event.GetClientId())
 						baseStream.updateProgress.Do(func() {
 							mediaId := baseStream.media.GetID()
 							epNum := baseStream.episode.GetProgressNumber()
 							totalEpisodes := baseStream.media.GetTotalEpisodeCount() // total episode count or -1
 
-							_ = baseStream.manager.platform.UpdateEntryProgress(context.Background(), mediaId, epNum, &totalEpisodes)
+							_ = clientPlatform.UpdateEntryProgress(context.Background(), mediaId, epNum, &totalEpisodes)
 						})
 					}
 				}
@@ -260,26 +299,33 @@ func (m *Manager) listenToNativePlayerEvents() {
 	}()
 }
 
-func (m *Manager) unloadStream() {
+// NativePlayerName is the player recorded in the continuity watch event log.
+const NativePlayerName = "native"
+
+func getWatchSource(streamType nativeplayer.StreamType) continuity.WatchSource {
+	switch streamType {
+	case nativeplayer.StreamTypeTorrent:
+		return continuity.TorrentWatchSource
+	case nativeplayer.StreamTypeDebrid:
+		return continuity.DebridWatchSource
+	default:
+		return continuity.LocalWatchSource
+	}
+}
+
+// unloadStream terminates the stream and removes it if it is still the one being played by its client.
+func (m *Manager) unloadStream(stream Stream) {
 	m.playbackMu.Lock()
 	defer m.playbackMu.Unlock()
 
-	m.Logger.Debug().Msg("directstream: Unloading current stream")
+	m.Logger.Debug().Str("clientId", stream.ClientId()).Msg("directstream: Unloading stream")
 
-	// Cancel any existing playback context first
-	if m.playbackCtxCancelFunc != nil {
-		m.Logger.Trace().Msg("directstream: Cancelling playback context")
-		m.playbackCtxCancelFunc()
-		m.playbackCtxCancelFunc = nil
-	}
+	stream.Terminate()
 
-	// Clear the current stream
-	if stream, ok := m.currentStream.Get(); ok {
-		m.Logger.Debug().Msg("directstream: Terminating current stream")
-		stream.Terminate()
+	if current, ok := m.streams.Get(stream.ClientId()); ok && current.base() == stream.base() {
+		m.streams.Delete(stream.ClientId())
 	}
 
-	m.currentStream = mo.None[Stream]()
 	m.Logger.Debug().Msg("directstream: Stream unloaded successfully")
 }
 
@@ -287,6 +333,7 @@ func (m *Manager) unloadStream() {
 
 type BaseStream struct {
 	logger                 *zerolog.Logger
+	id                     string // Identifies the stream in the stream URL
 	clientId               string
 	contentType            string
 	contentTypeOnce        sync.Once
@@ -305,10 +352,33 @@ type BaseStream struct {
 	// Subtitle stream management
 	activeSubtitleStreams *result.Map[string, *SubtitleStream]
 
+	// Cancelled when the stream is terminated
+	playbackCtx           context.Context
+	playbackCtxCancelFunc context.CancelFunc
+
 	manager        *Manager
 	updateProgress sync.Once
 }
 
+// newBaseStream returns the state shared by all stream types.
+func newBaseStream(m *Manager, clientId string) BaseStream {
+	ctx, cancel := context.WithCancel(context.Background())
+	return BaseStream{
+		manager:               m,
+		logger:                m.Logger,
+		id:                    uuid.New().String(),
+		clientId:              clientId,
+		subtitleEventCache:    result.NewResultMap[string, *mkvparser.SubtitleEvent](),
+		activeSubtitleStreams: result.NewResultMap[string, *SubtitleStream](),
+		playbackCtx:           ctx,
+		playbackCtxCancelFunc: cancel,
+	}
+}
+
+func (s *BaseStream) base() *BaseStream {
+	return s
+}
+
 func (s *BaseStream) GetAttachmentByName(filename string) (*mkvparser.AttachmentInfo, bool) {
 	return nil, false
 }
@@ -355,8 +425,8 @@ func (s *BaseStream) Terminate() {
 	s.terminateOnce.Do(func() {
 		// Cancel the playback context
 		// This will snowball and cancel other stuff
-		if s.manager.playbackCtxCancelFunc != nil {
-			s.manager.playbackCtxCancelFunc()
+		if s.playbackCtxCancelFunc != nil {
+			s.playbackCtxCancelFunc()
 		}
 
 		// Cancel all active subtitle streams
@@ -374,7 +444,7 @@ func (s *BaseStream) StreamError(err error) {
 	s.logger.Error().Err(err).Msg("directstream: Stream error occurred")
 	s.manager.nativePlayer.Error(s.clientId, err)
 	s.Terminate()
-	s.manager.unloadStream()
+	s.manager.unloadStream(s)
 }
 
 func (s *BaseStream) GetSubtitleEventCache() *result.Map[string, *mkvparser.SubtitleEvent] {
@@ -421,5 +491,5 @@ func loadContentType(path string, reader ...io.ReadSeekCloser) string {
 func (m *Manager) preStreamError(stream Stream, err error) {
 	stream.Terminate()
 	m.nativePlayer.Error(stream.ClientId(), err)
-	m.unloadStream()
+	m.unloadStream(stream)
 }
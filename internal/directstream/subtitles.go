@@ -6,9 +6,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"seanime/internal/events"
+	"seanime/internal/library/filesystem"
 	"seanime/internal/mkvparser"
+	"seanime/internal/subtitles"
 	"seanime/internal/util"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -287,45 +291,21 @@ func (m *Manager) streamSubtitles(ctx context.Context, stream Stream, parser *mk
 
 // OnSubtitleFileUploaded adds a subtitle track, converts it to ASS if needed.
 func (s *BaseStream) OnSubtitleFileUploaded(filename string, content string) {
-	parser, ok := s.playbackInfo.MkvMetadataParser.Get()
-	if !ok {
+	if s.playbackInfo == nil || s.playbackInfo.MkvMetadata == nil {
 		s.logger.Error().Msg("directstream:A Failed to load playback info")
 		return
 	}
 
 	ext := util.FileExt(filename)
 
-	newContent := content
-	if ext != ".ass" && ext != ".ssa" {
-		var err error
-		var from int
-		switch ext {
-		case ".srt":
-			from = mkvparser.SubtitleTypeSRT
-		case ".vtt":
-			from = mkvparser.SubtitleTypeWEBVTT
-		case ".ttml":
-			from = mkvparser.SubtitleTypeTTML
-		case ".stl":
-			from = mkvparser.SubtitleTypeSTL
-		case ".txt":
-			from = mkvparser.SubtitleTypeUnknown
-		default:
-			err = errors.New("unsupported subtitle format")
-		}
-		s.logger.Debug().
-			Str("filename", filename).
-			Str("ext", ext).
-			Int("detected", from).
-			Msg("directstream: Converting uploaded subtitle file")
-		newContent, err = mkvparser.ConvertToASS(content, from)
-		if err != nil {
-			s.manager.wsEventManager.SendEventTo(s.clientId, events.ErrorToast, "Failed to convert subtitle file: "+err.Error())
-			return
-		}
+	newContent, err := s.convertSubtitleFileToASS(filename, content)
+	if err != nil {
+		s.manager.wsEventManager.SendEventTo(s.clientId, events.ErrorToast, "Failed to convert subtitle file: "+err.Error())
+		return
 	}
 
-	metadata := parser.GetMetadata(context.Background())
+	// Use the metadata sent to the player, it can contain external subtitle tracks
+	metadata := s.playbackInfo.MkvMetadata
 	num := int64(len(metadata.Tracks)) + 1
 	subtitleNum := int64(len(metadata.SubtitleTracks))
 
@@ -374,3 +354,87 @@ func (s *BaseStream) OnSubtitleFileUploaded(filename string, content string) {
 
 	s.manager.nativePlayer.AddSubtitleTrack(s.clientId, track)
 }
+
+// convertSubtitleFileToASS converts the content of a subtitle file to ASS.
+func (s *BaseStream) convertSubtitleFileToASS(filename string, content string) (string, error) {
+	ext := util.FileExt(filename)
+	if ext == ".ass" || ext == ".ssa" {
+		return content, nil
+	}
+
+	var from int
+	switch ext {
+	case ".srt":
+		from = mkvparser.SubtitleTypeSRT
+	case ".vtt":
+		from = mkvparser.SubtitleTypeWEBVTT
+	case ".ttml":
+		from = mkvparser.SubtitleTypeTTML
+	case ".stl":
+		from = mkvparser.SubtitleTypeSTL
+	case ".txt":
+		from = mkvparser.SubtitleTypeUnknown
+	default:
+		return "", errors.New("unsupported subtitle format")
+	}
+	s.logger.Debug().
+		Str("filename", filename).
+		Str("ext", ext).
+		Int("detected", from).
+		Msg("directstream: Converting uploaded subtitle file")
+
+	switch ext {
+	case ".srt", ".vtt":
+		// keeps the basic styling and the positioning of the cues
+		return subtitles.Convert(content, subtitles.FormatFromExtension(ext), subtitles.FormatASS)
+	}
+	return mkvparser.ConvertToASS(content, from)
+}
+
+// addExternalSubtitleTracks adds the external subtitle files of a local file to the metadata as ASS tracks.
+// The metadata is copied since it is shared through the parser cache.
+func (s *BaseStream) addExternalSubtitleTracks(metadata *mkvparser.Metadata, externalSubs []*filesystem.ExternalSubtitle) *mkvparser.Metadata {
+	if len(externalSubs) == 0 {
+		return metadata
+	}
+
+	ret := *metadata
+	ret.Tracks = slices.Clone(metadata.Tracks)
+	ret.SubtitleTracks = slices.Clone(metadata.SubtitleTracks)
+
+	for _, sub := range externalSubs {
+		content, err := os.ReadFile(sub.Path)
+		if err != nil {
+			s.logger.Warn().Err(err).Str("path", sub.Path).Msg("directstream: Failed to read external subtitle file")
+			continue
+		}
+
+		assContent, err := s.convertSubtitleFileToASS(sub.Path, string(content))
+		if err != nil {
+			s.logger.Warn().Err(err).Str("path", sub.Path).Msg("directstream: Failed to convert external subtitle file")
+			continue
+		}
+
+		num := int64(len(ret.Tracks)) + 1
+		track := &mkvparser.TrackInfo{
+			Number:       num,
+			UID:          num + 900,
+			Type:         mkvparser.TrackTypeSubtitle,
+			CodecID:      "S_TEXT/ASS",
+			Name:         sub.Title,
+			Language:     cmp.Or(sub.Language, "und"),
+			LanguageIETF: cmp.Or(sub.Language, "und"),
+			Default:      false,
+			Forced:       sub.Forced,
+			Enabled:      true,
+			CodecPrivate: assContent,
+		}
+
+		ret.Tracks = append(ret.Tracks, track)
+		ret.SubtitleTracks = append(ret.SubtitleTracks, track)
+	}
+
+	s.logger.Debug().Int("count", len(ret.SubtitleTracks)-len(metadata.SubtitleTracks)).Msg("directstream: Added external subtitle tracks")
+
+	return &ret
+}
@@ -11,12 +11,10 @@ import (
 	"seanime/internal/mkvparser"
 	"seanime/internal/nativeplayer"
 	httputil "seanime/internal/util/http"
-	"seanime/internal/util/result"
 	"seanime/internal/util/torrentutil"
 	"time"
 
 	"github.com/anacrolix/torrent"
-	"github.com/google/uuid"
 	"github.com/samber/mo"
 )
 
@@ -57,7 +55,7 @@ func (s *TorrentStream) LoadPlaybackInfo() (ret *nativeplayer.PlaybackInfo, err
 			return
 		}
 
-		id := uuid.New().String()
+		id := s.id
 
 		var entryListData *anime.EntryListData
 		if animeCollection, ok := s.manager.animeCollection.Get(); ok {
@@ -105,7 +103,7 @@ func (s *TorrentStream) LoadPlaybackInfo() (ret *nativeplayer.PlaybackInfo, err
 }
 
 func (s *TorrentStream) GetAttachmentByName(filename string) (*mkvparser.AttachmentInfo, bool) {
-	return getAttachmentByName(s.manager.playbackCtx, s, filename)
+	return getAttachmentByName(s.playbackCtx, s, filename)
 }
 
 func (s *TorrentStream) GetStreamHandler() http.Handler {
@@ -164,10 +162,10 @@ func (s *TorrentStream) GetStreamHandler() http.Handler {
 			// Start a subtitle stream from the current position
 			subReader := file.NewReader()
 			subReader.SetResponsive()
-			s.StartSubtitleStream(s, s.manager.playbackCtx, subReader, ranges[0].Start)
+			s.StartSubtitleStream(s, s.playbackCtx, subReader, ranges[0].Start)
 		}
 
-		serveTorrent(w, r, s.manager.playbackCtx, tr, file.DisplayPath(), file.Length(), s.LoadContentType(), ranges)
+		serveTorrent(w, r, s.playbackCtx, tr, file.DisplayPath(), file.Length(), s.LoadContentType(), ranges)
 	})
 }
 
@@ -201,21 +199,15 @@ func (m *Manager) PlayTorrentStream(ctx context.Context, opts PlayTorrentStreamO
 	}
 
 	stream := &TorrentStream{
-		torrent: opts.Torrent,
-		file:    opts.File,
-		BaseStream: BaseStream{
-			manager:               m,
-			logger:                m.Logger,
-			clientId:              opts.ClientId,
-			media:                 opts.Media,
-			filename:              filepath.Base(opts.File.DisplayPath()),
-			episode:               episode,
-			episodeCollection:     episodeCollection,
-			subtitleEventCache:    result.NewResultMap[string, *mkvparser.SubtitleEvent](),
-			activeSubtitleStreams: result.NewResultMap[string, *SubtitleStream](),
-		},
+		torrent:       opts.Torrent,
+		file:          opts.File,
+		BaseStream:    newBaseStream(m, opts.ClientId),
 		streamReadyCh: make(chan struct{}),
 	}
+	stream.media = opts.Media
+	stream.filename = filepath.Base(opts.File.DisplayPath())
+	stream.episode = episode
+	stream.episodeCollection = episodeCollection
 
 	go func() {
 		<-stream.streamReadyCh
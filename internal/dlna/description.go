@@ -0,0 +1,165 @@
+package dlna
+
+import (
+	"fmt"
+	"html"
+	"maps"
+	"slices"
+)
+
+const (
+	deviceType            = "urn:schemas-upnp-org:device:MediaServer:1"
+	contentDirectoryType  = "urn:schemas-upnp-org:service:ContentDirectory:1"
+	connectionManagerType = "urn:schemas-upnp-org:service:ConnectionManager:1"
+)
+
+const (
+	ServiceContentDirectory  = "ContentDirectory"
+	ServiceConnectionManager = "ConnectionManager"
+)
+
+// deviceDescription returns the device description document.
+// The URLs are relative to the URL of the document.
+func deviceDescription(uuid string, friendlyName string, version string) string {
+	service := func(typ string, name string) string {
+		return `<service>` +
+			`<serviceType>` + typ + `</serviceType>` +
+			`<serviceId>urn:upnp-org:serviceId:` + name + `</serviceId>` +
+			`<SCPDURL>` + name + `/scpd.xml</SCPDURL>` +
+			`<controlURL>` + name + `/control</controlURL>` +
+			`<eventSubURL>` + name + `/event</eventSubURL>` +
+			`</service>`
+	}
+
+	return `<?xml version="1.0" encoding="utf-8"?>` +
+		`<root xmlns="urn:schemas-upnp-org:device-1-0" xmlns:dlna="urn:schemas-dlna-org:device-1-0">` +
+		`<specVersion><major>1</major><minor>0</minor></specVersion>` +
+		`<device>` +
+		`<deviceType>` + deviceType + `</deviceType>` +
+		`<friendlyName>` + html.EscapeString(friendlyName) + `</friendlyName>` +
+		`<manufacturer>Seanime</manufacturer>` +
+		`<manufacturerURL>https://seanime.rahim.app</manufacturerURL>` +
+		`<modelName>Seanime</modelName>` +
+		`<modelNumber>` + html.EscapeString(version) + `</modelNumber>` +
+		`<UDN>uuid:` + uuid + `</UDN>` +
+		`<dlna:X_DLNADOC>DMS-1.50</dlna:X_DLNADOC>` +
+		`<serviceList>` +
+		service(contentDirectoryType, ServiceContentDirectory) +
+		service(connectionManagerType, ServiceConnectionManager) +
+		`</serviceList>` +
+		`</device>` +
+		`</root>`
+}
+
+type scpdArgument struct {
+	name      string
+	direction string
+	variable  string
+}
+
+type scpdVariable struct {
+	name     string
+	dataType string
+	// Whether the variable sends events
+	events bool
+	// Allowed values, if any
+	values []string
+}
+
+// scpd returns a service description document.
+func scpd(actions map[string][]scpdArgument, variables []scpdVariable) string {
+	ret := `<?xml version="1.0" encoding="utf-8"?>` +
+		`<scpd xmlns="urn:schemas-upnp-org:service-1-0">` +
+		`<specVersion><major>1</major><minor>0</minor></specVersion>` +
+		`<actionList>`
+	for _, name := range slices.Sorted(maps.Keys(actions)) {
+		ret += `<action><name>` + name + `</name><argumentList>`
+		for _, arg := range actions[name] {
+			ret += fmt.Sprintf(`<argument><name>%s</name><direction>%s</direction><relatedStateVariable>%s</relatedStateVariable></argument>`, arg.name, arg.direction, arg.variable)
+		}
+		ret += `</argumentList></action>`
+	}
+	ret += `</actionList><serviceStateTable>`
+	for _, v := range variables {
+		sendEvents := "no"
+		if v.events {
+			sendEvents = "yes"
+		}
+		ret += fmt.Sprintf(`<stateVariable sendEvents="%s"><name>%s</name><dataType>%s</dataType>`, sendEvents, v.name, v.dataType)
+		if len(v.values) > 0 {
+			ret += `<allowedValueList>`
+			for _, value := range v.values {
+				ret += `<allowedValue>` + value + `</allowedValue>`
+			}
+			ret += `</allowedValueList>`
+		}
+		ret += `</stateVariable>`
+	}
+	ret += `</serviceStateTable></scpd>`
+	return ret
+}
+
+var contentDirectoryScpd = scpd(
+	map[string][]scpdArgument{
+		"Browse": {
+			{"ObjectID", "in", "A_ARG_TYPE_ObjectID"},
+			{"BrowseFlag", "in", "A_ARG_TYPE_BrowseFlag"},
+			{"Filter", "in", "A_ARG_TYPE_Filter"},
+			{"StartingIndex", "in", "A_ARG_TYPE_Index"},
+			{"RequestedCount", "in", "A_ARG_TYPE_Count"},
+			{"SortCriteria", "in", "A_ARG_TYPE_SortCriteria"},
+			{"Result", "out", "A_ARG_TYPE_Result"},
+			{"NumberReturned", "out", "A_ARG_TYPE_Count"},
+			{"TotalMatches", "out", "A_ARG_TYPE_Count"},
+			{"UpdateID", "out", "A_ARG_TYPE_UpdateID"},
+		},
+		"GetSearchCapabilities": {{"SearchCaps", "out", "SearchCapabilities"}},
+		"GetSortCapabilities":   {{"SortCaps", "out", "SortCapabilities"}},
+		"GetSystemUpdateID":     {{"Id", "out", "SystemUpdateID"}},
+	},
+	[]scpdVariable{
+		{name: "A_ARG_TYPE_ObjectID", dataType: "string"},
+		{name: "A_ARG_TYPE_BrowseFlag", dataType: "string", values: []string{"BrowseMetadata", "BrowseDirectChildren"}},
+		{name: "A_ARG_TYPE_Filter", dataType: "string"},
+		{name: "A_ARG_TYPE_Index", dataType: "ui4"},
+		{name: "A_ARG_TYPE_Count", dataType: "ui4"},
+		{name: "A_ARG_TYPE_SortCriteria", dataType: "string"},
+		{name: "A_ARG_TYPE_Result", dataType: "string"},
+		{name: "A_ARG_TYPE_UpdateID", dataType: "ui4"},
+		{name: "SearchCapabilities", dataType: "string"},
+		{name: "SortCapabilities", dataType: "string"},
+		{name: "SystemUpdateID", dataType: "ui4", events: true},
+	},
+)
+
+var connectionManagerScpd = scpd(
+	map[string][]scpdArgument{
+		"GetProtocolInfo": {
+			{"Source", "out", "SourceProtocolInfo"},
+			{"Sink", "out", "SinkProtocolInfo"},
+		},
+		"GetCurrentConnectionIDs": {{"ConnectionIDs", "out", "CurrentConnectionIDs"}},
+		"GetCurrentConnectionInfo": {
+			{"ConnectionID", "in", "A_ARG_TYPE_ConnectionID"},
+			{"RcsID", "out", "A_ARG_TYPE_RcsID"},
+			{"AVTransportID", "out", "A_ARG_TYPE_AVTransportID"},
+			{"ProtocolInfo", "out", "A_ARG_TYPE_ProtocolInfo"},
+			{"PeerConnectionManager", "out", "A_ARG_TYPE_ConnectionManager"},
+			{"PeerConnectionID", "out", "A_ARG_TYPE_ConnectionID"},
+			{"Direction", "out", "A_ARG_TYPE_Direction"},
+			{"Status", "out", "A_ARG_TYPE_ConnectionStatus"},
+		},
+	},
+	[]scpdVariable{
+		{name: "SourceProtocolInfo", dataType: "string", events: true},
+		{name: "SinkProtocolInfo", dataType: "string", events: true},
+		{name: "CurrentConnectionIDs", dataType: "string", events: true},
+		{name: "A_ARG_TYPE_ConnectionStatus", dataType: "string", values: []string{"OK", "ContentFormatMismatch", "InsufficientBandwidth", "UnreliableChannel", "Unknown"}},
+		{name: "A_ARG_TYPE_ConnectionManager", dataType: "string"},
+		{name: "A_ARG_TYPE_Direction", dataType: "string", values: []string{"Input", "Output"}},
+		{name: "A_ARG_TYPE_ProtocolInfo", dataType: "string"},
+		{name: "A_ARG_TYPE_ConnectionID", dataType: "i4"},
+		{name: "A_ARG_TYPE_AVTransportID", dataType: "i4"},
+		{name: "A_ARG_TYPE_RcsID", dataType: "i4"},
+	},
+)
@@ -0,0 +1,71 @@
+package dlna
+
+import (
+	"fmt"
+	"html"
+	"mime"
+	"path/filepath"
+	"strings"
+)
+
+// contentFeatures is the DLNA.ORG parameters of the served files.
+//   - OP=01: Byte range seeking is supported
+//   - CI=0: The file is not transcoded
+//   - FLAGS: Streaming transfer mode, background transfer mode, connection stalling, DLNA v1.5
+const contentFeatures = "DLNA.ORG_OP=01;DLNA.ORG_CI=0;DLNA.ORG_FLAGS=01700000000000000000000000000000"
+
+// contentType returns the MIME type of a video file.
+func contentType(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	switch ext {
+	case ".mkv":
+		return "video/x-matroska"
+	case ".mp4", ".m4v":
+		return "video/mp4"
+	case ".avi":
+		return "video/x-msvideo"
+	case ".ts", ".m2ts":
+		return "video/mp2t"
+	case ".webm":
+		return "video/webm"
+	}
+	if t := mime.TypeByExtension(ext); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// didl returns the DIDL-Lite document of the objects.
+//   - mediaUrl: Returns the URL of a file
+//   - fileSize: Returns the size of a file, 0 if unknown
+func didl(objects []*object, mediaUrl func(f *libraryFile) string, fileSize func(f *libraryFile) int64) string {
+	var b strings.Builder
+	b.WriteString(`<DIDL-Lite xmlns="urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:upnp="urn:schemas-upnp-org:metadata-1-0/upnp/" xmlns:dlna="urn:schemas-dlna-org:metadata-1-0/">`)
+	for _, obj := range objects {
+		if obj.File == nil {
+			fmt.Fprintf(&b, `<container id="%s" parentID="%s" restricted="1" searchable="0" childCount="%d">`, html.EscapeString(obj.ID), html.EscapeString(obj.ParentID), obj.ChildCount)
+			fmt.Fprintf(&b, `<dc:title>%s</dc:title>`, html.EscapeString(obj.Title))
+			b.WriteString(`<upnp:class>object.container.storageFolder</upnp:class>`)
+			if obj.AlbumArt != "" {
+				fmt.Fprintf(&b, `<upnp:albumArtURI>%s</upnp:albumArtURI>`, html.EscapeString(obj.AlbumArt))
+			}
+			b.WriteString(`</container>`)
+			continue
+		}
+
+		fmt.Fprintf(&b, `<item id="%s" parentID="%s" restricted="1">`, html.EscapeString(obj.ID), html.EscapeString(obj.ParentID))
+		fmt.Fprintf(&b, `<dc:title>%s</dc:title>`, html.EscapeString(obj.Title))
+		b.WriteString(`<upnp:class>object.item.videoItem</upnp:class>`)
+		if obj.AlbumArt != "" {
+			fmt.Fprintf(&b, `<upnp:albumArtURI>%s</upnp:albumArtURI>`, html.EscapeString(obj.AlbumArt))
+		}
+		b.WriteString(`<res protocolInfo="http-get:*:` + contentType(obj.File.LocalFile.Path) + `:` + contentFeatures + `"`)
+		if size := fileSize(obj.File); size > 0 {
+			fmt.Fprintf(&b, ` size="%d"`, size)
+		}
+		b.WriteString(`>` + html.EscapeString(mediaUrl(obj.File)) + `</res>`)
+		b.WriteString(`</item>`)
+	}
+	b.WriteString(`</DIDL-Lite>`)
+	return b.String()
+}
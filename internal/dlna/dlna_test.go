@@ -0,0 +1,183 @@
+package dlna
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"seanime/internal/api/anilist"
+	"seanime/internal/library/anime"
+	"seanime/internal/util"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLibrary() (*library, []*anime.LocalFile) {
+	lfs := []*anime.LocalFile{
+		{Path: "/anime/Frieren/Frieren - 02.mkv", Name: "Frieren - 02.mkv", MediaId: 154587, Metadata: &anime.LocalFileMetadata{Episode: 2, Type: anime.LocalFileTypeMain}},
+		{Path: "/anime/Frieren/Frieren - 01.mkv", Name: "Frieren - 01.mkv", MediaId: 154587, Metadata: &anime.LocalFileMetadata{Episode: 1, Type: anime.LocalFileTypeMain}},
+		{Path: "/anime/Frieren/Frieren - NCOP.mkv", Name: "Frieren - NCOP.mkv", MediaId: 154587, Metadata: &anime.LocalFileMetadata{Type: anime.LocalFileTypeNC}},
+		{Path: "/anime/Bocchi/Bocchi - 01.mp4", Name: "Bocchi - 01.mp4", MediaId: 130003, Metadata: &anime.LocalFileMetadata{Episode: 1, Type: anime.LocalFileTypeMain}},
+		// Not in the collection
+		{Path: "/anime/Unknown/Unknown - 01.mkv", Name: "Unknown - 01.mkv", MediaId: 1, Metadata: &anime.LocalFileMetadata{Episode: 1, Type: anime.LocalFileTypeMain}},
+	}
+
+	collection := &anime.LibraryCollection{
+		Lists: []*anime.LibraryCollectionList{
+			{
+				Entries: []*anime.LibraryCollectionEntry{
+					{MediaId: 154587, Media: &anilist.BaseAnime{ID: 154587, Title: &anilist.BaseAnime_Title{UserPreferred: lo.ToPtr("Sousou no Frieren")}}, EntryListData: &anime.EntryListData{Progress: 1}},
+					{MediaId: 130003, Media: &anilist.BaseAnime{ID: 130003, Title: &anilist.BaseAnime_Title{UserPreferred: lo.ToPtr("Bocchi the Rock!")}}},
+				},
+			},
+		},
+	}
+
+	return newLibrary(collection, lfs), lfs
+}
+
+func TestLibrary(t *testing.T) {
+	lib, _ := testLibrary()
+
+	root, found := lib.getChildren(rootId)
+	require.True(t, found)
+	require.Len(t, root, 2)
+	assert.Equal(t, "Bocchi the Rock!", root[0].Title)
+	assert.Equal(t, "anime/154587", root[1].ID)
+	assert.Equal(t, 3, root[1].ChildCount)
+
+	episodes, found := lib.getChildren("anime/154587")
+	require.True(t, found)
+	require.Len(t, episodes, 3)
+	assert.Equal(t, "Others", episodes[0].Title)
+	assert.Equal(t, "Episode 1", episodes[1].Title)
+	assert.Equal(t, "Episode 2", episodes[2].Title)
+	assert.Equal(t, "anime/154587", episodes[1].ParentID)
+
+	others, found := lib.getChildren("anime/154587/others")
+	require.True(t, found)
+	require.Len(t, others, 1)
+	assert.Equal(t, "Frieren - NCOP", others[0].Title)
+
+	obj, found := lib.getObject(episodes[2].ID)
+	require.True(t, found)
+	assert.Equal(t, "/anime/Frieren/Frieren - 02.mkv", obj.File.LocalFile.Path)
+
+	_, found = lib.getObject("anime/1")
+	assert.False(t, found)
+	_, found = lib.getChildren("anime/154587/specials")
+	assert.False(t, found)
+}
+
+func TestBrowse(t *testing.T) {
+	lib, _ := testLibrary()
+
+	s := NewServer(&NewServerOptions{Logger: util.NewLogger()})
+	s.settings = &Settings{Enabled: true}
+	s.library = lib
+	s.libraryUpdatedAt = time.Now()
+	s.systemUpdateId = 3
+
+	body := `<?xml version="1.0" encoding="utf-8"?>` +
+		`<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/"><s:Body>` +
+		`<u:Browse xmlns:u="urn:schemas-upnp-org:service:ContentDirectory:1">` +
+		`<ObjectID>anime/154587</ObjectID><BrowseFlag>BrowseDirectChildren</BrowseFlag><Filter>*</Filter>` +
+		`<StartingIndex>1</StartingIndex><RequestedCount>1</RequestedCount><SortCriteria></SortCriteria>` +
+		`</u:Browse></s:Body></s:Envelope>`
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "http://192.168.1.10:43211/dlna/ContentDirectory/control", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	err := s.ServeControl(e.NewContext(req, rec), ServiceContentDirectory)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	action, args, err := parseSoapRequest(rec.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "BrowseResponse", action)
+	assert.Equal(t, "1", args["NumberReturned"])
+	assert.Equal(t, "3", args["TotalMatches"])
+	assert.Equal(t, "3", args["UpdateID"])
+	assert.Contains(t, args["Result"], `<dc:title>Episode 1</dc:title>`)
+	assert.Contains(t, args["Result"], `protocolInfo="http-get:*:video/x-matroska:DLNA.ORG_OP=01`)
+	assert.Contains(t, args["Result"], `>http://192.168.1.10:43211/dlna/media/`+fileId("/anime/Frieren/Frieren - 01.mkv")+`.mkv</res>`)
+
+	// Unknown object
+	req = httptest.NewRequest(http.MethodPost, "/dlna/ContentDirectory/control", strings.NewReader(strings.Replace(body, "anime/154587", "anime/2", 1)))
+	rec = httptest.NewRecorder()
+	err = s.ServeControl(e.NewContext(req, rec), ServiceContentDirectory)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, rec.Body.String(), "<errorCode>701</errorCode>")
+}
+
+func TestPlayTracker(t *testing.T) {
+	tracker := newPlayTracker()
+
+	// Renderers usually fetch the end of the file first
+	assert.False(t, tracker.add("a", 1000, 900, 100))
+	assert.False(t, tracker.add("a", 1000, 0, 300))
+	// Overlapping range
+	assert.False(t, tracker.add("a", 1000, 200, 400))
+	assert.False(t, tracker.add("a", 1000, 550, 100))
+	assert.Equal(t, [][2]int64{{0, 650}, {900, 1000}}, tracker.files["a"].ranges)
+
+	assert.True(t, tracker.add("a", 1000, 600, 200))
+	// Only reported once
+	assert.False(t, tracker.add("a", 1000, 0, 1000))
+
+	assert.False(t, tracker.add("b", 1000, 0, 500))
+}
+
+func TestRangeStart(t *testing.T) {
+	assert.Equal(t, int64(0), rangeStart("", 1000))
+	assert.Equal(t, int64(200), rangeStart("bytes=200-", 1000))
+	assert.Equal(t, int64(200), rangeStart("bytes=200-499", 1000))
+	assert.Equal(t, int64(900), rangeStart("bytes=-100", 1000))
+	assert.Equal(t, int64(10), rangeStart("bytes=10-20, 40-50", 1000))
+}
+
+func TestParseSearchRequest(t *testing.T) {
+	st, mx, ok := parseSearchRequest([]byte("M-SEARCH * HTTP/1.1\r\nHOST: 239.255.255.250:1900\r\nMAN: \"ssdp:discover\"\r\nMX: 2\r\nST: urn:schemas-upnp-org:device:MediaServer:1\r\n\r\n"))
+	require.True(t, ok)
+	assert.Equal(t, deviceType, st)
+	assert.Equal(t, 2, mx)
+
+	_, _, ok = parseSearchRequest([]byte("NOTIFY * HTTP/1.1\r\nHOST: 239.255.255.250:1900\r\nNT: upnp:rootdevice\r\nNTS: ssdp:alive\r\n\r\n"))
+	assert.False(t, ok)
+}
+
+func TestIsLocalRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		expected   bool
+	}{
+		{name: "loopback", remoteAddr: "127.0.0.1:5000", expected: true},
+		{name: "private", remoteAddr: "192.168.1.20:5000", expected: true},
+		{name: "private ipv6", remoteAddr: "[fd00::1]:5000", expected: true},
+		{name: "link-local", remoteAddr: "169.254.10.1:5000", expected: true},
+		{name: "public", remoteAddr: "203.0.113.5:5000", expected: false},
+		{name: "proxied public", remoteAddr: "127.0.0.1:5000", headers: map[string]string{"X-Forwarded-For": "203.0.113.5, 127.0.0.1"}, expected: false},
+		{name: "proxied private", remoteAddr: "127.0.0.1:5000", headers: map[string]string{"X-Forwarded-For": "192.168.1.20"}, expected: true},
+		{name: "real ip", remoteAddr: "127.0.0.1:5000", headers: map[string]string{"X-Real-Ip": "203.0.113.5"}, expected: false},
+		{name: "forwarded", remoteAddr: "127.0.0.1:5000", headers: map[string]string{"Forwarded": `for="[2001:db8::1]:4711";proto=https`}, expected: false},
+		{name: "forwarded private", remoteAddr: "127.0.0.1:5000", headers: map[string]string{"Forwarded": "for=10.0.0.2"}, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/dlna/device.xml", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			assert.Equal(t, tt.expected, IsLocalRequest(req))
+		})
+	}
+}
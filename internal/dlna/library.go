@@ -0,0 +1,295 @@
+package dlna
+
+import (
+	"cmp"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"seanime/internal/api/anilist"
+	"seanime/internal/library/anime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Object IDs of the content directory:
+//   - "0": Root, lists the anime of the library
+//   - "anime/<mediaId>": Episodes of an anime, with the specials and other files in sub-containers
+//   - "anime/<mediaId>/specials", "anime/<mediaId>/others"
+//   - "file/<fileId>": A library file
+const (
+	rootId        = "0"
+	animePrefix   = "anime/"
+	filePrefix    = "file/"
+	specialsChild = "specials"
+	othersChild   = "others"
+)
+
+type (
+	// library is a snapshot of the library collection used to answer Browse requests.
+	library struct {
+		entries     []*libraryEntry
+		entriesById map[int]*libraryEntry
+		files       map[string]*libraryFile
+	}
+
+	libraryEntry struct {
+		Media    *anilist.BaseAnime
+		ListData *anime.EntryListData
+		// Used to get the progress number of main episodes
+		LocalEntry *anime.LocalFileWrapperEntry
+		Episodes   []*libraryFile
+		Specials   []*libraryFile
+		Others     []*libraryFile
+	}
+
+	libraryFile struct {
+		ID        string
+		LocalFile *anime.LocalFile
+		Entry     *libraryEntry
+	}
+
+	// object is a container or an item of the content directory.
+	object struct {
+		ID         string
+		ParentID   string
+		Title      string
+		ChildCount int
+		// Nil for containers
+		File *libraryFile
+		// Cover image of the anime
+		AlbumArt string
+	}
+)
+
+// newLibrary groups the local files of the collection by anime.
+// Files whose media is not in the collection are not included.
+func newLibrary(collection *anime.LibraryCollection, lfs []*anime.LocalFile) *library {
+	ret := &library{
+		entries:     make([]*libraryEntry, 0),
+		entriesById: make(map[int]*libraryEntry),
+		files:       make(map[string]*libraryFile),
+	}
+
+	if collection == nil {
+		return ret
+	}
+
+	for _, list := range collection.Lists {
+		for _, e := range list.Entries {
+			if e.Media == nil {
+				continue
+			}
+			if _, found := ret.entriesById[e.MediaId]; found {
+				continue
+			}
+			entry := &libraryEntry{
+				Media:    e.Media,
+				ListData: e.EntryListData,
+			}
+			ret.entriesById[e.MediaId] = entry
+		}
+	}
+
+	lfWrapper := anime.NewLocalFileWrapper(lfs)
+	for _, lf := range lfs {
+		entry, found := ret.entriesById[lf.MediaId]
+		if !found || lf.IsIgnored() || lf.Metadata == nil {
+			continue
+		}
+		f := &libraryFile{
+			ID:        fileId(lf.Path),
+			LocalFile: lf,
+			Entry:     entry,
+		}
+		switch lf.GetType() {
+		case anime.LocalFileTypeMain:
+			entry.Episodes = append(entry.Episodes, f)
+		case anime.LocalFileTypeSpecial:
+			entry.Specials = append(entry.Specials, f)
+		default:
+			entry.Others = append(entry.Others, f)
+		}
+		ret.files[f.ID] = f
+	}
+
+	for mId, entry := range ret.entriesById {
+		if len(entry.Episodes)+len(entry.Specials)+len(entry.Others) == 0 {
+			delete(ret.entriesById, mId)
+			continue
+		}
+		entry.LocalEntry, _ = lfWrapper.GetLocalEntryById(mId)
+		sortFiles(entry.Episodes)
+		sortFiles(entry.Specials)
+		sortFiles(entry.Others)
+		ret.entries = append(ret.entries, entry)
+	}
+
+	slices.SortFunc(ret.entries, func(a, b *libraryEntry) int {
+		return cmp.Compare(strings.ToLower(a.Media.GetPreferredTitle()), strings.ToLower(b.Media.GetPreferredTitle()))
+	})
+
+	return ret
+}
+
+func sortFiles(files []*libraryFile) {
+	slices.SortFunc(files, func(a, b *libraryFile) int {
+		if c := cmp.Compare(a.LocalFile.GetEpisodeNumber(), b.LocalFile.GetEpisodeNumber()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.LocalFile.Path, b.LocalFile.Path)
+	})
+}
+
+// fileId returns a stable ID for a file path.
+func fileId(path string) string {
+	sum := sha1.Sum([]byte(path))
+	return hex.EncodeToString(sum[:8])
+}
+
+// getObject returns the object with the given ID.
+func (l *library) getObject(id string) (*object, bool) {
+	if id == rootId {
+		return &object{ID: rootId, ParentID: "-1", Title: "Anime", ChildCount: len(l.entries)}, true
+	}
+
+	if fId, ok := strings.CutPrefix(id, filePrefix); ok {
+		f, found := l.files[fId]
+		if !found {
+			return nil, false
+		}
+		return l.fileObject(f), true
+	}
+
+	entry, child, found := l.getEntry(id)
+	if !found {
+		return nil, false
+	}
+	switch child {
+	case "":
+		return l.entryObject(entry), true
+	case specialsChild:
+		if len(entry.Specials) == 0 {
+			return nil, false
+		}
+		return &object{ID: id, ParentID: entryObjectId(entry), Title: "Specials", ChildCount: len(entry.Specials), AlbumArt: entry.Media.GetCoverImageSafe()}, true
+	case othersChild:
+		if len(entry.Others) == 0 {
+			return nil, false
+		}
+		return &object{ID: id, ParentID: entryObjectId(entry), Title: "Others", ChildCount: len(entry.Others), AlbumArt: entry.Media.GetCoverImageSafe()}, true
+	}
+	return nil, false
+}
+
+// getChildren returns the children of the container with the given ID.
+func (l *library) getChildren(id string) ([]*object, bool) {
+	if id == rootId {
+		ret := make([]*object, 0, len(l.entries))
+		for _, entry := range l.entries {
+			ret = append(ret, l.entryObject(entry))
+		}
+		return ret, true
+	}
+
+	if _, found := l.getObject(id); !found {
+		return nil, false
+	}
+	entry, child, found := l.getEntry(id)
+	if !found {
+		return nil, false
+	}
+
+	var files []*libraryFile
+	ret := make([]*object, 0)
+	switch child {
+	case "":
+		if len(entry.Specials) > 0 {
+			obj, _ := l.getObject(id + "/" + specialsChild)
+			ret = append(ret, obj)
+		}
+		if len(entry.Others) > 0 {
+			obj, _ := l.getObject(id + "/" + othersChild)
+			ret = append(ret, obj)
+		}
+		files = entry.Episodes
+	case specialsChild:
+		files = entry.Specials
+	case othersChild:
+		files = entry.Others
+	default:
+		return nil, false
+	}
+
+	for _, f := range files {
+		ret = append(ret, l.fileObject(f))
+	}
+	return ret, true
+}
+
+// getEntry parses an "anime/<mediaId>[/<child>]" object ID.
+func (l *library) getEntry(id string) (entry *libraryEntry, child string, found bool) {
+	rest, ok := strings.CutPrefix(id, animePrefix)
+	if !ok {
+		return nil, "", false
+	}
+	mIdStr, child, _ := strings.Cut(rest, "/")
+	mId, err := strconv.Atoi(mIdStr)
+	if err != nil {
+		return nil, "", false
+	}
+	entry, found = l.entriesById[mId]
+	return entry, child, found
+}
+
+func entryObjectId(entry *libraryEntry) string {
+	return animePrefix + strconv.Itoa(entry.Media.GetID())
+}
+
+func (l *library) entryObject(entry *libraryEntry) *object {
+	childCount := len(entry.Episodes)
+	if len(entry.Specials) > 0 {
+		childCount++
+	}
+	if len(entry.Others) > 0 {
+		childCount++
+	}
+	return &object{
+		ID:         entryObjectId(entry),
+		ParentID:   rootId,
+		Title:      entry.Media.GetPreferredTitle(),
+		ChildCount: childCount,
+		AlbumArt:   entry.Media.GetCoverImageSafe(),
+	}
+}
+
+func (l *library) fileObject(f *libraryFile) *object {
+	parentId := entryObjectId(f.Entry)
+	switch f.LocalFile.GetType() {
+	case anime.LocalFileTypeMain:
+	case anime.LocalFileTypeSpecial:
+		parentId += "/" + specialsChild
+	default:
+		parentId += "/" + othersChild
+	}
+	return &object{
+		ID:       filePrefix + f.ID,
+		ParentID: parentId,
+		Title:    fileTitle(f.LocalFile),
+		File:     f,
+		AlbumArt: f.Entry.Media.GetCoverImageSafe(),
+	}
+}
+
+// fileTitle returns the title displayed by the renderer.
+func fileTitle(lf *anime.LocalFile) string {
+	if lf.IsMain() {
+		title := fmt.Sprintf("Episode %d", lf.GetEpisodeNumber())
+		if epTitle := lf.GetParsedEpisodeTitle(); epTitle != "" {
+			title += " - " + epTitle
+		}
+		return title
+	}
+	return strings.TrimSuffix(lf.Name, filepath.Ext(lf.Name))
+}
@@ -0,0 +1,594 @@
+package dlna
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"seanime/internal/api/metadata"
+	"seanime/internal/constants"
+	"seanime/internal/continuity"
+	"seanime/internal/database/db"
+	"seanime/internal/database/db_bridge"
+	"seanime/internal/events"
+	"seanime/internal/library/anime"
+	"seanime/internal/platforms/platform"
+	"seanime/internal/util"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+)
+
+// How long the library snapshot is reused before being rebuilt
+const libraryCacheDuration = 30 * time.Second
+
+type (
+	// Server is a DLNA media server (ContentDirectory) exposing the anime library on the local network.
+	// Renderers (TVs, consoles, etc.) discover it through SSDP and play the files over HTTP.
+	// The HTTP endpoints are served by the main server under /dlna.
+	Server struct {
+		logger                     *zerolog.Logger
+		db                         *db.Database
+		platform                   platform.Platform
+		metadataProvider           metadata.Provider
+		continuityManager          *continuity.Manager
+		wsEventManager             events.WSEventManagerInterface
+		refreshAnimeCollectionFunc func()
+		// Returns the URL at which renderers can reach the server
+		serverUrl func() string
+
+		uuid     string
+		mu       sync.Mutex
+		settings *Settings
+		ssdp     *ssdpServer
+
+		libraryMu        sync.Mutex
+		library          *library
+		libraryUpdatedAt time.Time
+		systemUpdateId   int
+
+		tracker *playTracker
+	}
+
+	Settings struct {
+		Enabled bool
+		// Name displayed by renderers, defaults to "Seanime (<hostname>)"
+		FriendlyName string
+		// Whether to update the progress on AniList when a file is played
+		AutoUpdateProgress bool
+	}
+
+	NewServerOptions struct {
+		Logger                     *zerolog.Logger
+		Database                   *db.Database
+		Platform                   platform.Platform
+		MetadataProvider           metadata.Provider
+		ContinuityManager          *continuity.Manager
+		WSEventManager             events.WSEventManagerInterface
+		RefreshAnimeCollectionFunc func()
+		ServerUrl                  func() string
+	}
+)
+
+func NewServer(opts *NewServerOptions) *Server {
+	hostname, _ := os.Hostname()
+	return &Server{
+		logger:                     opts.Logger,
+		db:                         opts.Database,
+		platform:                   opts.Platform,
+		metadataProvider:           opts.MetadataProvider,
+		continuityManager:          opts.ContinuityManager,
+		wsEventManager:             opts.WSEventManager,
+		refreshAnimeCollectionFunc: opts.RefreshAnimeCollectionFunc,
+		serverUrl:                  opts.ServerUrl,
+		// The UUID should not change between restarts, renderers use it to remember servers
+		uuid:     uuid.NewSHA1(uuid.NameSpaceDNS, []byte("seanime-dlna."+hostname)).String(),
+		settings: &Settings{},
+		tracker:  newPlayTracker(),
+	}
+}
+
+// SetSettings starts or stops the server.
+func (s *Server) SetSettings(settings *Settings) {
+	if s == nil || settings == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.settings = settings
+
+	if !settings.Enabled {
+		if s.ssdp != nil {
+			s.ssdp.stop()
+			s.ssdp = nil
+			s.logger.Info().Msg("dlna: Server stopped")
+		}
+		return
+	}
+
+	if s.ssdp != nil {
+		return
+	}
+
+	ssdp := newSsdpServer(s.logger, s.uuid, s.descriptionUrl, fmt.Sprintf("%s/1.0 UPnP/1.0 Seanime/%s", runtime.GOOS, constants.Version))
+	if err := ssdp.start(); err != nil {
+		s.logger.Error().Err(err).Msg("dlna: Failed to start server")
+		return
+	}
+	s.ssdp = ssdp
+	s.logger.Info().Str("location", s.descriptionUrl()).Msg("dlna: Server started")
+}
+
+func (s *Server) Shutdown() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ssdp != nil {
+		s.ssdp.stop()
+		s.ssdp = nil
+	}
+}
+
+func (s *Server) getSettings() *Settings {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.settings
+}
+
+func (s *Server) isEnabled() bool {
+	return s != nil && s.getSettings().Enabled
+}
+
+func (s *Server) descriptionUrl() string {
+	return s.serverUrl() + "/dlna/device.xml"
+}
+
+func (s *Server) friendlyName() string {
+	if name := s.getSettings().FriendlyName; name != "" {
+		return name
+	}
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		return "Seanime"
+	}
+	return "Seanime (" + hostname + ")"
+}
+
+// getLibrary returns the library snapshot, rebuilding it if it is outdated.
+func (s *Server) getLibrary(ctx context.Context) (*library, int, error) {
+	s.libraryMu.Lock()
+	defer s.libraryMu.Unlock()
+
+	if s.library != nil && time.Since(s.libraryUpdatedAt) < libraryCacheDuration {
+		return s.library, s.systemUpdateId, nil
+	}
+
+	lfs, _, err := db_bridge.GetLocalFiles(s.db)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	animeCollection, err := s.platform.GetAnimeCollection(ctx, false)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var collection *anime.LibraryCollection
+	if animeCollection != nil {
+		collection, err = anime.NewLibraryCollection(ctx, &anime.NewLibraryCollectionOptions{
+			AnimeCollection:  animeCollection,
+			LocalFiles:       lfs,
+			Platform:         s.platform,
+			MetadataProvider: s.metadataProvider,
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	lib := newLibrary(collection, lfs)
+	if s.library == nil || !sameFiles(s.library, lib) {
+		s.systemUpdateId++
+	}
+	s.library = lib
+	s.libraryUpdatedAt = time.Now()
+
+	return s.library, s.systemUpdateId, nil
+}
+
+func (s *Server) invalidateLibrary() {
+	s.libraryMu.Lock()
+	defer s.libraryMu.Unlock()
+	s.libraryUpdatedAt = time.Time{}
+}
+
+func sameFiles(a, b *library) bool {
+	if len(a.files) != len(b.files) {
+		return false
+	}
+	for id := range a.files {
+		if _, found := b.files[id]; !found {
+			return false
+		}
+	}
+	return true
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// HTTP
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// ServeDeviceDescription serves the device description document, its URL is advertised through SSDP.
+func (s *Server) ServeDeviceDescription(c echo.Context) error {
+	if !s.isEnabled() {
+		return c.NoContent(http.StatusNotFound)
+	}
+	return c.Blob(http.StatusOK, `text/xml; charset="utf-8"`, []byte(deviceDescription(s.uuid, s.friendlyName(), constants.Version)))
+}
+
+// ServeServiceDescription serves the description document of a service.
+func (s *Server) ServeServiceDescription(c echo.Context, service string) error {
+	if !s.isEnabled() {
+		return c.NoContent(http.StatusNotFound)
+	}
+	switch service {
+	case ServiceContentDirectory:
+		return c.Blob(http.StatusOK, `text/xml; charset="utf-8"`, []byte(contentDirectoryScpd))
+	case ServiceConnectionManager:
+		return c.Blob(http.StatusOK, `text/xml; charset="utf-8"`, []byte(connectionManagerScpd))
+	}
+	return c.NoContent(http.StatusNotFound)
+}
+
+// ServeEventSubscription accepts event subscriptions.
+// Events are not sent, but some renderers refuse servers that reject subscriptions.
+func (s *Server) ServeEventSubscription(c echo.Context) error {
+	if !s.isEnabled() {
+		return c.NoContent(http.StatusNotFound)
+	}
+	if c.Request().Method == "SUBSCRIBE" {
+		sid := c.Request().Header.Get("SID")
+		if sid == "" {
+			sid = "uuid:" + uuid.New().String()
+		}
+		c.Response().Header().Set("SID", sid)
+		c.Response().Header().Set("TIMEOUT", "Second-"+strconv.Itoa(ssdpMaxAge))
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// ServeControl handles the SOAP actions of a service.
+func (s *Server) ServeControl(c echo.Context, service string) error {
+	if !s.isEnabled() {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	var serviceType string
+	switch service {
+	case ServiceContentDirectory:
+		serviceType = contentDirectoryType
+	case ServiceConnectionManager:
+		serviceType = connectionManagerType
+	default:
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	action, args, err := parseSoapRequest(c.Request().Body)
+	if err != nil {
+		return c.Blob(http.StatusInternalServerError, `text/xml; charset="utf-8"`, []byte(soapFault(&upnpError{Code: errInvalidAction, Description: "Invalid request"})))
+	}
+
+	var ret soapArgs
+	if service == ServiceContentDirectory {
+		ret, err = s.handleContentDirectoryAction(c, action, args)
+	} else {
+		ret, err = s.handleConnectionManagerAction(action)
+	}
+	if err != nil {
+		uErr, ok := err.(*upnpError)
+		if !ok {
+			s.logger.Error().Err(err).Str("action", action).Msg("dlna: Action failed")
+			uErr = &upnpError{Code: errActionFailed, Description: err.Error()}
+		}
+		return c.Blob(http.StatusInternalServerError, `text/xml; charset="utf-8"`, []byte(soapFault(uErr)))
+	}
+
+	c.Response().Header().Set("EXT", "")
+	return c.Blob(http.StatusOK, `text/xml; charset="utf-8"`, []byte(soapResponse(serviceType, action, ret)))
+}
+
+func (s *Server) handleContentDirectoryAction(c echo.Context, action string, args map[string]string) (soapArgs, error) {
+	switch action {
+	case "Browse":
+		return s.browse(c, args)
+	case "GetSystemUpdateID":
+		_, updateId, err := s.getLibrary(c.Request().Context())
+		if err != nil {
+			return nil, err
+		}
+		return soapArgs{{"Id", strconv.Itoa(updateId)}}, nil
+	case "GetSearchCapabilities":
+		return soapArgs{{"SearchCaps", ""}}, nil
+	case "GetSortCapabilities":
+		return soapArgs{{"SortCaps", ""}}, nil
+	}
+	return nil, &upnpError{Code: errInvalidAction, Description: "Invalid action"}
+}
+
+func (s *Server) handleConnectionManagerAction(action string) (soapArgs, error) {
+	switch action {
+	case "GetProtocolInfo":
+		protocols := make([]string, 0)
+		for _, ext := range []string{".mkv", ".mp4", ".avi", ".ts", ".webm"} {
+			protocols = append(protocols, "http-get:*:"+contentType(ext)+":*")
+		}
+		return soapArgs{{"Source", strings.Join(protocols, ",")}, {"Sink", ""}}, nil
+	case "GetCurrentConnectionIDs":
+		return soapArgs{{"ConnectionIDs", "0"}}, nil
+	case "GetCurrentConnectionInfo":
+		return soapArgs{
+			{"RcsID", "-1"},
+			{"AVTransportID", "-1"},
+			{"ProtocolInfo", ""},
+			{"PeerConnectionManager", ""},
+			{"PeerConnectionID", "-1"},
+			{"Direction", "Output"},
+			{"Status", "OK"},
+		}, nil
+	}
+	return nil, &upnpError{Code: errInvalidAction, Description: "Invalid action"}
+}
+
+func (s *Server) browse(c echo.Context, args map[string]string) (soapArgs, error) {
+	lib, updateId, err := s.getLibrary(c.Request().Context())
+	if err != nil {
+		return nil, err
+	}
+
+	start, _ := strconv.Atoi(args["StartingIndex"])
+	count, _ := strconv.Atoi(args["RequestedCount"])
+	if start < 0 || count < 0 {
+		return nil, &upnpError{Code: errInvalidArgs, Description: "Invalid args"}
+	}
+
+	var objects []*object
+	var total int
+	switch args["BrowseFlag"] {
+	case "BrowseMetadata":
+		obj, found := lib.getObject(args["ObjectID"])
+		if !found {
+			return nil, &upnpError{Code: errNoSuchObject, Description: "No such object"}
+		}
+		objects = []*object{obj}
+		total = 1
+	case "BrowseDirectChildren":
+		children, found := lib.getChildren(args["ObjectID"])
+		if !found {
+			return nil, &upnpError{Code: errNoSuchObject, Description: "No such object"}
+		}
+		total = len(children)
+		start = min(start, total)
+		end := total
+		if count > 0 {
+			end = min(start+count, total)
+		}
+		objects = children[start:end]
+	default:
+		return nil, &upnpError{Code: errInvalidArgs, Description: "Invalid args"}
+	}
+
+	// Use the address the renderer used to reach the server
+	baseUrl := c.Scheme() + "://" + c.Request().Host
+	result := didl(objects, func(f *libraryFile) string {
+		return baseUrl + "/dlna/media/" + f.ID + strings.ToLower(filepath.Ext(f.LocalFile.Path))
+	}, func(f *libraryFile) int64 {
+		info, err := os.Stat(f.LocalFile.Path)
+		if err != nil {
+			return 0
+		}
+		return info.Size()
+	})
+
+	return soapArgs{
+		{"Result", result},
+		{"NumberReturned", strconv.Itoa(len(objects))},
+		{"TotalMatches", strconv.Itoa(total)},
+		{"UpdateID", strconv.Itoa(updateId)},
+	}, nil
+}
+
+// IsLocalRequest reports whether the request comes from the local network.
+// The DLNA endpoints cannot be authenticated, so they are only served to loopback and private addresses.
+// Requests forwarded by a reverse proxy are also checked against the forwarded client addresses.
+func IsLocalRequest(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !isLocalAddress(host) {
+		return false
+	}
+
+	for _, value := range r.Header.Values("X-Forwarded-For") {
+		for _, addr := range strings.Split(value, ",") {
+			if !isLocalAddress(addr) {
+				return false
+			}
+		}
+	}
+	if addr := r.Header.Get("X-Real-Ip"); addr != "" && !isLocalAddress(addr) {
+		return false
+	}
+	for _, value := range r.Header.Values("Forwarded") {
+		for _, element := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' }) {
+			key, addr, ok := strings.Cut(strings.TrimSpace(element), "=")
+			if !ok || !strings.EqualFold(key, "for") {
+				continue
+			}
+			// e.g. for="[2001:db8::1]:4711"
+			addr = strings.Trim(addr, "\"")
+			if h, _, err := net.SplitHostPort(addr); err == nil {
+				addr = h
+			}
+			if !isLocalAddress(strings.Trim(addr, "[]")) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func isLocalAddress(addr string) bool {
+	ip := net.ParseIP(strings.TrimSpace(addr))
+	if ip == nil {
+		return false
+	}
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast()
+}
+
+// ServeMedia serves a library file to a renderer.
+// The fetched byte ranges are recorded, and the episode is marked as played once most of the file has been fetched.
+//   - id: File ID followed by the file extension
+//   - serve: Serves the file at the given path, range requests must be supported
+func (s *Server) ServeMedia(c echo.Context, id string, serve func(c echo.Context, path string) error) error {
+	if !s.isEnabled() {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	lib, _, err := s.getLibrary(c.Request().Context())
+	if err != nil {
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	f, found := lib.files[strings.TrimSuffix(id, filepath.Ext(id))]
+	if !found {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, contentType(f.LocalFile.Path))
+	header.Set("transferMode.dlna.org", "Streaming")
+	header.Set("contentFeatures.dlna.org", contentFeatures)
+
+	w := &countingWriter{ResponseWriter: c.Response().Writer}
+	c.Response().Writer = w
+	defer func() {
+		c.Response().Writer = w.ResponseWriter
+	}()
+
+	err = serve(c, f.LocalFile.Path)
+
+	if c.Request().Method == http.MethodGet && w.written > 0 {
+		if info, statErr := os.Stat(f.LocalFile.Path); statErr == nil {
+			var start int64
+			if c.Response().Status == http.StatusPartialContent {
+				start = rangeStart(c.Request().Header.Get("Range"), info.Size())
+			}
+			if s.tracker.add(f.ID, info.Size(), start, w.written) {
+				go s.markPlayed(f)
+			}
+		}
+	}
+
+	return err
+}
+
+// markPlayed updates the progress and the watch history after a renderer played a file.
+func (s *Server) markPlayed(f *libraryFile) {
+	defer util.HandlePanicInModuleThen("dlna/markPlayed", func() {})
+
+	lf := f.LocalFile
+	entry := f.Entry
+	mediaId := entry.Media.GetID()
+
+	s.logger.Debug().Str("path", lf.Path).Int("mediaId", mediaId).Msg("dlna: File played")
+
+	if !lf.IsMain() || entry.LocalEntry == nil {
+		return
+	}
+
+	// Remove the resume position of the episode
+	if s.continuityManager != nil {
+		if res := s.continuityManager.GetWatchHistoryItem(mediaId); res != nil && res.Found && res.Item.EpisodeNumber == lf.GetEpisodeNumber() {
+			_ = s.continuityManager.DeleteWatchHistoryItem(mediaId)
+		}
+	}
+
+	if !s.getSettings().AutoUpdateProgress {
+		return
+	}
+
+	progress := entry.LocalEntry.GetProgressNumber(lf)
+	if entry.ListData != nil && entry.ListData.Progress >= progress {
+		return
+	}
+
+	totalEpisodes := entry.Media.GetTotalEpisodeCount()
+	err := s.platform.UpdateEntryProgress(context.Background(), mediaId, progress, &totalEpisodes)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("dlna: Failed to update progress")
+		return
+	}
+
+	s.logger.Info().Int("mediaId", mediaId).Int("progress", progress).Msg("dlna: Updated progress")
+	if s.wsEventManager != nil {
+		s.wsEventManager.SendEvent(events.SuccessToast, fmt.Sprintf("Progress updated: %s - Episode %d", entry.Media.GetPreferredTitle(), progress))
+	}
+
+	s.invalidateLibrary()
+	if s.refreshAnimeCollectionFunc != nil {
+		s.refreshAnimeCollectionFunc()
+	}
+}
+
+// countingWriter counts the bytes of the response body.
+type countingWriter struct {
+	http.ResponseWriter
+	written int64
+}
+
+func (w *countingWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.written += int64(n)
+	return n, err
+}
+
+func (w *countingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// rangeStart returns the offset of the first range of a Range header.
+func rangeStart(header string, size int64) int64 {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok {
+		return 0
+	}
+	spec, _, _ = strings.Cut(spec, ",")
+	startStr, endStr, _ := strings.Cut(strings.TrimSpace(spec), "-")
+	if startStr == "" {
+		// Suffix range, e.g. "bytes=-500"
+		n, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil {
+			return 0
+		}
+		return max(size-n, 0)
+	}
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return start
+}
@@ -0,0 +1,84 @@
+package dlna
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// UPnP error codes
+const (
+	errInvalidAction = 401
+	errInvalidArgs   = 402
+	errActionFailed  = 501
+	errNoSuchObject  = 701
+)
+
+type (
+	soapEnvelope struct {
+		XMLName xml.Name `xml:"Envelope"`
+		Body    struct {
+			Action soapAction `xml:",any"`
+		} `xml:"Body"`
+	}
+
+	soapAction struct {
+		XMLName xml.Name
+		Args    []soapArg `xml:",any"`
+	}
+
+	soapArg struct {
+		XMLName xml.Name
+		Value   string `xml:",chardata"`
+	}
+
+	// soapArgs are the output arguments of an action, in order.
+	soapArgs [][2]string
+
+	upnpError struct {
+		Code        int
+		Description string
+	}
+)
+
+func (e *upnpError) Error() string {
+	return fmt.Sprintf("dlna: upnp error %d: %s", e.Code, e.Description)
+}
+
+// parseSoapRequest returns the name and the arguments of the action.
+func parseSoapRequest(r io.Reader) (action string, args map[string]string, err error) {
+	var env soapEnvelope
+	if err = xml.NewDecoder(r).Decode(&env); err != nil {
+		return "", nil, err
+	}
+	args = make(map[string]string, len(env.Body.Action.Args))
+	for _, arg := range env.Body.Action.Args {
+		args[arg.XMLName.Local] = arg.Value
+	}
+	return env.Body.Action.XMLName.Local, args, nil
+}
+
+func soapResponse(serviceType string, action string, args soapArgs) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="utf-8"?>`)
+	b.WriteString(`<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/"><s:Body>`)
+	b.WriteString(`<u:` + action + `Response xmlns:u="` + serviceType + `">`)
+	for _, arg := range args {
+		b.WriteString(`<` + arg[0] + `>` + html.EscapeString(arg[1]) + `</` + arg[0] + `>`)
+	}
+	b.WriteString(`</u:` + action + `Response>`)
+	b.WriteString(`</s:Body></s:Envelope>`)
+	return b.String()
+}
+
+func soapFault(e *upnpError) string {
+	return `<?xml version="1.0" encoding="utf-8"?>` +
+		`<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/"><s:Body>` +
+		`<s:Fault><faultcode>s:Client</faultcode><faultstring>UPnPError</faultstring><detail>` +
+		`<UPnPError xmlns="urn:schemas-upnp-org:control-1-0">` +
+		fmt.Sprintf(`<errorCode>%d</errorCode><errorDescription>%s</errorDescription>`, e.Code, html.EscapeString(e.Description)) +
+		`</UPnPError></detail></s:Fault>` +
+		`</s:Body></s:Envelope>`
+}
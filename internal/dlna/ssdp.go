@@ -0,0 +1,224 @@
+package dlna
+
+// Simple Service Discovery Protocol, used by renderers to find media servers.
+// http://upnp.org/specs/arch/UPnP-arch-DeviceArchitecture-v1.1.pdf (section 1)
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	ssdpAddr = "239.255.255.250:1900"
+	// How long the advertisement is valid, in seconds
+	ssdpMaxAge = 1800
+	// Advertisements are repeated before they expire
+	ssdpNotifyInterval = 10 * time.Minute
+)
+
+type ssdpServer struct {
+	logger *zerolog.Logger
+	uuid   string
+	// Returns the URL of the device description
+	location func() string
+	server   string
+
+	conn *net.UDPConn
+	addr *net.UDPAddr
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newSsdpServer(logger *zerolog.Logger, uuid string, location func() string, server string) *ssdpServer {
+	return &ssdpServer{
+		logger:   logger,
+		uuid:     uuid,
+		location: location,
+		server:   server,
+	}
+}
+
+func (s *ssdpServer) start() error {
+	addr, err := net.ResolveUDPAddr("udp4", ssdpAddr)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.ListenMulticastUDP("udp4", nil, addr)
+	if err != nil {
+		return fmt.Errorf("dlna: failed to listen for SSDP requests: %w", err)
+	}
+
+	s.conn = conn
+	s.addr = addr
+	s.done = make(chan struct{})
+
+	s.wg.Add(2)
+	go s.listen()
+	go s.notifyLoop()
+
+	return nil
+}
+
+func (s *ssdpServer) stop() {
+	if s.conn == nil {
+		return
+	}
+	close(s.done)
+	s.notify("ssdp:byebye")
+	_ = s.conn.Close()
+	s.wg.Wait()
+	s.conn = nil
+}
+
+// notificationTypes returns the types the server is advertised as.
+func (s *ssdpServer) notificationTypes() []string {
+	return []string{
+		"upnp:rootdevice",
+		"uuid:" + s.uuid,
+		deviceType,
+		contentDirectoryType,
+		connectionManagerType,
+	}
+}
+
+func (s *ssdpServer) usn(nt string) string {
+	if nt == "uuid:"+s.uuid {
+		return nt
+	}
+	return "uuid:" + s.uuid + "::" + nt
+}
+
+func (s *ssdpServer) notifyLoop() {
+	defer s.wg.Done()
+
+	s.notify("ssdp:alive")
+
+	ticker := time.NewTicker(ssdpNotifyInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.notify("ssdp:alive")
+		}
+	}
+}
+
+func (s *ssdpServer) notify(nts string) {
+	location := s.location()
+	for _, nt := range s.notificationTypes() {
+		msg := "NOTIFY * HTTP/1.1\r\n" +
+			"HOST: " + ssdpAddr + "\r\n" +
+			"NT: " + nt + "\r\n" +
+			"NTS: " + nts + "\r\n" +
+			"USN: " + s.usn(nt) + "\r\n"
+		if nts == "ssdp:alive" {
+			msg += "CACHE-CONTROL: max-age=" + strconv.Itoa(ssdpMaxAge) + "\r\n" +
+				"LOCATION: " + location + "\r\n" +
+				"SERVER: " + s.server + "\r\n"
+		}
+		msg += "\r\n"
+		if _, err := s.conn.WriteToUDP([]byte(msg), s.addr); err != nil {
+			s.logger.Debug().Err(err).Msg("dlna: Failed to send SSDP notification")
+			return
+		}
+	}
+}
+
+func (s *ssdpServer) listen() {
+	defer s.wg.Done()
+
+	buf := make([]byte, 2048)
+	for {
+		n, from, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			select {
+			case <-s.done:
+				return
+			default:
+			}
+			s.logger.Debug().Err(err).Msg("dlna: Failed to read SSDP request")
+			continue
+		}
+
+		st, mx, ok := parseSearchRequest(buf[:n])
+		if !ok {
+			continue
+		}
+		targets := s.searchTargets(st)
+		if len(targets) == 0 {
+			continue
+		}
+
+		// Responses should be sent after a random delay of at most MX seconds
+		delay := time.Duration(rand.Int64N(int64(min(mx, 3)) * int64(time.Second)))
+		go func() {
+			select {
+			case <-s.done:
+				return
+			case <-time.After(delay):
+			}
+			for _, target := range targets {
+				_, _ = s.conn.WriteToUDP([]byte(s.searchResponse(target)), from)
+			}
+		}()
+	}
+}
+
+// searchTargets returns the notification types matching the search target.
+func (s *ssdpServer) searchTargets(st string) []string {
+	if st == "ssdp:all" {
+		return s.notificationTypes()
+	}
+	for _, nt := range s.notificationTypes() {
+		if nt == st {
+			return []string{nt}
+		}
+	}
+	return nil
+}
+
+func (s *ssdpServer) searchResponse(st string) string {
+	return "HTTP/1.1 200 OK\r\n" +
+		"CACHE-CONTROL: max-age=" + strconv.Itoa(ssdpMaxAge) + "\r\n" +
+		"DATE: " + time.Now().UTC().Format(http.TimeFormat) + "\r\n" +
+		"EXT:\r\n" +
+		"LOCATION: " + s.location() + "\r\n" +
+		"SERVER: " + s.server + "\r\n" +
+		"ST: " + st + "\r\n" +
+		"USN: " + s.usn(st) + "\r\n" +
+		"\r\n"
+}
+
+// parseSearchRequest parses an M-SEARCH request.
+// It returns the search target and the maximum response delay in seconds.
+func parseSearchRequest(data []byte) (st string, mx int, ok bool) {
+	req, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(data)))
+	if err != nil {
+		return "", 0, false
+	}
+	if req.Method != "M-SEARCH" || strings.Trim(req.Header.Get("MAN"), `"`) != "ssdp:discover" {
+		return "", 0, false
+	}
+	st = req.Header.Get("ST")
+	if st == "" {
+		return "", 0, false
+	}
+	mx, err = strconv.Atoi(req.Header.Get("MX"))
+	if err != nil || mx < 1 {
+		mx = 1
+	}
+	return st, mx, true
+}
@@ -0,0 +1,98 @@
+package dlna
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// PlayedThreshold is the fraction of a file that must be fetched for it to be considered played.
+	PlayedThreshold = 0.8
+	// Fetches of a file separated by more than this duration are considered separate viewings.
+	viewingTimeout = 6 * time.Hour
+)
+
+type (
+	// playTracker records the byte ranges of the files fetched by renderers.
+	// Renderers don't report the playback position to media servers, the fetched ranges are used instead.
+	playTracker struct {
+		mu    sync.Mutex
+		files map[string]*fetchedFile
+	}
+
+	fetchedFile struct {
+		size int64
+		// Sorted, non-overlapping [start, end) ranges
+		ranges     [][2]int64
+		played     bool
+		lastAccess time.Time
+	}
+)
+
+func newPlayTracker() *playTracker {
+	return &playTracker{
+		files: make(map[string]*fetchedFile),
+	}
+}
+
+// add records a fetched range of a file.
+// It returns true the first time the fetched ranges cover PlayedThreshold of the file.
+func (t *playTracker) add(id string, size int64, start int64, length int64) (played bool) {
+	if size <= 0 || length <= 0 {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	f, found := t.files[id]
+	if !found || f.size != size || now.Sub(f.lastAccess) > viewingTimeout {
+		f = &fetchedFile{size: size}
+		t.files[id] = f
+	}
+	f.lastAccess = now
+
+	// Remove files from previous viewings
+	for k, v := range t.files {
+		if now.Sub(v.lastAccess) > viewingTimeout {
+			delete(t.files, k)
+		}
+	}
+
+	f.ranges = mergeRange(f.ranges, [2]int64{start, min(start+length, size)})
+
+	if f.played {
+		return false
+	}
+
+	var covered int64
+	for _, r := range f.ranges {
+		covered += r[1] - r[0]
+	}
+	if float64(covered)/float64(size) >= PlayedThreshold {
+		f.played = true
+		return true
+	}
+	return false
+}
+
+// mergeRange inserts r into the sorted, non-overlapping ranges.
+func mergeRange(ranges [][2]int64, r [2]int64) [][2]int64 {
+	if r[1] <= r[0] {
+		return ranges
+	}
+
+	ret := make([][2]int64, 0, len(ranges)+1)
+	i := 0
+	for ; i < len(ranges) && ranges[i][1] < r[0]; i++ {
+		ret = append(ret, ranges[i])
+	}
+	for ; i < len(ranges) && ranges[i][0] <= r[1]; i++ {
+		r[0] = min(r[0], ranges[i][0])
+		r[1] = max(r[1], ranges[i][1])
+	}
+	ret = append(ret, r)
+	return slices.Concat(ret, ranges[i:])
+}
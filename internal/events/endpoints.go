@@ -14,6 +14,7 @@ const (
 	ClearFileCacheMediastreamVideoFilesEndpoint        = "FILECACHE-clear-file-cache-mediastream-video-files"
 	CreateAutoDownloaderRuleEndpoint                   = "AUTO-DOWNLOADER-create-auto-downloader-rule"
 	CreatePlaylistEndpoint                             = "PLAYLIST-create-playlist"
+	CreateProfileEndpoint                              = "PROFILE-create-profile"
 	DebridAddTorrentsEndpoint                          = "DEBRID-debrid-add-torrents"
 	DebridCancelDownloadEndpoint                       = "DEBRID-debrid-cancel-download"
 	DebridCancelStreamEndpoint                         = "DEBRID-debrid-cancel-stream"
@@ -26,10 +27,12 @@ const (
 	DeleteAnilistListEntryEndpoint                     = "ANILIST-delete-anilist-list-entry"
 	DeleteAutoDownloaderItemEndpoint                   = "AUTO-DOWNLOADER-delete-auto-downloader-item"
 	DeleteAutoDownloaderRuleEndpoint                   = "AUTO-DOWNLOADER-delete-auto-downloader-rule"
+	DeleteContinuityWatchEventEndpoint                 = "CONTINUITY-delete-continuity-watch-event"
 	DeleteLocalFilesEndpoint                           = "LOCALFILES-delete-local-files"
 	DeleteLogsEndpoint                                 = "STATUS-delete-logs"
 	DeleteMangaDownloadedChaptersEndpoint              = "MANGA-DOWNLOAD-delete-manga-downloaded-chapters"
 	DeletePlaylistEndpoint                             = "PLAYLIST-delete-playlist"
+	DeleteProfileEndpoint                              = "PROFILE-delete-profile"
 	DirectorySelectorEndpoint                          = "DIRECTORY-SELECTOR-directory-selector"
 	DirectstreamPlayLocalFileEndpoint                  = "DIRECTSTREAM-directstream-play-local-file"
 	DownloadIssueReportEndpoint                        = "REPORT-download-issue-report"
@@ -40,6 +43,8 @@ const (
 	EditMALListEntryProgressEndpoint                   = "MAL-edit-mal-list-entry-progress"
 	EmptyMangaEntryCacheEndpoint                       = "MANGA-empty-manga-entry-cache"
 	EmptyTVDBEpisodesEndpoint                          = "METADATA-empty-tvdb-episodes"
+	ExportContinuityWatchHistoryEndpoint               = "CONTINUITY-export-continuity-watch-history"
+	ExportListsEndpoint                                = "LIST-EXPORT-export-lists"
 	FetchAnimeEntrySuggestionsEndpoint                 = "ANIME-ENTRIES-fetch-anime-entry-suggestions"
 	FetchExternalExtensionDataEndpoint                 = "EXTENSIONS-fetch-external-extension-data"
 	GetActiveTorrentListEndpoint                       = "TORRENT-CLIENT-get-active-torrent-list"
@@ -59,8 +64,10 @@ const (
 	GetAutoDownloaderRulesEndpoint                     = "AUTO-DOWNLOADER-get-auto-downloader-rules"
 	GetAutoDownloaderRulesByAnimeEndpoint              = "AUTO-DOWNLOADER-get-auto-downloader-rules-by-anime"
 	GetChangelogEndpoint                               = "RELEASES-get-changelog"
+	GetContinuityWatchEventsEndpoint                   = "CONTINUITY-get-continuity-watch-events"
 	GetContinuityWatchHistoryEndpoint                  = "CONTINUITY-get-continuity-watch-history"
 	GetContinuityWatchHistoryItemEndpoint              = "CONTINUITY-get-continuity-watch-history-item"
+	GetContinuityWatchStatsEndpoint                    = "CONTINUITY-get-continuity-watch-stats"
 	GetDebridSettingsEndpoint                          = "DEBRID-get-debrid-settings"
 	GetDocsEndpoint                                    = "DOCS-get-docs"
 	GetExtensionPayloadEndpoint                        = "EXTENSIONS-get-extension-payload"
@@ -94,9 +101,11 @@ const (
 	GetOnlineStreamEpisodeListEndpoint                 = "ONLINESTREAM-get-online-stream-episode-list"
 	GetOnlineStreamEpisodeSourceEndpoint               = "ONLINESTREAM-get-online-stream-episode-source"
 	GetOnlinestreamMappingEndpoint                     = "ONLINESTREAM-get-onlinestream-mapping"
+	GetOutboxItemsEndpoint                             = "OUTBOX-get-outbox-items"
 	GetPlaylistEpisodesEndpoint                        = "PLAYLIST-get-playlist-episodes"
 	GetPlaylistsEndpoint                               = "PLAYLIST-get-playlists"
 	GetPluginSettingsEndpoint                          = "EXTENSIONS-get-plugin-settings"
+	GetProfilesEndpoint                                = "PROFILE-get-profiles"
 	GetRawAnilistMangaCollectionEndpoint               = "MANGA-get-raw-anilist-manga-collection"
 	GetRawAnimeCollectionEndpoint                      = "ANILIST-get-raw-anime-collection"
 	GetScanSummariesEndpoint                           = "SCAN-SUMMARY-get-scan-summaries"
@@ -108,6 +117,8 @@ const (
 	GetTorrentstreamTorrentFilePreviewsEndpoint        = "TORRENTSTREAM-get-torrentstream-torrent-file-previews"
 	GettingStartedEndpoint                             = "SETTINGS-getting-started"
 	GrantPluginPermissionsEndpoint                     = "EXTENSIONS-grant-plugin-permissions"
+	ImportContinuityWatchHistoryEndpoint               = "CONTINUITY-import-continuity-watch-history"
+	ImportListsEndpoint                                = "LIST-EXPORT-import-lists"
 	ImportLocalFilesEndpoint                           = "LOCALFILES-import-local-files"
 	InstallExternalExtensionEndpoint                   = "EXTENSIONS-install-external-extension"
 	InstallLatestUpdateEndpoint                        = "RELEASES-install-latest-update"
@@ -117,14 +128,23 @@ const (
 	ListMangaProviderExtensionsEndpoint                = "EXTENSIONS-list-manga-provider-extensions"
 	ListOnlinestreamProviderExtensionsEndpoint         = "EXTENSIONS-list-onlinestream-provider-extensions"
 	LocalAddTrackedMediaEndpoint                       = "LOCAL-local-add-tracked-media"
+	LocalDeleteOfflineEpisodeEndpoint                  = "LOCAL-local-delete-offline-episode"
+	LocalEstimateSyncEndpoint                          = "LOCAL-local-estimate-sync"
 	LocalFileBulkActionEndpoint                        = "LOCALFILES-local-file-bulk-action"
 	LocalGetHasLocalChangesEndpoint                    = "LOCAL-local-get-has-local-changes"
 	LocalGetIsMediaTrackedEndpoint                     = "LOCAL-local-get-is-media-tracked"
 	LocalGetLocalStorageSizeEndpoint                   = "LOCAL-local-get-local-storage-size"
+	LocalGetOfflineDownloadSettingsEndpoint            = "LOCAL-local-get-offline-download-settings"
+	LocalGetOfflineEpisodesEndpoint                    = "LOCAL-local-get-offline-episodes"
 	LocalGetSyncQueueStateEndpoint                     = "LOCAL-local-get-sync-queue-state"
+	LocalGetSyncSettingsEndpoint                       = "LOCAL-local-get-sync-settings"
 	LocalGetTrackedMediaItemsEndpoint                  = "LOCAL-local-get-tracked-media-items"
 	LocalRemoveTrackedMediaEndpoint                    = "LOCAL-local-remove-tracked-media"
+	LocalRunOfflineDownloaderEndpoint                  = "LOCAL-local-run-offline-downloader"
+	LocalSaveOfflineDownloadSettingsEndpoint           = "LOCAL-local-save-offline-download-settings"
+	LocalSaveSyncSettingsEndpoint                      = "LOCAL-local-save-sync-settings"
 	LocalSetHasLocalChangesEndpoint                    = "LOCAL-local-set-has-local-changes"
+	LocalSetTrackedMediaPriorityEndpoint               = "LOCAL-local-set-tracked-media-priority"
 	LocalSyncAnilistDataEndpoint                       = "LOCAL-local-sync-anilist-data"
 	LocalSyncDataEndpoint                              = "LOCAL-local-sync-data"
 	LocalSyncSimulatedDataToAnilistEndpoint            = "LOCAL-local-sync-simulated-data-to-anilist"
@@ -182,6 +202,7 @@ const (
 	SaveTorrentstreamSettingsEndpoint                  = "TORRENTSTREAM-save-torrentstream-settings"
 	ScanLocalFilesEndpoint                             = "SCAN-scan-local-files"
 	SearchTorrentEndpoint                              = "TORRENT-SEARCH-search-torrent"
+	SelectProfileEndpoint                              = "PROFILE-select-profile"
 	SendNakamaMessageEndpoint                          = "NAKAMA-send-nakama-message"
 	SetDiscordAnimeActivityWithProgressEndpoint        = "DISCORD-set-discord-anime-activity-with-progress"
 	SetDiscordLegacyAnimeActivityEndpoint              = "DISCORD-set-discord-legacy-anime-activity"
@@ -210,5 +231,6 @@ const (
 	UpdateLocalFilesEndpoint                           = "LOCALFILES-update-local-files"
 	UpdateMangaProgressEndpoint                        = "MANGA-update-manga-progress"
 	UpdatePlaylistEndpoint                             = "PLAYLIST-update-playlist"
+	UpdateProfileEndpoint                              = "PROFILE-update-profile"
 	UpdateThemeEndpoint                                = "THEME-update-theme"
 )
@@ -37,6 +37,9 @@ const (
 	PlaybackManagerManualTrackingPlaybackState = "playback-manager-manual-tracking-playback-state" // Dispatches the current playback state
 	PlaybackManagerManualTrackingStopped       = "playback-manager-manual-tracking-stopped"        // The manual tracking has been stopped
 
+	PlaybackQueueState            = "playback-queue-state"              // Dispatches the current playback queue state
+	PlaybackQueuePlayOnlineStream = "playback-queue-play-online-stream" // The client should play an online stream episode of the queue
+
 	ExternalPlayerOpenURL = "external-player-open-url" // Open a URL to send media to an external media player
 
 	InfoToast    = "info-toast"
@@ -64,6 +67,8 @@ const (
 	SyncLocalFinished   = "sync-local-finished"
 	SyncAnilistFinished = "sync-anilist-finished"
 
+	ProfileChanged = "profile-changed" // The client selected another profile or its profile was deleted, it should refetch all data
+
 	TorrentStreamState = "torrentstream-state"
 
 	DebridDownloadProgress = "debrid-download-progress"
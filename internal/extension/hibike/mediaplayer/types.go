@@ -0,0 +1,71 @@
+package hibikemediaplayer
+
+type (
+	// MediaPlayer is a media player integration provided by an extension.
+	// Seanime tracks the progress of the media player by polling GetPlaybackStatus.
+	MediaPlayer interface {
+		// GetSettings returns the media player settings.
+		GetSettings() Settings
+		// Start launches the media player, or checks that it can be reached.
+		Start() error
+		// Play opens and plays a local file.
+		Play(req PlayRequest) (*PlayResponse, error)
+		// Stream opens and plays a stream URL.
+		Stream(req PlayRequest) (*PlayResponse, error)
+		// GetPlaybackStatus returns the playback status of the current file.
+		// It should return an error if the media player cannot be reached or nothing is playing.
+		GetPlaybackStatus() (*PlaybackStatus, error)
+		Pause() error
+		Resume() error
+		// Seek seeks to the given position in seconds.
+		Seek(position float64) error
+		// Stop stops playback and closes the media player if possible.
+		Stop() error
+	}
+
+	Settings struct {
+		// Whether GetPlaybackStatus is implemented.
+		// If false, the progress of the media player is not tracked.
+		CanTrackProgress bool `json:"canTrackProgress"`
+		// Whether the media player can only open URLs (e.g. remote devices, URL schemes).
+		// If true, local files are sent as URLs served by Seanime.
+		RequiresUrl bool `json:"requiresUrl"`
+	}
+
+	PlayRequest struct {
+		// Path of the local file or URL of the stream.
+		// When playing a local file on a media player that requires URLs, this is the URL of the file.
+		Path string `json:"path"`
+		// Path of the local file, empty when streaming.
+		LocalPath string `json:"localPath,omitempty"`
+		// External subtitle files of the video.
+		SubtitlePaths []string `json:"subtitlePaths,omitempty"`
+		// Position to resume from in seconds, 0 to start from the beginning.
+		StartTime float64 `json:"startTime"`
+		// Title of the stream, if any.
+		Title      string     `json:"title,omitempty"`
+		ClientInfo ClientInfo `json:"clientInfo"`
+	}
+
+	ClientInfo struct {
+		// e.g. "windows", "darwin", "linux", "ios", "android"
+		Platform string `json:"platform"`
+	}
+
+	PlayResponse struct {
+		// URL to open on the client, e.g. "infuse://x-callback-url/play?url=..."
+		// Leave empty if the media player was controlled directly.
+		OpenURL string `json:"openUrl,omitempty"`
+	}
+
+	PlaybackStatus struct {
+		Playing bool `json:"playing"`
+		// Name of the file being played, e.g. "Show - 01.mkv"
+		// Leave empty to use the name of the file sent by Seanime.
+		Filename string `json:"filename,omitempty"`
+		// Position in seconds
+		Position float64 `json:"position"`
+		// Duration in seconds
+		Duration float64 `json:"duration"`
+	}
+)
@@ -0,0 +1,98 @@
+package extension
+
+import (
+	hibikemediaplayer "seanime/internal/extension/hibike/mediaplayer"
+)
+
+type MediaPlayerExtension interface {
+	BaseExtension
+	GetMediaPlayer() hibikemediaplayer.MediaPlayer
+}
+
+type MediaPlayerExtensionImpl struct {
+	ext    *Extension
+	player hibikemediaplayer.MediaPlayer
+}
+
+func NewMediaPlayerExtension(ext *Extension, player hibikemediaplayer.MediaPlayer) MediaPlayerExtension {
+	return &MediaPlayerExtensionImpl{
+		ext:    ext,
+		player: player,
+	}
+}
+
+func (m *MediaPlayerExtensionImpl) GetMediaPlayer() hibikemediaplayer.MediaPlayer {
+	return m.player
+}
+
+func (m *MediaPlayerExtensionImpl) GetExtension() *Extension {
+	return m.ext
+}
+
+func (m *MediaPlayerExtensionImpl) GetType() Type {
+	return m.ext.Type
+}
+
+func (m *MediaPlayerExtensionImpl) GetID() string {
+	return m.ext.ID
+}
+
+func (m *MediaPlayerExtensionImpl) GetName() string {
+	return m.ext.Name
+}
+
+func (m *MediaPlayerExtensionImpl) GetVersion() string {
+	return m.ext.Version
+}
+
+func (m *MediaPlayerExtensionImpl) GetManifestURI() string {
+	return m.ext.ManifestURI
+}
+
+func (m *MediaPlayerExtensionImpl) GetLanguage() Language {
+	return m.ext.Language
+}
+
+func (m *MediaPlayerExtensionImpl) GetLang() string {
+	return GetExtensionLang(m.ext.Lang)
+}
+
+func (m *MediaPlayerExtensionImpl) GetDescription() string {
+	return m.ext.Description
+}
+
+func (m *MediaPlayerExtensionImpl) GetAuthor() string {
+	return m.ext.Author
+}
+
+func (m *MediaPlayerExtensionImpl) GetPayload() string {
+	return m.ext.Payload
+}
+
+func (m *MediaPlayerExtensionImpl) GetWebsite() string {
+	return m.ext.Website
+}
+
+func (m *MediaPlayerExtensionImpl) GetIcon() string {
+	return m.ext.Icon
+}
+
+func (m *MediaPlayerExtensionImpl) GetPermissions() []string {
+	return m.ext.Permissions
+}
+
+func (m *MediaPlayerExtensionImpl) GetUserConfig() *UserConfig {
+	return m.ext.UserConfig
+}
+
+func (m *MediaPlayerExtensionImpl) GetSavedUserConfig() *SavedUserConfig {
+	return m.ext.SavedUserConfig
+}
+
+func (m *MediaPlayerExtensionImpl) GetPayloadURI() string {
+	return m.ext.PayloadURI
+}
+
+func (m *MediaPlayerExtensionImpl) GetIsDevelopment() bool {
+	return m.ext.IsDevelopment
+}
@@ -4,6 +4,7 @@ import (
 	"seanime/internal/events"
 	"seanime/internal/extension"
 	hibikemanga "seanime/internal/extension/hibike/manga"
+	hibikemediaplayer "seanime/internal/extension/hibike/mediaplayer"
 	hibikeonlinestream "seanime/internal/extension/hibike/onlinestream"
 	hibiketorrent "seanime/internal/extension/hibike/torrent"
 )
@@ -115,6 +116,21 @@ func (r *Repository) loadBuiltInExtension(ext extension.Extension, provider inte
 		case extension.LanguageJavascript, extension.LanguageTypescript:
 			r.loadBuiltInOnlinestreamProviderExtensionJS(ext)
 		}
+	case extension.TypeMediaPlayer:
+		switch ext.Language {
+		// Go
+		case extension.LanguageGo:
+			if provider == nil {
+				r.logger.Error().Str("id", ext.ID).Msg("extensions: Built-in media player extension requires a provider")
+				return
+			}
+			saveUserConfigInProvider(&ext, provider)
+			if mediaPlayer, ok := provider.(hibikemediaplayer.MediaPlayer); ok {
+				r.loadBuiltInMediaPlayerExtension(ext, mediaPlayer)
+			}
+		case extension.LanguageJavascript, extension.LanguageTypescript:
+			r.loadBuiltInMediaPlayerExtensionJS(ext)
+		}
 	case extension.TypePlugin:
 		// TODO: Implement
 	}
@@ -147,3 +163,18 @@ func (r *Repository) loadBuiltInOnlinestreamProviderExtensionJS(ext extension.Ex
 	}
 	r.logger.Debug().Str("id", ext.ID).Msg("extensions: Loaded built-in onlinestream provider extension")
 }
+
+func (r *Repository) loadBuiltInMediaPlayerExtension(ext extension.Extension, player hibikemediaplayer.MediaPlayer) {
+	r.extensionBank.Set(ext.ID, extension.NewMediaPlayerExtension(&ext, player))
+	r.logger.Debug().Str("id", ext.ID).Msg("extensions: Loaded built-in media player extension")
+}
+
+func (r *Repository) loadBuiltInMediaPlayerExtensionJS(ext extension.Extension) {
+	// Load the extension as if it was an external extension
+	err := r.loadExternalMediaPlayerExtensionJS(&ext, ext.Language)
+	if err != nil {
+		r.logger.Error().Err(err).Str("id", ext.ID).Msg("extensions: Failed to load built-in JS media player extension")
+		return
+	}
+	r.logger.Debug().Str("id", ext.ID).Msg("extensions: Loaded built-in media player extension")
+}
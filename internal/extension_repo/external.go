@@ -599,6 +599,9 @@ func (r *Repository) loadExternalExtension(filePath string) {
 	case extension.TypeAnimeTorrentProvider:
 		// Load torrent provider
 		loadingErr = r.loadExternalAnimeTorrentProviderExtension(ext)
+	case extension.TypeMediaPlayer:
+		// Load media player
+		loadingErr = r.loadExternalMediaPlayerExtension(ext)
 	case extension.TypePlugin:
 		// Load plugin
 		loadingErr = r.loadPlugin(ext)
@@ -0,0 +1,41 @@
+package extension_repo
+
+import (
+	"fmt"
+	"seanime/internal/extension"
+	"seanime/internal/util"
+)
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Media player
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (r *Repository) loadExternalMediaPlayerExtension(ext *extension.Extension) (err error) {
+	defer util.HandlePanicInModuleWithError("extension_repo/loadExternalMediaPlayerExtension", &err)
+
+	switch ext.Language {
+	case extension.LanguageJavascript, extension.LanguageTypescript:
+		err = r.loadExternalMediaPlayerExtensionJS(ext, ext.Language)
+	default:
+		err = fmt.Errorf("unsupported language: %v", ext.Language)
+	}
+
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (r *Repository) loadExternalMediaPlayerExtensionJS(ext *extension.Extension, language extension.Language) error {
+	player, gojaExt, err := NewGojaMediaPlayer(ext, language, r.logger, r.gojaRuntimeManager)
+	if err != nil {
+		return err
+	}
+
+	// Add the extension to the map
+	retExt := extension.NewMediaPlayerExtension(ext, player)
+	r.extensionBank.Set(ext.ID, retExt)
+	r.gojaExtensions.Set(ext.ID, gojaExt)
+	return nil
+}
@@ -0,0 +1,150 @@
+package extension_repo
+
+import (
+	"context"
+	"fmt"
+	"seanime/internal/extension"
+	hibikemediaplayer "seanime/internal/extension/hibike/mediaplayer"
+	"seanime/internal/goja/goja_runtime"
+	"seanime/internal/util"
+
+	"github.com/dop251/goja"
+	"github.com/rs/zerolog"
+)
+
+type GojaMediaPlayer struct {
+	*gojaProviderBase
+}
+
+func NewGojaMediaPlayer(ext *extension.Extension, language extension.Language, logger *zerolog.Logger, runtimeManager *goja_runtime.Manager) (hibikemediaplayer.MediaPlayer, *GojaMediaPlayer, error) {
+	base, err := initializeProviderBase(ext, language, logger, runtimeManager)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	player := &GojaMediaPlayer{
+		gojaProviderBase: base,
+	}
+	return player, player, nil
+}
+
+// call calls the method and waits for the returned promise, if any.
+func (g *GojaMediaPlayer) call(methodName string, args ...interface{}) (goja.Value, error) {
+	method, err := g.callClassMethod(context.Background(), methodName, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	promiseRes, err := g.waitForPromise(method)
+	if err != nil {
+		return nil, err
+	}
+
+	return promiseRes, nil
+}
+
+func (g *GojaMediaPlayer) GetSettings() (ret hibikemediaplayer.Settings) {
+	defer util.HandlePanicInModuleThen(g.ext.ID+".GetSettings", func() {
+		ret = hibikemediaplayer.Settings{}
+	})
+
+	method, err := g.callClassMethod(context.Background(), "getSettings")
+	if err != nil {
+		return
+	}
+
+	err = g.unmarshalValue(method, &ret)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (g *GojaMediaPlayer) Start() (err error) {
+	defer util.HandlePanicInModuleWithError(g.ext.ID+".Start", &err)
+
+	_, err = g.call("start")
+	return err
+}
+
+func (g *GojaMediaPlayer) Play(req hibikemediaplayer.PlayRequest) (ret *hibikemediaplayer.PlayResponse, err error) {
+	return g.play("play", req)
+}
+
+func (g *GojaMediaPlayer) Stream(req hibikemediaplayer.PlayRequest) (ret *hibikemediaplayer.PlayResponse, err error) {
+	return g.play("stream", req)
+}
+
+func (g *GojaMediaPlayer) play(methodName string, req hibikemediaplayer.PlayRequest) (ret *hibikemediaplayer.PlayResponse, err error) {
+	defer util.HandlePanicInModuleWithError(g.ext.ID+"."+methodName, &err)
+
+	res, err := g.call(methodName, structToMap(req))
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s method: %w", methodName, err)
+	}
+
+	ret = &hibikemediaplayer.PlayResponse{}
+	// The method can return nothing if the media player was controlled directly
+	if goja.IsUndefined(res) || goja.IsNull(res) {
+		return ret, nil
+	}
+
+	err = g.unmarshalValue(res, ret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal play response: %w", err)
+	}
+
+	return ret, nil
+}
+
+func (g *GojaMediaPlayer) GetPlaybackStatus() (ret *hibikemediaplayer.PlaybackStatus, err error) {
+	defer util.HandlePanicInModuleWithError(g.ext.ID+".GetPlaybackStatus", &err)
+
+	res, err := g.call("getPlaybackStatus")
+	if err != nil {
+		return nil, err
+	}
+
+	if goja.IsUndefined(res) || goja.IsNull(res) {
+		return nil, fmt.Errorf("no playback status")
+	}
+
+	err = g.unmarshalValue(res, &ret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal playback status: %w", err)
+	}
+	if ret == nil {
+		return nil, fmt.Errorf("no playback status")
+	}
+
+	return ret, nil
+}
+
+func (g *GojaMediaPlayer) Pause() (err error) {
+	defer util.HandlePanicInModuleWithError(g.ext.ID+".Pause", &err)
+
+	_, err = g.call("pause")
+	return err
+}
+
+func (g *GojaMediaPlayer) Resume() (err error) {
+	defer util.HandlePanicInModuleWithError(g.ext.ID+".Resume", &err)
+
+	_, err = g.call("resume")
+	return err
+}
+
+func (g *GojaMediaPlayer) Seek(position float64) (err error) {
+	defer util.HandlePanicInModuleWithError(g.ext.ID+".Seek", &err)
+
+	_, err = g.call("seek", position)
+	return err
+}
+
+func (g *GojaMediaPlayer) Stop() (err error) {
+	defer util.HandlePanicInModuleWithError(g.ext.ID+".Stop", &err)
+
+	_, err = g.call("stop")
+	return err
+}
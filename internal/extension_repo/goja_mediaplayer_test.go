@@ -0,0 +1,88 @@
+package extension_repo_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"seanime/internal/extension"
+	hibikemediaplayer "seanime/internal/extension/hibike/mediaplayer"
+	"seanime/internal/extension_repo"
+	"seanime/internal/goja/goja_runtime"
+	"seanime/internal/util"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGojaMediaPlayer(t *testing.T) {
+	var mu sync.Mutex
+	var requests []map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var body map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			body["path"] = r.URL.Path
+			mu.Lock()
+			requests = append(requests, body)
+			mu.Unlock()
+		}
+		switch r.URL.Path {
+		case "/status":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"state": "playing", "time": 90.5, "length": 1420})
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true})
+		}
+	}))
+	defer server.Close()
+
+	fileB, err := os.ReadFile("./goja_mediaplayer_test/my-mediaplayer.ts")
+	require.NoError(t, err)
+
+	ext := &extension.Extension{
+		ID:       "my-mediaplayer",
+		Name:     "MyMediaPlayer",
+		Version:  "0.1.0",
+		Language: extension.LanguageTypescript,
+		Type:     extension.TypeMediaPlayer,
+		Payload:  string(fileB),
+		UserConfig: &extension.UserConfig{
+			Fields: []extension.ConfigField{{Name: "apiUrl", Type: extension.ConfigFieldTypeText}},
+		},
+		SavedUserConfig: &extension.SavedUserConfig{
+			Values: map[string]string{"apiUrl": server.URL},
+		},
+	}
+
+	player, _, err := extension_repo.NewGojaMediaPlayer(ext, ext.Language, util.NewLogger(), goja_runtime.NewManager(util.NewLogger()))
+	require.NoError(t, err)
+
+	assert.Equal(t, hibikemediaplayer.Settings{CanTrackProgress: true, RequiresUrl: true}, player.GetSettings())
+
+	require.NoError(t, player.Start())
+
+	res, err := player.Play(hibikemediaplayer.PlayRequest{Path: "http://192.168.1.10:43211/video.mkv", StartTime: 30})
+	require.NoError(t, err)
+	assert.Empty(t, res.OpenURL)
+
+	res, err = player.Stream(hibikemediaplayer.PlayRequest{Path: "http://192.168.1.10/stream", ClientInfo: hibikemediaplayer.ClientInfo{Platform: "ios"}})
+	require.NoError(t, err)
+	assert.Equal(t, "infuse://x-callback-url/play?url=http%3A%2F%2F192.168.1.10%2Fstream", res.OpenURL)
+
+	status, err := player.GetPlaybackStatus()
+	require.NoError(t, err)
+	assert.Equal(t, &hibikemediaplayer.PlaybackStatus{Playing: true, Position: 90.5, Duration: 1420}, status)
+
+	require.NoError(t, player.Seek(120))
+	require.NoError(t, player.Stop())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, requests, 3)
+	assert.Equal(t, map[string]interface{}{"path": "/open", "url": "http://192.168.1.10:43211/video.mkv", "start": 30.0}, requests[0])
+	assert.Equal(t, map[string]interface{}{"path": "/control", "action": "seek", "position": 120.0}, requests[1])
+	assert.Equal(t, map[string]interface{}{"path": "/control", "action": "stop"}, requests[2])
+}
@@ -0,0 +1,84 @@
+declare type Settings = {
+    /**
+     * Whether getPlaybackStatus is implemented.
+     * If false, the progress of the media player is not tracked.
+     */
+    canTrackProgress: boolean
+    /**
+     * Whether the media player can only open URLs (e.g. remote devices, URL schemes).
+     * If true, local files are sent as URLs served by Seanime.
+     */
+    requiresUrl: boolean
+}
+
+declare type ClientInfo = {
+    /** e.g. "windows", "darwin", "linux", "ios", "android" */
+    platform: string
+}
+
+declare type PlayRequest = {
+    /**
+     * Path of the local file or URL of the stream.
+     * When playing a local file on a media player that requires URLs, this is the URL of the file.
+     */
+    path: string
+    /** Path of the local file, undefined when streaming */
+    localPath?: string
+    /** External subtitle files of the video */
+    subtitlePaths?: string[]
+    /** Position to resume from in seconds, 0 to start from the beginning */
+    startTime: number
+    /** Title of the stream, if any */
+    title?: string
+    clientInfo: ClientInfo
+}
+
+declare type PlayResponse = {
+    /**
+     * URL to open on the client, e.g. "infuse://x-callback-url/play?url=..."
+     * Leave undefined if the media player was controlled directly.
+     */
+    openUrl?: string
+}
+
+declare type PlaybackStatus = {
+    playing: boolean
+    /**
+     * Name of the file being played, e.g. "Show - 01.mkv"
+     * Leave undefined to use the name of the file sent by Seanime.
+     */
+    filename?: string
+    /** Position in seconds */
+    position: number
+    /** Duration in seconds */
+    duration: number
+}
+
+declare interface MediaPlayer {
+    getSettings(): Settings
+
+    /** Launches the media player, or checks that it can be reached. */
+    start(): Promise<void>
+
+    /** Opens and plays a local file. */
+    play(req: PlayRequest): Promise<PlayResponse | undefined>
+
+    /** Opens and plays a stream URL. */
+    stream(req: PlayRequest): Promise<PlayResponse | undefined>
+
+    /**
+     * Returns the playback status of the current file.
+     * It should throw if the media player cannot be reached or nothing is playing.
+     */
+    getPlaybackStatus(): Promise<PlaybackStatus>
+
+    pause(): Promise<void>
+
+    resume(): Promise<void>
+
+    /** Seeks to the given position in seconds. */
+    seek(position: number): Promise<void>
+
+    /** Stops playback and closes the media player if possible. */
+    stop(): Promise<void>
+}
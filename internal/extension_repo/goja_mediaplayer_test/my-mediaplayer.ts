@@ -0,0 +1,70 @@
+/// <reference path="./mediaplayer.d.ts" />
+/// <reference path="../goja_plugin_types/core.d.ts" />
+
+// Media player controlled through a simple HTTP API, e.g. a smart TV remote API.
+class Provider implements MediaPlayer {
+
+    getSettings(): Settings {
+        return {
+            canTrackProgress: true,
+            requiresUrl: true,
+        }
+    }
+
+    private get api(): string {
+        return $getUserPreference("apiUrl") ?? "http://127.0.0.1:8080"
+    }
+
+    private async send(path: string, body?: any): Promise<any> {
+        const res = await fetch(`${this.api}${path}`, {
+            method: body ? "POST" : "GET",
+            headers: { "Content-Type": "application/json" },
+            body: body ? JSON.stringify(body) : undefined,
+        })
+        if (!res.ok) {
+            throw new Error(`request failed: ${res.status}`)
+        }
+        return res.json()
+    }
+
+    async start(): Promise<void> {
+        await this.send("/ping")
+    }
+
+    async play(req: PlayRequest): Promise<PlayResponse | undefined> {
+        await this.send("/open", { url: req.path, start: req.startTime })
+        return undefined
+    }
+
+    async stream(req: PlayRequest): Promise<PlayResponse | undefined> {
+        if (req.clientInfo.platform === "ios") {
+            return { openUrl: `infuse://x-callback-url/play?url=${encodeURIComponent(req.path)}` }
+        }
+        return this.play(req)
+    }
+
+    async getPlaybackStatus(): Promise<PlaybackStatus> {
+        const status = await this.send("/status")
+        return {
+            playing: status.state === "playing",
+            position: status.time,
+            duration: status.length,
+        }
+    }
+
+    async pause(): Promise<void> {
+        await this.send("/control", { action: "pause" })
+    }
+
+    async resume(): Promise<void> {
+        await this.send("/control", { action: "play" })
+    }
+
+    async seek(position: number): Promise<void> {
+        await this.send("/control", { action: "seek", position: position })
+    }
+
+    async stop(): Promise<void> {
+        await this.send("/control", { action: "stop" })
+    }
+}
@@ -7,6 +7,7 @@ import (
 	"seanime/internal/events"
 	"seanime/internal/extension"
 	hibikemanga "seanime/internal/extension/hibike/manga"
+	hibikemediaplayer "seanime/internal/extension/hibike/mediaplayer"
 	hibiketorrent "seanime/internal/extension/hibike/torrent"
 	"seanime/internal/goja/goja_runtime"
 	"seanime/internal/hook"
@@ -96,6 +97,12 @@ type (
 		Lang     string                              `json:"lang"` // ISO 639-1 language code
 		Settings hibiketorrent.AnimeProviderSettings `json:"settings"`
 	}
+
+	MediaPlayerExtensionItem struct {
+		ID       string                     `json:"id"`
+		Name     string                     `json:"name"`
+		Settings hibikemediaplayer.Settings `json:"settings"`
+	}
 )
 
 type NewRepositoryOptions struct {
@@ -296,6 +303,21 @@ func (r *Repository) ListAnimeTorrentProviderExtensions() []*AnimeTorrentProvide
 	return ret
 }
 
+func (r *Repository) ListMediaPlayerExtensions() []*MediaPlayerExtensionItem {
+	ret := make([]*MediaPlayerExtensionItem, 0)
+
+	extension.RangeExtensions(r.extensionBank, func(key string, ext extension.MediaPlayerExtension) bool {
+		ret = append(ret, &MediaPlayerExtensionItem{
+			ID:       ext.GetID(),
+			Name:     ext.GetName(),
+			Settings: ext.GetMediaPlayer().GetSettings(),
+		})
+		return true
+	})
+
+	return ret
+}
+
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
 // GetLoadedExtension returns the loaded extension by ID.
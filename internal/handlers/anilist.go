@@ -23,16 +23,17 @@ import (
 func (h *Handler) HandleGetAnimeCollection(c echo.Context) error {
 
 	bypassCache := c.Request().Method == "POST"
+	scope := h.getUserScope(c)
 
 	// Get the user's anilist collection
-	animeCollection, err := h.App.GetAnimeCollection(bypassCache)
+	animeCollection, err := scope.GetAnimeCollection(bypassCache)
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
 
 	go func() {
 		if h.App.Settings != nil && h.App.Settings.GetLibrary().EnableManga {
-			_, _ = h.App.GetMangaCollection(bypassCache)
+			_, _ = scope.GetMangaCollection(bypassCache)
 			if bypassCache {
 				h.App.WSEventManager.SendEvent(events.RefreshedAnilistMangaCollection, nil)
 			}
@@ -53,7 +54,7 @@ func (h *Handler) HandleGetRawAnimeCollection(c echo.Context) error {
 	bypassCache := c.Request().Method == "POST"
 
 	// Get the user's anilist collection
-	animeCollection, err := h.App.GetRawAnimeCollection(bypassCache)
+	animeCollection, err := h.getUserScope(c).GetRawAnimeCollection(bypassCache)
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
@@ -86,7 +87,7 @@ func (h *Handler) HandleEditAnilistListEntry(c echo.Context) error {
 		return h.RespondWithError(c, err)
 	}
 
-	err := h.App.AnilistPlatform.UpdateEntry(
+	err := h.getUserScope(c).GetPlatform().UpdateEntry(
 		c.Request().Context(),
 		*p.MediaId,
 		p.Status,
@@ -101,12 +102,12 @@ func (h *Handler) HandleEditAnilistListEntry(c echo.Context) error {
 
 	switch p.Type {
 	case "anime":
-		_, _ = h.App.RefreshAnimeCollection()
+		_, _ = h.getUserScope(c).RefreshAnimeCollection()
 	case "manga":
-		_, _ = h.App.RefreshMangaCollection()
+		_, _ = h.getUserScope(c).RefreshMangaCollection()
 	default:
-		_, _ = h.App.RefreshAnimeCollection()
-		_, _ = h.App.RefreshMangaCollection()
+		_, _ = h.getUserScope(c).RefreshAnimeCollection()
+		_, _ = h.getUserScope(c).RefreshMangaCollection()
 	}
 
 	return h.RespondWithData(c, true)
@@ -135,7 +136,7 @@ func (h *Handler) HandleGetAnilistAnimeDetails(c echo.Context) error {
 	if details, ok := detailsCache.Get(mId); ok {
 		return h.RespondWithData(c, details)
 	}
-	details, err := h.App.AnilistPlatform.GetAnimeDetails(c.Request().Context(), mId)
+	details, err := h.getUserScope(c).GetPlatform().GetAnimeDetails(c.Request().Context(), mId)
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
@@ -165,7 +166,7 @@ func (h *Handler) HandleGetAnilistStudioDetails(c echo.Context) error {
 	if details, ok := studioDetailsMap.Get(mId); ok {
 		return h.RespondWithData(c, details)
 	}
-	details, err := h.App.AnilistPlatform.GetStudioDetails(c.Request().Context(), mId)
+	details, err := h.getUserScope(c).GetPlatform().GetStudioDetails(c.Request().Context(), mId)
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
@@ -210,7 +211,7 @@ func (h *Handler) HandleDeleteAnilistListEntry(c echo.Context) error {
 	switch *p.Type {
 	case "anime":
 		// Get the list entry ID
-		animeCollection, err := h.App.GetAnimeCollection(false)
+		animeCollection, err := h.getUserScope(c).GetAnimeCollection(false)
 		if err != nil {
 			return h.RespondWithError(c, err)
 		}
@@ -222,7 +223,7 @@ func (h *Handler) HandleDeleteAnilistListEntry(c echo.Context) error {
 		listEntryID = listEntry.ID
 	case "manga":
 		// Get the list entry ID
-		mangaCollection, err := h.App.GetMangaCollection(false)
+		mangaCollection, err := h.getUserScope(c).GetMangaCollection(false)
 		if err != nil {
 			return h.RespondWithError(c, err)
 		}
@@ -235,16 +236,16 @@ func (h *Handler) HandleDeleteAnilistListEntry(c echo.Context) error {
 	}
 
 	// Delete the list entry
-	err := h.App.AnilistPlatform.DeleteEntry(c.Request().Context(), listEntryID)
+	err := h.getUserScope(c).GetPlatform().DeleteEntry(c.Request().Context(), listEntryID)
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
 
 	switch *p.Type {
 	case "anime":
-		_, _ = h.App.RefreshAnimeCollection()
+		_, _ = h.getUserScope(c).RefreshAnimeCollection()
 	case "manga":
-		_, _ = h.App.RefreshMangaCollection()
+		_, _ = h.getUserScope(c).RefreshMangaCollection()
 	}
 
 	return h.RespondWithData(c, true)
@@ -326,7 +327,7 @@ func (h *Handler) HandleAnilistListAnime(c echo.Context) error {
 		p.Format,
 		&isAdult,
 		h.App.Logger,
-		h.App.GetUserAnilistToken(),
+		h.getUserScope(c).GetUserAnilistToken(),
 	)
 	if err != nil {
 		return h.RespondWithError(c, err)
@@ -383,7 +384,7 @@ func (h *Handler) HandleAnilistListRecentAiringAnime(c echo.Context) error {
 		p.NotYetAired,
 		p.Sort,
 		h.App.Logger,
-		h.App.GetUserAnilistToken(),
+		h.getUserScope(c).GetUserAnilistToken(),
 	)
 	if err != nil {
 		return h.RespondWithError(c, err)
@@ -412,7 +413,7 @@ func (h *Handler) HandleAnilistListMissedSequels(c echo.Context) error {
 	}
 
 	// Get complete anime collection
-	animeCollection, err := h.App.AnilistPlatform.GetAnimeCollectionWithRelations(c.Request().Context())
+	animeCollection, err := h.getUserScope(c).GetPlatform().GetAnimeCollectionWithRelations(c.Request().Context())
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
@@ -420,7 +421,7 @@ func (h *Handler) HandleAnilistListMissedSequels(c echo.Context) error {
 	ret, err := anilist.ListMissedSequels(
 		animeCollection,
 		h.App.Logger,
-		h.App.GetUserAnilistToken(),
+		h.getUserScope(c).GetUserAnilistToken(),
 	)
 	if err != nil {
 		return h.RespondWithError(c, err)
@@ -447,7 +448,7 @@ func (h *Handler) HandleGetAniListStats(c echo.Context) error {
 		return h.RespondWithData(c, cached)
 	}
 
-	stats, err := h.App.AnilistPlatform.GetViewerStats(c.Request().Context())
+	stats, err := h.getUserScope(c).GetPlatform().GetViewerStats(c.Request().Context())
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
@@ -26,7 +26,7 @@ import (
 //	@returns anime.LibraryCollection
 func (h *Handler) HandleGetLibraryCollection(c echo.Context) error {
 
-	animeCollection, err := h.App.GetAnimeCollection(false)
+	animeCollection, err := h.getUserScope(c).GetAnimeCollection(false)
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
@@ -85,7 +85,7 @@ func (h *Handler) HandleGetLibraryCollection(c echo.Context) error {
 
 	libraryCollection, err := anime.NewLibraryCollection(c.Request().Context(), &anime.NewLibraryCollectionOptions{
 		AnimeCollection:  animeCollection,
-		Platform:         h.App.AnilistPlatform,
+		Platform:         h.getUserScope(c).GetPlatform(),
 		LocalFiles:       lfs,
 		MetadataProvider: h.App.MetadataProvider,
 	})
@@ -162,12 +162,12 @@ func (h *Handler) HandleGetAnimeCollectionSchedule(c echo.Context) error {
 		return h.RespondWithData(c, ret)
 	}
 
-	animeSchedule, err := h.App.AnilistPlatform.GetAnimeAiringSchedule(c.Request().Context())
+	animeSchedule, err := h.getUserScope(c).GetPlatform().GetAnimeAiringSchedule(c.Request().Context())
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
 
-	animeCollection, err := h.App.GetAnimeCollection(false)
+	animeCollection, err := h.getUserScope(c).GetAnimeCollection(false)
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
@@ -271,12 +271,12 @@ func (h *Handler) HandleAddUnknownMedia(c echo.Context) error {
 	}
 
 	// Add non-added media entries to AniList collection
-	if err := h.App.AnilistPlatform.AddMediaToCollection(c.Request().Context(), b.MediaIds); err != nil {
+	if err := h.getUserScope(c).GetPlatform().AddMediaToCollection(c.Request().Context(), b.MediaIds); err != nil {
 		return h.RespondWithError(c, errors.New("error: Anilist responded with an error, this is most likely a rate limit issue"))
 	}
 
 	// Bypass the cache
-	animeCollection, err := h.App.GetAnimeCollection(true)
+	animeCollection, err := h.getUserScope(c).GetAnimeCollection(true)
 	if err != nil {
 		return h.RespondWithError(c, errors.New("error: Anilist responded with an error, wait one minute before refreshing"))
 	}
@@ -53,7 +53,7 @@ func (h *Handler) HandleGetAnimeEntry(c echo.Context) error {
 	}
 
 	// Get the user's anilist collection
-	animeCollection, err := h.App.GetAnimeCollection(false)
+	animeCollection, err := h.getUserScope(c).GetAnimeCollection(false)
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
@@ -67,9 +67,9 @@ func (h *Handler) HandleGetAnimeEntry(c echo.Context) error {
 		MediaId:          mId,
 		LocalFiles:       lfs,
 		AnimeCollection:  animeCollection,
-		Platform:         h.App.AnilistPlatform,
+		Platform:         h.getUserScope(c).GetPlatform(),
 		MetadataProvider: h.App.MetadataProvider,
-		IsSimulated:      h.App.GetUser().IsSimulated,
+		IsSimulated:      h.getUserScope(c).GetUser().IsSimulated,
 	})
 	if err != nil {
 		return h.RespondWithError(c, err)
@@ -292,7 +292,7 @@ func (h *Handler) HandleFetchAnimeEntrySuggestions(c echo.Context) error {
 		nil,
 		nil,
 		h.App.Logger,
-		h.App.GetUserAnilistToken(),
+		h.getUserScope(c).GetUserAnilistToken(),
 	)
 	if err != nil {
 		return h.RespondWithError(c, err)
@@ -327,7 +327,7 @@ func (h *Handler) HandleAnimeEntryManualMatch(c echo.Context) error {
 		return h.RespondWithError(c, err)
 	}
 
-	animeCollectionWithRelations, err := h.App.AnilistPlatform.GetAnimeCollectionWithRelations(c.Request().Context())
+	animeCollectionWithRelations, err := h.getUserScope(c).GetPlatform().GetAnimeCollectionWithRelations(c.Request().Context())
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
@@ -358,7 +358,7 @@ func (h *Handler) HandleAnimeEntryManualMatch(c echo.Context) error {
 	})
 
 	// Get the media
-	media, err := h.App.AnilistPlatform.GetAnime(c.Request().Context(), b.MediaId)
+	media, err := h.getUserScope(c).GetPlatform().GetAnime(c.Request().Context(), b.MediaId)
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
@@ -379,7 +379,7 @@ func (h *Handler) HandleAnimeEntryManualMatch(c echo.Context) error {
 	fh := scanner.FileHydrator{
 		LocalFiles:         selectedLfs,
 		CompleteAnimeCache: anilist.NewCompleteAnimeCache(),
-		Platform:           h.App.AnilistPlatform,
+		Platform:           h.getUserScope(c).GetPlatform(),
 		MetadataProvider:   h.App.MetadataProvider,
 		AnilistRateLimiter: limiter.NewAnilistLimiter(),
 		Logger:             h.App.Logger,
@@ -451,7 +451,7 @@ func (h *Handler) HandleGetMissingEpisodes(c echo.Context) error {
 	// Get the user's anilist collection
 	// Do not bypass the cache, since this handler might be called multiple times, and we don't want to spam the API
 	// A cron job will refresh the cache every 10 minutes
-	animeCollection, err := h.App.GetAnimeCollection(false)
+	animeCollection, err := h.getUserScope(c).GetAnimeCollection(false)
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
@@ -570,7 +570,7 @@ func (h *Handler) HandleUpdateAnimeEntryProgress(c echo.Context) error {
 	}
 
 	// Update the progress on AniList
-	err := h.App.AnilistPlatform.UpdateEntryProgress(
+	err := h.getUserScope(c).GetPlatform().UpdateEntryProgress(
 		c.Request().Context(),
 		b.MediaId,
 		b.EpisodeNumber,
@@ -580,7 +580,7 @@ func (h *Handler) HandleUpdateAnimeEntryProgress(c echo.Context) error {
 		return h.RespondWithError(c, err)
 	}
 
-	_, _ = h.App.RefreshAnimeCollection() // Refresh the AniList collection
+	_, _ = h.getUserScope(c).RefreshAnimeCollection() // Refresh the AniList collection
 
 	return h.RespondWithData(c, true)
 }
@@ -606,7 +606,7 @@ func (h *Handler) HandleUpdateAnimeEntryRepeat(c echo.Context) error {
 		return h.RespondWithError(c, err)
 	}
 
-	err := h.App.AnilistPlatform.UpdateEntryRepeat(
+	err := h.getUserScope(c).GetPlatform().UpdateEntryRepeat(
 		c.Request().Context(),
 		b.MediaId,
 		b.Repeat,
@@ -615,7 +615,7 @@ func (h *Handler) HandleUpdateAnimeEntryRepeat(c echo.Context) error {
 		return h.RespondWithError(c, err)
 	}
 
-	//_, _ = h.App.RefreshAnimeCollection() // Refresh the AniList collection
+	//_, _ = h.getUserScope(c).RefreshAnimeCollection() // Refresh the AniList collection
 
 	return h.RespondWithData(c, true)
 }
@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"errors"
+	"seanime/internal/api/anilist"
 	"seanime/internal/database/models"
 	"seanime/internal/platforms/anilist_platform"
 	"seanime/internal/platforms/simulated_platform"
@@ -19,6 +20,7 @@ import (
 //	@desc This is called when the JWT token is obtained from AniList after logging in with redirection on the client.
 //	@desc It also fetches the Viewer data from AniList and saves it in the database.
 //	@desc It creates a new handlers.Status and refreshes App modules.
+//	@desc The account of a profile other than the main profile is saved in the profile, App modules are not refreshed.
 //	@route /api/v1/auth/login [POST]
 //	@returns handlers.Status
 func (h *Handler) HandleLogin(c echo.Context) error {
@@ -33,6 +35,38 @@ func (h *Handler) HandleLogin(c echo.Context) error {
 		return h.RespondWithError(c, err)
 	}
 
+	// The account of a profile other than the main profile is saved in the profile
+	scope := h.getUserScope(c)
+	if !scope.IsMainProfile() {
+		getViewer, err := anilist.NewAnilistClient(b.Token).GetViewer(context.Background())
+		if err != nil {
+			h.App.Logger.Error().Msg("Could not authenticate to AniList")
+			return h.RespondWithError(c, err)
+		}
+
+		if len(getViewer.Viewer.Name) == 0 {
+			return h.RespondWithError(c, errors.New("could not find user"))
+		}
+
+		bytes, err := json.Marshal(getViewer.Viewer)
+		if err != nil {
+			return h.RespondWithError(c, err)
+		}
+
+		err = scope.SetAccount(&models.Account{
+			Username: getViewer.Viewer.Name,
+			Token:    b.Token,
+			Viewer:   bytes,
+		})
+		if err != nil {
+			return h.RespondWithError(c, err)
+		}
+
+		h.App.Logger.Info().Uint("profileId", scope.GetProfileId()).Msg("app: Authenticated profile to AniList")
+
+		return h.RespondWithData(c, h.NewStatus(c))
+	}
+
 	// Set a new AniList client by passing to JWT token
 	h.App.UpdateAnilistClientToken(b.Token)
 
@@ -73,6 +107,7 @@ func (h *Handler) HandleLogin(c echo.Context) error {
 	// Update the platform
 	anilistPlatform := anilist_platform.NewAnilistPlatform(h.App.AnilistClient, h.App.Logger)
 	h.App.UpdatePlatform(anilistPlatform)
+	h.App.UseMalPlatformIfSelected()
 
 	// Create a new status
 	status := h.NewStatus(c)
@@ -98,10 +133,23 @@ func (h *Handler) HandleLogin(c echo.Context) error {
 //	@summary logs out the user by removing JWT token from the database.
 //	@desc It removes JWT token and Viewer data from the database.
 //	@desc It creates a new handlers.Status and refreshes App modules.
+//	@desc A profile other than the main profile only has its own account removed.
 //	@route /api/v1/auth/logout [POST]
 //	@returns handlers.Status
 func (h *Handler) HandleLogout(c echo.Context) error {
 
+	// Only the account of the profile is removed
+	scope := h.getUserScope(c)
+	if !scope.IsMainProfile() {
+		if err := scope.SetAccount(&models.Account{}); err != nil {
+			return h.RespondWithError(c, err)
+		}
+
+		h.App.Logger.Info().Uint("profileId", scope.GetProfileId()).Msg("Logged profile out of AniList")
+
+		return h.RespondWithData(c, h.NewStatus(c))
+	}
+
 	// Update the anilist client
 	h.App.UpdateAnilistClientToken("")
 
@@ -111,6 +159,7 @@ func (h *Handler) HandleLogout(c echo.Context) error {
 		return h.RespondWithError(c, err)
 	}
 	h.App.UpdatePlatform(simulatedPlatform)
+	h.App.UseMalPlatformIfSelected()
 
 	_, err = h.App.Database.UpsertAccount(&models.Account{
 		BaseModel: models.BaseModel{
@@ -3,6 +3,7 @@ package handlers
 import (
 	"seanime/internal/continuity"
 	"strconv"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
@@ -24,7 +25,32 @@ func (h *Handler) HandleUpdateContinuityWatchHistoryItem(c echo.Context) error {
 		return h.RespondWithError(c, err)
 	}
 
-	err := h.App.ContinuityManager.UpdateWatchHistoryItem(&b.Options)
+	// Record the progress of the web players in the watch event log
+	clientId, _ := c.Get("Seanime-Client-Id").(string)
+	switch b.Options.Kind {
+	case continuity.OnlinestreamKind:
+		h.getUserScope(c).GetContinuityManager().TrackWatchEvent(&continuity.TrackWatchEventOptions{
+			MediaId:       b.Options.MediaId,
+			EpisodeNumber: b.Options.EpisodeNumber,
+			CurrentTime:   b.Options.CurrentTime,
+			Duration:      b.Options.Duration,
+			Player:        string(continuity.OnlinestreamKind),
+			Source:        continuity.OnlineWatchSource,
+			ClientId:      clientId,
+		})
+	case continuity.MediastreamKind:
+		h.getUserScope(c).GetContinuityManager().TrackWatchEvent(&continuity.TrackWatchEventOptions{
+			MediaId:       b.Options.MediaId,
+			EpisodeNumber: b.Options.EpisodeNumber,
+			CurrentTime:   b.Options.CurrentTime,
+			Duration:      b.Options.Duration,
+			Player:        string(continuity.MediastreamKind),
+			Source:        continuity.LocalWatchSource,
+			ClientId:      clientId,
+		})
+	}
+
+	err := h.getUserScope(c).GetContinuityManager().UpdateWatchHistoryItem(&b.Options)
 	if err != nil {
 		// Ignore the error
 		return h.RespondWithError(c, err)
@@ -46,14 +72,14 @@ func (h *Handler) HandleGetContinuityWatchHistoryItem(c echo.Context) error {
 		return h.RespondWithError(c, err)
 	}
 
-	if !h.App.ContinuityManager.GetSettings().WatchContinuityEnabled {
+	if !h.getUserScope(c).GetContinuityManager().GetSettings().WatchContinuityEnabled {
 		return h.RespondWithData(c, &continuity.WatchHistoryItemResponse{
 			Item:  nil,
 			Found: false,
 		})
 	}
 
-	resp := h.App.ContinuityManager.GetWatchHistoryItem(id)
+	resp := h.getUserScope(c).GetContinuityManager().GetWatchHistoryItem(id)
 	return h.RespondWithData(c, resp)
 }
 
@@ -64,11 +90,156 @@ func (h *Handler) HandleGetContinuityWatchHistoryItem(c echo.Context) error {
 //	@route /api/v1/continuity/history [GET]
 //	@returns continuity.WatchHistory
 func (h *Handler) HandleGetContinuityWatchHistory(c echo.Context) error {
-	if !h.App.ContinuityManager.GetSettings().WatchContinuityEnabled {
+	if !h.getUserScope(c).GetContinuityManager().GetSettings().WatchContinuityEnabled {
 		ret := make(map[int]*continuity.WatchHistoryItem)
 		return h.RespondWithData(c, ret)
 	}
 
-	resp := h.App.ContinuityManager.GetWatchHistory()
+	resp := h.getUserScope(c).GetContinuityManager().GetWatchHistory()
 	return h.RespondWithData(c, resp)
 }
+
+// HandleGetContinuityWatchEvents
+//
+//	@summary returns the watch event log.
+//	@desc Each event is a viewing session of an episode, with the time actually spent watching it.
+//	@desc If mediaId is 0, the events of all media are returned. The time range defaults to the last 30 days.
+//	@route /api/v1/continuity/events [POST]
+//	@returns []models.WatchEvent
+func (h *Handler) HandleGetContinuityWatchEvents(c echo.Context) error {
+	type body struct {
+		MediaId int        `json:"mediaId"`
+		From    *time.Time `json:"from"`
+		To      *time.Time `json:"to"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	from, to := getWatchEventsTimeRange(b.From, b.To)
+
+	events, err := h.getUserScope(c).GetContinuityManager().GetWatchEvents(b.MediaId, from, to)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, events)
+}
+
+// HandleDeleteContinuityWatchEvent
+//
+//	@summary deletes an event from the watch event log.
+//	@route /api/v1/continuity/events/{id} [DELETE]
+//	@param id - int - true - "The watch event ID"
+//	@returns bool
+func (h *Handler) HandleDeleteContinuityWatchEvent(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	if err := h.getUserScope(c).GetContinuityManager().DeleteWatchEvent(uint(id)); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, true)
+}
+
+// HandleGetContinuityWatchStats
+//
+//	@summary returns statistics computed from the watch event log.
+//	@desc Time watched is aggregated per day, week, series, genre and source. Completed episodes that had already been completed are counted as rewatches.
+//	@desc Genres are taken from the anime collection, media that are not in the collection are not counted per genre.
+//	@desc The time range defaults to the last 30 days.
+//	@route /api/v1/continuity/stats [POST]
+//	@returns continuity.WatchStats
+func (h *Handler) HandleGetContinuityWatchStats(c echo.Context) error {
+	type body struct {
+		From *time.Time `json:"from"`
+		To   *time.Time `json:"to"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	from, to := getWatchEventsTimeRange(b.From, b.To)
+
+	genres := make(map[int][]string)
+	if animeCollection, err := h.getUserScope(c).GetAnimeCollection(false); err == nil && animeCollection != nil {
+		for _, list := range animeCollection.GetMediaListCollection().GetLists() {
+			for _, entry := range list.GetEntries() {
+				if entry.GetMedia() == nil {
+					continue
+				}
+				for _, genre := range entry.GetMedia().GetGenres() {
+					if genre != nil {
+						genres[entry.GetMedia().GetID()] = append(genres[entry.GetMedia().GetID()], *genre)
+					}
+				}
+			}
+		}
+	}
+
+	stats, err := h.getUserScope(c).GetContinuityManager().GetWatchStats(from, to, genres)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, stats)
+}
+
+func getWatchEventsTimeRange(from *time.Time, to *time.Time) (time.Time, time.Time) {
+	end := time.Now()
+	if to != nil {
+		end = *to
+	}
+	start := end.AddDate(0, 0, -30)
+	if from != nil {
+		start = *from
+	}
+	return start, end
+}
+
+// HandleExportContinuityWatchHistory
+//
+//	@summary exports the watch history.
+//	@desc The export can be imported in another Seanime instance to keep the resume positions.
+//	@route /api/v1/continuity/export [GET]
+//	@returns continuity.WatchHistoryExport
+func (h *Handler) HandleExportContinuityWatchHistory(c echo.Context) error {
+	export, err := h.getUserScope(c).GetContinuityManager().ExportWatchHistory()
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, export)
+}
+
+// HandleImportContinuityWatchHistory
+//
+//	@summary imports an exported watch history.
+//	@desc Imported items replace the existing ones only if they were updated more recently.
+//	@desc Returns the number of items that were imported.
+//	@route /api/v1/continuity/import [POST]
+//	@returns int
+func (h *Handler) HandleImportContinuityWatchHistory(c echo.Context) error {
+	type body struct {
+		Export continuity.WatchHistoryExport `json:"export"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	count, err := h.getUserScope(c).GetContinuityManager().ImportWatchHistory(&b.Export)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, count)
+}
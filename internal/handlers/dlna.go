@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"seanime/internal/dlna"
+	"seanime/internal/util"
+
+	"github.com/labstack/echo/v4"
+)
+
+//
+// DLNA media server
+// These routes are not behind the auth middleware, renderers cannot authenticate.
+// They are only served to the local network and respond with 404 when the server is disabled.
+//
+
+// dlnaLocalNetworkMiddleware rejects the requests that do not come from the local network.
+func dlnaLocalNetworkMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !dlna.IsLocalRequest(c.Request()) {
+			return c.NoContent(http.StatusForbidden)
+		}
+		return next(c)
+	}
+}
+
+func (h *Handler) HandleDlnaDeviceDescription(c echo.Context) error {
+	return h.App.DlnaServer.ServeDeviceDescription(c)
+}
+
+func (h *Handler) HandleDlnaServiceDescription(c echo.Context) error {
+	return h.App.DlnaServer.ServeServiceDescription(c, c.Param("service"))
+}
+
+func (h *Handler) HandleDlnaControl(c echo.Context) error {
+	return h.App.DlnaServer.ServeControl(c, c.Param("service"))
+}
+
+func (h *Handler) HandleDlnaEventSubscription(c echo.Context) error {
+	return h.App.DlnaServer.ServeEventSubscription(c)
+}
+
+func (h *Handler) HandleDlnaMedia(c echo.Context) error {
+	return h.App.DlnaServer.ServeMedia(c, c.Param("id"), func(c echo.Context, path string) error {
+		libraryPaths := h.App.Settings.GetLibrary().GetLibraryPaths()
+		return h.App.MediastreamRepository.ServeEchoFile(c, util.Base64EncodeStr(path), "dlna", libraryPaths)
+	})
+}
@@ -208,6 +208,17 @@ func (h *Handler) HandleListOnlinestreamProviderExtensions(c echo.Context) error
 	return h.RespondWithData(c, extensions)
 }
 
+// HandleListMediaPlayerExtensions
+//
+//	@summary returns the installed media player extensions.
+//	@desc The ID of a media player extension can be used as the default media player.
+//	@route /api/v1/extensions/list/mediaplayer [GET]
+//	@returns []extension_repo.MediaPlayerExtensionItem
+func (h *Handler) HandleListMediaPlayerExtensions(c echo.Context) error {
+	extensions := h.App.ExtensionRepository.ListMediaPlayerExtensions()
+	return h.RespondWithData(c, extensions)
+}
+
 // HandleListAnimeTorrentProviderExtensions
 //
 //	@summary returns the installed torrent providers.
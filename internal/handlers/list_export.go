@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"seanime/internal/events"
+	"seanime/internal/listexport"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HandleExportLists
+//
+//	@summary exports the anime or manga lists of the current account.
+//	@desc The lists of the simulated account are exported when no account is connected.
+//	@desc Format is "json" or "mal-xml". MyAnimeList XML files only contain the media of the given type ("anime" or "manga").
+//	@route /api/v1/lists/export [POST]
+//	@returns listexport.File
+func (h *Handler) HandleExportLists(c echo.Context) error {
+	type body struct {
+		Format listexport.Format    `json:"format"`
+		Type   listexport.MediaType `json:"type"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	ret, err := listexport.ExportFile(c.Request().Context(), h.getUserScope(c).GetPlatform(), b.Format, b.Type)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, ret)
+}
+
+// HandleImportLists
+//
+//	@summary imports exported anime and manga lists into the current account.
+//	@desc Format is "json" or "mal-xml". Entries of MyAnimeList exports are matched to AniList media.
+//	@desc The entries are imported in the background, returns the number of entries found in the file.
+//	@route /api/v1/lists/import [POST]
+//	@returns int
+func (h *Handler) HandleImportLists(c echo.Context) error {
+	type body struct {
+		Format  listexport.Format `json:"format"`
+		Content string            `json:"content"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	export, err := listexport.Parse(b.Format, []byte(b.Content))
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	count := len(export.Anime) + len(export.Manga)
+	if count == 0 {
+		return h.RespondWithError(c, errors.New("the file does not contain any entry"))
+	}
+
+	scope := h.getUserScope(c)
+	go func() {
+		ret := listexport.NewImporter(h.App.Logger, scope.GetPlatform()).Import(context.Background(), export)
+
+		_, _ = scope.RefreshAnimeCollection()
+		_, _ = scope.RefreshMangaCollection()
+		h.App.WSEventManager.SendEvent(events.RefreshedAnilistAnimeCollection, nil)
+		h.App.WSEventManager.SendEvent(events.RefreshedAnilistMangaCollection, nil)
+
+		if ret.Failed > 0 {
+			h.App.WSEventManager.SendEvent(events.WarningToast, fmt.Sprintf("Imported %d entries, %d could not be imported", ret.Imported, ret.Failed))
+			return
+		}
+		h.App.WSEventManager.SendEvent(events.SuccessToast, fmt.Sprintf("Imported %d entries", ret.Imported))
+	}()
+
+	return h.RespondWithData(c, count)
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"errors"
+	"seanime/internal/database/models"
+	"seanime/internal/listsync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HandleSaveListSyncSettings
+//
+//	@summary updates the AniList and MyAnimeList synchronization settings.
+//	@desc Origin is "anilist", "mal" or empty to keep the most recent change when both lists changed.
+//	@route /api/v1/settings/list-sync [PATCH]
+//	@returns bool
+func (h *Handler) HandleSaveListSyncSettings(c echo.Context) error {
+
+	type body struct {
+		Automatic bool   `json:"automatic"`
+		Origin    string `json:"origin"`
+	}
+
+	var b body
+
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	if b.Origin != listsync.OriginAnilist && b.Origin != listsync.OriginMal && b.Origin != listsync.OriginLatest {
+		return h.RespondWithError(c, errors.New("invalid origin"))
+	}
+
+	currSettings, err := h.App.Database.GetSettings()
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	listSyncSettings := &models.ListSyncSettings{
+		Automatic: b.Automatic,
+		Origin:    b.Origin,
+	}
+
+	currSettings.ListSync = listSyncSettings
+	currSettings.BaseModel = models.BaseModel{
+		ID:        1,
+		UpdatedAt: time.Now(),
+	}
+
+	_, err = h.App.Database.UpsertSettings(currSettings)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	h.App.ListSyncManager.SetSettings(listSyncSettings)
+
+	return h.RespondWithData(c, true)
+}
+
+// HandleListSyncPreview
+//
+//	@summary returns the changes needed to synchronize the AniList and MyAnimeList lists.
+//	@desc The preview is kept so that the same changes are applied by HandleListSyncApply.
+//	@route /api/v1/list-sync/preview [POST]
+//	@returns listsync.Preview
+func (h *Handler) HandleListSyncPreview(c echo.Context) error {
+	ret, err := h.App.ListSyncManager.Preview(c.Request().Context())
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, ret)
+}
+
+// HandleListSyncApply
+//
+//	@summary synchronizes the AniList and MyAnimeList lists.
+//	@desc The last preview is applied, a new one is computed if it is outdated.
+//	@route /api/v1/list-sync/apply [POST]
+//	@returns listsync.Result
+func (h *Handler) HandleListSyncApply(c echo.Context) error {
+	ret, err := h.App.ListSyncManager.Apply(c.Request().Context())
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, ret)
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"seanime/internal/events"
+	"seanime/internal/local"
 	"seanime/internal/util"
 	"strconv"
 
@@ -214,3 +216,166 @@ func (h *Handler) HandleLocalSyncSimulatedDataToAnilist(c echo.Context) error {
 	}
 	return h.RespondWithData(c, true)
 }
+
+// HandleLocalGetOfflineDownloadSettings
+//
+//	@summary gets the settings of the pre-download of upcoming episodes of tracked anime.
+//	@route /api/v1/local/offline-downloads/settings [GET]
+//	@returns local.OfflineDownloadSettings
+func (h *Handler) HandleLocalGetOfflineDownloadSettings(c echo.Context) error {
+	return h.RespondWithData(c, h.App.LocalManager.GetOfflineDownloadSettings())
+}
+
+// HandleLocalSaveOfflineDownloadSettings
+//
+//	@summary updates the settings of the pre-download of upcoming episodes of tracked anime.
+//	@desc The upcoming episodes are queued in the background after the settings are saved. The folder cannot overlap the library, if it changed the episodes of the previous folder are removed and downloaded again.
+//	@route /api/v1/local/offline-downloads/settings [PATCH]
+//	@returns local.OfflineDownloadSettings
+func (h *Handler) HandleLocalSaveOfflineDownloadSettings(c echo.Context) error {
+	type body struct {
+		Settings *local.OfflineDownloadSettings `json:"settings"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	previousDir := h.App.LocalManager.GetOfflineDownloadSettings().Dir
+
+	if err := h.App.LocalManager.SaveOfflineDownloadSettings(b.Settings); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	dirChanged := !util.IsSameDir(previousDir, h.App.LocalManager.GetOfflineDownloadSettings().Dir)
+
+	go func() {
+		// The episodes of the previous folder are dropped and downloaded again to the new folder
+		if dirChanged {
+			if err := h.App.OfflineDownloader.DropEpisodes(previousDir); err != nil {
+				h.App.Logger.Error().Err(err).Msg("offline downloader: Failed to remove the episodes of the previous folder")
+			}
+		}
+		_ = h.App.OfflineDownloader.Run()
+	}()
+
+	return h.RespondWithData(c, h.App.LocalManager.GetOfflineDownloadSettings())
+}
+
+// HandleLocalGetOfflineEpisodes
+//
+//	@summary gets the pre-downloaded episodes of tracked anime.
+//	@desc This includes the episodes that are being downloaded.
+//	@route /api/v1/local/offline-downloads [GET]
+//	@returns []local.OfflineEpisode
+func (h *Handler) HandleLocalGetOfflineEpisodes(c echo.Context) error {
+	return h.RespondWithData(c, h.App.LocalManager.GetOfflineEpisodes())
+}
+
+// HandleLocalRunOfflineDownloader
+//
+//	@summary checks the pre-downloaded episodes and queues the upcoming episodes of tracked anime.
+//	@desc This runs in the background, it is also run periodically.
+//	@route /api/v1/local/offline-downloads/run [POST]
+//	@returns bool
+func (h *Handler) HandleLocalRunOfflineDownloader(c echo.Context) error {
+	go func() {
+		if err := h.App.OfflineDownloader.Run(); err != nil {
+			h.App.Logger.Error().Err(err).Msg("offline downloader: Failed to run")
+		}
+		h.App.WSEventManager.SendEvent(events.InvalidateQueries, []string{events.LocalGetOfflineEpisodesEndpoint})
+	}()
+	return h.RespondWithData(c, true)
+}
+
+// HandleLocalDeleteOfflineEpisode
+//
+//	@summary removes a pre-downloaded episode.
+//	@desc This stops the download and deletes the files of the episode.
+//	@route /api/v1/local/offline-downloads/{id} [DELETE]
+//	@param id - int - true - "Offline episode ID"
+//	@returns bool
+func (h *Handler) HandleLocalDeleteOfflineEpisode(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	if err := h.App.OfflineDownloader.RemoveEpisode(uint(id)); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, true)
+}
+
+// HandleLocalGetSyncSettings
+//
+//	@summary gets the sync profile used when synchronizing tracked media.
+//	@route /api/v1/local/sync/settings [GET]
+//	@returns local.SyncSettings
+func (h *Handler) HandleLocalGetSyncSettings(c echo.Context) error {
+	return h.RespondWithData(c, h.App.LocalManager.GetSyncSettings())
+}
+
+// HandleLocalSaveSyncSettings
+//
+//	@summary updates the sync profile used when synchronizing tracked media.
+//	@desc The settings apply to the next synchronization.
+//	@route /api/v1/local/sync/settings [PATCH]
+//	@returns local.SyncSettings
+func (h *Handler) HandleLocalSaveSyncSettings(c echo.Context) error {
+	type body struct {
+		Settings *local.SyncSettings `json:"settings"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	if err := h.App.LocalManager.SaveSyncSettings(b.Settings); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, h.App.LocalManager.GetSyncSettings())
+}
+
+// HandleLocalEstimateSync
+//
+//	@summary estimates the space required by the next synchronization.
+//	@desc Nothing is downloaded. The media that don't fit in the storage budget are marked as skipped.
+//	@route /api/v1/local/sync/estimate [POST]
+//	@returns local.SyncEstimate
+func (h *Handler) HandleLocalEstimateSync(c echo.Context) error {
+	ret, err := h.App.LocalManager.EstimateSynchronizeLocal()
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+	return h.RespondWithData(c, ret)
+}
+
+// HandleLocalSetTrackedMediaPriority
+//
+//	@summary sets the sync priority of a tracked media.
+//	@desc Media with a higher priority are synchronized first when the storage budget is limited.
+//	@route /api/v1/local/track/priority [PATCH]
+//	@returns bool
+func (h *Handler) HandleLocalSetTrackedMediaPriority(c echo.Context) error {
+	type body struct {
+		MediaId  int    `json:"mediaId"`
+		Type     string `json:"type"`
+		Priority int    `json:"priority"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	if err := h.App.LocalManager.SetTrackedMediaPriority(b.MediaId, b.Type, b.Priority); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, true)
+}
@@ -72,16 +72,23 @@ func (h *Handler) HandleMALAuth(c echo.Context) error {
 		return h.RespondWithError(c, err)
 	}
 
+	// Keep the account platform setting when re-authenticating
+	isAccountPlatform := false
+	if prevMalInfo, err := h.App.Database.GetMalInfo(); err == nil {
+		isAccountPlatform = prevMalInfo.IsAccountPlatform
+	}
+
 	// Save
 	malInfo := models.Mal{
 		BaseModel: models.BaseModel{
 			ID:        1,
 			UpdatedAt: time.Now(),
 		},
-		Username:       "",
-		AccessToken:    ret.AccessToken,
-		RefreshToken:   ret.RefreshToken,
-		TokenExpiresAt: time.Now().Add(time.Duration(ret.ExpiresIn) * time.Second),
+		Username:          "",
+		AccessToken:       ret.AccessToken,
+		RefreshToken:      ret.RefreshToken,
+		TokenExpiresAt:    time.Now().Add(time.Duration(ret.ExpiresIn) * time.Second),
+		IsAccountPlatform: isAccountPlatform,
 	}
 
 	_, err = h.App.Database.UpsertMalInfo(&malInfo)
@@ -150,10 +157,64 @@ func (h *Handler) HandleEditMALListEntryProgress(c echo.Context) error {
 //	@returns bool
 func (h *Handler) HandleMALLogout(c echo.Context) error {
 
+	wasAccountPlatform := h.App.IsMalAccountPlatform()
+
 	err := h.App.Database.DeleteMalInfo()
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
 
+	// Go back to the AniList collections
+	if wasAccountPlatform {
+		if err := h.App.UseAnilistPlatform(); err != nil {
+			return h.RespondWithError(c, err)
+		}
+		h.App.InitOrRefreshModules()
+		h.App.InitOrRefreshAnilistData()
+	}
+
 	return h.RespondWithData(c, true)
 }
+
+// HandleSetMALAccountPlatform
+//
+//	@summary sets whether MyAnimeList is used as the account platform.
+//	@desc When enabled, the MyAnimeList lists are used as the anime and manga collections and list updates are sent to MyAnimeList.
+//	@desc Media data is still fetched from AniList. MyAnimeList must be connected.
+//	@desc The client should re-fetch the server status after this.
+//	@route /api/v1/mal/account-platform [POST]
+//	@returns handlers.Status
+func (h *Handler) HandleSetMALAccountPlatform(c echo.Context) error {
+
+	type body struct {
+		Enabled bool `json:"enabled"`
+	}
+
+	b := new(body)
+	if err := c.Bind(b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	malInfo, err := h.App.Database.GetMalInfo()
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	malInfo.IsAccountPlatform = b.Enabled
+	if _, err = h.App.Database.UpsertMalInfo(malInfo); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	// Update the platform
+	if !h.App.UseMalPlatformIfSelected() {
+		if err := h.App.UseAnilistPlatform(); err != nil {
+			return h.RespondWithError(c, err)
+		}
+	}
+
+	h.App.InitOrRefreshModules()
+
+	h.App.InitOrRefreshAnilistData()
+
+	return h.RespondWithData(c, h.NewStatus(c))
+}
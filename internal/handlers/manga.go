@@ -39,7 +39,7 @@ func (h *Handler) HandleGetAnilistMangaCollection(c echo.Context) error {
 		return h.RespondWithError(c, err)
 	}
 
-	collection, err := h.App.GetMangaCollection(b.BypassCache)
+	collection, err := h.getUserScope(c).GetMangaCollection(b.BypassCache)
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
@@ -57,7 +57,7 @@ func (h *Handler) HandleGetRawAnilistMangaCollection(c echo.Context) error {
 	bypassCache := c.Request().Method == "POST"
 
 	// Get the user's anilist collection
-	mangaCollection, err := h.App.GetRawMangaCollection(bypassCache)
+	mangaCollection, err := h.getUserScope(c).GetRawMangaCollection(bypassCache)
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
@@ -75,14 +75,14 @@ func (h *Handler) HandleGetRawAnilistMangaCollection(c echo.Context) error {
 //	@returns manga.Collection
 func (h *Handler) HandleGetMangaCollection(c echo.Context) error {
 
-	animeCollection, err := h.App.GetMangaCollection(false)
+	animeCollection, err := h.getUserScope(c).GetMangaCollection(false)
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
 
 	collection, err := manga.NewCollection(&manga.NewCollectionOptions{
 		MangaCollection: animeCollection,
-		Platform:        h.App.AnilistPlatform,
+		Platform:        h.getUserScope(c).GetPlatform(),
 	})
 	if err != nil {
 		return h.RespondWithError(c, err)
@@ -105,7 +105,7 @@ func (h *Handler) HandleGetMangaEntry(c echo.Context) error {
 		return h.RespondWithError(c, err)
 	}
 
-	animeCollection, err := h.App.GetMangaCollection(false)
+	animeCollection, err := h.getUserScope(c).GetMangaCollection(false)
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
@@ -114,7 +114,7 @@ func (h *Handler) HandleGetMangaEntry(c echo.Context) error {
 		MediaId:         id,
 		Logger:          h.App.Logger,
 		FileCacher:      h.App.FileCacher,
-		Platform:        h.App.AnilistPlatform,
+		Platform:        h.getUserScope(c).GetPlatform(),
 		MangaCollection: animeCollection,
 	})
 	if err != nil {
@@ -146,7 +146,7 @@ func (h *Handler) HandleGetMangaEntryDetails(c echo.Context) error {
 		return h.RespondWithData(c, detailsMedia)
 	}
 
-	details, err := h.App.AnilistPlatform.GetMangaDetails(c.Request().Context(), id)
+	details, err := h.getUserScope(c).GetPlatform().GetMangaDetails(c.Request().Context(), id)
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
@@ -188,7 +188,7 @@ func (h *Handler) HandleRefetchMangaChapterContainers(c echo.Context) error {
 		return h.RespondWithError(c, err)
 	}
 
-	mangaCollection, err := h.App.GetMangaCollection(false)
+	mangaCollection, err := h.getUserScope(c).GetMangaCollection(false)
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
@@ -248,7 +248,7 @@ func (h *Handler) HandleGetMangaEntryChapters(c echo.Context) error {
 	baseManga, found := baseMangaCache.Get(b.MediaId)
 	if !found {
 		var err error
-		baseManga, err = h.App.AnilistPlatform.GetManga(c.Request().Context(), b.MediaId)
+		baseManga, err = h.getUserScope(c).GetPlatform().GetManga(c.Request().Context(), b.MediaId)
 		if err != nil {
 			return h.RespondWithError(c, err)
 		}
@@ -316,7 +316,7 @@ func (h *Handler) HandleGetMangaEntryDownloadedChapters(c echo.Context) error {
 		return h.RespondWithError(c, err)
 	}
 
-	mangaCollection, err := h.App.GetMangaCollection(false)
+	mangaCollection, err := h.getUserScope(c).GetMangaCollection(false)
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
@@ -405,7 +405,7 @@ func (h *Handler) HandleAnilistListManga(c echo.Context) error {
 		p.CountryOfOrigin,
 		&isAdult,
 		h.App.Logger,
-		h.App.GetUserAnilistToken(),
+		h.getUserScope(c).GetUserAnilistToken(),
 	)
 	if err != nil {
 		return h.RespondWithError(c, err)
@@ -439,7 +439,7 @@ func (h *Handler) HandleUpdateMangaProgress(c echo.Context) error {
 	}
 
 	// Update the progress on AniList
-	err := h.App.AnilistPlatform.UpdateEntryProgress(
+	err := h.getUserScope(c).GetPlatform().UpdateEntryProgress(
 		c.Request().Context(),
 		b.MediaId,
 		b.ChapterNumber,
@@ -449,7 +449,7 @@ func (h *Handler) HandleUpdateMangaProgress(c echo.Context) error {
 		return h.RespondWithError(c, err)
 	}
 
-	_, _ = h.App.RefreshMangaCollection() // Refresh the AniList collection
+	_, _ = h.getUserScope(c).RefreshMangaCollection() // Refresh the AniList collection
 
 	return h.RespondWithData(c, true)
 }
@@ -194,7 +194,7 @@ func (h *Handler) HandleDeleteMangaDownloadedChapters(c echo.Context) error {
 //	@returns []manga.DownloadListItem
 func (h *Handler) HandleGetMangaDownloadsList(c echo.Context) error {
 
-	mangaCollection, err := h.App.GetMangaCollection(false)
+	mangaCollection, err := h.getUserScope(c).GetMangaCollection(false)
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
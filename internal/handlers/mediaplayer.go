@@ -1,6 +1,10 @@
 package handlers
 
 import (
+	"context"
+	"seanime/internal/mediaplayers/upnp"
+	"time"
+
 	"github.com/labstack/echo/v4"
 )
 
@@ -28,7 +32,36 @@ func (h *Handler) HandleStartDefaultMediaPlayer(c echo.Context) error {
 		if err != nil {
 			return h.RespondWithError(c, err)
 		}
+	case "kodi":
+		// Kodi cannot be launched remotely, check that it can be reached
+		err = h.App.MediaPlayer.Kodi.Ping()
+		if err != nil {
+			return h.RespondWithError(c, err)
+		}
+	case "upnp":
+		err = h.App.MediaPlayer.Upnp.Connect()
+		if err != nil {
+			return h.RespondWithError(c, err)
+		}
 	}
 
 	return h.RespondWithData(c, true)
 }
+
+// HandleDiscoverUpnpRenderers
+//
+//	@summary returns the UPnP/DLNA media renderers found on the local network.
+//	@desc The location of a renderer is used as the 'upnpRendererLocation' media player setting.
+//	@route /api/v1/media-player/upnp/renderers [GET]
+//	@returns []upnp.DiscoveredRenderer
+func (h *Handler) HandleDiscoverUpnpRenderers(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 3*time.Second)
+	defer cancel()
+
+	renderers, err := upnp.Discover(ctx)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, renderers)
+}
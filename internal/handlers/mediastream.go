@@ -3,10 +3,13 @@ package handlers
 import (
 	"errors"
 	"fmt"
+	"seanime/internal/database/db_bridge"
 	"seanime/internal/database/models"
+	"seanime/internal/library/anime"
 	"seanime/internal/mediastream"
 
 	"github.com/labstack/echo/v4"
+	"github.com/samber/lo"
 )
 
 // HandleGetMediastreamSettings
@@ -59,10 +62,11 @@ func (h *Handler) HandleSaveMediastreamSettings(c echo.Context) error {
 func (h *Handler) HandleRequestMediastreamMediaContainer(c echo.Context) error {
 
 	type body struct {
-		Path             string                 `json:"path"`             // The path of the file.
-		StreamType       mediastream.StreamType `json:"streamType"`       // The type of stream to request.
-		AudioStreamIndex int                    `json:"audioStreamIndex"` // The audio stream index to use. (unused)
-		ClientId         string                 `json:"clientId"`         // The session id
+		Path                string                 `json:"path"`                // The path of the file.
+		StreamType          mediastream.StreamType `json:"streamType"`          // The type of stream to request.
+		AudioStreamIndex    int                    `json:"audioStreamIndex"`    // The audio stream index to use. (unused)
+		ClientId            string                 `json:"clientId"`            // The session id
+		BurnInSubtitleIndex *uint32                `json:"burnInSubtitleIndex"` // The subtitle track to burn into the video. (transcode only)
 	}
 
 	var b body
@@ -77,7 +81,7 @@ func (h *Handler) HandleRequestMediastreamMediaContainer(c echo.Context) error {
 	case mediastream.StreamTypeDirect:
 		mediaContainer, err = h.App.MediastreamRepository.RequestDirectPlay(b.Path, b.ClientId)
 	case mediastream.StreamTypeTranscode:
-		mediaContainer, err = h.App.MediastreamRepository.RequestTranscodeStream(b.Path, b.ClientId)
+		mediaContainer, err = h.App.MediastreamRepository.RequestTranscodeStream(b.Path, b.ClientId, b.BurnInSubtitleIndex)
 	case mediastream.StreamTypeOptimized:
 		err = fmt.Errorf("stream type %s not implemented", b.StreamType)
 		//mediaContainer, err = h.App.MediastreamRepository.RequestOptimizedStream(b.Path)
@@ -88,7 +92,12 @@ func (h *Handler) HandleRequestMediastreamMediaContainer(c echo.Context) error {
 		return h.RespondWithError(c, err)
 	}
 
-	return h.RespondWithData(c, mediaContainer)
+	// Select the tracks matching the track preference of the media, on a copy since the media container is cached
+	ret := *mediaContainer
+	mediaId := h.App.TrackPreferenceManager.GetMediaIdByPath(b.Path)
+	ret.TrackSelection = h.App.TrackPreferenceManager.SelectMediaInfoTracks(mediaId, mediaContainer.MediaInfo)
+
+	return h.RespondWithData(c, &ret)
 }
 
 // HandlePreloadMediastreamMediaContainer
@@ -135,6 +144,51 @@ func (h *Handler) HandleMediastreamGetAttachments(c echo.Context) error {
 	return h.App.MediastreamRepository.ServeEchoExtractedAttachments(c)
 }
 
+//
+// Trickplay
+//
+
+// HandleGenerateMediastreamTrickplay
+//
+//	@summary generates the seek preview thumbnails of the local files of an anime.
+//	@desc The files are processed in the background, the thumbnails are referenced by the media container and the native player once generated.
+//	@returns bool
+//	@route /api/v1/mediastream/trickplay [POST]
+func (h *Handler) HandleGenerateMediastreamTrickplay(c echo.Context) error {
+
+	type body struct {
+		MediaId int `json:"mediaId"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	lfs, _, err := db_bridge.GetLocalFiles(h.App.Database)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	paths := lo.FilterMap(lfs, func(lf *anime.LocalFile, _ int) (string, bool) {
+		return lf.GetPath(), lf.MediaId == b.MediaId
+	})
+	if len(paths) == 0 {
+		return h.RespondWithError(c, errors.New("no local files found"))
+	}
+
+	err = h.App.MediastreamRepository.QueueTrickplayGeneration(paths)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, true)
+}
+
+func (h *Handler) HandleMediastreamGetTrickplay(c echo.Context) error {
+	return h.App.MediastreamRepository.ServeEchoTrickplay(c)
+}
+
 //
 // Direct
 //
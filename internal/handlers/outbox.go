@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HandleGetOutboxItems
+//
+//	@summary returns the list changes waiting to be sent to the account platform.
+//	@desc Changes are queued when AniList or MyAnimeList cannot be reached and sent once it is reachable again.
+//	@desc Failed changes and changes conflicting with the remote entry stay in the queue until they are retried or discarded.
+//	@route /api/v1/outbox [GET]
+//	@returns []outbox.Item
+func (h *Handler) HandleGetOutboxItems(c echo.Context) error {
+	items, err := h.App.OutboxManager.GetItems()
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, items)
+}
+
+// HandleRetryOutboxItem
+//
+//	@summary sends a queued list change again.
+//	@desc If the change conflicts with the remote entry, the remote entry is overwritten.
+//	@route /api/v1/outbox/{id}/retry [POST]
+//	@param id - int - true - "The outbox item ID"
+//	@returns bool
+func (h *Handler) HandleRetryOutboxItem(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	if err := h.App.OutboxManager.Retry(uint(id)); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, true)
+}
+
+// HandleDiscardOutboxItem
+//
+//	@summary removes a queued list change without sending it.
+//	@route /api/v1/outbox/{id} [DELETE]
+//	@param id - int - true - "The outbox item ID"
+//	@returns bool
+func (h *Handler) HandleDiscardOutboxItem(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	if err := h.App.OutboxManager.Discard(uint(id)); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, true)
+}
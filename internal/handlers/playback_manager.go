@@ -100,6 +100,11 @@ func (h *Handler) HandlePlaybackPlayNextEpisode(c echo.Context) error {
 //	@returns *anime.LocalFile
 func (h *Handler) HandlePlaybackGetNextEpisode(c echo.Context) error {
 
+	// The playback queue plays the next item itself
+	if h.App.PlaybackQueue.IsActive() {
+		return h.RespondWithData(c, nil)
+	}
+
 	lf := h.App.PlaybackManager.GetNextEpisode()
 	return h.RespondWithData(c, lf)
 }
@@ -112,6 +117,10 @@ func (h *Handler) HandlePlaybackGetNextEpisode(c echo.Context) error {
 //	@returns bool
 func (h *Handler) HandlePlaybackAutoPlayNextEpisode(c echo.Context) error {
 
+	if h.App.PlaybackQueue.IsActive() {
+		return h.RespondWithData(c, false)
+	}
+
 	err := h.App.PlaybackManager.AutoPlayNextEpisode()
 	if err != nil {
 		return h.RespondWithError(c, err)
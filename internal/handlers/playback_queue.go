@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"seanime/internal/library/playbackqueue"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HandleGetPlaybackQueue
+//
+//	@summary returns the playback queue.
+//	@route /api/v1/playback-queue [GET]
+//	@returns playbackqueue.State
+func (h *Handler) HandleGetPlaybackQueue(c echo.Context) error {
+	return h.RespondWithData(c, h.App.PlaybackQueue.GetState())
+}
+
+// HandlePlaybackQueueAddItems
+//
+//	@summary adds items to the playback queue.
+//	@desc Items are appended unless an index is given or 'next' is true, in which case they are added after the current item.
+//	@route /api/v1/playback-queue/items [POST]
+//	@returns playbackqueue.State
+func (h *Handler) HandlePlaybackQueueAddItems(c echo.Context) error {
+	type body struct {
+		Items []*playbackqueue.Item `json:"items"`
+		Index *int                  `json:"index"`
+		Next  bool                  `json:"next"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	index := -1
+	if b.Index != nil {
+		index = *b.Index
+	}
+
+	if err := h.App.PlaybackQueue.Add(c.Request().Context(), b.Items, index, b.Next); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, h.App.PlaybackQueue.GetState())
+}
+
+// HandlePlaybackQueueRemoveItem
+//
+//	@summary removes an item from the playback queue.
+//	@desc Removing the item being played plays the following item.
+//	@route /api/v1/playback-queue/items [DELETE]
+//	@returns playbackqueue.State
+func (h *Handler) HandlePlaybackQueueRemoveItem(c echo.Context) error {
+	type body struct {
+		ItemId string `json:"itemId"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	if err := h.App.PlaybackQueue.Remove(c.Request().Context(), b.ItemId); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, h.App.PlaybackQueue.GetState())
+}
+
+// HandlePlaybackQueueMoveItem
+//
+//	@summary moves an item of the playback queue to the given index.
+//	@desc This can be done while the queue is playing.
+//	@route /api/v1/playback-queue/items/move [POST]
+//	@returns playbackqueue.State
+func (h *Handler) HandlePlaybackQueueMoveItem(c echo.Context) error {
+	type body struct {
+		ItemId string `json:"itemId"`
+		Index  int    `json:"index"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	if err := h.App.PlaybackQueue.Move(b.ItemId, b.Index); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, h.App.PlaybackQueue.GetState())
+}
+
+// HandlePlaybackQueueClear
+//
+//	@summary removes all the items of the playback queue.
+//	@route /api/v1/playback-queue [DELETE]
+//	@returns bool
+func (h *Handler) HandlePlaybackQueueClear(c echo.Context) error {
+	h.App.PlaybackQueue.Clear()
+	return h.RespondWithData(c, true)
+}
+
+// HandlePlaybackQueuePlay
+//
+//	@summary plays an item of the playback queue.
+//	@desc If no item is given, the next item is played.
+//	@desc The queue then drives the playback and plays the next item when the current one has been watched.
+//	@route /api/v1/playback-queue/play [POST]
+//	@returns bool
+func (h *Handler) HandlePlaybackQueuePlay(c echo.Context) error {
+	type body struct {
+		ItemId   string                   `json:"itemId"`
+		Player   playbackqueue.PlayerType `json:"player"`
+		ClientId string                   `json:"clientId"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	err := h.App.PlaybackQueue.Play(c.Request().Context(), &playbackqueue.PlayOptions{
+		ItemId:    b.ItemId,
+		Player:    b.Player,
+		ClientId:  b.ClientId,
+		UserAgent: c.Request().Header.Get("User-Agent"),
+	})
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, true)
+}
+
+// HandlePlaybackQueueNext
+//
+//	@summary plays the next item of the playback queue.
+//	@desc This is also called by the client when an online stream episode of the queue ends.
+//	@route /api/v1/playback-queue/next [POST]
+//	@returns bool
+func (h *Handler) HandlePlaybackQueueNext(c echo.Context) error {
+	if err := h.App.PlaybackQueue.PlayNext(c.Request().Context()); err != nil {
+		return h.RespondWithError(c, err)
+	}
+	return h.RespondWithData(c, true)
+}
+
+// HandlePlaybackQueuePrevious
+//
+//	@summary plays the previous item of the playback queue.
+//	@route /api/v1/playback-queue/previous [POST]
+//	@returns bool
+func (h *Handler) HandlePlaybackQueuePrevious(c echo.Context) error {
+	if err := h.App.PlaybackQueue.PlayPrevious(c.Request().Context()); err != nil {
+		return h.RespondWithError(c, err)
+	}
+	return h.RespondWithData(c, true)
+}
+
+// HandlePlaybackQueueStop
+//
+//	@summary stops the playback queue from playing the next items.
+//	@desc The items are kept.
+//	@route /api/v1/playback-queue/stop [POST]
+//	@returns bool
+func (h *Handler) HandlePlaybackQueueStop(c echo.Context) error {
+	h.App.PlaybackQueue.Stop()
+	return h.RespondWithData(c, true)
+}
+
+// HandlePlaybackQueueGetWatchOrder
+//
+//	@summary returns the episodes of the franchise of a media in the given watch order.
+//	@desc Episodes in the library are played from local files, the others use the fallback stream type.
+//	@desc If 'replace' is true, the queue is replaced by the episodes.
+//	@route /api/v1/playback-queue/watch-order [POST]
+//	@returns []playbackqueue.Item
+func (h *Handler) HandlePlaybackQueueGetWatchOrder(c echo.Context) error {
+	type body struct {
+		MediaId      int                                `json:"mediaId"`
+		Order        playbackqueue.WatchOrder           `json:"order"`
+		Fallback     playbackqueue.ItemType             `json:"fallback"`
+		OnlineStream *playbackqueue.OnlineStreamOptions `json:"onlineStream"`
+		Replace      bool                               `json:"replace"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	items, err := h.App.PlaybackQueue.GetWatchOrderItems(c.Request().Context(), b.MediaId, b.Order, b.Fallback, b.OnlineStream)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	if b.Replace {
+		if err := h.App.PlaybackQueue.SetItems(c.Request().Context(), items); err != nil {
+			return h.RespondWithError(c, err)
+		}
+	}
+
+	return h.RespondWithData(c, items)
+}
+
+// HandleGetPlaybackQueuePlaylists
+//
+//	@summary returns the saved playback queue playlists.
+//	@route /api/v1/playback-queue/playlists [GET]
+//	@returns []playbackqueue.Playlist
+func (h *Handler) HandleGetPlaybackQueuePlaylists(c echo.Context) error {
+	ret, err := h.App.PlaybackQueue.GetPlaylists()
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+	return h.RespondWithData(c, ret)
+}
+
+// HandleSavePlaybackQueuePlaylist
+//
+//	@summary saves the items of the playback queue as a playlist.
+//	@desc The playlist is replaced if a database ID is given.
+//	@route /api/v1/playback-queue/playlists [POST]
+//	@returns playbackqueue.Playlist
+func (h *Handler) HandleSavePlaybackQueuePlaylist(c echo.Context) error {
+	type body struct {
+		DbId uint   `json:"dbId"`
+		Name string `json:"name"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	ret, err := h.App.PlaybackQueue.SavePlaylist(b.DbId, b.Name)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+	return h.RespondWithData(c, ret)
+}
+
+// HandleLoadPlaybackQueuePlaylist
+//
+//	@summary replaces the items of the playback queue with a saved playlist.
+//	@route /api/v1/playback-queue/playlists/{id}/load [POST]
+//	@param id - int - true - "Playlist database ID"
+//	@returns playbackqueue.State
+func (h *Handler) HandleLoadPlaybackQueuePlaylist(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	if err := h.App.PlaybackQueue.LoadPlaylist(c.Request().Context(), uint(id)); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, h.App.PlaybackQueue.GetState())
+}
+
+// HandleDeletePlaybackQueuePlaylist
+//
+//	@summary deletes a saved playback queue playlist.
+//	@route /api/v1/playback-queue/playlists/{id} [DELETE]
+//	@param id - int - true - "Playlist database ID"
+//	@returns bool
+func (h *Handler) HandleDeletePlaybackQueuePlaylist(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	if err := h.App.PlaybackQueue.DeletePlaylist(uint(id)); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, true)
+}
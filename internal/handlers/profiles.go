@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"seanime/internal/events"
+	"seanime/internal/profile"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HandleGetProfiles
+//
+//	@summary returns the profiles of the server.
+//	@desc The profile selected by the client is marked as active.
+//	@desc The main profile is created from the current account if there are no profiles yet.
+//	@route /api/v1/profiles [GET]
+//	@returns []profile.Profile
+func (h *Handler) HandleGetProfiles(c echo.Context) error {
+	clientId, _ := c.Get("Seanime-Client-Id").(string)
+
+	profiles, err := h.App.ProfileManager.GetProfiles(clientId)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, profiles)
+}
+
+// HandleCreateProfile
+//
+//	@summary creates a profile.
+//	@desc The profile is not logged in to AniList, it uses its own simulated collections until an account is connected.
+//	@desc The PIN is optional and must be made of 4 to 8 digits.
+//	@route /api/v1/profiles [POST]
+//	@returns profile.Profile
+func (h *Handler) HandleCreateProfile(c echo.Context) error {
+	type body struct {
+		Name string `json:"name"`
+		Pin  string `json:"pin"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	ret, err := h.App.ProfileManager.CreateProfile(b.Name, b.Pin)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, ret)
+}
+
+// HandleUpdateProfile
+//
+//	@summary updates the name or the PIN of a profile.
+//	@desc The current PIN is required if the profile is protected.
+//	@desc An empty PIN removes the protection, a null PIN keeps the current PIN.
+//	@desc The main profile cannot be protected since it is used by clients that have not selected a profile.
+//	@route /api/v1/profiles [PATCH]
+//	@returns profile.Profile
+func (h *Handler) HandleUpdateProfile(c echo.Context) error {
+	type body struct {
+		ID uint `json:"id"`
+		profile.UpdateProfileOptions
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	ret, err := h.App.ProfileManager.UpdateProfile(b.ID, &b.UpdateProfileOptions)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, ret)
+}
+
+// HandleDeleteProfile
+//
+//	@summary deletes a profile with its watch history and simulated collections.
+//	@desc The main profile cannot be deleted, the clients using the deleted profile go back to the main profile.
+//	@route /api/v1/profiles [DELETE]
+//	@returns bool
+func (h *Handler) HandleDeleteProfile(c echo.Context) error {
+	type body struct {
+		ID  uint   `json:"id"`
+		Pin string `json:"pin"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	if err := h.App.DeleteProfile(b.ID, b.Pin); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	// Clients that were using the profile refetch their data
+	h.App.WSEventManager.SendEvent(events.ProfileChanged, nil)
+
+	return h.RespondWithData(c, true)
+}
+
+// HandleSelectProfile
+//
+//	@summary selects the profile of the client.
+//	@desc The PIN is required if the profile is protected.
+//	@desc Only the client that sent the request uses the profile: its account, collections, watch history, theme and playback settings.
+//	@desc The library, torrent clients and caches are shared by all profiles.
+//	@route /api/v1/profiles/select [POST]
+//	@returns handlers.Status
+func (h *Handler) HandleSelectProfile(c echo.Context) error {
+	type body struct {
+		ID  uint   `json:"id"`
+		Pin string `json:"pin"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	if *h.App.IsOffline() {
+		return h.RespondWithError(c, errors.New("profiles cannot be selected in offline mode"))
+	}
+
+	clientId, _ := c.Get("Seanime-Client-Id").(string)
+
+	p, err := h.App.ProfileManager.SelectProfile(clientId, b.ID, b.Pin)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	// Other tabs of the client refetch their data
+	h.App.WSEventManager.SendEventTo(clientId, events.ProfileChanged, p.ID)
+
+	status := h.NewStatus(c)
+
+	return h.RespondWithData(c, status)
+}
@@ -37,6 +37,7 @@ func InitRoutes(app *core.App, e *echo.Echo) {
 		"/events",
 		"/api/v1/image-proxy",
 		"/api/v1/mediastream/transcode/",
+		"/api/v1/mediastream/trickplay/",
 		"/api/v1/torrent-client/list",
 		"/api/v1/proxy",
 	}
@@ -108,6 +109,16 @@ func InitRoutes(app *core.App, e *echo.Echo) {
 
 	e.GET("/events", h.webSocketEventHandler)
 
+	// DLNA media server
+	dlnaGroup := e.Group("/dlna", dlnaLocalNetworkMiddleware)
+	dlnaGroup.GET("/device.xml", h.HandleDlnaDeviceDescription)
+	dlnaGroup.GET("/:service/scpd.xml", h.HandleDlnaServiceDescription)
+	dlnaGroup.POST("/:service/control", h.HandleDlnaControl)
+	dlnaGroup.Add("SUBSCRIBE", "/:service/event", h.HandleDlnaEventSubscription)
+	dlnaGroup.Add("UNSUBSCRIBE", "/:service/event", h.HandleDlnaEventSubscription)
+	dlnaGroup.GET("/media/:id", h.HandleDlnaMedia)
+	dlnaGroup.HEAD("/media/:id", h.HandleDlnaMedia)
+
 	v1 := e.Group("/api").Group("/v1") // Commented out for now, will be used later
 
 	//
@@ -133,6 +144,13 @@ func InitRoutes(app *core.App, e *echo.Echo) {
 	v1.POST("/auth/login", h.HandleLogin)
 	v1.POST("/auth/logout", h.HandleLogout)
 
+	// Profiles
+	v1.GET("/profiles", h.HandleGetProfiles)
+	v1.POST("/profiles", h.HandleCreateProfile)
+	v1.PATCH("/profiles", h.HandleUpdateProfile)
+	v1.DELETE("/profiles", h.HandleDeleteProfile)
+	v1.POST("/profiles/select", h.HandleSelectProfile)
+
 	// Settings
 	v1.GET("/settings", h.HandleGetSettings)
 	v1.PATCH("/settings", h.HandleSaveSettings)
@@ -159,6 +177,7 @@ func InitRoutes(app *core.App, e *echo.Echo) {
 	v1.POST("/open-in-explorer", h.HandleOpenInExplorer)
 
 	v1.POST("/media-player/start", h.HandleStartDefaultMediaPlayer)
+	v1.GET("/media-player/upnp/renderers", h.HandleDiscoverUpnpRenderers)
 
 	//
 	// AniList
@@ -196,6 +215,40 @@ func InitRoutes(app *core.App, e *echo.Echo) {
 
 	v1.POST("/mal/logout", h.HandleMALLogout)
 
+	v1.POST("/mal/account-platform", h.HandleSetMALAccountPlatform)
+
+	//
+	// List Sync
+	//
+
+	v1.PATCH("/settings/list-sync", h.HandleSaveListSyncSettings)
+	v1.POST("/list-sync/preview", h.HandleListSyncPreview)
+	v1.POST("/list-sync/apply", h.HandleListSyncApply)
+
+	//
+	// List Export
+	//
+
+	v1.POST("/lists/export", h.HandleExportLists)
+	v1.POST("/lists/import", h.HandleImportLists)
+
+	//
+	// Scrobbler
+	//
+
+	v1.GET("/scrobbler/accounts", h.HandleGetScrobblerAccounts)
+	v1.POST("/scrobbler/accounts", h.HandleConnectScrobblerAccount)
+	v1.DELETE("/scrobbler/accounts/:tracker", h.HandleDisconnectScrobblerAccount)
+	v1.GET("/scrobbler/logs/:tracker", h.HandleGetScrobblerLogs)
+
+	//
+	// Outbox
+	//
+
+	v1.GET("/outbox", h.HandleGetOutboxItems)
+	v1.POST("/outbox/:id/retry", h.HandleRetryOutboxItem)
+	v1.DELETE("/outbox/:id", h.HandleDiscardOutboxItem)
+
 	//
 	// Library
 	//
@@ -393,6 +446,9 @@ func InitRoutes(app *core.App, e *echo.Echo) {
 	v1.GET("/mediastream/direct", h.HandleMediastreamDirectPlay)
 	v1.HEAD("/mediastream/direct", h.HandleMediastreamDirectPlay)
 	v1.GET("/mediastream/file", h.HandleMediastreamFile)
+	// Trickplay
+	v1.POST("/mediastream/trickplay", h.HandleGenerateMediastreamTrickplay)
+	v1.GET("/mediastream/trickplay/*", h.HandleMediastreamGetTrickplay)
 
 	//
 	// Direct Stream
@@ -412,6 +468,9 @@ func InitRoutes(app *core.App, e *echo.Echo) {
 	v1.POST("/torrentstream/drop", h.HandleTorrentstreamDropTorrent)
 	v1.POST("/torrentstream/torrent-file-previews", h.HandleGetTorrentstreamTorrentFilePreviews)
 	v1.POST("/torrentstream/batch-history", h.HandleGetTorrentstreamBatchHistory)
+	v1.GET("/torrentstream/cache", h.HandleGetTorrentstreamCacheEntries)
+	v1.DELETE("/torrentstream/cache", h.HandleDeleteTorrentstreamCacheEntry)
+	v1.POST("/torrentstream/cache/import", h.HandleImportTorrentstreamCacheEntry)
 	v1.GET("/torrentstream/stream/*", h.HandleTorrentstreamServeStream)
 
 	//
@@ -434,6 +493,7 @@ func InitRoutes(app *core.App, e *echo.Echo) {
 	v1Extensions.GET("/list/manga-provider", h.HandleListMangaProviderExtensions)
 	v1Extensions.GET("/list/onlinestream-provider", h.HandleListOnlinestreamProviderExtensions)
 	v1Extensions.GET("/list/anime-torrent-provider", h.HandleListAnimeTorrentProviderExtensions)
+	v1Extensions.GET("/list/mediaplayer", h.HandleListMediaPlayerExtensions)
 	v1Extensions.GET("/user-config/:id", h.HandleGetExtensionUserConfig)
 	v1Extensions.POST("/user-config", h.HandleSaveExtensionUserConfig)
 	v1Extensions.GET("/marketplace", h.HandleGetMarketplaceExtensions)
@@ -448,6 +508,47 @@ func InitRoutes(app *core.App, e *echo.Echo) {
 	v1Continuity.PATCH("/item", h.HandleUpdateContinuityWatchHistoryItem)
 	v1Continuity.GET("/item/:id", h.HandleGetContinuityWatchHistoryItem)
 	v1Continuity.GET("/history", h.HandleGetContinuityWatchHistory)
+	v1Continuity.POST("/events", h.HandleGetContinuityWatchEvents)
+	v1Continuity.DELETE("/events/:id", h.HandleDeleteContinuityWatchEvent)
+	v1Continuity.POST("/stats", h.HandleGetContinuityWatchStats)
+	v1Continuity.GET("/export", h.HandleExportContinuityWatchHistory)
+	v1Continuity.POST("/import", h.HandleImportContinuityWatchHistory)
+
+	//
+	// Skip Segments
+	//
+	v1SkipSegments := v1.Group("/skip-segments")
+	v1SkipSegments.GET("/:id/:episodeNumber", h.HandleGetSkipSegments)
+	v1SkipSegments.POST("", h.HandleSaveSkipSegment)
+	v1SkipSegments.DELETE("", h.HandleDeleteSkipSegment)
+	v1SkipSegments.POST("/detect", h.HandleDetectSkipSegments)
+
+	//
+	// Track Preferences
+	//
+	v1TrackPreferences := v1.Group("/track-preferences")
+	v1TrackPreferences.GET("", h.HandleGetTrackPreferences)
+	v1TrackPreferences.POST("", h.HandleSaveTrackPreference)
+	v1TrackPreferences.DELETE("", h.HandleDeleteTrackPreference)
+
+	//
+	// Playback Queue
+	//
+	v1PlaybackQueue := v1.Group("/playback-queue")
+	v1PlaybackQueue.GET("", h.HandleGetPlaybackQueue)
+	v1PlaybackQueue.DELETE("", h.HandlePlaybackQueueClear)
+	v1PlaybackQueue.POST("/items", h.HandlePlaybackQueueAddItems)
+	v1PlaybackQueue.DELETE("/items", h.HandlePlaybackQueueRemoveItem)
+	v1PlaybackQueue.POST("/items/move", h.HandlePlaybackQueueMoveItem)
+	v1PlaybackQueue.POST("/play", h.HandlePlaybackQueuePlay)
+	v1PlaybackQueue.POST("/next", h.HandlePlaybackQueueNext)
+	v1PlaybackQueue.POST("/previous", h.HandlePlaybackQueuePrevious)
+	v1PlaybackQueue.POST("/stop", h.HandlePlaybackQueueStop)
+	v1PlaybackQueue.POST("/watch-order", h.HandlePlaybackQueueGetWatchOrder)
+	v1PlaybackQueue.GET("/playlists", h.HandleGetPlaybackQueuePlaylists)
+	v1PlaybackQueue.POST("/playlists", h.HandleSavePlaybackQueuePlaylist)
+	v1PlaybackQueue.POST("/playlists/:id/load", h.HandleLoadPlaybackQueuePlaylist)
+	v1PlaybackQueue.DELETE("/playlists/:id", h.HandleDeletePlaybackQueuePlaylist)
 
 	//
 	// Sync
@@ -456,6 +557,7 @@ func InitRoutes(app *core.App, e *echo.Echo) {
 	v1Local.GET("/track", h.HandleLocalGetTrackedMediaItems)
 	v1Local.POST("/track", h.HandleLocalAddTrackedMedia)
 	v1Local.DELETE("/track", h.HandleLocalRemoveTrackedMedia)
+	v1Local.PATCH("/track/priority", h.HandleLocalSetTrackedMediaPriority)
 	v1Local.GET("/track/:id/:type", h.HandleLocalGetIsMediaTracked)
 	v1Local.POST("/local", h.HandleLocalSyncData)
 	v1Local.GET("/queue", h.HandleLocalGetSyncQueueState)
@@ -464,6 +566,14 @@ func InitRoutes(app *core.App, e *echo.Echo) {
 	v1Local.GET("/updated", h.HandleLocalGetHasLocalChanges)
 	v1Local.GET("/storage/size", h.HandleLocalGetLocalStorageSize)
 	v1Local.POST("/sync-simulated-to-anilist", h.HandleLocalSyncSimulatedDataToAnilist)
+	v1Local.GET("/sync/settings", h.HandleLocalGetSyncSettings)
+	v1Local.PATCH("/sync/settings", h.HandleLocalSaveSyncSettings)
+	v1Local.POST("/sync/estimate", h.HandleLocalEstimateSync)
+	v1Local.GET("/offline-downloads", h.HandleLocalGetOfflineEpisodes)
+	v1Local.GET("/offline-downloads/settings", h.HandleLocalGetOfflineDownloadSettings)
+	v1Local.PATCH("/offline-downloads/settings", h.HandleLocalSaveOfflineDownloadSettings)
+	v1Local.POST("/offline-downloads/run", h.HandleLocalRunOfflineDownloader)
+	v1Local.DELETE("/offline-downloads/:id", h.HandleLocalDeleteOfflineEpisode)
 
 	v1Local.POST("/offline", h.HandleSetOfflineMode)
 
@@ -555,3 +665,9 @@ func headMethodMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 		return next(c)
 	}
 }
+
+// getUserScope returns the scope of the user of the client that sent the request.
+func (h *Handler) getUserScope(c echo.Context) *core.UserScope {
+	clientId, _ := c.Get("Seanime-Client-Id").(string)
+	return h.App.GetUserScope(clientId)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"seanime/internal/scrobbler"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HandleGetScrobblerAccounts
+//
+//	@summary returns the connected tracker accounts.
+//	@desc List updates made on AniList are mirrored to these accounts.
+//	@route /api/v1/scrobbler/accounts [GET]
+//	@returns []scrobbler.Account
+func (h *Handler) HandleGetScrobblerAccounts(c echo.Context) error {
+	accounts, err := h.App.ScrobblerManager.GetAccounts()
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, accounts)
+}
+
+// HandleConnectScrobblerAccount
+//
+//	@summary connects a Kitsu, Shikimori or Simkl account.
+//	@desc Kitsu uses the username and password.
+//	@desc Shikimori and Simkl use the OAuth code and the credentials of the OAuth application that issued it.
+//	@desc The previous account of the tracker is replaced.
+//	@route /api/v1/scrobbler/accounts [POST]
+//	@returns scrobbler.Account
+func (h *Handler) HandleConnectScrobblerAccount(c echo.Context) error {
+
+	type body struct {
+		Tracker scrobbler.TrackerName `json:"tracker"`
+		scrobbler.Credentials
+	}
+
+	b := new(body)
+	if err := c.Bind(b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	account, err := h.App.ScrobblerManager.Connect(c.Request().Context(), b.Tracker, &b.Credentials)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, account)
+}
+
+// HandleDisconnectScrobblerAccount
+//
+//	@summary removes a tracker account.
+//	@route /api/v1/scrobbler/accounts/{tracker} [DELETE]
+//	@param tracker - string - true - "kitsu, shikimori or simkl"
+//	@returns bool
+func (h *Handler) HandleDisconnectScrobblerAccount(c echo.Context) error {
+	err := h.App.ScrobblerManager.Disconnect(scrobbler.TrackerName(c.Param("tracker")))
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, true)
+}
+
+// HandleGetScrobblerLogs
+//
+//	@summary returns the latest update attempts of a tracker.
+//	@desc Most recent first.
+//	@route /api/v1/scrobbler/logs/{tracker} [GET]
+//	@param tracker - string - true - "kitsu, shikimori or simkl"
+//	@returns []models.ScrobbleLog
+func (h *Handler) HandleGetScrobblerLogs(c echo.Context) error {
+	logs, err := h.App.ScrobblerManager.GetLogs(scrobbler.TrackerName(c.Param("tracker")))
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, logs)
+}
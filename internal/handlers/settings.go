@@ -29,6 +29,8 @@ func (h *Handler) HandleGetSettings(c echo.Context) error {
 		return h.RespondWithError(c, errors.New(runtime.GOOS))
 	}
 
+	h.getUserScope(c).ApplyPlaybackPreferences(settings)
+
 	return h.RespondWithData(c, settings)
 }
 
@@ -205,6 +207,26 @@ func (h *Handler) HandleSaveSettings(c echo.Context) error {
 	if err == nil && prevSettings.AutoDownloader != nil {
 		autoDownloaderSettings = *prevSettings.AutoDownloader
 	}
+	// List sync settings are saved separately
+	listSyncSettings := models.ListSyncSettings{}
+	if err == nil && prevSettings.ListSync != nil {
+		listSyncSettings = *prevSettings.ListSync
+	}
+
+	// The playback preferences of a profile other than the main profile are saved in the profile,
+	// the main profile keeps its own
+	scope := h.getUserScope(c)
+	if !scope.IsMainProfile() {
+		if saveErr := scope.SavePlaybackPreferences(&models.Settings{Library: &b.Library, MediaPlayer: &b.MediaPlayer}); saveErr != nil {
+			return h.RespondWithError(c, saveErr)
+		}
+		if err == nil && prevSettings.Library != nil && prevSettings.MediaPlayer != nil {
+			b.MediaPlayer.Default = prevSettings.MediaPlayer.Default
+			b.Library.AutoPlayNextEpisode = prevSettings.Library.AutoPlayNextEpisode
+			b.Library.EnableWatchContinuity = prevSettings.Library.EnableWatchContinuity
+		}
+	}
+
 	// Disable auto-downloader if the torrent provider is set to none
 	if b.Library.TorrentProvider == torrent.ProviderNone && autoDownloaderSettings.Enabled {
 		h.App.Logger.Debug().Msg("app: Disabling auto-downloader because the torrent provider is set to none")
@@ -224,6 +246,7 @@ func (h *Handler) HandleSaveSettings(c echo.Context) error {
 		Discord:        &b.Discord,
 		Notifications:  &b.Notifications,
 		Nakama:         &b.Nakama,
+		ListSync:       &listSyncSettings,
 		AutoDownloader: &autoDownloaderSettings,
 	})
 
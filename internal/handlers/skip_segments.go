@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"seanime/internal/database/db_bridge"
+	"seanime/internal/events"
+	"seanime/internal/library/anime"
+	"seanime/internal/skipsegments"
+	"slices"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HandleGetSkipSegments
+//
+//	@summary returns the skip segments of an episode.
+//	@desc Segments derived from the chapters of the file are not included.
+//	@route /api/v1/skip-segments/{id}/{episodeNumber} [GET]
+//	@param id - int - true - "AniList anime media ID"
+//	@param episodeNumber - int - true - "Episode number"
+//	@returns []skipsegments.Segment
+func (h *Handler) HandleGetSkipSegments(c echo.Context) error {
+	mId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+	episodeNumber, err := strconv.Atoi(c.Param("episodeNumber"))
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	ret := h.App.SkipSegmentsManager.GetSegments(&skipsegments.GetSegmentsOptions{
+		MediaId:       mId,
+		EpisodeNumber: episodeNumber,
+	})
+
+	return h.RespondWithData(c, ret)
+}
+
+// HandleSaveSkipSegment
+//
+//	@summary saves a skip segment submitted by the user.
+//	@desc It replaces the segment of the same type previously submitted by the user for the episode.
+//	@route /api/v1/skip-segments [POST]
+//	@returns skipsegments.Segment
+func (h *Handler) HandleSaveSkipSegment(c echo.Context) error {
+	type body struct {
+		MediaId       int                      `json:"mediaId"`
+		EpisodeNumber int                      `json:"episodeNumber"`
+		Type          skipsegments.SegmentType `json:"type"`
+		Start         float64                  `json:"start"`
+		End           float64                  `json:"end"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	ret, err := h.App.SkipSegmentsManager.SaveUserSegment(b.MediaId, b.EpisodeNumber, b.Type, b.Start, b.End)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, ret)
+}
+
+// HandleDeleteSkipSegment
+//
+//	@summary deletes a stored skip segment.
+//	@route /api/v1/skip-segments [DELETE]
+//	@returns bool
+func (h *Handler) HandleDeleteSkipSegment(c echo.Context) error {
+	type body struct {
+		ID uint `json:"id"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	if err := h.App.SkipSegmentsManager.DeleteSegment(b.ID); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, true)
+}
+
+// HandleDetectSkipSegments
+//
+//	@summary detects the openings of the downloaded episodes of a media.
+//	@desc The audio of the episodes is compared in the background, a toast is sent when the detection is done.
+//	@route /api/v1/skip-segments/detect [POST]
+//	@returns bool
+func (h *Handler) HandleDetectSkipSegments(c echo.Context) error {
+	type body struct {
+		MediaId int `json:"mediaId"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	lfs, _, err := db_bridge.GetLocalFiles(h.App.Database)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	episodes := make([]*skipsegments.EpisodeFile, 0)
+	for _, lf := range lfs {
+		if lf.MediaId != b.MediaId || lf.GetType() != anime.LocalFileTypeMain {
+			continue
+		}
+		episodes = append(episodes, &skipsegments.EpisodeFile{
+			EpisodeNumber: lf.GetEpisodeNumber(),
+			Path:          lf.GetPath(),
+		})
+	}
+	slices.SortFunc(episodes, func(a, b *skipsegments.EpisodeFile) int {
+		return cmp.Compare(a.EpisodeNumber, b.EpisodeNumber)
+	})
+
+	if len(episodes) < 2 {
+		return h.RespondWithError(c, errors.New("at least 2 downloaded episodes are needed to detect openings"))
+	}
+
+	ffmpegPath := ""
+	if settings, found := h.App.Database.GetMediastreamSettings(); found {
+		ffmpegPath = settings.FfmpegPath
+	}
+
+	go func() {
+		ret, err := h.App.SkipSegmentsManager.DetectOpenings(context.Background(), &skipsegments.DetectOpeningsOptions{
+			MediaId:    b.MediaId,
+			FfmpegPath: ffmpegPath,
+			Episodes:   episodes,
+		})
+		if err != nil {
+			h.App.WSEventManager.SendEvent(events.ErrorToast, err.Error())
+			return
+		}
+		h.App.WSEventManager.SendEvent(events.SuccessToast, fmt.Sprintf("Detected the opening of %d/%d episodes", len(ret), len(episodes)))
+	}()
+
+	return h.RespondWithData(c, true)
+}
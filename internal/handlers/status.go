@@ -42,6 +42,7 @@ type Status struct {
 	FeatureFlags          core.FeatureFlags             `json:"featureFlags"`
 	ServerReady           bool                          `json:"serverReady"`
 	ServerHasPassword     bool                          `json:"serverHasPassword"`
+	IsMalAccountPlatform  bool                          `json:"isMalAccountPlatform"` // The MyAnimeList lists are used instead of the AniList collections
 }
 
 var clientInfoCache = result.NewResultMap[string, util.ClientInfo]()
@@ -55,8 +56,10 @@ func (h *Handler) NewStatus(c echo.Context) *Status {
 	var theme *models.Theme
 	//var mal *models.Mal
 
+	scope := h.getUserScope(c)
+
 	// Get the user from the database (if logged in)
-	if dbAcc, _ = h.App.Database.GetAccount(); dbAcc != nil {
+	if dbAcc, _ = scope.GetAccount(); dbAcc != nil {
 		currentUser, _ = user.NewUser(dbAcc)
 		if currentUser != nil {
 			currentUser.Token = "HIDDEN"
@@ -71,6 +74,7 @@ func (h *Handler) NewStatus(c echo.Context) *Status {
 			settings = nil
 		}
 	}
+	scope.ApplyPlaybackPreferences(settings)
 
 	clientInfo, found := clientInfoCache.Get(c.Request().UserAgent())
 	if !found {
@@ -78,7 +82,7 @@ func (h *Handler) NewStatus(c echo.Context) *Status {
 		clientInfoCache.Set(c.Request().UserAgent(), clientInfo)
 	}
 
-	theme, _ = h.App.Database.GetTheme()
+	theme, _ = scope.GetTheme()
 
 	status := &Status{
 		OS:                    runtime.GOOS,
@@ -101,6 +105,7 @@ func (h *Handler) NewStatus(c echo.Context) *Status {
 		FeatureFlags:          h.App.FeatureFlags,
 		ServerReady:           h.App.ServerReady,
 		ServerHasPassword:     h.App.Config.Server.Password != "",
+		IsMalAccountPlatform:  scope.IsMainProfile() && h.App.IsMalAccountPlatform(),
 	}
 
 	if c.Get("unauthenticated") != nil && c.Get("unauthenticated").(bool) {
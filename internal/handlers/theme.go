@@ -12,7 +12,7 @@ import (
 //	@route /api/v1/theme [GET]
 //	@returns models.Theme
 func (h *Handler) HandleGetTheme(c echo.Context) error {
-	theme, err := h.App.Database.GetTheme()
+	theme, err := h.getUserScope(c).GetTheme()
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
@@ -42,7 +42,7 @@ func (h *Handler) HandleUpdateTheme(c echo.Context) error {
 	}
 
 	// Update the theme settings
-	if _, err := h.App.Database.UpsertTheme(&b.Theme); err != nil {
+	if _, err := h.getUserScope(c).UpsertTheme(&b.Theme); err != nil {
 		return h.RespondWithError(c, err)
 	}
 
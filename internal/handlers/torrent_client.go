@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"path/filepath"
 	"seanime/internal/api/anilist"
@@ -136,7 +137,7 @@ func (h *Handler) HandleTorrentClientDownload(c echo.Context) error {
 		return h.RespondWithError(c, errors.New("could not contact torrent client, verify your settings or make sure it's running"))
 	}
 
-	completeAnime, err := h.App.AnilistPlatform.GetAnimeWithRelations(c.Request().Context(), b.Media.ID)
+	completeAnime, err := h.getUserScope(c).GetPlatform().GetAnimeWithRelations(c.Request().Context(), b.Media.ID)
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
@@ -152,7 +153,7 @@ func (h *Handler) HandleTorrentClientDownload(c echo.Context) error {
 			EpisodeNumbers:   b.SmartSelect.MissingEpisodeNumbers,
 			Media:            completeAnime,
 			Destination:      b.Destination,
-			Platform:         h.App.AnilistPlatform,
+			Platform:         h.getUserScope(c).GetPlatform(),
 			ShouldAddTorrent: true,
 		})
 		if err != nil {
@@ -185,11 +186,12 @@ func (h *Handler) HandleTorrentClientDownload(c echo.Context) error {
 	}
 
 	// Add the media to the collection (if it wasn't already)
+	scope := h.getUserScope(c)
 	go func() {
 		defer util.HandlePanicInModuleThen("handlers/HandleTorrentClientDownload", func() {})
 		if b.Media != nil {
 			// Check if the media is already in the collection
-			animeCollection, err := h.App.GetAnimeCollection(false)
+			animeCollection, err := scope.GetAnimeCollection(false)
 			if err != nil {
 				return
 			}
@@ -198,11 +200,11 @@ func (h *Handler) HandleTorrentClientDownload(c echo.Context) error {
 				return
 			}
 			// Add the media to the collection
-			err = h.App.AnilistPlatform.AddMediaToCollection(c.Request().Context(), []int{b.Media.ID})
+			err = scope.GetPlatform().AddMediaToCollection(context.Background(), []int{b.Media.ID})
 			if err != nil {
 				h.App.Logger.Error().Err(err).Msg("anilist: Failed to add media to collection")
 			}
-			ac, _ := h.App.RefreshAnimeCollection()
+			ac, _ := scope.RefreshAnimeCollection()
 			h.App.WSEventManager.SendEvent(events.RefreshedAnilistAnimeCollection, ac)
 		}
 	}()
@@ -9,6 +9,8 @@ import (
 	"seanime/internal/events"
 	hibiketorrent "seanime/internal/extension/hibike/torrent"
 	"seanime/internal/torrentstream"
+	"seanime/internal/util"
+	"slices"
 
 	"github.com/labstack/echo/v4"
 )
@@ -168,11 +170,21 @@ func (h *Handler) HandleTorrentstreamStartStream(c echo.Context) error {
 //	@summary stop a torrent stream.
 //	@desc This stops the entire streaming process and drops the torrent if it's below a threshold.
 //	@desc This is made to be used while the stream is running.
+//	@desc Only the stream of the client is stopped, the streams of all clients are stopped if no client ID is given.
 //	@returns bool
 //	@route /api/v1/torrentstream/stop [POST]
 func (h *Handler) HandleTorrentstreamStopStream(c echo.Context) error {
 
-	err := h.App.TorrentstreamRepository.StopStream()
+	type body struct {
+		ClientId string `json:"clientId"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	err := h.App.TorrentstreamRepository.StopStream(b.ClientId)
 	if err != nil {
 		return h.RespondWithError(c, err)
 	}
@@ -216,6 +228,94 @@ func (h *Handler) HandleGetTorrentstreamBatchHistory(c echo.Context) error {
 	return h.RespondWithData(c, ret)
 }
 
+// HandleGetTorrentstreamCacheEntries
+//
+//	@summary returns the episodes kept by the streaming cache.
+//	@desc The most recently watched episodes are returned first.
+//	@returns []torrentstream.CacheEntry
+//	@route /api/v1/torrentstream/cache [GET]
+func (h *Handler) HandleGetTorrentstreamCacheEntries(c echo.Context) error {
+	ret, err := h.App.TorrentstreamRepository.GetCacheEntries()
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, ret)
+}
+
+// HandleDeleteTorrentstreamCacheEntry
+//
+//	@summary removes a torrent from the streaming cache.
+//	@desc This deletes the downloaded data of the torrent the entry belongs to.
+//	@desc If no id is provided, all the torrents that are not being streamed are removed.
+//	@returns bool
+//	@route /api/v1/torrentstream/cache [DELETE]
+func (h *Handler) HandleDeleteTorrentstreamCacheEntry(c echo.Context) error {
+	type body struct {
+		ID uint `json:"id"`
+	}
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	var err error
+	if b.ID == 0 {
+		err = h.App.TorrentstreamRepository.ClearCache()
+	} else {
+		err = h.App.TorrentstreamRepository.DeleteCacheEntry(b.ID)
+	}
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, true)
+}
+
+// HandleImportTorrentstreamCacheEntry
+//
+//	@summary moves a completely downloaded episode of the streaming cache into the library.
+//	@desc The file is moved to a folder named after the media in the destination, which defaults to the library path and must be in the library.
+//	@desc Only the moved file is scanned before it is added to the local files.
+//	@returns anime.LocalFile
+//	@route /api/v1/torrentstream/cache/import [POST]
+func (h *Handler) HandleImportTorrentstreamCacheEntry(c echo.Context) error {
+	type body struct {
+		ID          uint   `json:"id"`
+		Destination string `json:"destination"`
+	}
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	libraryPaths, err := h.App.Database.GetAllLibraryPathsFromSettings()
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	if b.Destination == "" && len(libraryPaths) > 0 {
+		b.Destination = libraryPaths[0]
+	}
+
+	// The file can only be moved into the library
+	if b.Destination == "" || !slices.ContainsFunc(libraryPaths, func(libraryPath string) bool {
+		return libraryPath != "" && (util.IsSameDir(libraryPath, b.Destination) || util.IsFileUnderDir(b.Destination, libraryPath))
+	}) {
+		return h.RespondWithError(c, errors.New("the destination must be in the library"))
+	}
+
+	lf, err := h.App.TorrentstreamRepository.ImportToLibrary(c.Request().Context(), &torrentstream.ImportToLibraryOptions{
+		CacheEntryId: b.ID,
+		LibraryPath:  b.Destination,
+	})
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, lf)
+}
+
 // route /api/v1/torrentstream/stream/*
 func (h *Handler) HandleTorrentstreamServeStream(c echo.Context) error {
 	h.App.TorrentstreamRepository.HTTPStreamHandler().ServeHTTP(c.Response().Writer, c.Request())
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"errors"
+	"seanime/internal/trackpreference"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HandleGetTrackPreferences
+//
+//	@summary returns the track preferences.
+//	@desc The default preference has a media ID of 0.
+//	@desc Preferences with an empty username apply to every user.
+//	@route /api/v1/track-preferences [GET]
+//	@returns []trackpreference.Preference
+func (h *Handler) HandleGetTrackPreferences(c echo.Context) error {
+	ret, err := h.App.TrackPreferenceManager.GetPreferences()
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, ret)
+}
+
+// HandleSaveTrackPreference
+//
+//	@summary saves the track preference of a media.
+//	@desc Set the media ID to 0 to save the default preference, which applies to media without a preference.
+//	@desc Set the username to the one of the logged-in user to save a preference that only applies to them, or leave it empty for every user.
+//	@route /api/v1/track-preferences [POST]
+//	@returns trackpreference.Preference
+func (h *Handler) HandleSaveTrackPreference(c echo.Context) error {
+	type body struct {
+		Preference trackpreference.Preference `json:"preference"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	if b.Preference.Username != "" && b.Preference.Username != h.getUserScope(c).GetUser().Viewer.Name {
+		return h.RespondWithError(c, errors.New("preferences can only be saved for the logged-in user"))
+	}
+
+	ret, err := h.App.TrackPreferenceManager.SavePreference(&b.Preference)
+	if err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, ret)
+}
+
+// HandleDeleteTrackPreference
+//
+//	@summary deletes the track preference of a media.
+//	@desc The default preference is used for the media afterwards.
+//	@route /api/v1/track-preferences [DELETE]
+//	@returns bool
+func (h *Handler) HandleDeleteTrackPreference(c echo.Context) error {
+	type body struct {
+		Username string `json:"username"`
+		MediaId  int    `json:"mediaId"`
+	}
+
+	var b body
+	if err := c.Bind(&b); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	if err := h.App.TrackPreferenceManager.DeletePreference(b.Username, b.MediaId); err != nil {
+		return h.RespondWithError(c, err)
+	}
+
+	return h.RespondWithData(c, true)
+}
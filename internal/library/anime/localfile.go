@@ -25,6 +25,8 @@ type (
 		Locked           bool                   `json:"locked"`
 		Ignored          bool                   `json:"ignored"` // Unused for now
 		MediaId          int                    `json:"mediaId"`
+		// Subtitles are the external subtitle files found next to the file during the scan
+		Subtitles []*filesystem.ExternalSubtitle `json:"subtitles,omitempty"`
 	}
 
 	// LocalFileMetadata holds metadata related to a media episode.
@@ -0,0 +1,237 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"seanime/internal/util"
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+)
+
+// ExternalSubtitle is a subtitle file stored next to a video file.
+type ExternalSubtitle struct {
+	Path string `json:"path"`
+	// Extension of the file without the dot, e.g. "ass", "srt"
+	Extension string `json:"extension"`
+	// Language is the BCP 47 tag detected from the file name, e.g. "en", "pt-BR"
+	Language string `json:"language,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Forced   bool   `json:"forced,omitempty"`
+	// Hearing impaired (SDH/CC)
+	HearingImpaired bool `json:"hearingImpaired,omitempty"`
+}
+
+var subtitleExtensions = []string{".ass", ".ssa", ".srt", ".vtt", ".sup"}
+
+// Folder names used by batch releases to store subtitles
+var subtitleFolderNames = []string{"subs", "subtitles", "sub", "subtitle"}
+
+// Language names commonly used in subtitle file names that are not recognized as BCP 47 tags
+var subtitleLanguageNames = map[string]string{
+	"english":    "en",
+	"japanese":   "ja",
+	"french":     "fr",
+	"german":     "de",
+	"spanish":    "es",
+	"latino":     "es-419",
+	"portuguese": "pt",
+	"brazilian":  "pt-BR",
+	"italian":    "it",
+	"russian":    "ru",
+	"arabic":     "ar",
+	"chinese":    "zh",
+	"korean":     "ko",
+	"polish":     "pl",
+	"indonesian": "id",
+	"thai":       "th",
+	"vietnamese": "vi",
+	"turkish":    "tr",
+	"dutch":      "nl",
+	"chs":        "zh-Hans",
+	"cht":        "zh-Hant",
+	"sc":         "zh-Hans",
+	"tc":         "zh-Hant",
+	"enm":        "en", // e.g. "[Group] Title - 01.enm.ass"
+}
+
+func IsSubtitleFile(path string) bool {
+	return slices.Contains(subtitleExtensions, strings.ToLower(filepath.Ext(path)))
+}
+
+// ExternalSubtitleFinder finds the external subtitle files of video files.
+// Directory listings are cached, so the same finder should be reused when looking up files in the same directories.
+// It is not safe for concurrent use.
+type ExternalSubtitleFinder struct {
+	dirs map[string][]os.DirEntry
+}
+
+func NewExternalSubtitleFinder() *ExternalSubtitleFinder {
+	return &ExternalSubtitleFinder{
+		dirs: make(map[string][]os.DirEntry),
+	}
+}
+
+// FindExternalSubtitles returns the external subtitle files of the video file at the given path.
+func FindExternalSubtitles(videoPath string) []*ExternalSubtitle {
+	return NewExternalSubtitleFinder().Find(videoPath)
+}
+
+// Find returns the external subtitle files of the video file at the given path.
+//
+// The following layouts are supported:
+//   - Files next to the video: "Title - 01.ass", "Title - 01.en.ass", "Title - 01.eng.forced.srt"
+//   - Files in a subtitle folder: "Subs/Title - 01.ass", "Subs/English/Title - 01.ass"
+//   - A subtitle folder named after the video: "Subs/Title - 01/2_English.ass"
+//
+// If the video is the only video file in its directory, all subtitle files in the directory and its subtitle folders are matched.
+func (f *ExternalSubtitleFinder) Find(videoPath string) []*ExternalSubtitle {
+	dir := filepath.Dir(videoPath)
+	videoStem := strings.TrimSuffix(filepath.Base(videoPath), filepath.Ext(videoPath))
+
+	entries := f.readDir(dir)
+
+	videoCount := 0
+	for _, entry := range entries {
+		if !entry.IsDir() && util.IsValidVideoExtension(strings.ToLower(filepath.Ext(entry.Name()))) {
+			videoCount++
+		}
+	}
+	isOnlyVideo := videoCount == 1
+
+	ret := make([]*ExternalSubtitle, 0)
+
+	// Files next to the video
+	ret = append(ret, f.matchFiles(dir, entries, videoStem, isOnlyVideo, "")...)
+
+	// Subtitle folders
+	for _, entry := range entries {
+		if !entry.IsDir() || !slices.Contains(subtitleFolderNames, strings.ToLower(entry.Name())) {
+			continue
+		}
+		subsDir := filepath.Join(dir, entry.Name())
+		subsEntries := f.readDir(subsDir)
+		ret = append(ret, f.matchFiles(subsDir, subsEntries, videoStem, isOnlyVideo, "")...)
+
+		for _, subEntry := range subsEntries {
+			if !subEntry.IsDir() {
+				continue
+			}
+			subDir := filepath.Join(subsDir, subEntry.Name())
+			if strings.EqualFold(subEntry.Name(), videoStem) {
+				// Every file in the folder belongs to the video
+				ret = append(ret, f.matchFiles(subDir, f.readDir(subDir), videoStem, true, "")...)
+			} else {
+				// The folder name is usually the language, e.g. "Subs/English/"
+				ret = append(ret, f.matchFiles(subDir, f.readDir(subDir), videoStem, isOnlyVideo, subEntry.Name())...)
+			}
+		}
+	}
+
+	return ret
+}
+
+func (f *ExternalSubtitleFinder) readDir(dir string) []os.DirEntry {
+	if entries, ok := f.dirs[dir]; ok {
+		return entries
+	}
+	entries, _ := os.ReadDir(dir)
+	f.dirs[dir] = entries
+	return entries
+}
+
+// matchFiles returns the subtitle files in the directory that belong to the video.
+//   - matchAll: Match files whose name does not start with the video's name
+//   - folderTag: Name of the folder, used as a fallback for the language
+func (f *ExternalSubtitleFinder) matchFiles(dir string, entries []os.DirEntry, videoStem string, matchAll bool, folderTag string) []*ExternalSubtitle {
+	ret := make([]*ExternalSubtitle, 0)
+
+	for _, entry := range entries {
+		if entry.IsDir() || !IsSubtitleFile(entry.Name()) {
+			continue
+		}
+
+		ext := filepath.Ext(entry.Name())
+		stem := strings.TrimSuffix(entry.Name(), ext)
+
+		var tags string
+		switch {
+		case strings.EqualFold(stem, videoStem):
+		case len(stem) > len(videoStem) && strings.EqualFold(stem[:len(videoStem)], videoStem) && stem[len(videoStem)] == '.':
+			tags = stem[len(videoStem)+1:]
+		case matchAll:
+			tags = stem
+		default:
+			continue
+		}
+
+		sub := parseSubtitleTags(tags)
+		if sub.Language == "" && folderTag != "" {
+			sub.Language = parseSubtitleLanguage(folderTag)
+		}
+		sub.Path = filepath.Join(dir, entry.Name())
+		sub.Extension = strings.ToLower(strings.TrimPrefix(ext, "."))
+		if sub.Title == "" && sub.Language != "" {
+			sub.Title = display.English.Tags().Name(language.Make(sub.Language))
+		}
+		if sub.Title == "" {
+			sub.Title = stem
+		}
+		ret = append(ret, sub)
+	}
+
+	return ret
+}
+
+// parseSubtitleTags parses the tags following the video name, e.g. "en.forced", "2_English", "English (SDH)".
+func parseSubtitleTags(tags string) *ExternalSubtitle {
+	sub := &ExternalSubtitle{}
+
+	var rest []string
+	for _, tag := range strings.FieldsFunc(tags, func(r rune) bool {
+		return r == '.' || r == '_' || r == ' ' || r == '[' || r == ']' || r == '(' || r == ')'
+	}) {
+		switch lower := strings.ToLower(tag); {
+		case lower == "forced":
+			sub.Forced = true
+		case lower == "sdh" || lower == "cc" || lower == "hi":
+			sub.HearingImpaired = true
+		case strings.IndexFunc(lower, func(r rune) bool { return !unicode.IsDigit(r) }) == -1:
+			// Track number, e.g. "2_English"
+		case sub.Language == "" && parseSubtitleLanguage(lower) != "":
+			sub.Language = parseSubtitleLanguage(lower)
+		default:
+			rest = append(rest, tag)
+		}
+	}
+
+	sub.Title = strings.Join(rest, " ")
+	return sub
+}
+
+// parseSubtitleLanguage returns the BCP 47 tag of a language code or name, or an empty string.
+func parseSubtitleLanguage(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if lang, ok := subtitleLanguageNames[s]; ok {
+		return lang
+	}
+	// Only accept ISO 639 codes ("en", "eng", "pt-br") to avoid matching arbitrary words
+	if len(s) < 2 || (len(s) > 3 && !strings.Contains(s, "-")) {
+		return ""
+	}
+	tag, err := language.Parse(s)
+	if err != nil {
+		return ""
+	}
+	base, confidence := tag.Base()
+	if confidence != language.Exact {
+		return ""
+	}
+	if region, confidence := tag.Region(); confidence == language.Exact {
+		return base.String() + "-" + region.String()
+	}
+	return base.String()
+}
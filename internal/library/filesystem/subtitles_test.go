@@ -0,0 +1,96 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindExternalSubtitles(t *testing.T) {
+	dir := t.TempDir()
+
+	files := []string{
+		"Show - 01.mkv",
+		"Show - 02.mkv",
+		"Show - 01.ass",
+		"Show - 01.en.forced.srt",
+		"Show - 01.Portuguese (SDH).srt",
+		"Show - 02.ja.ass",
+		"Show - 010.ass", // Different episode, should not match "Show - 01"
+		"Subs/Show - 01/2_English.ass",
+		"Subs/Show - 02/2_English.ass",
+		"Subs/French/Show - 01.ass",
+		"Subs/unrelated.ass",
+	}
+	for _, file := range files {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(file)), 0755))
+		createFile(t, filepath.Join(dir, file))
+	}
+
+	subs := FindExternalSubtitles(filepath.Join(dir, "Show - 01.mkv"))
+
+	type result struct {
+		name            string
+		language        string
+		forced          bool
+		hearingImpaired bool
+	}
+	results := make([]result, 0, len(subs))
+	for _, sub := range subs {
+		rel, _ := filepath.Rel(dir, sub.Path)
+		results = append(results, result{filepath.ToSlash(rel), sub.Language, sub.Forced, sub.HearingImpaired})
+	}
+
+	assert.ElementsMatch(t, []result{
+		{"Show - 01.ass", "", false, false},
+		{"Show - 01.en.forced.srt", "en", true, false},
+		{"Show - 01.Portuguese (SDH).srt", "pt", false, true},
+		{"Subs/Show - 01/2_English.ass", "en", false, false},
+		{"Subs/French/Show - 01.ass", "fr", false, false},
+	}, results)
+}
+
+func TestFindExternalSubtitles_SingleVideo(t *testing.T) {
+	dir := t.TempDir()
+
+	createFile(t, filepath.Join(dir, "[Group] Movie (1080p).mkv"))
+	createFile(t, filepath.Join(dir, "Movie.eng.ass"))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "subs"), 0755))
+	createFile(t, filepath.Join(dir, "subs", "signs.ass"))
+
+	subs := FindExternalSubtitles(filepath.Join(dir, "[Group] Movie (1080p).mkv"))
+	require.Len(t, subs, 2)
+
+	for _, sub := range subs {
+		switch filepath.Base(sub.Path) {
+		case "Movie.eng.ass":
+			assert.Equal(t, "en", sub.Language)
+			assert.Equal(t, "ass", sub.Extension)
+		case "signs.ass":
+			assert.Equal(t, "", sub.Language)
+			assert.Equal(t, "signs", sub.Title)
+		default:
+			t.Errorf("unexpected subtitle file %s", sub.Path)
+		}
+	}
+}
+
+func TestParseSubtitleLanguage(t *testing.T) {
+	tests := map[string]string{
+		"en":      "en",
+		"eng":     "en",
+		"English": "en",
+		"pt-BR":   "pt-BR",
+		"jpn":     "ja",
+		"chs":     "zh-Hans",
+		"signs":   "",
+		"v2":      "",
+	}
+
+	for input, expected := range tests {
+		assert.Equal(t, expected, parseSubtitleLanguage(input), input)
+	}
+}
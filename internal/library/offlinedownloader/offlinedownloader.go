@@ -0,0 +1,594 @@
+package offlinedownloader
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"seanime/internal/api/anilist"
+	"seanime/internal/database/db"
+	"seanime/internal/database/db_bridge"
+	"seanime/internal/database/models"
+	debrid_client "seanime/internal/debrid/client"
+	"seanime/internal/debrid/debrid"
+	"seanime/internal/events"
+	hibiketorrent "seanime/internal/extension/hibike/torrent"
+	"seanime/internal/library/anime"
+	"seanime/internal/local"
+	"seanime/internal/notifier"
+	"seanime/internal/torrent_clients/torrent_client"
+	itorrent "seanime/internal/torrents/torrent"
+	"seanime/internal/util"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anacrolix/torrent/metainfo"
+	"github.com/rs/zerolog"
+	"github.com/samber/mo"
+)
+
+// The offline downloader pre-downloads the upcoming episodes of tracked anime into a size-capped offline folder,
+// using the torrent client or debrid. Downloaded episodes are recorded in the local database and added to the local files
+// so that they can be watched in offline mode.
+
+const (
+	// Debrid downloads that did not produce a video file after this delay are dropped
+	debridDownloadTimeout = 24 * time.Hour
+)
+
+var (
+	ErrNoTorrentFound = errors.New("offline downloader: No torrent found")
+)
+
+type (
+	OfflineDownloader struct {
+		logger                  *zerolog.Logger
+		localManager            local.Manager
+		database                *db.Database
+		torrentRepository       *itorrent.Repository
+		torrentClientRepository *torrent_client.Repository
+		debridClientRepository  *debrid_client.Repository
+		wsEventManager          events.WSEventManagerInterface
+		animeCollection         mo.Option[*anilist.AnimeCollection]
+		isOffline               *bool
+		mu                      sync.Mutex
+	}
+
+	NewOfflineDownloaderOptions struct {
+		Logger                  *zerolog.Logger
+		LocalManager            local.Manager
+		Database                *db.Database
+		TorrentRepository       *itorrent.Repository
+		TorrentClientRepository *torrent_client.Repository
+		DebridClientRepository  *debrid_client.Repository
+		WSEventManager          events.WSEventManagerInterface
+		IsOffline               *bool
+	}
+)
+
+func New(opts *NewOfflineDownloaderOptions) *OfflineDownloader {
+	return &OfflineDownloader{
+		logger:                  opts.Logger,
+		localManager:            opts.LocalManager,
+		database:                opts.Database,
+		torrentRepository:       opts.TorrentRepository,
+		torrentClientRepository: opts.TorrentClientRepository,
+		debridClientRepository:  opts.DebridClientRepository,
+		wsEventManager:          opts.WSEventManager,
+		animeCollection:         mo.None[*anilist.AnimeCollection](),
+		isOffline:               opts.IsOffline,
+	}
+}
+
+func (d *OfflineDownloader) SetAnimeCollection(ac *anilist.AnimeCollection) {
+	if ac == nil {
+		d.animeCollection = mo.None[*anilist.AnimeCollection]()
+		return
+	}
+	d.animeCollection = mo.Some(ac)
+}
+
+func (d *OfflineDownloader) SetTorrentClientRepository(repo *torrent_client.Repository) {
+	if d == nil {
+		return
+	}
+	d.torrentClientRepository = repo
+}
+
+// Run updates the status of the downloads, removes the episodes that are not needed anymore and
+// queues the upcoming episodes of tracked anime that fit in the size limit.
+// New episodes are only queued when online.
+func (d *OfflineDownloader) Run() (err error) {
+	defer util.HandlePanicInModuleWithError("offlinedownloader/Run", &err)
+
+	if !d.mu.TryLock() {
+		return nil
+	}
+	defer d.mu.Unlock()
+
+	settings := d.localManager.GetOfflineDownloadSettings()
+	if !settings.Enabled {
+		return nil
+	}
+
+	d.logger.Debug().Msg("offline downloader: Checking offline episodes")
+
+	d.updateDownloads()
+
+	progress, tracked := d.getTrackedAnime()
+	for _, oe := range selectEvictions(d.localManager.GetOfflineEpisodes(), progress, tracked, int64(settings.MaxSize)<<30) {
+		d.logger.Debug().Int("mediaId", oe.MediaId).Int("episode", oe.EpisodeNumber).Msg("offline downloader: Removing episode")
+		d.removeEpisode(oe)
+	}
+
+	if d.isOffline == nil || !*d.isOffline {
+		if err := d.queueEpisodes(settings, progress, tracked); err != nil {
+			d.logger.Warn().Err(err).Msg("offline downloader: Failed to queue episodes")
+		}
+	}
+
+	return d.updateLocalFiles(settings.Dir)
+}
+
+// RemoveEpisode stops the download of the episode and deletes its files.
+func (d *OfflineDownloader) RemoveEpisode(id uint) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	idx := slices.IndexFunc(d.localManager.GetOfflineEpisodes(), func(oe *local.OfflineEpisode) bool {
+		return oe.ID == id
+	})
+	if idx == -1 {
+		return fmt.Errorf("offline downloader: Episode not found")
+	}
+
+	d.removeEpisode(d.localManager.GetOfflineEpisodes()[idx])
+
+	return d.updateLocalFiles(d.localManager.GetOfflineDownloadSettings().Dir)
+}
+
+// DropEpisodes removes all the episodes and their local files after the offline folder was changed from dir.
+// The episodes are downloaded again to the new folder by the next run.
+func (d *OfflineDownloader) DropEpisodes(dir string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, oe := range d.localManager.GetOfflineEpisodes() {
+		d.removeEpisode(oe)
+	}
+
+	return d.updateLocalFiles(dir)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// getTrackedAnime returns the progress of the tracked anime that are in the user's collection.
+func (d *OfflineDownloader) getTrackedAnime() (progress map[int]int, tracked map[int]*anilist.AnimeListEntry) {
+	progress = make(map[int]int)
+	tracked = make(map[int]*anilist.AnimeListEntry)
+
+	ac, ok := d.animeCollection.Get()
+	if !ok {
+		return
+	}
+
+	for _, item := range d.localManager.GetTrackedMediaItems() {
+		if item.Type != local.AnimeType {
+			continue
+		}
+		entry, found := ac.GetListEntryFromAnimeId(item.MediaId)
+		if !found {
+			continue
+		}
+		tracked[item.MediaId] = entry
+		if entry.GetProgress() != nil {
+			progress[item.MediaId] = *entry.GetProgress()
+		}
+	}
+	return
+}
+
+// updateDownloads marks the episodes that have been completely downloaded as complete.
+func (d *OfflineDownloader) updateDownloads() {
+	var torrents map[string]*torrent_client.Torrent
+
+	for _, oe := range d.localManager.GetOfflineEpisodes() {
+		if oe.Status != local.OfflineEpisodeStatusDownloading {
+			continue
+		}
+
+		switch oe.Source {
+		case local.OfflineDownloadSourceTorrent:
+			if d.torrentClientRepository == nil {
+				continue
+			}
+			if torrents == nil {
+				list, err := d.torrentClientRepository.GetList()
+				if err != nil {
+					return
+				}
+				torrents = make(map[string]*torrent_client.Torrent, len(list))
+				for _, t := range list {
+					torrents[strings.ToLower(t.Hash)] = t
+				}
+			}
+			t, found := torrents[strings.ToLower(oe.InfoHash)]
+			if !found {
+				// The torrent was removed from the torrent client
+				d.logger.Debug().Str("infoHash", oe.InfoHash).Msg("offline downloader: Torrent not found in the torrent client")
+				d.removeEpisode(oe)
+				continue
+			}
+			if t.Progress < 1 {
+				continue
+			}
+		case local.OfflineDownloadSourceDebrid:
+			if d.debridClientRepository == nil || d.debridClientRepository.IsDownloading(oe.DebridItemId) {
+				continue
+			}
+			// The torrent item is removed from the database once it's ready on the debrid service
+			items, err := d.database.GetDebridTorrentItems()
+			if err != nil || slices.ContainsFunc(items, func(item *models.DebridTorrentItem) bool {
+				return item.TorrentItemID == oe.DebridItemId
+			}) {
+				continue
+			}
+		}
+
+		path, size, found := findVideoFile(oe.Dir)
+		if !found {
+			if oe.Source == local.OfflineDownloadSourceDebrid && time.Since(oe.UpdatedAt) > debridDownloadTimeout {
+				d.removeEpisode(oe)
+			}
+			continue
+		}
+
+		oe.Path = path
+		oe.Size = size
+		oe.Status = local.OfflineEpisodeStatusComplete
+		if err := d.localManager.SaveOfflineEpisode(oe); err != nil {
+			d.logger.Error().Err(err).Msg("offline downloader: Failed to save episode")
+			continue
+		}
+
+		d.logger.Info().Int("mediaId", oe.MediaId).Int("episode", oe.EpisodeNumber).Msg("offline downloader: Episode downloaded")
+		notifier.GlobalNotifier.Notify(notifier.AutoDownloader, fmt.Sprintf("Downloaded %q for offline use", oe.TorrentName))
+	}
+}
+
+// queueEpisodes adds the upcoming episodes of the tracked anime to the torrent client or debrid.
+func (d *OfflineDownloader) queueEpisodes(settings *local.OfflineDownloadSettings, progress map[int]int, tracked map[int]*anilist.AnimeListEntry) error {
+	if len(tracked) == 0 {
+		return nil
+	}
+
+	switch settings.Source {
+	case local.OfflineDownloadSourceTorrent:
+		if d.torrentClientRepository == nil || !d.torrentClientRepository.Start() {
+			return errors.New("offline downloader: Torrent client not available")
+		}
+	case local.OfflineDownloadSourceDebrid:
+		if d.debridClientRepository == nil || !d.debridClientRepository.HasProvider() {
+			return errors.New("offline downloader: Debrid provider not set")
+		}
+	}
+
+	lfs, _, err := db_bridge.GetLocalFiles(d.database)
+	if err != nil {
+		return err
+	}
+
+	episodes := d.localManager.GetOfflineEpisodes()
+	var totalSize int64
+	for _, oe := range episodes {
+		totalSize += oe.Size
+	}
+	maxSize := int64(settings.MaxSize) << 30
+
+	mediaIds := slices.Sorted(maps.Keys(tracked))
+
+	for _, mediaId := range mediaIds {
+		media := tracked[mediaId].GetMedia()
+
+		for _, episodeNumber := range upcomingEpisodes(progress[mediaId], media.GetCurrentEpisodeCount(), settings.EpisodeCount) {
+			if isInLibrary(lfs, mediaId, episodeNumber, settings.Dir) || slices.ContainsFunc(episodes, func(oe *local.OfflineEpisode) bool {
+				return oe.MediaId == mediaId && oe.EpisodeNumber == episodeNumber
+			}) {
+				continue
+			}
+
+			oe, err := d.queueEpisode(settings, media, episodeNumber, maxSize-totalSize)
+			if err != nil {
+				d.logger.Debug().Err(err).Int("mediaId", mediaId).Int("episode", episodeNumber).Msg("offline downloader: Could not queue episode")
+				continue
+			}
+			totalSize += oe.Size
+			episodes = append(episodes, oe)
+		}
+	}
+
+	return nil
+}
+
+func (d *OfflineDownloader) queueEpisode(settings *local.OfflineDownloadSettings, media *anilist.BaseAnime, episodeNumber int, remaining int64) (*local.OfflineEpisode, error) {
+	t, magnet, err := d.findTorrent(settings, media, episodeNumber, remaining)
+	if err != nil {
+		return nil, err
+	}
+
+	oe := &local.OfflineEpisode{
+		MediaId:       media.GetID(),
+		EpisodeNumber: episodeNumber,
+		Source:        settings.Source,
+		Status:        local.OfflineEpisodeStatusDownloading,
+		TorrentName:   t.Name,
+		InfoHash:      t.InfoHash,
+		Dir:           filepath.Join(settings.Dir, strconv.Itoa(media.GetID()), strconv.Itoa(episodeNumber)),
+		Size:          t.Size,
+	}
+	if oe.InfoHash == "" {
+		if m, err := metainfo.ParseMagnetUri(magnet); err == nil {
+			oe.InfoHash = m.InfoHash.HexString()
+		}
+	}
+
+	if err := os.MkdirAll(oe.Dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	switch settings.Source {
+	case local.OfflineDownloadSourceTorrent:
+		if oe.InfoHash == "" {
+			return nil, errors.New("offline downloader: Unknown info hash")
+		}
+		if err := d.torrentClientRepository.AddMagnets([]string{magnet}, oe.Dir); err != nil {
+			return nil, err
+		}
+	case local.OfflineDownloadSourceDebrid:
+		itemId, err := d.debridClientRepository.AddAndQueueTorrent(debrid.AddTorrentOptions{
+			MagnetLink:   magnet,
+			InfoHash:     oe.InfoHash,
+			SelectFileId: "all",
+		}, oe.Dir, oe.MediaId)
+		if err != nil {
+			return nil, err
+		}
+		oe.DebridItemId = itemId
+	}
+
+	if err := d.localManager.SaveOfflineEpisode(oe); err != nil {
+		return nil, err
+	}
+
+	d.logger.Info().Str("torrent", t.Name).Int("mediaId", oe.MediaId).Int("episode", episodeNumber).Msg("offline downloader: Queued episode")
+
+	return oe, nil
+}
+
+// findTorrent searches the torrent of an episode that fits in the remaining space, preferring cached torrents on debrid.
+func (d *OfflineDownloader) findTorrent(settings *local.OfflineDownloadSettings, media *anilist.BaseAnime, episodeNumber int, remaining int64) (*hibiketorrent.AnimeTorrent, string, error) {
+	if d.torrentRepository == nil {
+		return nil, "", ErrNoTorrentFound
+	}
+
+	for _, providerId := range []string{itorrent.ProviderAnimeTosho, itorrent.ProviderNyaa} {
+		providerExtension, ok := d.torrentRepository.GetAnimeProviderExtension(providerId)
+		if !ok {
+			continue
+		}
+
+		data, err := d.torrentRepository.SearchAnime(context.Background(), itorrent.AnimeSearchOptions{
+			Provider:      providerId,
+			Type:          itorrent.AnimeSearchTypeSmart,
+			Media:         media,
+			Batch:         false,
+			EpisodeNumber: episodeNumber,
+			Resolution:    settings.Resolution,
+		})
+		if err != nil || len(data.Torrents) == 0 {
+			continue
+		}
+
+		// The size is needed to respect the size limit
+		torrents := slices.DeleteFunc(slices.Clone(data.Torrents), func(t *hibiketorrent.AnimeTorrent) bool {
+			return t.Size <= 0 || t.Size > remaining
+		})
+
+		cached := make(map[string]debrid.TorrentItemInstantAvailability)
+		if settings.Source == local.OfflineDownloadSourceDebrid {
+			if provider, err := d.debridClientRepository.GetProvider(); err == nil {
+				hashes := make([]string, 0, len(torrents))
+				for _, t := range torrents {
+					if t.InfoHash != "" {
+						hashes = append(hashes, t.InfoHash)
+					}
+				}
+				cached = provider.GetInstantAvailability(hashes)
+			}
+		}
+
+		slices.SortStableFunc(torrents, func(a, b *hibiketorrent.AnimeTorrent) int {
+			_, aCached := cached[a.InfoHash]
+			_, bCached := cached[b.InfoHash]
+			if aCached != bCached {
+				if aCached {
+					return -1
+				}
+				return 1
+			}
+			return cmp.Compare(b.Seeders, a.Seeders)
+		})
+
+		for _, t := range torrents {
+			magnet, err := providerExtension.GetProvider().GetTorrentMagnetLink(t)
+			if err != nil {
+				continue
+			}
+			return t, magnet, nil
+		}
+	}
+
+	return nil, "", ErrNoTorrentFound
+}
+
+// removeEpisode stops the download and deletes the files of the episode.
+func (d *OfflineDownloader) removeEpisode(oe *local.OfflineEpisode) {
+	switch oe.Source {
+	case local.OfflineDownloadSourceTorrent:
+		if d.torrentClientRepository != nil && oe.InfoHash != "" {
+			_ = d.torrentClientRepository.RemoveTorrents([]string{oe.InfoHash})
+		}
+	case local.OfflineDownloadSourceDebrid:
+		if d.debridClientRepository != nil && d.debridClientRepository.IsDownloading(oe.DebridItemId) {
+			_ = d.debridClientRepository.CancelDownload(oe.DebridItemId)
+		}
+	}
+
+	if oe.Dir != "" {
+		_ = os.RemoveAll(oe.Dir)
+	}
+	_ = d.localManager.DeleteOfflineEpisode(oe.ID)
+}
+
+// updateLocalFiles adds the downloaded episodes to the local files and removes the files of the offline folder that were removed.
+// The downloaded episodes are added again if a scan of the library removed them.
+func (d *OfflineDownloader) updateLocalFiles(dir string) error {
+	lfs, lfsId, err := db_bridge.GetLocalFiles(d.database)
+	if err != nil {
+		return err
+	}
+
+	offlineLfs := d.localManager.GetOfflineEpisodeLocalFiles()
+	offlinePaths := make(map[string]struct{}, len(offlineLfs))
+	for _, lf := range offlineLfs {
+		offlinePaths[lf.GetNormalizedPath()] = struct{}{}
+	}
+
+	// Never remove the files of the library, the folder could have been added to the library after it was set
+	libraryPath, overlaps := local.OfflineDirOverlapsLibrary(d.database, dir)
+	if overlaps {
+		d.logger.Warn().Str("dir", dir).Str("libraryPath", libraryPath).Msg("offline downloader: The offline folder overlaps the library, not removing local files")
+	}
+
+	changed := false
+	ret := make([]*anime.LocalFile, 0, len(lfs))
+	existingPaths := make(map[string]struct{}, len(lfs))
+	for _, lf := range lfs {
+		if _, found := offlinePaths[lf.GetNormalizedPath()]; !found && !overlaps && isInDir(lf.Path, dir) {
+			changed = true
+			continue
+		}
+		existingPaths[lf.GetNormalizedPath()] = struct{}{}
+		ret = append(ret, lf)
+	}
+	for _, lf := range offlineLfs {
+		if _, found := existingPaths[lf.GetNormalizedPath()]; !found {
+			ret = append(ret, lf)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	if _, err := db_bridge.SaveLocalFiles(d.database, lfsId, ret); err != nil {
+		return err
+	}
+
+	d.wsEventManager.SendEvent(events.InvalidateQueries, []string{events.GetLocalFilesEndpoint, events.GetAnimeEntryEndpoint, events.GetLibraryCollectionEndpoint, events.GetMissingEpisodesEndpoint, events.LocalGetOfflineEpisodesEndpoint})
+
+	return nil
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// upcomingEpisodes returns the next count episodes after the progress that have aired.
+// currentEpisodeCount is -1 if unknown.
+func upcomingEpisodes(progress int, currentEpisodeCount int, count int) []int {
+	ret := make([]int, 0, count)
+	for ep := progress + 1; ep <= progress+count; ep++ {
+		if currentEpisodeCount >= 0 && ep > currentEpisodeCount {
+			break
+		}
+		ret = append(ret, ep)
+	}
+	return ret
+}
+
+// selectEvictions returns the episodes to remove:
+//   - episodes of anime that are not tracked anymore
+//   - episodes that have been watched
+//   - if the total size is still over maxSize, the episodes furthest from the progress
+func selectEvictions(episodes []*local.OfflineEpisode, progress map[int]int, tracked map[int]*anilist.AnimeListEntry, maxSize int64) []*local.OfflineEpisode {
+	ret := make([]*local.OfflineEpisode, 0)
+	kept := make([]*local.OfflineEpisode, 0, len(episodes))
+
+	var total int64
+	for _, oe := range episodes {
+		if _, found := tracked[oe.MediaId]; !found || oe.EpisodeNumber <= progress[oe.MediaId] {
+			ret = append(ret, oe)
+			continue
+		}
+		kept = append(kept, oe)
+		total += oe.Size
+	}
+
+	slices.SortStableFunc(kept, func(a, b *local.OfflineEpisode) int {
+		return cmp.Compare(b.EpisodeNumber-progress[b.MediaId], a.EpisodeNumber-progress[a.MediaId])
+	})
+	for _, oe := range kept {
+		if total <= maxSize {
+			break
+		}
+		ret = append(ret, oe)
+		total -= oe.Size
+	}
+
+	return ret
+}
+
+// isInLibrary returns true if the episode is in the local files, outside the offline folder.
+func isInLibrary(lfs []*anime.LocalFile, mediaId int, episodeNumber int, dir string) bool {
+	return slices.ContainsFunc(lfs, func(lf *anime.LocalFile) bool {
+		return lf.MediaId == mediaId && lf.GetEpisodeNumber() == episodeNumber && lf.GetType() == anime.LocalFileTypeMain && !isInDir(lf.Path, dir)
+	})
+}
+
+func isInDir(path string, dir string) bool {
+	return strings.HasPrefix(util.NormalizePath(path), strings.TrimSuffix(util.NormalizePath(dir), "/")+"/")
+}
+
+// findVideoFile returns the largest video file in the directory, ignoring the temporary folders of debrid downloads.
+func findVideoFile(dir string) (path string, size int64, found bool) {
+	_ = filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if entry.IsDir() {
+			if strings.HasPrefix(entry.Name(), ".tmp-") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !util.IsValidVideoExtension(filepath.Ext(p)) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		if info.Size() > size {
+			path, size, found = p, info.Size(), true
+		}
+		return nil
+	})
+	return
+}
@@ -0,0 +1,68 @@
+package offlinedownloader
+
+import (
+	"os"
+	"path/filepath"
+	"seanime/internal/api/anilist"
+	"seanime/internal/local"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpcomingEpisodes(t *testing.T) {
+	assert.Equal(t, []int{4, 5}, upcomingEpisodes(3, 12, 2))
+	// Only aired episodes
+	assert.Equal(t, []int{11}, upcomingEpisodes(10, 11, 3))
+	assert.Empty(t, upcomingEpisodes(12, 12, 2))
+	// Unknown episode count
+	assert.Equal(t, []int{1, 2, 3}, upcomingEpisodes(0, -1, 3))
+}
+
+func TestSelectEvictions(t *testing.T) {
+	const gib = int64(1) << 30
+
+	episodes := []*local.OfflineEpisode{
+		{BaseModel: local.BaseModel{ID: 1}, MediaId: 1, EpisodeNumber: 3, Size: gib},
+		{BaseModel: local.BaseModel{ID: 2}, MediaId: 1, EpisodeNumber: 4, Size: gib},
+		{BaseModel: local.BaseModel{ID: 3}, MediaId: 1, EpisodeNumber: 5, Size: gib},
+		{BaseModel: local.BaseModel{ID: 4}, MediaId: 2, EpisodeNumber: 8, Size: gib},
+		// Not tracked anymore
+		{BaseModel: local.BaseModel{ID: 5}, MediaId: 3, EpisodeNumber: 1, Size: gib},
+	}
+	progress := map[int]int{1: 3, 2: 7}
+	tracked := map[int]*anilist.AnimeListEntry{1: {}, 2: {}}
+
+	ids := func(episodes []*local.OfflineEpisode) []uint {
+		ret := make([]uint, 0, len(episodes))
+		for _, oe := range episodes {
+			ret = append(ret, oe.ID)
+		}
+		return ret
+	}
+
+	// Watched and untracked episodes are always removed
+	assert.Equal(t, []uint{1, 5}, ids(selectEvictions(episodes, progress, tracked, 10*gib)))
+
+	// Over the limit, the episodes furthest from the progress are removed
+	assert.Equal(t, []uint{1, 5, 3}, ids(selectEvictions(episodes, progress, tracked, 2*gib)))
+}
+
+func TestFindVideoFile(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "Show", ".tmp-123"), os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Show", "episode.mkv"), make([]byte, 10), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Show", "episode.nfo"), make([]byte, 100), 0644))
+	// Partial debrid download
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Show", ".tmp-123", "episode.mkv"), make([]byte, 100), 0644))
+
+	path, size, found := findVideoFile(dir)
+	require.True(t, found)
+	assert.Equal(t, filepath.Join(dir, "Show", "episode.mkv"), path)
+	assert.Equal(t, int64(10), size)
+
+	_, _, found = findVideoFile(filepath.Join(dir, "missing"))
+	assert.False(t, found)
+}
@@ -15,6 +15,7 @@ import (
 	"seanime/internal/library/anime"
 	"seanime/internal/mediaplayers/mediaplayer"
 	"seanime/internal/platforms/platform"
+	"seanime/internal/skipsegments"
 	"seanime/internal/util"
 	"seanime/internal/util/result"
 	"sync"
@@ -48,6 +49,7 @@ type (
 		Database              *db.Database
 		MediaPlayerRepository *mediaplayer.Repository // MediaPlayerRepository is used to control the media player
 		continuityManager     *continuity.Manager
+		skipSegmentsManager   *skipsegments.Manager
 
 		settings *Settings
 
@@ -87,6 +89,7 @@ type (
 		// The current media being streamed, set in [StartStreamingUsingMediaPlayer]
 		currentStreamMedia        mo.Option[*anilist.BaseAnime]
 		currentStreamAniDbEpisode mo.Option[string]
+		currentStreamWatchSource  continuity.WatchSource
 
 		// \/ Manual progress tracking (non-integrated external player)
 		manualTrackingCtx           context.Context
@@ -193,6 +196,7 @@ type (
 		DiscordPresence            *discordrpc_presence.Presence
 		IsOffline                  *bool
 		ContinuityManager          *continuity.Manager
+		SkipSegmentsManager        *skipsegments.Manager
 	}
 
 	Settings struct {
@@ -237,6 +241,7 @@ func New(opts *NewPlaybackManagerOptions) *PlaybackManager {
 		currentLocalFileWrapperEntry: mo.None[*anime.LocalFileWrapperEntry](),
 		currentMediaListEntry:        mo.None[*anilist.AnimeListEntry](),
 		continuityManager:            opts.ContinuityManager,
+		skipSegmentsManager:          opts.SkipSegmentsManager,
 		playbackStatusSubscribers:    result.NewResultMap[string, *PlaybackStatusSubscriber](),
 	}
 
@@ -289,6 +294,8 @@ type StartPlayingOptions struct {
 	Payload   string // url or path
 	UserAgent string
 	ClientId  string
+	// Where the stream comes from, recorded in the watch event log
+	WatchSource continuity.WatchSource
 }
 
 func (pm *PlaybackManager) StartPlayingUsingMediaPlayer(opts *StartPlayingOptions) error {
@@ -422,6 +429,7 @@ func (pm *PlaybackManager) StartStreamingUsingMediaPlayer(windowTitle string, op
 	}
 
 	pm.currentStreamMedia = mo.Some(media)
+	pm.currentStreamWatchSource = opts.WatchSource
 	episodeNumber := 0
 
 	// Find the current episode being stream
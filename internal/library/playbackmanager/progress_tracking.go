@@ -114,6 +114,10 @@ func (pm *PlaybackManager) handleTrackingStarted(status *mediaplayer.PlaybackSta
 		MediaId:       pm.currentMediaListEntry.MustGet().GetMedia().GetID(),
 		Filepath:      pm.currentLocalFile.MustGet().GetPath(),
 	})
+	pm.trackWatchEvent(status)
+
+	// ------- Skip segments ------- //
+	go pm.sendSkipSegmentsToMediaPlayer(pm.currentMediaListEntry.MustGet().GetMedia().GetID(), pm.currentLocalFile.MustGet().GetEpisodeNumber())
 
 	// ------- Playlist ------- //
 	go pm.playlistHub.onVideoStart(pm.currentMediaListEntry.MustGet(), pm.currentLocalFile.MustGet(), _ps)
@@ -142,6 +146,7 @@ func (pm *PlaybackManager) handleVideoCompleted(status *mediaplayer.PlaybackStat
 	_ps := pm.getLocalFilePlaybackState(status)
 	// Log
 	pm.Logger.Debug().Msg("playback manager: Received video completed event")
+	pm.trackWatchEvent(status)
 
 	// Notify subscribers
 	go func() {
@@ -203,6 +208,7 @@ func (pm *PlaybackManager) handleTrackingStopped(reason string) {
 	if pm.currentMediaPlaybackStatus != nil {
 		pm.continuityManager.UpdateExternalPlayerEpisodeWatchHistoryItem(pm.currentMediaPlaybackStatus.CurrentTimeInSeconds, pm.currentMediaPlaybackStatus.DurationInSeconds)
 	}
+	pm.continuityManager.EndWatchEvents(pm.MediaPlayerRepository.GetDefault(), "")
 
 	// ------- Playlist ------- //
 	go pm.playlistHub.onTrackingStopped()
@@ -242,6 +248,7 @@ func (pm *PlaybackManager) handlePlaybackStatus(status *mediaplayer.PlaybackStat
 
 	// Send the playback state to the client
 	pm.wsEventManager.SendEvent(events.PlaybackManagerProgressPlaybackState, _ps)
+	pm.trackWatchEvent(status)
 
 	// ------- Playlist ------- //
 	if pm.currentMediaListEntry.IsPresent() && pm.currentLocalFile.IsPresent() {
@@ -307,6 +314,7 @@ func (pm *PlaybackManager) handleStreamingTrackingStarted(status *mediaplayer.Pl
 		MediaId:       pm.currentStreamMedia.MustGet().GetID(),
 		Filepath:      "",
 	})
+	pm.trackWatchEvent(status)
 
 	// ------- Discord ------- //
 	if pm.discordPresence != nil && !*pm.isOffline {
@@ -355,6 +363,7 @@ func (pm *PlaybackManager) handleStreamingPlaybackStatus(status *mediaplayer.Pla
 
 	// Send the playback state to the client
 	pm.wsEventManager.SendEvent(events.PlaybackManagerProgressPlaybackState, _ps)
+	pm.trackWatchEvent(status)
 
 	// ------- Discord ------- //
 	if pm.discordPresence != nil && !*pm.isOffline {
@@ -376,6 +385,7 @@ func (pm *PlaybackManager) handleStreamingVideoCompleted(status *mediaplayer.Pla
 	_ps := pm.getStreamPlaybackState(status)
 	// Log
 	pm.Logger.Debug().Msg("playback manager: Received video completed event")
+	pm.trackWatchEvent(status)
 
 	// Notify subscribers
 	go func() {
@@ -411,6 +421,7 @@ func (pm *PlaybackManager) handleStreamingTrackingStopped(reason string) {
 	if pm.currentMediaPlaybackStatus != nil {
 		pm.continuityManager.UpdateExternalPlayerEpisodeWatchHistoryItem(pm.currentMediaPlaybackStatus.CurrentTimeInSeconds, pm.currentMediaPlaybackStatus.DurationInSeconds)
 	}
+	pm.continuityManager.EndWatchEvents(pm.MediaPlayerRepository.GetDefault(), "")
 
 	// Notify subscribers
 	go func() {
@@ -511,6 +522,42 @@ func (pm *PlaybackManager) getStreamPlaybackState(status *mediaplayer.PlaybackSt
 
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
+// trackWatchEvent records the current playback in the continuity watch event log.
+func (pm *PlaybackManager) trackWatchEvent(status *mediaplayer.PlaybackStatus) {
+	if status == nil {
+		return
+	}
+
+	opts := &continuity.TrackWatchEventOptions{
+		CurrentTime: status.CurrentTimeInSeconds,
+		Duration:    status.DurationInSeconds,
+		Player:      pm.MediaPlayerRepository.GetDefault(),
+	}
+
+	switch pm.currentPlaybackType {
+	case LocalFilePlayback:
+		if pm.currentMediaListEntry.IsAbsent() || pm.currentLocalFileWrapperEntry.IsAbsent() || pm.currentLocalFile.IsAbsent() {
+			return
+		}
+		opts.MediaId = pm.currentMediaListEntry.MustGet().GetMedia().GetID()
+		opts.EpisodeNumber = pm.currentLocalFileWrapperEntry.MustGet().GetProgressNumber(pm.currentLocalFile.MustGet())
+		opts.Source = continuity.LocalWatchSource
+	case StreamPlayback:
+		if pm.currentStreamEpisode.IsAbsent() || pm.currentStreamMedia.IsAbsent() {
+			return
+		}
+		opts.MediaId = pm.currentStreamMedia.MustGet().GetID()
+		opts.EpisodeNumber = pm.currentStreamEpisode.MustGet().GetProgressNumber()
+		opts.Source = pm.currentStreamWatchSource
+	default:
+		return
+	}
+
+	pm.continuityManager.TrackWatchEvent(opts)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
 // autoSyncCurrentProgress syncs the current video playback progress with providers.
 // This is called once when a "video complete" event is heard.
 func (pm *PlaybackManager) autoSyncCurrentProgress(_ps *PlaybackState) {
@@ -7,6 +7,7 @@ import (
 	"seanime/internal/database/db_bridge"
 	"seanime/internal/hook"
 	"seanime/internal/library/anime"
+	"seanime/internal/skipsegments"
 	"seanime/internal/util"
 	"strings"
 
@@ -139,3 +140,21 @@ func (pm *PlaybackManager) getStreamPlaybackDetails(mId int) mo.Option[*anilist.
 
 	return mo.Some(ret)
 }
+
+// sendSkipSegmentsToMediaPlayer sends the stored skip segments of the episode to the media player.
+// Segments derived from chapters are left out since the media player reads the chapters of the file itself.
+func (pm *PlaybackManager) sendSkipSegmentsToMediaPlayer(mediaId int, episodeNumber int) {
+	if pm.skipSegmentsManager == nil || pm.MediaPlayerRepository == nil {
+		return
+	}
+
+	segments := pm.skipSegmentsManager.GetSegments(&skipsegments.GetSegmentsOptions{
+		MediaId:       mediaId,
+		EpisodeNumber: episodeNumber,
+	})
+	if len(segments) == 0 {
+		return
+	}
+
+	pm.MediaPlayerRepository.SetSkipSegments(segments)
+}
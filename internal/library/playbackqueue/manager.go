@@ -0,0 +1,516 @@
+package playbackqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"seanime/internal/database/db"
+	"seanime/internal/database/db_bridge"
+	debrid_client "seanime/internal/debrid/client"
+	"seanime/internal/directstream"
+	"seanime/internal/events"
+	"seanime/internal/library/playbackmanager"
+	"seanime/internal/nativeplayer"
+	"seanime/internal/platforms/platform"
+	"seanime/internal/torrentstream"
+	"seanime/internal/util"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// PlayerExternal plays items with the external media player.
+	PlayerExternal PlayerType = "external"
+	// PlayerNative plays items with the built-in player.
+	PlayerNative PlayerType = "nativeplayer"
+)
+
+const subscriberId = "playbackqueue"
+
+var ErrQueueEmpty = errors.New("playback queue: no item to play")
+
+type (
+	PlayerType string
+
+	// Manager plays the items of the queue one after the other.
+	// The queue advances when the player stops after an item has been completed,
+	// or when the client requests the next item (online streams).
+	Manager struct {
+		logger                  *zerolog.Logger
+		wsEventManager          events.WSEventManagerInterface
+		platform                platform.Platform
+		database                *db.Database
+		playbackManager         *playbackmanager.PlaybackManager
+		torrentstreamRepository *torrentstream.Repository
+		debridClientRepository  *debrid_client.Repository
+		directStreamManager     *directstream.Manager
+		nativePlayer            *nativeplayer.NativePlayer
+
+		mu        sync.Mutex
+		queue     *Queue
+		player    PlayerType
+		clientId  string
+		userAgent string
+		// ID of the current item if it has been completed
+		completedId string
+		// ID of the last item the queue advanced from, used to advance only once per item
+		advancedFromId string
+	}
+
+	NewManagerOptions struct {
+		Logger                  *zerolog.Logger
+		WSEventManager          events.WSEventManagerInterface
+		Platform                platform.Platform
+		Database                *db.Database
+		PlaybackManager         *playbackmanager.PlaybackManager
+		TorrentstreamRepository *torrentstream.Repository
+		DebridClientRepository  *debrid_client.Repository
+		DirectStreamManager     *directstream.Manager
+		NativePlayer            *nativeplayer.NativePlayer
+	}
+
+	// State is sent to the client when the queue changes.
+	State struct {
+		Queue    *Queue     `json:"queue"`
+		Player   PlayerType `json:"player"`
+		ClientId string     `json:"clientId"`
+		// Whether the queue is driving the playback
+		Active bool `json:"active"`
+	}
+
+	PlayOptions struct {
+		// Item to play, the next item if empty
+		ItemId    string
+		Player    PlayerType
+		ClientId  string
+		UserAgent string
+	}
+
+	// PlayOnlineStreamPayload is sent to the client to play an online stream item.
+	PlayOnlineStreamPayload struct {
+		Item *Item `json:"item"`
+	}
+)
+
+func NewManager(opts *NewManagerOptions) *Manager {
+	ret := &Manager{
+		logger:                  opts.Logger,
+		wsEventManager:          opts.WSEventManager,
+		platform:                opts.Platform,
+		database:                opts.Database,
+		playbackManager:         opts.PlaybackManager,
+		torrentstreamRepository: opts.TorrentstreamRepository,
+		debridClientRepository:  opts.DebridClientRepository,
+		directStreamManager:     opts.DirectStreamManager,
+		nativePlayer:            opts.NativePlayer,
+		queue:                   NewQueue(),
+		player:                  PlayerExternal,
+	}
+
+	ret.listenToPlaybackEvents()
+
+	return ret
+}
+
+// GetState returns a copy of the current state of the queue.
+func (m *Manager) GetState() *State {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.getState()
+}
+
+func (m *Manager) getState() *State {
+	return &State{
+		Queue:    m.queue.Clone(),
+		Player:   m.player,
+		ClientId: m.clientId,
+		Active:   m.queue.CurrentId != "",
+	}
+}
+
+// IsActive returns true if the queue is driving the playback.
+func (m *Manager) IsActive() bool {
+	if m == nil {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.queue.CurrentId != ""
+}
+
+func (m *Manager) sendState() {
+	m.wsEventManager.SendEvent(events.PlaybackQueueState, m.getState())
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// Add adds items to the queue at the given index, -1 appends them.
+// If next is true, the items are added after the current item.
+func (m *Manager) Add(ctx context.Context, items []*Item, index int, next bool) error {
+	m.hydrate(ctx, items)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var err error
+	if next {
+		err = m.queue.InsertNext(items...)
+	} else {
+		err = m.queue.Insert(index, items...)
+	}
+	if err != nil {
+		return err
+	}
+
+	m.sendState()
+	return nil
+}
+
+// Remove removes an item, removing the current item plays the following one.
+func (m *Manager) Remove(ctx context.Context, id string) error {
+	m.mu.Lock()
+	wasCurrent := id == m.queue.CurrentId
+	if err := m.queue.Remove(id); err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	item, found := m.queue.Current()
+	if wasCurrent {
+		m.completedId = ""
+	}
+	m.sendState()
+	m.mu.Unlock()
+
+	if !wasCurrent || !found {
+		return nil
+	}
+	return m.playItem(ctx, item)
+}
+
+func (m *Manager) Move(id string, index int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.queue.Move(id, index); err != nil {
+		return err
+	}
+
+	m.sendState()
+	return nil
+}
+
+// Clear removes all the items and stops driving the playback.
+func (m *Manager) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.queue.Clear()
+	m.completedId = ""
+	m.sendState()
+}
+
+// Stop stops driving the playback, the items are kept.
+func (m *Manager) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.queue.CurrentId = ""
+	m.completedId = ""
+	m.sendState()
+}
+
+// SetItems replaces the items of the queue.
+func (m *Manager) SetItems(ctx context.Context, items []*Item) error {
+	for _, item := range items {
+		if err := item.validate(); err != nil {
+			return err
+		}
+	}
+	m.hydrate(ctx, items)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.queue.Clear()
+	m.completedId = ""
+	if err := m.queue.Insert(-1, items...); err != nil {
+		return err
+	}
+
+	m.sendState()
+	return nil
+}
+
+// hydrate fetches the media of the items.
+func (m *Manager) hydrate(ctx context.Context, items []*Item) {
+	media := make(map[int]bool)
+	for _, item := range items {
+		if item == nil || item.Media != nil || item.MediaId == 0 || media[item.MediaId] {
+			continue
+		}
+		media[item.MediaId] = true
+		m.hydrateMedia(ctx, item.MediaId, items)
+	}
+}
+
+func (m *Manager) hydrateMedia(ctx context.Context, mediaId int, items []*Item) {
+	media, err := m.platform.GetAnime(ctx, mediaId)
+	if err != nil {
+		m.logger.Warn().Err(err).Int("mediaId", mediaId).Msg("playback queue: Failed to fetch media")
+		return
+	}
+	for _, item := range items {
+		if item != nil && item.MediaId == mediaId {
+			item.Media = media
+		}
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// Play plays an item of the queue and starts driving the playback.
+func (m *Manager) Play(ctx context.Context, opts *PlayOptions) error {
+	m.mu.Lock()
+	if opts.Player != "" {
+		m.player = opts.Player
+	}
+	m.clientId = opts.ClientId
+	m.userAgent = opts.UserAgent
+	m.advancedFromId = ""
+
+	var item *Item
+	var found bool
+	if opts.ItemId != "" {
+		item, found = m.queue.Get(opts.ItemId)
+	} else {
+		item, found = m.queue.Next()
+	}
+	if !found {
+		m.mu.Unlock()
+		return ErrQueueEmpty
+	}
+	m.queue.CurrentId = item.ID
+	m.completedId = ""
+	m.sendState()
+	m.mu.Unlock()
+
+	return m.playItem(ctx, item)
+}
+
+// PlayNext plays the item after the current one.
+// The queue stops driving the playback when there are no items left.
+func (m *Manager) PlayNext(ctx context.Context) error {
+	m.mu.Lock()
+	item, found := m.queue.Next()
+	if !found {
+		m.queue.CurrentId = ""
+		m.completedId = ""
+		m.sendState()
+		m.mu.Unlock()
+		m.wsEventManager.SendEvent(events.InfoToast, "Playback queue finished")
+		return nil
+	}
+	m.queue.CurrentId = item.ID
+	m.completedId = ""
+	m.sendState()
+	m.mu.Unlock()
+
+	return m.playItem(ctx, item)
+}
+
+// PlayPrevious plays the item before the current one.
+func (m *Manager) PlayPrevious(ctx context.Context) error {
+	m.mu.Lock()
+	item, found := m.queue.Previous()
+	if !found {
+		m.mu.Unlock()
+		return ErrQueueEmpty
+	}
+	m.queue.CurrentId = item.ID
+	m.completedId = ""
+	m.sendState()
+	m.mu.Unlock()
+
+	return m.playItem(ctx, item)
+}
+
+func (m *Manager) playItem(ctx context.Context, item *Item) (err error) {
+	defer util.HandlePanicInModuleWithError("playbackqueue/playItem", &err)
+
+	m.mu.Lock()
+	player, clientId, userAgent := m.player, m.clientId, m.userAgent
+	m.mu.Unlock()
+
+	m.logger.Debug().Str("type", string(item.Type)).Int("mediaId", item.MediaId).Int("episode", item.EpisodeNumber).
+		Str("player", string(player)).Msg("playback queue: Playing item")
+
+	switch item.Type {
+	case ItemTypeLocalFile:
+		if player == PlayerNative {
+			lfs, _, err := db_bridge.GetLocalFiles(m.database)
+			if err != nil {
+				return err
+			}
+			return m.directStreamManager.PlayLocalFile(ctx, directstream.PlayLocalFileOptions{
+				ClientId:   clientId,
+				Path:       item.Path,
+				LocalFiles: lfs,
+			})
+		}
+		return m.playbackManager.StartPlayingUsingMediaPlayer(&playbackmanager.StartPlayingOptions{
+			Payload:   item.Path,
+			UserAgent: userAgent,
+			ClientId:  clientId,
+		})
+	case ItemTypeTorrentStream:
+		playbackType := torrentstream.PlaybackTypeExternal
+		if player == PlayerNative {
+			playbackType = torrentstream.PlaybackTypeNativePlayer
+		}
+		return m.torrentstreamRepository.StartStream(ctx, &torrentstream.StartStreamOptions{
+			MediaId:       item.MediaId,
+			EpisodeNumber: item.EpisodeNumber,
+			AniDBEpisode:  item.aniDbEpisode(),
+			AutoSelect:    true,
+			UserAgent:     userAgent,
+			ClientId:      clientId,
+			PlaybackType:  playbackType,
+		})
+	case ItemTypeDebridStream:
+		playbackType := debrid_client.PlaybackTypeDefault
+		if player == PlayerNative {
+			playbackType = debrid_client.PlaybackTypeNativePlayer
+		}
+		return m.debridClientRepository.StartStream(ctx, &debrid_client.StartStreamOptions{
+			MediaId:       item.MediaId,
+			EpisodeNumber: item.EpisodeNumber,
+			AniDBEpisode:  item.aniDbEpisode(),
+			AutoSelect:    true,
+			UserAgent:     userAgent,
+			ClientId:      clientId,
+			PlaybackType:  playbackType,
+		})
+	case ItemTypeOnlineStream:
+		// Online streams are played by the client, which requests the next item when the episode ends
+		m.wsEventManager.SendEvent(events.PlaybackQueuePlayOnlineStream, &PlayOnlineStreamPayload{Item: item})
+		return nil
+	}
+
+	return fmt.Errorf("playback queue: unknown item type %q", item.Type)
+}
+
+func (i *Item) aniDbEpisode() string {
+	if i.AniDBEpisode != "" {
+		return i.AniDBEpisode
+	}
+	return fmt.Sprintf("%d", i.EpisodeNumber)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// listenToPlaybackEvents advances the queue when the current item has been completed and the player stops.
+func (m *Manager) listenToPlaybackEvents() {
+	if m.playbackManager != nil {
+		subscriber := m.playbackManager.SubscribeToPlaybackStatus(subscriberId)
+		go func() {
+			defer util.HandlePanicInModuleThen("playbackqueue/listenToPlaybackEvents", func() {})
+			for event := range subscriber.EventCh {
+				if !m.isCurrentPlayer(PlayerExternal, "") {
+					continue
+				}
+				switch event.(type) {
+				case playbackmanager.VideoCompletedEvent, playbackmanager.StreamCompletedEvent:
+					m.markCompleted()
+				case playbackmanager.VideoStoppedEvent, playbackmanager.StreamStoppedEvent:
+					m.advanceIfCompleted()
+				}
+			}
+		}()
+	}
+
+	if m.nativePlayer != nil {
+		subscriber := m.nativePlayer.Subscribe(subscriberId)
+		go func() {
+			defer util.HandlePanicInModuleThen("playbackqueue/listenToNativePlayerEvents", func() {})
+			for event := range subscriber.Events() {
+				switch e := event.(type) {
+				case *nativeplayer.VideoCompletedEvent:
+					if m.isCurrentPlayer(PlayerNative, e.ClientId) {
+						m.markCompleted()
+					}
+				case *nativeplayer.VideoEndedEvent:
+					if m.isCurrentPlayer(PlayerNative, e.ClientId) {
+						m.advanceIfCompleted()
+					}
+				}
+			}
+		}()
+	}
+}
+
+// isCurrentPlayer returns true if the queue is driving the playback of the given player.
+func (m *Manager) isCurrentPlayer(player PlayerType, clientId string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.queue.CurrentId == "" || m.player != player {
+		return false
+	}
+	if clientId != "" && m.clientId != "" && clientId != m.clientId {
+		return false
+	}
+	item, found := m.queue.Current()
+	return found && item.Type != ItemTypeOnlineStream
+}
+
+func (m *Manager) markCompleted() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.completedId = m.queue.CurrentId
+}
+
+func (m *Manager) advanceIfCompleted() {
+	m.mu.Lock()
+	current := m.queue.CurrentId
+	if current == "" || m.completedId != current || m.advancedFromId == current {
+		m.mu.Unlock()
+		return
+	}
+	m.advancedFromId = current
+	m.mu.Unlock()
+
+	go func() {
+		if err := m.PlayNext(context.Background()); err != nil {
+			m.logger.Error().Err(err).Msg("playback queue: Failed to play next item")
+			m.wsEventManager.SendEvent(events.ErrorToast, fmt.Sprintf("Playback queue: %s", err.Error()))
+		}
+	}()
+}
+
+// Shutdown unsubscribes from the players.
+func (m *Manager) Shutdown() {
+	if m.playbackManager != nil {
+		m.playbackManager.UnsubscribeFromPlaybackStatus(subscriberId)
+	}
+	if m.nativePlayer != nil {
+		m.nativePlayer.Unsubscribe(subscriberId)
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// GetWatchOrderItems returns the episodes of the franchise of a media in the given watch order.
+// Episodes in the library are played from local files, the others use the fallback type.
+func (m *Manager) GetWatchOrderItems(ctx context.Context, mediaId int, order WatchOrder, fallback ItemType, onlineStream *OnlineStreamOptions) ([]*Item, error) {
+	nodes, err := fetchFranchise(ctx, m.platform, mediaId)
+	if err != nil {
+		return nil, err
+	}
+
+	lfs, _, err := db_bridge.GetLocalFiles(m.database)
+	if err != nil {
+		return nil, err
+	}
+
+	return watchOrderItems(sortFranchise(mediaId, nodes, order), lfs, fallback, onlineStream), nil
+}
@@ -0,0 +1,168 @@
+package playbackqueue
+
+import (
+	"seanime/internal/api/anilist"
+	"seanime/internal/library/anime"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func torrentItem(mediaId int, ep int) *Item {
+	return &Item{Type: ItemTypeTorrentStream, MediaId: mediaId, EpisodeNumber: ep}
+}
+
+func itemEpisodes(items []*Item) []int {
+	return lo.Map(items, func(i *Item, _ int) int { return i.EpisodeNumber })
+}
+
+func TestQueue(t *testing.T) {
+	q := NewQueue()
+
+	require.NoError(t, q.Insert(-1, torrentItem(1, 1), torrentItem(1, 2), torrentItem(1, 3)))
+	assert.ErrorIs(t, q.Insert(-1, &Item{Type: ItemTypeLocalFile, MediaId: 1}), ErrInvalidItem)
+	assert.ErrorIs(t, q.Insert(-1, &Item{Type: ItemTypeOnlineStream, MediaId: 1, EpisodeNumber: 1}), ErrInvalidItem)
+
+	// Playback has not started
+	next, found := q.Next()
+	require.True(t, found)
+	assert.Equal(t, 1, next.EpisodeNumber)
+	_, found = q.Previous()
+	assert.False(t, found)
+
+	q.CurrentId = q.Items[1].ID
+	next, found = q.Next()
+	require.True(t, found)
+	assert.Equal(t, 3, next.EpisodeNumber)
+	assert.Equal(t, 1, q.Remaining())
+
+	// Added after the current item
+	require.NoError(t, q.InsertNext(torrentItem(2, 1)))
+	assert.Equal(t, []int{1, 2, 1, 3}, itemEpisodes(q.Items))
+	assert.Equal(t, 2, q.Items[2].MediaId)
+
+	// Reorder while playing
+	require.NoError(t, q.Move(q.Items[3].ID, 0))
+	assert.Equal(t, []int{3, 1, 2, 1}, itemEpisodes(q.Items))
+	current, found := q.Current()
+	require.True(t, found)
+	assert.Equal(t, 2, current.EpisodeNumber)
+	require.NoError(t, q.Move(q.Items[0].ID, 10))
+	assert.Equal(t, []int{1, 2, 1, 3}, itemEpisodes(q.Items))
+	assert.ErrorIs(t, q.Move("unknown", 0), ErrItemNotFound)
+
+	// Removing the current item advances to the following item
+	require.NoError(t, q.Remove(q.CurrentId))
+	current, found = q.Current()
+	require.True(t, found)
+	assert.Equal(t, 1, current.EpisodeNumber)
+	assert.Equal(t, 2, current.MediaId)
+	assert.Len(t, q.Items, 3)
+
+	clone := q.Clone()
+	clone.Items[0].EpisodeNumber = 10
+	require.NoError(t, clone.Remove(clone.Items[1].ID))
+	assert.Equal(t, []int{1, 1, 3}, itemEpisodes(q.Items))
+
+	// Playback stops when the last item is removed while being played
+	q.CurrentId = q.Items[2].ID
+	require.NoError(t, q.Remove(q.CurrentId))
+	assert.Empty(t, q.CurrentId)
+	assert.Len(t, q.Items, 2)
+}
+
+func date(year, month int) *anilist.BaseAnime_StartDate {
+	return &anilist.BaseAnime_StartDate{Year: lo.ToPtr(year), Month: lo.ToPtr(month)}
+}
+
+// Season 1 -> Season 2 -> Movie, with an OVA set after Season 1 and released after the movie,
+// and a special whose parent is Season 2.
+func testFranchise() map[int]*franchiseNode {
+	return map[int]*franchiseNode{
+		1: {
+			Media: &anilist.BaseAnime{ID: 1, StartDate: date(2018, 1)},
+			Relations: []*franchiseRelation{
+				{Type: anilist.MediaRelationSequel, MediaId: 2},
+				{Type: anilist.MediaRelationSideStory, MediaId: 4},
+			},
+		},
+		2: {
+			Media: &anilist.BaseAnime{ID: 2, StartDate: date(2019, 4)},
+			Relations: []*franchiseRelation{
+				{Type: anilist.MediaRelationPrequel, MediaId: 1},
+				{Type: anilist.MediaRelationSequel, MediaId: 3},
+			},
+		},
+		3: {
+			Media: &anilist.BaseAnime{ID: 3, StartDate: date(2020, 8)},
+			Relations: []*franchiseRelation{
+				{Type: anilist.MediaRelationPrequel, MediaId: 2},
+			},
+		},
+		4: {
+			Media: &anilist.BaseAnime{ID: 4, StartDate: date(2021, 1)},
+			Relations: []*franchiseRelation{
+				{Type: anilist.MediaRelationParent, MediaId: 1},
+			},
+		},
+		5: {
+			Media: &anilist.BaseAnime{ID: 5, StartDate: date(2019, 12)},
+			Relations: []*franchiseRelation{
+				{Type: anilist.MediaRelationParent, MediaId: 2},
+			},
+		},
+	}
+}
+
+func TestSortFranchise(t *testing.T) {
+	ids := func(media []*anilist.BaseAnime) []int {
+		return lo.Map(media, func(m *anilist.BaseAnime, _ int) int { return m.ID })
+	}
+
+	tests := []struct {
+		name     string
+		mediaId  int
+		order    WatchOrder
+		expected []int
+	}{
+		{name: "series", mediaId: 3, order: WatchOrderSeries, expected: []int{1, 2, 3}},
+		{name: "chronological", mediaId: 2, order: WatchOrderChronological, expected: []int{1, 4, 2, 5, 3}},
+		{name: "release", mediaId: 1, order: WatchOrderRelease, expected: []int{1, 2, 5, 3, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ids(sortFranchise(tt.mediaId, testFranchise(), tt.order)))
+		})
+	}
+}
+
+func TestWatchOrderItems(t *testing.T) {
+	media := []*anilist.BaseAnime{
+		{ID: 1, Episodes: lo.ToPtr(3), Status: lo.ToPtr(anilist.MediaStatusFinished)},
+		{ID: 2, Episodes: lo.ToPtr(1), Status: lo.ToPtr(anilist.MediaStatusFinished)},
+	}
+	lfs := []*anime.LocalFile{
+		{Path: "/anime/S1/02.mkv", MediaId: 1, Metadata: &anime.LocalFileMetadata{Episode: 2, Type: anime.LocalFileTypeMain}},
+		{Path: "/anime/S1/NCOP.mkv", MediaId: 1, Metadata: &anime.LocalFileMetadata{Type: anime.LocalFileTypeNC}},
+	}
+
+	items := watchOrderItems(media, lfs, ItemTypeDebridStream, nil)
+	require.Len(t, items, 4)
+	assert.Equal(t, ItemTypeDebridStream, items[0].Type)
+	assert.Equal(t, "1", items[0].AniDBEpisode)
+	assert.Equal(t, ItemTypeLocalFile, items[1].Type)
+	assert.Equal(t, "/anime/S1/02.mkv", items[1].Path)
+	assert.Equal(t, 2, items[3].MediaId)
+
+	// Missing episodes are skipped without a fallback
+	items = watchOrderItems(media, lfs, "", nil)
+	require.Len(t, items, 1)
+	assert.Equal(t, ItemTypeLocalFile, items[0].Type)
+
+	items = watchOrderItems(media, lfs, ItemTypeOnlineStream, &OnlineStreamOptions{Provider: "provider"})
+	require.Len(t, items, 4)
+	assert.Equal(t, "provider", items[3].OnlineStream.Provider)
+}
@@ -0,0 +1,99 @@
+package playbackqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"seanime/internal/database/models"
+)
+
+type (
+	// Playlist is a saved queue.
+	Playlist struct {
+		DbId  uint    `json:"dbId"`
+		Name  string  `json:"name"`
+		Items []*Item `json:"items"`
+	}
+)
+
+// SavePlaylist saves the items of the queue as a playlist.
+// The playlist with the given ID is replaced, a new one is created if the ID is 0.
+func (m *Manager) SavePlaylist(dbId uint, name string) (*Playlist, error) {
+	if name == "" {
+		return nil, errors.New("playback queue: playlist name is required")
+	}
+
+	m.mu.Lock()
+	items := make([]*Item, 0, len(m.queue.Items))
+	for _, item := range m.queue.Items {
+		// The media is fetched again when the playlist is loaded
+		i := *item
+		i.Media = nil
+		items = append(items, &i)
+	}
+	m.mu.Unlock()
+
+	value, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &models.QueuePlaylist{
+		Name:  name,
+		Value: value,
+	}
+	entry.ID = dbId
+
+	entry, err = m.database.SaveQueuePlaylist(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Playlist{DbId: entry.ID, Name: entry.Name, Items: items}, nil
+}
+
+// GetPlaylists returns the saved playlists.
+func (m *Manager) GetPlaylists() ([]*Playlist, error) {
+	entries, err := m.database.GetQueuePlaylists()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]*Playlist, 0, len(entries))
+	for _, entry := range entries {
+		playlist, err := playlistFromModel(entry)
+		if err != nil {
+			m.logger.Warn().Err(err).Uint("dbId", entry.ID).Msg("playback queue: Failed to read playlist")
+			continue
+		}
+		ret = append(ret, playlist)
+	}
+	return ret, nil
+}
+
+// LoadPlaylist replaces the items of the queue with the items of a saved playlist.
+func (m *Manager) LoadPlaylist(ctx context.Context, dbId uint) error {
+	entry, err := m.database.GetQueuePlaylist(dbId)
+	if err != nil {
+		return err
+	}
+
+	playlist, err := playlistFromModel(entry)
+	if err != nil {
+		return err
+	}
+
+	return m.SetItems(ctx, playlist.Items)
+}
+
+func (m *Manager) DeletePlaylist(dbId uint) error {
+	return m.database.DeleteQueuePlaylist(dbId)
+}
+
+func playlistFromModel(entry *models.QueuePlaylist) (*Playlist, error) {
+	var items []*Item
+	if err := json.Unmarshal(entry.Value, &items); err != nil {
+		return nil, err
+	}
+	return &Playlist{DbId: entry.ID, Name: entry.Name, Items: items}, nil
+}
@@ -0,0 +1,210 @@
+package playbackqueue
+
+import (
+	"errors"
+	"seanime/internal/api/anilist"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ItemTypeLocalFile     ItemType = "localfile"
+	ItemTypeTorrentStream ItemType = "torrentstream"
+	ItemTypeDebridStream  ItemType = "debridstream"
+	ItemTypeOnlineStream  ItemType = "onlinestream"
+)
+
+var (
+	ErrItemNotFound = errors.New("playback queue: item not found")
+	ErrInvalidItem  = errors.New("playback queue: invalid item")
+)
+
+type (
+	ItemType string
+
+	// Item is an episode in the queue.
+	Item struct {
+		ID            string   `json:"id"`
+		Type          ItemType `json:"type"`
+		MediaId       int      `json:"mediaId"`
+		EpisodeNumber int      `json:"episodeNumber"`
+		// AniDB episode, used by torrent and debrid streams, e.g. "1", "S1"
+		AniDBEpisode string `json:"aniDbEpisode,omitempty"`
+		// Path of the local file, ItemTypeLocalFile only
+		Path string `json:"path,omitempty"`
+		// ItemTypeOnlineStream only
+		OnlineStream *OnlineStreamOptions `json:"onlineStream,omitempty"`
+		// Hydrated when the item is added
+		Media *anilist.BaseAnime `json:"media,omitempty"`
+	}
+
+	OnlineStreamOptions struct {
+		Provider string `json:"provider"`
+		Server   string `json:"server,omitempty"`
+		Dubbed   bool   `json:"dubbed"`
+		Quality  string `json:"quality,omitempty"`
+	}
+
+	// Queue is an ordered list of items and the position of the item being played.
+	// It is not safe for concurrent use.
+	Queue struct {
+		Items []*Item `json:"items"`
+		// ID of the item being played, empty if playback has not started
+		CurrentId string `json:"currentId"`
+	}
+)
+
+func (i *Item) validate() error {
+	if i == nil || i.MediaId == 0 {
+		return ErrInvalidItem
+	}
+	switch i.Type {
+	case ItemTypeLocalFile:
+		if i.Path == "" {
+			return ErrInvalidItem
+		}
+	case ItemTypeTorrentStream, ItemTypeDebridStream:
+		if i.EpisodeNumber == 0 && i.AniDBEpisode == "" {
+			return ErrInvalidItem
+		}
+	case ItemTypeOnlineStream:
+		if i.OnlineStream == nil || i.OnlineStream.Provider == "" {
+			return ErrInvalidItem
+		}
+	default:
+		return ErrInvalidItem
+	}
+	return nil
+}
+
+func NewQueue() *Queue {
+	return &Queue{
+		Items: make([]*Item, 0),
+	}
+}
+
+// Insert inserts items at the given index, -1 appends them.
+// Items are given a new ID.
+func (q *Queue) Insert(index int, items ...*Item) error {
+	for _, item := range items {
+		if err := item.validate(); err != nil {
+			return err
+		}
+	}
+	for _, item := range items {
+		item.ID = uuid.NewString()
+	}
+
+	if index < 0 || index > len(q.Items) {
+		index = len(q.Items)
+	}
+	q.Items = slices.Insert(q.Items, index, items...)
+	return nil
+}
+
+// InsertNext inserts items after the current item.
+func (q *Queue) InsertNext(items ...*Item) error {
+	return q.Insert(q.currentIndex()+1, items...)
+}
+
+// Remove removes an item.
+// If it is the current item, the following item becomes the current one, playback stops if there is none.
+func (q *Queue) Remove(id string) error {
+	idx := q.indexOf(id)
+	if idx == -1 {
+		return ErrItemNotFound
+	}
+	q.Items = slices.Delete(q.Items, idx, idx+1)
+	if id == q.CurrentId {
+		q.CurrentId = ""
+		if idx < len(q.Items) {
+			q.CurrentId = q.Items[idx].ID
+		}
+	}
+	return nil
+}
+
+// Move moves an item to the given index.
+func (q *Queue) Move(id string, index int) error {
+	idx := q.indexOf(id)
+	if idx == -1 {
+		return ErrItemNotFound
+	}
+	item := q.Items[idx]
+	q.Items = slices.Delete(q.Items, idx, idx+1)
+	index = max(0, min(index, len(q.Items)))
+	q.Items = slices.Insert(q.Items, index, item)
+	return nil
+}
+
+func (q *Queue) Clear() {
+	q.Items = make([]*Item, 0)
+	q.CurrentId = ""
+}
+
+func (q *Queue) Get(id string) (*Item, bool) {
+	idx := q.indexOf(id)
+	if idx == -1 {
+		return nil, false
+	}
+	return q.Items[idx], true
+}
+
+func (q *Queue) Current() (*Item, bool) {
+	return q.Get(q.CurrentId)
+}
+
+// Next returns the item after the current one, or the first item if playback has not started.
+func (q *Queue) Next() (*Item, bool) {
+	idx := q.currentIndex() + 1
+	if idx >= len(q.Items) {
+		return nil, false
+	}
+	return q.Items[idx], true
+}
+
+// Previous returns the item before the current one.
+func (q *Queue) Previous() (*Item, bool) {
+	idx := q.currentIndex() - 1
+	if idx < 0 {
+		return nil, false
+	}
+	return q.Items[idx], true
+}
+
+// Remaining returns the number of items after the current one.
+func (q *Queue) Remaining() int {
+	return len(q.Items) - q.currentIndex() - 1
+}
+
+// Clone returns a copy of the queue that does not share its items.
+// The media of the items is shared since it is not modified once hydrated.
+func (q *Queue) Clone() *Queue {
+	ret := &Queue{
+		Items:     make([]*Item, 0, len(q.Items)),
+		CurrentId: q.CurrentId,
+	}
+	for _, item := range q.Items {
+		c := *item
+		if item.OnlineStream != nil {
+			onlineStream := *item.OnlineStream
+			c.OnlineStream = &onlineStream
+		}
+		ret.Items = append(ret.Items, &c)
+	}
+	return ret
+}
+
+func (q *Queue) currentIndex() int {
+	return q.indexOf(q.CurrentId)
+}
+
+func (q *Queue) indexOf(id string) int {
+	if id == "" {
+		return -1
+	}
+	return slices.IndexFunc(q.Items, func(i *Item) bool {
+		return i.ID == id
+	})
+}
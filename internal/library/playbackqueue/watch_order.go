@@ -0,0 +1,258 @@
+package playbackqueue
+
+import (
+	"cmp"
+	"context"
+	"seanime/internal/api/anilist"
+	"seanime/internal/library/anime"
+	"seanime/internal/platforms/platform"
+	"slices"
+	"strconv"
+)
+
+const (
+	// WatchOrderSeries plays the prequel/sequel chain of the franchise.
+	WatchOrderSeries WatchOrder = "series"
+	// WatchOrderChronological plays the prequel/sequel chain, with each side story after the entry it belongs to.
+	WatchOrderChronological WatchOrder = "chronological"
+	// WatchOrderRelease plays the chain and side stories in order of release.
+	WatchOrderRelease WatchOrder = "release"
+)
+
+// maxFranchiseSize is the maximum number of media fetched to build a watch order.
+const maxFranchiseSize = 25
+
+type (
+	WatchOrder string
+
+	franchiseNode struct {
+		Media     *anilist.BaseAnime
+		Relations []*franchiseRelation
+	}
+
+	franchiseRelation struct {
+		Type    anilist.MediaRelation
+		MediaId int
+	}
+)
+
+// followedRelations are the relations used to build the franchise.
+var followedRelations = []anilist.MediaRelation{
+	anilist.MediaRelationPrequel,
+	anilist.MediaRelationSequel,
+	anilist.MediaRelationSideStory,
+	anilist.MediaRelationParent,
+}
+
+// fetchFranchise fetches the media related to the given media.
+// Unreleased media and formats other than TV, movies, OVAs and ONAs are not included.
+func fetchFranchise(ctx context.Context, p platform.Platform, mediaId int) (map[int]*franchiseNode, error) {
+	nodes := make(map[int]*franchiseNode)
+	queue := []int{mediaId}
+
+	for len(queue) > 0 && len(nodes) < maxFranchiseSize {
+		mId := queue[0]
+		queue = queue[1:]
+		if _, found := nodes[mId]; found {
+			continue
+		}
+
+		media, err := p.GetAnimeWithRelations(ctx, mId)
+		if err != nil {
+			// The requested media is required
+			if mId == mediaId {
+				return nil, err
+			}
+			continue
+		}
+
+		node := &franchiseNode{Media: media.ToBaseAnime()}
+		for _, edge := range media.GetRelations().GetEdges() {
+			if edge.GetRelationType() == nil || edge.GetNode() == nil || !edge.IsBroadRelationFormat() {
+				continue
+			}
+			if !slices.Contains(followedRelations, *edge.GetRelationType()) {
+				continue
+			}
+			if status := edge.GetNode().GetStatus(); status != nil && *status == anilist.MediaStatusNotYetReleased {
+				continue
+			}
+			node.Relations = append(node.Relations, &franchiseRelation{Type: *edge.GetRelationType(), MediaId: edge.GetNode().GetID()})
+			queue = append(queue, edge.GetNode().GetID())
+		}
+		nodes[mId] = node
+	}
+
+	return nodes, nil
+}
+
+// sortFranchise returns the media of the franchise in the given watch order.
+func sortFranchise(mediaId int, nodes map[int]*franchiseNode, order WatchOrder) []*anilist.BaseAnime {
+	if _, found := nodes[mediaId]; !found {
+		return nil
+	}
+
+	// Go back to the first entry
+	root := mediaId
+	visited := map[int]bool{root: true}
+	for {
+		prequel, found := nodes[root].first(nodes, anilist.MediaRelationPrequel, visited)
+		if !found {
+			break
+		}
+		visited[prequel] = true
+		root = prequel
+	}
+
+	// Follow the sequels
+	chain := []int{root}
+	visited = map[int]bool{root: true}
+	for {
+		sequel, found := nodes[chain[len(chain)-1]].first(nodes, anilist.MediaRelationSequel, visited)
+		if !found {
+			break
+		}
+		visited[sequel] = true
+		chain = append(chain, sequel)
+	}
+
+	ret := make([]*anilist.BaseAnime, 0, len(nodes))
+	switch order {
+	case WatchOrderChronological:
+		for _, mId := range chain {
+			ret = append(ret, nodes[mId].Media)
+			for _, sideStoryId := range sideStories(mId, nodes, visited) {
+				ret = append(ret, nodes[sideStoryId].Media)
+			}
+		}
+	case WatchOrderRelease:
+		ids := slices.Clone(chain)
+		for _, mId := range chain {
+			ids = append(ids, sideStories(mId, nodes, visited)...)
+		}
+		for _, mId := range ids {
+			ret = append(ret, nodes[mId].Media)
+		}
+		slices.SortStableFunc(ret, compareStartDate)
+	default:
+		for _, mId := range chain {
+			ret = append(ret, nodes[mId].Media)
+		}
+	}
+
+	return ret
+}
+
+// first returns the earliest related media with the given relation that has not been visited.
+func (n *franchiseNode) first(nodes map[int]*franchiseNode, relation anilist.MediaRelation, visited map[int]bool) (int, bool) {
+	var ret *anilist.BaseAnime
+	for _, r := range n.Relations {
+		related, found := nodes[r.MediaId]
+		if r.Type != relation || !found || visited[r.MediaId] {
+			continue
+		}
+		if ret == nil || compareStartDate(related.Media, ret) < 0 {
+			ret = related.Media
+		}
+	}
+	if ret == nil {
+		return 0, false
+	}
+	return ret.GetID(), true
+}
+
+// sideStories returns the side stories of a media sorted by start date and marks them as visited.
+// Side stories are either listed by the media or list the media as their parent.
+func sideStories(mediaId int, nodes map[int]*franchiseNode, visited map[int]bool) []int {
+	ids := make([]int, 0)
+	for _, r := range nodes[mediaId].Relations {
+		if r.Type == anilist.MediaRelationSideStory {
+			ids = append(ids, r.MediaId)
+		}
+	}
+	for mId, node := range nodes {
+		for _, r := range node.Relations {
+			if r.Type == anilist.MediaRelationParent && r.MediaId == mediaId {
+				ids = append(ids, mId)
+			}
+		}
+	}
+
+	media := make([]*anilist.BaseAnime, 0, len(ids))
+	for _, mId := range ids {
+		node, found := nodes[mId]
+		if !found || visited[mId] {
+			continue
+		}
+		visited[mId] = true
+		media = append(media, node.Media)
+	}
+	slices.SortStableFunc(media, compareStartDate)
+
+	ret := make([]int, 0, len(media))
+	for _, m := range media {
+		ret = append(ret, m.GetID())
+	}
+	return ret
+}
+
+// compareStartDate compares the start dates of two media, unknown dates are sorted last.
+func compareStartDate(a, b *anilist.BaseAnime) int {
+	for _, f := range []func(d *anilist.BaseAnime_StartDate) *int{
+		func(d *anilist.BaseAnime_StartDate) *int { return d.Year },
+		func(d *anilist.BaseAnime_StartDate) *int { return d.Month },
+		func(d *anilist.BaseAnime_StartDate) *int { return d.Day },
+	} {
+		if c := cmp.Compare(dateValue(a, f), dateValue(b, f)); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(a.GetID(), b.GetID())
+}
+
+func dateValue(m *anilist.BaseAnime, f func(d *anilist.BaseAnime_StartDate) *int) int {
+	if m.GetStartDate() == nil || f(m.GetStartDate()) == nil {
+		return 1 << 30
+	}
+	return *f(m.GetStartDate())
+}
+
+// watchOrderItems returns the episodes of the given media.
+// Main episodes in the library are played from local files, the others use the fallback type.
+// Missing episodes are skipped if there is no fallback.
+func watchOrderItems(media []*anilist.BaseAnime, lfs []*anime.LocalFile, fallback ItemType, onlineStream *OnlineStreamOptions) []*Item {
+	lfWrapper := anime.NewLocalFileWrapper(lfs)
+
+	ret := make([]*Item, 0)
+	for _, m := range media {
+		files := make(map[int]*anime.LocalFile)
+		if entry, found := lfWrapper.GetLocalEntryById(m.GetID()); found {
+			for _, lf := range entry.GetLocalFiles() {
+				if lf.Metadata == nil || !lf.IsMain() {
+					continue
+				}
+				files[entry.GetProgressNumber(lf)] = lf
+			}
+		}
+
+		for ep := 1; ep <= m.GetCurrentEpisodeCount(); ep++ {
+			item := &Item{MediaId: m.GetID(), EpisodeNumber: ep, Media: m}
+			if lf, found := files[ep]; found {
+				item.Type = ItemTypeLocalFile
+				item.Path = lf.Path
+			} else {
+				item.Type = fallback
+				item.AniDBEpisode = strconv.Itoa(ep)
+				if fallback == ItemTypeOnlineStream && onlineStream != nil {
+					item.OnlineStream = new(OnlineStreamOptions)
+					*item.OnlineStream = *onlineStream
+				}
+			}
+			if item.validate() != nil {
+				continue
+			}
+			ret = append(ret, item)
+		}
+	}
+	return ret
+}
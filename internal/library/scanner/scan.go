@@ -244,6 +244,8 @@ func (scn *Scanner) Scan(ctx context.Context) (lfs []*anime.LocalFile, err error
 				}
 			}
 		}
+		scn.matchExternalSubtitles(localFiles)
+
 		scn.Logger.Debug().Msg("scanner: Scan completed")
 		scn.WSEventManager.SendEvent(events.EventScanProgress, 100)
 		scn.WSEventManager.SendEvent(events.EventScanStatus, "Scan completed")
@@ -398,6 +400,12 @@ func (scn *Scanner) Scan(ctx context.Context) (lfs []*anime.LocalFile, err error
 		wg.Wait()
 	}
 
+	// +---------------------+
+	// |     Subtitles       |
+	// +---------------------+
+
+	scn.matchExternalSubtitles(localFiles)
+
 	scn.Logger.Info().Msg("scanner: Scan completed")
 	scn.WSEventManager.SendEvent(events.EventScanProgress, 100)
 	scn.WSEventManager.SendEvent(events.EventScanStatus, "Scan completed")
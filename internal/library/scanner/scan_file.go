@@ -0,0 +1,75 @@
+package scanner
+
+import (
+	"errors"
+	"seanime/internal/api/anilist"
+	"seanime/internal/api/metadata"
+	"seanime/internal/library/anime"
+	"seanime/internal/library/filesystem"
+	"seanime/internal/platforms/platform"
+	"seanime/internal/util/limiter"
+
+	"github.com/rs/zerolog"
+)
+
+type ScanFileOptions struct {
+	Path             string                 // Path of the file, it should be in one of the library paths
+	LibraryPaths     []string               // Used to parse the folder names of the file
+	Media            *anilist.CompleteAnime // The media the file belongs to
+	Platform         platform.Platform
+	MetadataProvider metadata.Provider
+	Logger           *zerolog.Logger
+}
+
+// ScanFile scans a single file that was added to the library, the rest of the library is not scanned.
+// The file is matched to the given media and goes through the same parsing, hydration and subtitle matching as a library scan.
+func ScanFile(opts *ScanFileOptions) (*anime.LocalFile, error) {
+	if opts.Media == nil || opts.Platform == nil {
+		return nil, errors.New("scanner: media and platform are required")
+	}
+
+	completeAnimeCache := anilist.NewCompleteAnimeCache()
+	anilistRateLimiter := limiter.NewAnilistLimiter()
+
+	lf := anime.NewLocalFileS(opts.Path, opts.LibraryPaths)
+	lfs := []*anime.LocalFile{lf}
+
+	// The relations of the media are needed to resolve absolute episode numbers
+	tree := anilist.NewCompleteAnimeRelationTree()
+	if err := opts.Media.FetchMediaTree(anilist.FetchMediaTreeAll, opts.Platform.GetAnilistClient(), anilistRateLimiter, tree, completeAnimeCache); err != nil {
+		return nil, err
+	}
+
+	mc := NewMediaContainer(&MediaContainerOptions{
+		AllMedia: tree.Values(),
+	})
+
+	matcher := &Matcher{
+		LocalFiles:         lfs,
+		MediaContainer:     mc,
+		CompleteAnimeCache: completeAnimeCache,
+		Logger:             opts.Logger,
+	}
+	if err := matcher.MatchLocalFilesWithMedia(); err != nil {
+		return nil, err
+	}
+	lf.MediaId = opts.Media.GetID()
+
+	fh := &FileHydrator{
+		LocalFiles:         lfs,
+		AllMedia:           mc.NormalizedMedia,
+		CompleteAnimeCache: completeAnimeCache,
+		Platform:           opts.Platform,
+		MetadataProvider:   opts.MetadataProvider,
+		AnilistRateLimiter: anilistRateLimiter,
+		Logger:             opts.Logger,
+		ForceMediaId:       opts.Media.GetID(),
+	}
+	fh.HydrateMetadata()
+
+	lf.Subtitles = filesystem.NewExternalSubtitleFinder().Find(lf.Path)
+
+	opts.Logger.Debug().Str("path", lf.Path).Int("mediaId", lf.MediaId).Msg("scanner: Scanned file")
+
+	return lf, nil
+}
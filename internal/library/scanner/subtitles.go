@@ -0,0 +1,25 @@
+package scanner
+
+import (
+	"seanime/internal/library/anime"
+	"seanime/internal/library/filesystem"
+)
+
+// matchExternalSubtitles associates the external subtitle files found on disk with the local files.
+// Locked and ignored files are updated too since subtitle files can be added or removed at any time.
+func (scn *Scanner) matchExternalSubtitles(localFiles []*anime.LocalFile) {
+	finder := filesystem.NewExternalSubtitleFinder()
+
+	count := 0
+	for _, lf := range localFiles {
+		lf.Subtitles = finder.Find(lf.Path)
+		count += len(lf.Subtitles)
+	}
+
+	scn.Logger.Debug().Int("count", count).Msg("scanner: Matched external subtitle files")
+	if scn.ScanLogger != nil {
+		scn.ScanLogger.logger.Debug().
+			Int("count", count).
+			Msg("Matched external subtitle files")
+	}
+}
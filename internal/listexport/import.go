@@ -0,0 +1,153 @@
+package listexport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"seanime/internal/api/anilist"
+	"seanime/internal/api/anizip"
+	"seanime/internal/api/mappings"
+	"seanime/internal/platforms/platform"
+	"seanime/internal/util/limiter"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+type (
+	// Importer adds the entries of an export to the account platform.
+	// Entries coming from MyAnimeList are matched to AniList using the anime-lists mappings, then AniZip, then AniList itself.
+	Importer struct {
+		logger         *zerolog.Logger
+		platform       platform.Platform
+		anilistLimiter *limiter.Limiter
+		anizipCache    *anizip.Cache
+
+		animeLists     *mappings.AnimeListResponse
+		animeListsOnce sync.Once
+	}
+
+	// ImportResult is the outcome of an import.
+	ImportResult struct {
+		Imported int      `json:"imported"`
+		Failed   int      `json:"failed"`
+		Errors   []string `json:"errors"`
+	}
+)
+
+func NewImporter(logger *zerolog.Logger, p platform.Platform) *Importer {
+	return &Importer{
+		logger:         logger,
+		platform:       p,
+		anilistLimiter: limiter.NewAnilistLimiter(),
+		anizipCache:    anizip.NewCache(),
+	}
+}
+
+// Import adds or updates the entries of the export.
+// Entries that cannot be matched to an AniList media are reported as failed.
+func (i *Importer) Import(ctx context.Context, export *Export) *ImportResult {
+	ret := &ImportResult{
+		Errors: make([]string, 0),
+	}
+
+	importEntries := func(mediaType MediaType, entries []*Entry) {
+		for _, e := range entries {
+			if ctx.Err() != nil {
+				return
+			}
+			if err := i.importEntry(ctx, mediaType, e); err != nil {
+				ret.Failed++
+				ret.Errors = append(ret.Errors, fmt.Sprintf("%s: %s", e.Title, err.Error()))
+				i.logger.Warn().Err(err).Str("title", e.Title).Msg("list export: Failed to import entry")
+				continue
+			}
+			ret.Imported++
+		}
+	}
+
+	importEntries(MediaTypeAnime, export.Anime)
+	importEntries(MediaTypeManga, export.Manga)
+
+	i.logger.Info().Int("imported", ret.Imported).Int("failed", ret.Failed).Msg("list export: Import finished")
+
+	return ret
+}
+
+func (i *Importer) importEntry(ctx context.Context, mediaType MediaType, e *Entry) error {
+	mediaId := e.AnilistId
+	if mediaId == 0 {
+		if e.MalId == 0 {
+			return errors.New("the entry has no ID")
+		}
+		var err error
+		mediaId, err = i.resolveAnilistId(ctx, mediaType, e.MalId)
+		if err != nil {
+			return err
+		}
+	}
+
+	status := e.Status
+	score := e.Score
+	progress := e.Progress
+
+	i.anilistLimiter.Wait()
+	if err := i.platform.UpdateEntry(ctx, mediaId, &status, &score, &progress, e.StartedAt, e.CompletedAt); err != nil {
+		return err
+	}
+
+	if e.Repeat > 0 {
+		i.anilistLimiter.Wait()
+		if err := i.platform.UpdateEntryRepeat(ctx, mediaId, e.Repeat); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolveAnilistId returns the AniList ID of a MyAnimeList media.
+func (i *Importer) resolveAnilistId(ctx context.Context, mediaType MediaType, malId int) (int, error) {
+	if mediaType == MediaTypeAnime {
+		i.animeListsOnce.Do(func() {
+			animeLists, err := mappings.GetAnimeLists()
+			if err != nil {
+				i.logger.Warn().Err(err).Msg("list export: Failed to get the anime mappings, falling back to AniZip")
+				return
+			}
+			i.animeLists = animeLists
+		})
+
+		if item, ok := i.animeLists.FindFromMalID(malId); ok {
+			return item.AnilistID, nil
+		}
+
+		if media, err := anizip.FetchAniZipMediaC("mal", malId, i.anizipCache); err == nil && media.Mappings != nil && media.Mappings.AnilistID != 0 {
+			return media.Mappings.AnilistID, nil
+		}
+
+		i.anilistLimiter.Wait()
+		media, err := i.platform.GetAnimeByMalID(ctx, malId)
+		if err != nil {
+			return 0, fmt.Errorf("could not find the anime on AniList: %w", err)
+		}
+		return media.GetID(), nil
+	}
+
+	// The mappings only cover anime
+	i.anilistLimiter.Wait()
+	data, err := anilist.CustomQuery(map[string]interface{}{
+		"query":     `query ($id: Int) { Media(idMal: $id, type: MANGA) { id } }`,
+		"variables": map[string]interface{}{"id": malId},
+	}, i.logger, "")
+	if err != nil {
+		return 0, fmt.Errorf("could not find the manga on AniList: %w", err)
+	}
+	root, _ := data.(map[string]interface{})
+	media, _ := root["Media"].(map[string]interface{})
+	id, ok := media["id"].(float64)
+	if !ok || id == 0 {
+		return 0, errors.New("could not find the manga on AniList")
+	}
+	return int(id), nil
+}
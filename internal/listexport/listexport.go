@@ -0,0 +1,187 @@
+package listexport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"seanime/internal/api/anilist"
+	"seanime/internal/platforms/platform"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/samber/lo"
+)
+
+const (
+	FormatJson   Format = "json"
+	FormatMalXml Format = "mal-xml"
+)
+
+const (
+	MediaTypeAnime MediaType = "anime"
+	MediaTypeManga MediaType = "manga"
+)
+
+const exportVersion = 1
+
+type (
+	Format    string
+	MediaType string
+
+	// Export is a portable copy of the anime and manga lists of an account.
+	Export struct {
+		Version    int       `json:"version"`
+		ExportedAt time.Time `json:"exportedAt"`
+		Anime      []*Entry  `json:"anime"`
+		Manga      []*Entry  `json:"manga"`
+	}
+
+	Entry struct {
+		// 0 if the entry comes from a MyAnimeList export
+		AnilistId int                     `json:"anilistId"`
+		MalId     int                     `json:"malId,omitempty"`
+		Title     string                  `json:"title"`
+		Status    anilist.MediaListStatus `json:"status"`
+		Progress  int                     `json:"progress"`
+		// Score out of 100, 0 if not scored
+		Score  int `json:"score"`
+		Repeat int `json:"repeat"`
+		// Number of episodes or chapters of the media, 0 if unknown
+		Total       int                     `json:"total,omitempty"`
+		StartedAt   *anilist.FuzzyDateInput `json:"startedAt,omitempty"`
+		CompletedAt *anilist.FuzzyDateInput `json:"completedAt,omitempty"`
+	}
+
+	// File is an exported list ready to be downloaded.
+	File struct {
+		Name    string `json:"name"`
+		Content string `json:"content"`
+		// Number of exported entries
+		Count int `json:"count"`
+		// Number of entries left out because the format cannot represent them (e.g. media without a MyAnimeList ID)
+		Skipped int `json:"skipped"`
+	}
+)
+
+// ExportFile exports the lists of the account platform.
+// MyAnimeList XML files only contain one type of media, JSON files contain both.
+func ExportFile(ctx context.Context, p platform.Platform, format Format, mediaType MediaType) (*File, error) {
+	animeCollection, err := p.GetAnimeCollection(ctx, false)
+	if err != nil {
+		return nil, fmt.Errorf("list export: Failed to get anime collection: %w", err)
+	}
+	mangaCollection, err := p.GetMangaCollection(ctx, false)
+	if err != nil {
+		return nil, fmt.Errorf("list export: Failed to get manga collection: %w", err)
+	}
+
+	export := NewExport(animeCollection, mangaCollection)
+	date := export.ExportedAt.Format(time.DateOnly)
+
+	switch format {
+	case FormatJson:
+		data, err := json.MarshalIndent(export, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return &File{
+			Name:    fmt.Sprintf("seanime-lists-%s.json", date),
+			Content: string(data),
+			Count:   len(export.Anime) + len(export.Manga),
+		}, nil
+	case FormatMalXml:
+		entries := lo.Ternary(mediaType == MediaTypeManga, export.Manga, export.Anime)
+		data, count, err := MarshalMalXml(entries, mediaType)
+		if err != nil {
+			return nil, err
+		}
+		return &File{
+			Name:    fmt.Sprintf("%slist-%s.xml", mediaType, date),
+			Content: string(data),
+			Count:   count,
+			Skipped: len(entries) - count,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("list export: Unknown format %q", format)
+}
+
+// Parse reads an exported file.
+func Parse(format Format, data []byte) (*Export, error) {
+	switch format {
+	case FormatJson:
+		var export Export
+		if err := json.Unmarshal(data, &export); err != nil {
+			return nil, fmt.Errorf("list export: Invalid JSON export: %w", err)
+		}
+		if export.Version > exportVersion {
+			return nil, errors.New("list export: The export was made by a newer version of Seanime")
+		}
+		return &export, nil
+	case FormatMalXml:
+		return UnmarshalMalXml(data)
+	}
+
+	return nil, fmt.Errorf("list export: Unknown format %q", format)
+}
+
+// NewExport converts the collections to the portable format.
+// The scores of the collections are expected to be in the POINT_100 format.
+func NewExport(animeCollection *anilist.AnimeCollection, mangaCollection *anilist.MangaCollection) *Export {
+	ret := &Export{
+		Version:    exportVersion,
+		ExportedAt: time.Now(),
+		Anime:      make([]*Entry, 0),
+		Manga:      make([]*Entry, 0),
+	}
+
+	for _, list := range animeCollection.GetMediaListCollection().GetLists() {
+		for _, e := range list.GetEntries() {
+			if e.GetMedia() == nil {
+				continue
+			}
+			ret.Anime = append(ret.Anime, &Entry{
+				AnilistId:   e.GetMedia().GetID(),
+				MalId:       lo.FromPtr(e.GetMedia().GetIDMal()),
+				Title:       e.GetMedia().GetRomajiTitleSafe(),
+				Status:      lo.FromPtrOr(e.GetStatus(), anilist.MediaListStatusPlanning),
+				Progress:    lo.FromPtr(e.GetProgress()),
+				Score:       int(lo.FromPtr(e.GetScore())),
+				Repeat:      lo.FromPtr(e.GetRepeat()),
+				Total:       lo.FromPtr(e.GetMedia().GetEpisodes()),
+				StartedAt:   newFuzzyDate(e.GetStartedAt().GetYear(), e.GetStartedAt().GetMonth(), e.GetStartedAt().GetDay()),
+				CompletedAt: newFuzzyDate(e.GetCompletedAt().GetYear(), e.GetCompletedAt().GetMonth(), e.GetCompletedAt().GetDay()),
+			})
+		}
+	}
+
+	for _, list := range mangaCollection.GetMediaListCollection().GetLists() {
+		for _, e := range list.GetEntries() {
+			if e.GetMedia() == nil {
+				continue
+			}
+			ret.Manga = append(ret.Manga, &Entry{
+				AnilistId:   e.GetMedia().GetID(),
+				MalId:       lo.FromPtr(e.GetMedia().GetIDMal()),
+				Title:       e.GetMedia().GetRomajiTitleSafe(),
+				Status:      lo.FromPtrOr(e.GetStatus(), anilist.MediaListStatusPlanning),
+				Progress:    lo.FromPtr(e.GetProgress()),
+				Score:       int(lo.FromPtr(e.GetScore())),
+				Repeat:      lo.FromPtr(e.GetRepeat()),
+				Total:       lo.FromPtr(e.GetMedia().GetChapters()),
+				StartedAt:   newFuzzyDate(e.GetStartedAt().GetYear(), e.GetStartedAt().GetMonth(), e.GetStartedAt().GetDay()),
+				CompletedAt: newFuzzyDate(e.GetCompletedAt().GetYear(), e.GetCompletedAt().GetMonth(), e.GetCompletedAt().GetDay()),
+			})
+		}
+	}
+
+	return ret
+}
+
+// newFuzzyDate returns nil if the date is empty.
+func newFuzzyDate(year *int, month *int, day *int) *anilist.FuzzyDateInput {
+	if lo.FromPtr(year) == 0 && lo.FromPtr(month) == 0 && lo.FromPtr(day) == 0 {
+		return nil
+	}
+	return &anilist.FuzzyDateInput{Year: year, Month: month, Day: day}
+}
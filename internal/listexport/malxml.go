@@ -0,0 +1,230 @@
+package listexport
+
+import (
+	"encoding/xml"
+	"fmt"
+	"seanime/internal/api/anilist"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// DEVNOTE: The format mirrors the export of MyAnimeList (https://myanimelist.net/panel.php?go=export).
+// MyAnimeList only imports one type of media per file, the type is given by myinfo.user_export_type.
+
+const (
+	malExportTypeAnime = 1
+	malExportTypeManga = 2
+)
+
+type (
+	malXml struct {
+		XMLName xml.Name       `xml:"myanimelist"`
+		MyInfo  malXmlInfo     `xml:"myinfo"`
+		Anime   []*malXmlAnime `xml:"anime"`
+		Manga   []*malXmlManga `xml:"manga"`
+	}
+
+	malXmlInfo struct {
+		UserExportType int `xml:"user_export_type"`
+	}
+
+	malXmlAnime struct {
+		SeriesAnimedbId   int         `xml:"series_animedb_id"`
+		SeriesTitle       malXmlCdata `xml:"series_title"`
+		SeriesEpisodes    int         `xml:"series_episodes"`
+		MyWatchedEpisodes int         `xml:"my_watched_episodes"`
+		MyStartDate       string      `xml:"my_start_date"`
+		MyFinishDate      string      `xml:"my_finish_date"`
+		MyScore           int         `xml:"my_score"`
+		MyStatus          string      `xml:"my_status"`
+		MyTimesWatched    int         `xml:"my_times_watched"`
+		MyRewatching      int         `xml:"my_rewatching"`
+		UpdateOnImport    int         `xml:"update_on_import"`
+	}
+
+	malXmlManga struct {
+		MangaMangadbId int         `xml:"manga_mangadb_id"`
+		MangaTitle     malXmlCdata `xml:"manga_title"`
+		MangaChapters  int         `xml:"manga_chapters"`
+		MyReadChapters int         `xml:"my_read_chapters"`
+		MyStartDate    string      `xml:"my_start_date"`
+		MyFinishDate   string      `xml:"my_finish_date"`
+		MyScore        int         `xml:"my_score"`
+		MyStatus       string      `xml:"my_status"`
+		MyTimesRead    int         `xml:"my_times_read"`
+		MyRereading    int         `xml:"my_rereading"`
+		UpdateOnImport int         `xml:"update_on_import"`
+	}
+
+	malXmlCdata struct {
+		Value string `xml:",cdata"`
+	}
+)
+
+// MarshalMalXml converts the entries to a MyAnimeList XML export.
+// Entries without a MyAnimeList ID are left out, the number of exported entries is returned.
+func MarshalMalXml(entries []*Entry, mediaType MediaType) ([]byte, int, error) {
+	doc := &malXml{}
+
+	for _, e := range entries {
+		if e.MalId == 0 {
+			continue
+		}
+		status, repeating := toMalXmlStatus(e.Status, mediaType)
+		if mediaType == MediaTypeManga {
+			doc.Manga = append(doc.Manga, &malXmlManga{
+				MangaMangadbId: e.MalId,
+				MangaTitle:     malXmlCdata{Value: e.Title},
+				MangaChapters:  e.Total,
+				MyReadChapters: e.Progress,
+				MyStartDate:    formatMalXmlDate(e.StartedAt),
+				MyFinishDate:   formatMalXmlDate(e.CompletedAt),
+				MyScore:        scoreToMal(e.Score),
+				MyStatus:       status,
+				MyTimesRead:    e.Repeat,
+				MyRereading:    lo.Ternary(repeating, 1, 0),
+				UpdateOnImport: 1,
+			})
+		} else {
+			doc.Anime = append(doc.Anime, &malXmlAnime{
+				SeriesAnimedbId:   e.MalId,
+				SeriesTitle:       malXmlCdata{Value: e.Title},
+				SeriesEpisodes:    e.Total,
+				MyWatchedEpisodes: e.Progress,
+				MyStartDate:       formatMalXmlDate(e.StartedAt),
+				MyFinishDate:      formatMalXmlDate(e.CompletedAt),
+				MyScore:           scoreToMal(e.Score),
+				MyStatus:          status,
+				MyTimesWatched:    e.Repeat,
+				MyRewatching:      lo.Ternary(repeating, 1, 0),
+				UpdateOnImport:    1,
+			})
+		}
+	}
+
+	doc.MyInfo.UserExportType = lo.Ternary(mediaType == MediaTypeManga, malExportTypeManga, malExportTypeAnime)
+
+	data, err := xml.MarshalIndent(doc, "", "\t")
+	if err != nil {
+		return nil, 0, fmt.Errorf("list export: Failed to write MyAnimeList export: %w", err)
+	}
+
+	return append([]byte(xml.Header), data...), len(doc.Anime) + len(doc.Manga), nil
+}
+
+// UnmarshalMalXml reads a MyAnimeList XML export.
+// The AniList IDs of the returned entries are not set.
+func UnmarshalMalXml(data []byte) (*Export, error) {
+	var doc malXml
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("list export: Invalid MyAnimeList export: %w", err)
+	}
+
+	ret := &Export{
+		Version: exportVersion,
+		Anime:   make([]*Entry, 0, len(doc.Anime)),
+		Manga:   make([]*Entry, 0, len(doc.Manga)),
+	}
+
+	for _, a := range doc.Anime {
+		if a.SeriesAnimedbId == 0 {
+			continue
+		}
+		ret.Anime = append(ret.Anime, &Entry{
+			MalId:       a.SeriesAnimedbId,
+			Title:       strings.TrimSpace(a.SeriesTitle.Value),
+			Status:      fromMalXmlStatus(a.MyStatus, a.MyRewatching == 1),
+			Progress:    a.MyWatchedEpisodes,
+			Score:       a.MyScore * 10,
+			Repeat:      a.MyTimesWatched,
+			Total:       a.SeriesEpisodes,
+			StartedAt:   parseMalXmlDate(a.MyStartDate),
+			CompletedAt: parseMalXmlDate(a.MyFinishDate),
+		})
+	}
+
+	for _, m := range doc.Manga {
+		if m.MangaMangadbId == 0 {
+			continue
+		}
+		ret.Manga = append(ret.Manga, &Entry{
+			MalId:       m.MangaMangadbId,
+			Title:       strings.TrimSpace(m.MangaTitle.Value),
+			Status:      fromMalXmlStatus(m.MyStatus, m.MyRereading == 1),
+			Progress:    m.MyReadChapters,
+			Score:       m.MyScore * 10,
+			Repeat:      m.MyTimesRead,
+			Total:       m.MangaChapters,
+			StartedAt:   parseMalXmlDate(m.MyStartDate),
+			CompletedAt: parseMalXmlDate(m.MyFinishDate),
+		})
+	}
+
+	return ret, nil
+}
+
+// toMalXmlStatus converts an AniList status, it returns true if the entry is being rewatched or reread.
+func toMalXmlStatus(status anilist.MediaListStatus, mediaType MediaType) (string, bool) {
+	switch status {
+	case anilist.MediaListStatusCurrent, anilist.MediaListStatusRepeating:
+		return lo.Ternary(mediaType == MediaTypeManga, "Reading", "Watching"), status == anilist.MediaListStatusRepeating
+	case anilist.MediaListStatusCompleted:
+		return "Completed", false
+	case anilist.MediaListStatusPaused:
+		return "On-Hold", false
+	case anilist.MediaListStatusDropped:
+		return "Dropped", false
+	default:
+		return lo.Ternary(mediaType == MediaTypeManga, "Plan to Read", "Plan to Watch"), false
+	}
+}
+
+// fromMalXmlStatus converts a MyAnimeList status.
+// Older exports use numeric statuses.
+func fromMalXmlStatus(status string, repeating bool) anilist.MediaListStatus {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "watching", "reading", "1":
+		return lo.Ternary(repeating, anilist.MediaListStatusRepeating, anilist.MediaListStatusCurrent)
+	case "completed", "2":
+		return lo.Ternary(repeating, anilist.MediaListStatusRepeating, anilist.MediaListStatusCompleted)
+	case "on-hold", "3":
+		return anilist.MediaListStatusPaused
+	case "dropped", "4":
+		return anilist.MediaListStatusDropped
+	default:
+		return anilist.MediaListStatusPlanning
+	}
+}
+
+// scoreToMal converts a score out of 100 to a score out of 10.
+func scoreToMal(score int) int {
+	return min(max((score+5)/10, 0), 10)
+}
+
+// formatMalXmlDate returns the date in the "YYYY-MM-DD" format, unknown parts are zeros.
+func formatMalXmlDate(date *anilist.FuzzyDateInput) string {
+	if date == nil {
+		return "0000-00-00"
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", lo.FromPtr(date.Year), lo.FromPtr(date.Month), lo.FromPtr(date.Day))
+}
+
+// parseMalXmlDate parses a "YYYY-MM-DD" date, it returns nil if the date is unknown.
+func parseMalXmlDate(value string) *anilist.FuzzyDateInput {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) != 3 {
+		return nil
+	}
+
+	toPtr := func(s string) *int {
+		n, err := strconv.Atoi(s)
+		if err != nil || n == 0 {
+			return nil
+		}
+		return &n
+	}
+
+	return newFuzzyDate(toPtr(parts[0]), toPtr(parts[1]), toPtr(parts[2]))
+}
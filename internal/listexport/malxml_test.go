@@ -0,0 +1,93 @@
+package listexport
+
+import (
+	"seanime/internal/api/anilist"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalMalXml(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8" ?>
+<myanimelist>
+	<myinfo>
+		<user_id>1</user_id>
+		<user_export_type>1</user_export_type>
+	</myinfo>
+	<anime>
+		<series_animedb_id>1</series_animedb_id>
+		<series_title><![CDATA[Cowboy Bebop]]></series_title>
+		<series_type>TV</series_type>
+		<series_episodes>26</series_episodes>
+		<my_watched_episodes>26</my_watched_episodes>
+		<my_start_date>2020-05-00</my_start_date>
+		<my_finish_date>0000-00-00</my_finish_date>
+		<my_score>9</my_score>
+		<my_status>Completed</my_status>
+		<my_times_watched>1</my_times_watched>
+		<update_on_import>1</update_on_import>
+	</anime>
+	<anime>
+		<series_animedb_id>5114</series_animedb_id>
+		<series_title><![CDATA[Fullmetal Alchemist: Brotherhood]]></series_title>
+		<my_watched_episodes>3</my_watched_episodes>
+		<my_score>0</my_score>
+		<my_status>3</my_status>
+	</anime>
+</myanimelist>`)
+
+	export, err := UnmarshalMalXml(data)
+	require.NoError(t, err)
+	require.Len(t, export.Anime, 2)
+	assert.Empty(t, export.Manga)
+
+	bebop := export.Anime[0]
+	assert.Equal(t, 1, bebop.MalId)
+	assert.Zero(t, bebop.AnilistId)
+	assert.Equal(t, "Cowboy Bebop", bebop.Title)
+	assert.Equal(t, anilist.MediaListStatusCompleted, bebop.Status)
+	assert.Equal(t, 26, bebop.Progress)
+	assert.Equal(t, 90, bebop.Score)
+	assert.Equal(t, 1, bebop.Repeat)
+	require.NotNil(t, bebop.StartedAt)
+	assert.Equal(t, 2020, lo.FromPtr(bebop.StartedAt.Year))
+	assert.Equal(t, 5, lo.FromPtr(bebop.StartedAt.Month))
+	assert.Nil(t, bebop.StartedAt.Day)
+	assert.Nil(t, bebop.CompletedAt)
+
+	// Numeric status of older exports
+	assert.Equal(t, anilist.MediaListStatusPaused, export.Anime[1].Status)
+
+	_, err = UnmarshalMalXml([]byte("not xml"))
+	assert.Error(t, err)
+}
+
+func TestMarshalMalXml(t *testing.T) {
+	entries := []*Entry{
+		{AnilistId: 101, MalId: 11, Title: "Rewatched", Status: anilist.MediaListStatusRepeating, Progress: 4, Score: 75, Repeat: 2},
+		// Not on MyAnimeList
+		{AnilistId: 102, Title: "AniList only", Status: anilist.MediaListStatusPlanning},
+		{AnilistId: 103, MalId: 13, Title: "Planned & <special>", Status: anilist.MediaListStatusPlanning},
+	}
+
+	data, count, err := MarshalMalXml(entries, MediaTypeManga)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Contains(t, string(data), "<user_export_type>2</user_export_type>")
+
+	export, err := UnmarshalMalXml(data)
+	require.NoError(t, err)
+	require.Len(t, export.Manga, 2)
+
+	assert.Equal(t, 11, export.Manga[0].MalId)
+	assert.Equal(t, anilist.MediaListStatusRepeating, export.Manga[0].Status)
+	assert.Equal(t, 4, export.Manga[0].Progress)
+	// Scores are rounded to MyAnimeList's 10 point scale
+	assert.Equal(t, 80, export.Manga[0].Score)
+	assert.Equal(t, 2, export.Manga[0].Repeat)
+
+	assert.Equal(t, "Planned & <special>", export.Manga[1].Title)
+	assert.Equal(t, anilist.MediaListStatusPlanning, export.Manga[1].Status)
+}
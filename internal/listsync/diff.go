@@ -0,0 +1,203 @@
+package listsync
+
+import (
+	"seanime/internal/api/anilist"
+	"seanime/internal/api/mal"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+const (
+	OriginAnilist = "anilist" // AniList entries overwrite MyAnimeList entries
+	OriginMal     = "mal"     // MyAnimeList entries overwrite AniList entries
+	OriginLatest  = ""        // The most recently changed entry wins
+)
+
+type (
+	// Diff is a change needed to bring an entry in sync.
+	Diff struct {
+		MediaType MediaType `json:"mediaType"`
+		// 0 if the entry is only on MyAnimeList, the AniList ID is looked up when the change is applied
+		AnilistId int    `json:"anilistId"`
+		MalId     int    `json:"malId"`
+		Title     string `json:"title"`
+		// List the change is applied to
+		Target Target `json:"target"`
+		// The entry is added to the target list
+		IsNew   bool   `json:"isNew"`
+		Anilist *Entry `json:"anilist,omitempty"`
+		Mal     *Entry `json:"mal,omitempty"`
+	}
+
+	// pair holds the entries of a media on both platforms, one of them can be nil.
+	pair struct {
+		mediaType    MediaType
+		anilistId    int
+		malId        int
+		title        string
+		anilist      *Entry
+		mal          *Entry
+		malUpdatedAt time.Time
+	}
+
+	pairKey struct {
+		mediaType MediaType
+		malId     int
+	}
+)
+
+// Source returns the entry that is copied to the target list.
+func (d *Diff) Source() *Entry {
+	if d.Target == TargetMal {
+		return d.Anilist
+	}
+	return d.Mal
+}
+
+// resolve returns the change needed to bring the pair in sync, nil if there is nothing to do.
+//   - snapshot is the state of the entry after the last synchronization, nil if it has never been synchronized.
+//   - anilistChangedAt is the time at which Seanime last updated the AniList entry, zero if unknown.
+//
+// Entries missing from one list are added to it unless they have been synchronized before,
+// in which case they have been removed from that list and are left alone.
+// When both entries changed since the last synchronization and the origin is OriginLatest,
+// the MyAnimeList entry only wins if it was updated after Seanime updated the AniList entry,
+// AniList being the main platform.
+func (p *pair) resolve(origin string, snapshot *Entry, anilistChangedAt time.Time) *Diff {
+	diff := &Diff{
+		MediaType: p.mediaType,
+		AnilistId: p.anilistId,
+		MalId:     p.malId,
+		Title:     p.title,
+		Anilist:   p.anilist,
+		Mal:       p.mal,
+	}
+
+	switch {
+	case p.anilist == nil && p.mal == nil:
+		return nil
+	case p.mal == nil:
+		if snapshot != nil {
+			return nil
+		}
+		diff.Target = TargetMal
+		diff.IsNew = true
+		return diff
+	case p.anilist == nil:
+		if snapshot != nil {
+			return nil
+		}
+		diff.Target = TargetAnilist
+		diff.IsNew = true
+		return diff
+	case p.anilist.Equals(p.mal):
+		return nil
+	}
+
+	switch origin {
+	case OriginAnilist:
+		diff.Target = TargetMal
+	case OriginMal:
+		diff.Target = TargetAnilist
+	default:
+		anilistChanged := !p.anilist.Equals(snapshot)
+		malChanged := !p.mal.Equals(snapshot)
+		switch {
+		case anilistChanged && !malChanged:
+			diff.Target = TargetMal
+		case malChanged && !anilistChanged:
+			diff.Target = TargetAnilist
+		case !anilistChangedAt.IsZero() && p.malUpdatedAt.After(anilistChangedAt):
+			diff.Target = TargetAnilist
+		default:
+			diff.Target = TargetMal
+		}
+	}
+
+	return diff
+}
+
+// buildAnimePairs matches the entries of both lists using the MyAnimeList ID of the AniList media.
+// AniList entries whose media has no MyAnimeList ID are ignored.
+func buildAnimePairs(collection *anilist.AnimeCollection, malEntries []*mal.AnimeListEntry) []*pair {
+	pairs := make(map[int]*pair)
+	var ret []*pair
+
+	for _, list := range collection.GetMediaListCollection().GetLists() {
+		for _, entry := range list.GetEntries() {
+			malId := lo.FromPtr(entry.GetMedia().GetIDMal())
+			if malId == 0 || pairs[malId] != nil {
+				continue
+			}
+			p := &pair{
+				mediaType: MediaTypeAnime,
+				anilistId: entry.GetMedia().GetID(),
+				malId:     malId,
+				title:     entry.GetMedia().GetPreferredTitle(),
+				anilist:   fromAnilist(entry.GetStatus(), entry.GetProgress(), entry.GetScore()),
+			}
+			pairs[malId] = p
+			ret = append(ret, p)
+		}
+	}
+
+	for _, entry := range malEntries {
+		p, ok := pairs[entry.Node.ID]
+		if !ok {
+			p = &pair{
+				mediaType: MediaTypeAnime,
+				malId:     entry.Node.ID,
+				title:     entry.Node.Title,
+			}
+			pairs[entry.Node.ID] = p
+			ret = append(ret, p)
+		}
+		p.mal = fromMalAnime(entry)
+		p.malUpdatedAt = parseMalTime(entry.ListStatus.UpdatedAt)
+	}
+
+	return ret
+}
+
+// buildMangaPairs matches the entries of both lists using the MyAnimeList ID of the AniList media.
+// AniList entries whose media has no MyAnimeList ID are ignored.
+func buildMangaPairs(collection *anilist.MangaCollection, malEntries []*mal.MangaListEntry) []*pair {
+	pairs := make(map[int]*pair)
+	var ret []*pair
+
+	for _, list := range collection.GetMediaListCollection().GetLists() {
+		for _, entry := range list.GetEntries() {
+			malId := lo.FromPtr(entry.GetMedia().GetIDMal())
+			if malId == 0 || pairs[malId] != nil {
+				continue
+			}
+			p := &pair{
+				mediaType: MediaTypeManga,
+				anilistId: entry.GetMedia().GetID(),
+				malId:     malId,
+				title:     entry.GetMedia().GetPreferredTitle(),
+				anilist:   fromAnilist(entry.GetStatus(), entry.GetProgress(), entry.GetScore()),
+			}
+			pairs[malId] = p
+			ret = append(ret, p)
+		}
+	}
+
+	for _, entry := range malEntries {
+		p, ok := pairs[entry.Node.ID]
+		if !ok {
+			p = &pair{
+				mediaType: MediaTypeManga,
+				malId:     entry.Node.ID,
+				title:     entry.Node.Title,
+			}
+			pairs[entry.Node.ID] = p
+			ret = append(ret, p)
+		}
+		p.mal = fromMalManga(entry)
+		p.malUpdatedAt = parseMalTime(entry.ListStatus.UpdatedAt)
+	}
+
+	return ret
+}
@@ -0,0 +1,176 @@
+package listsync
+
+import (
+	"seanime/internal/api/anilist"
+	"seanime/internal/api/mal"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPairResolve(t *testing.T) {
+	now := time.Now()
+
+	watching := &Entry{Status: anilist.MediaListStatusCurrent, Progress: 3}
+	watchingMore := &Entry{Status: anilist.MediaListStatusCurrent, Progress: 5}
+	completed := &Entry{Status: anilist.MediaListStatusCompleted, Progress: 12, Score: 8}
+
+	tests := []struct {
+		name             string
+		pair             *pair
+		origin           string
+		snapshot         *Entry
+		anilistChangedAt time.Time
+		expectedTarget   Target // empty if no change is expected
+		expectedNew      bool
+	}{
+		{
+			name:           "Only on AniList",
+			pair:           &pair{anilist: watching},
+			expectedTarget: TargetMal,
+			expectedNew:    true,
+		},
+		{
+			name:           "Only on MyAnimeList",
+			pair:           &pair{mal: watching},
+			expectedTarget: TargetAnilist,
+			expectedNew:    true,
+		},
+		{
+			name:     "Removed from MyAnimeList since the last synchronization",
+			pair:     &pair{anilist: watching},
+			snapshot: watching,
+		},
+		{
+			name: "Already in sync",
+			pair: &pair{anilist: watching, mal: &Entry{Status: anilist.MediaListStatusCurrent, Progress: 3}},
+		},
+		{
+			name:           "AniList origin",
+			pair:           &pair{anilist: watching, mal: watchingMore},
+			origin:         OriginAnilist,
+			snapshot:       watching,
+			expectedTarget: TargetMal,
+		},
+		{
+			name:           "MyAnimeList origin",
+			pair:           &pair{anilist: watchingMore, mal: watching},
+			origin:         OriginMal,
+			snapshot:       watching,
+			expectedTarget: TargetAnilist,
+		},
+		{
+			name:           "Only AniList changed",
+			pair:           &pair{anilist: watchingMore, mal: watching},
+			snapshot:       watching,
+			expectedTarget: TargetMal,
+		},
+		{
+			name:           "Only MyAnimeList changed",
+			pair:           &pair{anilist: watching, mal: watchingMore},
+			snapshot:       watching,
+			expectedTarget: TargetAnilist,
+		},
+		{
+			name:             "Both changed, MyAnimeList more recently",
+			pair:             &pair{anilist: watchingMore, mal: completed, malUpdatedAt: now},
+			snapshot:         watching,
+			anilistChangedAt: now.Add(-time.Hour),
+			expectedTarget:   TargetAnilist,
+		},
+		{
+			name:             "Both changed, AniList more recently",
+			pair:             &pair{anilist: watchingMore, mal: completed, malUpdatedAt: now.Add(-time.Hour)},
+			snapshot:         watching,
+			anilistChangedAt: now,
+			expectedTarget:   TargetMal,
+		},
+		{
+			name:           "Never synchronized, AniList change time unknown",
+			pair:           &pair{anilist: watchingMore, mal: completed, malUpdatedAt: now},
+			expectedTarget: TargetMal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := tt.pair.resolve(tt.origin, tt.snapshot, tt.anilistChangedAt)
+			if tt.expectedTarget == "" {
+				assert.Nil(t, diff)
+				return
+			}
+			require.NotNil(t, diff)
+			assert.Equal(t, tt.expectedTarget, diff.Target)
+			assert.Equal(t, tt.expectedNew, diff.IsNew)
+		})
+	}
+}
+
+func TestBuildAnimePairs(t *testing.T) {
+	collection := &anilist.AnimeCollection{
+		MediaListCollection: &anilist.AnimeCollection_MediaListCollection{
+			Lists: []*anilist.AnimeCollection_MediaListCollection_Lists{
+				{
+					Entries: []*anilist.AnimeCollection_MediaListCollection_Lists_Entries{
+						{
+							Status:   lo.ToPtr(anilist.MediaListStatusCurrent),
+							Progress: lo.ToPtr(4),
+							Score:    lo.ToPtr(75.0),
+							Media:    &anilist.BaseAnime{ID: 1, IDMal: lo.ToPtr(101)},
+						},
+						{
+							// No MyAnimeList ID, ignored
+							Status: lo.ToPtr(anilist.MediaListStatusPlanning),
+							Media:  &anilist.BaseAnime{ID: 2},
+						},
+					},
+				},
+			},
+		},
+	}
+	malEntries := []*mal.AnimeListEntry{{}, {}}
+	malEntries[0].Node.ID = 101
+	malEntries[0].ListStatus.Status = mal.MediaListStatusWatching
+	malEntries[0].ListStatus.NumEpisodesWatched = 4
+	malEntries[0].ListStatus.Score = 8
+	// Not on AniList
+	malEntries[1].Node.ID = 202
+	malEntries[1].ListStatus.Status = mal.MediaListStatusCompleted
+	malEntries[1].ListStatus.IsRewatching = true
+
+	pairs := buildAnimePairs(collection, malEntries)
+	require.Len(t, pairs, 2)
+
+	// AniList scores are converted to scores out of 10
+	assert.Equal(t, 1, pairs[0].anilistId)
+	assert.True(t, pairs[0].anilist.Equals(pairs[0].mal))
+
+	assert.Equal(t, 0, pairs[1].anilistId)
+	assert.Nil(t, pairs[1].anilist)
+	assert.Equal(t, anilist.MediaListStatusRepeating, pairs[1].mal.Status)
+}
+
+func TestToMalStatus(t *testing.T) {
+	status, repeating := toMalStatus(anilist.MediaListStatusRepeating, MediaTypeManga)
+	assert.Equal(t, mal.MediaListStatusReading, status)
+	assert.True(t, repeating)
+
+	status, repeating = toMalStatus(anilist.MediaListStatusPlanning, MediaTypeAnime)
+	assert.Equal(t, mal.MediaListStatusPlanToWatch, status)
+	assert.False(t, repeating)
+
+	for _, s := range []anilist.MediaListStatus{
+		anilist.MediaListStatusCurrent,
+		anilist.MediaListStatusCompleted,
+		anilist.MediaListStatusPaused,
+		anilist.MediaListStatusDropped,
+		anilist.MediaListStatusPlanning,
+		anilist.MediaListStatusRepeating,
+	} {
+		malStatus, repeating := toMalStatus(s, MediaTypeAnime)
+		assert.Equal(t, s, fromMalStatus(malStatus, repeating))
+	}
+}
@@ -0,0 +1,122 @@
+package listsync
+
+import (
+	"math"
+	"seanime/internal/api/anilist"
+	"seanime/internal/api/mal"
+	"seanime/internal/database/models"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+const (
+	MediaTypeAnime MediaType = "anime"
+	MediaTypeManga MediaType = "manga"
+)
+
+const (
+	TargetAnilist Target = "anilist"
+	TargetMal     Target = "mal"
+)
+
+type (
+	MediaType string
+	// Target is the list a change is applied to.
+	Target string
+
+	// Entry is a list entry in a form that both AniList and MyAnimeList can represent.
+	Entry struct {
+		Status   anilist.MediaListStatus `json:"status"`
+		Progress int                     `json:"progress"`
+		// Score out of 10, 0 if not scored. AniList scores are rounded since MyAnimeList only supports whole numbers.
+		Score int `json:"score"`
+	}
+)
+
+// Equals returns true if both entries would be represented the same way on both platforms.
+func (e *Entry) Equals(other *Entry) bool {
+	if e == nil || other == nil {
+		return e == other
+	}
+	return e.Status == other.Status && e.Progress == other.Progress && e.Score == other.Score
+}
+
+// fromAnilist converts an AniList entry, the score is expected to be in the POINT_100 format.
+func fromAnilist(status *anilist.MediaListStatus, progress *int, score *float64) *Entry {
+	return &Entry{
+		Status:   lo.FromPtrOr(status, anilist.MediaListStatusPlanning),
+		Progress: lo.FromPtr(progress),
+		Score:    int(math.Round(lo.FromPtr(score) / 10)),
+	}
+}
+
+func fromMalAnime(e *mal.AnimeListEntry) *Entry {
+	return &Entry{
+		Status:   fromMalStatus(e.ListStatus.Status, e.ListStatus.IsRewatching),
+		Progress: e.ListStatus.NumEpisodesWatched,
+		Score:    e.ListStatus.Score,
+	}
+}
+
+func fromMalManga(e *mal.MangaListEntry) *Entry {
+	return &Entry{
+		Status:   fromMalStatus(e.ListStatus.Status, e.ListStatus.IsRereading),
+		Progress: e.ListStatus.NumChaptersRead,
+		Score:    e.ListStatus.Score,
+	}
+}
+
+func fromModel(e *models.ListSyncEntry) *Entry {
+	return &Entry{
+		Status:   anilist.MediaListStatus(e.Status),
+		Progress: e.Progress,
+		Score:    e.Score,
+	}
+}
+
+// fromMalStatus converts a MyAnimeList status, rewatched and reread entries are repeating on AniList.
+func fromMalStatus(status mal.MediaListStatus, repeating bool) anilist.MediaListStatus {
+	if repeating {
+		return anilist.MediaListStatusRepeating
+	}
+	switch status {
+	case mal.MediaListStatusWatching, mal.MediaListStatusReading:
+		return anilist.MediaListStatusCurrent
+	case mal.MediaListStatusCompleted:
+		return anilist.MediaListStatusCompleted
+	case mal.MediaListStatusOnHold:
+		return anilist.MediaListStatusPaused
+	case mal.MediaListStatusDropped:
+		return anilist.MediaListStatusDropped
+	default:
+		return anilist.MediaListStatusPlanning
+	}
+}
+
+// toMalStatus converts an AniList status, it returns true if the entry is being rewatched or reread.
+func toMalStatus(status anilist.MediaListStatus, mediaType MediaType) (mal.MediaListStatus, bool) {
+	switch status {
+	case anilist.MediaListStatusCurrent:
+		return lo.Ternary(mediaType == MediaTypeManga, mal.MediaListStatusReading, mal.MediaListStatusWatching), false
+	case anilist.MediaListStatusRepeating:
+		return lo.Ternary(mediaType == MediaTypeManga, mal.MediaListStatusReading, mal.MediaListStatusWatching), true
+	case anilist.MediaListStatusCompleted:
+		return mal.MediaListStatusCompleted, false
+	case anilist.MediaListStatusPaused:
+		return mal.MediaListStatusOnHold, false
+	case anilist.MediaListStatusDropped:
+		return mal.MediaListStatusDropped, false
+	default:
+		return lo.Ternary(mediaType == MediaTypeManga, mal.MediaListStatusPlanToRead, mal.MediaListStatusPlanToWatch), false
+	}
+}
+
+// parseMalTime parses the update time of a MyAnimeList entry, zero if it cannot be parsed.
+func parseMalTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,472 @@
+package listsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"seanime/internal/api/anilist"
+	"seanime/internal/api/mal"
+	"seanime/internal/database/db"
+	"seanime/internal/database/models"
+	"seanime/internal/hook"
+	"seanime/internal/hook_resolver"
+	"seanime/internal/platforms/anilist_platform"
+	"seanime/internal/platforms/platform"
+	"seanime/internal/util"
+	"seanime/internal/util/limiter"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/samber/lo"
+)
+
+const (
+	// Number of changes applied between two pauses
+	batchSize = 10
+	// Pause between two batches, on top of the rate limiters
+	batchPause = 2 * time.Second
+	// A preview older than this is computed again before being applied
+	previewTTL = 5 * time.Minute
+	// Delay between the last progress update and the automatic synchronization
+	autoSyncDelay = 30 * time.Second
+)
+
+var (
+	ErrMalNotConnected     = errors.New("not logged in to MyAnimeList")
+	ErrAnilistNotConnected = errors.New("not logged in to AniList")
+	ErrSyncInProgress      = errors.New("a synchronization is already in progress")
+)
+
+type (
+	// Manager synchronizes the AniList and MyAnimeList lists.
+	//
+	// Entries are matched using the MyAnimeList ID of the AniList media.
+	// The state of each entry after a synchronization is saved so that later changes can be attributed to one side.
+	Manager struct {
+		logger *zerolog.Logger
+		db     *db.Database
+		// Dedicated AniList platform, the account platform of the app can be MyAnimeList
+		platform                   platform.Platform
+		refreshAnimeCollectionFunc func()
+		refreshMangaCollectionFunc func()
+		anilistLimiter             *limiter.Limiter
+		malLimiter                 *limiter.Limiter
+
+		mu       sync.Mutex
+		settings *models.ListSyncSettings
+		preview  *Preview
+		// Time at which Seanime last updated an AniList entry, by media ID
+		anilistChanges map[int]time.Time
+		// Media being updated by the synchronization, their hook events are ignored
+		updating      map[int]struct{}
+		autoSyncTimer *time.Timer
+
+		syncMu sync.Mutex
+	}
+
+	NewManagerOptions struct {
+		Logger                     *zerolog.Logger
+		Database                   *db.Database
+		AnilistClient              anilist.AnilistClient
+		RefreshAnimeCollectionFunc func()
+		RefreshMangaCollectionFunc func()
+	}
+
+	// Preview holds the changes needed to bring both lists in sync.
+	Preview struct {
+		Diffs []*Diff `json:"diffs"`
+		// Number of entries that are already in sync
+		InSyncCount int       `json:"inSyncCount"`
+		CreatedAt   time.Time `json:"createdAt"`
+		// Entries that are already in sync, their state is saved when the preview is applied
+		inSync []*pair
+	}
+
+	// Result is the outcome of a synchronization.
+	Result struct {
+		Applied int      `json:"applied"`
+		Failed  int      `json:"failed"`
+		Errors  []string `json:"errors"`
+	}
+)
+
+func NewManager(opts *NewManagerOptions) *Manager {
+	m := &Manager{
+		logger:                     opts.Logger,
+		db:                         opts.Database,
+		platform:                   anilist_platform.NewAnilistPlatform(opts.AnilistClient, opts.Logger),
+		refreshAnimeCollectionFunc: opts.RefreshAnimeCollectionFunc,
+		refreshMangaCollectionFunc: opts.RefreshMangaCollectionFunc,
+		anilistLimiter:             limiter.NewAnilistLimiter(),
+		malLimiter:                 limiter.NewLimiter(time.Second, 3),
+		settings:                   &models.ListSyncSettings{},
+		anilistChanges:             make(map[int]time.Time),
+		updating:                   make(map[int]struct{}),
+	}
+
+	m.bindHooks()
+
+	return m
+}
+
+// SetAnilistClient should be called when the AniList token changes.
+func (m *Manager) SetAnilistClient(client anilist.AnilistClient) {
+	m.platform.SetAnilistClient(client)
+}
+
+// SetUsername sets the AniList username used to fetch the collections.
+func (m *Manager) SetUsername(username string) {
+	m.platform.SetUsername(username)
+}
+
+func (m *Manager) SetSettings(settings *models.ListSyncSettings) {
+	if settings == nil {
+		settings = &models.ListSyncSettings{}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.settings = settings
+	m.preview = nil
+	if !settings.Automatic && m.autoSyncTimer != nil {
+		m.autoSyncTimer.Stop()
+		m.autoSyncTimer = nil
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// Preview computes the changes needed to bring both lists in sync without applying them.
+func (m *Manager) Preview(ctx context.Context) (*Preview, error) {
+	malWrapper, err := m.getMalWrapper()
+	if err != nil {
+		return nil, err
+	}
+	if !m.platform.GetAnilistClient().IsAuthenticated() {
+		return nil, ErrAnilistNotConnected
+	}
+
+	animeCollection, err := m.platform.GetAnimeCollection(ctx, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get AniList anime collection: %w", err)
+	}
+	mangaCollection, err := m.platform.GetMangaCollection(ctx, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get AniList manga collection: %w", err)
+	}
+	malAnime, err := malWrapper.GetAnimeCollection()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MyAnimeList anime list: %w", err)
+	}
+	malManga, err := malWrapper.GetMangaCollection()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MyAnimeList manga list: %w", err)
+	}
+
+	snapshots, err := m.getSnapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := append(buildAnimePairs(animeCollection, malAnime), buildMangaPairs(mangaCollection, malManga)...)
+
+	m.mu.Lock()
+	origin := m.settings.Origin
+	anilistChanges := maps.Clone(m.anilistChanges)
+	m.mu.Unlock()
+
+	ret := &Preview{
+		Diffs:     make([]*Diff, 0),
+		CreatedAt: time.Now(),
+	}
+	for _, p := range pairs {
+		diff := p.resolve(origin, snapshots[pairKey{p.mediaType, p.malId}], anilistChanges[p.anilistId])
+		if diff != nil {
+			ret.Diffs = append(ret.Diffs, diff)
+		} else if p.anilist != nil && p.mal != nil {
+			ret.inSync = append(ret.inSync, p)
+		}
+	}
+	ret.InSyncCount = len(ret.inSync)
+
+	m.logger.Debug().Int("changes", len(ret.Diffs)).Int("inSync", ret.InSyncCount).Msg("listsync: Computed preview")
+
+	m.mu.Lock()
+	m.preview = ret
+	m.mu.Unlock()
+
+	return ret, nil
+}
+
+// Apply applies the last preview, or a new one if it is outdated.
+// Changes are applied in batches, failed changes do not stop the synchronization.
+func (m *Manager) Apply(ctx context.Context) (*Result, error) {
+	if !m.syncMu.TryLock() {
+		return nil, ErrSyncInProgress
+	}
+	defer m.syncMu.Unlock()
+
+	m.mu.Lock()
+	preview := m.preview
+	m.preview = nil
+	m.mu.Unlock()
+
+	if preview == nil || time.Since(preview.CreatedAt) > previewTTL {
+		var err error
+		preview, err = m.Preview(ctx)
+		if err != nil {
+			return nil, err
+		}
+		m.mu.Lock()
+		m.preview = nil
+		m.mu.Unlock()
+	}
+
+	malWrapper, err := m.getMalWrapper()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &Result{Errors: make([]string, 0)}
+	snapshots := lo.Map(preview.inSync, func(p *pair, _ int) *models.ListSyncEntry {
+		return toModel(p.mediaType, p.anilistId, p.malId, p.anilist)
+	})
+	anilistUpdated := make(map[MediaType]bool)
+
+	m.logger.Info().Int("changes", len(preview.Diffs)).Msg("listsync: Applying changes")
+
+batches:
+	for i, batch := range lo.Chunk(preview.Diffs, batchSize) {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				// Keep the state of the changes that have been applied
+				ret.Errors = append(ret.Errors, ctx.Err().Error())
+				break batches
+			case <-time.After(batchPause):
+			}
+		}
+
+		for _, diff := range batch {
+			if err := m.applyDiff(ctx, malWrapper, diff); err != nil {
+				m.logger.Warn().Err(err).Int("malId", diff.MalId).Str("target", string(diff.Target)).Msg("listsync: Failed to apply change")
+				ret.Failed++
+				ret.Errors = append(ret.Errors, fmt.Sprintf("%s: %s", diff.Title, err.Error()))
+				continue
+			}
+			ret.Applied++
+			snapshots = append(snapshots, toModel(diff.MediaType, diff.AnilistId, diff.MalId, diff.Source()))
+			if diff.Target == TargetAnilist {
+				anilistUpdated[diff.MediaType] = true
+			}
+		}
+	}
+
+	if err := m.db.UpsertListSyncEntries(snapshots); err != nil {
+		return nil, fmt.Errorf("failed to save synchronization state: %w", err)
+	}
+
+	m.mu.Lock()
+	for _, s := range snapshots {
+		delete(m.anilistChanges, s.AnilistID)
+	}
+	m.mu.Unlock()
+
+	if anilistUpdated[MediaTypeAnime] && m.refreshAnimeCollectionFunc != nil {
+		m.refreshAnimeCollectionFunc()
+	}
+	if anilistUpdated[MediaTypeManga] && m.refreshMangaCollectionFunc != nil {
+		m.refreshMangaCollectionFunc()
+	}
+
+	m.logger.Info().Int("applied", ret.Applied).Int("failed", ret.Failed).Msg("listsync: Synchronization complete")
+
+	return ret, nil
+}
+
+func (m *Manager) applyDiff(ctx context.Context, malWrapper *mal.Wrapper, diff *Diff) error {
+	source := diff.Source()
+	if source == nil {
+		return errors.New("nothing to apply")
+	}
+
+	if diff.Target == TargetMal {
+		m.malLimiter.Wait()
+		status, repeating := toMalStatus(source.Status, diff.MediaType)
+		if diff.MediaType == MediaTypeManga {
+			return malWrapper.UpdateMangaListStatus(&mal.MangaListStatusParams{
+				Status:          &status,
+				IsRereading:     &repeating,
+				NumChaptersRead: &source.Progress,
+				Score:           &source.Score,
+			}, diff.MalId)
+		}
+		return malWrapper.UpdateAnimeListStatus(&mal.AnimeListStatusParams{
+			Status:             &status,
+			IsRewatching:       &repeating,
+			NumEpisodesWatched: &source.Progress,
+			Score:              &source.Score,
+		}, diff.MalId)
+	}
+
+	if diff.AnilistId == 0 {
+		anilistId, err := m.findAnilistId(ctx, diff.MediaType, diff.MalId)
+		if err != nil {
+			return err
+		}
+		diff.AnilistId = anilistId
+	}
+
+	m.mu.Lock()
+	m.updating[diff.AnilistId] = struct{}{}
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		delete(m.updating, diff.AnilistId)
+		m.mu.Unlock()
+	}()
+
+	m.anilistLimiter.Wait()
+	// AniList raw scores are out of 100
+	scoreRaw := source.Score * 10
+	return m.platform.UpdateEntry(ctx, diff.AnilistId, &source.Status, &scoreRaw, &source.Progress, nil, nil)
+}
+
+// findAnilistId returns the AniList ID of a media only present on MyAnimeList.
+func (m *Manager) findAnilistId(ctx context.Context, mediaType MediaType, malId int) (int, error) {
+	m.anilistLimiter.Wait()
+
+	if mediaType == MediaTypeAnime {
+		media, err := m.platform.GetAnimeByMalID(ctx, malId)
+		if err != nil {
+			return 0, fmt.Errorf("could not find the anime on AniList: %w", err)
+		}
+		return media.GetID(), nil
+	}
+
+	// The platform cannot look up manga by MyAnimeList ID
+	data, err := anilist.CustomQuery(map[string]interface{}{
+		"query":     `query ($id: Int) { Media(idMal: $id, type: MANGA) { id } }`,
+		"variables": map[string]interface{}{"id": malId},
+	}, m.logger, m.db.GetAnilistToken())
+	if err != nil {
+		return 0, fmt.Errorf("could not find the manga on AniList: %w", err)
+	}
+	media, _ := data.(map[string]interface{})["Media"].(map[string]interface{})
+	id, ok := media["id"].(float64)
+	if !ok || id == 0 {
+		return 0, errors.New("could not find the manga on AniList")
+	}
+	return int(id), nil
+}
+
+func (m *Manager) getMalWrapper() (*mal.Wrapper, error) {
+	malInfo, err := m.db.GetMalInfo()
+	if err != nil || malInfo == nil || malInfo.AccessToken == "" {
+		return nil, ErrMalNotConnected
+	}
+	// Refresh the token if needed
+	malInfo, err = mal.VerifyMALAuth(malInfo, m.db, m.logger)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalNotConnected, err)
+	}
+	return mal.NewWrapper(malInfo.AccessToken, m.logger), nil
+}
+
+// isMalAccountPlatform returns true if the list updates of the app go to MyAnimeList instead of AniList.
+func (m *Manager) isMalAccountPlatform() bool {
+	malInfo, err := m.db.GetMalInfo()
+	return err == nil && malInfo.AccessToken != "" && malInfo.IsAccountPlatform
+}
+
+func (m *Manager) getSnapshots() (map[pairKey]*Entry, error) {
+	entries, err := m.db.GetListSyncEntries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get synchronization state: %w", err)
+	}
+	ret := make(map[pairKey]*Entry, len(entries))
+	for _, e := range entries {
+		ret[pairKey{MediaType(e.MediaType), e.MalID}] = fromModel(e)
+	}
+	return ret, nil
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// bindHooks records the AniList entries updated by Seanime and schedules the automatic synchronization.
+// The MyAnimeList platform triggers the same events, they are ignored since the change was not made on AniList.
+func (m *Manager) bindHooks() {
+	hook.GlobalHookManager.OnPostUpdateEntry().BindFunc(func(e hook_resolver.Resolver) error {
+		if event, ok := e.(*anilist_platform.PostUpdateEntryEvent); ok {
+			m.onAnilistEntryUpdated(event.MediaID)
+		}
+		return e.Next()
+	})
+	hook.GlobalHookManager.OnPostUpdateEntryProgress().BindFunc(func(e hook_resolver.Resolver) error {
+		if event, ok := e.(*anilist_platform.PostUpdateEntryProgressEvent); ok {
+			m.onAnilistEntryUpdated(event.MediaID)
+		}
+		return e.Next()
+	})
+	hook.GlobalHookManager.OnPostUpdateEntryRepeat().BindFunc(func(e hook_resolver.Resolver) error {
+		if event, ok := e.(*anilist_platform.PostUpdateEntryRepeatEvent); ok {
+			m.onAnilistEntryUpdated(event.MediaID)
+		}
+		return e.Next()
+	})
+}
+
+func (m *Manager) onAnilistEntryUpdated(mediaId *int) {
+	if mediaId == nil || m.isMalAccountPlatform() {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Ignore the updates made by the synchronization
+	if _, ok := m.updating[*mediaId]; ok {
+		return
+	}
+
+	m.anilistChanges[*mediaId] = time.Now()
+	m.preview = nil
+
+	if !m.settings.Automatic {
+		return
+	}
+
+	// Wait for the user to stop updating their list before synchronizing
+	if m.autoSyncTimer != nil {
+		m.autoSyncTimer.Stop()
+	}
+	m.autoSyncTimer = time.AfterFunc(autoSyncDelay, m.autoSync)
+}
+
+func (m *Manager) autoSync() {
+	defer util.HandlePanicInModuleThen("listsync/autoSync", func() {})
+
+	m.mu.Lock()
+	m.autoSyncTimer = nil
+	m.preview = nil
+	m.mu.Unlock()
+
+	m.logger.Debug().Msg("listsync: Running automatic synchronization")
+
+	_, err := m.Apply(context.Background())
+	if err != nil {
+		m.logger.Warn().Err(err).Msg("listsync: Automatic synchronization failed")
+	}
+}
+
+func toModel(mediaType MediaType, anilistId int, malId int, entry *Entry) *models.ListSyncEntry {
+	return &models.ListSyncEntry{
+		MediaType: string(mediaType),
+		MalID:     malId,
+		AnilistID: anilistId,
+		Status:    string(entry.Status),
+		Progress:  entry.Progress,
+		Score:     entry.Score,
+	}
+}
@@ -69,6 +69,7 @@ func migrateTables(db *gorm.DB) error {
 		&AnimeSnapshot{},
 		&MangaSnapshot{},
 		&TrackedMedia{},
+		&OfflineEpisode{},
 	)
 	if err != nil {
 		return err
@@ -65,6 +65,28 @@ func (ldb *Database) RemoveTrackedMedia(mediaId int, kind string) error {
 	return ldb.gormdb.Where("media_id = ? AND type = ?", mediaId, kind).Delete(&TrackedMedia{}).Error
 }
 
+//----------------------------------------------------------------------------------------------------------------------------------------------------
+
+func (ldb *Database) SaveOfflineEpisode(oe *OfflineEpisode) error {
+	return ldb.gormdb.Save(oe).Error
+}
+
+func (ldb *Database) GetOfflineEpisodes() ([]*OfflineEpisode, bool) {
+	var oe []*OfflineEpisode
+	err := ldb.gormdb.Order("media_id, episode_number").Find(&oe).Error
+	return oe, err == nil
+}
+
+func (ldb *Database) GetOfflineEpisode(mediaId int, episodeNumber int) (*OfflineEpisode, bool) {
+	var oe OfflineEpisode
+	err := ldb.gormdb.Where("media_id = ? AND episode_number = ?", mediaId, episodeNumber).First(&oe).Error
+	return &oe, err == nil
+}
+
+func (ldb *Database) DeleteOfflineEpisode(id uint) error {
+	return ldb.gormdb.Delete(&OfflineEpisode{}, id).Error
+}
+
 //----------------------------------------------------------------------------------------------------------------------------------------------------
 //----------------------------------------------------------------------------------------------------------------------------------------------------
 
@@ -181,13 +203,13 @@ func (ldb *Database) _saveLocalCollection(collectionType string, value interface
 // Simulated collections
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
-func (ldb *Database) _getSimulatedCollection(collectionType string) (*SimulatedCollection, bool) {
+func (ldb *Database) _getSimulatedCollection(profileId uint, collectionType string) (*SimulatedCollection, bool) {
 	var lc SimulatedCollection
-	err := ldb.gormdb.Where("type = ?", collectionType).First(&lc).Error
+	err := ldb.gormdb.Where("profile_id = ? AND type = ?", profileId, collectionType).First(&lc).Error
 	return &lc, err == nil
 }
 
-func (ldb *Database) _saveSimulatedCollection(collectionType string, value interface{}) error {
+func (ldb *Database) _saveSimulatedCollection(profileId uint, collectionType string, value interface{}) error {
 
 	marshalledValue, err := json.Marshal(value)
 	if err != nil {
@@ -195,30 +217,31 @@ func (ldb *Database) _saveSimulatedCollection(collectionType string, value inter
 	}
 
 	// Check if collection already exists
-	lc, ok := ldb._getSimulatedCollection(collectionType)
+	lc, ok := ldb._getSimulatedCollection(profileId, collectionType)
 	if ok {
 		lc.Value = marshalledValue
 		return ldb.gormdb.Save(&lc).Error
 	}
 
 	lcN := SimulatedCollection{
-		Type:  collectionType,
-		Value: marshalledValue,
+		ProfileID: profileId,
+		Type:      collectionType,
+		Value:     marshalledValue,
 	}
 
 	return ldb.gormdb.Save(&lcN).Error
 }
 
-func (ldb *Database) SaveSimulatedAnimeCollection(ac *anilist.AnimeCollection) error {
-	return ldb._saveSimulatedCollection(AnimeType, ac)
+func (ldb *Database) SaveSimulatedAnimeCollection(profileId uint, ac *anilist.AnimeCollection) error {
+	return ldb._saveSimulatedCollection(profileId, AnimeType, ac)
 }
 
-func (ldb *Database) SaveSimulatedMangaCollection(mc *anilist.MangaCollection) error {
-	return ldb._saveSimulatedCollection(MangaType, mc)
+func (ldb *Database) SaveSimulatedMangaCollection(profileId uint, mc *anilist.MangaCollection) error {
+	return ldb._saveSimulatedCollection(profileId, MangaType, mc)
 }
 
-func (ldb *Database) GetSimulatedAnimeCollection() (*anilist.AnimeCollection, bool) {
-	lc, ok := ldb._getSimulatedCollection(AnimeType)
+func (ldb *Database) GetSimulatedAnimeCollection(profileId uint) (*anilist.AnimeCollection, bool) {
+	lc, ok := ldb._getSimulatedCollection(profileId, AnimeType)
 	if !ok {
 		return nil, false
 	}
@@ -229,8 +252,8 @@ func (ldb *Database) GetSimulatedAnimeCollection() (*anilist.AnimeCollection, bo
 	return &ac, err == nil
 }
 
-func (ldb *Database) GetSimulatedMangaCollection() (*anilist.MangaCollection, bool) {
-	lc, ok := ldb._getSimulatedCollection(MangaType)
+func (ldb *Database) GetSimulatedMangaCollection(profileId uint) (*anilist.MangaCollection, bool) {
+	lc, ok := ldb._getSimulatedCollection(profileId, MangaType)
 	if !ok {
 		return nil, false
 	}
@@ -240,3 +263,7 @@ func (ldb *Database) GetSimulatedMangaCollection() (*anilist.MangaCollection, bo
 
 	return &mc, err == nil
 }
+
+func (ldb *Database) DeleteSimulatedCollections(profileId uint) error {
+	return ldb.gormdb.Where("profile_id = ?", profileId).Delete(&SimulatedCollection{}).Error
+}
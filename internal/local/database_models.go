@@ -22,6 +22,31 @@ type Settings struct {
 	BaseModel
 	// Flag to determine if there are local changes that need to be synced with AniList.
 	Updated bool `gorm:"column:updated" json:"updated"`
+	// Pre-download of upcoming episodes of tracked anime
+	OfflineDownload *OfflineDownloadSettings `gorm:"embedded;embeddedPrefix:offline_download_" json:"offlineDownload"`
+	// Sync profile used when synchronizing tracked media
+	Sync *SyncSettings `gorm:"embedded;embeddedPrefix:sync_" json:"sync"`
+}
+
+type SyncSettings struct {
+	// Maximum size of the local storage in MiB, 0 for no limit
+	StorageBudget int `gorm:"column:storage_budget" json:"storageBudget"`
+	// "cover", "banner" or "all"
+	ImageQuality string `gorm:"column:image_quality" json:"imageQuality"`
+}
+
+type OfflineDownloadSettings struct {
+	Enabled bool `gorm:"column:enabled" json:"enabled"`
+	// "torrent" or "debrid"
+	Source string `gorm:"column:source" json:"source"`
+	// Number of upcoming episodes to download for each tracked anime
+	EpisodeCount int `gorm:"column:episode_count" json:"episodeCount"`
+	// Maximum size of the offline folder in GiB
+	MaxSize int `gorm:"column:max_size" json:"maxSize"`
+	// Preferred resolution, e.g. "1080p", empty for any
+	Resolution string `gorm:"column:resolution" json:"resolution"`
+	// Folder in which the episodes are downloaded, defaults to a folder in the local data directory
+	Dir string `gorm:"column:dir" json:"dir"`
 }
 
 // +---------------------+
@@ -41,6 +66,8 @@ type TrackedMedia struct {
 	BaseModel
 	MediaId int    `gorm:"column:media_id" json:"mediaId"`
 	Type    string `gorm:"column:type" json:"type"` // "anime" or "manga"
+	// Media with a higher priority are synchronized first when the storage budget is limited
+	Priority int `gorm:"column:priority" json:"priority"`
 }
 
 type AnimeSnapshot struct {
@@ -66,15 +93,36 @@ type MangaSnapshot struct {
 	ReferenceKey string `gorm:"column:reference_key" json:"referenceKey"`
 }
 
+// OfflineEpisode is an upcoming episode of a tracked anime downloaded to the offline folder.
+// Completed episodes are added to the local files so that they appear in the offline collection.
+type OfflineEpisode struct {
+	BaseModel
+	MediaId       int    `gorm:"column:media_id" json:"mediaId"`
+	EpisodeNumber int    `gorm:"column:episode_number" json:"episodeNumber"`
+	Source        string `gorm:"column:source" json:"source"` // "torrent" or "debrid"
+	Status        string `gorm:"column:status" json:"status"` // "downloading" or "complete"
+	TorrentName   string `gorm:"column:torrent_name" json:"torrentName"`
+	InfoHash      string `gorm:"column:info_hash" json:"infoHash"`
+	// ID of the torrent on the debrid service
+	DebridItemId string `gorm:"column:debrid_item_id" json:"debridItemId"`
+	// Folder of the episode in the offline folder
+	Dir string `gorm:"column:dir" json:"dir"`
+	// Video file, set once the download is complete
+	Path string `gorm:"column:path" json:"path"`
+	Size int64  `gorm:"column:size" json:"size"`
+}
+
 // +---------------------+
 // |      Simulated      |
 // +---------------------+
 
 // SimulatedCollection is used for users without an account.
+// Each profile has its own simulated collections, collections created before profiles existed belong to the main profile.
 type SimulatedCollection struct {
 	BaseModel
-	Type  string `gorm:"column:type" json:"type"`   // "anime" or "manga"
-	Value []byte `gorm:"column:value" json:"value"` // Marshalled struct
+	ProfileID uint   `gorm:"column:profile_id;index;default:1" json:"profileId"`
+	Type      string `gorm:"column:type" json:"type"`   // "anime" or "manga"
+	Value     []byte `gorm:"column:value" json:"value"` // Marshalled struct
 }
 
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
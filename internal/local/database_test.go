@@ -0,0 +1,52 @@
+package local
+
+import (
+	"seanime/internal/api/anilist"
+	"seanime/internal/database/models"
+	"seanime/internal/util"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalCollections(t *testing.T) {
+	t.Setenv("TEST_ENV", "true")
+
+	ldb, err := newLocalSyncDatabase(t.TempDir(), "local", util.NewLogger())
+	require.NoError(t, err)
+
+	_, ok := ldb.GetLocalAnimeCollection()
+	assert.False(t, ok)
+
+	// Saving twice updates the same row
+	require.NoError(t, ldb.SaveAnimeCollection(&anilist.AnimeCollection{}))
+	require.NoError(t, ldb.SaveAnimeCollection(&anilist.AnimeCollection{}))
+	require.NoError(t, ldb.SaveMangaCollection(&anilist.MangaCollection{}))
+
+	_, ok = ldb.GetLocalAnimeCollection()
+	assert.True(t, ok)
+	_, ok = ldb.GetLocalMangaCollection()
+	assert.True(t, ok)
+
+	var count int64
+	require.NoError(t, ldb.gormdb.Model(&LocalCollection{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+
+	// Simulated collections are stored apart from the local collections
+	_, ok = ldb.GetSimulatedAnimeCollection(models.DefaultProfileID)
+	assert.False(t, ok)
+	require.NoError(t, ldb.SaveSimulatedAnimeCollection(models.DefaultProfileID, &anilist.AnimeCollection{}))
+	_, ok = ldb.GetSimulatedAnimeCollection(models.DefaultProfileID)
+	assert.True(t, ok)
+
+	// Each profile has its own simulated collections
+	_, ok = ldb.GetSimulatedAnimeCollection(2)
+	assert.False(t, ok)
+	require.NoError(t, ldb.SaveSimulatedAnimeCollection(2, &anilist.AnimeCollection{}))
+	require.NoError(t, ldb.DeleteSimulatedCollections(2))
+	_, ok = ldb.GetSimulatedAnimeCollection(2)
+	assert.False(t, ok)
+	_, ok = ldb.GetSimulatedAnimeCollection(models.DefaultProfileID)
+	assert.True(t, ok)
+}
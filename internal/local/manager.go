@@ -9,6 +9,7 @@ import (
 	"seanime/internal/api/metadata"
 	"seanime/internal/database/db"
 	"seanime/internal/database/db_bridge"
+	"seanime/internal/database/models"
 	"seanime/internal/events"
 	"seanime/internal/library/anime"
 	"seanime/internal/manga"
@@ -84,6 +85,31 @@ type Manager interface {
 	SynchronizeSimulatedCollectionToAnilist() error
 	// SynchronizeAnilistToSimulatedCollection synchronizes the user's AniList account to the simulated anime and manga collections.
 	SynchronizeAnilistToSimulatedCollection() error
+	// GetProfileManager returns a Manager whose simulated collections are the ones of the profile.
+	// Everything else is shared with this Manager.
+	GetProfileManager(profileId uint) Manager
+	// DeleteSimulatedCollections removes the simulated collections of a profile.
+	DeleteSimulatedCollections(profileId uint) error
+	// GetOfflineDownloadSettings returns the settings of the pre-download of upcoming episodes of tracked anime.
+	GetOfflineDownloadSettings() *OfflineDownloadSettings
+	// SaveOfflineDownloadSettings updates the settings of the pre-download of upcoming episodes of tracked anime.
+	SaveOfflineDownloadSettings(settings *OfflineDownloadSettings) error
+	// GetOfflineEpisodes returns the pre-downloaded episodes stored in the local database.
+	GetOfflineEpisodes() []*OfflineEpisode
+	// SaveOfflineEpisode adds or updates a pre-downloaded episode in the local database.
+	SaveOfflineEpisode(oe *OfflineEpisode) error
+	// DeleteOfflineEpisode removes a pre-downloaded episode from the local database.
+	DeleteOfflineEpisode(id uint) error
+	// GetOfflineEpisodeLocalFiles returns the local files of the completely downloaded episodes.
+	GetOfflineEpisodeLocalFiles() []*anime.LocalFile
+	// GetSyncSettings returns the sync profile used when synchronizing tracked media.
+	GetSyncSettings() *SyncSettings
+	// SaveSyncSettings updates the sync profile used when synchronizing tracked media.
+	SaveSyncSettings(settings *SyncSettings) error
+	// SetTrackedMediaPriority changes the order in which a tracked media is synchronized.
+	SetTrackedMediaPriority(mId int, kind string, priority int) error
+	// EstimateSynchronizeLocal returns the space required by the next synchronization without running it.
+	EstimateSynchronizeLocal() (*SyncEstimate, error)
 
 	SetOffline(bool)
 }
@@ -125,6 +151,7 @@ type (
 	TrackedMediaItem struct {
 		MediaId    int                     `json:"mediaId"`
 		Type       string                  `json:"type"`
+		Priority   int                     `json:"priority"`
 		AnimeEntry *anilist.AnimeListEntry `json:"animeEntry,omitempty"`
 		MangaEntry *anilist.MangaListEntry `json:"mangaEntry,omitempty"`
 	}
@@ -403,6 +430,7 @@ func (m *ManagerImpl) GetTrackedMediaItems() (ret []*TrackedMediaItem) {
 					ret = append(ret, &TrackedMediaItem{
 						MediaId:    item.MediaId,
 						Type:       item.Type,
+						Priority:   item.Priority,
 						AnimeEntry: e,
 					})
 					continue
@@ -411,6 +439,7 @@ func (m *ManagerImpl) GetTrackedMediaItems() (ret []*TrackedMediaItem) {
 					ret = append(ret, &TrackedMediaItem{
 						MediaId:    item.MediaId,
 						Type:       item.Type,
+						Priority:   item.Priority,
 						AnimeEntry: e,
 					})
 					continue
@@ -422,6 +451,7 @@ func (m *ManagerImpl) GetTrackedMediaItems() (ret []*TrackedMediaItem) {
 					ret = append(ret, &TrackedMediaItem{
 						MediaId:    item.MediaId,
 						Type:       item.Type,
+						Priority:   item.Priority,
 						MangaEntry: e,
 					})
 					continue
@@ -431,6 +461,7 @@ func (m *ManagerImpl) GetTrackedMediaItems() (ret []*TrackedMediaItem) {
 				ret = append(ret, &TrackedMediaItem{
 					MediaId:    item.MediaId,
 					Type:       item.Type,
+					Priority:   item.Priority,
 					MangaEntry: e,
 				})
 				continue
@@ -465,26 +496,37 @@ func (m *ManagerImpl) SynchronizeLocal() error {
 		return fmt.Errorf("cannot sync, upload or ignore local changes before syncing")
 	}
 
+	lfs, mangaChapterContainers, err := m.getSyncSources()
+	if err != nil {
+		return err
+	}
+
+	return m.synchronize(lfs, mangaChapterContainers)
+}
+
+// getSyncSources returns the local files and downloaded chapter containers used to synchronize the tracked media.
+func (m *ManagerImpl) getSyncSources() ([]*anime.LocalFile, []*manga.ChapterContainer, error) {
 	lfs, _, err := db_bridge.GetLocalFiles(m.db)
 	if err != nil {
-		return fmt.Errorf("local manager: Couldn't start syncing, failed to get local files: %w", err)
+		return nil, nil, fmt.Errorf("local manager: Couldn't start syncing, failed to get local files: %w", err)
 	}
+	lfs = m.mergeOfflineEpisodeLocalFiles(lfs)
 
 	// Check if the anime and manga collections are set
 	if m.animeCollection.IsAbsent() {
-		return fmt.Errorf("local manager: Couldn't start syncing, anime collection not set")
+		return nil, nil, fmt.Errorf("local manager: Couldn't start syncing, anime collection not set")
 	}
 
 	if m.mangaCollection.IsAbsent() {
-		return fmt.Errorf("local manager: Couldn't start syncing, manga collection not set")
+		return nil, nil, fmt.Errorf("local manager: Couldn't start syncing, manga collection not set")
 	}
 
 	mangaChapterContainers, err := m.mangaRepository.GetDownloadedChapterContainers(m.mangaCollection.MustGet())
 	if err != nil {
-		return fmt.Errorf("local manager: Couldn't start syncing, failed to get downloaded chapter containers: %w", err)
+		return nil, nil, fmt.Errorf("local manager: Couldn't start syncing, failed to get downloaded chapter containers: %w", err)
 	}
 
-	return m.synchronize(lfs, mangaChapterContainers)
+	return lfs, mangaChapterContainers, nil
 }
 
 func (m *ManagerImpl) synchronize(lfs []*anime.LocalFile, mangaChapterContainers []*manga.ChapterContainer) error {
@@ -525,24 +567,29 @@ func (m *ManagerImpl) synchronize(lfs []*anime.LocalFile, mangaChapterContainers
 		}
 	}
 
-	// Get snapshots for all tracked anime and manga
+	animeSnapshotMap, mangaSnapshotMap := m.getSnapshotMaps()
+
+	m.syncer.runDiffs(trackedAnimeMap, animeSnapshotMap, trackedMangaMap, mangaSnapshotMap, m.localFiles, m.downloadedChapterContainers)
+
+	return nil
+}
+
+// getSnapshotMaps returns the snapshots of all tracked anime and manga, keyed by media ID.
+func (m *ManagerImpl) getSnapshotMaps() (animeSnapshotMap map[int]*AnimeSnapshot, mangaSnapshotMap map[int]*MangaSnapshot) {
 	animeSnapshots, _ := m.localDb.GetAnimeSnapshots()
 	mangaSnapshots, _ := m.localDb.GetMangaSnapshots()
 
-	// Create a map of the snapshots
-	animeSnapshotMap := make(map[int]*AnimeSnapshot)
+	animeSnapshotMap = make(map[int]*AnimeSnapshot)
 	for _, snapshot := range animeSnapshots {
 		animeSnapshotMap[snapshot.MediaId] = snapshot
 	}
 
-	mangaSnapshotMap := make(map[int]*MangaSnapshot)
+	mangaSnapshotMap = make(map[int]*MangaSnapshot)
 	for _, snapshot := range mangaSnapshots {
 		mangaSnapshotMap[snapshot.MediaId] = snapshot
 	}
 
-	m.syncer.runDiffs(trackedAnimeMap, animeSnapshotMap, trackedMangaMap, mangaSnapshotMap, m.localFiles, m.downloadedChapterContainers)
-
-	return nil
+	return
 }
 
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -784,7 +831,7 @@ func (m *ManagerImpl) GetLocalStorageSize() int64 {
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
 func (m *ManagerImpl) GetSimulatedAnimeCollection() mo.Option[*anilist.AnimeCollection] {
-	ac, ok := m.localDb.GetSimulatedAnimeCollection()
+	ac, ok := m.localDb.GetSimulatedAnimeCollection(models.DefaultProfileID)
 	if !ok {
 		return mo.None[*anilist.AnimeCollection]()
 	}
@@ -792,7 +839,7 @@ func (m *ManagerImpl) GetSimulatedAnimeCollection() mo.Option[*anilist.AnimeColl
 }
 
 func (m *ManagerImpl) GetSimulatedMangaCollection() mo.Option[*anilist.MangaCollection] {
-	mc, ok := m.localDb.GetSimulatedMangaCollection()
+	mc, ok := m.localDb.GetSimulatedMangaCollection(models.DefaultProfileID)
 	if !ok {
 		return mo.None[*anilist.MangaCollection]()
 	}
@@ -806,11 +853,11 @@ func (m *ManagerImpl) SaveSimulatedAnimeCollection(ac *anilist.AnimeCollection)
 	//		entry.GetMedia().NextAiringEpisode = nil
 	//	}
 	//}
-	_ = m.localDb.SaveSimulatedAnimeCollection(ac)
+	_ = m.localDb.SaveSimulatedAnimeCollection(models.DefaultProfileID, ac)
 }
 
 func (m *ManagerImpl) SaveSimulatedMangaCollection(mc *anilist.MangaCollection) {
-	_ = m.localDb.SaveSimulatedMangaCollection(mc)
+	_ = m.localDb.SaveSimulatedMangaCollection(models.DefaultProfileID, mc)
 }
 
 func (m *ManagerImpl) SynchronizeAnilistToSimulatedCollection() error {
@@ -826,7 +873,7 @@ func (m *ManagerImpl) SynchronizeAnilistToSimulatedCollection() error {
 }
 
 func (m *ManagerImpl) SynchronizeSimulatedCollectionToAnilist() error {
-	if localAnimeCollection, ok := m.localDb.GetSimulatedAnimeCollection(); ok {
+	if localAnimeCollection, ok := m.localDb.GetSimulatedAnimeCollection(models.DefaultProfileID); ok {
 		for _, list := range localAnimeCollection.MediaListCollection.Lists {
 			if list.GetStatus() == nil || list.GetEntries() == nil {
 				continue
@@ -891,7 +938,7 @@ func (m *ManagerImpl) SynchronizeSimulatedCollectionToAnilist() error {
 		}
 	}
 
-	if localMangaCollection, ok := m.localDb.GetSimulatedMangaCollection(); ok {
+	if localMangaCollection, ok := m.localDb.GetSimulatedMangaCollection(models.DefaultProfileID); ok {
 		for _, list := range localMangaCollection.MediaListCollection.Lists {
 			if list.GetStatus() == nil || list.GetEntries() == nil {
 				continue
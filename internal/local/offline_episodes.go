@@ -0,0 +1,141 @@
+package local
+
+import (
+	"cmp"
+	"fmt"
+	"path/filepath"
+	"seanime/internal/database/db"
+	"seanime/internal/library/anime"
+	"seanime/internal/util"
+	"strconv"
+)
+
+const (
+	OfflineDownloadSourceTorrent = "torrent"
+	OfflineDownloadSourceDebrid  = "debrid"
+
+	OfflineEpisodeStatusDownloading = "downloading"
+	OfflineEpisodeStatusComplete    = "complete"
+
+	defaultOfflineDownloadEpisodeCount = 2
+	defaultOfflineDownloadMaxSize      = 20 // GiB
+)
+
+// GetOfflineDownloadSettings returns the settings of the pre-download of upcoming episodes, unset values are replaced by the defaults.
+func (m *ManagerImpl) GetOfflineDownloadSettings() *OfflineDownloadSettings {
+	ret := &OfflineDownloadSettings{}
+	if s := m.localDb.GetSettings(); s.OfflineDownload != nil {
+		*ret = *s.OfflineDownload
+	}
+
+	ret.Source = cmp.Or(ret.Source, OfflineDownloadSourceTorrent)
+	if ret.EpisodeCount <= 0 {
+		ret.EpisodeCount = defaultOfflineDownloadEpisodeCount
+	}
+	if ret.MaxSize <= 0 {
+		ret.MaxSize = defaultOfflineDownloadMaxSize
+	}
+	ret.Dir = cmp.Or(ret.Dir, filepath.Join(m.localDir, "episodes"))
+
+	return ret
+}
+
+func (m *ManagerImpl) SaveOfflineDownloadSettings(settings *OfflineDownloadSettings) error {
+	if settings == nil {
+		return fmt.Errorf("local manager: No settings provided")
+	}
+	if settings.Source != OfflineDownloadSourceTorrent && settings.Source != OfflineDownloadSourceDebrid {
+		return fmt.Errorf("local manager: Invalid offline download source %q", settings.Source)
+	}
+	if settings.Dir != "" && !filepath.IsAbs(settings.Dir) {
+		return fmt.Errorf("local manager: The offline download folder must be an absolute path")
+	}
+	// The files of the offline folder that are not downloaded episodes are removed from the local files
+	dir := cmp.Or(settings.Dir, filepath.Join(m.localDir, "episodes"))
+	if libraryPath, overlaps := OfflineDirOverlapsLibrary(m.db, dir); overlaps {
+		return fmt.Errorf("local manager: The offline download folder cannot overlap the library folder %q", libraryPath)
+	}
+
+	s := m.localDb.GetSettings()
+	s.OfflineDownload = settings
+	return m.localDb.SaveSettings(s)
+}
+
+// OfflineDirOverlapsLibrary returns the library path that contains the offline download folder or is contained by it, if any.
+func OfflineDirOverlapsLibrary(database *db.Database, dir string) (string, bool) {
+	libraryPaths, err := database.GetAllLibraryPathsFromSettings()
+	if err != nil {
+		return "", false
+	}
+	for _, libraryPath := range libraryPaths {
+		if libraryPath == "" {
+			continue
+		}
+		if util.IsSameDir(libraryPath, dir) || util.IsSubdirectory(libraryPath, dir) || util.IsSubdirectory(dir, libraryPath) {
+			return libraryPath, true
+		}
+	}
+	return "", false
+}
+
+// GetOfflineEpisodes returns the downloaded and downloading episodes, ordered by media and episode number.
+func (m *ManagerImpl) GetOfflineEpisodes() []*OfflineEpisode {
+	ret, ok := m.localDb.GetOfflineEpisodes()
+	if !ok {
+		return make([]*OfflineEpisode, 0)
+	}
+	return ret
+}
+
+func (m *ManagerImpl) SaveOfflineEpisode(oe *OfflineEpisode) error {
+	return m.localDb.SaveOfflineEpisode(oe)
+}
+
+// DeleteOfflineEpisode removes the episode from the local database, the files are not deleted.
+func (m *ManagerImpl) DeleteOfflineEpisode(id uint) error {
+	return m.localDb.DeleteOfflineEpisode(id)
+}
+
+// GetOfflineEpisodeLocalFiles returns the local files of the completely downloaded episodes.
+func (m *ManagerImpl) GetOfflineEpisodeLocalFiles() []*anime.LocalFile {
+	dir := m.GetOfflineDownloadSettings().Dir
+
+	ret := make([]*anime.LocalFile, 0)
+	for _, oe := range m.GetOfflineEpisodes() {
+		if oe.Status != OfflineEpisodeStatusComplete || oe.Path == "" {
+			continue
+		}
+		ret = append(ret, NewOfflineEpisodeLocalFile(oe, dir))
+	}
+	return ret
+}
+
+// NewOfflineEpisodeLocalFile returns the local file of a downloaded episode.
+// The file is locked since it is matched to the episode it was downloaded for.
+func NewOfflineEpisodeLocalFile(oe *OfflineEpisode, dir string) *anime.LocalFile {
+	lf := anime.NewLocalFile(oe.Path, dir)
+	lf.MediaId = oe.MediaId
+	lf.Locked = true
+	lf.Metadata = &anime.LocalFileMetadata{
+		Episode:      oe.EpisodeNumber,
+		AniDBEpisode: strconv.Itoa(oe.EpisodeNumber),
+		Type:         anime.LocalFileTypeMain,
+	}
+	return lf
+}
+
+// mergeOfflineEpisodeLocalFiles adds the local files of the downloaded episodes that are not in lfs.
+// They are missing if the library was scanned after they were downloaded.
+func (m *ManagerImpl) mergeOfflineEpisodeLocalFiles(lfs []*anime.LocalFile) []*anime.LocalFile {
+	paths := make(map[string]struct{}, len(lfs))
+	for _, lf := range lfs {
+		paths[lf.GetNormalizedPath()] = struct{}{}
+	}
+
+	for _, lf := range m.GetOfflineEpisodeLocalFiles() {
+		if _, found := paths[util.NormalizePath(lf.Path)]; !found {
+			lfs = append(lfs, lf)
+		}
+	}
+	return lfs
+}
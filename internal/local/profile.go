@@ -0,0 +1,53 @@
+package local
+
+import (
+	"seanime/internal/api/anilist"
+	"seanime/internal/database/models"
+
+	"github.com/samber/mo"
+)
+
+// profileManager is the Manager used by the simulated platform of a profile.
+// Only the simulated collections belong to the profile, the local collections and the offline data are shared.
+type profileManager struct {
+	*ManagerImpl
+	profileId uint
+}
+
+func (m *ManagerImpl) GetProfileManager(profileId uint) Manager {
+	if profileId == 0 || profileId == models.DefaultProfileID {
+		return m
+	}
+	return &profileManager{
+		ManagerImpl: m,
+		profileId:   profileId,
+	}
+}
+
+func (m *ManagerImpl) DeleteSimulatedCollections(profileId uint) error {
+	return m.localDb.DeleteSimulatedCollections(profileId)
+}
+
+func (m *profileManager) GetSimulatedAnimeCollection() mo.Option[*anilist.AnimeCollection] {
+	ac, ok := m.localDb.GetSimulatedAnimeCollection(m.profileId)
+	if !ok {
+		return mo.None[*anilist.AnimeCollection]()
+	}
+	return mo.Some(ac)
+}
+
+func (m *profileManager) GetSimulatedMangaCollection() mo.Option[*anilist.MangaCollection] {
+	mc, ok := m.localDb.GetSimulatedMangaCollection(m.profileId)
+	if !ok {
+		return mo.None[*anilist.MangaCollection]()
+	}
+	return mo.Some(mc)
+}
+
+func (m *profileManager) SaveSimulatedAnimeCollection(ac *anilist.AnimeCollection) {
+	_ = m.localDb.SaveSimulatedAnimeCollection(m.profileId, ac)
+}
+
+func (m *profileManager) SaveSimulatedMangaCollection(mc *anilist.MangaCollection) {
+	_ = m.localDb.SaveSimulatedMangaCollection(m.profileId, mc)
+}
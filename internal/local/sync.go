@@ -49,6 +49,8 @@ type (
 	QueueState struct {
 		AnimeTasks map[int]*QueueMediaTask `json:"animeTasks"`
 		MangaTasks map[int]*QueueMediaTask `json:"mangaTasks"`
+		// Media that were not queued because they don't fit in the storage budget
+		SkippedTasks []*QueueMediaTask `json:"skippedTasks"`
 	}
 
 	QueueMediaTask struct {
@@ -59,9 +61,12 @@ type (
 	}
 	AnimeTask struct {
 		Diff *AnimeDiffResult
+		// Settings used to plan the synchronization, the images are downloaded with the same settings
+		Settings *SyncSettings
 	}
 	MangaTask struct {
-		Diff *MangaDiffResult
+		Diff     *MangaDiffResult
+		Settings *SyncSettings
 	}
 )
 
@@ -76,8 +81,9 @@ func NewQueue(manager *ManagerImpl) *Syncer {
 		manager:                      manager,
 		mu:                           sync.RWMutex{},
 		queueState: QueueState{
-			AnimeTasks: make(map[int]*QueueMediaTask),
-			MangaTasks: make(map[int]*QueueMediaTask),
+			AnimeTasks:   make(map[int]*QueueMediaTask),
+			MangaTasks:   make(map[int]*QueueMediaTask),
+			SkippedTasks: make([]*QueueMediaTask, 0),
 		},
 		queueStateMu: sync.RWMutex{},
 	}
@@ -102,7 +108,7 @@ func (q *Syncer) processAnimeJobs() {
 		q.queueStateMu.Unlock()
 
 		q.shouldUpdateLocalCollections = true
-		q.synchronizeAnime(job.Diff)
+		q.synchronizeAnime(job.Diff, job.Settings)
 
 		q.queueStateMu.Lock()
 		delete(q.queueState.AnimeTasks, job.Diff.AnimeEntry.Media.ID)
@@ -127,7 +133,7 @@ func (q *Syncer) processMangaJobs() {
 		q.queueStateMu.Unlock()
 
 		q.shouldUpdateLocalCollections = true
-		q.synchronizeManga(job.Diff)
+		q.synchronizeManga(job.Diff, job.Settings)
 
 		q.queueStateMu.Lock()
 		delete(q.queueState.MangaTasks, job.Diff.MangaEntry.Media.ID)
@@ -442,7 +448,8 @@ func (q *Syncer) refreshCollections() {
 }
 
 // runDiffs runs the diffing process to find outdated anime & manga.
-// The diffs are then added to the job queues for synchronization.
+// The diffs are then added to the job queues for synchronization, by order of priority.
+// Diffs that don't fit in the storage budget are not queued and are reported in QueueState.SkippedTasks.
 func (q *Syncer) runDiffs(
 	trackedAnimeMap map[int]*TrackedMedia,
 	trackedAnimeSnapshotMap map[int]*AnimeSnapshot,
@@ -471,6 +478,69 @@ func (q *Syncer) runDiffs(
 		return
 	}
 
+	animeDiffs, mangaDiffs := q.getDiffs(trackedAnimeMap, trackedAnimeSnapshotMap, trackedMangaMap, trackedMangaSnapshotMap, localFiles, downloadedChapterContainers)
+
+	// The settings are read once so that the queued tasks download what the plan estimated,
+	// even if the settings change during the synchronization
+	settings := q.manager.GetSyncSettings()
+
+	plan := q.planSync(animeDiffs, mangaDiffs, localFiles, trackedAnimeMap, trackedMangaMap, settings)
+
+	skippedTasks := make([]*QueueMediaTask, 0)
+	for _, item := range plan.Items {
+		if item.Skipped {
+			skippedTasks = append(skippedTasks, &QueueMediaTask{
+				MediaId: item.MediaId,
+				Image:   item.Image,
+				Title:   item.Title,
+				Type:    item.Type,
+			})
+		}
+	}
+	if len(skippedTasks) > 0 {
+		q.manager.logger.Warn().Int("skipped", len(skippedTasks)).Int64("budget", plan.Budget).Msg("local manager: Some media don't fit in the storage budget and will not be synchronized")
+	}
+
+	q.queueStateMu.Lock()
+	q.queueState.SkippedTasks = skippedTasks
+	q.SendQueueStateToClient()
+	q.queueStateMu.Unlock()
+
+	// Add the diffs to be synced asynchronously
+	go func() {
+		q.manager.logger.Trace().Int("animeJobs", len(animeDiffs)).Int("mangaJobs", len(mangaDiffs)).Msg("local manager: Adding diffs to the job queues")
+
+		for _, item := range plan.Items {
+			if item.Skipped {
+				continue
+			}
+			switch item.Type {
+			case AnimeType:
+				q.animeJobQueue <- AnimeTask{Diff: animeDiffs[item.MediaId], Settings: settings}
+			case MangaType:
+				q.mangaJobQueue <- MangaTask{Diff: mangaDiffs[item.MediaId], Settings: settings}
+			}
+		}
+
+		if len(plan.Items) == 0 {
+			q.manager.logger.Trace().Msg("local manager: No diffs found")
+			//q.refreshCollections()
+		}
+	}()
+
+	// Done
+	q.manager.logger.Trace().Msg("local manager: Done running diffs")
+}
+
+// getDiffs returns the outdated tracked anime & manga.
+func (q *Syncer) getDiffs(
+	trackedAnimeMap map[int]*TrackedMedia,
+	trackedAnimeSnapshotMap map[int]*AnimeSnapshot,
+	trackedMangaMap map[int]*TrackedMedia,
+	trackedMangaSnapshotMap map[int]*MangaSnapshot,
+	localFiles []*anime.LocalFile,
+	downloadedChapterContainers []*manga.ChapterContainer,
+) (animeDiffs map[int]*AnimeDiffResult, mangaDiffs map[int]*MangaDiffResult) {
 	diff := &Diff{
 		Logger: q.manager.logger,
 	}
@@ -478,8 +548,6 @@ func (q *Syncer) runDiffs(
 	wg := sync.WaitGroup{}
 	wg.Add(2)
 
-	var animeDiffs map[int]*AnimeDiffResult
-
 	go func() {
 		animeDiffs = diff.GetAnimeDiffs(GetAnimeDiffOptions{
 			Collection:      q.manager.animeCollection.MustGet(),
@@ -492,8 +560,6 @@ func (q *Syncer) runDiffs(
 		//q.manager.logger.Trace().Msg("local manager: Finished getting anime diffs")
 	}()
 
-	var mangaDiffs map[int]*MangaDiffResult
-
 	go func() {
 		mangaDiffs = diff.GetMangaDiffs(GetMangaDiffOptions{
 			Collection:                  q.manager.mangaCollection.MustGet(),
@@ -508,25 +574,7 @@ func (q *Syncer) runDiffs(
 
 	wg.Wait()
 
-	// Add the diffs to be synced asynchronously
-	go func() {
-		q.manager.logger.Trace().Int("animeJobs", len(animeDiffs)).Int("mangaJobs", len(mangaDiffs)).Msg("local manager: Adding diffs to the job queues")
-
-		for _, i := range animeDiffs {
-			q.animeJobQueue <- AnimeTask{Diff: i}
-		}
-		for _, i := range mangaDiffs {
-			q.mangaJobQueue <- MangaTask{Diff: i}
-		}
-
-		if len(animeDiffs) == 0 && len(mangaDiffs) == 0 {
-			q.manager.logger.Trace().Msg("local manager: No diffs found")
-			//q.refreshCollections()
-		}
-	}()
-
-	// Done
-	q.manager.logger.Trace().Msg("local manager: Done running diffs")
+	return
 }
 
 //----------------------------------------------------------------------------------------------------------------------------------------------------
@@ -535,7 +583,7 @@ func (q *Syncer) runDiffs(
 // The anime should be tracked.
 //   - If the anime has no local files, it will be removed entirely from the local database.
 //   - If the anime has local files, we create or update the snapshot.
-func (q *Syncer) synchronizeAnime(diff *AnimeDiffResult) {
+func (q *Syncer) synchronizeAnime(diff *AnimeDiffResult, settings *SyncSettings) {
 	defer util.HandlePanicInModuleThen("sync/synchronizeAnime", func() {})
 
 	entry := diff.AnimeEntry
@@ -576,7 +624,7 @@ func (q *Syncer) synchronizeAnime(diff *AnimeDiffResult) {
 	// The snapshot is missing
 	//
 	if diff.DiffType == DiffTypeMissing && animeMetadata != nil {
-		bannerImage, coverImage, episodeImagePaths, ok := DownloadAnimeImages(q.manager.logger, q.manager.localAssetsDir, entry, animeMetadata, metadataWrapper, lfs)
+		bannerImage, coverImage, episodeImagePaths, ok := DownloadAnimeImages(q.manager.logger, q.manager.localAssetsDir, entry, animeMetadata, metadataWrapper, lfs, settings.ImageQuality)
 		if !ok {
 			q.sendAnimeToFailedQueue(entry)
 			return
@@ -630,7 +678,7 @@ func (q *Syncer) synchronizeAnime(diff *AnimeDiffResult) {
 		}
 
 		// Download the episode images if needed
-		if len(episodeImageUrlsToDownload) > 0 {
+		if len(episodeImageUrlsToDownload) > 0 && settings.ImageQuality == SyncImageQualityAll {
 			// Download only the episode images that we need to download
 			episodeImagePaths, ok := DownloadAnimeEpisodeImages(q.manager.logger, q.manager.localAssetsDir, entry.GetMedia().GetID(), episodeImageUrlsToDownload)
 			if !ok {
@@ -661,7 +709,7 @@ func (q *Syncer) synchronizeAnime(diff *AnimeDiffResult) {
 // We know that the manga is tracked.
 //   - If the manga has no chapter containers, it will be removed entirely from the local database.
 //   - If the manga has chapter containers, we create or update the snapshot.
-func (q *Syncer) synchronizeManga(diff *MangaDiffResult) {
+func (q *Syncer) synchronizeManga(diff *MangaDiffResult, settings *SyncSettings) {
 	defer util.HandlePanicInModuleThen("sync/synchronizeManga", func() {})
 
 	entry := diff.MangaEntry
@@ -704,7 +752,7 @@ func (q *Syncer) synchronizeManga(diff *MangaDiffResult) {
 	}
 
 	if diff.DiffType == DiffTypeMissing {
-		bannerImage, coverImage, ok := DownloadMangaImages(q.manager.logger, q.manager.localAssetsDir, entry)
+		bannerImage, coverImage, ok := DownloadMangaImages(q.manager.logger, q.manager.localAssetsDir, entry, settings.ImageQuality)
 		if !ok {
 			q.sendMangaToFailedQueue(entry)
 			return
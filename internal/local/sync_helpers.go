@@ -127,8 +127,9 @@ func DownloadAnimeEpisodeImages(logger *zerolog.Logger, assetsDir string, mId in
 // This should be used to download the images for an anime for the first time.
 //
 // It will download the images to the `<assetsDir>/<mId>` directory and return the filenames of the banner, cover, and episode images.
+// The imageQuality (see SyncImageQualityAll) determines which images are downloaded, the cover is used as the banner if it isn't downloaded.
 //
-//	DownloadAnimeImages(logger, "path/to/datadir/local/assets", entry, animeMetadata)
+//	DownloadAnimeImages(logger, "path/to/datadir/local/assets", entry, animeMetadata, metadataWrapper, lfs, SyncImageQualityAll)
 //	-> "banner.jpg", "cover.jpg", map[string]string{"1": "filename1.jpg", "2": "filename2.jpg"}
 func DownloadAnimeImages(
 	logger *zerolog.Logger,
@@ -137,6 +138,7 @@ func DownloadAnimeImages(
 	animeMetadata *metadata.AnimeMetadata, // This is updated
 	metadataWrapper metadata.AnimeMetadataWrapper,
 	lfs []*anime.LocalFile,
+	imageQuality string,
 ) (string, string, map[string]string, bool) {
 	defer util.HandlePanicInModuleThen("sync/DownloadAnimeImages", func() {})
 
@@ -147,6 +149,9 @@ func DownloadAnimeImages(
 	// Download the images
 	ogBannerImage := entry.GetMedia().GetBannerImageSafe()
 	ogCoverImage := entry.GetMedia().GetCoverImageSafe()
+	if imageQuality == SyncImageQualityCover {
+		ogBannerImage = ogCoverImage
+	}
 
 	imgUrls := []string{ogBannerImage, ogCoverImage}
 
@@ -155,8 +160,14 @@ func DownloadAnimeImages(
 		lfMap[lf.Metadata.AniDBEpisode] = lf
 	}
 
+	// Episode images are only downloaded with the highest quality
+	episodes := animeMetadata.Episodes
+	if imageQuality != SyncImageQualityAll {
+		episodes = nil
+	}
+
 	ogEpisodeImages := make(map[string]string)
-	for episodeNum, episode := range animeMetadata.Episodes {
+	for episodeNum, episode := range episodes {
 		// Check if the episode is in the local files
 		if _, ok := lfMap[episodeNum]; !ok {
 			continue
@@ -207,10 +218,11 @@ func DownloadAnimeImages(
 // This should be used to download the images for a manga for the first time.
 //
 // It will download the images to the `<assetsDir>/<mId>` directory and return the filenames of the banner and cover images.
+// If the imageQuality is SyncImageQualityCover, the cover is used as the banner.
 //
-//	DownloadMangaImages(logger, "path/to/datadir/local/assets", entry)
+//	DownloadMangaImages(logger, "path/to/datadir/local/assets", entry, SyncImageQualityAll)
 //	-> "banner.jpg", "cover.jpg"
-func DownloadMangaImages(logger *zerolog.Logger, assetsDir string, entry *anilist.MangaListEntry) (string, string, bool) {
+func DownloadMangaImages(logger *zerolog.Logger, assetsDir string, entry *anilist.MangaListEntry, imageQuality string) (string, string, bool) {
 	logger.Trace().Msgf("local manager: Downloading images for manga %d", entry.Media.ID)
 
 	// e.g. /datadir/local/assets/123
@@ -219,6 +231,9 @@ func DownloadMangaImages(logger *zerolog.Logger, assetsDir string, entry *anilis
 	// Download the images
 	ogBannerImage := entry.GetMedia().GetBannerImageSafe()
 	ogCoverImage := entry.GetMedia().GetCoverImageSafe()
+	if imageQuality == SyncImageQualityCover {
+		ogBannerImage = ogCoverImage
+	}
 
 	imgUrls := []string{ogBannerImage, ogCoverImage}
 
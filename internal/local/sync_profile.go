@@ -0,0 +1,229 @@
+package local
+
+import (
+	"cmp"
+	"fmt"
+	"seanime/internal/library/anime"
+	"slices"
+
+	"github.com/samber/lo"
+)
+
+const (
+	SyncImageQualityCover  = "cover"  // Only the cover image is downloaded, it is also used as the banner
+	SyncImageQualityBanner = "banner" // The cover and banner images are downloaded
+	SyncImageQualityAll    = "all"    // The cover, banner and episode images are downloaded
+
+	// Estimated sizes of the downloaded images, used to plan the synchronization before downloading anything
+	estimatedCoverImageSize   = 200 << 10
+	estimatedBannerImageSize  = 350 << 10
+	estimatedEpisodeImageSize = 100 << 10
+)
+
+type (
+	// SyncEstimate is the result of a dry run of the synchronization.
+	SyncEstimate struct {
+		// Size of the local storage in bytes
+		CurrentSize int64 `json:"currentSize"`
+		// Storage budget in bytes, 0 if there is no limit
+		Budget int64 `json:"budget"`
+		// Estimated size of the media that will be synchronized in bytes
+		RequiredSize int64 `json:"requiredSize"`
+		// Media that need to be synchronized, ordered by priority
+		Items []*SyncEstimateItem `json:"items"`
+	}
+
+	SyncEstimateItem struct {
+		MediaId  int    `json:"mediaId"`
+		Type     string `json:"type"`
+		Title    string `json:"title"`
+		Image    string `json:"image"`
+		Priority int    `json:"priority"`
+		// Estimated size of the downloaded images in bytes
+		Size int64 `json:"size"`
+		// Whether the media is skipped because it doesn't fit in the storage budget
+		Skipped bool `json:"skipped"`
+	}
+)
+
+// GetSyncSettings returns the sync profile, unset values are replaced by the defaults.
+func (m *ManagerImpl) GetSyncSettings() *SyncSettings {
+	ret := &SyncSettings{}
+	if s := m.localDb.GetSettings(); s.Sync != nil {
+		*ret = *s.Sync
+	}
+
+	ret.ImageQuality = cmp.Or(ret.ImageQuality, SyncImageQualityAll)
+	ret.StorageBudget = max(ret.StorageBudget, 0)
+
+	return ret
+}
+
+func (m *ManagerImpl) SaveSyncSettings(settings *SyncSettings) error {
+	if settings == nil {
+		return fmt.Errorf("local manager: No settings provided")
+	}
+	switch settings.ImageQuality {
+	case SyncImageQualityCover, SyncImageQualityBanner, SyncImageQualityAll:
+	default:
+		return fmt.Errorf("local manager: Invalid image quality %q", settings.ImageQuality)
+	}
+	if settings.StorageBudget < 0 {
+		return fmt.Errorf("local manager: The storage budget cannot be negative")
+	}
+
+	s := m.localDb.GetSettings()
+	s.Sync = settings
+	return m.localDb.SaveSettings(s)
+}
+
+func (m *ManagerImpl) SetTrackedMediaPriority(mId int, kind string, priority int) error {
+	tm, found := m.localDb.GetTrackedMedia(mId, kind)
+	if !found {
+		return fmt.Errorf("local manager: Media %d is not tracked", mId)
+	}
+
+	tm.Priority = priority
+	return m.localDb.SetTrackedMedia(tm)
+}
+
+// EstimateSynchronizeLocal runs the diffs of SynchronizeLocal without queuing anything.
+// The returned items are the ones the Syncer would synchronize, with those that don't fit in the storage budget marked as skipped.
+func (m *ManagerImpl) EstimateSynchronizeLocal() (*SyncEstimate, error) {
+	lfs, mangaChapterContainers, err := m.getSyncSources()
+	if err != nil {
+		return nil, err
+	}
+
+	trackedAnimeMap, trackedMangaMap := m.loadTrackedMedia()
+	animeSnapshotMap, mangaSnapshotMap := m.getSnapshotMaps()
+
+	animeDiffs, mangaDiffs := m.syncer.getDiffs(trackedAnimeMap, animeSnapshotMap, trackedMangaMap, mangaSnapshotMap, lfs, mangaChapterContainers)
+
+	return m.syncer.planSync(animeDiffs, mangaDiffs, lfs, trackedAnimeMap, trackedMangaMap, m.GetSyncSettings()), nil
+}
+
+//----------------------------------------------------------------------------------------------------------------------------------------------------
+
+// planSync estimates the size of each diff and marks the ones that don't fit in the storage budget as skipped.
+func (q *Syncer) planSync(
+	animeDiffs map[int]*AnimeDiffResult,
+	mangaDiffs map[int]*MangaDiffResult,
+	localFiles []*anime.LocalFile,
+	trackedAnimeMap map[int]*TrackedMedia,
+	trackedMangaMap map[int]*TrackedMedia,
+	settings *SyncSettings,
+) *SyncEstimate {
+	lfsByMedia := lo.GroupBy(localFiles, func(lf *anime.LocalFile) int {
+		return lf.MediaId
+	})
+
+	items := make([]*SyncEstimateItem, 0, len(animeDiffs)+len(mangaDiffs))
+	for mId, diff := range animeDiffs {
+		item := &SyncEstimateItem{
+			MediaId: mId,
+			Type:    AnimeType,
+			Title:   diff.AnimeEntry.GetMedia().GetPreferredTitle(),
+			Image:   diff.AnimeEntry.GetMedia().GetCoverImageSafe(),
+			Size:    estimateAnimeSyncSize(diff, lfsByMedia[mId], settings.ImageQuality),
+		}
+		if tm, ok := trackedAnimeMap[mId]; ok {
+			item.Priority = tm.Priority
+		}
+		items = append(items, item)
+	}
+	for mId, diff := range mangaDiffs {
+		item := &SyncEstimateItem{
+			MediaId: mId,
+			Type:    MangaType,
+			Title:   diff.MangaEntry.GetMedia().GetPreferredTitle(),
+			Image:   diff.MangaEntry.GetMedia().GetCoverImageSafe(),
+			Size:    estimateMangaSyncSize(diff, settings.ImageQuality),
+		}
+		if tm, ok := trackedMangaMap[mId]; ok {
+			item.Priority = tm.Priority
+		}
+		items = append(items, item)
+	}
+
+	ret := &SyncEstimate{
+		CurrentSize: q.manager.GetLocalStorageSize(),
+		Budget:      int64(settings.StorageBudget) << 20,
+		Items:       items,
+	}
+	ret.RequiredSize = planSyncItems(ret.Items, ret.CurrentSize, ret.Budget)
+
+	return ret
+}
+
+// planSyncItems sorts the items by priority and marks the ones that would exceed the budget as skipped.
+// Smaller items with a lower priority are still synchronized if they fit in the remaining space.
+// It returns the total size of the items that are not skipped.
+func planSyncItems(items []*SyncEstimateItem, currentSize int64, budget int64) (required int64) {
+	slices.SortStableFunc(items, func(a, b *SyncEstimateItem) int {
+		return cmp.Or(
+			cmp.Compare(b.Priority, a.Priority),
+			cmp.Compare(a.Type, b.Type),
+			cmp.Compare(a.MediaId, b.MediaId),
+		)
+	})
+
+	for _, item := range items {
+		if budget > 0 && item.Size > 0 && currentSize+required+item.Size > budget {
+			item.Skipped = true
+			continue
+		}
+		required += item.Size
+	}
+	return
+}
+
+// estimateAnimeSyncSize returns the estimated size of the images downloaded by Syncer.synchronizeAnime.
+// lfs are the local files of the anime.
+func estimateAnimeSyncSize(diff *AnimeDiffResult, lfs []*anime.LocalFile, imageQuality string) int64 {
+	episodes := make(map[string]struct{})
+	for _, lf := range lfs {
+		if lf.Metadata != nil && lf.Metadata.AniDBEpisode != "" {
+			episodes[lf.Metadata.AniDBEpisode] = struct{}{}
+		}
+	}
+
+	switch diff.DiffType {
+	case DiffTypeMissing:
+		media := diff.AnimeEntry.GetMedia()
+		size := int64(estimatedCoverImageSize)
+		if imageQuality != SyncImageQualityCover && media.GetBannerImageSafe() != media.GetCoverImageSafe() {
+			size += estimatedBannerImageSize
+		}
+		if imageQuality == SyncImageQualityAll {
+			size += int64(len(episodes)) * estimatedEpisodeImageSize
+		}
+		return size
+	case DiffTypeMetadata:
+		if imageQuality != SyncImageQualityAll || diff.AnimeSnapshot == nil {
+			return 0
+		}
+		var size int64
+		for episode := range episodes {
+			if _, found := diff.AnimeSnapshot.EpisodeImagePaths[episode]; !found {
+				size += estimatedEpisodeImageSize
+			}
+		}
+		return size
+	}
+	return 0
+}
+
+// estimateMangaSyncSize returns the estimated size of the images downloaded by Syncer.synchronizeManga.
+func estimateMangaSyncSize(diff *MangaDiffResult, imageQuality string) int64 {
+	if diff.DiffType != DiffTypeMissing {
+		return 0
+	}
+
+	media := diff.MangaEntry.GetMedia()
+	size := int64(estimatedCoverImageSize)
+	if imageQuality != SyncImageQualityCover && media.GetBannerImageSafe() != media.GetCoverImageSafe() {
+		size += estimatedBannerImageSize
+	}
+	return size
+}
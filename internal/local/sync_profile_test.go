@@ -0,0 +1,62 @@
+package local
+
+import (
+	"seanime/internal/api/anilist"
+	"seanime/internal/library/anime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanSyncItems(t *testing.T) {
+	items := []*SyncEstimateItem{
+		{MediaId: 1, Type: AnimeType, Size: 400},
+		{MediaId: 2, Type: AnimeType, Size: 300, Priority: 1},
+		{MediaId: 3, Type: MangaType, Size: 100},
+		// Metadata changes only
+		{MediaId: 4, Type: AnimeType, Size: 0},
+	}
+
+	required := planSyncItems(items, 500, 1000)
+
+	// Media 2 has the highest priority, media 1 doesn't fit anymore but the smaller media 3 still does
+	assert.Equal(t, []int{2, 1, 4, 3}, []int{items[0].MediaId, items[1].MediaId, items[2].MediaId, items[3].MediaId})
+	assert.Equal(t, []bool{false, true, false, false}, []bool{items[0].Skipped, items[1].Skipped, items[2].Skipped, items[3].Skipped})
+	assert.Equal(t, int64(400), required)
+
+	// No budget
+	for _, item := range items {
+		item.Skipped = false
+	}
+	assert.Equal(t, int64(800), planSyncItems(items, 500, 0))
+}
+
+func TestEstimateAnimeSyncSize(t *testing.T) {
+	cover := "cover.jpg"
+	banner := "banner.jpg"
+	entry := &anilist.AnimeListEntry{
+		Media: &anilist.BaseAnime{
+			ID:          1,
+			BannerImage: &banner,
+			CoverImage:  &anilist.BaseAnime_CoverImage{ExtraLarge: &cover},
+		},
+	}
+	lfs := []*anime.LocalFile{
+		{MediaId: 1, Metadata: &anime.LocalFileMetadata{Episode: 1, AniDBEpisode: "1"}},
+		{MediaId: 1, Metadata: &anime.LocalFileMetadata{Episode: 2, AniDBEpisode: "2"}},
+	}
+
+	missing := &AnimeDiffResult{AnimeEntry: entry, DiffType: DiffTypeMissing}
+	assert.Equal(t, int64(estimatedCoverImageSize), estimateAnimeSyncSize(missing, lfs, SyncImageQualityCover))
+	assert.Equal(t, int64(estimatedCoverImageSize+estimatedBannerImageSize), estimateAnimeSyncSize(missing, lfs, SyncImageQualityBanner))
+	assert.Equal(t, int64(estimatedCoverImageSize+estimatedBannerImageSize+2*estimatedEpisodeImageSize), estimateAnimeSyncSize(missing, lfs, SyncImageQualityAll))
+
+	// Only the episode images that are not in the snapshot are downloaded
+	outdated := &AnimeDiffResult{
+		AnimeEntry:    entry,
+		AnimeSnapshot: &AnimeSnapshot{EpisodeImagePaths: StringMap{"1": "1.jpg"}},
+		DiffType:      DiffTypeMetadata,
+	}
+	assert.Equal(t, int64(estimatedEpisodeImageSize), estimateAnimeSyncSize(outdated, lfs, SyncImageQualityAll))
+	assert.Zero(t, estimateAnimeSyncSize(outdated, lfs, SyncImageQualityBanner))
+}
@@ -144,11 +144,23 @@ func (i *Iina) launchPlayer(idle bool, filePath string, args ...string) error {
 	return nil
 }
 
-func (i *Iina) replaceFile(filePath string) error {
+func (i *Iina) replaceFile(filePath string, subtitlePaths []string) error {
 	i.Logger.Debug().Msg("iina: Replacing file")
 
 	if i.conn != nil && !i.conn.IsClosed() {
-		_, err := i.conn.Call("loadfile", filePath, "replace")
+		// The external subtitle files are loaded with the next file, clear the ones from the previous file
+		_, err := i.conn.Call("change-list", "sub-files", "clr", "")
+		if err != nil {
+			i.Logger.Warn().Err(err).Msg("iina: Failed to clear external subtitle files")
+		}
+		for _, subtitlePath := range subtitlePaths {
+			_, err = i.conn.Call("change-list", "sub-files", "append", subtitlePath)
+			if err != nil {
+				i.Logger.Warn().Err(err).Str("path", subtitlePath).Msg("iina: Failed to add external subtitle file")
+			}
+		}
+
+		_, err = i.conn.Call("loadfile", filePath, "replace")
 		if err != nil {
 			return err
 		}
@@ -162,6 +174,11 @@ func (i *Iina) Exited() chan struct{} {
 }
 
 func (i *Iina) OpenAndPlay(filePath string, args ...string) error {
+	return i.OpenAndPlayWithSubtitles(filePath, nil, args...)
+}
+
+// OpenAndPlayWithSubtitles plays the file and loads the given external subtitle files.
+func (i *Iina) OpenAndPlayWithSubtitles(filePath string, subtitlePaths []string, args ...string) error {
 	i.mu.Lock()
 	defer i.mu.Unlock()
 
@@ -171,9 +188,12 @@ func (i *Iina) OpenAndPlay(filePath string, args ...string) error {
 	var err error
 	if i.conn != nil && !i.conn.IsClosed() {
 		// Launch player or replace file
-		err = i.replaceFile(filePath)
+		err = i.replaceFile(filePath, subtitlePaths)
 	} else {
 		// Launch player
+		for _, subtitlePath := range subtitlePaths {
+			args = append(args, "--mpv-sub-files-append="+subtitlePath)
+		}
 		err = i.launchPlayer(false, filePath, args...)
 	}
 	if err != nil {
@@ -0,0 +1,265 @@
+package kodi
+
+// https://kodi.wiki/view/JSON-RPC_API/v13
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Kodi controls a Kodi instance through its JSON-RPC API.
+// The web server must be enabled in Kodi (Settings > Services > Control > Allow remote control via HTTP).
+type Kodi struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	Logger   *zerolog.Logger
+
+	client    *http.Client
+	requestId atomic.Int64
+}
+
+type (
+	// Status is the playback status of the active video player.
+	Status struct {
+		Playing bool
+		// Position in seconds
+		Time float64
+		// Duration in seconds
+		TotalTime float64
+		// File or URL being played
+		File string
+	}
+
+	request struct {
+		JsonRPC string      `json:"jsonrpc"`
+		Method  string      `json:"method"`
+		Params  interface{} `json:"params,omitempty"`
+		ID      int64       `json:"id"`
+	}
+
+	response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *responseError  `json:"error"`
+	}
+
+	responseError struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+
+	activePlayer struct {
+		PlayerID int    `json:"playerid"`
+		Type     string `json:"type"`
+	}
+
+	globalTime struct {
+		Hours        int `json:"hours"`
+		Minutes      int `json:"minutes"`
+		Seconds      int `json:"seconds"`
+		Milliseconds int `json:"milliseconds"`
+	}
+)
+
+var ErrNoActivePlayer = errors.New("kodi: no active video player")
+
+func New(logger *zerolog.Logger, host string, port int, username string, password string) *Kodi {
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	if port == 0 {
+		port = 8080
+	}
+	return &Kodi{
+		Host:     host,
+		Port:     port,
+		Username: username,
+		Password: password,
+		Logger:   logger,
+		client:   &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (k *Kodi) url() string {
+	return fmt.Sprintf("http://%s:%d/jsonrpc", k.Host, k.Port)
+}
+
+// call sends a JSON-RPC request and decodes the result into ret, if not nil.
+func (k *Kodi) call(method string, params interface{}, ret interface{}) error {
+	body, err := json.Marshal(&request{
+		JsonRPC: "2.0",
+		Method:  method,
+		Params:  params,
+		ID:      k.requestId.Add(1),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, k.url(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if k.Username != "" || k.Password != "" {
+		req.SetBasicAuth(k.Username, k.Password)
+	}
+
+	client := k.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("kodi: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return errors.New("kodi: invalid username or password")
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("kodi: http error code: %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var res response
+	if err := json.Unmarshal(data, &res); err != nil {
+		return fmt.Errorf("kodi: invalid response: %w", err)
+	}
+	if res.Error != nil {
+		return fmt.Errorf("kodi: %s: %s (%d)", method, res.Error.Message, res.Error.Code)
+	}
+
+	if ret != nil {
+		return json.Unmarshal(res.Result, ret)
+	}
+	return nil
+}
+
+func (k *Kodi) Ping() error {
+	return k.call("JSONRPC.Ping", nil, nil)
+}
+
+// Open plays the file or URL.
+func (k *Kodi) Open(file string) error {
+	k.Logger.Debug().Str("file", file).Msg("kodi: Opening file")
+	return k.call("Player.Open", map[string]interface{}{
+		"item": map[string]interface{}{"file": file},
+	}, nil)
+}
+
+// getVideoPlayerId returns the ID of the active video player.
+func (k *Kodi) getVideoPlayerId() (int, error) {
+	var players []activePlayer
+	if err := k.call("Player.GetActivePlayers", nil, &players); err != nil {
+		return 0, err
+	}
+	for _, p := range players {
+		if p.Type == "video" {
+			return p.PlayerID, nil
+		}
+	}
+	return 0, ErrNoActivePlayer
+}
+
+// SetPlaying pauses or resumes playback.
+func (k *Kodi) SetPlaying(playing bool) error {
+	playerId, err := k.getVideoPlayerId()
+	if err != nil {
+		return err
+	}
+	return k.call("Player.PlayPause", map[string]interface{}{
+		"playerid": playerId,
+		"play":     playing,
+	}, nil)
+}
+
+// Seek seeks to the given position in seconds.
+func (k *Kodi) Seek(seconds float64) error {
+	playerId, err := k.getVideoPlayerId()
+	if err != nil {
+		return err
+	}
+	return k.call("Player.Seek", map[string]interface{}{
+		"playerid": playerId,
+		"value":    map[string]interface{}{"time": toGlobalTime(seconds)},
+	}, nil)
+}
+
+func (k *Kodi) Stop() error {
+	playerId, err := k.getVideoPlayerId()
+	if err != nil {
+		return err
+	}
+	return k.call("Player.Stop", map[string]interface{}{"playerid": playerId}, nil)
+}
+
+// GetStatus returns the playback status of the active video player.
+func (k *Kodi) GetStatus() (*Status, error) {
+	playerId, err := k.getVideoPlayerId()
+	if err != nil {
+		return nil, err
+	}
+
+	var props struct {
+		Speed     float64    `json:"speed"`
+		Time      globalTime `json:"time"`
+		TotalTime globalTime `json:"totaltime"`
+	}
+	err = k.call("Player.GetProperties", map[string]interface{}{
+		"playerid":   playerId,
+		"properties": []string{"speed", "time", "totaltime"},
+	}, &props)
+	if err != nil {
+		return nil, err
+	}
+
+	var item struct {
+		Item struct {
+			File  string `json:"file"`
+			Label string `json:"label"`
+		} `json:"item"`
+	}
+	err = k.call("Player.GetItem", map[string]interface{}{
+		"playerid":   playerId,
+		"properties": []string{"file"},
+	}, &item)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Status{
+		Playing:   props.Speed != 0,
+		Time:      props.Time.seconds(),
+		TotalTime: props.TotalTime.seconds(),
+		File:      item.Item.File,
+	}, nil
+}
+
+func (t globalTime) seconds() float64 {
+	return float64(t.Hours*3600+t.Minutes*60+t.Seconds) + float64(t.Milliseconds)/1000
+}
+
+func toGlobalTime(seconds float64) globalTime {
+	ms := int(seconds * 1000)
+	return globalTime{
+		Hours:        ms / 3_600_000,
+		Minutes:      ms / 60_000 % 60,
+		Seconds:      ms / 1000 % 60,
+		Milliseconds: ms % 1000,
+	}
+}
@@ -0,0 +1,76 @@
+package kodi
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"seanime/internal/util"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKodi_GetStatus(t *testing.T) {
+	var seekParams map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		if user != "kodi" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req struct {
+			Method string                 `json:"method"`
+			Params map[string]interface{} `json:"params"`
+			ID     int64                  `json:"id"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		var result interface{}
+		switch req.Method {
+		case "Player.GetActivePlayers":
+			result = []map[string]interface{}{{"playerid": 1, "type": "video"}}
+		case "Player.GetProperties":
+			result = map[string]interface{}{
+				"speed":     1,
+				"time":      map[string]int{"hours": 0, "minutes": 12, "seconds": 30, "milliseconds": 500},
+				"totaltime": map[string]int{"hours": 0, "minutes": 24, "seconds": 0, "milliseconds": 0},
+			}
+		case "Player.GetItem":
+			result = map[string]interface{}{"item": map[string]interface{}{"file": "http://192.168.1.10/video.mkv", "label": "video.mkv"}}
+		case "Player.Seek":
+			seekParams = req.Params
+			result = "OK"
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"id": req.ID, "error": map[string]interface{}{"code": -32601, "message": "Method not found."}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"id": req.ID, "jsonrpc": "2.0", "result": result})
+	}))
+	defer server.Close()
+
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+	k := New(util.NewLogger(), host, p, "kodi", "secret")
+
+	status, err := k.GetStatus()
+	require.NoError(t, err)
+	assert.True(t, status.Playing)
+	assert.Equal(t, 750.5, status.Time)
+	assert.Equal(t, 1440.0, status.TotalTime)
+	assert.Equal(t, "http://192.168.1.10/video.mkv", status.File)
+
+	require.NoError(t, k.Seek(3725.25))
+	assert.Equal(t, map[string]interface{}{
+		"time": map[string]interface{}{"hours": 1.0, "minutes": 2.0, "seconds": 5.0, "milliseconds": 250.0},
+	}, seekParams["value"])
+
+	assert.ErrorContains(t, k.Ping(), "Method not found")
+
+	k.Password = "wrong"
+	assert.ErrorContains(t, k.Ping(), "invalid username or password")
+}
@@ -0,0 +1,887 @@
+package mediaplayer
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"seanime/internal/events"
+	"seanime/internal/extension"
+	hibikemediaplayer "seanime/internal/extension/hibike/mediaplayer"
+	"seanime/internal/mediaplayers/iina"
+	"seanime/internal/mediaplayers/kodi"
+	mpchc2 "seanime/internal/mediaplayers/mpchc"
+	"seanime/internal/mediaplayers/mpv"
+	"seanime/internal/mediaplayers/upnp"
+	vlc2 "seanime/internal/mediaplayers/vlc"
+	"seanime/internal/trackpreference"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// MediaPlayer is implemented by every media player the Repository can control.
+	MediaPlayer interface {
+		// Start launches the media player if it is not running.
+		// Players that are launched when a file is opened, or that cannot be launched by Seanime, do nothing.
+		Start() error
+		// Play opens and plays a local file.
+		Play(opts *PlayOptions) error
+		// Stream opens and plays a stream URL.
+		Stream(opts *StreamOptions) error
+		Pause() error
+		Resume() error
+		// Seek seeks to the given position in seconds.
+		Seek(seconds float64) error
+		// GetStatus returns the playback status of the current file.
+		// It returns an error if the media player cannot be reached or nothing is playing.
+		GetStatus() (*PlayerStatus, error)
+		// Close closes the media player if it is managed by Seanime.
+		Close()
+		GetExecutablePath() string
+	}
+
+	PlayOptions struct {
+		Path string
+		// External subtitle files of the video
+		SubtitlePaths []string
+		// Position to resume from in seconds, 0 to start from the beginning
+		StartTime float64
+		// Tracks to select, nil if no track preference is set
+		Tracks *trackpreference.PlayerTracks
+	}
+
+	StreamOptions struct {
+		Url         string
+		WindowTitle string
+		// Position to resume from in seconds, 0 to start from the beginning
+		StartTime float64
+		// Tracks to select, nil if no track preference is set
+		Tracks *trackpreference.PlayerTracks
+	}
+
+	// PlayerStatus is the playback status reported by a media player.
+	PlayerStatus struct {
+		Playing  bool
+		Filename string
+		Filepath string
+		// Duration as reported by the media player, see PlaybackStatus.Duration
+		Duration             int
+		CurrentTimeInSeconds float64
+		DurationInSeconds    float64
+		CompletionPercentage float64
+	}
+)
+
+var (
+	ErrNoDefaultPlayer = errors.New("no default media player set")
+	ErrNotPlaying      = errors.New("nothing is playing")
+)
+
+// delayedSeek pauses the player, seeks and resumes playback.
+// Used by players that ignore seek requests sent right after a file is opened.
+func delayedSeek(pause func() error, seek func() error, resume func() error) {
+	time.Sleep(400 * time.Millisecond)
+	_ = pause()
+	time.Sleep(400 * time.Millisecond)
+	_ = seek()
+	time.Sleep(400 * time.Millisecond)
+	_ = resume()
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// VLC
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type vlcPlayer struct {
+	vlc *vlc2.VLC
+}
+
+func (p *vlcPlayer) Start() error {
+	if err := p.vlc.Start(); err != nil {
+		return fmt.Errorf("could not start VLC, %w", err)
+	}
+	return nil
+}
+
+func (p *vlcPlayer) Play(opts *PlayOptions) error {
+	started, err := p.open(opts.Path, vlcTrackOptions(opts.Tracks))
+	if err != nil {
+		return err
+	}
+	p.disableSubtitlesAfterOpen(started, opts.Tracks)
+
+	// Subtitles added before the input has started are ignored
+	if len(opts.SubtitlePaths) > 0 && started {
+		for _, subtitlePath := range opts.SubtitlePaths {
+			_ = p.vlc.AddSubtitle(subtitlePath)
+		}
+	}
+
+	if opts.StartTime > 0 {
+		p.seekAfterOpen(opts.StartTime)
+	}
+	return nil
+}
+
+func (p *vlcPlayer) Stream(opts *StreamOptions) error {
+	started, err := p.open(opts.Url, vlcTrackOptions(opts.Tracks))
+	if err != nil {
+		return err
+	}
+	p.disableSubtitlesAfterOpen(started, opts.Tracks)
+	if opts.StartTime > 0 {
+		p.seekAfterOpen(opts.StartTime)
+	}
+	return nil
+}
+
+// vlcInputTimeout is how long open waits for the input to start.
+const vlcInputTimeout = 10 * time.Second
+
+// open plays the URI and waits for VLC to report the new input as playing.
+// It returns false if the input did not start before the timeout.
+func (p *vlcPlayer) open(uri string, options []string) (bool, error) {
+	// The playlist item ID changes when the input is replaced
+	previousId := uint(0)
+	if status, err := p.vlc.GetStatus(); err == nil {
+		previousId = status.CurrentPlID
+	}
+
+	if err := p.vlc.AddAndPlayWithOptions(uri, options); err != nil {
+		return false, err
+	}
+
+	deadline := time.Now().Add(vlcInputTimeout)
+	for time.Now().Before(deadline) {
+		status, err := p.vlc.GetStatus()
+		if err == nil && status.CurrentPlID != previousId && status.State != "stopped" && status.Length > 0 {
+			return true, nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return false, nil
+}
+
+// disableSubtitlesAfterOpen deselects the subtitle track once the input has started.
+func (p *vlcPlayer) disableSubtitlesAfterOpen(started bool, tracks *trackpreference.PlayerTracks) {
+	if !started || tracks == nil || !tracks.SubtitlesDisabled {
+		return
+	}
+	_ = p.vlc.SelectSubtitleTrack(-1)
+}
+
+// vlcTrackOptions returns the input options selecting the preferred tracks.
+// VLC track numbers start at 0.
+func vlcTrackOptions(tracks *trackpreference.PlayerTracks) []string {
+	if tracks == nil {
+		return nil
+	}
+	var options []string
+	if tracks.AudioPosition >= 0 {
+		options = append(options, "audio-track="+strconv.Itoa(tracks.AudioPosition))
+	} else if len(tracks.AudioLanguages) > 0 {
+		options = append(options, "audio-language="+strings.Join(tracks.AudioLanguages, ","))
+	}
+	if !tracks.SubtitlesDisabled {
+		if tracks.SubtitlePosition >= 0 {
+			options = append(options, "sub-track="+strconv.Itoa(tracks.SubtitlePosition))
+		} else if len(tracks.SubtitleLanguages) > 0 {
+			options = append(options, "sub-language="+strings.Join(tracks.SubtitleLanguages, ","))
+		}
+	}
+	return options
+}
+
+func (p *vlcPlayer) seekAfterOpen(seconds float64) {
+	delayedSeek(p.vlc.ForcePause, func() error { return p.Seek(seconds) }, p.vlc.Resume)
+}
+
+func (p *vlcPlayer) Pause() error {
+	return p.vlc.Pause()
+}
+
+func (p *vlcPlayer) Resume() error {
+	return p.vlc.Resume()
+}
+
+func (p *vlcPlayer) Seek(seconds float64) error {
+	return p.vlc.Seek(fmt.Sprintf("%d", int(seconds)))
+}
+
+func (p *vlcPlayer) GetStatus() (*PlayerStatus, error) {
+	st, err := p.vlc.GetStatus()
+	if err != nil {
+		return nil, err
+	}
+	if st == nil {
+		return nil, ErrNotPlaying
+	}
+
+	return &PlayerStatus{
+		CompletionPercentage: st.Position,
+		Playing:              st.State == "playing",
+		Filename:             st.Information.Category["meta"].Filename,
+		Duration:             int(st.Length * 1000),
+		Filepath:             "", // VLC does not provide the filepath
+		CurrentTimeInSeconds: float64(st.Time),
+		DurationInSeconds:    float64(st.Length),
+	}, nil
+}
+
+func (p *vlcPlayer) Close() {}
+
+func (p *vlcPlayer) GetExecutablePath() string {
+	return p.vlc.GetExecutablePath()
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// MPC-HC
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type mpcHcPlayer struct {
+	mpcHc *mpchc2.MpcHc
+}
+
+func (p *mpcHcPlayer) Start() error {
+	if err := p.mpcHc.Start(); err != nil {
+		return fmt.Errorf("could not start MPC-HC, %w", err)
+	}
+	return nil
+}
+
+// Play opens the file.
+// MPC-HC's web interface cannot load subtitle files, it loads them from the video's folder and its "Subs" folder by itself.
+func (p *mpcHcPlayer) Play(opts *PlayOptions) error {
+	return p.open(opts.Path, opts.StartTime)
+}
+
+func (p *mpcHcPlayer) Stream(opts *StreamOptions) error {
+	return p.open(opts.Url, opts.StartTime)
+}
+
+func (p *mpcHcPlayer) open(path string, startTime float64) error {
+	if _, err := p.mpcHc.OpenAndPlay(path); err != nil {
+		return err
+	}
+	if startTime > 0 {
+		delayedSeek(p.mpcHc.Pause, func() error { return p.Seek(startTime) }, p.mpcHc.Play)
+	}
+	return nil
+}
+
+func (p *mpcHcPlayer) Pause() error {
+	return p.mpcHc.Pause()
+}
+
+func (p *mpcHcPlayer) Resume() error {
+	return p.mpcHc.Play()
+}
+
+func (p *mpcHcPlayer) Seek(seconds float64) error {
+	return p.mpcHc.Seek(int(seconds))
+}
+
+func (p *mpcHcPlayer) GetStatus() (*PlayerStatus, error) {
+	st, err := p.mpcHc.GetVariables()
+	if err != nil {
+		return nil, err
+	}
+	if st == nil || st.Duration == 0 {
+		return nil, ErrNotPlaying
+	}
+
+	return &PlayerStatus{
+		CompletionPercentage: st.Position / st.Duration,
+		Playing:              st.State == 2,
+		Filename:             st.File,
+		Duration:             int(st.Duration),
+		Filepath:             st.FilePath,
+		CurrentTimeInSeconds: st.Position / 1000,
+		DurationInSeconds:    st.Duration / 1000,
+	}, nil
+}
+
+func (p *mpcHcPlayer) Close() {}
+
+func (p *mpcHcPlayer) GetExecutablePath() string {
+	return p.mpcHc.GetExecutablePath()
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// MPV
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type mpvPlayer struct {
+	mpv *mpv.Mpv
+}
+
+// Start does nothing, MPV is launched when a file is opened.
+func (p *mpvPlayer) Start() error {
+	return nil
+}
+
+func (p *mpvPlayer) Play(opts *PlayOptions) error {
+	var args []string
+	if opts.StartTime > 0 {
+		args = append(args, "--no-resume-playback")
+	}
+	args = append(args, mpvTrackArgs(opts.Tracks)...)
+	if err := p.mpv.OpenAndPlayWithSubtitles(opts.Path, opts.SubtitlePaths, args...); err != nil {
+		return err
+	}
+	if opts.StartTime > 0 {
+		_ = p.mpv.SeekTo(opts.StartTime)
+	}
+	return nil
+}
+
+func (p *mpvPlayer) Stream(opts *StreamOptions) error {
+	var args []string
+	if opts.WindowTitle != "" {
+		args = append(args, fmt.Sprintf("--title=%q", opts.WindowTitle))
+	}
+	args = append(args, mpvTrackArgs(opts.Tracks)...)
+	if err := p.mpv.OpenAndPlay(opts.Url, args...); err != nil {
+		return err
+	}
+	if opts.StartTime > 0 {
+		_ = p.mpv.SeekTo(opts.StartTime)
+	}
+	return nil
+}
+
+// mpvTrackArgs returns the launch options selecting the preferred tracks.
+// MPV track IDs start at 1, the languages are used when the track IDs are not known.
+func mpvTrackArgs(tracks *trackpreference.PlayerTracks) []string {
+	if tracks == nil {
+		return nil
+	}
+	var args []string
+	if len(tracks.AudioLanguages) > 0 {
+		args = append(args, "--alang="+strings.Join(tracks.AudioLanguages, ","))
+	}
+	if len(tracks.SubtitleLanguages) > 0 {
+		args = append(args, "--slang="+strings.Join(tracks.SubtitleLanguages, ","))
+	}
+	if tracks.AudioPosition >= 0 {
+		args = append(args, "--aid="+strconv.Itoa(tracks.AudioPosition+1))
+	}
+	if tracks.SubtitlesDisabled {
+		args = append(args, "--sid=no")
+	} else if tracks.SubtitlePosition >= 0 {
+		args = append(args, "--sid="+strconv.Itoa(tracks.SubtitlePosition+1))
+	}
+	return args
+}
+
+func (p *mpvPlayer) Pause() error {
+	return p.mpv.Pause()
+}
+
+func (p *mpvPlayer) Resume() error {
+	return p.mpv.Resume()
+}
+
+func (p *mpvPlayer) Seek(seconds float64) error {
+	return p.mpv.Seek(seconds)
+}
+
+func (p *mpvPlayer) GetStatus() (*PlayerStatus, error) {
+	st, err := p.mpv.GetPlaybackStatus()
+	if err != nil {
+		return nil, err
+	}
+	if st == nil || st.Duration == 0 || !st.IsRunning {
+		return nil, ErrNotPlaying
+	}
+
+	return &PlayerStatus{
+		CompletionPercentage: st.Position / st.Duration,
+		Playing:              !st.Paused,
+		Filename:             st.Filename,
+		Duration:             int(st.Duration),
+		Filepath:             st.Filepath,
+		CurrentTimeInSeconds: st.Position,
+		DurationInSeconds:    st.Duration,
+	}, nil
+}
+
+func (p *mpvPlayer) Close() {
+	p.mpv.CloseAll()
+}
+
+func (p *mpvPlayer) GetExecutablePath() string {
+	return p.mpv.GetExecutablePath()
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// IINA
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type iinaPlayer struct {
+	iina *iina.Iina
+}
+
+// Start does nothing, IINA is launched when a file is opened.
+func (p *iinaPlayer) Start() error {
+	return nil
+}
+
+func (p *iinaPlayer) Play(opts *PlayOptions) error {
+	var args []string
+	if opts.StartTime > 0 {
+		args = append(args, "--mpv-no-resume-playback")
+	}
+	if err := p.iina.OpenAndPlayWithSubtitles(opts.Path, opts.SubtitlePaths, args...); err != nil {
+		return err
+	}
+	if opts.StartTime > 0 {
+		_ = p.iina.SeekTo(opts.StartTime)
+	}
+	return nil
+}
+
+func (p *iinaPlayer) Stream(opts *StreamOptions) error {
+	var args []string
+	if opts.WindowTitle != "" {
+		args = append(args, fmt.Sprintf("--mpv-title=%q", opts.WindowTitle))
+	}
+	if err := p.iina.OpenAndPlay(opts.Url, args...); err != nil {
+		return err
+	}
+	if opts.StartTime > 0 {
+		_ = p.iina.SeekTo(opts.StartTime)
+	}
+	return nil
+}
+
+func (p *iinaPlayer) Pause() error {
+	return p.iina.Pause()
+}
+
+func (p *iinaPlayer) Resume() error {
+	return p.iina.Resume()
+}
+
+func (p *iinaPlayer) Seek(seconds float64) error {
+	return p.iina.Seek(seconds)
+}
+
+func (p *iinaPlayer) GetStatus() (*PlayerStatus, error) {
+	st, err := p.iina.GetPlaybackStatus()
+	if err != nil {
+		return nil, err
+	}
+	if st == nil || st.Duration == 0 || !st.IsRunning {
+		return nil, ErrNotPlaying
+	}
+
+	return &PlayerStatus{
+		CompletionPercentage: st.Position / st.Duration,
+		Playing:              !st.Paused,
+		Filename:             st.Filename,
+		Duration:             int(st.Duration),
+		Filepath:             st.Filepath,
+		CurrentTimeInSeconds: st.Position,
+		DurationInSeconds:    st.Duration,
+	}, nil
+}
+
+func (p *iinaPlayer) Close() {}
+
+func (p *iinaPlayer) GetExecutablePath() string {
+	return p.iina.GetExecutablePath()
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Network players
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// networkPlayer is a media player running on another device (Kodi, DLNA renderer).
+// These players cannot open local paths, local files are served to them over HTTP.
+// Since they only know the URL, the path of the local file is remembered so that the status can be matched with the library.
+type networkPlayer struct {
+	// fileUrl returns the URL at which the player can fetch the local file
+	fileUrl func(path string) (string, error)
+	// serverUrl returns the URL at which the player can reach the server
+	serverUrl func() string
+
+	mu          sync.Mutex
+	currentUrl  string
+	currentPath string // Local path of the file being played, empty when streaming
+	currentName string
+}
+
+func (p *networkPlayer) setCurrent(fileUrl string, path string, name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.currentUrl = fileUrl
+	p.currentPath = path
+	p.currentName = name
+}
+
+// localFileUrl returns the URL of a local file and sets it as the current file.
+func (p *networkPlayer) localFileUrl(path string) (string, error) {
+	if p.fileUrl == nil {
+		return "", errors.New("local files cannot be played on this media player, the server URL is not set")
+	}
+	ret, err := p.fileUrl(path)
+	if err != nil {
+		return "", err
+	}
+	p.setCurrent(ret, path, filepath.Base(path))
+	return ret, nil
+}
+
+// remoteStreamUrl replaces the loopback host of a stream served by Seanime with the server URL and sets it as the current file.
+func (p *networkPlayer) remoteStreamUrl(streamUrl string, name string) string {
+	ret := streamUrl
+	if u, err := url.Parse(streamUrl); err == nil && p.serverUrl != nil {
+		host := u.Hostname()
+		if host == "127.0.0.1" || host == "localhost" || host == "0.0.0.0" {
+			if base, err := url.Parse(p.serverUrl()); err == nil && base.Host != "" {
+				u.Scheme = base.Scheme
+				u.Host = base.Host
+				ret = u.String()
+			}
+		}
+	}
+	p.setCurrent(ret, "", name)
+	return ret
+}
+
+// fillStatus sets the filename and filepath of the status from the current file.
+//   - uri: URI reported by the player, used to detect that another file is playing
+func (p *networkPlayer) fillStatus(status *PlayerStatus, uri string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.currentUrl != "" && (uri == "" || uri == p.currentUrl) {
+		status.Filename = p.currentName
+		status.Filepath = p.currentPath
+		return
+	}
+	// Playing something that was not opened by Seanime
+	status.Filename = filepath.Base(uri)
+}
+
+func newPlayerStatus(playing bool, position float64, duration float64) (*PlayerStatus, error) {
+	if duration <= 0 {
+		return nil, ErrNotPlaying
+	}
+	return &PlayerStatus{
+		CompletionPercentage: position / duration,
+		Playing:              playing,
+		Duration:             int(duration * 1000),
+		CurrentTimeInSeconds: position,
+		DurationInSeconds:    duration,
+	}, nil
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Kodi
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type kodiPlayer struct {
+	networkPlayer
+	kodi *kodi.Kodi
+}
+
+// Start checks that Kodi can be reached, Kodi cannot be launched remotely.
+func (p *kodiPlayer) Start() error {
+	if err := p.kodi.Ping(); err != nil {
+		return fmt.Errorf("could not connect to Kodi, %w", err)
+	}
+	return nil
+}
+
+func (p *kodiPlayer) Play(opts *PlayOptions) error {
+	fileUrl, err := p.localFileUrl(opts.Path)
+	if err != nil {
+		return err
+	}
+	return p.open(fileUrl, opts.StartTime)
+}
+
+func (p *kodiPlayer) Stream(opts *StreamOptions) error {
+	return p.open(p.remoteStreamUrl(opts.Url, opts.WindowTitle), opts.StartTime)
+}
+
+func (p *kodiPlayer) open(fileUrl string, startTime float64) error {
+	if err := p.kodi.Open(fileUrl); err != nil {
+		return err
+	}
+	if startTime > 0 {
+		// Kodi ignores seek requests until the file is loaded
+		go func() {
+			for i := 0; i < 20; i++ {
+				time.Sleep(500 * time.Millisecond)
+				if st, err := p.kodi.GetStatus(); err == nil && st.TotalTime > 0 {
+					_ = p.kodi.Seek(startTime)
+					return
+				}
+			}
+		}()
+	}
+	return nil
+}
+
+func (p *kodiPlayer) Pause() error {
+	return p.kodi.SetPlaying(false)
+}
+
+func (p *kodiPlayer) Resume() error {
+	return p.kodi.SetPlaying(true)
+}
+
+func (p *kodiPlayer) Seek(seconds float64) error {
+	return p.kodi.Seek(seconds)
+}
+
+func (p *kodiPlayer) GetStatus() (*PlayerStatus, error) {
+	st, err := p.kodi.GetStatus()
+	if err != nil {
+		return nil, err
+	}
+	ret, err := newPlayerStatus(st.Playing, st.Time, st.TotalTime)
+	if err != nil {
+		return nil, err
+	}
+	p.fillStatus(ret, st.File)
+	return ret, nil
+}
+
+func (p *kodiPlayer) Close() {}
+
+func (p *kodiPlayer) GetExecutablePath() string {
+	return ""
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// UPnP / DLNA
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type upnpPlayer struct {
+	networkPlayer
+	renderer *upnp.Renderer
+}
+
+// Start fetches the device description of the renderer, the renderer cannot be launched remotely.
+func (p *upnpPlayer) Start() error {
+	if err := p.renderer.Connect(); err != nil {
+		return fmt.Errorf("could not connect to the renderer, %w", err)
+	}
+	return nil
+}
+
+func (p *upnpPlayer) Play(opts *PlayOptions) error {
+	fileUrl, err := p.localFileUrl(opts.Path)
+	if err != nil {
+		return err
+	}
+	return p.open(fileUrl, filepath.Base(opts.Path), opts.StartTime)
+}
+
+func (p *upnpPlayer) Stream(opts *StreamOptions) error {
+	return p.open(p.remoteStreamUrl(opts.Url, opts.WindowTitle), opts.WindowTitle, opts.StartTime)
+}
+
+func (p *upnpPlayer) open(fileUrl string, title string, startTime float64) error {
+	if err := p.renderer.Connect(); err != nil {
+		return err
+	}
+	if err := p.renderer.SetURI(fileUrl, title); err != nil {
+		return err
+	}
+	if err := p.renderer.Play(); err != nil {
+		return err
+	}
+	if startTime > 0 {
+		// Most renderers reject seek requests while the media is loading
+		go func() {
+			for i := 0; i < 20; i++ {
+				time.Sleep(500 * time.Millisecond)
+				if st, err := p.renderer.GetStatus(); err == nil && st.Duration > 0 {
+					_ = p.renderer.Seek(startTime)
+					return
+				}
+			}
+		}()
+	}
+	return nil
+}
+
+func (p *upnpPlayer) Pause() error {
+	return p.renderer.Pause()
+}
+
+func (p *upnpPlayer) Resume() error {
+	return p.renderer.Play()
+}
+
+func (p *upnpPlayer) Seek(seconds float64) error {
+	return p.renderer.Seek(seconds)
+}
+
+func (p *upnpPlayer) GetStatus() (*PlayerStatus, error) {
+	if err := p.renderer.Connect(); err != nil {
+		return nil, err
+	}
+	st, err := p.renderer.GetStatus()
+	if err != nil {
+		return nil, err
+	}
+	if st.State == upnp.StateStopped || st.State == upnp.StateNoMedia {
+		return nil, ErrNotPlaying
+	}
+	ret, err := newPlayerStatus(st.State == upnp.StatePlaying, st.Position, st.Duration)
+	if err != nil {
+		return nil, err
+	}
+	p.fillStatus(ret, st.URI)
+	return ret, nil
+}
+
+// Close stops playback, the renderer keeps showing the last frame otherwise.
+func (p *upnpPlayer) Close() {
+	_ = p.renderer.Stop()
+}
+
+func (p *upnpPlayer) GetExecutablePath() string {
+	return ""
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Extensions
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// extensionPlayer is a media player provided by an extension.
+type extensionPlayer struct {
+	networkPlayer
+	ext            extension.MediaPlayerExtension
+	wsEventManager events.WSEventManagerInterface
+}
+
+func (p *extensionPlayer) player() hibikemediaplayer.MediaPlayer {
+	return p.ext.GetMediaPlayer()
+}
+
+// CanTrackProgress returns false if the extension does not report the playback status.
+func (p *extensionPlayer) CanTrackProgress() bool {
+	return p.player().GetSettings().CanTrackProgress
+}
+
+func (p *extensionPlayer) Start() error {
+	if err := p.player().Start(); err != nil {
+		return fmt.Errorf("could not start %s, %w", p.ext.GetName(), err)
+	}
+	return nil
+}
+
+func (p *extensionPlayer) Play(opts *PlayOptions) error {
+	req := hibikemediaplayer.PlayRequest{
+		Path:          opts.Path,
+		LocalPath:     opts.Path,
+		SubtitlePaths: opts.SubtitlePaths,
+		StartTime:     opts.StartTime,
+		ClientInfo:    hibikemediaplayer.ClientInfo{Platform: runtime.GOOS},
+	}
+
+	if p.player().GetSettings().RequiresUrl {
+		fileUrl, err := p.localFileUrl(opts.Path)
+		if err != nil {
+			return err
+		}
+		req.Path = fileUrl
+	} else {
+		p.setCurrent(opts.Path, opts.Path, filepath.Base(opts.Path))
+	}
+
+	res, err := p.player().Play(req)
+	if err != nil {
+		return err
+	}
+	p.openUrl(res)
+	return nil
+}
+
+func (p *extensionPlayer) Stream(opts *StreamOptions) error {
+	req := hibikemediaplayer.PlayRequest{
+		Path:       opts.Url,
+		StartTime:  opts.StartTime,
+		Title:      opts.WindowTitle,
+		ClientInfo: hibikemediaplayer.ClientInfo{Platform: runtime.GOOS},
+	}
+
+	if p.player().GetSettings().RequiresUrl {
+		req.Path = p.remoteStreamUrl(opts.Url, opts.WindowTitle)
+	} else {
+		p.setCurrent(opts.Url, "", opts.WindowTitle)
+	}
+
+	res, err := p.player().Stream(req)
+	if err != nil {
+		return err
+	}
+	p.openUrl(res)
+	return nil
+}
+
+// openUrl asks the client to open the URL returned by the extension, e.g. a URL scheme of a mobile player.
+func (p *extensionPlayer) openUrl(res *hibikemediaplayer.PlayResponse) {
+	if res == nil || res.OpenURL == "" || p.wsEventManager == nil {
+		return
+	}
+	p.wsEventManager.SendEvent(events.ExternalPlayerOpenURL, struct {
+		Url string `json:"url"`
+	}{
+		Url: res.OpenURL,
+	})
+}
+
+func (p *extensionPlayer) Pause() error {
+	return p.player().Pause()
+}
+
+func (p *extensionPlayer) Resume() error {
+	return p.player().Resume()
+}
+
+func (p *extensionPlayer) Seek(seconds float64) error {
+	return p.player().Seek(seconds)
+}
+
+func (p *extensionPlayer) GetStatus() (*PlayerStatus, error) {
+	if !p.CanTrackProgress() {
+		return nil, errors.New("the media player does not report its playback status")
+	}
+
+	st, err := p.player().GetPlaybackStatus()
+	if err != nil {
+		return nil, err
+	}
+	ret, err := newPlayerStatus(st.Playing, st.Position, st.Duration)
+	if err != nil {
+		return nil, err
+	}
+
+	p.fillStatus(ret, "")
+	// The extension knows better what is playing
+	if st.Filename != "" && st.Filename != ret.Filename {
+		ret.Filename = st.Filename
+		ret.Filepath = ""
+	}
+	return ret, nil
+}
+
+func (p *extensionPlayer) Close() {
+	_ = p.player().Stop()
+}
+
+func (p *extensionPlayer) GetExecutablePath() string {
+	return ""
+}
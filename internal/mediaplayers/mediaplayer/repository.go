@@ -2,20 +2,26 @@ package mediaplayer
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"seanime/internal/continuity"
 	"seanime/internal/events"
+	"seanime/internal/extension"
 	"seanime/internal/hook"
+	"seanime/internal/library/filesystem"
 	"seanime/internal/mediaplayers/iina"
+	"seanime/internal/mediaplayers/kodi"
 	mpchc2 "seanime/internal/mediaplayers/mpchc"
 	"seanime/internal/mediaplayers/mpv"
+	"seanime/internal/mediaplayers/upnp"
 	vlc2 "seanime/internal/mediaplayers/vlc"
+	"seanime/internal/skipsegments"
+	"seanime/internal/trackpreference"
 	"seanime/internal/util/result"
 	"sync"
 	"time"
 
 	"github.com/rs/zerolog"
+	"github.com/samber/lo"
 )
 
 const (
@@ -38,8 +44,17 @@ type (
 		MpcHc                 *mpchc2.MpcHc
 		Mpv                   *mpv.Mpv
 		Iina                  *iina.Iina
+		Kodi                  *kodi.Kodi
+		Upnp                  *upnp.Renderer
+		players               map[string]MediaPlayer
+		extensionBank         *extension.UnifiedBank
+		extensionPlayers      *result.Map[string, *extensionPlayer]
+		localFileUrl          func(path string) (string, error)
+		serverUrl             func() string
+		externalSubtitles     func(path string) []*filesystem.ExternalSubtitle
 		wsEventManager        events.WSEventManagerInterface
 		continuityManager     *continuity.Manager
+		trackPreferences      *trackpreference.Manager
 		playerInUse           string
 		completionThreshold   float64
 		mu                    sync.RWMutex
@@ -57,8 +72,21 @@ type (
 		MpcHc             *mpchc2.MpcHc
 		Mpv               *mpv.Mpv
 		Iina              *iina.Iina
+		Kodi              *kodi.Kodi
+		Upnp              *upnp.Renderer
 		WSEventManager    events.WSEventManagerInterface
 		ContinuityManager *continuity.Manager
+		// TrackPreferences selects the audio and subtitle tracks of the files and streams opened in MPV and VLC
+		TrackPreferences *trackpreference.Manager
+		// LocalFileUrl returns the URL at which network media players (Kodi, DLNA renderers) can fetch a local file
+		LocalFileUrl func(path string) (string, error)
+		// ServerUrl returns the URL at which network media players can reach the server, e.g. "http://192.168.1.10:43211"
+		ServerUrl func() string
+		// ExternalSubtitles returns the external subtitle files of a video, e.g. the ones stored by the scanner
+		// The directory of the video is searched if it is nil
+		ExternalSubtitles func(path string) []*filesystem.ExternalSubtitle
+		// ExtensionBank provides the media players of extensions, selected by setting Default to the extension ID
+		ExtensionBank *extension.UnifiedBank
 	}
 
 	// RepositorySubscriber provides a single event channel for all media player events
@@ -141,6 +169,26 @@ func (e StreamingPlaybackStatusEvent) Type() string  { return "streaming_playbac
 
 func NewRepository(opts *NewRepositoryOptions) *Repository {
 
+	players := make(map[string]MediaPlayer)
+	if opts.VLC != nil {
+		players["vlc"] = &vlcPlayer{vlc: opts.VLC}
+	}
+	if opts.MpcHc != nil {
+		players["mpc-hc"] = &mpcHcPlayer{mpcHc: opts.MpcHc}
+	}
+	if opts.Mpv != nil {
+		players["mpv"] = &mpvPlayer{mpv: opts.Mpv}
+	}
+	if opts.Iina != nil {
+		players["iina"] = &iinaPlayer{iina: opts.Iina}
+	}
+	if opts.Kodi != nil {
+		players["kodi"] = &kodiPlayer{networkPlayer: networkPlayer{fileUrl: opts.LocalFileUrl, serverUrl: opts.ServerUrl}, kodi: opts.Kodi}
+	}
+	if opts.Upnp != nil {
+		players["upnp"] = &upnpPlayer{networkPlayer: networkPlayer{fileUrl: opts.LocalFileUrl, serverUrl: opts.ServerUrl}, renderer: opts.Upnp}
+	}
+
 	return &Repository{
 		Logger:                opts.Logger,
 		Default:               opts.Default,
@@ -148,8 +196,17 @@ func NewRepository(opts *NewRepositoryOptions) *Repository {
 		MpcHc:                 opts.MpcHc,
 		Mpv:                   opts.Mpv,
 		Iina:                  opts.Iina,
+		Kodi:                  opts.Kodi,
+		Upnp:                  opts.Upnp,
+		players:               players,
+		extensionBank:         opts.ExtensionBank,
+		extensionPlayers:      result.NewResultMap[string, *extensionPlayer](),
+		localFileUrl:          opts.LocalFileUrl,
+		serverUrl:             opts.ServerUrl,
+		externalSubtitles:     opts.ExternalSubtitles,
 		wsEventManager:        opts.WSEventManager,
 		continuityManager:     opts.ContinuityManager,
+		trackPreferences:      opts.TrackPreferences,
 		completionThreshold:   0.8,
 		subscribers:           result.NewResultMap[string, *RepositorySubscriber](),
 		currentPlaybackStatus: &PlaybackStatus{},
@@ -191,9 +248,9 @@ func (m *Repository) PullStatus() (*PlaybackStatus, bool) {
 	}
 
 	if m.currentPlaybackStatus.PlaybackType == PlaybackTypeFile {
-		ok = m.processStatus(m.Default, status)
+		ok = m.processStatus(status)
 	} else {
-		ok = m.processStreamStatus(m.Default, status)
+		ok = m.processStreamStatus(status)
 	}
 	return m.currentPlaybackStatus, ok
 }
@@ -203,23 +260,109 @@ func (m *Repository) IsRunning() bool {
 }
 
 func (m *Repository) GetExecutablePath() string {
-	switch m.Default {
-	case "vlc":
-		return m.VLC.GetExecutablePath()
-	case "mpc-hc":
-		return m.MpcHc.GetExecutablePath()
-	case "mpv":
-		return m.Mpv.GetExecutablePath()
-	case "iina":
-		return m.Iina.GetExecutablePath()
-	}
-	return ""
+	player, err := m.getPlayer()
+	if err != nil {
+		return ""
+	}
+	return player.GetExecutablePath()
 }
 
 func (m *Repository) GetDefault() string {
 	return m.Default
 }
 
+// getPlayer returns the default media player.
+func (m *Repository) getPlayer() (MediaPlayer, error) {
+	if m.Default == "" {
+		return nil, ErrNoDefaultPlayer
+	}
+	if player, ok := m.players[m.Default]; ok {
+		return player, nil
+	}
+	if player, ok := m.getExtensionPlayer(m.Default); ok {
+		return player, nil
+	}
+	return nil, fmt.Errorf("unsupported media player: %s", m.Default)
+}
+
+// getExtensionPlayer returns the media player of the extension with the given ID.
+func (m *Repository) getExtensionPlayer(id string) (*extensionPlayer, bool) {
+	if m.extensionBank == nil {
+		return nil, false
+	}
+	ext, ok := extension.GetExtension[extension.MediaPlayerExtension](m.extensionBank, id)
+	if !ok {
+		return nil, false
+	}
+
+	// Keep the player as long as the extension is not reloaded, it remembers the current file
+	if player, ok := m.extensionPlayers.Get(id); ok && player.ext == ext {
+		return player, true
+	}
+	player := &extensionPlayer{
+		networkPlayer:  networkPlayer{fileUrl: m.localFileUrl, serverUrl: m.serverUrl},
+		ext:            ext,
+		wsEventManager: m.wsEventManager,
+	}
+	m.extensionPlayers.Set(id, player)
+	return player, true
+}
+
+// canTrackProgress returns false if the default media player does not report its playback status.
+func (m *Repository) canTrackProgress() bool {
+	player, err := m.getPlayer()
+	if err != nil {
+		return true
+	}
+	if p, ok := player.(interface{ CanTrackProgress() bool }); ok {
+		return p.CanTrackProgress()
+	}
+	return true
+}
+
+// SetSkipSegments exposes the skip segments of the current file to the media player.
+// Only mpv supports this, the segments are added to the chapters of the file.
+func (m *Repository) SetSkipSegments(segments []*skipsegments.Segment) {
+	if m.Default != "mpv" || len(segments) == 0 {
+		return
+	}
+
+	err := m.Mpv.AddSkipSegments(lo.Map(segments, func(s *skipsegments.Segment, _ int) *mpv.SkipSegment {
+		return &mpv.SkipSegment{
+			Title: skipSegmentTitle(s.Type),
+			Start: s.Start,
+			End:   s.End,
+		}
+	}))
+	if err != nil {
+		m.Logger.Warn().Err(err).Msg("media player: Could not add skip segments to MPV")
+		return
+	}
+
+	m.Logger.Debug().Int("count", len(segments)).Msg("media player: Added skip segments to MPV")
+}
+
+func skipSegmentTitle(t skipsegments.SegmentType) string {
+	switch t {
+	case skipsegments.TypeOpening:
+		return "Opening"
+	case skipsegments.TypeEnding:
+		return "Ending"
+	case skipsegments.TypeRecap:
+		return "Recap"
+	case skipsegments.TypePreview:
+		return "Preview"
+	}
+	return string(t)
+}
+
+func (m *Repository) getExternalSubtitles(path string) []*filesystem.ExternalSubtitle {
+	if m.externalSubtitles != nil {
+		return m.externalSubtitles(path)
+	}
+	return filesystem.FindExternalSubtitles(path)
+}
+
 // Play will start the media player and load the video at the given path.
 // The implementation of the specific media player is handled by the respective media player package.
 // Calling it multiple *should* not open multiple instances of the media player -- subsequent calls should just load a new video if the media player is already open.
@@ -227,186 +370,79 @@ func (m *Repository) Play(path string) error {
 
 	m.Logger.Debug().Str("path", path).Msg("media player: Media requested")
 
-	lastWatched := m.continuityManager.GetExternalPlayerEpisodeWatchHistoryItem(path, false, 0, 0)
-
-	switch m.Default {
-	case "vlc":
-		err := m.VLC.Start()
-		if err != nil {
-			m.Logger.Error().Err(err).Msg("media player: Could not start media player using VLC")
-			return fmt.Errorf("could not start VLC, %w", err)
-		}
-
-		err = m.VLC.AddAndPlay(path)
-		if err != nil {
-			m.Logger.Error().Err(err).Msg("media player: Could not open and play video using VLC")
-			if m.VLC.Path != "" {
-				return fmt.Errorf("could not open and play video, %w", err)
-			} else {
-				return fmt.Errorf("could not open and play video, %w", err)
-			}
-		}
-
-		if m.continuityManager.GetSettings().WatchContinuityEnabled {
-			if lastWatched.Found {
-				time.Sleep(400 * time.Millisecond)
-				_ = m.VLC.ForcePause()
-				time.Sleep(400 * time.Millisecond)
-				_ = m.VLC.Seek(fmt.Sprintf("%d", int(lastWatched.Item.CurrentTime)))
-				time.Sleep(400 * time.Millisecond)
-				_ = m.VLC.Resume()
-			}
-		}
+	player, err := m.getPlayer()
+	if err != nil {
+		return err
+	}
 
-		return nil
-	case "mpc-hc":
-		err := m.MpcHc.Start()
-		if err != nil {
-			m.Logger.Error().Err(err).Msg("media player: Could not start media player using MPC-HC")
-			return fmt.Errorf("could not start MPC-HC, %w", err)
-		}
-		_, err = m.MpcHc.OpenAndPlay(path)
-		if err != nil {
-			m.Logger.Error().Err(err).Msg("media player: Could not open and play video using MPC-HC")
-			return fmt.Errorf("could not open and play video, %w", err)
-		}
+	lastWatched := m.continuityManager.GetExternalPlayerEpisodeWatchHistoryItem(path, false, 0, 0)
 
-		if m.continuityManager.GetSettings().WatchContinuityEnabled {
-			if lastWatched.Found {
-				time.Sleep(400 * time.Millisecond)
-				_ = m.MpcHc.Pause()
-				time.Sleep(400 * time.Millisecond)
-				_ = m.MpcHc.Seek(int(lastWatched.Item.CurrentTime))
-				time.Sleep(400 * time.Millisecond)
-				_ = m.MpcHc.Play()
-			}
-		}
+	// External subtitle files next to the video (e.g. "Subs/" folders of batch releases)
+	subtitlePaths := lo.Map(m.getExternalSubtitles(path), func(sub *filesystem.ExternalSubtitle, _ int) string {
+		return sub.Path
+	})
+	if len(subtitlePaths) > 0 {
+		m.Logger.Debug().Int("count", len(subtitlePaths)).Msg("media player: Loading external subtitle files")
+	}
 
-		return nil
-	case "mpv":
-		if m.continuityManager.GetSettings().WatchContinuityEnabled {
-			var args []string
-			if lastWatched.Found {
-				//args = append(args, "--no-resume-playback", fmt.Sprintf("--start=+%d", int(lastWatched.Item.CurrentTime)))
-				args = append(args, "--no-resume-playback")
-			}
-			err := m.Mpv.OpenAndPlay(path, args...)
-			if err != nil {
-				m.Logger.Error().Err(err).Msg("media player: Could not open and play video using MPV")
-				return fmt.Errorf("could not open and play video, %w", err)
-			}
-			if lastWatched.Found {
-				_ = m.Mpv.SeekTo(lastWatched.Item.CurrentTime)
-			}
-		} else {
-			err := m.Mpv.OpenAndPlay(path)
-			if err != nil {
-				m.Logger.Error().Err(err).Msg("media player: Could not open and play video using MPV")
-				return fmt.Errorf("could not open and play video, %w", err)
-			}
-			if lastWatched.Found {
-				_ = m.Mpv.SeekTo(lastWatched.Item.CurrentTime)
-			}
-		}
+	err = player.Start()
+	if err != nil {
+		m.Logger.Error().Err(err).Str("player", m.Default).Msg("media player: Could not start media player")
+		return err
+	}
 
-		return nil
-	case "iina":
-		if m.continuityManager.GetSettings().WatchContinuityEnabled {
-			var args []string
-			if lastWatched.Found {
-				//args = append(args, "--mpv-no-resume-playback", fmt.Sprintf("--mpv-start=+%d", int(lastWatched.Item.CurrentTime)))
-				args = append(args, "--mpv-no-resume-playback")
-			}
-			err := m.Iina.OpenAndPlay(path, args...)
-			if err != nil {
-				m.Logger.Error().Err(err).Msg("media player: Could not open and play video using IINA")
-				return fmt.Errorf("could not open and play video, %w", err)
-			}
-			if lastWatched.Found {
-				_ = m.Iina.SeekTo(lastWatched.Item.CurrentTime)
-			}
-		} else {
-			err := m.Iina.OpenAndPlay(path)
-			if err != nil {
-				m.Logger.Error().Err(err).Msg("media player: Could not open and play video using IINA")
-				return fmt.Errorf("could not open and play video, %w", err)
-			}
-			if lastWatched.Found {
-				_ = m.Iina.SeekTo(lastWatched.Item.CurrentTime)
-			}
-		}
+	opts := &PlayOptions{
+		Path:          path,
+		SubtitlePaths: subtitlePaths,
+		Tracks:        m.trackPreferences.GetPlayerTracks(m.trackPreferences.GetMediaIdByPath(path), path),
+	}
+	if lastWatched.Found {
+		opts.StartTime = lastWatched.Item.CurrentTime
+	}
 
-		return nil
-	default:
-		return errors.New("no default media player set")
+	err = player.Play(opts)
+	if err != nil {
+		m.Logger.Error().Err(err).Str("player", m.Default).Msg("media player: Could not open and play video")
+		return fmt.Errorf("could not open and play video, %w", err)
 	}
 
+	return nil
 }
 
 func (m *Repository) Pause() error {
-	switch m.Default {
-	case "vlc":
-		return m.VLC.Pause()
-	case "mpc-hc":
-		return m.MpcHc.Pause()
-	case "mpv":
-		return m.Mpv.Pause()
-	case "iina":
-		return m.Iina.Pause()
-	default:
-		return errors.New("no default media player set")
+	player, err := m.getPlayer()
+	if err != nil {
+		return err
 	}
+	return player.Pause()
 }
 
 func (m *Repository) Resume() error {
-	switch m.Default {
-	case "vlc":
-		return m.VLC.Resume()
-	case "mpc-hc":
-		return m.MpcHc.Play()
-	case "mpv":
-		return m.Mpv.Resume()
-	case "iina":
-		return m.Iina.Resume()
-	default:
-		return errors.New("no default media player set")
+	player, err := m.getPlayer()
+	if err != nil {
+		return err
 	}
+	return player.Resume()
 }
 
 func (m *Repository) Seek(seconds float64) error {
-	switch m.Default {
-	case "vlc":
-		return m.VLC.Seek(fmt.Sprintf("%d", int(seconds)))
-	case "mpc-hc":
-		return m.MpcHc.Seek(int(seconds))
-	case "mpv":
-		return m.Mpv.Seek(seconds)
-	case "iina":
-		return m.Iina.Seek(seconds)
-	default:
-		return errors.New("no default media player set")
+	player, err := m.getPlayer()
+	if err != nil {
+		return err
 	}
+	return player.Seek(seconds)
 }
 
 func (m *Repository) Stream(streamUrl string, episode int, mediaId int, windowTitle string) error {
 
 	m.Logger.Debug().Str("streamUrl", streamUrl).Msg("media player: Stream requested")
-	var err error
-
-	switch m.Default {
-	case "vlc":
-		err = m.VLC.Start()
-	case "mpc-hc":
-		err = m.MpcHc.Start()
-		_, err = m.MpcHc.OpenAndPlay(streamUrl)
-	case "mpv":
-		// MPV does not need to be started
-	case "iina":
-		// IINA does not need to be started
-	default:
-		return errors.New("no default media player set")
+
+	player, err := m.getPlayer()
+	if err != nil {
+		return err
 	}
 
+	err = player.Start()
 	if err != nil {
 		m.Logger.Error().Err(err).Msg("media player: Could not start media player for stream")
 		return fmt.Errorf("could not open media player, %w", err)
@@ -414,65 +450,16 @@ func (m *Repository) Stream(streamUrl string, episode int, mediaId int, windowTi
 
 	lastWatched := m.continuityManager.GetExternalPlayerEpisodeWatchHistoryItem("", true, episode, mediaId)
 
-	switch m.Default {
-	case "vlc":
-		err = m.VLC.AddAndPlay(streamUrl)
-
-		if m.continuityManager.GetSettings().WatchContinuityEnabled {
-			if lastWatched.Found {
-				time.Sleep(400 * time.Millisecond)
-				_ = m.VLC.ForcePause()
-				time.Sleep(400 * time.Millisecond)
-				_ = m.VLC.Seek(fmt.Sprintf("%d", int(lastWatched.Item.CurrentTime)))
-				time.Sleep(400 * time.Millisecond)
-				_ = m.VLC.Resume()
-			}
-		}
-
-	case "mpc-hc":
-		_, err = m.MpcHc.OpenAndPlay(streamUrl)
-
-		if m.continuityManager.GetSettings().WatchContinuityEnabled {
-			if lastWatched.Found {
-				time.Sleep(400 * time.Millisecond)
-				_ = m.MpcHc.Pause()
-				time.Sleep(400 * time.Millisecond)
-				_ = m.MpcHc.Seek(int(lastWatched.Item.CurrentTime))
-				time.Sleep(400 * time.Millisecond)
-				_ = m.MpcHc.Play()
-			}
-		}
-
-	case "mpv":
-		args := []string{}
-		if windowTitle != "" {
-			args = append(args, fmt.Sprintf("--title=%q", windowTitle))
-		}
-		if m.continuityManager.GetSettings().WatchContinuityEnabled {
-			err = m.Mpv.OpenAndPlay(streamUrl, args...)
-			if lastWatched.Found {
-				_ = m.Mpv.SeekTo(lastWatched.Item.CurrentTime)
-			}
-		} else {
-			err = m.Mpv.OpenAndPlay(streamUrl, args...)
-		}
-
-	case "iina":
-		args := []string{}
-		if windowTitle != "" {
-			args = append(args, fmt.Sprintf("--mpv-title=%q", windowTitle))
-		}
-		if m.continuityManager.GetSettings().WatchContinuityEnabled {
-			err = m.Iina.OpenAndPlay(streamUrl, args...)
-			if lastWatched.Found {
-				_ = m.Iina.SeekTo(lastWatched.Item.CurrentTime)
-			}
-		} else {
-			err = m.Iina.OpenAndPlay(streamUrl, args...)
-		}
-
+	opts := &StreamOptions{
+		Url:         streamUrl,
+		WindowTitle: windowTitle,
+		Tracks:      m.trackPreferences.GetPlayerTracks(mediaId, ""),
+	}
+	if lastWatched.Found {
+		opts.StartTime = lastWatched.Item.CurrentTime
 	}
 
+	err = player.Stream(opts)
 	if err != nil {
 		m.Logger.Error().Err(err).Msg("media player: Could not open and play stream")
 		return fmt.Errorf("could not open and play stream, %w", err)
@@ -491,9 +478,9 @@ func (m *Repository) Cancel() {
 	} else {
 		m.Logger.Debug().Msg("media player: Cancel request received, but no context found")
 	}
-	// Close MPV if it's the default player
-	if m.Default == "mpv" {
-		m.Mpv.CloseAll()
+	// Close the media player if it's managed by Seanime
+	if player, err := m.getPlayer(); err == nil {
+		player.Close()
 	}
 	m.mu.Unlock()
 }
@@ -506,9 +493,9 @@ func (m *Repository) Stop() {
 		m.cancel()
 		m.cancel = nil
 		m.trackingStopped("Tracking stopped")
-		// Close MPV if it's the default player
-		if m.Default == "mpv" {
-			go m.Mpv.CloseAll()
+		// Close the media player if it's managed by Seanime
+		if player, err := m.getPlayer(); err == nil {
+			go player.Close()
 		}
 	}
 	m.mu.Unlock()
@@ -516,6 +503,11 @@ func (m *Repository) Stop() {
 
 // StartTrackingTorrentStream will start tracking media player status for torrent streaming
 func (m *Repository) StartTrackingTorrentStream() {
+	if !m.canTrackProgress() {
+		m.Logger.Debug().Str("player", m.Default).Msg("media player: Media player does not support progress tracking")
+		return
+	}
+
 	m.mu.Lock()
 	// If a previous context exists, cancel it
 	if m.cancel != nil {
@@ -632,7 +624,7 @@ func (m *Repository) StartTrackingTorrentStream() {
 				}
 
 				trackingStarted = true
-				ok := m.processStreamStatus(m.Default, status)
+				ok := m.processStreamStatus(status)
 
 				if !ok {
 					m.streamingTrackingRetry("Failed to get player status")
@@ -671,6 +663,11 @@ func (m *Repository) StartTrackingTorrentStream() {
 // StartTracking will start tracking media player status.
 // This method is safe to call multiple times -- it will cancel the previous context and start a new one.
 func (m *Repository) StartTracking() {
+	if !m.canTrackProgress() {
+		m.Logger.Debug().Str("player", m.Default).Msg("media player: Media player does not support progress tracking")
+		return
+	}
+
 	m.mu.Lock()
 	// If a previous context exists, cancel it
 	if m.cancel != nil {
@@ -766,7 +763,7 @@ func (m *Repository) StartTracking() {
 
 				gotFirstStatus = true
 
-				ok := m.processStatus(m.Default, status)
+				ok := m.processStatus(status)
 
 				if !ok {
 					m.trackingRetry("Failed to get player status")
@@ -875,167 +872,37 @@ func (m *Repository) streamingPlaybackStatus(status *PlaybackStatus) {
 	})
 }
 
-func (m *Repository) getStatus() (interface{}, error) {
-	switch m.Default {
-	case "vlc":
-		return m.VLC.GetStatus()
-	case "mpc-hc":
-		return m.MpcHc.GetVariables()
-	case "mpv":
-		return m.Mpv.GetPlaybackStatus()
-	case "iina":
-		return m.Iina.GetPlaybackStatus()
-	}
-	return nil, errors.New("unsupported media player")
+func (m *Repository) getStatus() (*PlayerStatus, error) {
+	player, err := m.getPlayer()
+	if err != nil {
+		return nil, err
+	}
+	return player.GetStatus()
 }
 
-func (m *Repository) processStatus(player string, status interface{}) bool {
+func (m *Repository) processStatus(status *PlayerStatus) bool {
 	m.currentPlaybackStatus.PlaybackType = PlaybackTypeFile
-	switch player {
-	case "vlc":
-		// Process VLC status
-		st, ok := status.(*vlc2.Status)
-		if !ok || st == nil {
-			return false
-		}
-
-		m.currentPlaybackStatus.CompletionPercentage = st.Position
-		m.currentPlaybackStatus.Playing = st.State == "playing"
-		m.currentPlaybackStatus.Filename = st.Information.Category["meta"].Filename
-		m.currentPlaybackStatus.Duration = int(st.Length * 1000)
-		m.currentPlaybackStatus.Filepath = "" // VLC does not provide the filepath
-
-		m.currentPlaybackStatus.CurrentTimeInSeconds = float64(st.Time)
-		m.currentPlaybackStatus.DurationInSeconds = float64(st.Length)
-		return true
-	case "mpc-hc":
-		// Process MPC-HC status
-		st, ok := status.(*mpchc2.Variables)
-		if !ok || st == nil || st.Duration == 0 {
-			return false
-		}
-
-		m.currentPlaybackStatus.CompletionPercentage = st.Position / st.Duration
-		m.currentPlaybackStatus.Playing = st.State == 2
-		m.currentPlaybackStatus.Filename = st.File
-		m.currentPlaybackStatus.Duration = int(st.Duration)
-		m.currentPlaybackStatus.Filepath = st.FilePath
-
-		m.currentPlaybackStatus.CurrentTimeInSeconds = st.Position / 1000
-		m.currentPlaybackStatus.DurationInSeconds = st.Duration / 1000
-
-		return true
-	case "mpv":
-		// Process MPV status
-		st, ok := status.(*mpv.Playback)
-		if !ok || st == nil || st.Duration == 0 || st.IsRunning == false {
-			return false
-		}
-
-		m.currentPlaybackStatus.CompletionPercentage = st.Position / st.Duration
-		m.currentPlaybackStatus.Playing = !st.Paused
-		m.currentPlaybackStatus.Filename = st.Filename
-		m.currentPlaybackStatus.Duration = int(st.Duration)
-		m.currentPlaybackStatus.Filepath = st.Filepath
-
-		m.currentPlaybackStatus.CurrentTimeInSeconds = st.Position
-		m.currentPlaybackStatus.DurationInSeconds = st.Duration
-
-		return true
-	case "iina":
-		// Process IINA status
-		st, ok := status.(*iina.Playback)
-		if !ok || st == nil || st.Duration == 0 || st.IsRunning == false {
-			return false
-		}
-
-		m.currentPlaybackStatus.CompletionPercentage = st.Position / st.Duration
-		m.currentPlaybackStatus.Playing = !st.Paused
-		m.currentPlaybackStatus.Filename = st.Filename
-		m.currentPlaybackStatus.Duration = int(st.Duration)
-		m.currentPlaybackStatus.Filepath = st.Filepath
-
-		m.currentPlaybackStatus.CurrentTimeInSeconds = st.Position
-		m.currentPlaybackStatus.DurationInSeconds = st.Duration
-
-		return true
-	default:
-		return false
-	}
+	return m.setCurrentPlaybackStatus(status)
 }
 
-func (m *Repository) processStreamStatus(player string, status interface{}) bool {
+func (m *Repository) processStreamStatus(status *PlayerStatus) bool {
 	m.currentPlaybackStatus.PlaybackType = PlaybackTypeStream
-	switch player {
-	case "vlc":
-		// Process VLC status
-		st, ok := status.(*vlc2.Status)
-		if !ok || st == nil {
-			return false
-		}
-
-		m.currentPlaybackStatus.CompletionPercentage = st.Position
-		m.currentPlaybackStatus.Playing = st.State == "playing"
-		m.currentPlaybackStatus.Filename = st.Information.Category["meta"].Filename
-		m.currentPlaybackStatus.Duration = int(st.Length * 1000)
-		m.currentPlaybackStatus.Filepath = "" // VLC does not provide the filepath
-
-		m.currentPlaybackStatus.CurrentTimeInSeconds = float64(st.Time)
-		m.currentPlaybackStatus.DurationInSeconds = float64(st.Length)
-
-		return true
-	case "mpc-hc":
-		// Process MPC-HC status
-		st, ok := status.(*mpchc2.Variables)
-		if !ok || st == nil {
-			return false
-		}
-
-		m.currentPlaybackStatus.CompletionPercentage = st.Position / st.Duration
-		m.currentPlaybackStatus.Playing = st.State == 2
-		m.currentPlaybackStatus.Filename = st.File
-		m.currentPlaybackStatus.Duration = int(st.Duration)
-		m.currentPlaybackStatus.Filepath = st.FilePath
-
-		m.currentPlaybackStatus.CurrentTimeInSeconds = st.Position / 1000
-		m.currentPlaybackStatus.DurationInSeconds = st.Duration / 1000
-
-		return true
-	case "mpv":
-		// Process MPV status
-		st, ok := status.(*mpv.Playback)
-		if !ok || st == nil || st.Duration == 0 || st.IsRunning == false {
-			return false
-		}
-
-		m.currentPlaybackStatus.CompletionPercentage = st.Position / st.Duration
-		m.currentPlaybackStatus.Playing = !st.Paused
-		m.currentPlaybackStatus.Filename = st.Filename
-		m.currentPlaybackStatus.Duration = int(st.Duration)
-		m.currentPlaybackStatus.Filepath = st.Filepath
-
-		m.currentPlaybackStatus.CurrentTimeInSeconds = st.Position
-		m.currentPlaybackStatus.DurationInSeconds = st.Duration
+	return m.setCurrentPlaybackStatus(status)
+}
 
-		return true
-	case "iina":
-		// Process IINA status
-		st, ok := status.(*iina.Playback)
-		if !ok || st == nil || st.Duration == 0 || st.IsRunning == false {
-			return false
-		}
+func (m *Repository) setCurrentPlaybackStatus(status *PlayerStatus) bool {
+	if status == nil {
+		return false
+	}
 
-		m.currentPlaybackStatus.CompletionPercentage = st.Position / st.Duration
-		m.currentPlaybackStatus.Playing = !st.Paused
-		m.currentPlaybackStatus.Filename = st.Filename
-		m.currentPlaybackStatus.Duration = int(st.Duration)
-		m.currentPlaybackStatus.Filepath = st.Filepath
+	m.currentPlaybackStatus.CompletionPercentage = status.CompletionPercentage
+	m.currentPlaybackStatus.Playing = status.Playing
+	m.currentPlaybackStatus.Filename = status.Filename
+	m.currentPlaybackStatus.Duration = status.Duration
+	m.currentPlaybackStatus.Filepath = status.Filepath
 
-		m.currentPlaybackStatus.CurrentTimeInSeconds = st.Position
-		m.currentPlaybackStatus.DurationInSeconds = st.Duration
+	m.currentPlaybackStatus.CurrentTimeInSeconds = status.CurrentTimeInSeconds
+	m.currentPlaybackStatus.DurationInSeconds = status.DurationInSeconds
 
-		return true
-	default:
-		return false
-	}
+	return true
 }
@@ -3,13 +3,16 @@ package mpv
 import (
 	"bufio"
 	"bytes"
+	"cmp"
 	"context"
 	"errors"
+	"math"
 	"os/exec"
 	"runtime"
 	"seanime/internal/mediaplayers/mpvipc"
 	"seanime/internal/util"
 	"seanime/internal/util/result"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -179,11 +182,43 @@ func (m *Mpv) launchPlayer(idle bool, filePath string, args ...string) error {
 	return nil
 }
 
-func (m *Mpv) replaceFile(filePath string) error {
+// trackOptions are the launch options selecting the tracks of the file.
+// Launch options are ignored when the player is already running, so these are set as properties instead.
+// The options that are not passed are reset so that the tracks selected for the previous file are not kept.
+var trackOptions = map[string]string{
+	"alang": "",
+	"slang": "",
+	"aid":   "auto",
+	"sid":   "auto",
+}
+
+func (m *Mpv) replaceFile(filePath string, subtitlePaths []string, args []string) error {
 	m.Logger.Debug().Msg("mpv: Replacing file")
 
 	if m.conn != nil && !m.conn.IsClosed() {
-		_, err := m.conn.Call("loadfile", filePath, "replace")
+		for name, reset := range trackOptions {
+			value, found := getOptionArg(args, name)
+			if !found {
+				value = reset
+			}
+			if err := m.conn.Set(name, value); err != nil {
+				m.Logger.Warn().Err(err).Str("option", name).Msg("mpv: Failed to set track option")
+			}
+		}
+
+		// The external subtitle files are loaded with the next file, clear the ones from the previous file
+		_, err := m.conn.Call("change-list", "sub-files", "clr", "")
+		if err != nil {
+			m.Logger.Warn().Err(err).Msg("mpv: Failed to clear external subtitle files")
+		}
+		for _, subtitlePath := range subtitlePaths {
+			_, err = m.conn.Call("change-list", "sub-files", "append", subtitlePath)
+			if err != nil {
+				m.Logger.Warn().Err(err).Str("path", subtitlePath).Msg("mpv: Failed to add external subtitle file")
+			}
+		}
+
+		_, err = m.conn.Call("loadfile", filePath, "replace")
 		if err != nil {
 			return err
 		}
@@ -192,11 +227,26 @@ func (m *Mpv) replaceFile(filePath string) error {
 	return nil
 }
 
+// getOptionArg returns the value of a launch option, e.g. "jpn,ja" for "--alang=jpn,ja".
+func getOptionArg(args []string, name string) (string, bool) {
+	for _, arg := range args {
+		if value, found := strings.CutPrefix(arg, "--"+name+"="); found {
+			return value, true
+		}
+	}
+	return "", false
+}
+
 func (m *Mpv) Exited() chan struct{} {
 	return m.exitedCh
 }
 
 func (m *Mpv) OpenAndPlay(filePath string, args ...string) error {
+	return m.OpenAndPlayWithSubtitles(filePath, nil, args...)
+}
+
+// OpenAndPlayWithSubtitles plays the file and loads the given external subtitle files.
+func (m *Mpv) OpenAndPlayWithSubtitles(filePath string, subtitlePaths []string, args ...string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -206,9 +256,12 @@ func (m *Mpv) OpenAndPlay(filePath string, args ...string) error {
 	var err error
 	if m.conn != nil && !m.conn.IsClosed() {
 		// Launch player or replace file
-		err = m.replaceFile(filePath)
+		err = m.replaceFile(filePath, subtitlePaths, args)
 	} else {
 		// Launch player
+		for _, subtitlePath := range subtitlePaths {
+			args = append(args, "--sub-files-append="+subtitlePath)
+		}
 		err = m.launchPlayer(false, filePath, args...)
 	}
 	if err != nil {
@@ -655,3 +708,76 @@ func (m *Mpv) publishDone() {
 		return true
 	})
 }
+
+// SkipSegment is a skippable range of the current file (opening, ending, etc.)
+type SkipSegment struct {
+	Title string
+	Start float64
+	End   float64
+}
+
+// AddSkipSegments adds chapters at the start and end of the segments so that they can be skipped with the chapter keybindings.
+// Chapters of the file that already mark a segment are kept as is.
+func (m *Mpv) AddSkipSegments(segments []*SkipSegment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.conn == nil || m.conn.IsClosed() {
+		return errors.New("mpv is not running")
+	}
+
+	type chapter struct {
+		title string
+		time  float64
+	}
+
+	chapters := make([]chapter, 0)
+	if res, err := m.conn.Get("chapter-list"); err == nil {
+		if list, ok := res.([]interface{}); ok {
+			for _, item := range list {
+				if c, ok := item.(map[string]interface{}); ok {
+					title, _ := c["title"].(string)
+					t, _ := c["time"].(float64)
+					chapters = append(chapters, chapter{title: title, time: t})
+				}
+			}
+		}
+	}
+
+	duration := m.Playback.Duration
+	if res, err := m.conn.Get("duration"); err == nil {
+		if d, ok := res.(float64); ok {
+			duration = d
+		}
+	}
+
+	addChapter := func(title string, t float64) {
+		for _, c := range chapters {
+			if math.Abs(c.time-t) < 1 {
+				return
+			}
+		}
+		chapters = append(chapters, chapter{title: title, time: t})
+	}
+
+	if len(chapters) == 0 {
+		addChapter("Episode", 0)
+	}
+	for _, segment := range segments {
+		addChapter(segment.Title, segment.Start)
+		if duration == 0 || segment.End < duration-1 {
+			addChapter("Episode", segment.End)
+		}
+	}
+
+	slices.SortFunc(chapters, func(a, b chapter) int {
+		return cmp.Compare(a.time, b.time)
+	})
+
+	list := make([]map[string]interface{}, 0, len(chapters))
+	for _, c := range chapters {
+		list = append(list, map[string]interface{}{"title": c.title, "time": c.time})
+	}
+
+	return m.conn.Set("chapter-list", list)
+}
@@ -0,0 +1,249 @@
+package upnp
+
+// UPnP/DLNA MediaRenderer control through the AVTransport service.
+// http://upnp.org/specs/av/UPnP-av-AVTransport-v1-Service.pdf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"mime"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/huin/goupnp"
+	"github.com/huin/goupnp/dcps/av1"
+	"github.com/rs/zerolog"
+)
+
+const (
+	StatePlaying       = "PLAYING"
+	StatePaused        = "PAUSED_PLAYBACK"
+	StateStopped       = "STOPPED"
+	StateTransitioning = "TRANSITIONING"
+	StateNoMedia       = "NO_MEDIA_PRESENT"
+)
+
+const mediaRendererDeviceType = "urn:schemas-upnp-org:device:MediaRenderer:1"
+
+type (
+	// Renderer controls a DLNA MediaRenderer (TV, receiver, etc.).
+	Renderer struct {
+		// Location is the URL of the device description, e.g. "http://192.168.1.20:1400/xml/device_description.xml"
+		Location string
+		Logger   *zerolog.Logger
+
+		mu        sync.Mutex
+		transport *av1.AVTransport1
+	}
+
+	// Status is the transport status of the renderer.
+	Status struct {
+		// One of the State constants
+		State string
+		// Position in seconds
+		Position float64
+		// Duration in seconds, 0 if unknown
+		Duration float64
+		// URI of the current media
+		URI string
+	}
+
+	// DiscoveredRenderer is a renderer found on the local network.
+	DiscoveredRenderer struct {
+		Name         string `json:"name"`
+		Location     string `json:"location"`
+		Manufacturer string `json:"manufacturer"`
+		Model        string `json:"model"`
+	}
+)
+
+func New(logger *zerolog.Logger, location string) *Renderer {
+	return &Renderer{
+		Location: location,
+		Logger:   logger,
+	}
+}
+
+// Connect fetches the device description of the renderer.
+// It does nothing if the renderer is already connected.
+func (r *Renderer) Connect() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.transport != nil {
+		return nil
+	}
+	if r.Location == "" {
+		return errors.New("upnp: renderer location is not set")
+	}
+
+	loc, err := url.Parse(r.Location)
+	if err != nil {
+		return fmt.Errorf("upnp: invalid renderer location: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	clients, err := av1.NewAVTransport1ClientsByURLCtx(ctx, loc)
+	if err != nil {
+		return fmt.Errorf("upnp: %w", err)
+	}
+	if len(clients) == 0 {
+		return errors.New("upnp: device does not support AVTransport")
+	}
+
+	r.transport = clients[0]
+	r.Logger.Debug().Str("location", r.Location).Str("name", clients[0].RootDevice.Device.FriendlyName).Msg("upnp: Connected to renderer")
+	return nil
+}
+
+func (r *Renderer) getTransport() (*av1.AVTransport1, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.transport == nil {
+		return nil, errors.New("upnp: renderer is not connected")
+	}
+	return r.transport, nil
+}
+
+// SetURI loads the media at the given URL.
+//   - title: Title displayed by the renderer, its extension is used to guess the content type
+func (r *Renderer) SetURI(uri string, title string) error {
+	transport, err := r.getTransport()
+	if err != nil {
+		return err
+	}
+
+	// Some renderers refuse to play while media is loaded
+	_ = transport.Stop(0)
+
+	r.Logger.Debug().Str("uri", uri).Msg("upnp: Setting media")
+	return transport.SetAVTransportURI(0, uri, didlMetadata(uri, title))
+}
+
+func (r *Renderer) Play() error {
+	transport, err := r.getTransport()
+	if err != nil {
+		return err
+	}
+	return transport.Play(0, "1")
+}
+
+func (r *Renderer) Pause() error {
+	transport, err := r.getTransport()
+	if err != nil {
+		return err
+	}
+	return transport.Pause(0)
+}
+
+func (r *Renderer) Stop() error {
+	transport, err := r.getTransport()
+	if err != nil {
+		return err
+	}
+	return transport.Stop(0)
+}
+
+// Seek seeks to the given position in seconds.
+func (r *Renderer) Seek(seconds float64) error {
+	transport, err := r.getTransport()
+	if err != nil {
+		return err
+	}
+	return transport.Seek(0, "REL_TIME", formatTime(seconds))
+}
+
+func (r *Renderer) GetStatus() (*Status, error) {
+	transport, err := r.getTransport()
+	if err != nil {
+		return nil, err
+	}
+
+	state, _, _, err := transport.GetTransportInfo(0)
+	if err != nil {
+		return nil, err
+	}
+
+	_, duration, _, uri, relTime, _, _, _, err := transport.GetPositionInfo(0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Status{
+		State:    state,
+		Position: parseTime(relTime),
+		Duration: parseTime(duration),
+		URI:      uri,
+	}, nil
+}
+
+// Discover returns the renderers found on the local network.
+func Discover(ctx context.Context) ([]*DiscoveredRenderer, error) {
+	devices, err := goupnp.DiscoverDevicesCtx(ctx, mediaRendererDeviceType)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]*DiscoveredRenderer, 0, len(devices))
+	for _, d := range devices {
+		if d.Err != nil || d.Root == nil {
+			continue
+		}
+		ret = append(ret, &DiscoveredRenderer{
+			Name:         d.Root.Device.FriendlyName,
+			Location:     d.Location.String(),
+			Manufacturer: d.Root.Device.Manufacturer,
+			Model:        d.Root.Device.ModelName,
+		})
+	}
+	return ret, nil
+}
+
+// didlMetadata returns the DIDL-Lite metadata of a video item.
+// Renderers use it to display the title and to check that they support the content type.
+func didlMetadata(uri string, title string) string {
+	contentType := mime.TypeByExtension(strings.ToLower(filepath.Ext(title)))
+	switch {
+	case strings.HasSuffix(strings.ToLower(title), ".mkv"):
+		contentType = "video/x-matroska"
+	case contentType == "":
+		contentType = "*"
+	}
+
+	return `<DIDL-Lite xmlns="urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:upnp="urn:schemas-upnp-org:metadata-1-0/upnp/">` +
+		`<item id="0" parentID="-1" restricted="1">` +
+		`<dc:title>` + html.EscapeString(title) + `</dc:title>` +
+		`<upnp:class>object.item.videoItem</upnp:class>` +
+		`<res protocolInfo="http-get:*:` + contentType + `:*">` + html.EscapeString(uri) + `</res>` +
+		`</item></DIDL-Lite>`
+}
+
+// parseTime parses a duration in the "H+:MM:SS[.F+]" format, e.g. "0:23:41.500".
+// It returns 0 for invalid or unknown values ("NOT_IMPLEMENTED").
+func parseTime(s string) float64 {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) != 3 {
+		return 0
+	}
+	hours, err1 := strconv.Atoi(parts[0])
+	minutes, err2 := strconv.Atoi(parts[1])
+	seconds, err3 := strconv.ParseFloat(parts[2], 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return 0
+	}
+	return float64(hours*3600+minutes*60) + seconds
+}
+
+// formatTime formats seconds in the "H:MM:SS" format.
+func formatTime(seconds float64) string {
+	s := int(seconds)
+	return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+}
@@ -0,0 +1,35 @@
+package upnp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTime(t *testing.T) {
+	tests := map[string]float64{
+		"0:23:41":         1421,
+		"01:02:03.500":    3723.5,
+		"10:00:00":        36000,
+		"NOT_IMPLEMENTED": 0,
+		"":                0,
+	}
+
+	for input, expected := range tests {
+		assert.Equal(t, expected, parseTime(input), input)
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	assert.Equal(t, "0:00:00", formatTime(0))
+	assert.Equal(t, "0:23:41", formatTime(1421.9))
+	assert.Equal(t, "1:02:03", formatTime(3723))
+}
+
+func TestDidlMetadata(t *testing.T) {
+	metadata := didlMetadata("http://192.168.1.10:43211/api/v1/mediastream/file?path=abc&token=def", "Show & Co - 01.mkv")
+
+	assert.Contains(t, metadata, "<dc:title>Show &amp; Co - 01.mkv</dc:title>")
+	assert.Contains(t, metadata, `protocolInfo="http-get:*:video/x-matroska:*"`)
+	assert.Contains(t, metadata, "?path=abc&amp;token=def</res>")
+}
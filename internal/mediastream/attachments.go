@@ -2,10 +2,18 @@ package mediastream
 
 import (
 	"errors"
+	"fmt"
+	"math"
 	"net/url"
+	"os"
 	"path/filepath"
 	"seanime/internal/events"
 	"seanime/internal/mediastream/videofile"
+	"seanime/internal/subtitles"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
 
 	"github.com/labstack/echo/v4"
 )
@@ -39,9 +47,72 @@ func (r *Repository) ServeEchoExtractedSubtitles(c echo.Context) error {
 
 	r.logger.Trace().Msgf("mediastream: Serving subtitles from %s", retPath)
 
+	// Any extracted text track can be fetched as WebVTT (e.g. "0.vtt") or as an HLS subtitle playlist (e.g. "0.m3u8")
+	switch filepath.Ext(subFilePath) {
+	case ".m3u8":
+		return c.Blob(200, "application/vnd.apple.mpegurl", []byte(getSubtitlePlaylist(mediaContainer, strings.TrimSuffix(subFilePath, ".m3u8")+".vtt")))
+	case ".vtt":
+		if _, err := os.Stat(filepath.Join(retPath, subFilePath)); err == nil {
+			break
+		}
+		content, err := r.getSubtitlesAsWebVTT(mediaContainer, strings.TrimSuffix(subFilePath, ".vtt"))
+		if err != nil {
+			return err
+		}
+		return c.Blob(200, "text/vtt; charset=utf-8", []byte(content))
+	}
+
+	// External subtitle files are served from their original location
+	if index, err := strconv.ParseUint(strings.TrimSuffix(subFilePath, filepath.Ext(subFilePath)), 10, 32); err == nil {
+		if path, ok := mediaContainer.externalSubtitles[uint32(index)]; ok {
+			return c.File(path)
+		}
+	}
+
 	return c.File(filepath.Join(retPath, subFilePath))
 }
 
+// getSubtitlesAsWebVTT converts an extracted subtitle track to WebVTT.
+func (r *Repository) getSubtitlesAsWebVTT(mediaContainer *MediaContainer, indexStr string) (string, error) {
+	index, err := strconv.ParseUint(indexStr, 10, 32)
+	if err != nil {
+		return "", errors.New("invalid subtitle index")
+	}
+
+	if _, found := lo.Find(mediaContainer.MediaInfo.BitmapSubtitles, func(s videofile.Subtitle) bool { return s.Index == uint32(index) }); found {
+		return "", subtitles.ErrBitmapFormat
+	}
+
+	sub, found := lo.Find(mediaContainer.MediaInfo.Subtitles, func(s videofile.Subtitle) bool { return s.Index == uint32(index) })
+	if !found || sub.Extension == nil {
+		return "", errors.New("subtitle track not found")
+	}
+
+	data, err := os.ReadFile(mediaContainer.getSubtitlePath(r.cacheDir, uint32(index), *sub.Extension))
+	if err != nil {
+		return "", err
+	}
+
+	r.logger.Trace().Uint64("index", index).Str("from", *sub.Extension).Msg("mediastream: Converting subtitles to WebVTT")
+
+	return subtitles.Convert(string(data), subtitles.FormatFromExtension(*sub.Extension), subtitles.FormatWebVTT)
+}
+
+// getSubtitlePlaylist returns an HLS playlist containing a single WebVTT segment spanning the whole media.
+func getSubtitlePlaylist(mediaContainer *MediaContainer, vttFilename string) string {
+	duration := mediaContainer.MediaInfo.Duration
+
+	playlist := "#EXTM3U\n"
+	playlist += "#EXT-X-VERSION:3\n"
+	playlist += fmt.Sprintf("#EXT-X-TARGETDURATION:%d\n", int(math.Ceil(float64(duration))))
+	playlist += "#EXT-X-MEDIA-SEQUENCE:0\n"
+	playlist += "#EXT-X-PLAYLIST-TYPE:VOD\n"
+	playlist += fmt.Sprintf("#EXTINF:%.6f,\n", duration)
+	playlist += "./" + vttFilename + "\n"
+	playlist += "#EXT-X-ENDLIST\n"
+	return playlist
+}
+
 func (r *Repository) ServeEchoExtractedAttachments(c echo.Context) error {
 	if !r.IsInitialized() {
 		r.wsEventManager.SendEvent(events.MediastreamShutdownStream, "Module not initialized")
@@ -3,10 +3,16 @@ package mediastream
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
+	"seanime/internal/library/filesystem"
 	"seanime/internal/mediastream/videofile"
+	"seanime/internal/subtitles"
+	"seanime/internal/trackpreference"
 	"seanime/internal/util/result"
+	"slices"
 
 	"github.com/rs/zerolog"
+	"github.com/samber/lo"
 	"github.com/samber/mo"
 )
 
@@ -24,6 +30,7 @@ type (
 		currentMediaContainer mo.Option[*MediaContainer] // The current media being played.
 		repository            *Repository
 		mediaContainers       *result.Map[string, *MediaContainer] // Temporary cache for the media containers.
+		burnInSubtitles       *result.Map[string, uint32]          // The subtitle track burned into the video, keyed by client ID.
 	}
 
 	PlaybackState struct {
@@ -36,6 +43,16 @@ type (
 		StreamType StreamType           `json:"streamType"` // Tells the frontend how to play the media.
 		StreamUrl  string               `json:"streamUrl"`  // The relative endpoint to stream the media.
 		MediaInfo  *videofile.MediaInfo `json:"mediaInfo"`
+		// The index of the subtitle track burned into the video (transcode only).
+		BurnInSubtitleIndex *uint32 `json:"burnInSubtitleIndex,omitempty"`
+		// The relative endpoint of the WebVTT file referencing the seek preview thumbnails.
+		// Empty if the thumbnails have not been generated yet.
+		TrickplayUrl string `json:"trickplayUrl,omitempty"`
+		// Tracks selected by the track preference of the media, the indexes are those of MediaInfo.
+		// nil if no preference is set.
+		TrackSelection *trackpreference.Selection `json:"trackSelection,omitempty"`
+		// Paths of the external subtitle files, keyed by their index in MediaInfo.Subtitles.
+		externalSubtitles map[uint32]string
 		//Metadata  *Metadata       `json:"metadata"`
 		// todo: add more fields (e.g. metadata)
 	}
@@ -46,6 +63,7 @@ func NewPlaybackManager(repository *Repository) *PlaybackManager {
 		logger:          repository.logger,
 		repository:      repository,
 		mediaContainers: result.NewResultMap[string, *MediaContainer](),
+		burnInSubtitles: result.NewResultMap[string, uint32](),
 	}
 }
 
@@ -53,6 +71,7 @@ func (p *PlaybackManager) KillPlayback() {
 	p.logger.Debug().Msg("mediastream: Killing playback")
 	if p.currentMediaContainer.IsPresent() {
 		p.currentMediaContainer = mo.None[*MediaContainer]()
+		p.burnInSubtitles.Clear()
 		p.logger.Trace().Msg("mediastream: Removed current media container")
 	}
 }
@@ -70,6 +89,9 @@ func (p *PlaybackManager) RequestPlayback(filepath string, streamType StreamType
 		return nil, fmt.Errorf("failed to create media container: %v", err)
 	}
 
+	// The thumbnails might have been generated since the media container was cached
+	ret.TrickplayUrl = p.repository.GetTrickplayUrl(filepath)
+
 	// Set the current media container.
 	p.currentMediaContainer = mo.Some(ret)
 
@@ -96,6 +118,31 @@ func (p *PlaybackManager) PreloadPlayback(filepath string, streamType StreamType
 	return
 }
 
+// setBurnInSubtitle selects the subtitle track burned into the video for the client.
+// A nil index clears the selection. The returned media container is a copy that includes the selection,
+// the current media container is left untouched so that other clients are not affected.
+func (p *PlaybackManager) setBurnInSubtitle(clientId string, mc *MediaContainer, index *uint32) (*MediaContainer, error) {
+	if index == nil {
+		p.burnInSubtitles.Delete(clientId)
+		return mc, nil
+	}
+
+	_, isText := lo.Find(mc.MediaInfo.Subtitles, func(s videofile.Subtitle) bool { return s.Index == *index })
+	_, isBitmap := lo.Find(mc.MediaInfo.BitmapSubtitles, func(s videofile.Subtitle) bool { return s.Index == *index })
+	if !isText && !isBitmap {
+		return nil, fmt.Errorf("subtitle track %d not found", *index)
+	}
+
+	p.logger.Debug().Uint32("index", *index).Str("clientId", clientId).Msg("mediastream: Burning subtitles into the video")
+
+	p.burnInSubtitles.Set(clientId, *index)
+
+	ret := *mc
+	ret.BurnInSubtitleIndex = index
+
+	return &ret, nil
+}
+
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 // Optimize
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -145,6 +192,8 @@ func (p *PlaybackManager) newMediaContainer(filepath string, streamType StreamTy
 
 	p.logger.Debug().Msg("mediastream: Extracted attachments")
 
+	p.addExternalSubtitles(ret)
+
 	streamUrl := ""
 	switch streamType {
 	case StreamTypeDirect:
@@ -174,3 +223,54 @@ func (p *PlaybackManager) newMediaContainer(filepath string, streamType StreamTy
 
 	return
 }
+
+// addExternalSubtitles adds the subtitle files found next to the media file to the media info.
+// They are indexed after the embedded tracks so that they can be served like extracted tracks.
+func (p *PlaybackManager) addExternalSubtitles(mc *MediaContainer) {
+	externalSubs := lo.Filter(p.repository.getExternalSubtitles(mc.Filepath), func(sub *filesystem.ExternalSubtitle, _ int) bool {
+		// Bitmap subtitle files are not supported
+		return subtitles.FormatFromExtension(sub.Extension) != subtitles.FormatPGS
+	})
+	if len(externalSubs) == 0 {
+		return
+	}
+
+	// Copy the media info since it is cached
+	mediaInfo := *mc.MediaInfo
+	mediaInfo.Subtitles = slices.Clone(mediaInfo.Subtitles)
+
+	nextIndex := uint32(0)
+	for _, sub := range append(mediaInfo.Subtitles, mediaInfo.BitmapSubtitles...) {
+		nextIndex = max(nextIndex, sub.Index+1)
+	}
+
+	mc.externalSubtitles = make(map[uint32]string, len(externalSubs))
+	for _, sub := range externalSubs {
+		index := nextIndex
+		nextIndex++
+
+		mediaInfo.Subtitles = append(mediaInfo.Subtitles, videofile.Subtitle{
+			Index:      index,
+			Title:      lo.EmptyableToPtr(sub.Title),
+			Language:   lo.EmptyableToPtr(sub.Language),
+			Codec:      sub.Extension,
+			Extension:  lo.ToPtr(sub.Extension),
+			IsForced:   sub.Forced,
+			IsExternal: true,
+			Link:       lo.ToPtr(fmt.Sprintf("/%d.%s", index, sub.Extension)),
+		})
+		mc.externalSubtitles[index] = sub.Path
+	}
+
+	mc.MediaInfo = &mediaInfo
+
+	p.logger.Debug().Int("count", len(externalSubs)).Msg("mediastream: Added external subtitle files")
+}
+
+// getSubtitlePath returns the path of an extracted or external subtitle track.
+func (mc *MediaContainer) getSubtitlePath(cacheDir string, index uint32, extension string) string {
+	if path, ok := mc.externalSubtitles[index]; ok {
+		return path
+	}
+	return filepath.Join(videofile.GetFileSubsCacheDir(cacheDir, mc.Hash), fmt.Sprintf("%d.%s", index, extension))
+}
@@ -3,11 +3,13 @@ package mediastream
 import (
 	"errors"
 	"github.com/rs/zerolog"
+	"github.com/samber/lo"
 	"github.com/samber/mo"
 	"os"
 	"path/filepath"
 	"seanime/internal/database/models"
 	"seanime/internal/events"
+	"seanime/internal/library/filesystem"
 	"seanime/internal/mediastream/optimizer"
 	"seanime/internal/mediastream/transcoder"
 	"seanime/internal/mediastream/videofile"
@@ -25,6 +27,8 @@ type (
 		logger             *zerolog.Logger
 		wsEventManager     events.WSEventManagerInterface
 		fileCacher         *filecache.Cacher
+		externalSubtitles  func(path string) []*filesystem.ExternalSubtitle
+		trickplayGenerator *trickplayGenerator
 		reqMu              sync.Mutex
 		cacheDir           string // where attachments are stored
 		transcodeDir       string // where stream segments are stored
@@ -34,6 +38,9 @@ type (
 		Logger         *zerolog.Logger
 		WSEventManager events.WSEventManagerInterface
 		FileCacher     *filecache.Cacher
+		// ExternalSubtitles returns the external subtitle files of a video, e.g. the ones stored by the scanner
+		// The directory of the video is searched if it is nil
+		ExternalSubtitles func(path string) []*filesystem.ExternalSubtitle
 	}
 )
 
@@ -48,19 +55,28 @@ func NewRepository(opts *NewRepositoryOptions) *Repository {
 		transcoder:         mo.None[*transcoder.Transcoder](),
 		wsEventManager:     opts.WSEventManager,
 		fileCacher:         opts.FileCacher,
+		externalSubtitles:  opts.ExternalSubtitles,
 		mediaInfoExtractor: videofile.NewMediaInfoExtractor(opts.FileCacher, opts.Logger),
 	}
 	ret.playbackManager = NewPlaybackManager(ret)
+	ret.trickplayGenerator = newTrickplayGenerator(ret)
 
 	return ret
 }
 
+func (r *Repository) getExternalSubtitles(path string) []*filesystem.ExternalSubtitle {
+	if r.externalSubtitles != nil {
+		return r.externalSubtitles(path)
+	}
+	return filesystem.FindExternalSubtitles(path)
+}
+
 func (r *Repository) IsInitialized() bool {
 	return r.settings.IsPresent()
 }
 
 func (r *Repository) OnCleanup() {
-
+	r.trickplayGenerator.stop()
 }
 
 func (r *Repository) InitializeModules(settings *models.MediastreamSettings, cacheDir string, transcodeDir string) {
@@ -176,7 +192,9 @@ func (r *Repository) TranscoderIsInitialized() bool {
 	return r.IsInitialized() && r.transcoder.IsPresent()
 }
 
-func (r *Repository) RequestTranscodeStream(filepath string, clientId string) (ret *MediaContainer, err error) {
+// RequestTranscodeStream requests a transcode stream for the file.
+// If burnInSubtitleIndex is set, the subtitle track will be burned into the video.
+func (r *Repository) RequestTranscodeStream(filepath string, clientId string, burnInSubtitleIndex *uint32) (ret *MediaContainer, err error) {
 	r.reqMu.Lock()
 	defer r.reqMu.Unlock()
 
@@ -192,8 +210,38 @@ func (r *Repository) RequestTranscodeStream(filepath string, clientId string) (r
 	}
 
 	ret, err = r.playbackManager.RequestPlayback(filepath, StreamTypeTranscode)
+	if err != nil {
+		return nil, err
+	}
 
-	return
+	return r.playbackManager.setBurnInSubtitle(clientId, ret, burnInSubtitleIndex)
+}
+
+// getBurnInSubtitle returns the subtitle track the client burns into the video of the media container, if any.
+// Text subtitles are read from the extracted subtitles and use the fonts extracted from the attachments.
+func (r *Repository) getBurnInSubtitle(mc *MediaContainer, clientId string) *transcoder.BurnInSubtitle {
+	index, ok := r.playbackManager.burnInSubtitles.Get(clientId)
+	if !ok {
+		return nil
+	}
+
+	if _, found := lo.Find(mc.MediaInfo.BitmapSubtitles, func(s videofile.Subtitle) bool { return s.Index == index }); found {
+		return &transcoder.BurnInSubtitle{
+			Index:    index,
+			IsBitmap: true,
+		}
+	}
+
+	sub, found := lo.Find(mc.MediaInfo.Subtitles, func(s videofile.Subtitle) bool { return s.Index == index })
+	if !found || sub.Extension == nil {
+		return nil
+	}
+
+	return &transcoder.BurnInSubtitle{
+		Index:    index,
+		Path:     mc.getSubtitlePath(r.cacheDir, index, *sub.Extension),
+		FontsDir: videofile.GetFileAttCacheDir(r.cacheDir, mc.Hash),
+	}
 }
 
 func (r *Repository) RequestPreloadTranscodeStream(filepath string) (err error) {
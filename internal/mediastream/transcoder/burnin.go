@@ -0,0 +1,66 @@
+package transcoder
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BurnInSubtitle describes a subtitle track that should be burned into the video stream.
+// This is used by clients that cannot render styled (ASS/SSA) or bitmap (PGS) subtitles.
+type BurnInSubtitle struct {
+	// Index of the subtitle track in the file (0:s:Index)
+	Index uint32
+	// IsBitmap is true for PGS/VobSub subtitles, they are overlaid directly from the source file.
+	IsBitmap bool
+	// Path of the extracted subtitle file (text subtitles only)
+	Path string
+	// FontsDir is the directory containing the fonts extracted from the file's attachments
+	FontsDir string
+}
+
+// streamKey returns the key of a file stream.
+// Burning subtitles changes the video output, so each subtitle track gets its own file stream.
+func streamKey(path string, burnIn *BurnInSubtitle) string {
+	if burnIn == nil {
+		return path
+	}
+	return fmt.Sprintf("%s#sub%d", path, burnIn.Index)
+}
+
+// outDirName returns the name of the directory where the segments of a file stream are written.
+func outDirName(sha string, burnIn *BurnInSubtitle) string {
+	if burnIn == nil {
+		return sha
+	}
+	return fmt.Sprintf("%s-sub%d", sha, burnIn.Index)
+}
+
+// getSubtitlesFilter returns the filter rendering text subtitles with libass.
+// Frames keep their original timestamps (-copyts), so the subtitles stay in sync after seeking.
+func (b *BurnInSubtitle) getSubtitlesFilter() string {
+	filter := "subtitles=filename=" + escapeFilterValue(b.Path)
+	if b.FontsDir != "" {
+		filter += ":fontsdir=" + escapeFilterValue(b.FontsDir)
+	}
+	return filter
+}
+
+// escapeFilterValue escapes a filter option value so that it can be used in a filtergraph.
+// Values are unescaped twice by ffmpeg, once when parsing the filtergraph and once when parsing the filter options.
+// See https://ffmpeg.org/ffmpeg-filters.html#Notes-on-filtergraph-escaping
+func escapeFilterValue(value string) string {
+	optionReplacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`, `:`, `\:`)
+	graphReplacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`, `[`, `\[`, `]`, `\]`, `,`, `\,`, `;`, `\;`)
+	return graphReplacer.Replace(optionReplacer.Replace(value))
+}
+
+// splitBurnInFilter splits a scale/tonemap filter chain around the point where subtitles are burned in.
+// Subtitles are burned in after tonemapping, at the output resolution, and always on the CPU.
+// Chains that end with frames on the GPU (hwupload without a later hwdownload) download them first
+// and upload them back once the subtitles are rendered.
+func splitBurnInFilter(filter string) (before string, after string) {
+	if strings.LastIndex(filter, "hwupload") > strings.LastIndex(filter, "hwdownload") {
+		return filter + ",hwdownload,format=nv12", ",hwupload"
+	}
+	return filter, ""
+}
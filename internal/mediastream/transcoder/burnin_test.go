@@ -0,0 +1,54 @@
+package transcoder
+
+import (
+	"testing"
+)
+
+func TestEscapeFilterValue(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"/data/subs/0.ass", "/data/subs/0.ass"},
+		{`C:\Users\seanime\subs\0.ass`, `C\\:\\\\Users\\\\seanime\\\\subs\\\\0.ass`},
+		{"/data/[Group] Show, Part 1/0.ass", `/data/\[Group\] Show\, Part 1/0.ass`},
+		{"/data/it's/0.ass", `/data/it\\\'s/0.ass`},
+	}
+
+	for _, tt := range tests {
+		if got := escapeFilterValue(tt.value); got != tt.expected {
+			t.Errorf("escapeFilterValue(%q) = %q, want %q", tt.value, got, tt.expected)
+		}
+	}
+}
+
+func TestStreamKey(t *testing.T) {
+	if got := streamKey("/a.mkv", nil); got != "/a.mkv" {
+		t.Errorf("streamKey without burn-in = %q", got)
+	}
+	if got := streamKey("/a.mkv", &BurnInSubtitle{Index: 2}); got != "/a.mkv#sub2" {
+		t.Errorf("streamKey with burn-in = %q", got)
+	}
+}
+
+func TestSplitBurnInFilter(t *testing.T) {
+	tests := []struct {
+		filter string
+		before string
+		after  string
+	}{
+		// CPU, subtitles go after the scale/tonemap chain
+		{"scale=1280:720", "scale=1280:720", ""},
+		// GPU frames are downloaded for the subtitles and uploaded back
+		{"format=nv12|vaapi,hwupload,scale_vaapi=1280:720:format=nv12", "format=nv12|vaapi,hwupload,scale_vaapi=1280:720:format=nv12,hwdownload,format=nv12", ",hwupload"},
+		// frames already downloaded for CPU tonemapping stay on the CPU
+		{"format=p010le|cuda,hwupload,scale_cuda=1280:720:format=p010le,hwdownload,format=p010le,format=nv12", "format=p010le|cuda,hwupload,scale_cuda=1280:720:format=p010le,hwdownload,format=p010le,format=nv12", ""},
+	}
+
+	for _, tt := range tests {
+		before, after := splitBurnInFilter(tt.filter)
+		if before != tt.before || after != tt.after {
+			t.Errorf("splitBurnInFilter(%q) = (%q, %q), want (%q, %q)", tt.filter, before, after, tt.before, tt.after)
+		}
+	}
+}
@@ -24,6 +24,7 @@ type FileStream struct {
 	Out       string                             // The output path.
 	Keyframes *Keyframe                          // The keyframes of the video.
 	Info      *videofile.MediaInfo               // The media information of the file.
+	BurnIn    *BurnInSubtitle                    // The subtitle track burned into the video, if any.
 	videos    *result.Map[Quality, *VideoStream] // A map of video streams.
 	audios    *result.Map[int32, *AudioStream]   // A map of audio streams.
 	logger    *zerolog.Logger
@@ -35,12 +36,14 @@ func NewFileStream(
 	path string,
 	sha string,
 	mediaInfo *videofile.MediaInfo,
+	burnIn *BurnInSubtitle,
 	settings *Settings,
 	logger *zerolog.Logger,
 ) *FileStream {
 	ret := &FileStream{
 		Path:     path,
-		Out:      filepath.Join(settings.StreamDir, sha),
+		Out:      filepath.Join(settings.StreamDir, outDirName(sha, burnIn)),
+		BurnIn:   burnIn,
 		videos:   result.NewResultMap[Quality, *VideoStream](),
 		audios:   result.NewResultMap[int32, *AudioStream](),
 		logger:   logger,
@@ -79,6 +82,7 @@ func (fs *FileStream) Destroy() {
 // GetMaster generates the master playlist.
 func (fs *FileStream) GetMaster() string {
 	master := "#EXTM3U\n"
+	subtitleRenditions := fs.getSubtitleRenditions()
 	if fs.Info.Video != nil {
 		var transmuxQuality Quality
 		for _, quality := range Qualities {
@@ -93,10 +97,16 @@ func (fs *FileStream) GetMaster() string {
 			master += fmt.Sprintf("AVERAGE-BANDWIDTH=%d,", int(math.Min(bitrate*0.8, float64(transmuxQuality.AverageBitrate()))))
 			master += fmt.Sprintf("BANDWIDTH=%d,", int(math.Min(bitrate, float64(transmuxQuality.MaxBitrate()))))
 			master += fmt.Sprintf("RESOLUTION=%dx%d,", fs.Info.Video.Width, fs.Info.Video.Height)
-			if fs.Info.Video.MimeCodec != nil {
+			if fs.BurnIn != nil {
+				// the original quality is transcoded when subtitles are burned in
+				master += "CODECS=\"avc1.640028\","
+			} else if fs.Info.Video.MimeCodec != nil {
 				master += fmt.Sprintf("CODECS=\"%s\",", *fs.Info.Video.MimeCodec)
 			}
 			master += "AUDIO=\"audio\","
+			if subtitleRenditions != "" {
+				master += "SUBTITLES=\"subs\","
+			}
 			master += "CLOSED-CAPTIONS=NONE\n"
 			master += fmt.Sprintf("./%s/index.m3u8\n", Original)
 		}
@@ -116,6 +126,9 @@ func (fs *FileStream) GetMaster() string {
 				master += fmt.Sprintf("RESOLUTION=%dx%d,", int(aspectRatio*float32(quality.Height())+0.5), quality.Height())
 				master += fmt.Sprintf("CODECS=\"%s\",", transmuxCodec)
 				master += "AUDIO=\"audio\","
+				if subtitleRenditions != "" {
+					master += "SUBTITLES=\"subs\","
+				}
 				master += "CLOSED-CAPTIONS=NONE\n"
 				master += fmt.Sprintf("./%s/index.m3u8\n", quality)
 			}
@@ -153,9 +166,43 @@ func (fs *FileStream) GetMaster() string {
 		master += "CHANNELS=\"2\","
 		master += fmt.Sprintf("URI=\"./audio/%d/index.m3u8\"\n", audio.Index)
 	}
+	master += subtitleRenditions
 	return master
 }
 
+// getSubtitleRenditions returns the subtitle renditions of the master playlist.
+// Text subtitle tracks are served as WebVTT playlists by the subtitles endpoint (/api/v1/mediastream/subs/{index}.m3u8).
+// The track burned into the video is not listed.
+func (fs *FileStream) getSubtitleRenditions() string {
+	ret := ""
+	for _, sub := range fs.Info.Subtitles {
+		if sub.Extension == nil || *sub.Extension == "" || (fs.BurnIn != nil && !fs.BurnIn.IsBitmap && fs.BurnIn.Index == sub.Index) {
+			continue
+		}
+		ret += "#EXT-X-MEDIA:TYPE=SUBTITLES,"
+		ret += "GROUP-ID=\"subs\","
+		if sub.Language != nil {
+			ret += fmt.Sprintf("LANGUAGE=\"%s\",", *sub.Language)
+		}
+		if sub.Title != nil {
+			ret += fmt.Sprintf("NAME=\"%s\",", *sub.Title)
+		} else if sub.Language != nil {
+			ret += fmt.Sprintf("NAME=\"%s\",", *sub.Language)
+		} else {
+			ret += fmt.Sprintf("NAME=\"Subtitle %d\",", sub.Index)
+		}
+		if sub.IsDefault && fs.BurnIn == nil {
+			ret += "DEFAULT=YES,"
+		}
+		ret += "AUTOSELECT=YES,"
+		if sub.IsForced {
+			ret += "FORCED=YES,"
+		}
+		ret += fmt.Sprintf("URI=\"../subs/%d.m3u8\"\n", sub.Index)
+	}
+	return ret
+}
+
 // GetVideoIndex gets the index of a video stream of a specific quality.
 func (fs *FileStream) GetVideoIndex(quality Quality) (string, error) {
 	stream := fs.getVideoStream(quality)
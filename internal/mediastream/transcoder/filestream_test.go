@@ -0,0 +1,42 @@
+package transcoder
+
+import (
+	"seanime/internal/mediastream/videofile"
+	"strings"
+	"testing"
+
+	"github.com/samber/lo"
+)
+
+func TestGetMasterSubtitleRenditions(t *testing.T) {
+	fs := &FileStream{
+		Info: &videofile.MediaInfo{
+			Subtitles: []videofile.Subtitle{
+				{Index: 0, Language: lo.ToPtr("eng"), Title: lo.ToPtr("English"), Extension: lo.ToPtr("ass"), IsDefault: true},
+				{Index: 1, Language: lo.ToPtr("jpn"), Extension: lo.ToPtr("srt")},
+				{Index: 2, Codec: "hdmv_pgs_subtitle"},
+			},
+		},
+	}
+
+	master := fs.GetMaster()
+	if !strings.Contains(master, `#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",LANGUAGE="eng",NAME="English",DEFAULT=YES,AUTOSELECT=YES,URI="../subs/0.m3u8"`) {
+		t.Errorf("missing rendition for subtitle 0:\n%s", master)
+	}
+	if !strings.Contains(master, `#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",LANGUAGE="jpn",NAME="jpn",AUTOSELECT=YES,URI="../subs/1.m3u8"`) {
+		t.Errorf("missing rendition for subtitle 1:\n%s", master)
+	}
+	if strings.Contains(master, "subs/2.m3u8") {
+		t.Errorf("bitmap subtitle should not be listed:\n%s", master)
+	}
+
+	// The burned-in track is not listed
+	fs.BurnIn = &BurnInSubtitle{Index: 0}
+	master = fs.GetMaster()
+	if strings.Contains(master, "subs/0.m3u8") {
+		t.Errorf("burned-in subtitle should not be listed:\n%s", master)
+	}
+	if strings.Contains(master, "DEFAULT=YES") {
+		t.Errorf("no subtitle should be default when burning in:\n%s", master)
+	}
+}
@@ -1,7 +1,11 @@
 package transcoder
 
 import (
+	"cmp"
 	"runtime"
+	"seanime/internal/util"
+	"strings"
+	"sync"
 
 	"github.com/goccy/go-json"
 )
@@ -11,6 +15,7 @@ type (
 		Kind           string
 		Preset         string
 		CustomSettings string
+		FfmpegPath     string // Used to check the filters available for tonemapping
 	}
 )
 
@@ -39,6 +44,18 @@ func GetHardwareAccelSettings(opts HwAccelOptions) HwAccelSettings {
 		defaultOSDevice = "auto"
 	}
 
+	// CPU tonemapping, used when no hardware-specific tonemapping filter is available.
+	// zscale linearizes the HDR signal (PQ/HLG), tonemap maps it to SDR using the hable curve and zscale converts it back to bt709.
+	// this requires ffmpeg to be built with libzimg.
+	// without libzimg, the frames are only converted to 8bits, colors will look washed out but the video is playable.
+	cpuTonemapFilter := "scale=%d:%d,zscale=t=linear:npl=100,format=gbrpf32le,zscale=p=bt709,tonemap=tonemap=hable:desat=0,zscale=t=bt709:m=bt709:r=tv,format=yuv420p"
+	cudaTonemapFilter := "format=p010le|cuda,hwupload,scale_cuda=%d:%d:format=p010le,hwdownload,format=p010le,zscale=t=linear:npl=100,format=gbrpf32le,zscale=p=bt709,tonemap=tonemap=hable:desat=0,zscale=t=bt709:m=bt709:r=tv,format=nv12"
+	if !hasFilter(opts.FfmpegPath, "zscale") {
+		streamLogger.Warn().Msg("transcoder: FFmpeg was built without zscale, HDR videos will not be tonemapped")
+		cpuTonemapFilter = "scale=%d:%d,format=yuv420p"
+		cudaTonemapFilter = "format=p010le|cuda,hwupload,scale_cuda=%d:%d:format=nv12"
+	}
+
 	// superfast or ultrafast would produce heavy files, so opt for "fast" by default.
 	// vaapi does not have any presets so this flag is unused for vaapi hwaccel.
 	preset := opts.Preset
@@ -61,16 +78,22 @@ func GetHardwareAccelSettings(opts HwAccelOptions) HwAccelSettings {
 			// we could put :force_original_aspect_ratio=decrease:force_divisible_by=2 here but we already calculate a correct width and
 			// aspect ratio in our code so there is no need.
 			ScaleFilter:   "scale=%d:%d",
+			TonemapFilter: cpuTonemapFilter,
 			WithForcedIdr: true,
 		}
 	case "vaapi":
+		device := GetEnvOr("SEANIME_TRANSCODER_VAAPI_RENDERER", defaultOSDevice)
 		return HwAccelSettings{
 			Name: name,
 			DecodeFlags: []string{
 				"-hwaccel", "vaapi",
-				"-hwaccel_device", GetEnvOr("SEANIME_TRANSCODER_VAAPI_RENDERER", defaultOSDevice),
+				"-hwaccel_device", device,
 				"-hwaccel_output_format", "vaapi",
 			},
+			BurnInDecodeFlags: []string{
+				"-init_hw_device", hwDeviceSpec("vaapi=va", device, ":"),
+				"-filter_hw_device", "va",
+			},
 			EncodeFlags: []string{
 				// h264_vaapi does not have any preset or scenecut flags.
 				"-c:v", "h264_vaapi",
@@ -89,23 +112,32 @@ func GetHardwareAccelSettings(opts HwAccelOptions) HwAccelSettings {
 			//   convert whatever to nv12 on GPU // scale_vaapi doesn't support passthrough option, so it has to make a copy
 			// }
 			// See https://www.reddit.com/r/ffmpeg/comments/1bqn60w/hardware_accelerated_decoding_without_hwdownload/ for more info
-			ScaleFilter:   "format=nv12|vaapi,hwupload,scale_vaapi=%d:%d:format=nv12",
+			ScaleFilter: "format=nv12|vaapi,hwupload,scale_vaapi=%d:%d:format=nv12",
+			// keep the 10 bits until the tonemapping is done on the GPU, tonemap_vaapi outputs nv12.
+			TonemapFilter: "format=p010|vaapi,hwupload,scale_vaapi=%d:%d:format=p010,tonemap_vaapi=format=nv12:p=bt709:t=bt709:m=bt709",
 			WithForcedIdr: true,
 		}
 	case "qsv", "intel":
+		device := GetEnvOr("SEANIME_TRANSCODER_QSV_RENDERER", defaultOSDevice)
 		return HwAccelSettings{
 			Name: name,
 			DecodeFlags: []string{
 				"-hwaccel", "qsv",
-				"-qsv_device", GetEnvOr("SEANIME_TRANSCODER_QSV_RENDERER", defaultOSDevice),
+				"-qsv_device", device,
 				"-hwaccel_output_format", "qsv",
 			},
+			BurnInDecodeFlags: []string{
+				"-init_hw_device", hwDeviceSpec("qsv=qs:hw_any", device, ",child_device="),
+				"-filter_hw_device", "qs",
+			},
 			EncodeFlags: []string{
 				"-c:v", "h264_qsv",
 				"-preset", preset,
 			},
 			// see note on ScaleFilter of the vaapi HwAccel, this is the same filter but adapted to qsv
-			ScaleFilter:   "format=nv12|qsv,hwupload,scale_qsv=%d:%d:format=nv12",
+			ScaleFilter: "format=nv12|qsv,hwupload,scale_qsv=%d:%d:format=nv12",
+			// vpp_qsv can tonemap HDR to SDR (ffmpeg 6.0+)
+			TonemapFilter: "format=p010|qsv,hwupload,scale_qsv=%d:%d:format=p010,vpp_qsv=tonemap=1:format=nv12",
 			WithForcedIdr: true,
 		}
 	case "nvidia":
@@ -117,6 +149,10 @@ func GetHardwareAccelSettings(opts HwAccelOptions) HwAccelSettings {
 				// it forces the whole dec/enc to be on the gpu. We want that.
 				"-hwaccel_output_format", "cuda",
 			},
+			BurnInDecodeFlags: []string{
+				"-init_hw_device", "cuda=cu",
+				"-filter_hw_device", "cu",
+			},
 			EncodeFlags: []string{
 				"-c:v", "h264_nvenc",
 				"-preset", preset,
@@ -124,7 +160,10 @@ func GetHardwareAccelSettings(opts HwAccelOptions) HwAccelSettings {
 				"-no-scenecut", "1",
 			},
 			// see note on ScaleFilter of the vaapi HwAccel, this is the same filter but adapted to cuda
-			ScaleFilter:   "format=nv12|cuda,hwupload,scale_cuda=%d:%d:format=nv12",
+			ScaleFilter: "format=nv12|cuda,hwupload,scale_cuda=%d:%d:format=nv12",
+			// upstream ffmpeg has no cuda tonemapping filter, so we scale on the GPU and download the frames to tonemap them on the CPU.
+			// nvenc accepts frames from system memory.
+			TonemapFilter: cudaTonemapFilter,
 			WithForcedIdr: true,
 		}
 	case "videotoolbox":
@@ -138,6 +177,7 @@ func GetHardwareAccelSettings(opts HwAccelOptions) HwAccelSettings {
 				"-profile:v", "main",
 			},
 			ScaleFilter:   "scale=%d:%d",
+			TonemapFilter: cpuTonemapFilter,
 			WithForcedIdr: true,
 		}
 	case "custom":
@@ -147,3 +187,45 @@ func GetHardwareAccelSettings(opts HwAccelOptions) HwAccelSettings {
 		panic("unreachable")
 	}
 }
+
+// hwDeviceSpec returns the -init_hw_device value for the device, the device is left out when it is picked automatically.
+func hwDeviceSpec(spec string, device string, sep string) string {
+	if device == "" || device == "auto" {
+		return spec
+	}
+	return spec + sep + device
+}
+
+var (
+	ffmpegFilters   = make(map[string]string)
+	ffmpegFiltersMu sync.Mutex
+)
+
+// hasFilter returns true if FFmpeg was built with the filter.
+// The list of filters is cached per FFmpeg binary.
+func hasFilter(ffmpegPath string, filter string) bool {
+	ffmpegPath = cmp.Or(ffmpegPath, "ffmpeg")
+
+	ffmpegFiltersMu.Lock()
+	defer ffmpegFiltersMu.Unlock()
+
+	filters, ok := ffmpegFilters[ffmpegPath]
+	if !ok {
+		out, err := util.NewCmd(ffmpegPath, "-hide_banner", "-filters").Output()
+		if err != nil {
+			// Assume the filter is available, FFmpeg will report the error when transcoding
+			return true
+		}
+		filters = string(out)
+		ffmpegFilters[ffmpegPath] = filters
+	}
+
+	// Each line is "<flags> <name> <io> <description>"
+	for _, line := range strings.Split(filters, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[1] == filter {
+			return true
+		}
+	}
+	return false
+}
@@ -11,9 +11,15 @@ func GetEnvOr(env string, def string) string {
 }
 
 type HwAccelSettings struct {
-	Name          string   `json:"name"`
-	DecodeFlags   []string `json:"decodeFlags"`
-	EncodeFlags   []string `json:"encodeFlags"`
-	ScaleFilter   string   `json:"scaleFilter"`
-	WithForcedIdr bool     `json:"removeForcedIdr"`
+	Name        string   `json:"name"`
+	DecodeFlags []string `json:"decodeFlags"`
+	// BurnInDecodeFlags replace DecodeFlags when subtitles are burned into the video.
+	// Frames are decoded on the CPU for the subtitles filters, these flags only create the device used by hwupload.
+	BurnInDecodeFlags []string `json:"burnInDecodeFlags"`
+	EncodeFlags       []string `json:"encodeFlags"`
+	ScaleFilter       string   `json:"scaleFilter"`
+	// TonemapFilter replaces ScaleFilter when the source is HDR.
+	// It scales the video and maps it to SDR (bt709, 8 bits).
+	TonemapFilter string `json:"tonemapFilter"`
+	WithForcedIdr bool   `json:"removeForcedIdr"`
 }
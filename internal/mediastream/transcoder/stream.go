@@ -421,7 +421,13 @@ func (ts *Stream) run(start int32) error {
 		"-nostats", "-hide_banner", "-loglevel", "warning",
 	}
 
-	args = append(args, ts.settings.HwAccel.DecodeFlags...)
+	// subtitles are rendered on the CPU, so burned-in video streams are decoded on the CPU.
+	// the hardware scale filters upload the frames back to the GPU using the device created by BurnInDecodeFlags.
+	if ts.file.BurnIn == nil || ts.handle.getFlags()&VideoF == 0 {
+		args = append(args, ts.settings.HwAccel.DecodeFlags...)
+	} else {
+		args = append(args, ts.settings.HwAccel.BurnInDecodeFlags...)
+	}
 
 	if startRef != 0 {
 		if ts.handle.getFlags()&VideoF != 0 {
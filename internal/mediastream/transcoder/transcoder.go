@@ -65,6 +65,7 @@ func NewTranscoder(opts *NewTranscoderOptions) (*Transcoder, error) {
 				Kind:           opts.HwAccelKind,
 				Preset:         opts.Preset,
 				CustomSettings: opts.HwAccelCustomSettings,
+				FfmpegPath:     opts.FfmpegPath,
 			}),
 			FfmpegPath:  opts.FfmpegPath,
 			FfprobePath: opts.FfprobePath,
@@ -100,39 +101,40 @@ func (t *Transcoder) Destroy() {
 	t.logger.Debug().Msg("transcoder: Transcoder destroyed")
 }
 
-func (t *Transcoder) getFileStream(path string, hash string, mediaInfo *videofile.MediaInfo) (*FileStream, error) {
+func (t *Transcoder) getFileStream(path string, hash string, mediaInfo *videofile.MediaInfo, burnIn *BurnInSubtitle) (*FileStream, error) {
 	if debugStream {
 		start := time.Now()
 		t.logger.Trace().Msgf("transcoder: Getting filestream")
 		defer t.logger.Trace().Msgf("transcoder: Filestream retrieved in %.2fs", time.Since(start).Seconds())
 	}
-	ret, _ := t.streams.GetOrSet(path, func() (*FileStream, error) {
-		return NewFileStream(path, hash, mediaInfo, &t.settings, t.logger), nil
+	key := streamKey(path, burnIn)
+	ret, _ := t.streams.GetOrSet(key, func() (*FileStream, error) {
+		return NewFileStream(path, hash, mediaInfo, burnIn, &t.settings, t.logger), nil
 	})
 	if ret == nil {
 		return nil, fmt.Errorf("could not get filestream, file may not exist")
 	}
 	ret.ready.Wait()
 	if ret.err != nil {
-		t.streams.Delete(path)
+		t.streams.Delete(key)
 		return nil, ret.err
 	}
 	return ret, nil
 }
 
-func (t *Transcoder) GetMaster(path string, hash string, mediaInfo *videofile.MediaInfo, client string) (string, error) {
+func (t *Transcoder) GetMaster(path string, hash string, mediaInfo *videofile.MediaInfo, burnIn *BurnInSubtitle, client string) (string, error) {
 	if debugStream {
 		start := time.Now()
 		t.logger.Trace().Msgf("transcoder: Retrieving master file")
 		defer t.logger.Trace().Msgf("transcoder: Master file retrieved in %.2fs", time.Since(start).Seconds())
 	}
-	stream, err := t.getFileStream(path, hash, mediaInfo)
+	stream, err := t.getFileStream(path, hash, mediaInfo, burnIn)
 	if err != nil {
 		return "", err
 	}
 	t.clientChan <- ClientInfo{
 		client:  client,
-		path:    path,
+		path:    streamKey(path, burnIn),
 		quality: nil,
 		audio:   -1,
 		head:    -1,
@@ -144,6 +146,7 @@ func (t *Transcoder) GetVideoIndex(
 	path string,
 	hash string,
 	mediaInfo *videofile.MediaInfo,
+	burnIn *BurnInSubtitle,
 	quality Quality,
 	client string,
 ) (string, error) {
@@ -152,13 +155,13 @@ func (t *Transcoder) GetVideoIndex(
 		t.logger.Trace().Msgf("transcoder: Retrieving video index file (%s)", quality)
 		defer t.logger.Trace().Msgf("transcoder: Video index file retrieved in %.2fs", time.Since(start).Seconds())
 	}
-	stream, err := t.getFileStream(path, hash, mediaInfo)
+	stream, err := t.getFileStream(path, hash, mediaInfo, burnIn)
 	if err != nil {
 		return "", err
 	}
 	t.clientChan <- ClientInfo{
 		client:  client,
-		path:    path,
+		path:    streamKey(path, burnIn),
 		quality: &quality,
 		audio:   -1,
 		head:    -1,
@@ -170,6 +173,7 @@ func (t *Transcoder) GetAudioIndex(
 	path string,
 	hash string,
 	mediaInfo *videofile.MediaInfo,
+	burnIn *BurnInSubtitle,
 	audio int32,
 	client string,
 ) (string, error) {
@@ -178,13 +182,13 @@ func (t *Transcoder) GetAudioIndex(
 		t.logger.Trace().Msgf("transcoder: Retrieving audio index file (%d)", audio)
 		defer t.logger.Trace().Msgf("transcoder: Audio index file retrieved in %.2fs", time.Since(start).Seconds())
 	}
-	stream, err := t.getFileStream(path, hash, mediaInfo)
+	stream, err := t.getFileStream(path, hash, mediaInfo, burnIn)
 	if err != nil {
 		return "", err
 	}
 	t.clientChan <- ClientInfo{
 		client: client,
-		path:   path,
+		path:   streamKey(path, burnIn),
 		audio:  audio,
 		head:   -1,
 	}
@@ -195,6 +199,7 @@ func (t *Transcoder) GetVideoSegment(
 	path string,
 	hash string,
 	mediaInfo *videofile.MediaInfo,
+	burnIn *BurnInSubtitle,
 	quality Quality,
 	segment int32,
 	client string,
@@ -204,14 +209,14 @@ func (t *Transcoder) GetVideoSegment(
 		t.logger.Trace().Msgf("transcoder: Retrieving video segment %d (%s) [GetVideoSegment]", segment, quality)
 		defer t.logger.Trace().Msgf("transcoder: Video segment retrieved in %.2fs", time.Since(start).Seconds())
 	}
-	stream, err := t.getFileStream(path, hash, mediaInfo)
+	stream, err := t.getFileStream(path, hash, mediaInfo, burnIn)
 	if err != nil {
 		return "", err
 	}
 	//t.logger.Trace().Msgf("transcoder: Sending client info, segment %d (%s) [GetVideoSegment]", segment, quality)
 	t.clientChan <- ClientInfo{
 		client:  client,
-		path:    path,
+		path:    streamKey(path, burnIn),
 		quality: &quality,
 		audio:   -1,
 		head:    segment,
@@ -224,6 +229,7 @@ func (t *Transcoder) GetAudioSegment(
 	path string,
 	hash string,
 	mediaInfo *videofile.MediaInfo,
+	burnIn *BurnInSubtitle,
 	audio int32,
 	segment int32,
 	client string,
@@ -233,13 +239,13 @@ func (t *Transcoder) GetAudioSegment(
 		t.logger.Trace().Msgf("transcoder: Retrieving audio segment %d (%d)", segment, audio)
 		defer t.logger.Trace().Msgf("transcoder: Audio segment %d (%d) retrieved in %.2fs", segment, audio, time.Since(start).Seconds())
 	}
-	stream, err := t.getFileStream(path, hash, mediaInfo)
+	stream, err := t.getFileStream(path, hash, mediaInfo, burnIn)
 	if err != nil {
 		return "", err
 	}
 	t.clientChan <- ClientInfo{
 		client: client,
-		path:   path,
+		path:   streamKey(path, burnIn),
 		audio:  audio,
 		head:   segment,
 	}
@@ -3,6 +3,7 @@ package transcoder
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/rs/zerolog"
 )
@@ -25,7 +26,8 @@ func NewVideoStream(file *FileStream, quality Quality, logger *zerolog.Logger, s
 }
 
 func (vs *VideoStream) getFlags() Flags {
-	if vs.quality == Original {
+	// subtitles can't be burned in without transcoding
+	if vs.quality == Original && vs.file.BurnIn == nil {
 		return VideoF | Transmux
 	}
 	return VideoF
@@ -46,12 +48,11 @@ func closestMultiple(n int32, x int32) int32 {
 }
 
 func (vs *VideoStream) getTranscodeArgs(segments string) []string {
-	args := []string{
-		"-map", "0:V:0",
-	}
+	args := []string{}
 
-	if vs.quality == Original {
+	if vs.quality == Original && vs.file.BurnIn == nil {
 		args = append(args,
+			"-map", "0:V:0",
 			"-c:v", "copy",
 		)
 		vs.logger.Debug().Msg("videostream: Transcoding to original quality")
@@ -60,17 +61,51 @@ func (vs *VideoStream) getTranscodeArgs(segments string) []string {
 
 	vs.logger.Debug().Interface("hwaccelArgs", vs.settings.HwAccel).Msg("videostream: Hardware Acceleration")
 
-	args = append(args, vs.settings.HwAccel.EncodeFlags...)
-	width := int32(float64(vs.quality.Height()) / float64(vs.file.Info.Video.Height) * float64(vs.file.Info.Video.Width))
+	// when burning subtitles, the original quality is transcoded at the source resolution
+	// and uses the bitrate of the closest quality
+	quality := vs.quality
+	height := vs.file.Info.Video.Height
+	if quality == Original {
+		quality = QualityFromHeight(height)
+	} else {
+		height = quality.Height()
+	}
+	width := int32(float64(height) / float64(vs.file.Info.Video.Height) * float64(vs.file.Info.Video.Width))
 	// force a width that is a multiple of two else some apps behave badly.
 	width = closestMultiple(width, 2)
+	filter := vs.getVideoFilter(width, height)
+
+	switch {
+	case vs.file.BurnIn != nil && vs.file.BurnIn.IsBitmap:
+		// bitmap subtitles are overlaid from the subtitle stream of the source file,
+		// they are scaled to the output resolution and overlaid on the tonemapped frames
+		vs.logger.Debug().Uint32("index", vs.file.BurnIn.Index).Msg("videostream: Burning bitmap subtitles into the video")
+		before, after := splitBurnInFilter(filter)
+		args = append(args,
+			"-filter_complex", fmt.Sprintf("[0:V:0]%s[base];[0:s:%d]scale=%d:%d[sub];[base][sub]overlay%s[v]", before, vs.file.BurnIn.Index, width, height, after),
+			"-map", "[v]",
+		)
+	case vs.file.BurnIn != nil:
+		vs.logger.Debug().Uint32("index", vs.file.BurnIn.Index).Msg("videostream: Burning text subtitles into the video")
+		before, after := splitBurnInFilter(filter)
+		args = append(args,
+			"-map", "0:V:0",
+			"-vf", before+","+vs.file.BurnIn.getSubtitlesFilter()+after,
+		)
+	default:
+		args = append(args,
+			"-map", "0:V:0",
+			"-vf", filter,
+		)
+	}
+
+	args = append(args, vs.settings.HwAccel.EncodeFlags...)
 	args = append(args,
-		"-vf", fmt.Sprintf(vs.settings.HwAccel.ScaleFilter, width, vs.quality.Height()),
 		// Even less sure but buf size are 5x the average bitrate since the average bitrate is only
 		// useful for hls segments.
-		"-bufsize", fmt.Sprint(vs.quality.MaxBitrate()*5),
-		"-b:v", fmt.Sprint(vs.quality.AverageBitrate()),
-		"-maxrate", fmt.Sprint(vs.quality.MaxBitrate()),
+		"-bufsize", fmt.Sprint(quality.MaxBitrate()*5),
+		"-b:v", fmt.Sprint(quality.AverageBitrate()),
+		"-maxrate", fmt.Sprint(quality.MaxBitrate()),
 	)
 	if vs.settings.HwAccel.WithForcedIdr {
 		// Force segments to be split exactly on keyframes (only works when transcoding)
@@ -89,3 +124,28 @@ func (vs *VideoStream) getTranscodeArgs(segments string) []string {
 
 	return args
 }
+
+// getVideoFilter returns the filter chain used to scale the video.
+// HDR sources are tonemapped to SDR since the output is H.264 (8 bits, bt709), otherwise colors come out washed-out.
+// 10-bit SDR sources are converted to an 8-bit pixel format.
+func (vs *VideoStream) getVideoFilter(width int32, height uint32) string {
+	video := vs.file.Info.Video
+
+	if video.IsHDR() {
+		if vs.settings.HwAccel.TonemapFilter != "" {
+			vs.logger.Debug().Str("transfer", video.ColorTransfer).Str("primaries", video.ColorPrimaries).Msg("videostream: HDR source detected, applying tonemapping")
+			return fmt.Sprintf(vs.settings.HwAccel.TonemapFilter, width, height)
+		}
+		vs.logger.Warn().Str("hwaccel", vs.settings.HwAccel.Name).Msg("videostream: HDR source detected but no tonemapping filter is available")
+	}
+
+	filter := fmt.Sprintf(vs.settings.HwAccel.ScaleFilter, width, height)
+
+	// Hardware filters already output nv12 (see the ScaleFilter of hardware accelerators),
+	// software filters keep the source pixel format, which H.264 encoders (and most devices) don't handle in 10 bits.
+	if video.IsHighBitDepth() && !strings.Contains(filter, "hwupload") {
+		filter += ",format=yuv420p"
+	}
+
+	return filter
+}
@@ -0,0 +1,197 @@
+package mediastream
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"seanime/internal/mediastream/videofile"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+)
+
+const defaultTrickplayInterval = 10
+
+type (
+	// trickplayGenerator generates the seek preview thumbnails of local files in the background.
+	// Files are processed one at a time in the order they were requested.
+	trickplayGenerator struct {
+		repository *Repository
+		logger     *zerolog.Logger
+
+		mu      sync.Mutex
+		queue   []*trickplayJob
+		queued  map[string]struct{} // Hashes of the queued files and the file being processed
+		running bool
+
+		ctx       context.Context
+		ctxCancel context.CancelFunc
+	}
+
+	trickplayJob struct {
+		path string
+		hash string
+	}
+)
+
+func newTrickplayGenerator(repository *Repository) *trickplayGenerator {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &trickplayGenerator{
+		repository: repository,
+		logger:     repository.logger,
+		queue:      make([]*trickplayJob, 0),
+		queued:     make(map[string]struct{}),
+		ctx:        ctx,
+		ctxCancel:  cancel,
+	}
+}
+
+// GetTrickplayUrl returns the relative URL of the thumbnails of the file.
+// If the thumbnails have not been generated, the file is queued and an empty string is returned.
+func (r *Repository) GetTrickplayUrl(path string) string {
+	settings, ok := r.settings.Get()
+	if !ok || !settings.TrickplayEnabled || r.cacheDir == "" {
+		return ""
+	}
+
+	hash, err := videofile.GetHashFromPath(path)
+	if err != nil {
+		return ""
+	}
+
+	if videofile.TrickplayExists(r.cacheDir, hash) {
+		return getTrickplayUrl(hash)
+	}
+
+	r.trickplayGenerator.enqueue(path, hash)
+	return ""
+}
+
+// QueueTrickplayGeneration queues the generation of the thumbnails of the files that do not have any.
+func (r *Repository) QueueTrickplayGeneration(paths []string) error {
+	settings, ok := r.settings.Get()
+	if !ok {
+		return errors.New("module not initialized")
+	}
+	if !settings.TrickplayEnabled {
+		return errors.New("trickplay thumbnails are disabled")
+	}
+
+	for _, path := range paths {
+		hash, err := videofile.GetHashFromPath(path)
+		if err != nil {
+			r.logger.Warn().Err(err).Str("path", path).Msg("mediastream: Cannot queue trickplay generation")
+			continue
+		}
+		if videofile.TrickplayExists(r.cacheDir, hash) {
+			continue
+		}
+		r.trickplayGenerator.enqueue(path, hash)
+	}
+
+	return nil
+}
+
+// ServeEchoTrickplay serves the WebVTT file and the sprite sheets of a file, e.g. "/{hash}/thumbnails.vtt".
+func (r *Repository) ServeEchoTrickplay(c echo.Context) error {
+	if r.cacheDir == "" {
+		return errors.New("module not initialized")
+	}
+
+	hash, filename, ok := strings.Cut(c.Param("*"), "/")
+	// Prevent path traversal
+	if !ok || hash == "" || filename == "" || filepath.Base(hash) != hash || filepath.Base(filename) != filename {
+		return echo.ErrNotFound
+	}
+
+	if filename == videofile.TrickplayVttFilename {
+		c.Response().Header().Set(echo.HeaderContentType, "text/vtt; charset=utf-8")
+	}
+
+	return c.File(filepath.Join(videofile.GetFileTrickplayCacheDir(r.cacheDir, hash), filename))
+}
+
+func getTrickplayUrl(hash string) string {
+	return "/api/v1/mediastream/trickplay/" + hash + "/" + videofile.TrickplayVttFilename
+}
+
+func (g *trickplayGenerator) enqueue(path string, hash string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, found := g.queued[hash]; found {
+		return
+	}
+
+	g.logger.Debug().Str("path", path).Msg("mediastream: Queued trickplay generation")
+
+	g.queued[hash] = struct{}{}
+	g.queue = append(g.queue, &trickplayJob{path: path, hash: hash})
+
+	if !g.running {
+		g.running = true
+		go g.run()
+	}
+}
+
+func (g *trickplayGenerator) run() {
+	for {
+		g.mu.Lock()
+		if len(g.queue) == 0 || g.ctx.Err() != nil {
+			g.running = false
+			g.mu.Unlock()
+			return
+		}
+		job := g.queue[0]
+		g.queue = g.queue[1:]
+		g.mu.Unlock()
+
+		g.process(job)
+
+		g.mu.Lock()
+		delete(g.queued, job.hash)
+		g.mu.Unlock()
+	}
+}
+
+func (g *trickplayGenerator) process(job *trickplayJob) {
+	settings, ok := g.repository.settings.Get()
+	if !ok || !settings.TrickplayEnabled {
+		return
+	}
+
+	if videofile.TrickplayExists(g.repository.cacheDir, job.hash) {
+		return
+	}
+
+	mediaInfo, err := g.repository.mediaInfoExtractor.GetInfo(settings.FfprobePath, job.path)
+	if err != nil {
+		g.logger.Error().Err(err).Str("path", job.path).Msg("mediastream: Failed to get media info for trickplay generation")
+		return
+	}
+
+	interval := settings.TrickplayInterval
+	if interval <= 0 {
+		interval = defaultTrickplayInterval
+	}
+
+	err = videofile.ExtractTrickplay(g.ctx, settings.FfmpegPath, job.path, job.hash, mediaInfo, interval, g.repository.cacheDir, g.logger)
+	if err != nil {
+		g.logger.Error().Err(err).Str("path", job.path).Msg("mediastream: Failed to generate trickplay thumbnails")
+		return
+	}
+
+	g.logger.Info().Str("path", job.path).Msg("mediastream: Generated trickplay thumbnails")
+}
+
+// stop cancels the thumbnail generation in progress and drops the queued files.
+func (g *trickplayGenerator) stop() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.ctxCancel()
+	g.queue = make([]*trickplayJob, 0)
+	g.queued = make(map[string]struct{})
+}
@@ -41,6 +41,9 @@ type MediaInfo struct {
 	Audios []Audio `json:"audios"`
 	// The list of subtitles tracks
 	Subtitles []Subtitle `json:"subtitles"`
+	// The list of bitmap subtitle tracks (PGS, VobSub).
+	// They cannot be extracted as text and can only be burned into the video.
+	BitmapSubtitles []Subtitle `json:"bitmapSubtitles"`
 	// The list of fonts that can be used to display subtitles
 	Fonts []string `json:"fonts"`
 	// The list of chapters. See Chapter for more information
@@ -62,6 +65,30 @@ type Video struct {
 	Height uint32 `json:"height"`
 	// The average bitrate of the video in bytes/s
 	Bitrate uint32 `json:"bitrate"`
+	// The pixel format of the video stream, e.g., "yuv420p10le"
+	PixelFormat string `json:"pixelFormat"`
+	// The number of bits per color component (8, 10, 12)
+	BitDepth uint32 `json:"bitDepth"`
+	// The transfer characteristics of the video stream, e.g., "smpte2084" for HDR10 and "arib-std-b67" for HLG
+	ColorTransfer string `json:"colorTransfer"`
+	// The color primaries of the video stream, e.g., "bt2020"
+	ColorPrimaries string `json:"colorPrimaries"`
+	// The color space (matrix coefficients) of the video stream, e.g., "bt2020nc"
+	ColorSpace string `json:"colorSpace"`
+}
+
+// IsHDR returns true if the video stream uses an HDR transfer function (PQ or HLG).
+func (v *Video) IsHDR() bool {
+	switch v.ColorTransfer {
+	case "smpte2084", "arib-std-b67":
+		return true
+	}
+	return false
+}
+
+// IsHighBitDepth returns true if the video stream has more than 8 bits per color component.
+func (v *Video) IsHighBitDepth() bool {
+	return v.BitDepth > 8
 }
 
 type Audio struct {
@@ -112,6 +139,10 @@ type Chapter struct {
 	// TODO: add a type field for Opening, Credits...
 }
 
+// mediaInfoCacheVersion is part of the cache bucket name.
+// It must be incremented when fields are added to MediaInfo so that cached media information is extracted again.
+const mediaInfoCacheVersion = 2
+
 type MediaInfoExtractor struct {
 	fileCacher *filecache.Cacher
 	logger     *zerolog.Logger
@@ -134,7 +165,7 @@ func (e *MediaInfoExtractor) GetInfo(ffprobePath, path string) (mi *MediaInfo, e
 
 	e.logger.Debug().Str("path", path).Str("hash", hash).Msg("mediastream: Getting media information [MediaInfoExtractor]")
 
-	bucketName := fmt.Sprintf("mediastream_mediainfo_%s", hash)
+	bucketName := fmt.Sprintf("mediastream_mediainfo_v%d_%s", mediaInfoCacheVersion, hash)
 	bucket := filecache.NewBucket(bucketName, 24*7*52*time.Hour)
 	e.logger.Trace().Str("bucketName", bucketName).Msg("mediastream: Using cache bucket [MediaInfoExtractor]")
 
@@ -205,7 +236,12 @@ func FfprobeGetInfo(ffprobePath, path, hash string) (*MediaInfo, error) {
 			Height:    uint32(stream.Height),
 			// ffmpeg does not report bitrate in mkv files, fallback to bitrate of the whole container
 			// (bigger than the result since it contains audio and other videos but better than nothing).
-			Bitrate: uint32(bitrate),
+			Bitrate:        uint32(bitrate),
+			PixelFormat:    stream.PixFmt,
+			BitDepth:       streamToBitDepth(stream),
+			ColorTransfer:  stream.ColorTransfer,
+			ColorPrimaries: stream.ColorPrimaries,
+			ColorSpace:     stream.ColorSpace,
 		}
 	})
 
@@ -250,6 +286,11 @@ func FfprobeGetInfo(ffprobePath, path, hash string) (*MediaInfo, error) {
 		}
 	})
 
+	// Keep bitmap subtitles apart, they can only be burned into the video
+	mi.BitmapSubtitles = lo.Filter(mi.Subtitles, func(item Subtitle, _ int) bool {
+		return item.Codec == "hdmv_pgs_subtitle" || item.Codec == "dvd_subtitle"
+	})
+
 	// Remove subtitles without extensions (not supported)
 	mi.Subtitles = lo.Filter(mi.Subtitles, func(item Subtitle, _ int) bool {
 		if item.Extension == nil || *item.Extension == "" || item.Link == nil {
@@ -324,6 +365,22 @@ func streamToMap[T any](streams []*ffprobe.Stream, kind ffprobe.StreamType, mapp
 	return ret
 }
 
+// streamToBitDepth returns the bit depth of a video stream.
+// ffprobe does not always report bits_per_raw_sample (e.g. for mkv files), so we fall back to the pixel format.
+func streamToBitDepth(stream *ffprobe.Stream) uint32 {
+	if bitDepth, err := strconv.ParseUint(stream.BitsPerRawSample, 10, 32); err == nil && bitDepth > 0 {
+		return uint32(bitDepth)
+	}
+	pixFmt := strings.TrimSuffix(strings.TrimSuffix(stream.PixFmt, "le"), "be")
+	switch {
+	case strings.HasSuffix(pixFmt, "p10"), strings.HasSuffix(pixFmt, "010"):
+		return 10
+	case strings.HasSuffix(pixFmt, "p12"), strings.HasSuffix(pixFmt, "012"):
+		return 12
+	}
+	return 8
+}
+
 func streamToMimeCodec(stream *ffprobe.Stream) *string {
 	switch stream.CodecName {
 	case "h264":
@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"seanime/internal/util"
 	"testing"
+
+	"gopkg.in/vansante/go-ffprobe.v2"
 )
 
 func TestFfprobeGetInfo_1(t *testing.T) {
@@ -49,3 +51,41 @@ func TestExtractAttachment(t *testing.T) {
 		t.Logf("Entry: %s, Size: %d\n", entry.Name(), info.Size())
 	}
 }
+
+func TestStreamToBitDepth(t *testing.T) {
+	tests := []struct {
+		stream   *ffprobe.Stream
+		expected uint32
+	}{
+		{&ffprobe.Stream{PixFmt: "yuv420p"}, 8},
+		{&ffprobe.Stream{PixFmt: "yuv420p10le"}, 10},
+		{&ffprobe.Stream{PixFmt: "p010le"}, 10},
+		{&ffprobe.Stream{PixFmt: "yuv420p12be"}, 12},
+		{&ffprobe.Stream{PixFmt: "yuv420p", BitsPerRawSample: "10"}, 10},
+	}
+
+	for _, tt := range tests {
+		if got := streamToBitDepth(tt.stream); got != tt.expected {
+			t.Errorf("streamToBitDepth(%q, %q) = %d, want %d", tt.stream.PixFmt, tt.stream.BitsPerRawSample, got, tt.expected)
+		}
+	}
+}
+
+func TestVideo_IsHDR(t *testing.T) {
+	tests := []struct {
+		transfer string
+		expected bool
+	}{
+		{"smpte2084", true},
+		{"arib-std-b67", true},
+		{"bt709", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		v := &Video{ColorTransfer: tt.transfer}
+		if got := v.IsHDR(); got != tt.expected {
+			t.Errorf("IsHDR(%q) = %v, want %v", tt.transfer, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package videofile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"seanime/internal/util"
+	"seanime/internal/util/crashlog"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// TrickplayVttFilename is the name of the WebVTT file referencing the thumbnails in the sprite sheets.
+	TrickplayVttFilename = "thumbnails.vtt"
+	// TrickplayThumbnailWidth is the width of a thumbnail in pixels.
+	TrickplayThumbnailWidth = 320
+	// TrickplayTileColumns and TrickplayTileRows define the number of thumbnails in a sprite sheet.
+	TrickplayTileColumns = 10
+	TrickplayTileRows    = 10
+)
+
+// GetFileTrickplayCacheDir returns the directory where the thumbnails of the file are stored.
+// They are stored outside the "videofiles" directory since that one is cleared when transcoding is disabled.
+func GetFileTrickplayCacheDir(outDir string, hash string) string {
+	return filepath.Join(outDir, "trickplay", hash)
+}
+
+// TrickplayExists returns true if the thumbnails of the file have been generated.
+func TrickplayExists(cacheDir string, hash string) bool {
+	_, err := os.Stat(filepath.Join(GetFileTrickplayCacheDir(cacheDir, hash), TrickplayVttFilename))
+	return err == nil
+}
+
+// ExtractTrickplay generates the seek preview thumbnails of the file.
+// The thumbnails are taken every interval seconds and packed into sprite sheets ("sprite-0.jpg", "sprite-1.jpg", ...).
+// The WebVTT file maps each time range to the region of a sprite sheet, it is written last so that its presence means the thumbnails are complete.
+func ExtractTrickplay(ctx context.Context, ffmpegPath string, path string, hash string, mediaInfo *MediaInfo, interval int, cacheDir string, logger *zerolog.Logger) (err error) {
+	if mediaInfo.Video == nil || mediaInfo.Video.Width == 0 || mediaInfo.Video.Height == 0 {
+		return errors.New("videofile: No video stream")
+	}
+	if mediaInfo.Duration <= 0 {
+		return errors.New("videofile: Unknown duration")
+	}
+	if interval <= 0 {
+		return errors.New("videofile: Invalid thumbnail interval")
+	}
+
+	logger.Debug().Str("hash", hash).Int("interval", interval).Msgf("videofile: Starting trickplay extraction")
+
+	outDir := GetFileTrickplayCacheDir(cacheDir, hash)
+	// Remove incomplete thumbnails
+	_ = os.RemoveAll(outDir)
+	err = os.MkdirAll(outDir, 0755)
+	if err != nil {
+		return err
+	}
+
+	// The height is rounded to an even number since most encoders require it
+	width := TrickplayThumbnailWidth
+	height := int(math.Round(float64(width)*float64(mediaInfo.Video.Height)/float64(mediaInfo.Video.Width)/2)) * 2
+
+	// Instantiate a new crash logger
+	crashLogger := crashlog.GlobalCrashLogger.InitArea("ffmpeg")
+	defer crashLogger.Close()
+
+	crashLogger.LogInfof("Extracting trickplay thumbnails from %s", path)
+
+	// DEVNOTE: All paths fed into this command should be absolute
+	cmd := util.NewCmdCtx(
+		ctx,
+		ffmpegPath,
+		"-nostats", "-hide_banner", "-loglevel", "warning",
+		// Only decode keyframes, the thumbnails do not need to be frame-accurate
+		"-skip_frame", "nokey",
+		"-i", path,
+		"-map", "0:V:0",
+		"-an", "-sn", "-dn",
+		"-vf", fmt.Sprintf("fps=1/%d,scale=%d:%d,tile=%dx%d", interval, width, height, TrickplayTileColumns, TrickplayTileRows),
+		"-q:v", "5",
+		"-start_number", "0",
+		"-y",
+		filepath.Join(outDir, "sprite-%d.jpg"),
+	)
+	cmd.Stdout = crashLogger.Stdout()
+	cmd.Stderr = crashLogger.Stdout()
+	err = cmd.Run()
+	if err != nil {
+		logger.Error().Err(err).Msgf("videofile: Error running FFmpeg")
+		crashlog.GlobalCrashLogger.WriteAreaLogToFile(crashLogger)
+		_ = os.RemoveAll(outDir)
+		return err
+	}
+
+	vtt := getTrickplayVtt(float64(mediaInfo.Duration), interval, width, height)
+	err = os.WriteFile(filepath.Join(outDir, TrickplayVttFilename), []byte(vtt), 0644)
+	if err != nil {
+		_ = os.RemoveAll(outDir)
+		return err
+	}
+
+	logger.Debug().Str("hash", hash).Msgf("videofile: Trickplay thumbnails extracted")
+
+	return nil
+}
+
+// getTrickplayVtt returns the WebVTT file referencing the thumbnails with media fragments (e.g. "sprite-0.jpg#xywh=0,0,320,180").
+func getTrickplayVtt(duration float64, interval int, width int, height int) string {
+	perSprite := TrickplayTileColumns * TrickplayTileRows
+
+	var sb strings.Builder
+	sb.WriteString("WEBVTT\n\n")
+	for i := 0; float64(i*interval) < duration; i++ {
+		start := float64(i * interval)
+		end := math.Min(float64((i+1)*interval), duration)
+		tile := i % perSprite
+		x := (tile % TrickplayTileColumns) * width
+		y := (tile / TrickplayTileColumns) * height
+		sb.WriteString(fmt.Sprintf("%s --> %s\n", formatVttTimestamp(start), formatVttTimestamp(end)))
+		sb.WriteString(fmt.Sprintf("sprite-%d.jpg#xywh=%d,%d,%d,%d\n\n", i/perSprite, x, y, width, height))
+	}
+	return sb.String()
+}
+
+func formatVttTimestamp(seconds float64) string {
+	ms := int64(math.Round(seconds * 1000))
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3_600_000, (ms/60_000)%60, (ms/1000)%60, ms%1000)
+}
@@ -0,0 +1,29 @@
+package videofile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTrickplayVtt(t *testing.T) {
+	vtt := getTrickplayVtt(1005.5, 10, 320, 180)
+
+	cues := strings.Split(strings.TrimSpace(strings.TrimPrefix(vtt, "WEBVTT\n\n")), "\n\n")
+	require.Len(t, cues, 101)
+
+	assert.Equal(t, "00:00:00.000 --> 00:00:10.000\nsprite-0.jpg#xywh=0,0,320,180", cues[0])
+	// Second row of the first sprite sheet
+	assert.Equal(t, "00:01:50.000 --> 00:02:00.000\nsprite-0.jpg#xywh=320,180,320,180", cues[11])
+	// Last thumbnail of the first sprite sheet
+	assert.Equal(t, "00:16:30.000 --> 00:16:40.000\nsprite-0.jpg#xywh=2880,1620,320,180", cues[99])
+	// The last cue ends with the media
+	assert.Equal(t, "00:16:40.000 --> 00:16:45.500\nsprite-1.jpg#xywh=0,0,320,180", cues[100])
+}
+
+func TestFormatVttTimestamp(t *testing.T) {
+	assert.Equal(t, "00:00:00.000", formatVttTimestamp(0))
+	assert.Equal(t, "01:02:03.450", formatVttTimestamp(3723.45))
+}
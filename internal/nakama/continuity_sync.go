@@ -0,0 +1,151 @@
+package nakama
+
+import (
+	"encoding/json"
+	"errors"
+	"seanime/internal/continuity"
+	"time"
+)
+
+const (
+	// Peer -> Host, sent after connecting. The host replies with MessageTypeContinuitySyncReply.
+	MessageTypeContinuitySync = "continuity_sync"
+	// Host -> Peer
+	MessageTypeContinuitySyncReply = "continuity_sync_reply"
+	// Host <-> Peer, sent when a watch history item is updated by playback. The host forwards it to the other peers.
+	MessageTypeContinuityItemUpdated = "continuity_item_updated"
+)
+
+// ContinuitySyncPayload contains watch history items exchanged with the host.
+// Items are merged with last-writer-wins by WatchHistoryItem.TimeUpdated.
+type ContinuitySyncPayload struct {
+	Items []*continuity.WatchHistoryItem `json:"items"`
+}
+
+func (m *Manager) registerContinuitySyncHandlers() {
+	m.messageHandlers[MessageTypeContinuitySync] = m.handleContinuitySyncMessage
+	m.messageHandlers[MessageTypeContinuitySyncReply] = m.handleContinuitySyncMessage
+	m.messageHandlers[MessageTypeContinuityItemUpdated] = m.handleContinuitySyncMessage
+}
+
+// canSyncContinuity returns true if watch continuity is enabled on this instance.
+func (m *Manager) canSyncContinuity() bool {
+	if m.continuityManager == nil {
+		return false
+	}
+	settings := m.continuityManager.GetSettings()
+	return settings != nil && settings.WatchContinuityEnabled
+}
+
+// syncContinuityWithHost sends the whole watch history to the host.
+// This is called after connecting to the host.
+func (m *Manager) syncContinuityWithHost() {
+	if !m.canSyncContinuity() {
+		return
+	}
+
+	items, err := m.continuityManager.GetWatchHistoryItems()
+	if err != nil {
+		m.logger.Error().Err(err).Msg("nakama: Failed to get watch history for sync")
+		return
+	}
+
+	err = m.SendMessageToHost(MessageTypeContinuitySync, ContinuitySyncPayload{Items: items})
+	if err != nil {
+		m.logger.Error().Err(err).Msg("nakama: Failed to send watch history to host")
+		return
+	}
+
+	m.logger.Debug().Int("count", len(items)).Msg("nakama: Sent watch history to host")
+}
+
+// onWatchHistoryItemUpdated sends the item updated by the playback of this instance to the host or peers.
+func (m *Manager) onWatchHistoryItemUpdated(item *continuity.WatchHistoryItem) {
+	if !m.settings.Enabled || !m.canSyncContinuity() {
+		return
+	}
+
+	payload := ContinuitySyncPayload{Items: []*continuity.WatchHistoryItem{item}}
+
+	if m.IsHost() {
+		_ = m.SendMessage(MessageTypeContinuityItemUpdated, payload)
+		return
+	}
+
+	if m.IsConnectedToHost() {
+		_ = m.SendMessageToHost(MessageTypeContinuityItemUpdated, payload)
+	}
+}
+
+func (m *Manager) handleContinuitySyncMessage(message *Message, senderID string) error {
+	if !m.canSyncContinuity() {
+		return nil
+	}
+
+	data, err := json.Marshal(message.Payload)
+	if err != nil {
+		return err
+	}
+
+	var payload ContinuitySyncPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return err
+	}
+
+	merged, err := m.continuityManager.MergeWatchHistoryItems(payload.Items)
+	if err != nil {
+		return err
+	}
+
+	if !m.IsHost() {
+		return nil
+	}
+
+	peerConn, exists := m.peerConnections.Get(senderID)
+	if !exists {
+		return errors.New("peer connection not found")
+	}
+
+	switch message.Type {
+	case MessageTypeContinuitySync:
+		// Reply with the host's watch history, the peer keeps the most recent items
+		items, err := m.continuityManager.GetWatchHistoryItems()
+		if err != nil {
+			return err
+		}
+		err = peerConn.SendMessage(&Message{
+			Type:      MessageTypeContinuitySyncReply,
+			Payload:   ContinuitySyncPayload{Items: items},
+			Timestamp: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Forward the items the host did not have to the other peers
+	if len(merged) > 0 {
+		m.sendMessageToOtherPeers(senderID, MessageTypeContinuityItemUpdated, ContinuitySyncPayload{Items: merged})
+	}
+
+	return nil
+}
+
+// sendMessageToOtherPeers sends a message to all authenticated peers except the sender.
+func (m *Manager) sendMessageToOtherPeers(senderID string, msgType MessageType, payload interface{}) {
+	message := &Message{
+		Type:      msgType,
+		Payload:   payload,
+		Timestamp: time.Now(),
+	}
+
+	m.peerConnections.Range(func(id string, conn *PeerConnection) bool {
+		if id == senderID || !conn.Authenticated {
+			return true
+		}
+		if err := conn.SendMessage(message); err != nil {
+			m.logger.Error().Err(err).Str("peerId", conn.PeerId).Msg("nakama: Failed to send message to peer")
+		}
+		return true
+	})
+}
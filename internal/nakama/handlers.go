@@ -31,6 +31,9 @@ func (m *Manager) registerDefaultHandlers() {
 	m.messageHandlers[MessageTypeWatchPartyRelayModePeersReady] = m.handleWatchPartyMessage
 	m.messageHandlers[MessageTypeWatchPartyRelayModePeerBuffering] = m.handleWatchPartyMessage
 	m.messageHandlers[MessageTypeWatchPartyRelayModeOriginPlaybackStopped] = m.handleWatchPartyMessage
+
+	// Continuity handlers
+	m.registerContinuitySyncHandlers()
 }
 
 // handleMessage routes messages to the appropriate handler
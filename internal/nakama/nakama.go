@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"seanime/internal/continuity"
 	"seanime/internal/database/models"
 	debrid_client "seanime/internal/debrid/client"
 	"seanime/internal/events"
@@ -34,6 +35,7 @@ type Manager struct {
 	playbackManager         *playbackmanager.PlaybackManager
 	torrentstreamRepository *torrentstream.Repository
 	debridClientRepository  *debrid_client.Repository
+	continuityManager       *continuity.Manager
 	peerId                  string
 
 	// Host connections (when acting as host)
@@ -69,6 +71,7 @@ type NewManagerOptions struct {
 	PlaybackManager         *playbackmanager.PlaybackManager
 	TorrentstreamRepository *torrentstream.Repository
 	DebridClientRepository  *debrid_client.Repository
+	ContinuityManager       *continuity.Manager
 	Platform                platform.Platform
 	ServerHost              string
 	ServerPort              int
@@ -202,6 +205,7 @@ func NewManager(opts *NewManagerOptions) *Manager {
 		settings:                &models.NakamaSettings{},
 		torrentstreamRepository: opts.TorrentstreamRepository,
 		debridClientRepository:  opts.DebridClientRepository,
+		continuityManager:       opts.ContinuityManager,
 		previousPath:            "",
 	}
 
@@ -210,6 +214,9 @@ func NewManager(opts *NewManagerOptions) *Manager {
 	// Register default message handlers
 	m.registerDefaultHandlers()
 
+	// Exchange watch history items with the host or peers
+	m.continuityManager.SetWatchHistoryItemUpdatedCallback(m.onWatchHistoryItemUpdated)
+
 	eventListener := m.wsEventManager.SubscribeToClientEvents("nakama")
 	go func() {
 		for event := range eventListener.Channel {
@@ -278,6 +278,9 @@ func (m *Manager) attemptHostConnection(connCtx context.Context) error {
 	// Start client ping routine
 	go m.clientPingRoutine()
 
+	// Exchange watch history with the host
+	go m.syncContinuityWithHost()
+
 	return nil
 }
 
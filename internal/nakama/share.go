@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"seanime/internal/api/anilist"
 	"seanime/internal/api/metadata"
+	"seanime/internal/continuity"
 	"seanime/internal/events"
 	"seanime/internal/library/anime"
 	"seanime/internal/library/playbackmanager"
@@ -169,9 +170,10 @@ func (m *Manager) PlayHostAnimeLibraryFile(path string, userAgent string, media
 	playbackSubscriber := m.playbackManager.SubscribeToPlaybackStatus("nakama-file")
 
 	err = m.playbackManager.StartStreamingUsingMediaPlayer("", &playbackmanager.StartPlayingOptions{
-		Payload:   ret,
-		UserAgent: userAgent,
-		ClientId:  "",
+		Payload:     ret,
+		UserAgent:   userAgent,
+		ClientId:    "",
+		WatchSource: continuity.LocalWatchSource,
 	}, media, aniDBEpisode)
 	if err != nil {
 		m.wsEventManager.SendEvent(events.HideIndefiniteLoader, "nakama-file")
@@ -212,12 +214,19 @@ func (m *Manager) PlayHostAnimeStream(streamType string, userAgent string, media
 		ret = strings.Replace(ret, "http://http", "http", 1)
 	}
 
+	// "file", "torrent" or "debrid"
+	watchSource := continuity.WatchSource(streamType)
+	if streamType == "file" {
+		watchSource = continuity.LocalWatchSource
+	}
+
 	playbackSubscriber := m.playbackManager.SubscribeToPlaybackStatus("nakama-stream")
 
 	err := m.playbackManager.StartStreamingUsingMediaPlayer("", &playbackmanager.StartPlayingOptions{
-		Payload:   ret,
-		UserAgent: userAgent,
-		ClientId:  "",
+		Payload:     ret,
+		UserAgent:   userAgent,
+		ClientId:    "",
+		WatchSource: watchSource,
 	}, media, aniDBEpisode)
 	if err != nil {
 		m.wsEventManager.SendEvent(events.HideIndefiniteLoader, "nakama-stream")
@@ -72,14 +72,15 @@ func (p *NativePlayer) AddSubtitleTrack(clientId string, track *mkvparser.TrackI
 	p.sendPlayerEventTo(clientId, string(ServerEventAddSubtitleTrack), track)
 }
 
-// Stop emits a VideoTerminatedEvent to all subscribers.
+// Stop emits a VideoTerminatedEvent to all subscribers and terminates the player of the client.
 // It should only be called by a module.
-func (p *NativePlayer) Stop() {
-	p.logger.Debug().Msg("nativeplayer: Stopping playback, notifying subscribers")
+func (p *NativePlayer) Stop(clientId string) {
+	p.logger.Debug().Str("clientId", clientId).Msg("nativeplayer: Stopping playback, notifying subscribers")
+	p.removePlaybackStatus(clientId)
 	p.NotifySubscribers(&VideoTerminatedEvent{
-		BaseVideoEvent: BaseVideoEvent{ClientId: p.playbackStatus.ClientId},
+		BaseVideoEvent: BaseVideoEvent{ClientId: clientId},
 	})
-	p.sendPlayerEvent(string(ServerEventTerminate), nil)
+	p.sendPlayerEventTo(clientId, string(ServerEventTerminate), nil)
 }
 
 ///////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -235,11 +236,10 @@ func (p *NativePlayer) listenToPlayerEvents() {
 					case PlayerEventVideoPaused:
 						payload := &videoPausedPayload{}
 						if err := playerEvent.UnmarshalAs(&payload); err == nil {
-							p.setPlaybackStatus(func() {
-								p.playbackStatus.ClientId = playerEvent.ClientId
-								p.playbackStatus.Paused = true
-								p.playbackStatus.CurrentTime = payload.CurrentTime
-								p.playbackStatus.Duration = payload.Duration
+							status := p.setPlaybackStatus(playerEvent.ClientId, func(status *PlaybackStatus) {
+								status.Paused = true
+								status.CurrentTime = payload.CurrentTime
+								status.Duration = payload.Duration
 							})
 							p.NotifySubscribers(&VideoPausedEvent{
 								BaseVideoEvent: BaseVideoEvent{ClientId: playerEvent.ClientId},
@@ -248,17 +248,16 @@ func (p *NativePlayer) listenToPlayerEvents() {
 							})
 							p.NotifySubscribers(&VideoStatusEvent{
 								BaseVideoEvent: BaseVideoEvent{ClientId: playerEvent.ClientId},
-								Status:         *p.playbackStatus,
+								Status:         status,
 							})
 						}
 					case PlayerEventVideoResumed:
 						payload := &videoResumedPayload{}
 						if err := playerEvent.UnmarshalAs(&payload); err == nil {
-							p.setPlaybackStatus(func() {
-								p.playbackStatus.ClientId = playerEvent.ClientId
-								p.playbackStatus.Paused = false
-								p.playbackStatus.CurrentTime = payload.CurrentTime
-								p.playbackStatus.Duration = payload.Duration
+							status := p.setPlaybackStatus(playerEvent.ClientId, func(status *PlaybackStatus) {
+								status.Paused = false
+								status.CurrentTime = payload.CurrentTime
+								status.Duration = payload.Duration
 							})
 							p.NotifySubscribers(&VideoResumedEvent{
 								BaseVideoEvent: BaseVideoEvent{ClientId: playerEvent.ClientId},
@@ -267,16 +266,15 @@ func (p *NativePlayer) listenToPlayerEvents() {
 							})
 							p.NotifySubscribers(&VideoStatusEvent{
 								BaseVideoEvent: BaseVideoEvent{ClientId: playerEvent.ClientId},
-								Status:         *p.playbackStatus,
+								Status:         status,
 							})
 						}
 					case PlayerEventVideoCompleted:
 						payload := &videoCompletedPayload{}
 						if err := playerEvent.UnmarshalAs(&payload); err == nil {
-							p.setPlaybackStatus(func() {
-								p.playbackStatus.ClientId = playerEvent.ClientId
-								p.playbackStatus.CurrentTime = payload.CurrentTime
-								p.playbackStatus.Duration = payload.Duration
+							p.setPlaybackStatus(playerEvent.ClientId, func(status *PlaybackStatus) {
+								status.CurrentTime = payload.CurrentTime
+								status.Duration = payload.Duration
 							})
 							p.NotifySubscribers(&VideoCompletedEvent{
 								BaseVideoEvent: BaseVideoEvent{ClientId: playerEvent.ClientId},
@@ -287,9 +285,7 @@ func (p *NativePlayer) listenToPlayerEvents() {
 					case PlayerEventVideoEnded:
 						payload := &videoEndedPayload{}
 						if err := playerEvent.UnmarshalAs(&payload); err == nil {
-							p.setPlaybackStatus(func() {
-								p.playbackStatus.ClientId = playerEvent.ClientId
-							})
+							p.setPlaybackStatus(playerEvent.ClientId, func(status *PlaybackStatus) {})
 							p.NotifySubscribers(&VideoEndedEvent{
 								BaseVideoEvent: BaseVideoEvent{ClientId: playerEvent.ClientId},
 								AutoNext:       payload.AutoNext,
@@ -298,9 +294,7 @@ func (p *NativePlayer) listenToPlayerEvents() {
 					case PlayerEventVideoError:
 						payload := &videoErrorPayload{}
 						if err := playerEvent.UnmarshalAs(&payload); err == nil {
-							p.setPlaybackStatus(func() {
-								p.playbackStatus.ClientId = playerEvent.ClientId
-							})
+							p.setPlaybackStatus(playerEvent.ClientId, func(status *PlaybackStatus) {})
 							p.NotifySubscribers(&VideoErrorEvent{
 								BaseVideoEvent: BaseVideoEvent{ClientId: playerEvent.ClientId},
 								Error:          payload.Error,
@@ -309,30 +303,25 @@ func (p *NativePlayer) listenToPlayerEvents() {
 					case PlayerEventVideoSeeked:
 						payload := &videoSeekedPayload{}
 						if err := playerEvent.UnmarshalAs(&payload); err == nil {
-							p.setPlaybackStatus(func() {
-								p.playbackStatus.ClientId = playerEvent.ClientId
-							})
-							if p.seekedEventCancelFunc != nil {
-								p.seekedEventCancelFunc()
+							p.setPlaybackStatus(playerEvent.ClientId, func(status *PlaybackStatus) {})
+							if cancel, ok := p.seekedEventCancelFuncs.Get(playerEvent.ClientId); ok {
+								cancel()
 							}
-							var ctx context.Context
-							ctx, p.seekedEventCancelFunc = context.WithCancel(context.Background())
+							ctx, cancel := context.WithCancel(context.Background())
+							p.seekedEventCancelFuncs.Set(playerEvent.ClientId, cancel)
 							// Debounce the event
 							go func() {
 								defer func() {
 									if r := recover(); r != nil {
 									}
-									if p.seekedEventCancelFunc != nil {
-										p.seekedEventCancelFunc()
-										p.seekedEventCancelFunc = nil
-									}
+									cancel()
 								}()
 								select {
 								case <-ctx.Done():
 								case <-time.After(time.Millisecond * 150):
-									p.setPlaybackStatus(func() {
-										p.playbackStatus.CurrentTime = payload.CurrentTime
-										p.playbackStatus.Duration = payload.Duration
+									p.setPlaybackStatus(playerEvent.ClientId, func(status *PlaybackStatus) {
+										status.CurrentTime = payload.CurrentTime
+										status.Duration = payload.Duration
 									})
 									p.NotifySubscribers(&VideoSeekedEvent{
 										BaseVideoEvent: BaseVideoEvent{ClientId: playerEvent.ClientId},
@@ -348,10 +337,9 @@ func (p *NativePlayer) listenToPlayerEvents() {
 					case PlayerEventVideoLoadedMetadata:
 						payload := &videoLoadedMetadataPayload{}
 						if err := playerEvent.UnmarshalAs(&payload); err == nil {
-							p.setPlaybackStatus(func() {
-								p.playbackStatus.ClientId = playerEvent.ClientId
-								p.playbackStatus.CurrentTime = payload.CurrentTime
-								p.playbackStatus.Duration = payload.Duration
+							p.setPlaybackStatus(playerEvent.ClientId, func(status *PlaybackStatus) {
+								status.CurrentTime = payload.CurrentTime
+								status.Duration = payload.Duration
 							})
 							p.NotifySubscribers(&VideoLoadedMetadataEvent{
 								BaseVideoEvent: BaseVideoEvent{ClientId: playerEvent.ClientId},
@@ -362,9 +350,7 @@ func (p *NativePlayer) listenToPlayerEvents() {
 					case PlayerEventSubtitleFileUploaded:
 						payload := &subtitleFileUploadedPayload{}
 						if err := playerEvent.UnmarshalAs(&payload); err == nil {
-							p.setPlaybackStatus(func() {
-								p.playbackStatus.ClientId = playerEvent.ClientId
-							})
+							p.setPlaybackStatus(playerEvent.ClientId, func(status *PlaybackStatus) {})
 							p.NotifySubscribers(&SubtitleFileUploadedEvent{
 								BaseVideoEvent: BaseVideoEvent{ClientId: playerEvent.ClientId},
 								Filename:       payload.Filename,
@@ -372,24 +358,22 @@ func (p *NativePlayer) listenToPlayerEvents() {
 							})
 						}
 					case PlayerEventVideoTerminated:
-						p.setPlaybackStatus(func() {
-							p.playbackStatus.ClientId = playerEvent.ClientId
-						})
+						p.setPlaybackStatus(playerEvent.ClientId, func(status *PlaybackStatus) {})
 						p.NotifySubscribers(&VideoTerminatedEvent{
 							BaseVideoEvent: BaseVideoEvent{ClientId: playerEvent.ClientId},
 						})
+						p.removePlaybackStatus(playerEvent.ClientId)
 					case PlayerEventVideoTimeUpdate:
 						payload := &videoTimeUpdatePayload{}
 						if err := playerEvent.UnmarshalAs(&payload); err == nil {
-							p.setPlaybackStatus(func() {
-								p.playbackStatus.ClientId = playerEvent.ClientId
-								p.playbackStatus.CurrentTime = payload.CurrentTime
-								p.playbackStatus.Duration = payload.Duration
-								p.playbackStatus.Paused = payload.Paused
+							status := p.setPlaybackStatus(playerEvent.ClientId, func(status *PlaybackStatus) {
+								status.CurrentTime = payload.CurrentTime
+								status.Duration = payload.Duration
+								status.Paused = payload.Paused
 							})
 							p.NotifySubscribers(&VideoStatusEvent{
 								BaseVideoEvent: BaseVideoEvent{ClientId: playerEvent.ClientId},
-								Status:         *p.playbackStatus,
+								Status:         status,
 							})
 						}
 					}
@@ -6,6 +6,8 @@ import (
 	"seanime/internal/events"
 	"seanime/internal/library/anime"
 	"seanime/internal/mkvparser"
+	"seanime/internal/skipsegments"
+	"seanime/internal/trackpreference"
 	"seanime/internal/util/result"
 	"sync"
 
@@ -32,6 +34,12 @@ type (
 		EntryListData *anime.EntryListData `json:"entryListData,omitempty"` // nil if not in list
 		Episode       *anime.Episode       `json:"episode"`
 		Media         *anilist.BaseAnime   `json:"media"`
+		// Skippable ranges of the episode (opening, ending, etc.)
+		SkipSegments []*skipsegments.Segment `json:"skipSegments"`
+		// URL of the WebVTT file referencing the seek preview thumbnails, empty if not generated
+		TrickplayUrl string `json:"trickplayUrl,omitempty"`
+		// Tracks selected by the track preference of the media, the indexes are track numbers. nil if no preference is set
+		TrackSelection *trackpreference.Selection `json:"trackSelection,omitempty"`
 
 		MkvMetadataParser mo.Option[*mkvparser.MetadataParser] `json:"-"`
 	}
@@ -39,15 +47,19 @@ type (
 
 type (
 	// NativePlayer is the built-in HTML5 video player in Seanime.
-	// There can only be one instance of this player at a time.
+	// Each client has its own player, the playback status is tracked per client.
 	NativePlayer struct {
 		wsEventManager              events.WSEventManagerInterface
 		clientPlayerEventSubscriber *events.ClientEventSubscriber
 
 		playbackStatusMu sync.RWMutex
-		playbackStatus   *PlaybackStatus
+		// Playback status of each client
+		playbackStatuses map[string]*PlaybackStatus
+		// Last updated playback status
+		playbackStatus *PlaybackStatus
 
-		seekedEventCancelFunc context.CancelFunc
+		// Cancels the debounced seek event of each client
+		seekedEventCancelFuncs *result.Map[string, context.CancelFunc]
 
 		subscribers *result.Map[string, *Subscriber]
 
@@ -76,7 +88,9 @@ type (
 // New returns a new instance of NativePlayer.
 func New(options NewNativePlayerOptions) *NativePlayer {
 	np := &NativePlayer{
+		playbackStatuses:            make(map[string]*PlaybackStatus),
 		playbackStatus:              &PlaybackStatus{},
+		seekedEventCancelFuncs:      result.NewResultMap[string, context.CancelFunc](),
 		wsEventManager:              options.WsEventManager,
 		clientPlayerEventSubscriber: options.WsEventManager.SubscribeToClientNativePlayerEvents("nativeplayer"),
 		subscribers:                 result.NewResultMap[string, *Subscriber](),
@@ -139,29 +153,52 @@ func (p *NativePlayer) NotifySubscribers(event VideoEvent) {
 
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
-// GetPlaybackStatus returns the current playback status of the player.
+// GetPlaybackStatus returns the last updated playback status of the player.
 func (p *NativePlayer) GetPlaybackStatus() *PlaybackStatus {
 	p.playbackStatusMu.RLock()
 	defer p.playbackStatusMu.RUnlock()
 	return p.playbackStatus
 }
 
+// GetClientPlaybackStatus returns the playback status of the player of a client.
+func (p *NativePlayer) GetClientPlaybackStatus(clientId string) (*PlaybackStatus, bool) {
+	p.playbackStatusMu.RLock()
+	defer p.playbackStatusMu.RUnlock()
+	status, ok := p.playbackStatuses[clientId]
+	return status, ok
+}
+
 func (p *NativePlayer) SetPlaybackStatus(status *PlaybackStatus) {
-	p.setPlaybackStatus(func() {
-		p.playbackStatus = status
+	p.setPlaybackStatus(status.ClientId, func(s *PlaybackStatus) {
+		*s = *status
 	})
 }
 
-// setPlaybackStatus sets the current playback status of the player
+// setPlaybackStatus updates the playback status of the client's player
 // and notifies all subscribers of the change.
-func (p *NativePlayer) setPlaybackStatus(do func()) {
+func (p *NativePlayer) setPlaybackStatus(clientId string, do func(status *PlaybackStatus)) PlaybackStatus {
 	p.playbackStatusMu.Lock()
 	defer p.playbackStatusMu.Unlock()
-	do()
+	status, ok := p.playbackStatuses[clientId]
+	if !ok {
+		status = &PlaybackStatus{}
+		p.playbackStatuses[clientId] = status
+	}
+	do(status)
+	status.ClientId = clientId
+	p.playbackStatus = status
 	p.NotifySubscribers(&VideoStatusEvent{
 		BaseVideoEvent: BaseVideoEvent{
-			ClientId: p.playbackStatus.ClientId,
+			ClientId: clientId,
 		},
-		Status: *p.playbackStatus,
+		Status: *status,
 	})
+	return *status
+}
+
+// removePlaybackStatus removes the playback status of a client once its player is terminated.
+func (p *NativePlayer) removePlaybackStatus(clientId string) {
+	p.playbackStatusMu.Lock()
+	defer p.playbackStatusMu.Unlock()
+	delete(p.playbackStatuses, clientId)
 }
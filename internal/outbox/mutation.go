@@ -0,0 +1,114 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"seanime/internal/api/anilist"
+
+	"github.com/Yamashou/gqlgenc/clientv2"
+)
+
+type (
+	// Mutation is a change to a list entry.
+	// Nil fields are not changed.
+	Mutation struct {
+		// The entry is deleted, the other changes are dropped
+		Delete bool `json:"delete"`
+		// ID passed to platform.Platform.DeleteEntry
+		EntryID     int                      `json:"entryId,omitempty"`
+		Status      *anilist.MediaListStatus `json:"status,omitempty"`
+		ScoreRaw    *int                     `json:"scoreRaw,omitempty"`
+		Progress    *int                     `json:"progress,omitempty"`
+		TotalCount  *int                     `json:"totalCount,omitempty"`
+		StartedAt   *anilist.FuzzyDateInput  `json:"startedAt,omitempty"`
+		CompletedAt *anilist.FuzzyDateInput  `json:"completedAt,omitempty"`
+		Repeat      *int                     `json:"repeat,omitempty"`
+		// State of the remote entry before the first queued change, nil if it was unknown
+		Base *EntryState `json:"base,omitempty"`
+	}
+
+	// EntryState is the part of a remote entry used to detect conflicts.
+	EntryState struct {
+		Exists   bool                    `json:"exists"`
+		Status   anilist.MediaListStatus `json:"status"`
+		Progress int                     `json:"progress"`
+	}
+)
+
+// merge applies the next change on top of the queued one, the base state is kept.
+func (m *Mutation) merge(next *Mutation) {
+	if next.Delete {
+		*m = Mutation{Delete: true, EntryID: next.EntryID, Base: m.Base}
+		return
+	}
+	if m.Delete {
+		*m = Mutation{Base: m.Base}
+	}
+	m.Status = or(next.Status, m.Status)
+	m.ScoreRaw = or(next.ScoreRaw, m.ScoreRaw)
+	m.Progress = or(next.Progress, m.Progress)
+	m.TotalCount = or(next.TotalCount, m.TotalCount)
+	m.StartedAt = or(next.StartedAt, m.StartedAt)
+	m.CompletedAt = or(next.CompletedAt, m.CompletedAt)
+	m.Repeat = or(next.Repeat, m.Repeat)
+}
+
+func or[T any](a, b *T) *T {
+	if a != nil {
+		return a
+	}
+	return b
+}
+
+// isEntryUpdate returns true if the mutation needs a full entry update rather than a progress update.
+func (m *Mutation) isEntryUpdate() bool {
+	return m.Status != nil || m.ScoreRaw != nil || m.StartedAt != nil || m.CompletedAt != nil
+}
+
+// conflict returns why the mutation should not be applied, empty if it can be applied.
+// The entry may have changed elsewhere since the mutation was queued, these changes are only overwritten
+// if the mutation still moves the entry forward.
+func (m *Mutation) conflict(remote *EntryState) string {
+	if m.Base == nil || remote == nil || *remote == *m.Base {
+		return ""
+	}
+
+	switch {
+	case m.Delete:
+		if !remote.Exists {
+			return ""
+		}
+		return "the entry was updated after it was deleted"
+	case m.Base.Exists && !remote.Exists:
+		return "the entry was deleted"
+	case m.Progress != nil && remote.Progress >= *m.Progress:
+		return fmt.Sprintf("the progress is already %d", remote.Progress)
+	case m.Status != nil && remote.Status != m.Base.Status && remote.Status != *m.Status:
+		return fmt.Sprintf("the status was changed to %s", remote.Status)
+	}
+
+	return ""
+}
+
+// isUnreachable returns true if the error means the platform could not be reached, as opposed to the change being rejected.
+func isUnreachable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var urlErr *url.Error
+	var netErr net.Error
+	if errors.As(err, &urlErr) || errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var respErr *clientv2.ErrorResponse
+	if errors.As(err, &respErr) && respErr.NetworkError != nil {
+		return respErr.NetworkError.Code >= 500 || respErr.NetworkError.Code == 429
+	}
+
+	return false
+}
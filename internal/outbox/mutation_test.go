@@ -0,0 +1,109 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"seanime/internal/api/anilist"
+	"testing"
+
+	"github.com/Yamashou/gqlgenc/clientv2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMutationMerge(t *testing.T) {
+	base := &EntryState{Exists: true, Status: anilist.MediaListStatusCurrent, Progress: 2}
+
+	m := &Mutation{Progress: lo.ToPtr(3), Base: base}
+	m.merge(&Mutation{Progress: lo.ToPtr(4)})
+	m.merge(&Mutation{Repeat: lo.ToPtr(1)})
+	assert.Equal(t, 4, *m.Progress)
+	assert.Equal(t, 1, *m.Repeat)
+	assert.False(t, m.isEntryUpdate())
+
+	m.merge(&Mutation{Status: lo.ToPtr(anilist.MediaListStatusCompleted)})
+	assert.True(t, m.isEntryUpdate())
+	assert.Equal(t, 4, *m.Progress)
+
+	// Deleting drops the other changes
+	m.merge(&Mutation{Delete: true, EntryID: 10})
+	assert.Equal(t, &Mutation{Delete: true, EntryID: 10, Base: base}, m)
+
+	// Adding the entry back cancels the deletion
+	m.merge(&Mutation{Progress: lo.ToPtr(1)})
+	assert.False(t, m.Delete)
+	assert.Equal(t, 1, *m.Progress)
+	assert.Same(t, base, m.Base)
+}
+
+func TestMutationConflict(t *testing.T) {
+	base := &EntryState{Exists: true, Status: anilist.MediaListStatusCurrent, Progress: 2}
+
+	tests := []struct {
+		name     string
+		mutation *Mutation
+		remote   *EntryState
+		conflict bool
+	}{
+		{
+			name:     "Remote entry unchanged",
+			mutation: &Mutation{Progress: lo.ToPtr(3), Base: base},
+			remote:   base,
+		},
+		{
+			name:     "Remote entry unknown",
+			mutation: &Mutation{Progress: lo.ToPtr(3), Base: base},
+		},
+		{
+			name:     "Progress moves forward",
+			mutation: &Mutation{Progress: lo.ToPtr(5), Base: base},
+			remote:   &EntryState{Exists: true, Status: anilist.MediaListStatusCurrent, Progress: 4},
+		},
+		{
+			name:     "Progress already higher",
+			mutation: &Mutation{Progress: lo.ToPtr(3), Base: base},
+			remote:   &EntryState{Exists: true, Status: anilist.MediaListStatusCurrent, Progress: 6},
+			conflict: true,
+		},
+		{
+			name:     "Entry deleted remotely",
+			mutation: &Mutation{Progress: lo.ToPtr(3), Base: base},
+			remote:   &EntryState{},
+			conflict: true,
+		},
+		{
+			name:     "Status changed remotely",
+			mutation: &Mutation{Status: lo.ToPtr(anilist.MediaListStatusPaused), Base: base},
+			remote:   &EntryState{Exists: true, Status: anilist.MediaListStatusDropped, Progress: 2},
+			conflict: true,
+		},
+		{
+			name:     "Entry updated remotely after being deleted",
+			mutation: &Mutation{Delete: true, Base: base},
+			remote:   &EntryState{Exists: true, Status: anilist.MediaListStatusCurrent, Progress: 3},
+			conflict: true,
+		},
+		{
+			name:     "No base state",
+			mutation: &Mutation{Progress: lo.ToPtr(3)},
+			remote:   &EntryState{Exists: true, Progress: 6},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.conflict, tt.mutation.conflict(tt.remote) != "")
+		})
+	}
+}
+
+func TestIsUnreachable(t *testing.T) {
+	assert.False(t, isUnreachable(nil))
+	assert.False(t, isUnreachable(errors.New("invalid media")))
+	assert.True(t, isUnreachable(fmt.Errorf("request failed: %w", &url.Error{Op: "Post", URL: "https://graphql.anilist.co", Err: errors.New("no such host")})))
+	assert.True(t, isUnreachable(context.DeadlineExceeded))
+	assert.True(t, isUnreachable(&clientv2.ErrorResponse{NetworkError: &clientv2.HTTPError{Code: 503}}))
+	assert.False(t, isUnreachable(&clientv2.ErrorResponse{NetworkError: &clientv2.HTTPError{Code: 400}}))
+}
@@ -0,0 +1,465 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"seanime/internal/api/anilist"
+	"seanime/internal/database/db"
+	"seanime/internal/database/models"
+	"seanime/internal/events"
+	"seanime/internal/platforms/anilist_platform"
+	"seanime/internal/platforms/mal_platform"
+	"seanime/internal/platforms/platform"
+	"seanime/internal/util"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+	"github.com/samber/lo"
+)
+
+const (
+	StatusPending  = "pending"
+	StatusFailed   = "failed"
+	StatusConflict = "conflict"
+
+	// Interval between two checks for mutations to replay
+	replayInterval = 30 * time.Second
+	// Delay before the first retry, doubled after each attempt
+	retryDelay    = 30 * time.Second
+	maxRetryDelay = 30 * time.Minute
+	// Number of attempts before a mutation is marked as failed
+	maxAttempts = 10
+)
+
+var ErrMutationNotFound = errors.New("mutation not found")
+
+type (
+	// Manager queues the list mutations that could not be sent because the account platform was unreachable,
+	// and replays them once it is reachable again.
+	Manager struct {
+		logger                     *zerolog.Logger
+		db                         *db.Database
+		wsEventManager             events.WSEventManagerInterface
+		refreshAnimeCollectionFunc func()
+		refreshMangaCollectionFunc func()
+		wakeCh                     chan struct{}
+
+		mu sync.Mutex
+		// Platform the mutations are replayed to, nil if the active platform is local
+		platform     platform.Platform
+		platformName string
+		// The platform was reached, the next replay ignores the retry delays
+		reachable bool
+	}
+
+	NewManagerOptions struct {
+		Logger         *zerolog.Logger
+		Database       *db.Database
+		WSEventManager events.WSEventManagerInterface
+	}
+
+	// Item is a queued mutation.
+	Item struct {
+		ID            uint      `json:"id"`
+		Platform      string    `json:"platform"`
+		MediaID       int       `json:"mediaId"`
+		Mutation      *Mutation `json:"mutation"`
+		Status        string    `json:"status"`
+		Attempts      int       `json:"attempts"`
+		NextAttemptAt time.Time `json:"nextAttemptAt"`
+		Error         string    `json:"error"`
+		CreatedAt     time.Time `json:"createdAt"`
+	}
+)
+
+func NewManager(opts *NewManagerOptions) *Manager {
+	m := &Manager{
+		logger:         opts.Logger,
+		db:             opts.Database,
+		wsEventManager: opts.WSEventManager,
+		wakeCh:         make(chan struct{}, 1),
+	}
+
+	go m.worker()
+
+	return m
+}
+
+// SetRefreshCollectionFuncs sets the functions called after mutations were replayed.
+func (m *Manager) SetRefreshCollectionFuncs(refreshAnimeCollectionFunc func(), refreshMangaCollectionFunc func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.refreshAnimeCollectionFunc = refreshAnimeCollectionFunc
+	m.refreshMangaCollectionFunc = refreshMangaCollectionFunc
+}
+
+// Wrap returns the platform with its mutations going through the outbox.
+// Local platforms are returned unchanged, the replay is paused while they are active.
+func (m *Manager) Wrap(p platform.Platform) platform.Platform {
+	if wrapped, ok := p.(*Platform); ok {
+		p = wrapped.Platform
+	}
+
+	name := platformName(p)
+
+	m.mu.Lock()
+	m.platform, m.platformName = nil, ""
+	if name != "" {
+		m.platform, m.platformName = p, name
+	}
+	m.mu.Unlock()
+
+	if name == "" {
+		return p
+	}
+
+	m.wake()
+
+	return &Platform{Platform: p, manager: m, name: name}
+}
+
+func platformName(p platform.Platform) string {
+	switch p.(type) {
+	case *anilist_platform.AnilistPlatform:
+		return "anilist"
+	case *mal_platform.MalPlatform:
+		return "mal"
+	}
+	return ""
+}
+
+func (m *Manager) GetItems() ([]*Item, error) {
+	mutations, err := m.db.GetOutboxMutations()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]*Item, 0, len(mutations))
+	for _, mutation := range mutations {
+		value := new(Mutation)
+		if err := json.Unmarshal(mutation.Value, value); err != nil {
+			continue
+		}
+		ret = append(ret, &Item{
+			ID:            mutation.ID,
+			Platform:      mutation.Platform,
+			MediaID:       mutation.MediaID,
+			Mutation:      value,
+			Status:        mutation.Status,
+			Attempts:      mutation.Attempts,
+			NextAttemptAt: mutation.NextAttemptAt,
+			Error:         mutation.Error,
+			CreatedAt:     mutation.CreatedAt,
+		})
+	}
+	return ret, nil
+}
+
+// Retry replays the mutation as soon as possible.
+// Conflicts are ignored, the mutation overwrites the remote entry.
+func (m *Manager) Retry(id uint) error {
+	mutation, err := m.db.GetOutboxMutationByID(id)
+	if err != nil {
+		return ErrMutationNotFound
+	}
+
+	value := new(Mutation)
+	if err := json.Unmarshal(mutation.Value, value); err != nil {
+		return err
+	}
+	value.Base = nil
+	if mutation.Value, err = json.Marshal(value); err != nil {
+		return err
+	}
+
+	mutation.Status = StatusPending
+	mutation.Attempts = 0
+	mutation.NextAttemptAt = time.Now()
+	mutation.Error = ""
+	if err := m.db.SaveOutboxMutation(mutation); err != nil {
+		return err
+	}
+
+	m.wake()
+	return nil
+}
+
+// Discard removes the mutation without applying it.
+func (m *Manager) Discard(id uint) error {
+	return m.db.DeleteOutboxMutation(id)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// do applies the mutation, or queues it if the platform is unreachable or older mutations of the media are still queued.
+func (m *Manager) do(ctx context.Context, p *Platform, mediaId int, mutation *Mutation, apply func() error) error {
+	return m.doLazy(ctx, p, func() int { return mediaId }, mutation, apply)
+}
+
+// doLazy is like do but the media ID is only resolved if mutations are queued for the platform or the mutation has to be queued.
+func (m *Manager) doLazy(ctx context.Context, p *Platform, resolveMediaId func() int, mutation *Mutation, apply func() error) error {
+	mediaId := 0
+	getMediaId := func() int {
+		if mediaId == 0 {
+			mediaId = resolveMediaId()
+		}
+		return mediaId
+	}
+
+	hasQueued, err := m.db.HasOutboxMutations(p.name)
+	if err != nil {
+		return err
+	}
+
+	var queued *models.OutboxMutation
+	if hasQueued {
+		queued, err = m.db.GetOutboxMutation(p.name, getMediaId())
+		if err != nil {
+			return err
+		}
+	}
+
+	// Older mutations of the media are applied first
+	if queued != nil {
+		if err := m.enqueue(ctx, p, getMediaId(), mutation, queued); err != nil {
+			return err
+		}
+		m.wake()
+		m.wsEventManager.SendEvent(events.InvalidateQueries, []string{events.GetOutboxItemsEndpoint})
+		return nil
+	}
+
+	err = apply()
+	if !isUnreachable(err) {
+		// The platform is reachable again, replay the queued mutations without waiting
+		if err == nil {
+			m.mu.Lock()
+			m.reachable = true
+			m.mu.Unlock()
+			m.wake()
+		}
+		return err
+	}
+
+	m.logger.Warn().Err(err).Int("mediaId", getMediaId()).Msg("outbox: Platform unreachable, queuing mutation")
+
+	if err := m.enqueue(ctx, p, getMediaId(), mutation, nil); err != nil {
+		return err
+	}
+
+	m.wsEventManager.SendEvent(events.WarningToast, "Could not reach "+p.displayName()+", the change will be sent later")
+	m.wsEventManager.SendEvent(events.InvalidateQueries, []string{events.GetOutboxItemsEndpoint})
+	return nil
+}
+
+// enqueue merges the mutation into the queued mutation of the media, or queues it.
+func (m *Manager) enqueue(ctx context.Context, p *Platform, mediaId int, mutation *Mutation, queued *models.OutboxMutation) error {
+	value := mutation
+	if queued != nil {
+		value = new(Mutation)
+		if err := json.Unmarshal(queued.Value, value); err != nil {
+			return err
+		}
+		value.merge(mutation)
+	} else {
+		// The cached lists hold the last known state of the remote entry
+		value.Base = loadRemoteLists(ctx, p.Platform, false).state(mediaId)
+		queued = &models.OutboxMutation{
+			Platform: p.name,
+			MediaID:  mediaId,
+		}
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	queued.Value = data
+	queued.Status = StatusPending
+	queued.Attempts = 0
+	queued.NextAttemptAt = time.Now()
+	if queued.ID == 0 {
+		queued.NextAttemptAt = time.Now().Add(retryDelay)
+	}
+	queued.Error = ""
+	return m.db.SaveOutboxMutation(queued)
+}
+
+func (m *Manager) wake() {
+	select {
+	case m.wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) worker() {
+	ticker := time.NewTicker(replayInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-m.wakeCh:
+		}
+		m.replay()
+	}
+}
+
+// replay applies the due mutations of the active platform.
+func (m *Manager) replay() {
+	defer util.HandlePanicInModuleThen("outbox/replay", func() {})
+
+	m.mu.Lock()
+	p, name, reachable := m.platform, m.platformName, m.reachable
+	m.reachable = false
+	m.mu.Unlock()
+	if p == nil {
+		return
+	}
+
+	mutations, err := m.db.GetOutboxMutations()
+	if err != nil {
+		return
+	}
+	mutations = lo.Filter(mutations, func(mutation *models.OutboxMutation, _ int) bool {
+		return mutation.Platform == name && mutation.Status == StatusPending && (reachable || !mutation.NextAttemptAt.After(time.Now()))
+	})
+	if len(mutations) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	remote := loadRemoteLists(ctx, p, true)
+
+	applied := 0
+	for _, mutation := range mutations {
+		value := new(Mutation)
+		if err := json.Unmarshal(mutation.Value, value); err != nil {
+			_ = m.db.DeleteOutboxMutation(mutation.ID)
+			continue
+		}
+
+		state := remote.state(mutation.MediaID)
+
+		// Already deleted
+		if value.Delete && state != nil && !state.Exists {
+			_ = m.db.DeleteOutboxMutation(mutation.ID)
+			continue
+		}
+
+		if reason := value.conflict(state); reason != "" {
+			m.logger.Warn().Int("mediaId", mutation.MediaID).Str("reason", reason).Msg("outbox: Mutation conflicts with the remote entry")
+			mutation.Status = StatusConflict
+			mutation.Error = reason
+			_ = m.db.SaveOutboxMutation(mutation)
+			continue
+		}
+
+		err := apply(ctx, p, mutation.MediaID, value)
+		if err == nil {
+			m.logger.Debug().Int("mediaId", mutation.MediaID).Msg("outbox: Mutation replayed")
+			_ = m.db.DeleteOutboxMutation(mutation.ID)
+			applied++
+			continue
+		}
+
+		mutation.Attempts++
+		mutation.Error = err.Error()
+		if !isUnreachable(err) || mutation.Attempts >= maxAttempts {
+			m.logger.Error().Err(err).Int("mediaId", mutation.MediaID).Msg("outbox: Mutation failed")
+			mutation.Status = StatusFailed
+		} else {
+			mutation.NextAttemptAt = time.Now().Add(min(maxRetryDelay, retryDelay*time.Duration(1<<(mutation.Attempts-1))))
+		}
+		_ = m.db.SaveOutboxMutation(mutation)
+
+		// The next mutations would fail too
+		if isUnreachable(err) {
+			break
+		}
+	}
+
+	if applied > 0 {
+		m.mu.Lock()
+		refreshAnime, refreshManga := m.refreshAnimeCollectionFunc, m.refreshMangaCollectionFunc
+		m.mu.Unlock()
+		if refreshAnime != nil {
+			refreshAnime()
+		}
+		if refreshManga != nil {
+			refreshManga()
+		}
+	}
+
+	m.wsEventManager.SendEvent(events.InvalidateQueries, []string{events.GetOutboxItemsEndpoint})
+}
+
+func apply(ctx context.Context, p platform.Platform, mediaId int, mutation *Mutation) error {
+	if mutation.Delete {
+		return p.DeleteEntry(ctx, mutation.EntryID)
+	}
+
+	var err error
+	if mutation.isEntryUpdate() {
+		err = p.UpdateEntry(ctx, mediaId, mutation.Status, mutation.ScoreRaw, mutation.Progress, mutation.StartedAt, mutation.CompletedAt)
+	} else if mutation.Progress != nil {
+		err = p.UpdateEntryProgress(ctx, mediaId, *mutation.Progress, mutation.TotalCount)
+	}
+	if err == nil && mutation.Repeat != nil {
+		err = p.UpdateEntryRepeat(ctx, mediaId, *mutation.Repeat)
+	}
+	return err
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// remoteLists are the collections of the platform, nil if they could not be fetched.
+type remoteLists struct {
+	anime *anilist.AnimeCollection
+	manga *anilist.MangaCollection
+}
+
+func loadRemoteLists(ctx context.Context, p platform.Platform, bypassCache bool) *remoteLists {
+	ret := &remoteLists{}
+	ret.anime, _ = p.GetAnimeCollection(ctx, bypassCache)
+	ret.manga, _ = p.GetMangaCollection(ctx, bypassCache)
+	return ret
+}
+
+// state returns the state of the entry, nil if it is unknown.
+func (r *remoteLists) state(mediaId int) *EntryState {
+	if r.anime == nil || r.manga == nil {
+		return nil
+	}
+	if entry, found := r.anime.GetListEntryFromAnimeId(mediaId); found {
+		return &EntryState{Exists: true, Status: lo.FromPtr(entry.GetStatus()), Progress: lo.FromPtr(entry.GetProgress())}
+	}
+	if entry, found := r.manga.GetListEntryFromMangaId(mediaId); found {
+		return &EntryState{Exists: true, Status: lo.FromPtr(entry.GetStatus()), Progress: lo.FromPtr(entry.GetProgress())}
+	}
+	return &EntryState{Exists: false}
+}
+
+// mediaIdFromEntryId returns the media ID of the list entry, 0 if it is not in the lists.
+func (r *remoteLists) mediaIdFromEntryId(entryId int) int {
+	for _, list := range r.anime.GetMediaListCollection().GetLists() {
+		for _, entry := range list.GetEntries() {
+			if entry.GetID() == entryId {
+				return entry.GetMedia().GetID()
+			}
+		}
+	}
+	for _, list := range r.manga.GetMediaListCollection().GetLists() {
+		for _, entry := range list.GetEntries() {
+			if entry.GetID() == entryId {
+				return entry.GetMedia().GetID()
+			}
+		}
+	}
+	return 0
+}
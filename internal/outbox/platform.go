@@ -0,0 +1,72 @@
+package outbox
+
+import (
+	"context"
+	"seanime/internal/api/anilist"
+	"seanime/internal/platforms/platform"
+)
+
+// Platform sends the list mutations of the wrapped platform through the outbox.
+// The other methods are those of the wrapped platform.
+type Platform struct {
+	platform.Platform
+	manager *Manager
+	name    string
+}
+
+func (p *Platform) displayName() string {
+	if p.name == "mal" {
+		return "MyAnimeList"
+	}
+	return "AniList"
+}
+
+func (p *Platform) UpdateEntry(ctx context.Context, mediaID int, status *anilist.MediaListStatus, scoreRaw *int, progress *int, startedAt *anilist.FuzzyDateInput, completedAt *anilist.FuzzyDateInput) error {
+	mutation := &Mutation{
+		Status:      status,
+		ScoreRaw:    scoreRaw,
+		Progress:    progress,
+		StartedAt:   startedAt,
+		CompletedAt: completedAt,
+	}
+	return p.manager.do(ctx, p, mediaID, mutation, func() error {
+		return p.Platform.UpdateEntry(ctx, mediaID, status, scoreRaw, progress, startedAt, completedAt)
+	})
+}
+
+func (p *Platform) UpdateEntryProgress(ctx context.Context, mediaID int, progress int, totalEpisodes *int) error {
+	mutation := &Mutation{
+		Progress:   &progress,
+		TotalCount: totalEpisodes,
+	}
+	return p.manager.do(ctx, p, mediaID, mutation, func() error {
+		return p.Platform.UpdateEntryProgress(ctx, mediaID, progress, totalEpisodes)
+	})
+}
+
+func (p *Platform) UpdateEntryRepeat(ctx context.Context, mediaID int, repeat int) error {
+	mutation := &Mutation{
+		Repeat: &repeat,
+	}
+	return p.manager.do(ctx, p, mediaID, mutation, func() error {
+		return p.Platform.UpdateEntryRepeat(ctx, mediaID, repeat)
+	})
+}
+
+// DeleteEntry queues the deletion under the media of the entry, so that it replaces the queued changes of the media.
+// The media is looked up in the cached lists only when the deletion has to be queued.
+func (p *Platform) DeleteEntry(ctx context.Context, entryID int) error {
+	mediaID := func() int {
+		if id := loadRemoteLists(ctx, p.Platform, false).mediaIdFromEntryId(entryID); id != 0 {
+			return id
+		}
+		return entryID
+	}
+	mutation := &Mutation{
+		Delete:  true,
+		EntryID: entryID,
+	}
+	return p.manager.doLazy(ctx, p, mediaID, mutation, func() error {
+		return p.Platform.DeleteEntry(ctx, entryID)
+	})
+}
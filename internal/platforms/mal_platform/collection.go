@@ -0,0 +1,264 @@
+package mal_platform
+
+import (
+	"fmt"
+	"math"
+	"seanime/internal/api/anilist"
+	"seanime/internal/api/mal"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+	"github.com/samber/lo"
+)
+
+// Number of MyAnimeList IDs looked up per AniList request
+const mediaLookupChunkSize = 40
+
+// The lookup queries reuse the fragments of the generated queries so that the media decode as BaseAnime and BaseManga
+var (
+	baseAnimeByMalIdsDocument = `query BaseAnimeByMalIds ($ids: [Int]) {
+	Page(perPage: 50) {
+		media(idMal_in: $ids, type: ANIME) {
+			... baseAnime
+		}
+	}
+}
+` + fragmentOf(anilist.BaseAnimeByIDDocument)
+
+	baseMangaByMalIdsDocument = `query BaseMangaByMalIds ($ids: [Int]) {
+	Page(perPage: 50) {
+		media(idMal_in: $ids, type: MANGA) {
+			... baseManga
+		}
+	}
+}
+` + fragmentOf(anilist.BaseMangaByIDDocument)
+)
+
+func fragmentOf(document string) string {
+	return document[strings.Index(document, "fragment "):]
+}
+
+// fetchMediaByMalIds returns the AniList media of the given MyAnimeList IDs, by MyAnimeList ID.
+// T is anilist.BaseAnime or anilist.BaseManga, the document must match it.
+func fetchMediaByMalIds[T any](document string, malIds []int, getMalId func(*T) *int, logger *zerolog.Logger) (map[int]*T, error) {
+	ret := make(map[int]*T, len(malIds))
+
+	for _, chunk := range lo.Chunk(lo.Uniq(malIds), mediaLookupChunkSize) {
+		data, err := anilist.CustomQuery(map[string]interface{}{
+			"query":     document,
+			"variables": map[string]interface{}{"ids": chunk},
+		}, logger, "")
+		if err != nil {
+			return nil, err
+		}
+
+		var res struct {
+			Page struct {
+				Media []*T `json:"media"`
+			} `json:"Page"`
+		}
+		dataB, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(dataB, &res); err != nil {
+			return nil, err
+		}
+
+		for _, media := range res.Page.Media {
+			malId := lo.FromPtr(getMalId(media))
+			// Keep the first match if several AniList media share a MyAnimeList ID
+			if _, found := ret[malId]; malId == 0 || found {
+				continue
+			}
+			ret[malId] = media
+		}
+	}
+
+	return ret, nil
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// buildAnimeCollection converts the MyAnimeList anime list into an AniList collection.
+// Entries whose anime could not be found on AniList are skipped.
+// The ID of an entry is the AniList ID of its media, since MyAnimeList list entries have no ID of their own.
+func buildAnimeCollection(entries []*mal.AnimeListEntry, media map[int]*anilist.BaseAnime) *anilist.AnimeCollection {
+	lists := make(map[anilist.MediaListStatus]*anilist.AnimeCollection_MediaListCollection_Lists)
+	ret := &anilist.AnimeCollection{
+		MediaListCollection: &anilist.AnimeCollection_MediaListCollection{
+			Lists: make([]*anilist.AnimeCollection_MediaListCollection_Lists, 0),
+		},
+	}
+
+	for _, entry := range entries {
+		m, ok := media[entry.Node.ID]
+		if !ok {
+			continue
+		}
+
+		status := fromMalStatus(entry.ListStatus.Status, entry.ListStatus.IsRewatching)
+		list, ok := lists[status]
+		if !ok {
+			list = &anilist.AnimeCollection_MediaListCollection_Lists{
+				Status:       lo.ToPtr(status),
+				Name:         lo.ToPtr(string(status)),
+				IsCustomList: lo.ToPtr(false),
+				Entries:      make([]*anilist.AnimeCollection_MediaListCollection_Lists_Entries, 0),
+			}
+			lists[status] = list
+			ret.MediaListCollection.Lists = append(ret.MediaListCollection.Lists, list)
+		}
+
+		startedAt := parseMalDate(entry.ListStatus.StartDate)
+		completedAt := parseMalDate(entry.ListStatus.FinishDate)
+		list.Entries = append(list.Entries, &anilist.AnimeCollection_MediaListCollection_Lists_Entries{
+			ID:       m.GetID(),
+			Score:    lo.ToPtr(float64(entry.ListStatus.Score * 10)),
+			Progress: lo.ToPtr(entry.ListStatus.NumEpisodesWatched),
+			Status:   lo.ToPtr(status),
+			Repeat:   lo.ToPtr(0),
+			Private:  lo.ToPtr(false),
+			StartedAt: &anilist.AnimeCollection_MediaListCollection_Lists_Entries_StartedAt{
+				Year: startedAt.Year, Month: startedAt.Month, Day: startedAt.Day,
+			},
+			CompletedAt: &anilist.AnimeCollection_MediaListCollection_Lists_Entries_CompletedAt{
+				Year: completedAt.Year, Month: completedAt.Month, Day: completedAt.Day,
+			},
+			Media: m,
+		})
+	}
+
+	return ret
+}
+
+// buildMangaCollection converts the MyAnimeList manga list into an AniList collection.
+// Entries whose manga could not be found on AniList are skipped, novels are removed like on the AniList platform.
+func buildMangaCollection(entries []*mal.MangaListEntry, media map[int]*anilist.BaseManga) *anilist.MangaCollection {
+	lists := make(map[anilist.MediaListStatus]*anilist.MangaCollection_MediaListCollection_Lists)
+	ret := &anilist.MangaCollection{
+		MediaListCollection: &anilist.MangaCollection_MediaListCollection{
+			Lists: make([]*anilist.MangaCollection_MediaListCollection_Lists, 0),
+		},
+	}
+
+	for _, entry := range entries {
+		m, ok := media[entry.Node.ID]
+		if !ok || lo.FromPtr(m.GetFormat()) == anilist.MediaFormatNovel {
+			continue
+		}
+
+		status := fromMalStatus(entry.ListStatus.Status, entry.ListStatus.IsRereading)
+		list, ok := lists[status]
+		if !ok {
+			list = &anilist.MangaCollection_MediaListCollection_Lists{
+				Status:       lo.ToPtr(status),
+				Name:         lo.ToPtr(string(status)),
+				IsCustomList: lo.ToPtr(false),
+				Entries:      make([]*anilist.MangaCollection_MediaListCollection_Lists_Entries, 0),
+			}
+			lists[status] = list
+			ret.MediaListCollection.Lists = append(ret.MediaListCollection.Lists, list)
+		}
+
+		startedAt := parseMalDate(entry.ListStatus.StartDate)
+		completedAt := parseMalDate(entry.ListStatus.FinishDate)
+		list.Entries = append(list.Entries, &anilist.MangaCollection_MediaListCollection_Lists_Entries{
+			ID:       m.GetID(),
+			Score:    lo.ToPtr(float64(entry.ListStatus.Score * 10)),
+			Progress: lo.ToPtr(entry.ListStatus.NumChaptersRead),
+			Status:   lo.ToPtr(status),
+			Repeat:   lo.ToPtr(0),
+			Private:  lo.ToPtr(false),
+			StartedAt: &anilist.MangaCollection_MediaListCollection_Lists_Entries_StartedAt{
+				Year: startedAt.Year, Month: startedAt.Month, Day: startedAt.Day,
+			},
+			CompletedAt: &anilist.MangaCollection_MediaListCollection_Lists_Entries_CompletedAt{
+				Year: completedAt.Year, Month: completedAt.Month, Day: completedAt.Day,
+			},
+			Media: m,
+		})
+	}
+
+	return ret
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// fromMalStatus converts a MyAnimeList status, rewatched and reread entries are repeating on AniList.
+func fromMalStatus(status mal.MediaListStatus, repeating bool) anilist.MediaListStatus {
+	if repeating {
+		return anilist.MediaListStatusRepeating
+	}
+	switch status {
+	case mal.MediaListStatusWatching, mal.MediaListStatusReading:
+		return anilist.MediaListStatusCurrent
+	case mal.MediaListStatusCompleted:
+		return anilist.MediaListStatusCompleted
+	case mal.MediaListStatusOnHold:
+		return anilist.MediaListStatusPaused
+	case mal.MediaListStatusDropped:
+		return anilist.MediaListStatusDropped
+	default:
+		return anilist.MediaListStatusPlanning
+	}
+}
+
+// toMalStatus converts an AniList status, it returns true if the entry is being rewatched or reread.
+func toMalStatus(status anilist.MediaListStatus, isManga bool) (mal.MediaListStatus, bool) {
+	switch status {
+	case anilist.MediaListStatusCurrent:
+		return lo.Ternary(isManga, mal.MediaListStatusReading, mal.MediaListStatusWatching), false
+	case anilist.MediaListStatusRepeating:
+		return lo.Ternary(isManga, mal.MediaListStatusReading, mal.MediaListStatusWatching), true
+	case anilist.MediaListStatusCompleted:
+		return mal.MediaListStatusCompleted, false
+	case anilist.MediaListStatusPaused:
+		return mal.MediaListStatusOnHold, false
+	case anilist.MediaListStatusDropped:
+		return mal.MediaListStatusDropped, false
+	default:
+		return lo.Ternary(isManga, mal.MediaListStatusPlanToRead, mal.MediaListStatusPlanToWatch), false
+	}
+}
+
+// toMalScore converts an AniList raw score (out of 100) to a MyAnimeList score (out of 10).
+func toMalScore(scoreRaw int) int {
+	return min(10, max(0, int(math.Round(float64(scoreRaw)/10))))
+}
+
+// parseMalDate parses a MyAnimeList date, which can be "2006-01-02", "2006-01" or "2006".
+func parseMalDate(value string) *anilist.FuzzyDateInput {
+	ret := &anilist.FuzzyDateInput{}
+	parts := strings.Split(value, "-")
+	fields := []**int{&ret.Year, &ret.Month, &ret.Day}
+	for i, part := range parts {
+		if i >= len(fields) {
+			break
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n == 0 {
+			break
+		}
+		*fields[i] = lo.ToPtr(n)
+	}
+	return ret
+}
+
+// toMalDate formats a date for MyAnimeList, nil if the year is not set.
+func toMalDate(date *anilist.FuzzyDateInput) *string {
+	if date == nil || date.Year == nil {
+		return nil
+	}
+	ret := fmt.Sprintf("%04d", *date.Year)
+	if date.Month != nil {
+		ret += fmt.Sprintf("-%02d", *date.Month)
+		if date.Day != nil {
+			ret += fmt.Sprintf("-%02d", *date.Day)
+		}
+	}
+	return &ret
+}
@@ -0,0 +1,88 @@
+package mal_platform
+
+import (
+	"seanime/internal/api/anilist"
+	"seanime/internal/api/mal"
+	"strings"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupDocuments(t *testing.T) {
+	assert.True(t, strings.Contains(baseAnimeByMalIdsDocument, "fragment baseAnime on Media"))
+	assert.True(t, strings.Contains(baseMangaByMalIdsDocument, "fragment baseManga on Media"))
+}
+
+func TestBuildAnimeCollection(t *testing.T) {
+	entries := []*mal.AnimeListEntry{{}, {}, {}}
+	entries[0].Node.ID = 101
+	entries[0].ListStatus.Status = mal.MediaListStatusWatching
+	entries[0].ListStatus.NumEpisodesWatched = 3
+	entries[0].ListStatus.Score = 7
+	entries[0].ListStatus.StartDate = "2024-03-05"
+	entries[1].Node.ID = 102
+	entries[1].ListStatus.Status = mal.MediaListStatusCompleted
+	entries[1].ListStatus.IsRewatching = true
+	// Not found on AniList
+	entries[2].Node.ID = 103
+
+	media := map[int]*anilist.BaseAnime{
+		101: {ID: 1, IDMal: lo.ToPtr(101)},
+		102: {ID: 2, IDMal: lo.ToPtr(102)},
+	}
+
+	collection := buildAnimeCollection(entries, media)
+	require.Len(t, collection.GetMediaListCollection().GetLists(), 2)
+
+	entry, found := collection.GetListEntryFromAnimeId(1)
+	require.True(t, found)
+	assert.Equal(t, 1, entry.GetID())
+	assert.Equal(t, anilist.MediaListStatusCurrent, *entry.GetStatus())
+	assert.Equal(t, 3, *entry.GetProgress())
+	assert.Equal(t, 70.0, *entry.GetScore())
+	assert.Equal(t, 2024, *entry.GetStartedAt().GetYear())
+	assert.Equal(t, 5, *entry.GetStartedAt().GetDay())
+
+	entry, found = collection.GetListEntryFromAnimeId(2)
+	require.True(t, found)
+	assert.Equal(t, anilist.MediaListStatusRepeating, *entry.GetStatus())
+}
+
+func TestBuildMangaCollectionSkipsNovels(t *testing.T) {
+	entries := []*mal.MangaListEntry{{}, {}}
+	entries[0].Node.ID = 201
+	entries[1].Node.ID = 202
+
+	media := map[int]*anilist.BaseManga{
+		201: {ID: 1, Format: lo.ToPtr(anilist.MediaFormatManga)},
+		202: {ID: 2, Format: lo.ToPtr(anilist.MediaFormatNovel)},
+	}
+
+	collection := buildMangaCollection(entries, media)
+	_, found := collection.GetListEntryFromMangaId(1)
+	assert.True(t, found)
+	_, found = collection.GetListEntryFromMangaId(2)
+	assert.False(t, found)
+}
+
+func TestMalDates(t *testing.T) {
+	date := parseMalDate("2023-07")
+	assert.Equal(t, 2023, *date.Year)
+	assert.Equal(t, 7, *date.Month)
+	assert.Nil(t, date.Day)
+
+	assert.Nil(t, parseMalDate("").Year)
+
+	assert.Equal(t, "2023-07", *toMalDate(date))
+	assert.Equal(t, "2023-07-09", *toMalDate(&anilist.FuzzyDateInput{Year: lo.ToPtr(2023), Month: lo.ToPtr(7), Day: lo.ToPtr(9)}))
+	assert.Nil(t, toMalDate(&anilist.FuzzyDateInput{}))
+}
+
+func TestToMalScore(t *testing.T) {
+	assert.Equal(t, 8, toMalScore(75))
+	assert.Equal(t, 0, toMalScore(0))
+	assert.Equal(t, 10, toMalScore(100))
+}
@@ -0,0 +1,583 @@
+package mal_platform
+
+import (
+	"context"
+	"errors"
+	"seanime/internal/api/anilist"
+	"seanime/internal/api/mal"
+	"seanime/internal/database/db"
+	"seanime/internal/hook"
+	"seanime/internal/platforms/anilist_platform"
+	"seanime/internal/platforms/platform"
+	"seanime/internal/util/limiter"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+)
+
+var (
+	ErrNotConnected  = errors.New("not logged in to MyAnimeList")
+	ErrMediaNotFound = errors.New("media not found on MyAnimeList")
+)
+
+// MalPlatform uses the MyAnimeList lists of the user as the collections.
+//
+// Media data still comes from AniList, MyAnimeList entries are matched to AniList media using their MyAnimeList ID
+// so that the rest of the app can keep working with AniList IDs.
+// The ID of an entry in the collections is the AniList ID of its media.
+type MalPlatform struct {
+	logger          *zerolog.Logger
+	db              *db.Database
+	anilistClient   anilist.AnilistClient // used for media data
+	animeCollection mo.Option[*anilist.AnimeCollection]
+	mangaCollection mo.Option[*anilist.MangaCollection]
+	mu              sync.RWMutex // guards the collections
+	rateLimiter     *limiter.Limiter
+}
+
+func NewMalPlatform(db *db.Database, anilistClient anilist.AnilistClient, logger *zerolog.Logger) platform.Platform {
+	return &MalPlatform{
+		logger:          logger,
+		db:              db,
+		anilistClient:   anilistClient,
+		animeCollection: mo.None[*anilist.AnimeCollection](),
+		mangaCollection: mo.None[*anilist.MangaCollection](),
+		rateLimiter:     limiter.NewLimiter(time.Second, 3),
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Implementation
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (mp *MalPlatform) SetUsername(username string) {
+	// no-op, the MyAnimeList lists are fetched with the token
+}
+
+func (mp *MalPlatform) SetAnilistClient(client anilist.AnilistClient) {
+	mp.anilistClient = client
+}
+
+func (mp *MalPlatform) UpdateEntry(ctx context.Context, mediaID int, status *anilist.MediaListStatus, scoreRaw *int, progress *int, startedAt *anilist.FuzzyDateInput, completedAt *anilist.FuzzyDateInput) error {
+	mp.logger.Trace().Int("mediaID", mediaID).Msg("mal platform: Updating entry")
+
+	event := new(anilist_platform.PreUpdateEntryEvent)
+	event.MediaID = &mediaID
+	event.Status = status
+	event.ScoreRaw = scoreRaw
+	event.Progress = progress
+	event.StartedAt = startedAt
+	event.CompletedAt = completedAt
+
+	err := hook.GlobalHookManager.OnPreUpdateEntry().Trigger(event)
+	if err != nil {
+		return err
+	}
+
+	if event.DefaultPrevented {
+		return nil
+	}
+
+	malId, isManga, err := mp.findMalId(ctx, mediaID)
+	if err != nil {
+		return err
+	}
+
+	var malStatus *mal.MediaListStatus
+	var repeating *bool
+	if event.Status != nil {
+		s, r := toMalStatus(*event.Status, isManga)
+		malStatus, repeating = &s, &r
+	}
+	var score *int
+	if event.ScoreRaw != nil {
+		score = lo.ToPtr(toMalScore(*event.ScoreRaw))
+	}
+
+	err = mp.updateListStatus(malId, isManga, malStatus, repeating, event.Progress, score, toMalDate(event.StartedAt), toMalDate(event.CompletedAt))
+	if err != nil {
+		return err
+	}
+
+	postEvent := new(anilist_platform.PostUpdateEntryEvent)
+	postEvent.MediaID = &mediaID
+
+	err = hook.GlobalHookManager.OnPostUpdateEntry().Trigger(postEvent)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (mp *MalPlatform) UpdateEntryProgress(ctx context.Context, mediaID int, progress int, totalCount *int) error {
+	mp.logger.Trace().Int("mediaID", mediaID).Int("progress", progress).Msg("mal platform: Updating entry progress")
+
+	event := new(anilist_platform.PreUpdateEntryProgressEvent)
+	event.MediaID = &mediaID
+	event.Progress = &progress
+	event.TotalCount = totalCount
+	event.Status = lo.ToPtr(anilist.MediaListStatusCurrent)
+
+	err := hook.GlobalHookManager.OnPreUpdateEntryProgress().Trigger(event)
+	if err != nil {
+		return err
+	}
+
+	if event.DefaultPrevented {
+		return nil
+	}
+
+	realTotalCount := lo.FromPtr(totalCount)
+
+	// Keep the entry in the repeating list
+	if status, found := mp.findCachedStatus(mediaID); found && status == anilist.MediaListStatusRepeating {
+		*event.Status = anilist.MediaListStatusRepeating
+	}
+	if realTotalCount > 0 && *event.Progress >= realTotalCount {
+		*event.Status = anilist.MediaListStatusCompleted
+		*event.Progress = realTotalCount
+	}
+
+	malId, isManga, err := mp.findMalId(ctx, mediaID)
+	if err != nil {
+		return err
+	}
+
+	malStatus, repeating := toMalStatus(*event.Status, isManga)
+	err = mp.updateListStatus(malId, isManga, &malStatus, &repeating, event.Progress, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	postEvent := new(anilist_platform.PostUpdateEntryProgressEvent)
+	postEvent.MediaID = &mediaID
+
+	err = hook.GlobalHookManager.OnPostUpdateEntryProgress().Trigger(postEvent)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (mp *MalPlatform) UpdateEntryRepeat(ctx context.Context, mediaID int, repeat int) error {
+	mp.logger.Trace().Int("mediaID", mediaID).Int("repeat", repeat).Msg("mal platform: Updating entry repeat")
+
+	event := new(anilist_platform.PreUpdateEntryRepeatEvent)
+	event.MediaID = &mediaID
+	event.Repeat = &repeat
+
+	err := hook.GlobalHookManager.OnPreUpdateEntryRepeat().Trigger(event)
+	if err != nil {
+		return err
+	}
+
+	if event.DefaultPrevented {
+		return nil
+	}
+
+	malId, isManga, err := mp.findMalId(ctx, mediaID)
+	if err != nil {
+		return err
+	}
+
+	wrapper, err := mp.getWrapper()
+	if err != nil {
+		return err
+	}
+
+	mp.rateLimiter.Wait()
+	if isManga {
+		err = wrapper.UpdateMangaListStatus(&mal.MangaListStatusParams{NumTimesReread: event.Repeat}, malId)
+	} else {
+		err = wrapper.UpdateAnimeListStatus(&mal.AnimeListStatusParams{NumTimesRewatched: event.Repeat}, malId)
+	}
+	if err != nil {
+		return err
+	}
+
+	postEvent := new(anilist_platform.PostUpdateEntryRepeatEvent)
+	postEvent.MediaID = &mediaID
+
+	err = hook.GlobalHookManager.OnPostUpdateEntryRepeat().Trigger(postEvent)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteEntry deletes the entry with the given ID, which is the AniList ID of its media.
+func (mp *MalPlatform) DeleteEntry(ctx context.Context, entryId int) error {
+	mp.logger.Trace().Int("entryId", entryId).Msg("mal platform: Deleting entry")
+
+	malId, isManga, err := mp.findMalId(ctx, entryId)
+	if err != nil {
+		return err
+	}
+
+	wrapper, err := mp.getWrapper()
+	if err != nil {
+		return err
+	}
+
+	mp.rateLimiter.Wait()
+	if isManga {
+		return wrapper.DeleteMangaListItem(malId)
+	}
+	return wrapper.DeleteAnimeListItem(malId)
+}
+
+func (mp *MalPlatform) GetAnime(ctx context.Context, mediaID int) (*anilist.BaseAnime, error) {
+	mp.logger.Trace().Int("mediaID", mediaID).Msg("mal platform: Getting anime")
+
+	resp, err := mp.anilistClient.BaseAnimeByID(ctx, &mediaID)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.GetMedia(), nil
+}
+
+func (mp *MalPlatform) GetAnimeByMalID(ctx context.Context, malID int) (*anilist.BaseAnime, error) {
+	mp.logger.Trace().Int("malID", malID).Msg("mal platform: Getting anime by MAL ID")
+
+	resp, err := mp.anilistClient.BaseAnimeByMalID(ctx, &malID)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.GetMedia(), nil
+}
+
+func (mp *MalPlatform) GetAnimeDetails(ctx context.Context, mediaID int) (*anilist.AnimeDetailsById_Media, error) {
+	mp.logger.Trace().Int("mediaID", mediaID).Msg("mal platform: Getting anime details")
+
+	resp, err := mp.anilistClient.AnimeDetailsByID(ctx, &mediaID)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.GetMedia(), nil
+}
+
+func (mp *MalPlatform) GetAnimeWithRelations(ctx context.Context, mediaID int) (*anilist.CompleteAnime, error) {
+	mp.logger.Trace().Int("mediaID", mediaID).Msg("mal platform: Getting anime with relations")
+
+	resp, err := mp.anilistClient.CompleteAnimeByID(ctx, &mediaID)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.GetMedia(), nil
+}
+
+func (mp *MalPlatform) GetManga(ctx context.Context, mediaID int) (*anilist.BaseManga, error) {
+	mp.logger.Trace().Int("mediaID", mediaID).Msg("mal platform: Getting manga")
+
+	resp, err := mp.anilistClient.BaseMangaByID(ctx, &mediaID)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.GetMedia(), nil
+}
+
+func (mp *MalPlatform) GetMangaDetails(ctx context.Context, mediaID int) (*anilist.MangaDetailsById_Media, error) {
+	mp.logger.Trace().Int("mediaID", mediaID).Msg("mal platform: Getting manga details")
+
+	resp, err := mp.anilistClient.MangaDetailsByID(ctx, &mediaID)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.GetMedia(), nil
+}
+
+func (mp *MalPlatform) GetAnimeCollection(ctx context.Context, bypassCache bool) (*anilist.AnimeCollection, error) {
+	mp.mu.RLock()
+	cached := mp.animeCollection
+	mp.mu.RUnlock()
+
+	if !bypassCache && cached.IsPresent() {
+		return cached.MustGet(), nil
+	}
+
+	return mp.RefreshAnimeCollection(ctx)
+}
+
+// GetRawAnimeCollection returns the anime collection, MyAnimeList has no custom lists.
+func (mp *MalPlatform) GetRawAnimeCollection(ctx context.Context, bypassCache bool) (*anilist.AnimeCollection, error) {
+	return mp.GetAnimeCollection(ctx, bypassCache)
+}
+
+func (mp *MalPlatform) RefreshAnimeCollection(ctx context.Context) (*anilist.AnimeCollection, error) {
+	mp.logger.Trace().Msg("mal platform: Refreshing anime collection")
+
+	wrapper, err := mp.getWrapper()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := wrapper.GetAnimeCollection()
+	if err != nil {
+		return nil, err
+	}
+
+	malIds := lo.Map(entries, func(e *mal.AnimeListEntry, _ int) int { return e.Node.ID })
+	media, err := fetchMediaByMalIds(baseAnimeByMalIdsDocument, malIds, (*anilist.BaseAnime).GetIDMal, mp.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if skipped := len(lo.Uniq(malIds)) - len(media); skipped > 0 {
+		mp.logger.Debug().Int("count", skipped).Msg("mal platform: Some anime could not be found on AniList")
+	}
+
+	collection := buildAnimeCollection(entries, media)
+
+	mp.mu.Lock()
+	mp.animeCollection = mo.Some(collection)
+	mp.mu.Unlock()
+
+	return collection, nil
+}
+
+// GetAnimeCollectionWithRelations returns the anime collection (without relations)
+func (mp *MalPlatform) GetAnimeCollectionWithRelations(ctx context.Context) (*anilist.AnimeCollectionWithRelations, error) {
+	mp.logger.Trace().Msg("mal platform: Getting anime collection with relations")
+
+	collection, err := mp.GetAnimeCollection(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Use JSON to convert the collection structs
+	ret := &anilist.AnimeCollectionWithRelations{}
+
+	marshaled, err := json.Marshal(collection)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(marshaled, ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (mp *MalPlatform) GetMangaCollection(ctx context.Context, bypassCache bool) (*anilist.MangaCollection, error) {
+	mp.mu.RLock()
+	cached := mp.mangaCollection
+	mp.mu.RUnlock()
+
+	if !bypassCache && cached.IsPresent() {
+		return cached.MustGet(), nil
+	}
+
+	return mp.RefreshMangaCollection(ctx)
+}
+
+// GetRawMangaCollection returns the manga collection, MyAnimeList has no custom lists.
+func (mp *MalPlatform) GetRawMangaCollection(ctx context.Context, bypassCache bool) (*anilist.MangaCollection, error) {
+	return mp.GetMangaCollection(ctx, bypassCache)
+}
+
+func (mp *MalPlatform) RefreshMangaCollection(ctx context.Context) (*anilist.MangaCollection, error) {
+	mp.logger.Trace().Msg("mal platform: Refreshing manga collection")
+
+	wrapper, err := mp.getWrapper()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := wrapper.GetMangaCollection()
+	if err != nil {
+		return nil, err
+	}
+
+	malIds := lo.Map(entries, func(e *mal.MangaListEntry, _ int) int { return e.Node.ID })
+	media, err := fetchMediaByMalIds(baseMangaByMalIdsDocument, malIds, (*anilist.BaseManga).GetIDMal, mp.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if skipped := len(lo.Uniq(malIds)) - len(media); skipped > 0 {
+		mp.logger.Debug().Int("count", skipped).Msg("mal platform: Some manga could not be found on AniList")
+	}
+
+	collection := buildMangaCollection(entries, media)
+
+	mp.mu.Lock()
+	mp.mangaCollection = mo.Some(collection)
+	mp.mu.Unlock()
+
+	return collection, nil
+}
+
+// AddMediaToCollection adds the anime to the plan to watch list.
+func (mp *MalPlatform) AddMediaToCollection(ctx context.Context, mIds []int) error {
+	mp.logger.Trace().Interface("mediaIDs", mIds).Msg("mal platform: Adding media to collection")
+
+	if len(mIds) == 0 {
+		return nil
+	}
+
+	wrapper, err := mp.getWrapper()
+	if err != nil {
+		return err
+	}
+
+	for _, mediaID := range mIds {
+		media, err := mp.GetAnime(ctx, mediaID)
+		if err != nil || lo.FromPtr(media.GetIDMal()) == 0 {
+			mp.logger.Warn().Int("mediaID", mediaID).Msg("mal platform: Anime not found on MyAnimeList, not adding it")
+			continue
+		}
+		mp.rateLimiter.Wait()
+		err = wrapper.UpdateAnimeListStatus(&mal.AnimeListStatusParams{
+			Status: lo.ToPtr(mal.MediaListStatusPlanToWatch),
+		}, *media.GetIDMal())
+		if err != nil {
+			mp.logger.Error().Err(err).Int("mediaID", mediaID).Msg("mal platform: Failed to add anime to plan to watch list")
+		}
+	}
+
+	mp.logger.Debug().Int("count", len(mIds)).Msg("mal platform: Media added to plan to watch list")
+	return nil
+}
+
+func (mp *MalPlatform) GetStudioDetails(ctx context.Context, studioID int) (*anilist.StudioDetails, error) {
+	return mp.anilistClient.StudioDetails(ctx, &studioID)
+}
+
+func (mp *MalPlatform) GetAnilistClient() anilist.AnilistClient {
+	return mp.anilistClient
+}
+
+func (mp *MalPlatform) GetViewerStats(ctx context.Context) (*anilist.ViewerStats, error) {
+	return nil, errors.New("stats are not available for MyAnimeList accounts")
+}
+
+func (mp *MalPlatform) GetAnimeAiringSchedule(ctx context.Context) (*anilist.AnimeAiringSchedule, error) {
+	collection, err := mp.GetAnimeCollection(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaIds := make([]*int, 0)
+	for _, list := range collection.MediaListCollection.Lists {
+		for _, entry := range list.Entries {
+			mediaIds = append(mediaIds, &[]int{entry.GetMedia().GetID()}[0])
+		}
+	}
+
+	now := time.Now()
+	currentSeason, currentSeasonYear := anilist.GetSeasonInfo(now, anilist.GetSeasonKindCurrent)
+	previousSeason, previousSeasonYear := anilist.GetSeasonInfo(now, anilist.GetSeasonKindPrevious)
+	nextSeason, nextSeasonYear := anilist.GetSeasonInfo(now, anilist.GetSeasonKindNext)
+
+	return mp.anilistClient.AnimeAiringSchedule(ctx, mediaIds, &currentSeason, &currentSeasonYear, &previousSeason, &previousSeasonYear, &nextSeason, &nextSeasonYear)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Helper Methods
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (mp *MalPlatform) getWrapper() (*mal.Wrapper, error) {
+	malInfo, err := mp.db.GetMalInfo()
+	if err != nil || malInfo == nil || malInfo.AccessToken == "" {
+		return nil, ErrNotConnected
+	}
+	// Refresh the token if needed
+	malInfo, err = mal.VerifyMALAuth(malInfo, mp.db, mp.logger)
+	if err != nil {
+		return nil, err
+	}
+	return mal.NewWrapper(malInfo.AccessToken, mp.logger), nil
+}
+
+func (mp *MalPlatform) updateListStatus(malId int, isManga bool, status *mal.MediaListStatus, repeating *bool, progress *int, score *int, startDate *string, finishDate *string) error {
+	wrapper, err := mp.getWrapper()
+	if err != nil {
+		return err
+	}
+
+	mp.rateLimiter.Wait()
+	if isManga {
+		return wrapper.UpdateMangaListStatus(&mal.MangaListStatusParams{
+			Status:          status,
+			IsRereading:     repeating,
+			NumChaptersRead: progress,
+			Score:           score,
+			StartDate:       startDate,
+			FinishDate:      finishDate,
+		}, malId)
+	}
+	return wrapper.UpdateAnimeListStatus(&mal.AnimeListStatusParams{
+		Status:             status,
+		IsRewatching:       repeating,
+		NumEpisodesWatched: progress,
+		Score:              score,
+		StartDate:          startDate,
+		FinishDate:         finishDate,
+	}, malId)
+}
+
+// findMalId returns the MyAnimeList ID of an AniList media and whether it is a manga.
+// The collections are searched first, the media is fetched from AniList if it is not in them.
+func (mp *MalPlatform) findMalId(ctx context.Context, mediaID int) (int, bool, error) {
+	mp.mu.RLock()
+	animeCollection, mangaCollection := mp.animeCollection, mp.mangaCollection
+	mp.mu.RUnlock()
+
+	if animeCollection.IsPresent() {
+		if entry, found := animeCollection.MustGet().GetListEntryFromAnimeId(mediaID); found {
+			return lo.FromPtr(entry.GetMedia().GetIDMal()), false, nil
+		}
+	}
+	if mangaCollection.IsPresent() {
+		if entry, found := mangaCollection.MustGet().GetListEntryFromMangaId(mediaID); found {
+			return lo.FromPtr(entry.GetMedia().GetIDMal()), true, nil
+		}
+	}
+
+	if resp, err := mp.anilistClient.BaseAnimeByID(ctx, &mediaID); err == nil && resp.GetMedia() != nil {
+		if malId := lo.FromPtr(resp.GetMedia().GetIDMal()); malId != 0 {
+			return malId, false, nil
+		}
+		return 0, false, ErrMediaNotFound
+	}
+
+	if resp, err := mp.anilistClient.BaseMangaByID(ctx, &mediaID); err == nil && resp.GetMedia() != nil {
+		if malId := lo.FromPtr(resp.GetMedia().GetIDMal()); malId != 0 {
+			return malId, true, nil
+		}
+	}
+
+	return 0, false, ErrMediaNotFound
+}
+
+// findCachedStatus returns the status of the entry of the media in the collections.
+func (mp *MalPlatform) findCachedStatus(mediaID int) (anilist.MediaListStatus, bool) {
+	mp.mu.RLock()
+	defer mp.mu.RUnlock()
+
+	if mp.animeCollection.IsPresent() {
+		if entry, found := mp.animeCollection.MustGet().GetListEntryFromAnimeId(mediaID); found {
+			return lo.FromPtr(entry.GetStatus()), true
+		}
+	}
+	if mp.mangaCollection.IsPresent() {
+		if entry, found := mp.mangaCollection.MustGet().GetListEntryFromMangaId(mediaID); found {
+			return lo.FromPtr(entry.GetStatus()), true
+		}
+	}
+	return "", false
+}
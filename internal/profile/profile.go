@@ -0,0 +1,473 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"seanime/internal/database/db"
+	"seanime/internal/database/models"
+	"seanime/internal/user"
+	"seanime/internal/util/result"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// DEVNOTE: Profiles are selected per client, the client ID (cookie) is mapped to the selected profile in memory.
+// Clients that have not selected a profile use the main profile, whose account, theme and settings are the global rows,
+// this is why the main profile cannot be protected by a PIN.
+// The library, torrent clients, caches and other settings are shared by all profiles.
+
+const maxNameLength = 32
+
+var pinRegex = regexp.MustCompile(`^\d{4,8}$`)
+
+var ErrInvalidPin = errors.New("invalid PIN")
+
+type (
+	// Manager handles the profiles of the server and the profile selected by each client.
+	Manager struct {
+		db     *db.Database
+		logger *zerolog.Logger
+		// Client ID -> Profile ID
+		sessions *result.Map[string, uint]
+		mu       sync.Mutex
+	}
+
+	// Profile is the data of a profile sent to the client.
+	Profile struct {
+		ID     uint   `json:"id"`
+		Name   string `json:"name"`
+		HasPin bool   `json:"hasPin"`
+		// True if the profile is the one selected by the client
+		Active bool `json:"active"`
+		// True for the profile used by clients that have not selected a profile
+		IsMain bool `json:"isMain"`
+		// AniList username, empty if the profile is not logged in
+		Username string `json:"username"`
+		Avatar   string `json:"avatar"`
+	}
+
+	// PlaybackPreferences are the settings of the playback that belong to a profile.
+	PlaybackPreferences struct {
+		DefaultPlayer         string `json:"defaultPlayer"`
+		AutoPlayNextEpisode   bool   `json:"autoPlayNextEpisode"`
+		EnableWatchContinuity bool   `json:"enableWatchContinuity"`
+	}
+
+	UpdateProfileOptions struct {
+		Name string `json:"name"`
+		// New PIN, an empty string removes the PIN, nil keeps the current PIN
+		Pin *string `json:"pin"`
+		// Required if the profile is protected
+		CurrentPin string `json:"currentPin"`
+	}
+
+	NewManagerOptions struct {
+		Database *db.Database
+		Logger   *zerolog.Logger
+	}
+)
+
+func NewManager(opts *NewManagerOptions) *Manager {
+	return &Manager{
+		db:       opts.Database,
+		logger:   opts.Logger,
+		sessions: result.NewResultMap[string, uint](),
+	}
+}
+
+// GetClientProfileId returns the ID of the profile selected by the client, or the ID of the main profile.
+func (m *Manager) GetClientProfileId(clientId string) uint {
+	if m == nil || clientId == "" {
+		return models.DefaultProfileID
+	}
+	if id, ok := m.sessions.Get(clientId); ok {
+		return id
+	}
+	return models.DefaultProfileID
+}
+
+// GetProfile returns the profile.
+func (m *Manager) GetProfile(id uint) (*models.Profile, error) {
+	return m.db.GetProfile(id)
+}
+
+// GetProfiles returns all the profiles, the profile selected by the client is marked as active.
+// The main profile is created from the current account if there are no profiles yet.
+func (m *Manager) GetProfiles(clientId string) ([]*Profile, error) {
+	if err := m.ensureMainProfile(); err != nil {
+		return nil, err
+	}
+
+	profiles, err := m.db.GetProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	activeId := m.GetClientProfileId(clientId)
+
+	ret := make([]*Profile, 0, len(profiles))
+	for _, p := range profiles {
+		// The account of the main profile is in the Account row
+		if p.ID == models.DefaultProfileID {
+			if acc, err := m.db.GetAccount(); err == nil {
+				p.Username, p.Viewer = acc.Username, acc.Viewer
+			} else {
+				p.Username, p.Viewer = "", nil
+			}
+		}
+		r := toProfile(p)
+		r.Active = p.ID == activeId
+		ret = append(ret, r)
+	}
+
+	return ret, nil
+}
+
+// CreateProfile creates a profile that is not logged in.
+// It uses the theme and playback settings of the main profile until they are changed.
+func (m *Manager) CreateProfile(name string, pin string) (*Profile, error) {
+	if err := m.ensureMainProfile(); err != nil {
+		return nil, err
+	}
+
+	name, err := validateName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &models.Profile{
+		Name: name,
+	}
+
+	if pin != "" {
+		p.PinHash, err = HashPin(pin)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.db.SaveProfile(p); err != nil {
+		return nil, err
+	}
+
+	m.logger.Info().Str("name", name).Msg("profile: Created profile")
+
+	return toProfile(p), nil
+}
+
+// UpdateProfile changes the name or the PIN of a profile.
+func (m *Manager) UpdateProfile(id uint, opts *UpdateProfileOptions) (*Profile, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, err := m.db.GetProfile(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !VerifyPin(p.PinHash, opts.CurrentPin) {
+		return nil, ErrInvalidPin
+	}
+
+	if opts.Name != "" {
+		p.Name, err = validateName(opts.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Pin != nil {
+		if *opts.Pin != "" && p.ID == models.DefaultProfileID {
+			return nil, errors.New("the main profile cannot be protected by a PIN")
+		}
+		p.PinHash = ""
+		if *opts.Pin != "" {
+			p.PinHash, err = HashPin(*opts.Pin)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := m.db.SaveProfile(p); err != nil {
+		return nil, err
+	}
+
+	return toProfile(p), nil
+}
+
+// DeleteProfile deletes a profile and its watch events.
+// The clients using the profile go back to the main profile, which cannot be deleted.
+func (m *Manager) DeleteProfile(id uint, pin string) error {
+	if id == models.DefaultProfileID {
+		return errors.New("the main profile cannot be deleted")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, err := m.db.GetProfile(id)
+	if err != nil {
+		return err
+	}
+
+	if !VerifyPin(p.PinHash, pin) {
+		return ErrInvalidPin
+	}
+
+	if err := m.db.DeleteProfile(id); err != nil {
+		return err
+	}
+
+	m.sessions.Range(func(clientId string, profileId uint) bool {
+		if profileId == id {
+			m.sessions.Delete(clientId)
+		}
+		return true
+	})
+
+	m.logger.Info().Str("name", p.Name).Msg("profile: Deleted profile")
+
+	return nil
+}
+
+// SelectProfile makes a profile the profile of the client.
+// The PIN is required if the profile is protected.
+func (m *Manager) SelectProfile(clientId string, id uint, pin string) (*models.Profile, error) {
+	if clientId == "" {
+		return nil, errors.New("profiles cannot be selected without a client ID")
+	}
+
+	if err := m.ensureMainProfile(); err != nil {
+		return nil, err
+	}
+
+	p, err := m.db.GetProfile(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !VerifyPin(p.PinHash, pin) {
+		return nil, ErrInvalidPin
+	}
+
+	if p.ID == models.DefaultProfileID {
+		m.sessions.Delete(clientId)
+	} else {
+		m.sessions.Set(clientId, p.ID)
+	}
+
+	m.logger.Debug().Str("name", p.Name).Str("clientId", clientId).Msg("profile: Selected profile")
+
+	return p, nil
+}
+
+// SetAccount saves the AniList account of a profile, an empty account logs the profile out.
+// The account of the main profile is the Account row.
+func (m *Manager) SetAccount(id uint, acc *models.Account) error {
+	if id == models.DefaultProfileID {
+		return errors.New("the account of the main profile is not stored in the profile")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, err := m.db.GetProfile(id)
+	if err != nil {
+		return err
+	}
+
+	p.Username, p.Token, p.Viewer = acc.Username, acc.Token, acc.Viewer
+
+	return m.db.SaveProfile(p)
+}
+
+// GetTheme returns the theme of a profile, or nil if the profile uses the theme of the main profile.
+func (m *Manager) GetTheme(id uint) *models.Theme {
+	p, err := m.db.GetProfile(id)
+	if err != nil || len(p.Theme) == 0 {
+		return nil
+	}
+	var theme models.Theme
+	if err := json.Unmarshal(p.Theme, &theme); err != nil {
+		return nil
+	}
+	return &theme
+}
+
+// SaveTheme saves the theme of a profile.
+func (m *Manager) SaveTheme(id uint, theme *models.Theme) error {
+	if id == models.DefaultProfileID {
+		return errors.New("the theme of the main profile is not stored in the profile")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, err := m.db.GetProfile(id)
+	if err != nil {
+		return err
+	}
+
+	p.Theme, err = json.Marshal(theme)
+	if err != nil {
+		return err
+	}
+
+	return m.db.SaveProfile(p)
+}
+
+// GetPlaybackPreferences returns the playback preferences of a profile, or nil if the profile uses the settings of the main profile.
+func (m *Manager) GetPlaybackPreferences(id uint) *PlaybackPreferences {
+	p, err := m.db.GetProfile(id)
+	if err != nil || len(p.Playback) == 0 {
+		return nil
+	}
+	var prefs PlaybackPreferences
+	if err := json.Unmarshal(p.Playback, &prefs); err != nil {
+		return nil
+	}
+	return &prefs
+}
+
+// SavePlaybackPreferences saves the playback preferences of a profile.
+func (m *Manager) SavePlaybackPreferences(id uint, prefs *PlaybackPreferences) error {
+	if id == models.DefaultProfileID {
+		return errors.New("the playback preferences of the main profile are not stored in the profile")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, err := m.db.GetProfile(id)
+	if err != nil {
+		return err
+	}
+
+	p.Playback, err = json.Marshal(prefs)
+	if err != nil {
+		return err
+	}
+
+	return m.db.SaveProfile(p)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// ensureMainProfile creates the main profile if it does not exist.
+func (m *Manager) ensureMainProfile() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := m.db.GetProfile(models.DefaultProfileID)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	name := "Default"
+	if acc, err := m.db.GetAccount(); err == nil && acc.Username != "" {
+		name = acc.Username
+	}
+
+	p := &models.Profile{
+		BaseModel: models.BaseModel{
+			ID:        models.DefaultProfileID,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		},
+		Name: name,
+	}
+	if err := m.db.SaveProfile(p); err != nil {
+		return err
+	}
+
+	m.logger.Info().Str("name", name).Msg("profile: Created main profile")
+
+	return nil
+}
+
+// GetPlaybackPreferences returns the playback preferences contained in the settings.
+func GetPlaybackPreferences(settings *models.Settings) *PlaybackPreferences {
+	ret := &PlaybackPreferences{}
+	if settings.MediaPlayer != nil {
+		ret.DefaultPlayer = settings.MediaPlayer.Default
+	}
+	if settings.Library != nil {
+		ret.AutoPlayNextEpisode = settings.Library.AutoPlayNextEpisode
+		ret.EnableWatchContinuity = settings.Library.EnableWatchContinuity
+	}
+	return ret
+}
+
+// Apply sets the playback preferences in the settings.
+func (p *PlaybackPreferences) Apply(settings *models.Settings) {
+	if settings.MediaPlayer != nil && p.DefaultPlayer != "" {
+		settings.MediaPlayer.Default = p.DefaultPlayer
+	}
+	if settings.Library != nil {
+		settings.Library.AutoPlayNextEpisode = p.AutoPlayNextEpisode
+		settings.Library.EnableWatchContinuity = p.EnableWatchContinuity
+	}
+}
+
+// HashPin returns the bcrypt hash of a PIN made of 4 to 8 digits.
+func HashPin(pin string) (string, error) {
+	if !pinRegex.MatchString(pin) {
+		return "", errors.New("the PIN must be made of 4 to 8 digits")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// VerifyPin returns true if the PIN matches the hash, or if the profile has no PIN.
+func VerifyPin(hash string, pin string) bool {
+	if hash == "" {
+		return true
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(pin)) == nil
+}
+
+func validateName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("the name cannot be empty")
+	}
+	if len([]rune(name)) > maxNameLength {
+		return "", fmt.Errorf("the name cannot be longer than %d characters", maxNameLength)
+	}
+	return name, nil
+}
+
+func toProfile(p *models.Profile) *Profile {
+	ret := &Profile{
+		ID:       p.ID,
+		Name:     p.Name,
+		HasPin:   p.PinHash != "",
+		IsMain:   p.ID == models.DefaultProfileID,
+		Username: p.Username,
+	}
+	if p.Username != "" && len(p.Viewer) > 0 {
+		if u, err := user.NewUser(&models.Account{Username: p.Username, Viewer: p.Viewer}); err == nil {
+			if avatar := u.Viewer.GetAvatar().GetMedium(); avatar != nil {
+				ret.Avatar = *avatar
+			}
+		}
+	}
+	return ret
+}
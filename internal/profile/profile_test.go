@@ -0,0 +1,104 @@
+package profile
+
+import (
+	"seanime/internal/database/db"
+	"seanime/internal/database/models"
+	"seanime/internal/util"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPin(t *testing.T) {
+	hash, err := HashPin("1234")
+	require.NoError(t, err)
+	assert.NotEqual(t, "1234", hash)
+
+	assert.True(t, VerifyPin(hash, "1234"))
+	assert.False(t, VerifyPin(hash, "4321"))
+	assert.False(t, VerifyPin(hash, ""))
+
+	// Profiles without a PIN are not protected
+	assert.True(t, VerifyPin("", ""))
+	assert.True(t, VerifyPin("", "1234"))
+
+	for _, pin := range []string{"", "123", "123456789", "12a4", " 1234"} {
+		_, err := HashPin(pin)
+		assert.Error(t, err, pin)
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	name, err := validateName("  Alice ")
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", name)
+
+	_, err = validateName("   ")
+	assert.Error(t, err)
+
+	_, err = validateName(strings.Repeat("a", maxNameLength+1))
+	assert.Error(t, err)
+}
+
+func TestPlaybackPreferences(t *testing.T) {
+	settings := &models.Settings{
+		MediaPlayer: &models.MediaPlayerSettings{Default: "mpv", MpvPath: "/usr/bin/mpv"},
+		Library:     &models.LibrarySettings{LibraryPath: "/anime", AutoPlayNextEpisode: true},
+	}
+
+	prefs := GetPlaybackPreferences(settings)
+	assert.Equal(t, &PlaybackPreferences{DefaultPlayer: "mpv", AutoPlayNextEpisode: true}, prefs)
+
+	(&PlaybackPreferences{DefaultPlayer: "vlc", EnableWatchContinuity: true}).Apply(settings)
+	assert.Equal(t, "vlc", settings.MediaPlayer.Default)
+	assert.False(t, settings.Library.AutoPlayNextEpisode)
+	assert.True(t, settings.Library.EnableWatchContinuity)
+	// Shared settings are untouched
+	assert.Equal(t, "/usr/bin/mpv", settings.MediaPlayer.MpvPath)
+	assert.Equal(t, "/anime", settings.Library.LibraryPath)
+
+	// Settings without the blocks are left as is
+	(&PlaybackPreferences{DefaultPlayer: "vlc"}).Apply(&models.Settings{})
+}
+
+func TestSelectProfile(t *testing.T) {
+	logger := util.NewLogger()
+	database, err := db.NewDatabase(t.TempDir(), "seanime-test", logger)
+	require.NoError(t, err)
+
+	m := NewManager(&NewManagerOptions{Database: database, Logger: logger})
+
+	p, err := m.CreateProfile("Alice", "1234")
+	require.NoError(t, err)
+
+	// Clients use the main profile until they select another one
+	assert.Equal(t, models.DefaultProfileID, m.GetClientProfileId("a"))
+
+	_, err = m.SelectProfile("a", p.ID, "4321")
+	assert.ErrorIs(t, err, ErrInvalidPin)
+	_, err = m.SelectProfile("a", p.ID, "1234")
+	require.NoError(t, err)
+
+	// Only the client that selected the profile uses it
+	assert.Equal(t, p.ID, m.GetClientProfileId("a"))
+	assert.Equal(t, models.DefaultProfileID, m.GetClientProfileId("b"))
+
+	profiles, err := m.GetProfiles("a")
+	require.NoError(t, err)
+	require.Len(t, profiles, 2)
+	assert.True(t, profiles[0].IsMain)
+	assert.False(t, profiles[0].Active)
+	assert.True(t, profiles[1].Active)
+
+	// The main profile cannot be protected or deleted
+	pin := "1234"
+	_, err = m.UpdateProfile(models.DefaultProfileID, &UpdateProfileOptions{Pin: &pin})
+	assert.Error(t, err)
+	assert.Error(t, m.DeleteProfile(models.DefaultProfileID, ""))
+
+	// Deleting a profile sends its clients back to the main profile
+	require.NoError(t, m.DeleteProfile(p.ID, "1234"))
+	assert.Equal(t, models.DefaultProfileID, m.GetClientProfileId("a"))
+}
@@ -0,0 +1,237 @@
+package scrobbler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"seanime/internal/api/anilist"
+	"seanime/internal/database/models"
+	"strconv"
+)
+
+const kitsuBaseURL = "https://kitsu.app"
+
+// Kitsu uses the password grant, it does not need application credentials.
+type kitsuTracker struct {
+	client *http.Client
+}
+
+func newKitsuTracker() *kitsuTracker {
+	return &kitsuTracker{client: &http.Client{}}
+}
+
+func (t *kitsuTracker) Name() TrackerName {
+	return TrackerKitsu
+}
+
+func (t *kitsuTracker) headers(account *models.TrackerAccount) map[string]string {
+	ret := map[string]string{
+		"Accept": "application/vnd.api+json",
+	}
+	if account != nil {
+		ret["Authorization"] = "Bearer " + account.AccessToken
+	}
+	return ret
+}
+
+func (t *kitsuTracker) Authenticate(ctx context.Context, credentials *Credentials) (*models.TrackerAccount, error) {
+	if credentials.Username == "" || credentials.Password == "" {
+		return nil, errors.New("username and password are required")
+	}
+
+	var token tokenResponse
+	err := doRequest(ctx, t.client, &request{
+		method: http.MethodPost,
+		uri:    kitsuBaseURL + "/api/oauth/token",
+		body: url.Values{
+			"grant_type": {"password"},
+			"username":   {credentials.Username},
+			"password":   {credentials.Password},
+		},
+		ret: &token,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	account := &models.TrackerAccount{Tracker: string(TrackerKitsu)}
+	token.apply(account)
+
+	var users struct {
+		Data []struct {
+			ID         string `json:"id"`
+			Attributes struct {
+				Name string `json:"name"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	err = doRequest(ctx, t.client, &request{
+		method:  http.MethodGet,
+		uri:     kitsuBaseURL + "/api/edge/users?filter[self]=true",
+		headers: t.headers(account),
+		ret:     &users,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(users.Data) == 0 {
+		return nil, errors.New("kitsu: user not found")
+	}
+
+	account.UserID = users.Data[0].ID
+	account.Username = users.Data[0].Attributes.Name
+	return account, nil
+}
+
+func (t *kitsuTracker) RefreshToken(ctx context.Context, account *models.TrackerAccount) error {
+	var token tokenResponse
+	err := doRequest(ctx, t.client, &request{
+		method: http.MethodPost,
+		uri:    kitsuBaseURL + "/api/oauth/token",
+		body: url.Values{
+			"grant_type":    {"refresh_token"},
+			"refresh_token": {account.RefreshToken},
+		},
+		ret: &token,
+	})
+	if err != nil {
+		return err
+	}
+	token.apply(account)
+	return nil
+}
+
+func (t *kitsuTracker) Scrobble(ctx context.Context, account *models.TrackerAccount, update *Update) error {
+	kitsuId, err := t.getMediaId(ctx, update)
+	if err != nil {
+		return err
+	}
+
+	kind := string(update.MediaType)
+
+	// Find the library entry
+	var entries struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	err = doRequest(ctx, t.client, &request{
+		method:  http.MethodGet,
+		uri:     fmt.Sprintf("%s/api/edge/library-entries?filter[userId]=%s&filter[kind]=%s&filter[%sId]=%d", kitsuBaseURL, account.UserID, kind, kind, kitsuId),
+		headers: t.headers(account),
+		ret:     &entries,
+	})
+	if err != nil {
+		return err
+	}
+
+	attributes := map[string]interface{}{}
+	if update.Status != nil {
+		attributes["status"] = toKitsuStatus(*update.Status)
+		attributes["reconsuming"] = update.isRepeating()
+	}
+	if update.Progress != nil {
+		attributes["progress"] = *update.Progress
+	}
+	if update.Score != nil {
+		// Kitsu ratings are out of 20, null removes the rating
+		var rating *int
+		if score := update.score10(); score != nil {
+			rating = new(int)
+			*rating = *score * 2
+		}
+		attributes["ratingTwenty"] = rating
+	}
+	if update.Repeat != nil {
+		attributes["reconsumeCount"] = *update.Repeat
+	}
+
+	data := map[string]interface{}{
+		"type":       "libraryEntries",
+		"attributes": attributes,
+	}
+	headers := t.headers(account)
+	headers["Content-Type"] = "application/vnd.api+json"
+
+	// Update the existing entry
+	if len(entries.Data) > 0 {
+		data["id"] = entries.Data[0].ID
+		return doRequest(ctx, t.client, &request{
+			method:  http.MethodPatch,
+			uri:     kitsuBaseURL + "/api/edge/library-entries/" + entries.Data[0].ID,
+			headers: headers,
+			body:    map[string]interface{}{"data": data},
+		})
+	}
+
+	// Create the entry
+	if _, ok := attributes["status"]; !ok {
+		attributes["status"] = "current"
+	}
+	data["relationships"] = map[string]interface{}{
+		"user": map[string]interface{}{
+			"data": map[string]string{"type": "users", "id": account.UserID},
+		},
+		kind: map[string]interface{}{
+			"data": map[string]string{"type": kind, "id": strconv.Itoa(kitsuId)},
+		},
+	}
+	return doRequest(ctx, t.client, &request{
+		method:  http.MethodPost,
+		uri:     kitsuBaseURL + "/api/edge/library-entries",
+		headers: headers,
+		body:    map[string]interface{}{"data": data},
+	})
+}
+
+// getMediaId returns the Kitsu ID of the media.
+// Manga are not in the anime lists, they are found using the MyAnimeList mappings of Kitsu.
+func (t *kitsuTracker) getMediaId(ctx context.Context, update *Update) (int, error) {
+	if update.KitsuID != 0 {
+		return update.KitsuID, nil
+	}
+	if update.MalID == 0 {
+		return 0, ErrNoMediaId
+	}
+
+	var mappings struct {
+		Included []struct {
+			ID string `json:"id"`
+		} `json:"included"`
+	}
+	err := doRequest(ctx, t.client, &request{
+		method:  http.MethodGet,
+		uri:     fmt.Sprintf("%s/api/edge/mappings?filter[externalSite]=myanimelist/%s&filter[externalId]=%d&include=item", kitsuBaseURL, update.MediaType, update.MalID),
+		headers: t.headers(nil),
+		ret:     &mappings,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(mappings.Included) == 0 {
+		return 0, ErrNoMediaId
+	}
+
+	ret, err := strconv.Atoi(mappings.Included[0].ID)
+	if err != nil {
+		return 0, ErrNoMediaId
+	}
+	return ret, nil
+}
+
+func toKitsuStatus(status anilist.MediaListStatus) string {
+	switch status {
+	case anilist.MediaListStatusCompleted:
+		return "completed"
+	case anilist.MediaListStatusPaused:
+		return "on_hold"
+	case anilist.MediaListStatusDropped:
+		return "dropped"
+	case anilist.MediaListStatusPlanning:
+		return "planned"
+	default:
+		return "current"
+	}
+}
@@ -0,0 +1,548 @@
+package scrobbler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"seanime/internal/api/mappings"
+	"seanime/internal/database/db"
+	"seanime/internal/database/models"
+	"seanime/internal/hook"
+	"seanime/internal/hook_resolver"
+	"seanime/internal/platforms/anilist_platform"
+	"seanime/internal/platforms/platform"
+	"seanime/internal/util"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/samber/lo"
+)
+
+const (
+	// Number of attempts before a failed update is dropped
+	maxAttempts = 5
+	// Delay before the first retry, doubled after each attempt
+	retryDelay = time.Minute
+	// Interval at which the due retries are sent
+	retryInterval = 30 * time.Second
+	// Timeout of a single update, including the token refresh
+	scrobbleTimeout = 30 * time.Second
+	// The anime lists are fetched again after this
+	mappingsTTL = 24 * time.Hour
+)
+
+var ErrUnknownTracker = errors.New("unknown tracker")
+
+type (
+	// Manager mirrors the list updates made on the main platform to the connected tracker accounts.
+	// Failed updates are saved and retried with a growing delay, every attempt is logged.
+	Manager struct {
+		logger   *zerolog.Logger
+		db       *db.Database
+		trackers map[TrackerName]Tracker
+		queue    chan *job
+		wakeCh   chan struct{}
+
+		mu       sync.Mutex
+		platform platform.Platform
+		// Updates captured by the pre-update hooks, sent once the update succeeded
+		pending map[pendingKey]*Update
+
+		mappingsMu        sync.Mutex
+		mappings          *mappings.AnimeListResponse
+		mappingsFetchedAt time.Time
+	}
+
+	NewManagerOptions struct {
+		Logger   *zerolog.Logger
+		Database *db.Database
+		Platform platform.Platform
+	}
+
+	// Account is a connected tracker account.
+	Account struct {
+		Tracker     TrackerName `json:"tracker"`
+		Username    string      `json:"username"`
+		ConnectedAt time.Time   `json:"connectedAt"`
+		// Number of failed updates waiting to be retried
+		PendingRetries int `json:"pendingRetries"`
+	}
+
+	job struct {
+		tracker TrackerName
+		update  *Update
+		attempt int
+		// Saved retry of the update, nil if the update was never saved
+		retry *models.ScrobbleRetry
+	}
+
+	retryKey struct {
+		tracker TrackerName
+		mediaId int
+	}
+
+	// pendingKey identifies the update of a hook, updates of different kinds can be made concurrently on the same media.
+	pendingKey struct {
+		kind    updateKind
+		mediaId int
+	}
+
+	updateKind string
+)
+
+const (
+	updateKindEntry    updateKind = "entry"
+	updateKindProgress updateKind = "progress"
+	updateKindRepeat   updateKind = "repeat"
+)
+
+func NewManager(opts *NewManagerOptions) *Manager {
+	m := &Manager{
+		logger:   opts.Logger,
+		db:       opts.Database,
+		platform: opts.Platform,
+		trackers: make(map[TrackerName]Tracker),
+		queue:    make(chan *job, 100),
+		wakeCh:   make(chan struct{}, 1),
+		pending:  make(map[pendingKey]*Update),
+	}
+
+	for _, t := range []Tracker{newKitsuTracker(), newShikimoriTracker(), newSimklTracker()} {
+		m.trackers[t.Name()] = t
+	}
+
+	go m.worker()
+
+	m.bindHooks()
+
+	return m
+}
+
+// SetPlatform should be called when the platform changes, the media of the updates are resolved with it.
+func (m *Manager) SetPlatform(p platform.Platform) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.platform = p
+}
+
+func (m *Manager) getPlatform() platform.Platform {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.platform
+}
+
+// Connect authenticates the tracker account and saves it, replacing the previous account of the tracker.
+func (m *Manager) Connect(ctx context.Context, tracker TrackerName, credentials *Credentials) (*Account, error) {
+	t, ok := m.trackers[tracker]
+	if !ok {
+		return nil, ErrUnknownTracker
+	}
+
+	account, err := t.Authenticate(ctx, credentials)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", tracker, err)
+	}
+
+	account, err = m.db.UpsertTrackerAccount(account)
+	if err != nil {
+		return nil, err
+	}
+
+	m.logger.Info().Str("tracker", string(tracker)).Msgf("scrobbler: Connected %s", account.Username)
+
+	return m.toAccount(account), nil
+}
+
+// Disconnect removes the tracker account, its pending retries are dropped.
+func (m *Manager) Disconnect(tracker TrackerName) error {
+	if _, ok := m.trackers[tracker]; !ok {
+		return ErrUnknownTracker
+	}
+	if err := m.db.DeleteScrobbleRetries(string(tracker)); err != nil {
+		return err
+	}
+	return m.db.DeleteTrackerAccount(string(tracker))
+}
+
+func (m *Manager) GetAccounts() ([]*Account, error) {
+	accounts, err := m.db.GetTrackerAccounts()
+	if err != nil {
+		return nil, err
+	}
+	return lo.Map(accounts, func(a *models.TrackerAccount, _ int) *Account {
+		return m.toAccount(a)
+	}), nil
+}
+
+func (m *Manager) GetLogs(tracker TrackerName) ([]*models.ScrobbleLog, error) {
+	if _, ok := m.trackers[tracker]; !ok {
+		return nil, ErrUnknownTracker
+	}
+	return m.db.GetScrobbleLogs(string(tracker))
+}
+
+func (m *Manager) toAccount(account *models.TrackerAccount) *Account {
+	pendingRetries, _ := m.db.CountScrobbleRetries(account.Tracker)
+	return &Account{
+		Tracker:        TrackerName(account.Tracker),
+		Username:       account.Username,
+		ConnectedAt:    account.CreatedAt,
+		PendingRetries: pendingRetries,
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// bindHooks captures the values of the updates in the pre-update hooks, the post-update hooks are only triggered if the update succeeded.
+func (m *Manager) bindHooks() {
+	hook.GlobalHookManager.OnPreUpdateEntry().BindFunc(func(e hook_resolver.Resolver) error {
+		err := e.Next()
+		if event, ok := e.(*anilist_platform.PreUpdateEntryEvent); ok && event.MediaID != nil {
+			m.setPending(updateKindEntry, &Update{MediaID: *event.MediaID, Status: event.Status, Progress: event.Progress, Score: event.ScoreRaw})
+		}
+		return err
+	})
+	hook.GlobalHookManager.OnPreUpdateEntryProgress().BindFunc(func(e hook_resolver.Resolver) error {
+		err := e.Next()
+		if event, ok := e.(*anilist_platform.PreUpdateEntryProgressEvent); ok && event.MediaID != nil {
+			m.setPending(updateKindProgress, &Update{MediaID: *event.MediaID, Status: event.Status, Progress: event.Progress})
+		}
+		return err
+	})
+	hook.GlobalHookManager.OnPreUpdateEntryRepeat().BindFunc(func(e hook_resolver.Resolver) error {
+		err := e.Next()
+		if event, ok := e.(*anilist_platform.PreUpdateEntryRepeatEvent); ok && event.MediaID != nil {
+			m.setPending(updateKindRepeat, &Update{MediaID: *event.MediaID, Repeat: event.Repeat})
+		}
+		return err
+	})
+
+	hook.GlobalHookManager.OnPostUpdateEntry().BindFunc(func(e hook_resolver.Resolver) error {
+		if event, ok := e.(*anilist_platform.PostUpdateEntryEvent); ok && event.MediaID != nil {
+			m.dispatch(updateKindEntry, *event.MediaID)
+		}
+		return e.Next()
+	})
+	hook.GlobalHookManager.OnPostUpdateEntryProgress().BindFunc(func(e hook_resolver.Resolver) error {
+		if event, ok := e.(*anilist_platform.PostUpdateEntryProgressEvent); ok && event.MediaID != nil {
+			m.dispatch(updateKindProgress, *event.MediaID)
+		}
+		return e.Next()
+	})
+	hook.GlobalHookManager.OnPostUpdateEntryRepeat().BindFunc(func(e hook_resolver.Resolver) error {
+		if event, ok := e.(*anilist_platform.PostUpdateEntryRepeatEvent); ok && event.MediaID != nil {
+			m.dispatch(updateKindRepeat, *event.MediaID)
+		}
+		return e.Next()
+	})
+}
+
+// setPending keeps the update until the platform confirms it.
+// The fields point to the values of the event, the platform can still change them after the hook.
+func (m *Manager) setPending(kind updateKind, update *Update) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pending[pendingKey{kind: kind, mediaId: update.MediaID}] = update
+}
+
+// takePending returns a copy of the pending update of the media and removes it.
+func (m *Manager) takePending(kind updateKind, mediaId int) (*Update, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := pendingKey{kind: kind, mediaId: mediaId}
+	update, ok := m.pending[key]
+	if !ok {
+		return nil, false
+	}
+	delete(m.pending, key)
+
+	ret := *update
+	ret.Status = copyPtr(update.Status)
+	ret.Progress = copyPtr(update.Progress)
+	ret.Score = copyPtr(update.Score)
+	ret.Repeat = copyPtr(update.Repeat)
+	return &ret, true
+}
+
+func copyPtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	return lo.ToPtr(*p)
+}
+
+// dispatch queues the pending update of the media for every connected tracker.
+func (m *Manager) dispatch(kind updateKind, mediaId int) {
+	update, ok := m.takePending(kind, mediaId)
+	if !ok {
+		return
+	}
+
+	go func() {
+		defer util.HandlePanicInModuleThen("scrobbler/dispatch", func() {})
+
+		accounts, err := m.db.GetTrackerAccounts()
+		if err != nil || len(accounts) == 0 {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), scrobbleTimeout)
+		defer cancel()
+		if err := m.resolveMedia(ctx, update); err != nil {
+			m.logger.Warn().Err(err).Int("mediaId", mediaId).Msg("scrobbler: Could not find media")
+			return
+		}
+
+		for _, account := range accounts {
+			j := &job{tracker: TrackerName(account.Tracker), update: update, attempt: 1}
+
+			// The update is sent after the failed updates of the media so that it is not overwritten by them
+			if waiting, _ := m.db.HasScrobbleRetries(account.Tracker, mediaId); waiting {
+				m.saveRetry(j, 0, time.Now())
+				continue
+			}
+
+			select {
+			case m.queue <- j:
+			default:
+				m.logger.Warn().Str("tracker", account.Tracker).Int("mediaId", mediaId).Msg("scrobbler: Queue is full, update deferred")
+				m.saveRetry(j, 0, time.Now())
+			}
+		}
+	}()
+}
+
+// resolveMedia sets the media type and the IDs of the media on other databases.
+func (m *Manager) resolveMedia(ctx context.Context, update *Update) error {
+	p := m.getPlatform()
+	if p == nil {
+		return errors.New("no platform")
+	}
+
+	if collection, err := p.GetAnimeCollection(ctx, false); err == nil {
+		if entry, found := collection.GetListEntryFromAnimeId(update.MediaID); found {
+			update.MediaType = MediaTypeAnime
+			update.MalID = lo.FromPtr(entry.GetMedia().GetIDMal())
+		}
+	}
+	if update.MediaType == "" {
+		if collection, err := p.GetMangaCollection(ctx, false); err == nil {
+			if entry, found := collection.GetListEntryFromMangaId(update.MediaID); found {
+				update.MediaType = MediaTypeManga
+				update.MalID = lo.FromPtr(entry.GetMedia().GetIDMal())
+			}
+		}
+	}
+	// The media was added to the list by this update
+	if update.MediaType == "" {
+		if anime, err := p.GetAnime(ctx, update.MediaID); err == nil {
+			update.MediaType = MediaTypeAnime
+			update.MalID = lo.FromPtr(anime.GetIDMal())
+		} else if manga, err := p.GetManga(ctx, update.MediaID); err == nil {
+			update.MediaType = MediaTypeManga
+			update.MalID = lo.FromPtr(manga.GetIDMal())
+		} else {
+			return err
+		}
+	}
+
+	if update.MediaType == MediaTypeAnime {
+		if item, found := m.getMappings().FindFromAnilistID(update.MediaID); found {
+			update.MalID = lo.Ternary(update.MalID != 0, update.MalID, item.MalID)
+			update.KitsuID = item.KitsuID
+			update.SimklID = item.SimklID
+		}
+	}
+
+	return nil
+}
+
+// getMappings returns the anime lists, nil if they could not be fetched.
+func (m *Manager) getMappings() *mappings.AnimeListResponse {
+	m.mappingsMu.Lock()
+	defer m.mappingsMu.Unlock()
+
+	if m.mappings != nil && time.Since(m.mappingsFetchedAt) < mappingsTTL {
+		return m.mappings
+	}
+
+	res, err := mappings.GetAnimeLists()
+	if err != nil {
+		m.logger.Warn().Err(err).Msg("scrobbler: Failed to fetch anime lists")
+		return m.mappings
+	}
+	m.mappings = res
+	m.mappingsFetchedAt = time.Now()
+	return m.mappings
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func (m *Manager) wake() {
+	select {
+	case m.wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) worker() {
+	ticker := time.NewTicker(retryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case j := <-m.queue:
+			m.process(j)
+		case <-ticker.C:
+			m.replay()
+		case <-m.wakeCh:
+			m.replay()
+		}
+	}
+}
+
+// replay sends the due retries.
+// The retries of a media are sent in order, a retry is not sent while an older one of the same media is waiting.
+func (m *Manager) replay() {
+	defer util.HandlePanicInModuleThen("scrobbler/replay", func() {})
+
+	retries, err := m.db.GetScrobbleRetries()
+	if err != nil {
+		return
+	}
+
+	blocked := make(map[retryKey]struct{})
+	for _, retry := range retries {
+		key := retryKey{tracker: TrackerName(retry.Tracker), mediaId: retry.MediaID}
+		if _, ok := blocked[key]; ok {
+			continue
+		}
+		if retry.NextAttemptAt.After(time.Now()) {
+			blocked[key] = struct{}{}
+			continue
+		}
+
+		var update Update
+		if err := json.Unmarshal(retry.Value, &update); err != nil {
+			_ = m.db.DeleteScrobbleRetry(retry.ID)
+			continue
+		}
+
+		if !m.process(&job{tracker: TrackerName(retry.Tracker), update: &update, attempt: retry.Attempts + 1, retry: retry}) {
+			blocked[key] = struct{}{}
+		}
+	}
+}
+
+// process sends the update and saves it for a retry if it failed.
+// It returns false if the update was saved for a retry.
+func (m *Manager) process(j *job) (done bool) {
+	defer util.HandlePanicInModuleThen("scrobbler/process", func() {})
+
+	done = true
+	defer func() {
+		if done && j.retry != nil {
+			_ = m.db.DeleteScrobbleRetry(j.retry.ID)
+		}
+	}()
+
+	// The account was disconnected
+	account, err := m.db.GetTrackerAccount(string(j.tracker))
+	if err != nil {
+		return
+	}
+	tracker, ok := m.trackers[j.tracker]
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), scrobbleTimeout)
+	defer cancel()
+
+	err = m.scrobble(ctx, tracker, account, j.update)
+
+	log := &models.ScrobbleLog{
+		Tracker:   string(j.tracker),
+		MediaID:   j.update.MediaID,
+		MediaType: string(j.update.MediaType),
+		Status:    string(lo.FromPtr(j.update.Status)),
+		Progress:  lo.FromPtr(j.update.Progress),
+		Attempt:   j.attempt,
+	}
+	if err != nil {
+		log.Error = err.Error()
+	}
+	if dbErr := m.db.InsertScrobbleLog(log); dbErr != nil {
+		m.logger.Error().Err(dbErr).Msg("scrobbler: Failed to save log")
+	}
+
+	if err == nil {
+		m.logger.Debug().Str("tracker", string(j.tracker)).Int("mediaId", j.update.MediaID).Msg("scrobbler: Updated entry")
+		return
+	}
+
+	m.logger.Warn().Err(err).Str("tracker", string(j.tracker)).Int("mediaId", j.update.MediaID).Int("attempt", j.attempt).Msg("scrobbler: Failed to update entry")
+
+	if errors.Is(err, ErrUnsupported) || errors.Is(err, ErrNoMediaId) || j.attempt >= maxAttempts {
+		return
+	}
+	done = !m.saveRetry(j, j.attempt, time.Now().Add(retryDelay*time.Duration(1<<(j.attempt-1))))
+	return
+}
+
+// scrobble sends the update, refreshing the token if it expired or was rejected.
+func (m *Manager) scrobble(ctx context.Context, tracker Tracker, account *models.TrackerAccount, update *Update) error {
+	if tokenExpired(account) {
+		if err := m.refreshToken(ctx, tracker, account); err != nil {
+			return err
+		}
+	}
+
+	err := tracker.Scrobble(ctx, account, update)
+	if errors.Is(err, ErrUnauthorized) && account.RefreshToken != "" {
+		if err := m.refreshToken(ctx, tracker, account); err != nil {
+			return err
+		}
+		err = tracker.Scrobble(ctx, account, update)
+	}
+	return err
+}
+
+func (m *Manager) refreshToken(ctx context.Context, tracker Tracker, account *models.TrackerAccount) error {
+	if err := tracker.RefreshToken(ctx, account); err != nil {
+		return fmt.Errorf("failed to refresh token: %w", err)
+	}
+	_, err := m.db.UpsertTrackerAccount(account)
+	return err
+}
+
+// saveRetry saves the update so that it is sent again at the given time, it returns false if it could not be saved.
+func (m *Manager) saveRetry(j *job, attempts int, at time.Time) bool {
+	retry := j.retry
+	if retry == nil {
+		data, err := json.Marshal(j.update)
+		if err != nil {
+			return false
+		}
+		retry = &models.ScrobbleRetry{
+			Tracker: string(j.tracker),
+			MediaID: j.update.MediaID,
+			Value:   data,
+		}
+	}
+	retry.Attempts = attempts
+	retry.NextAttemptAt = at
+
+	if err := m.db.SaveScrobbleRetry(retry); err != nil {
+		m.logger.Error().Err(err).Msg("scrobbler: Failed to save retry")
+		return false
+	}
+
+	if !at.After(time.Now()) {
+		m.wake()
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+package scrobbler
+
+import (
+	"seanime/internal/api/anilist"
+	"seanime/internal/database/models"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPendingUpdates(t *testing.T) {
+	m := &Manager{pending: make(map[pendingKey]*Update)}
+
+	// The platform changes the values of the event after the pre-update hook
+	status := anilist.MediaListStatusCurrent
+	progress := 12
+	m.setPending(updateKindProgress, &Update{MediaID: 1, Status: &status, Progress: &progress})
+	status = anilist.MediaListStatusCompleted
+
+	update, ok := m.takePending(updateKindProgress, 1)
+	require.True(t, ok)
+	assert.Equal(t, anilist.MediaListStatusCompleted, *update.Status)
+	assert.Equal(t, 12, *update.Progress)
+	assert.Nil(t, update.Score)
+
+	// The copy is not affected by later changes
+	status = anilist.MediaListStatusDropped
+	assert.Equal(t, anilist.MediaListStatusCompleted, *update.Status)
+
+	_, ok = m.takePending(updateKindProgress, 1)
+	assert.False(t, ok)
+}
+
+func TestPendingUpdatesOfDifferentKinds(t *testing.T) {
+	m := &Manager{pending: make(map[pendingKey]*Update)}
+
+	progress := 3
+	repeat := 1
+	m.setPending(updateKindProgress, &Update{MediaID: 1, Progress: &progress})
+	m.setPending(updateKindRepeat, &Update{MediaID: 1, Repeat: &repeat})
+
+	update, ok := m.takePending(updateKindProgress, 1)
+	require.True(t, ok)
+	assert.Equal(t, 3, *update.Progress)
+
+	update, ok = m.takePending(updateKindRepeat, 1)
+	require.True(t, ok)
+	assert.Equal(t, 1, *update.Repeat)
+}
+
+func TestUpdateScore10(t *testing.T) {
+	tests := []struct {
+		score    *int
+		expected *int
+	}{
+		{score: nil, expected: nil},
+		{score: lo.ToPtr(0), expected: nil},
+		{score: lo.ToPtr(3), expected: lo.ToPtr(1)},
+		{score: lo.ToPtr(75), expected: lo.ToPtr(8)},
+		{score: lo.ToPtr(100), expected: lo.ToPtr(10)},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, (&Update{Score: tt.score}).score10())
+	}
+}
+
+func TestStatusConversion(t *testing.T) {
+	assert.Equal(t, "current", toKitsuStatus(anilist.MediaListStatusRepeating))
+	assert.Equal(t, "planned", toKitsuStatus(anilist.MediaListStatusPlanning))
+	assert.Equal(t, "rewatching", toShikimoriStatus(anilist.MediaListStatusRepeating))
+	assert.Equal(t, "on_hold", toShikimoriStatus(anilist.MediaListStatusPaused))
+	assert.Equal(t, "hold", toSimklStatus(anilist.MediaListStatusPaused))
+	assert.Equal(t, "plantowatch", toSimklStatus(anilist.MediaListStatusPlanning))
+}
+
+func TestTokenExpired(t *testing.T) {
+	// Simkl tokens do not expire
+	assert.False(t, tokenExpired(&models.TrackerAccount{}))
+	assert.True(t, tokenExpired(&models.TrackerAccount{TokenExpiresAt: time.Now().Add(30 * time.Second)}))
+	assert.False(t, tokenExpired(&models.TrackerAccount{TokenExpiresAt: time.Now().Add(time.Hour)}))
+}
@@ -0,0 +1,192 @@
+package scrobbler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"seanime/internal/api/anilist"
+	"seanime/internal/database/models"
+	"strconv"
+)
+
+const shikimoriBaseURL = "https://shikimori.one"
+
+// Shikimori uses MyAnimeList IDs.
+type shikimoriTracker struct {
+	client *http.Client
+}
+
+func newShikimoriTracker() *shikimoriTracker {
+	return &shikimoriTracker{client: &http.Client{}}
+}
+
+func (t *shikimoriTracker) Name() TrackerName {
+	return TrackerShikimori
+}
+
+func (t *shikimoriTracker) headers(account *models.TrackerAccount) map[string]string {
+	// Requests without a User-Agent are rejected
+	ret := map[string]string{
+		"User-Agent": "Seanime",
+	}
+	if account != nil {
+		ret["Authorization"] = "Bearer " + account.AccessToken
+	}
+	return ret
+}
+
+func (t *shikimoriTracker) Authenticate(ctx context.Context, credentials *Credentials) (*models.TrackerAccount, error) {
+	if credentials.Code == "" || credentials.ClientID == "" || credentials.ClientSecret == "" {
+		return nil, errors.New("code, client ID and client secret are required")
+	}
+
+	var token tokenResponse
+	err := doRequest(ctx, t.client, &request{
+		method:  http.MethodPost,
+		uri:     shikimoriBaseURL + "/oauth/token",
+		headers: t.headers(nil),
+		body: url.Values{
+			"grant_type":    {"authorization_code"},
+			"client_id":     {credentials.ClientID},
+			"client_secret": {credentials.ClientSecret},
+			"code":          {credentials.Code},
+			"redirect_uri":  {credentials.RedirectURI},
+		},
+		ret: &token,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	account := &models.TrackerAccount{
+		Tracker:      string(TrackerShikimori),
+		ClientID:     credentials.ClientID,
+		ClientSecret: credentials.ClientSecret,
+	}
+	token.apply(account)
+
+	var user struct {
+		ID       int    `json:"id"`
+		Nickname string `json:"nickname"`
+	}
+	err = doRequest(ctx, t.client, &request{
+		method:  http.MethodGet,
+		uri:     shikimoriBaseURL + "/api/users/whoami",
+		headers: t.headers(account),
+		ret:     &user,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	account.UserID = strconv.Itoa(user.ID)
+	account.Username = user.Nickname
+	return account, nil
+}
+
+func (t *shikimoriTracker) RefreshToken(ctx context.Context, account *models.TrackerAccount) error {
+	var token tokenResponse
+	err := doRequest(ctx, t.client, &request{
+		method:  http.MethodPost,
+		uri:     shikimoriBaseURL + "/oauth/token",
+		headers: t.headers(nil),
+		body: url.Values{
+			"grant_type":    {"refresh_token"},
+			"client_id":     {account.ClientID},
+			"client_secret": {account.ClientSecret},
+			"refresh_token": {account.RefreshToken},
+		},
+		ret: &token,
+	})
+	if err != nil {
+		return err
+	}
+	token.apply(account)
+	return nil
+}
+
+func (t *shikimoriTracker) Scrobble(ctx context.Context, account *models.TrackerAccount, update *Update) error {
+	if update.MalID == 0 {
+		return ErrNoMediaId
+	}
+
+	targetType := "Anime"
+	progressField := "episodes"
+	if update.MediaType == MediaTypeManga {
+		targetType = "Manga"
+		progressField = "chapters"
+	}
+
+	// Find the user rate
+	var rates []struct {
+		ID int `json:"id"`
+	}
+	err := doRequest(ctx, t.client, &request{
+		method:  http.MethodGet,
+		uri:     fmt.Sprintf("%s/api/v2/user_rates?user_id=%s&target_id=%d&target_type=%s", shikimoriBaseURL, account.UserID, update.MalID, targetType),
+		headers: t.headers(account),
+		ret:     &rates,
+	})
+	if err != nil {
+		return err
+	}
+
+	rate := map[string]interface{}{}
+	if update.Status != nil {
+		rate["status"] = toShikimoriStatus(*update.Status)
+	}
+	if update.Progress != nil {
+		rate[progressField] = *update.Progress
+	}
+	if update.Score != nil {
+		rate["score"] = 0
+		if score := update.score10(); score != nil {
+			rate["score"] = *score
+		}
+	}
+	if update.Repeat != nil {
+		rate["rewatches"] = *update.Repeat
+	}
+
+	if len(rates) > 0 {
+		return doRequest(ctx, t.client, &request{
+			method:  http.MethodPatch,
+			uri:     fmt.Sprintf("%s/api/v2/user_rates/%d", shikimoriBaseURL, rates[0].ID),
+			headers: t.headers(account),
+			body:    map[string]interface{}{"user_rate": rate},
+		})
+	}
+
+	rate["user_id"] = account.UserID
+	rate["target_id"] = update.MalID
+	rate["target_type"] = targetType
+	if _, ok := rate["status"]; !ok {
+		rate["status"] = "watching"
+	}
+	return doRequest(ctx, t.client, &request{
+		method:  http.MethodPost,
+		uri:     shikimoriBaseURL + "/api/v2/user_rates",
+		headers: t.headers(account),
+		body:    map[string]interface{}{"user_rate": rate},
+	})
+}
+
+// toShikimoriStatus converts the status, "watching" is also used for manga.
+func toShikimoriStatus(status anilist.MediaListStatus) string {
+	switch status {
+	case anilist.MediaListStatusRepeating:
+		return "rewatching"
+	case anilist.MediaListStatusCompleted:
+		return "completed"
+	case anilist.MediaListStatusPaused:
+		return "on_hold"
+	case anilist.MediaListStatusDropped:
+		return "dropped"
+	case anilist.MediaListStatusPlanning:
+		return "planned"
+	default:
+		return "watching"
+	}
+}
@@ -0,0 +1,166 @@
+package scrobbler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"seanime/internal/api/anilist"
+	"seanime/internal/database/models"
+	"strconv"
+)
+
+const simklBaseURL = "https://api.simkl.com"
+
+// Simkl only tracks anime, its tokens do not expire.
+type simklTracker struct {
+	client *http.Client
+}
+
+func newSimklTracker() *simklTracker {
+	return &simklTracker{client: &http.Client{}}
+}
+
+func (t *simklTracker) Name() TrackerName {
+	return TrackerSimkl
+}
+
+func (t *simklTracker) headers(account *models.TrackerAccount) map[string]string {
+	return map[string]string{
+		"simkl-api-key": account.ClientID,
+		"Authorization": "Bearer " + account.AccessToken,
+	}
+}
+
+func (t *simklTracker) Authenticate(ctx context.Context, credentials *Credentials) (*models.TrackerAccount, error) {
+	if credentials.Code == "" || credentials.ClientID == "" || credentials.ClientSecret == "" {
+		return nil, errors.New("code, client ID and client secret are required")
+	}
+
+	var token tokenResponse
+	err := doRequest(ctx, t.client, &request{
+		method: http.MethodPost,
+		uri:    simklBaseURL + "/oauth/token",
+		body: map[string]string{
+			"grant_type":    "authorization_code",
+			"client_id":     credentials.ClientID,
+			"client_secret": credentials.ClientSecret,
+			"code":          credentials.Code,
+			"redirect_uri":  credentials.RedirectURI,
+		},
+		ret: &token,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	account := &models.TrackerAccount{
+		Tracker:      string(TrackerSimkl),
+		ClientID:     credentials.ClientID,
+		ClientSecret: credentials.ClientSecret,
+	}
+	token.apply(account)
+
+	var settings struct {
+		User struct {
+			Name string `json:"name"`
+		} `json:"user"`
+		Account struct {
+			ID int `json:"id"`
+		} `json:"account"`
+	}
+	err = doRequest(ctx, t.client, &request{
+		method:  http.MethodPost,
+		uri:     simklBaseURL + "/users/settings",
+		headers: t.headers(account),
+		ret:     &settings,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	account.UserID = strconv.Itoa(settings.Account.ID)
+	account.Username = settings.User.Name
+	return account, nil
+}
+
+func (t *simklTracker) RefreshToken(ctx context.Context, account *models.TrackerAccount) error {
+	return nil
+}
+
+func (t *simklTracker) Scrobble(ctx context.Context, account *models.TrackerAccount, update *Update) error {
+	if update.MediaType != MediaTypeAnime {
+		return ErrUnsupported
+	}
+
+	ids := map[string]int{"anilist": update.MediaID}
+	if update.MalID != 0 {
+		ids["mal"] = update.MalID
+	}
+	if update.SimklID != 0 {
+		ids["simkl"] = update.SimklID
+	}
+
+	if update.Status != nil {
+		err := doRequest(ctx, t.client, &request{
+			method:  http.MethodPost,
+			uri:     simklBaseURL + "/sync/add-to-list",
+			headers: t.headers(account),
+			body: map[string]interface{}{
+				"shows": []map[string]interface{}{{"to": toSimklStatus(*update.Status), "ids": ids}},
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Mark the episodes up to the progress as watched, watched episodes are not duplicated
+	if update.Progress != nil && *update.Progress > 0 {
+		episodes := make([]map[string]int, 0, *update.Progress)
+		for i := 1; i <= *update.Progress; i++ {
+			episodes = append(episodes, map[string]int{"number": i})
+		}
+		err := doRequest(ctx, t.client, &request{
+			method:  http.MethodPost,
+			uri:     simklBaseURL + "/sync/history",
+			headers: t.headers(account),
+			body: map[string]interface{}{
+				"shows": []map[string]interface{}{{"ids": ids, "episodes": episodes}},
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if score := update.score10(); score != nil {
+		err := doRequest(ctx, t.client, &request{
+			method:  http.MethodPost,
+			uri:     simklBaseURL + "/sync/ratings",
+			headers: t.headers(account),
+			body: map[string]interface{}{
+				"shows": []map[string]interface{}{{"ids": ids, "rating": *score}},
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func toSimklStatus(status anilist.MediaListStatus) string {
+	switch status {
+	case anilist.MediaListStatusCompleted:
+		return "completed"
+	case anilist.MediaListStatusPaused:
+		return "hold"
+	case anilist.MediaListStatusDropped:
+		return "dropped"
+	case anilist.MediaListStatusPlanning:
+		return "plantowatch"
+	default:
+		return "watching"
+	}
+}
@@ -0,0 +1,174 @@
+package scrobbler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"seanime/internal/api/anilist"
+	"seanime/internal/database/models"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+const (
+	TrackerKitsu     TrackerName = "kitsu"
+	TrackerShikimori TrackerName = "shikimori"
+	TrackerSimkl     TrackerName = "simkl"
+
+	MediaTypeAnime MediaType = "anime"
+	MediaTypeManga MediaType = "manga"
+)
+
+var (
+	ErrUnauthorized = errors.New("tracker rejected the access token")
+	// ErrUnsupported is returned when the tracker does not support the media, it is not retried
+	ErrUnsupported = errors.New("media not supported by the tracker")
+	ErrNoMediaId   = errors.New("media not found on the tracker")
+)
+
+type (
+	TrackerName string
+	MediaType   string
+
+	// Tracker mirrors list updates to a tracker account.
+	Tracker interface {
+		Name() TrackerName
+		// Authenticate exchanges the credentials for tokens and returns the new account.
+		Authenticate(ctx context.Context, credentials *Credentials) (*models.TrackerAccount, error)
+		// RefreshToken updates the tokens of the account, it does nothing if the tokens do not expire.
+		RefreshToken(ctx context.Context, account *models.TrackerAccount) error
+		Scrobble(ctx context.Context, account *models.TrackerAccount, update *Update) error
+	}
+
+	// Credentials used to connect a tracker account.
+	// Kitsu uses the username and password, Shikimori and Simkl use an OAuth code.
+	Credentials struct {
+		Username     string `json:"username"`
+		Password     string `json:"password"`
+		Code         string `json:"code"`
+		RedirectURI  string `json:"redirectUri"`
+		ClientID     string `json:"clientId"`
+		ClientSecret string `json:"clientSecret"`
+	}
+
+	// Update is a change made to a list entry on the main platform.
+	// Nil fields were not changed.
+	Update struct {
+		MediaID   int       `json:"mediaId"`
+		MediaType MediaType `json:"mediaType"`
+		// IDs on other databases, 0 if unknown
+		MalID   int `json:"malId"`
+		KitsuID int `json:"kitsuId"`
+		SimklID int `json:"simklId"`
+
+		Status   *anilist.MediaListStatus `json:"status"`
+		Progress *int                     `json:"progress"`
+		// Score out of 100
+		Score  *int `json:"score"`
+		Repeat *int `json:"repeat"`
+	}
+)
+
+func (u *Update) isRepeating() bool {
+	return u.Status != nil && *u.Status == anilist.MediaListStatusRepeating
+}
+
+// score10 returns the score out of 10, nil if the score was not changed or removed.
+func (u *Update) score10() *int {
+	if u.Score == nil || *u.Score <= 0 {
+		return nil
+	}
+	ret := min(10, max(1, (*u.Score+5)/10))
+	return &ret
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type request struct {
+	method  string
+	uri     string
+	headers map[string]string
+	// Encoded as JSON unless it is url.Values
+	body interface{}
+	// Decoded from JSON if not nil
+	ret interface{}
+}
+
+func doRequest(ctx context.Context, client *http.Client, r *request) error {
+	var reader io.Reader
+	contentType := ""
+	switch body := r.body.(type) {
+	case nil:
+	case url.Values:
+		reader = strings.NewReader(body.Encode())
+		contentType = "application/x-www-form-urlencoded"
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.method, r.uri, reader)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for k, v := range r.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return ErrUnauthorized
+	}
+	if !((resp.StatusCode >= 200) && (resp.StatusCode <= 299)) {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("invalid response status %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	if r.ret == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(r.ret)
+}
+
+// tokenResponse is the OAuth token response of all trackers.
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+func (t *tokenResponse) apply(account *models.TrackerAccount) {
+	account.AccessToken = t.AccessToken
+	if t.RefreshToken != "" {
+		account.RefreshToken = t.RefreshToken
+	}
+	// Tokens without expiry are valid until revoked
+	if t.ExpiresIn > 0 {
+		account.TokenExpiresAt = time.Now().Add(time.Duration(t.ExpiresIn) * time.Second)
+	} else {
+		account.TokenExpiresAt = time.Time{}
+	}
+}
+
+// tokenExpired returns true if the token expires within the next minute.
+func tokenExpired(account *models.TrackerAccount) bool {
+	return !account.TokenExpiresAt.IsZero() && time.Now().Add(time.Minute).After(account.TokenExpiresAt)
+}
@@ -0,0 +1,79 @@
+package skipsegments
+
+import (
+	"regexp"
+	"seanime/internal/mkvparser"
+	"strings"
+)
+
+var (
+	// e.g. "Opening", "OP", "OP1", "Intro", "Opening Credits", "NCOP"
+	openingChapterRegex = regexp.MustCompile(`^(?:nc)?(?:op|opening|intro|opening credits|opening theme|opening song)(?:\s*\d+)?$`)
+	// e.g. "Ending", "ED", "ED2", "Outro", "Credits", "End Credits"
+	endingChapterRegex = regexp.MustCompile(`^(?:nc)?(?:ed|ending|outro|credits|end credits|ending credits|ending theme|ending song)(?:\s*\d+)?$`)
+	// e.g. "Preview", "Next Episode Preview", "Next Time"
+	previewChapterRegex = regexp.MustCompile(`^(?:next episode preview|episode preview|preview|next time|next episode|yokoku)$`)
+	// e.g. "Recap", "Previously On"
+	recapChapterRegex = regexp.MustCompile(`^(?:recap|previously|previously on|summary)$`)
+)
+
+// FromChapters derives skip segments from the chapter names of a file.
+//   - duration: Duration of the file in seconds, used as the end of the last chapter
+func FromChapters(chapters []*mkvparser.ChapterInfo, duration float64) []*Segment {
+	ret := make([]*Segment, 0)
+
+	for i, chapter := range chapters {
+		segmentType, ok := chapterSegmentType(chapter.Text)
+		if !ok {
+			continue
+		}
+
+		// The end of a chapter is optional, use the start of the next chapter
+		end := chapter.End
+		if end <= chapter.Start {
+			if i+1 < len(chapters) {
+				end = chapters[i+1].Start
+			} else {
+				end = duration
+			}
+		}
+		if end <= chapter.Start {
+			continue
+		}
+
+		// Merge consecutive chapters of the same type (e.g. "Opening" followed by "Opening (cont.)")
+		if len(ret) > 0 && ret[len(ret)-1].Type == segmentType && ret[len(ret)-1].End >= chapter.Start-1 {
+			ret[len(ret)-1].End = end
+			continue
+		}
+
+		ret = append(ret, &Segment{
+			Type:   segmentType,
+			Source: SourceChapters,
+			Start:  chapter.Start,
+			End:    end,
+		})
+	}
+
+	return ret
+}
+
+func chapterSegmentType(name string) (SegmentType, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	// Remove punctuation, e.g. "[OP]", "Opening (cont.)", "Next Episode's Preview"
+	name = strings.NewReplacer("[", "", "]", "", "(", "", ")", "", "'s", "", "-", " ", "_", " ", ".", "").Replace(name)
+	name = strings.TrimSuffix(strings.TrimSpace(name), " cont")
+	name = strings.Join(strings.Fields(name), " ")
+
+	switch {
+	case openingChapterRegex.MatchString(name):
+		return TypeOpening, true
+	case endingChapterRegex.MatchString(name):
+		return TypeEnding, true
+	case previewChapterRegex.MatchString(name):
+		return TypePreview, true
+	case recapChapterRegex.MatchString(name):
+		return TypeRecap, true
+	}
+	return "", false
+}
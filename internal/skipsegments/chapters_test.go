@@ -0,0 +1,81 @@
+package skipsegments
+
+import (
+	"seanime/internal/mkvparser"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromChapters(t *testing.T) {
+	chapters := []*mkvparser.ChapterInfo{
+		{Start: 0, Text: "Prologue"},
+		{Start: 95.5, Text: "Opening"},
+		{Start: 185.5, Text: "Part A"},
+		{Start: 700, Text: "Part B"},
+		{Start: 1290, End: 1380, Text: "ED"},
+		{Start: 1380, Text: "Next Episode's Preview"},
+	}
+
+	segments := FromChapters(chapters, 1420)
+
+	assert.Equal(t, []*Segment{
+		{Type: TypeOpening, Source: SourceChapters, Start: 95.5, End: 185.5},
+		{Type: TypeEnding, Source: SourceChapters, Start: 1290, End: 1380},
+		{Type: TypePreview, Source: SourceChapters, Start: 1380, End: 1420},
+	}, segments)
+}
+
+func TestChapterSegmentType(t *testing.T) {
+	tests := map[string]SegmentType{
+		"Opening":            TypeOpening,
+		"OP":                 TypeOpening,
+		"OP2":                TypeOpening,
+		"[NCOP]":             TypeOpening,
+		"Opening (cont.)":    TypeOpening,
+		"Intro":              TypeOpening,
+		"Ending":             TypeEnding,
+		"ed 1":               TypeEnding,
+		"End Credits":        TypeEnding,
+		"Preview":            TypePreview,
+		"Recap":              TypeRecap,
+		"Chapter 1":          "",
+		"Operation Overlord": "",
+		"Episode":            "",
+	}
+
+	for name, expected := range tests {
+		segmentType, ok := chapterSegmentType(name)
+		assert.Equal(t, expected != "", ok, name)
+		assert.Equal(t, expected, segmentType, name)
+	}
+}
+
+func TestMergeSegments(t *testing.T) {
+	segments := mergeSegments([]*Segment{
+		{Type: TypeEnding, Source: SourceChapters, Start: 1300, End: 1390},
+		{Type: TypeOpening, Source: SourceChapters, Start: 90, End: 180},
+		{Type: TypeOpening, Source: SourceUser, Start: 92, End: 181},
+		{Type: TypeOpening, Source: SourceFingerprint, Start: 91, End: 180},
+	})
+
+	assert.Equal(t, []*Segment{
+		{Type: TypeOpening, Source: SourceUser, Start: 92, End: 181},
+		{Type: TypeEnding, Source: SourceChapters, Start: 1300, End: 1390},
+	}, segments)
+}
+
+func TestMergeSegments_SameTypeRanges(t *testing.T) {
+	segments := mergeSegments([]*Segment{
+		{Type: TypeRecap, Source: SourceChapters, Start: 0, End: 60},
+		{Type: TypeRecap, Source: SourceChapters, Start: 150, End: 200},
+		{Type: TypeRecap, Source: SourceUser, Start: 140, End: 195},
+		{Type: TypeOpening, Source: SourceChapters, Start: 60, End: 150},
+	})
+
+	assert.Equal(t, []*Segment{
+		{Type: TypeRecap, Source: SourceChapters, Start: 0, End: 60},
+		{Type: TypeOpening, Source: SourceChapters, Start: 60, End: 150},
+		{Type: TypeRecap, Source: SourceUser, Start: 140, End: 195},
+	}, segments)
+}
@@ -0,0 +1,305 @@
+package skipsegments
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+	"math/cmplx"
+	"seanime/internal/database/models"
+	"seanime/internal/util"
+	"strconv"
+	"sync"
+)
+
+// Openings are detected by comparing the audio of two episodes of the same media.
+// Each episode is reduced to a sequence of 32-bit sub-fingerprints (one every ~93ms) that encode how the energy
+// of the spectrum changes across frequency bands and time (Haitsma & Kalker, "A Highly Robust Audio Fingerprinting System").
+// The longest range of matching sub-fingerprints shared by both episodes is the opening.
+
+const (
+	fingerprintSampleRate = 5512
+	fingerprintFrameSize  = 2048 // Must be a power of 2
+	fingerprintHopSize    = 512
+	fingerprintBands      = 33 // 32 bits per sub-fingerprint
+	fingerprintMinFreq    = 300.0
+	fingerprintMaxFreq    = 2000.0
+	// Only the beginning of episodes is analyzed
+	fingerprintMaxDuration = 6 * 60
+	// Sub-fingerprints differing by at most this many bits are considered the same
+	fingerprintMaxBitErrors = 10
+	// Unmatched sub-fingerprints allowed inside a matching range
+	fingerprintMaxGap = 10
+	// Minimum duration of a detected opening in seconds
+	fingerprintMinDuration = 20.0
+)
+
+const fingerprintFrameDuration = float64(fingerprintHopSize) / fingerprintSampleRate
+
+type (
+	EpisodeFile struct {
+		EpisodeNumber int
+		Path          string
+	}
+
+	DetectOpeningsOptions struct {
+		MediaId    int
+		FfmpegPath string
+		// Episodes of the media, sorted by episode number
+		Episodes []*EpisodeFile
+	}
+)
+
+var detectMu sync.Mutex
+
+// DetectOpenings detects the openings of the episodes by comparing each episode with the next one.
+// The detected segments are stored and returned by episode number.
+// Episodes whose opening cannot be found are left out.
+func (m *Manager) DetectOpenings(ctx context.Context, opts *DetectOpeningsOptions) (map[int]*Segment, error) {
+	if len(opts.Episodes) < 2 {
+		return nil, fmt.Errorf("at least 2 episodes are needed to detect openings")
+	}
+	if opts.FfmpegPath == "" {
+		opts.FfmpegPath = "ffmpeg"
+	}
+
+	if !detectMu.TryLock() {
+		return nil, fmt.Errorf("opening detection is already running")
+	}
+	defer detectMu.Unlock()
+
+	m.logger.Info().Int("mediaId", opts.MediaId).Int("episodes", len(opts.Episodes)).Msg("skip segments: Detecting openings")
+
+	fingerprints := make(map[int][]uint32)
+	getFingerprint := func(ep *EpisodeFile) ([]uint32, error) {
+		if fp, ok := fingerprints[ep.EpisodeNumber]; ok {
+			return fp, nil
+		}
+		fp, err := extractFingerprint(ctx, opts.FfmpegPath, ep.Path)
+		if err != nil {
+			return nil, err
+		}
+		fingerprints[ep.EpisodeNumber] = fp
+		return fp, nil
+	}
+
+	ret := make(map[int]*Segment)
+	for i, ep := range opts.Episodes {
+		if _, ok := ret[ep.EpisodeNumber]; ok {
+			continue
+		}
+		if ctx.Err() != nil {
+			return ret, ctx.Err()
+		}
+
+		// Compare with the next episode, or the previous one for the last episode
+		other := opts.Episodes[max(i-1, 0)]
+		if i+1 < len(opts.Episodes) {
+			other = opts.Episodes[i+1]
+		}
+
+		fpA, err := getFingerprint(ep)
+		if err != nil {
+			m.logger.Warn().Err(err).Int("episode", ep.EpisodeNumber).Msg("skip segments: Failed to extract audio fingerprint")
+			continue
+		}
+		fpB, err := getFingerprint(other)
+		if err != nil {
+			m.logger.Warn().Err(err).Int("episode", other.EpisodeNumber).Msg("skip segments: Failed to extract audio fingerprint")
+			continue
+		}
+
+		startA, startB, length, ok := findCommonRange(fpA, fpB)
+		if !ok {
+			m.logger.Debug().Int("episode", ep.EpisodeNumber).Msg("skip segments: No opening found")
+			continue
+		}
+
+		for _, match := range []struct {
+			episode int
+			start   int
+		}{{ep.EpisodeNumber, startA}, {other.EpisodeNumber, startB}} {
+			if _, ok := ret[match.episode]; ok {
+				continue
+			}
+			saved, err := m.db.UpsertSkipSegment(&models.SkipSegment{
+				MediaID:       opts.MediaId,
+				EpisodeNumber: match.episode,
+				Type:          string(TypeOpening),
+				Source:        string(SourceFingerprint),
+				StartTime:     roundTime(float64(match.start) * fingerprintFrameDuration),
+				EndTime:       roundTime(float64(match.start+length) * fingerprintFrameDuration),
+			})
+			if err != nil {
+				return ret, err
+			}
+			ret[match.episode] = fromModel(saved)
+		}
+
+		// Free the fingerprints that are no longer needed
+		if i > 0 {
+			delete(fingerprints, opts.Episodes[i-1].EpisodeNumber)
+		}
+	}
+
+	m.logger.Info().Int("mediaId", opts.MediaId).Int("detected", len(ret)).Msg("skip segments: Finished detecting openings")
+
+	return ret, nil
+}
+
+// extractFingerprint decodes the beginning of the file's audio with ffmpeg and returns its fingerprint.
+func extractFingerprint(ctx context.Context, ffmpegPath string, path string) ([]uint32, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := util.NewCmdCtx(ctx, ffmpegPath,
+		"-hide_banner", "-loglevel", "error",
+		"-i", path,
+		"-t", strconv.Itoa(fingerprintMaxDuration),
+		"-vn", "-sn", "-dn",
+		"-ac", "1",
+		"-ar", strconv.Itoa(fingerprintSampleRate),
+		"-f", "s16le",
+		"-",
+	)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg: %w: %s", err, stderr.String())
+	}
+
+	data := stdout.Bytes()
+	samples := make([]float64, len(data)/2)
+	for i := range samples {
+		samples[i] = float64(int16(binary.LittleEndian.Uint16(data[i*2:]))) / math.MaxInt16
+	}
+
+	return computeFingerprint(samples), nil
+}
+
+// computeFingerprint returns the sub-fingerprints of mono samples at fingerprintSampleRate.
+func computeFingerprint(samples []float64) []uint32 {
+	if len(samples) < fingerprintFrameSize {
+		return nil
+	}
+
+	// Hann window
+	window := make([]float64, fingerprintFrameSize)
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(fingerprintFrameSize-1))
+	}
+
+	// Logarithmically spaced band edges, as FFT bin indices
+	edges := make([]int, fingerprintBands+1)
+	for i := range edges {
+		freq := fingerprintMinFreq * math.Pow(fingerprintMaxFreq/fingerprintMinFreq, float64(i)/fingerprintBands)
+		edges[i] = int(math.Round(freq * fingerprintFrameSize / fingerprintSampleRate))
+	}
+
+	frameCount := (len(samples)-fingerprintFrameSize)/fingerprintHopSize + 1
+	ret := make([]uint32, 0, frameCount)
+
+	frame := make([]complex128, fingerprintFrameSize)
+	energies := make([]float64, fingerprintBands)
+	prevEnergies := make([]float64, fingerprintBands)
+
+	for f := 0; f < frameCount; f++ {
+		offset := f * fingerprintHopSize
+		for i := range frame {
+			frame[i] = complex(samples[offset+i]*window[i], 0)
+		}
+		fft(frame)
+
+		for b := 0; b < fingerprintBands; b++ {
+			energies[b] = 0
+			for k := edges[b]; k < max(edges[b+1], edges[b]+1); k++ {
+				energies[b] += real(frame[k])*real(frame[k]) + imag(frame[k])*imag(frame[k])
+			}
+		}
+
+		if f > 0 {
+			var fp uint32
+			for b := 0; b < fingerprintBands-1; b++ {
+				if (energies[b]-energies[b+1])-(prevEnergies[b]-prevEnergies[b+1]) > 0 {
+					fp |= 1 << b
+				}
+			}
+			ret = append(ret, fp)
+		}
+
+		energies, prevEnergies = prevEnergies, energies
+	}
+
+	return ret
+}
+
+// findCommonRange returns the longest range of sub-fingerprints shared by a and b.
+//   - startA, startB: Index of the range in a and b
+//   - length: Length of the range
+func findCommonRange(a, b []uint32) (startA int, startB int, length int, ok bool) {
+	minLength := int(math.Ceil(fingerprintMinDuration / fingerprintFrameDuration))
+
+	// Try every alignment of b relative to a (b[i-offset] is compared with a[i])
+	for offset := -(len(b) - 1); offset < len(a); offset++ {
+		from := max(0, offset)
+		to := min(len(a), len(b)+offset)
+		if to-from < max(minLength, length) {
+			continue
+		}
+
+		runStart, lastMatch := -1, -1
+		for i := from; i < to; i++ {
+			// Silence is encoded as 0, it should not be matched
+			if a[i] == 0 || bits.OnesCount32(a[i]^b[i-offset]) > fingerprintMaxBitErrors {
+				if runStart != -1 && i-lastMatch > fingerprintMaxGap {
+					runStart = -1
+				}
+				continue
+			}
+			if runStart == -1 {
+				runStart = i
+			}
+			lastMatch = i
+			if runLength := lastMatch - runStart + 1; runLength > length {
+				startA, startB, length = runStart, runStart-offset, runLength
+			}
+		}
+	}
+
+	return startA, startB, length, length >= minLength
+}
+
+// fft computes the discrete Fourier transform in place, len(x) must be a power of 2.
+func fft(x []complex128) {
+	n := len(x)
+
+	// Bit reversal permutation
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				u := x[start+k]
+				v := x[start+k+size/2] * w
+				x[start+k] = u + v
+				x[start+k+size/2] = u - v
+				w *= step
+			}
+		}
+	}
+}
+
+func roundTime(t float64) float64 {
+	return math.Round(t*100) / 100
+}
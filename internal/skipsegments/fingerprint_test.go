@@ -0,0 +1,80 @@
+package skipsegments
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFFT(t *testing.T) {
+	n := 64
+	x := make([]complex128, n)
+	for i := range x {
+		x[i] = complex(math.Cos(2*math.Pi*4*float64(i)/float64(n)), 0)
+	}
+	fft(x)
+
+	for k, v := range x {
+		magnitude := math.Round(math.Hypot(real(v), imag(v)))
+		if k == 4 || k == n-4 {
+			assert.Equal(t, float64(n/2), magnitude, "bin %d", k)
+		} else {
+			assert.Equal(t, float64(0), magnitude, "bin %d", k)
+		}
+	}
+}
+
+func TestComputeFingerprint_DetectsSharedAudio(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	// Colored noise so that the spectrum changes over time like real audio
+	noise := func(seconds float64) []float64 {
+		ret := make([]float64, int(seconds*fingerprintSampleRate))
+		freq := 500.0
+		for i := range ret {
+			if i%2000 == 0 {
+				freq = 300 + rng.Float64()*1700
+			}
+			ret[i] = 0.5*math.Sin(2*math.Pi*freq*float64(i)/fingerprintSampleRate) + 0.2*(rng.Float64()*2-1)
+		}
+		return ret
+	}
+
+	opening := noise(60)
+
+	var a, b []float64
+	a = append(a, noise(90)...)
+	a = append(a, opening...)
+	a = append(a, noise(30)...)
+	b = append(b, noise(10.3)...)
+	b = append(b, opening...)
+	b = append(b, noise(50)...)
+
+	startA, startB, length, ok := findCommonRange(computeFingerprint(a), computeFingerprint(b))
+	require.True(t, ok)
+
+	assert.InDelta(t, 90, float64(startA)*fingerprintFrameDuration, 2)
+	assert.InDelta(t, 10.3, float64(startB)*fingerprintFrameDuration, 2)
+	assert.InDelta(t, 60, float64(length)*fingerprintFrameDuration, 4)
+}
+
+func TestFindCommonRange_NoMatch(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+
+	a := make([]uint32, 1000)
+	b := make([]uint32, 1000)
+	for i := range a {
+		a[i] = rng.Uint32()
+		b[i] = rng.Uint32()
+	}
+
+	_, _, _, ok := findCommonRange(a, b)
+	assert.False(t, ok)
+
+	// Silence should not be matched
+	_, _, _, ok = findCommonRange(make([]uint32, 1000), make([]uint32, 1000))
+	assert.False(t, ok)
+}
@@ -0,0 +1,157 @@
+package skipsegments
+
+import (
+	"cmp"
+	"errors"
+	"seanime/internal/database/db"
+	"seanime/internal/database/models"
+	"seanime/internal/mkvparser"
+	"slices"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	TypeOpening SegmentType = "opening"
+	TypeEnding  SegmentType = "ending"
+	TypeRecap   SegmentType = "recap"
+	TypePreview SegmentType = "preview"
+)
+
+const (
+	SourceUser        Source = "user"        // Submitted by the user
+	SourceFingerprint Source = "fingerprint" // Detected by comparing the audio of episodes
+	SourceChapters    Source = "chapters"    // Derived from the chapter names of the file
+)
+
+type (
+	SegmentType string
+	Source      string
+
+	// Segment is a skippable range of an episode.
+	Segment struct {
+		// ID of the stored segment, 0 for chapter-based segments
+		ID     uint        `json:"id,omitempty"`
+		Type   SegmentType `json:"type"`
+		Source Source      `json:"source"`
+		Start  float64     `json:"start"` // Start time in seconds
+		End    float64     `json:"end"`   // End time in seconds
+	}
+
+	// Manager merges the skip segments derived from chapters with the stored ones.
+	Manager struct {
+		logger *zerolog.Logger
+		db     *db.Database
+	}
+
+	NewManagerOptions struct {
+		Logger   *zerolog.Logger
+		Database *db.Database
+	}
+)
+
+// Sources sorted by priority, a segment from a source replaces the overlapping segments of the same type from the sources after it.
+var sourcePriority = []Source{SourceUser, SourceFingerprint, SourceChapters}
+
+func NewManager(opts *NewManagerOptions) *Manager {
+	return &Manager{
+		logger: opts.Logger,
+		db:     opts.Database,
+	}
+}
+
+func IsValidType(t SegmentType) bool {
+	return t == TypeOpening || t == TypeEnding || t == TypeRecap || t == TypePreview
+}
+
+type GetSegmentsOptions struct {
+	MediaId       int
+	EpisodeNumber int
+	// Chapters of the file, optional
+	Chapters []*mkvparser.ChapterInfo
+	// Duration of the file in seconds, optional
+	Duration float64
+}
+
+// GetSegments returns the skip segments of an episode, sorted by start time.
+func (m *Manager) GetSegments(opts *GetSegmentsOptions) []*Segment {
+	if m == nil {
+		return []*Segment{}
+	}
+
+	segments := FromChapters(opts.Chapters, opts.Duration)
+
+	stored, err := m.db.GetSkipSegments(opts.MediaId, opts.EpisodeNumber)
+	if err != nil {
+		m.logger.Error().Err(err).Msg("skip segments: Failed to get stored segments")
+	}
+	for _, s := range stored {
+		segments = append(segments, fromModel(s))
+	}
+
+	return mergeSegments(segments)
+}
+
+// SaveUserSegment saves a segment submitted by the user.
+func (m *Manager) SaveUserSegment(mediaId int, episodeNumber int, segmentType SegmentType, start float64, end float64) (*Segment, error) {
+	if !IsValidType(segmentType) {
+		return nil, errors.New("invalid segment type")
+	}
+	if start < 0 || end <= start {
+		return nil, errors.New("invalid segment range")
+	}
+
+	saved, err := m.db.UpsertSkipSegment(&models.SkipSegment{
+		MediaID:       mediaId,
+		EpisodeNumber: episodeNumber,
+		Type:          string(segmentType),
+		Source:        string(SourceUser),
+		StartTime:     start,
+		EndTime:       end,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.logger.Debug().Int("mediaId", mediaId).Int("episode", episodeNumber).Str("type", string(segmentType)).Msg("skip segments: Saved segment")
+
+	return fromModel(saved), nil
+}
+
+func (m *Manager) DeleteSegment(id uint) error {
+	return m.db.DeleteSkipSegment(id)
+}
+
+// mergeSegments merges the segments of the same type that overlap, keeping the one from the source with the highest priority.
+// Segments of the same type that don't overlap (e.g. a recap at the start and another one after the opening) are all kept.
+func mergeSegments(segments []*Segment) []*Segment {
+	sorted := slices.Clone(segments)
+	slices.SortStableFunc(sorted, func(a, b *Segment) int {
+		return cmp.Compare(slices.Index(sourcePriority, a.Source), slices.Index(sourcePriority, b.Source))
+	})
+
+	ret := make([]*Segment, 0, len(sorted))
+	for _, s := range sorted {
+		overlaps := slices.ContainsFunc(ret, func(kept *Segment) bool {
+			return kept.Type == s.Type && s.Start < kept.End && kept.Start < s.End
+		})
+		if !overlaps {
+			ret = append(ret, s)
+		}
+	}
+	slices.SortFunc(ret, func(a, b *Segment) int {
+		return cmp.Compare(a.Start, b.Start)
+	})
+
+	return ret
+}
+
+func fromModel(s *models.SkipSegment) *Segment {
+	return &Segment{
+		ID:     s.ID,
+		Type:   SegmentType(s.Type),
+		Source: Source(s.Source),
+		Start:  s.StartTime,
+		End:    s.EndTime,
+	}
+}
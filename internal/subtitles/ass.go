@@ -0,0 +1,346 @@
+package subtitles
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Default script resolution when PlayResX/PlayResY are missing
+	assDefaultPlayResX = 384
+	assDefaultPlayResY = 288
+)
+
+type assStyle struct {
+	bold      bool
+	italic    bool
+	underline bool
+	alignment int
+}
+
+func parseASS(content string) (*Track, error) {
+	track := &Track{}
+
+	playResX, playResY := float64(0), float64(0)
+	styles := make(map[string]*assStyle)
+	isLegacy := false // SSA (v4) uses a different alignment layout
+
+	var section string
+	var styleFormat, eventFormat []string
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(line)
+			if section == "[v4 styles]" {
+				isLegacy = true
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch section {
+		case "[script info]":
+			switch strings.ToLower(key) {
+			case "playresx":
+				playResX, _ = strconv.ParseFloat(value, 64)
+			case "playresy":
+				playResY, _ = strconv.ParseFloat(value, 64)
+			}
+		case "[v4+ styles]", "[v4 styles]":
+			switch key {
+			case "Format":
+				styleFormat = splitASSFormat(value)
+			case "Style":
+				fields := splitASSFields(value, len(styleFormat))
+				name, style := parseASSStyle(styleFormat, fields, isLegacy)
+				styles[name] = style
+			}
+		case "[events]":
+			switch key {
+			case "Format":
+				eventFormat = splitASSFormat(value)
+			case "Dialogue":
+				if len(eventFormat) == 0 {
+					return nil, fmt.Errorf("missing events format")
+				}
+				cue, err := parseASSDialogue(eventFormat, splitASSFields(value, len(eventFormat)), styles, isLegacy)
+				if err != nil {
+					return nil, err
+				}
+				if cue != nil {
+					track.Cues = append(track.Cues, cue)
+				}
+			}
+		}
+	}
+
+	// Convert \pos coordinates to percentages of the script resolution
+	switch {
+	case playResX == 0 && playResY == 0:
+		playResX, playResY = assDefaultPlayResX, assDefaultPlayResY
+	case playResX == 0:
+		playResX = playResY * 4 / 3
+	case playResY == 0:
+		playResY = playResX * 3 / 4
+	}
+	for _, cue := range track.Cues {
+		if cue.Position != nil {
+			cue.Position.X = math.Round(cue.Position.X/playResX*10000) / 100
+			cue.Position.Y = math.Round(cue.Position.Y/playResY*10000) / 100
+		}
+	}
+
+	return track, nil
+}
+
+func splitASSFormat(value string) []string {
+	format := strings.Split(value, ",")
+	for i := range format {
+		format[i] = strings.ToLower(strings.TrimSpace(format[i]))
+	}
+	return format
+}
+
+// splitASSFields splits a line into n fields, the last field (text) can contain commas.
+func splitASSFields(value string, n int) []string {
+	if n <= 0 {
+		return nil
+	}
+	fields := strings.SplitN(value, ",", n)
+	for i := 0; i < len(fields)-1; i++ {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	return fields
+}
+
+func getASSField(format []string, fields []string, name string) string {
+	for i, f := range format {
+		if f == name && i < len(fields) {
+			return fields[i]
+		}
+	}
+	return ""
+}
+
+func parseASSStyle(format []string, fields []string, isLegacy bool) (string, *assStyle) {
+	style := &assStyle{
+		// -1 is true in ASS
+		bold:      getASSField(format, fields, "bold") == "-1" || getASSField(format, fields, "bold") == "1",
+		italic:    getASSField(format, fields, "italic") == "-1" || getASSField(format, fields, "italic") == "1",
+		underline: getASSField(format, fields, "underline") == "-1" || getASSField(format, fields, "underline") == "1",
+	}
+	style.alignment, _ = strconv.Atoi(getASSField(format, fields, "alignment"))
+	if isLegacy {
+		style.alignment = legacyAlignmentToNumpad(style.alignment)
+	}
+	return strings.TrimPrefix(getASSField(format, fields, "name"), "*"), style
+}
+
+func parseASSDialogue(format []string, fields []string, styles map[string]*assStyle, isLegacy bool) (*Cue, error) {
+	start, err := parseASSTimestamp(getASSField(format, fields, "start"))
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseASSTimestamp(getASSField(format, fields, "end"))
+	if err != nil {
+		return nil, err
+	}
+
+	style, ok := styles[strings.TrimPrefix(getASSField(format, fields, "style"), "*")]
+	if !ok {
+		style, ok = styles["Default"]
+		if !ok {
+			style = &assStyle{}
+		}
+	}
+
+	cue := &Cue{
+		Start:     start,
+		End:       end,
+		Alignment: style.alignment,
+	}
+
+	parseASSText(cue, getASSField(format, fields, "text"), style)
+	cue.trimSpans()
+	if len(cue.Spans) == 0 {
+		return nil, nil
+	}
+
+	return cue, nil
+}
+
+// parseASSText converts the text of a dialogue line and its override tags to spans.
+// Only basic styling (bold, italic, underline), alignment and position are kept.
+func parseASSText(cue *Cue, text string, style *assStyle) {
+	current := Span{Bold: style.bold, Italic: style.italic, Underline: style.underline}
+	drawing := false
+	alignmentSet := false
+
+	for len(text) > 0 {
+		if text[0] == '{' {
+			end := strings.IndexByte(text, '}')
+			if end != -1 {
+				for _, tag := range strings.Split(text[1:end], `\`)[1:] {
+					tag = strings.TrimSpace(tag)
+					switch {
+					case strings.HasPrefix(tag, "r"):
+						current.Bold, current.Italic, current.Underline = style.bold, style.italic, style.underline
+					case strings.HasPrefix(tag, "an"):
+						if n, err := strconv.Atoi(tag[2:]); err == nil && n >= 1 && n <= 9 && !alignmentSet {
+							cue.Alignment = n
+							alignmentSet = true
+						}
+					case strings.HasPrefix(tag, "a") && !strings.HasPrefix(tag, "alpha"):
+						if n, err := strconv.Atoi(tag[1:]); err == nil && !alignmentSet {
+							cue.Alignment = legacyAlignmentToNumpad(n)
+							alignmentSet = true
+						}
+					case strings.HasPrefix(tag, "pos("), strings.HasPrefix(tag, "move("):
+						args := strings.Split(strings.TrimSuffix(tag[strings.IndexByte(tag, '(')+1:], ")"), ",")
+						if len(args) >= 2 && cue.Position == nil {
+							x, errX := strconv.ParseFloat(strings.TrimSpace(args[0]), 64)
+							y, errY := strconv.ParseFloat(strings.TrimSpace(args[1]), 64)
+							if errX == nil && errY == nil {
+								cue.Position = &Position{X: x, Y: y}
+							}
+						}
+					case strings.HasPrefix(tag, "p") && !strings.HasPrefix(tag, "pbo"):
+						// Drawing mode, the text is vector drawing commands
+						n, err := strconv.Atoi(tag[1:])
+						if err == nil {
+							drawing = n > 0
+						}
+					case strings.HasPrefix(tag, "b") && !strings.HasPrefix(tag, "blur") && !strings.HasPrefix(tag, "be") && !strings.HasPrefix(tag, "bord"):
+						// \b1, \b0 or a font weight (\b700)
+						if n, err := strconv.Atoi(tag[1:]); err == nil {
+							current.Bold = n == 1 || n >= 700
+						}
+					case strings.HasPrefix(tag, "i") && !strings.HasPrefix(tag, "iclip"):
+						if n, err := strconv.Atoi(tag[1:]); err == nil {
+							current.Italic = n == 1
+						}
+					case strings.HasPrefix(tag, "u"):
+						if n, err := strconv.Atoi(tag[1:]); err == nil {
+							current.Underline = n == 1
+						}
+					}
+				}
+				text = text[end+1:]
+				continue
+			}
+		}
+
+		// Read the text until the next override block
+		// An unclosed override block is treated as text
+		next := strings.IndexByte(text[1:], '{') + 1
+		if next == 0 {
+			next = len(text)
+		}
+		if !drawing {
+			span := current
+			span.Text = strings.NewReplacer(`\N`, "\n", `\n`, " ", `\h`, " ").Replace(text[:next])
+			cue.appendSpan(span)
+		}
+		text = text[next:]
+	}
+}
+
+func writeASS(cues []*Cue) string {
+	var sb strings.Builder
+
+	sb.WriteString("[Script Info]\n")
+	sb.WriteString("ScriptType: v4.00+\n")
+	sb.WriteString("WrapStyle: 0\n")
+	sb.WriteString("ScaledBorderAndShadow: yes\n")
+	sb.WriteString(fmt.Sprintf("PlayResX: %d\n", assDefaultPlayResX))
+	sb.WriteString(fmt.Sprintf("PlayResY: %d\n", assDefaultPlayResY))
+	sb.WriteString("\n")
+	sb.WriteString("[V4+ Styles]\n")
+	sb.WriteString("Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding\n")
+	sb.WriteString("Style: Default,Roboto Medium,20,&H00FFFFFF,&H000000FF,&H00000000,&H00000000,0,0,0,0,100,100,0,0,1,1.1,0,2,16,16,19,1\n")
+	sb.WriteString("\n")
+	sb.WriteString("[Events]\n")
+	sb.WriteString("Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n")
+
+	for _, cue := range cues {
+		if len(cue.Spans) == 0 {
+			continue
+		}
+		sb.WriteString("Dialogue: 0,")
+		sb.WriteString(formatASSTimestamp(cue.Start))
+		sb.WriteString(",")
+		sb.WriteString(formatASSTimestamp(cue.End))
+		sb.WriteString(",Default,,0,0,0,,")
+		sb.WriteString(alignmentTag(cue.Alignment))
+		if cue.Position != nil {
+			sb.WriteString(fmt.Sprintf("{\\pos(%s,%s)}",
+				strconv.FormatFloat(math.Round(cue.Position.X*assDefaultPlayResX)/100, 'f', -1, 64),
+				strconv.FormatFloat(math.Round(cue.Position.Y*assDefaultPlayResY)/100, 'f', -1, 64)))
+		}
+
+		prev := Span{}
+		for _, span := range cue.Spans {
+			var tags string
+			if span.Bold != prev.Bold {
+				tags += `\b` + boolToASS(span.Bold)
+			}
+			if span.Italic != prev.Italic {
+				tags += `\i` + boolToASS(span.Italic)
+			}
+			if span.Underline != prev.Underline {
+				tags += `\u` + boolToASS(span.Underline)
+			}
+			if tags != "" {
+				sb.WriteString("{" + tags + "}")
+			}
+			sb.WriteString(strings.ReplaceAll(span.Text, "\n", `\N`))
+			prev = span
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+func boolToASS(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// parseASSTimestamp parses "H:MM:SS.cc".
+func parseASSTimestamp(s string) (time.Duration, error) {
+	return parseTimestamp(strings.TrimSpace(s))
+}
+
+func formatASSTimestamp(d time.Duration) string {
+	h, m, s, ms := splitDuration(d)
+	return fmt.Sprintf("%d:%02d:%02d.%02d", h, m, s, ms/10)
+}
+
+// legacyAlignmentToNumpad converts an SSA alignment (1-3 bottom, 5-7 top, 9-11 middle) to the numpad layout used by ASS.
+func legacyAlignmentToNumpad(alignment int) int {
+	switch {
+	case alignment >= 1 && alignment <= 3:
+		return alignment
+	case alignment >= 5 && alignment <= 7:
+		return alignment + 2
+	case alignment >= 9 && alignment <= 11:
+		return alignment - 5
+	}
+	return 0
+}
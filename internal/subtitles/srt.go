@@ -0,0 +1,163 @@
+package subtitles
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var srtAlignmentRegex = regexp.MustCompile(`\{\\an?(\d+)\}`)
+var srtOverrideRegex = regexp.MustCompile(`\{\\[^}]*\}`)
+
+func parseSRT(content string) (*Track, error) {
+	track := &Track{}
+
+	blocks := strings.Split(strings.TrimSpace(content), "\n\n")
+	for _, block := range blocks {
+		lines := strings.Split(strings.TrimSpace(block), "\n")
+
+		// Find the timing line, the index line is optional
+		timingIdx := -1
+		for i, line := range lines {
+			if strings.Contains(line, "-->") {
+				timingIdx = i
+				break
+			}
+		}
+		if timingIdx == -1 {
+			continue
+		}
+
+		start, end, _, err := parseTimingLine(lines[timingIdx], parseSRTTimestamp)
+		if err != nil {
+			return nil, err
+		}
+
+		cue := &Cue{
+			Start: start,
+			End:   end,
+		}
+
+		text := strings.Join(lines[timingIdx+1:], "\n")
+		// {\an8} is commonly used to position SRT subtitles
+		if match := srtAlignmentRegex.FindStringSubmatch(text); match != nil {
+			cue.Alignment, _ = strconv.Atoi(match[1])
+			if strings.HasPrefix(match[0], `{\a`) && !strings.HasPrefix(match[0], `{\an`) {
+				cue.Alignment = legacyAlignmentToNumpad(cue.Alignment)
+			}
+		}
+		text = srtOverrideRegex.ReplaceAllString(text, "")
+
+		cue.Spans = parseMarkup(text, func(s string) string { return s })
+		if len(cue.Spans) == 0 {
+			continue
+		}
+
+		track.Cues = append(track.Cues, cue)
+	}
+
+	return track, nil
+}
+
+func writeSRT(cues []*Cue) string {
+	var sb strings.Builder
+
+	i := 1
+	for _, cue := range cues {
+		if len(cue.Spans) == 0 {
+			continue
+		}
+		sb.WriteString(strconv.Itoa(i))
+		sb.WriteString("\n")
+		sb.WriteString(formatSRTTimestamp(cue.Start))
+		sb.WriteString(" --> ")
+		sb.WriteString(formatSRTTimestamp(cue.End))
+		sb.WriteString("\n")
+		sb.WriteString(alignmentTag(cue.Alignment))
+		writeMarkup(&sb, cue.Spans, func(s string) string { return s })
+		sb.WriteString("\n\n")
+		i++
+	}
+
+	return sb.String()
+}
+
+// parseSRTTimestamp parses "HH:MM:SS,mmm".
+// Some files use a dot instead of a comma.
+func parseSRTTimestamp(s string) (time.Duration, error) {
+	return parseTimestamp(strings.Replace(s, ",", ".", 1))
+}
+
+func formatSRTTimestamp(d time.Duration) string {
+	h, m, s, ms := splitDuration(d)
+	return fmt.Sprintf("%02d:%02d:%02d,%03d", h, m, s, ms)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// parseTimingLine parses a "start --> end [settings]" line.
+func parseTimingLine(line string, parse func(string) (time.Duration, error)) (start time.Duration, end time.Duration, settings string, err error) {
+	parts := strings.SplitN(line, "-->", 2)
+	if len(parts) != 2 {
+		return 0, 0, "", fmt.Errorf("invalid timing line %q", line)
+	}
+
+	start, err = parse(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, "", err
+	}
+
+	endAndSettings := strings.Fields(parts[1])
+	if len(endAndSettings) == 0 {
+		return 0, 0, "", fmt.Errorf("invalid timing line %q", line)
+	}
+
+	end, err = parse(endAndSettings[0])
+	if err != nil {
+		return 0, 0, "", err
+	}
+
+	return start, end, strings.Join(endAndSettings[1:], " "), nil
+}
+
+// parseTimestamp parses "[HH:]MM:SS.fff", the fraction can have any number of digits.
+func parseTimestamp(s string) (time.Duration, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+
+	var hours, minutes int
+	var err error
+	if len(parts) == 3 {
+		if hours, err = strconv.Atoi(parts[0]); err != nil {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		parts = parts[1:]
+	}
+	if minutes, err = strconv.Atoi(parts[0]); err != nil {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+	seconds, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+
+	return time.Duration(hours)*time.Hour +
+		time.Duration(minutes)*time.Minute +
+		time.Duration(seconds*float64(time.Second)+0.5), nil
+}
+
+func splitDuration(d time.Duration) (h, m, s, ms int64) {
+	if d < 0 {
+		d = 0
+	}
+	total := d.Milliseconds()
+	h = total / 3_600_000
+	m = (total / 60_000) % 60
+	s = (total / 1000) % 60
+	ms = total % 1000
+	return
+}
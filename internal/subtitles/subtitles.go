@@ -0,0 +1,286 @@
+package subtitles
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Format is a subtitle format.
+type Format string
+
+const (
+	FormatASS    Format = "ass"
+	FormatSRT    Format = "srt"
+	FormatWebVTT Format = "vtt"
+	// FormatPGS is a bitmap format, it can only be passed through or burned into the video.
+	FormatPGS     Format = "pgs"
+	FormatUnknown Format = ""
+)
+
+var (
+	ErrUnknownFormat = errors.New("subtitles: unknown subtitle format")
+	ErrBitmapFormat  = errors.New("subtitles: bitmap subtitles cannot be converted to text")
+)
+
+// Track is a list of cues, independent of the subtitle format.
+type Track struct {
+	Cues []*Cue
+}
+
+// Cue is a single subtitle entry.
+type Cue struct {
+	Start time.Duration
+	End   time.Duration
+	// Spans of styled text, lines are separated by "\n"
+	Spans []Span
+	// Alignment using the ASS numpad layout (1 = bottom left, 5 = middle center, 9 = top right).
+	// 0 means the default position (bottom center).
+	Alignment int
+	// Position of the anchor point as a percentage of the video size, nil if the cue is not explicitly positioned.
+	Position *Position
+}
+
+// Span is a run of text sharing the same basic styling.
+type Span struct {
+	Text      string
+	Bold      bool
+	Italic    bool
+	Underline bool
+}
+
+type Position struct {
+	X float64 // 0-100
+	Y float64 // 0-100
+}
+
+// FormatFromExtension returns the format for a file extension (e.g. ".ass", "srt") or a codec name (e.g. "subrip", "hdmv_pgs_subtitle").
+func FormatFromExtension(ext string) Format {
+	switch strings.ToLower(strings.TrimPrefix(ext, ".")) {
+	case "ass", "ssa", "s_text/ass", "s_text/ssa":
+		return FormatASS
+	case "srt", "subrip", "s_text/utf8":
+		return FormatSRT
+	case "vtt", "webvtt", "s_text/webvtt":
+		return FormatWebVTT
+	case "sup", "pgs", "hdmv_pgs_subtitle", "s_hdmv/pgs":
+		return FormatPGS
+	}
+	return FormatUnknown
+}
+
+// Detect guesses the format of text subtitles from their content.
+func Detect(content string) Format {
+	trimmed := strings.TrimSpace(strings.TrimPrefix(content, "\ufeff"))
+	switch {
+	case strings.HasPrefix(trimmed, "[Script Info]"):
+		return FormatASS
+	case strings.HasPrefix(trimmed, "WEBVTT"):
+		return FormatWebVTT
+	case strings.Contains(trimmed, "-->"):
+		return FormatSRT
+	}
+	return FormatUnknown
+}
+
+// Parse parses subtitles of the given format.
+// If the format is FormatUnknown, it is detected from the content.
+func Parse(content string, format Format) (*Track, error) {
+	content = strings.TrimPrefix(content, "\ufeff")
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	if format == FormatUnknown {
+		format = Detect(content)
+	}
+
+	var track *Track
+	var err error
+	switch format {
+	case FormatASS:
+		track, err = parseASS(content)
+	case FormatSRT:
+		track, err = parseSRT(content)
+	case FormatWebVTT:
+		track, err = parseWebVTT(content)
+	case FormatPGS:
+		return nil, ErrBitmapFormat
+	default:
+		return nil, ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, fmt.Errorf("subtitles: failed to parse %s: %w", format, err)
+	}
+
+	return track, nil
+}
+
+// Write returns the track in the given format.
+func (t *Track) Write(format Format) (string, error) {
+	// Sort cues by start time since ASS events are not necessarily ordered
+	cues := slices.Clone(t.Cues)
+	slices.SortStableFunc(cues, func(a, b *Cue) int {
+		return cmp.Compare(a.Start, b.Start)
+	})
+
+	switch format {
+	case FormatASS:
+		return writeASS(cues), nil
+	case FormatSRT:
+		return writeSRT(cues), nil
+	case FormatWebVTT:
+		return writeWebVTT(cues), nil
+	case FormatPGS:
+		return "", ErrBitmapFormat
+	}
+	return "", ErrUnknownFormat
+}
+
+// Convert converts subtitles from one format to another.
+// If both formats are the same, the content is returned as is.
+func Convert(content string, from Format, to Format) (string, error) {
+	if from == FormatUnknown {
+		from = Detect(content)
+	}
+	if from == to && from != FormatUnknown {
+		return content, nil
+	}
+
+	track, err := Parse(content, from)
+	if err != nil {
+		return "", err
+	}
+
+	return track.Write(to)
+}
+
+// PlainText returns the text of the cue without styling.
+func (c *Cue) PlainText() string {
+	var sb strings.Builder
+	for _, span := range c.Spans {
+		sb.WriteString(span.Text)
+	}
+	return sb.String()
+}
+
+// appendSpan adds text to the cue, merging it with the last span if the style is the same.
+func (c *Cue) appendSpan(span Span) {
+	if span.Text == "" {
+		return
+	}
+	if len(c.Spans) > 0 {
+		last := &c.Spans[len(c.Spans)-1]
+		if last.Bold == span.Bold && last.Italic == span.Italic && last.Underline == span.Underline {
+			last.Text += span.Text
+			return
+		}
+	}
+	c.Spans = append(c.Spans, span)
+}
+
+// trimSpans removes leading and trailing whitespace from the cue's text.
+func (c *Cue) trimSpans() {
+	for len(c.Spans) > 0 {
+		c.Spans[0].Text = strings.TrimLeft(c.Spans[0].Text, " \n\t")
+		if c.Spans[0].Text != "" {
+			break
+		}
+		c.Spans = c.Spans[1:]
+	}
+	for len(c.Spans) > 0 {
+		last := &c.Spans[len(c.Spans)-1]
+		last.Text = strings.TrimRight(last.Text, " \n\t")
+		if last.Text != "" {
+			break
+		}
+		c.Spans = c.Spans[:len(c.Spans)-1]
+	}
+}
+
+// writeMarkup writes the spans using HTML-like tags (<b>, <i>, <u>), which are supported by both SRT and WebVTT.
+func writeMarkup(sb *strings.Builder, spans []Span, escape func(string) string) {
+	for _, span := range spans {
+		if span.Bold {
+			sb.WriteString("<b>")
+		}
+		if span.Italic {
+			sb.WriteString("<i>")
+		}
+		if span.Underline {
+			sb.WriteString("<u>")
+		}
+		sb.WriteString(escape(span.Text))
+		if span.Underline {
+			sb.WriteString("</u>")
+		}
+		if span.Italic {
+			sb.WriteString("</i>")
+		}
+		if span.Bold {
+			sb.WriteString("</b>")
+		}
+	}
+}
+
+// parseMarkup parses text containing HTML-like tags (<b>, <i>, <u>) into spans.
+// Unknown tags are removed.
+func parseMarkup(text string, unescape func(string) string) []Span {
+	cue := &Cue{}
+	var bold, italic, underline int
+
+	for len(text) > 0 {
+		start := strings.IndexByte(text, '<')
+		if start == -1 {
+			start = len(text)
+		}
+		if start > 0 {
+			cue.appendSpan(Span{Text: unescape(text[:start]), Bold: bold > 0, Italic: italic > 0, Underline: underline > 0})
+		}
+		if start == len(text) {
+			break
+		}
+
+		end := strings.IndexByte(text[start:], '>')
+		if end == -1 {
+			// Not a tag
+			cue.appendSpan(Span{Text: unescape(text[start:]), Bold: bold > 0, Italic: italic > 0, Underline: underline > 0})
+			break
+		}
+
+		tag := strings.ToLower(strings.TrimSpace(text[start+1 : start+end]))
+		text = text[start+end+1:]
+
+		closing := strings.HasPrefix(tag, "/")
+		tag = strings.TrimPrefix(tag, "/")
+		// e.g. "c.yellow", "v Speaker", "font color=..."
+		if i := strings.IndexAny(tag, ". \t"); i != -1 {
+			tag = tag[:i]
+		}
+
+		delta := 1
+		if closing {
+			delta = -1
+		}
+		switch tag {
+		case "b":
+			bold = max(bold+delta, 0)
+		case "i":
+			italic = max(italic+delta, 0)
+		case "u":
+			underline = max(underline+delta, 0)
+		}
+	}
+
+	cue.trimSpans()
+	return cue.Spans
+}
+
+// alignmentTag returns the {\anN} override tag for the cue's alignment, or an empty string for the default alignment.
+func alignmentTag(alignment int) string {
+	if alignment == 0 || alignment == 2 {
+		return ""
+	}
+	return fmt.Sprintf("{\\an%d}", alignment)
+}
@@ -0,0 +1,145 @@
+package subtitles
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testASS = `[Script Info]
+ScriptType: v4.00+
+PlayResX: 1920
+PlayResY: 1080
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Default,Arial,60,&H00FFFFFF,&H000000FF,&H00000000,&H00000000,0,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+Style: Thoughts,Arial,60,&H00FFFFFF,&H000000FF,&H00000000,&H00000000,0,-1,0,0,100,100,0,0,1,2,0,8,10,10,10,1
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+Dialogue: 0,0:00:01.50,0:00:04.00,Default,,0,0,0,,Hello, {\i1}world{\i0}!\NSecond line
+Dialogue: 0,0:00:05.00,0:00:06.00,Thoughts,,0,0,0,,I wonder...
+Dialogue: 0,0:00:07.00,0:00:08.00,Default,,0,0,0,,{\pos(960,540)\fad(200,200)}Sign & <text>
+Dialogue: 0,0:00:02.00,0:00:03.00,Default,,0,0,0,,{\an7\b1}Top left
+Dialogue: 0,0:00:09.00,0:00:10.00,Default,,0,0,0,,{\p1}m 0 0 l 100 0 100 100 0 100{\p0}
+Comment: 0,0:00:11.00,0:00:12.00,Default,,0,0,0,,Ignored
+`
+
+func TestParseASS(t *testing.T) {
+	track, err := Parse(testASS, FormatASS)
+	require.NoError(t, err)
+	require.Len(t, track.Cues, 4)
+
+	cue := track.Cues[0]
+	assert.Equal(t, 1500*time.Millisecond, cue.Start)
+	assert.Equal(t, 4*time.Second, cue.End)
+	assert.Equal(t, []Span{{Text: "Hello, "}, {Text: "world", Italic: true}, {Text: "!\nSecond line"}}, cue.Spans)
+	assert.Equal(t, 2, cue.Alignment)
+
+	// Style alignment and italic
+	cue = track.Cues[1]
+	assert.Equal(t, 8, cue.Alignment)
+	assert.Equal(t, []Span{{Text: "I wonder...", Italic: true}}, cue.Spans)
+
+	// Position is converted to a percentage of the script resolution
+	cue = track.Cues[2]
+	require.NotNil(t, cue.Position)
+	assert.Equal(t, Position{X: 50, Y: 50}, *cue.Position)
+	assert.Equal(t, "Sign & <text>", cue.PlainText())
+
+	cue = track.Cues[3]
+	assert.Equal(t, 7, cue.Alignment)
+	assert.Equal(t, []Span{{Text: "Top left", Bold: true}}, cue.Spans)
+}
+
+func TestConvertASSToWebVTT(t *testing.T) {
+	out, err := Convert(testASS, FormatASS, FormatWebVTT)
+	require.NoError(t, err)
+
+	expected := `WEBVTT
+
+00:00:01.500 --> 00:00:04.000
+Hello, <i>world</i>!
+Second line
+
+00:00:02.000 --> 00:00:03.000 line:5%,start position:5%,line-left align:left
+<b>Top left</b>
+
+00:00:05.000 --> 00:00:06.000 line:5%,start
+<i>I wonder...</i>
+
+00:00:07.000 --> 00:00:08.000 line:50%,end position:50%,center align:center
+Sign &amp; &lt;text&gt;
+
+`
+	assert.Equal(t, expected, out)
+}
+
+func TestConvertSRT(t *testing.T) {
+	srt := "1\r\n00:00:01,000 --> 00:00:02,500\r\n<i>Hello</i>\r\nworld\r\n\r\n2\r\n00:01:00,000 --> 00:01:01,000\r\n{\\an8}<font color=\"#ffffff\">Top</font>\r\n"
+
+	vtt, err := Convert(srt, FormatSRT, FormatWebVTT)
+	require.NoError(t, err)
+	assert.Equal(t, "WEBVTT\n\n00:00:01.000 --> 00:00:02.500\n<i>Hello</i>\nworld\n\n00:01:00.000 --> 00:01:01.000 line:5%,start\nTop\n\n", vtt)
+
+	ass, err := Convert(srt, FormatSRT, FormatASS)
+	require.NoError(t, err)
+	assert.Contains(t, ass, "Dialogue: 0,0:00:01.00,0:00:02.50,Default,,0,0,0,,{\\i1}Hello{\\i0}\\Nworld\n")
+	assert.Contains(t, ass, "Dialogue: 0,0:01:00.00,0:01:01.00,Default,,0,0,0,,{\\an8}Top\n")
+
+	// Round trip
+	back, err := Convert(ass, FormatASS, FormatSRT)
+	require.NoError(t, err)
+	assert.Equal(t, "1\n00:00:01,000 --> 00:00:02,500\n<i>Hello</i>\nworld\n\n2\n00:01:00,000 --> 00:01:01,000\n{\\an8}Top\n\n", back)
+}
+
+func TestParseWebVTT(t *testing.T) {
+	vtt := `WEBVTT
+Kind: captions
+
+NOTE this is a comment
+
+STYLE
+::cue { color: white; }
+
+intro
+00:01.000 --> 00:03.000 line:10% position:20% align:left
+<v Speaker>Tom &amp; Jerry</v>
+
+00:00:04.000 --> 00:00:05.000
+<c.yellow><b>Bold</b></c>
+`
+	track, err := Parse(vtt, FormatUnknown)
+	require.NoError(t, err)
+	require.Len(t, track.Cues, 2)
+
+	assert.Equal(t, time.Second, track.Cues[0].Start)
+	assert.Equal(t, "Tom & Jerry", track.Cues[0].PlainText())
+	assert.Equal(t, 7, track.Cues[0].Alignment)
+	require.NotNil(t, track.Cues[0].Position)
+	assert.Equal(t, Position{X: 20, Y: 10}, *track.Cues[0].Position)
+
+	assert.Equal(t, []Span{{Text: "Bold", Bold: true}}, track.Cues[1].Spans)
+}
+
+func TestDetect(t *testing.T) {
+	assert.Equal(t, FormatASS, Detect("\ufeff[Script Info]\nTitle: test"))
+	assert.Equal(t, FormatWebVTT, Detect("WEBVTT\n\n00:01.000 --> 00:02.000\nHi"))
+	assert.Equal(t, FormatSRT, Detect("1\n00:00:01,000 --> 00:00:02,000\nHi"))
+	assert.Equal(t, FormatUnknown, Detect("hello"))
+}
+
+func TestConvertBitmap(t *testing.T) {
+	_, err := Convert("", FormatPGS, FormatWebVTT)
+	assert.ErrorIs(t, err, ErrBitmapFormat)
+}
+
+func TestConvertSameFormat(t *testing.T) {
+	out, err := Convert(testASS, FormatASS, FormatASS)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out, "[Script Info]"))
+}
@@ -0,0 +1,191 @@
+package subtitles
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var webVTTEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+var webVTTUnescaper = strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">", "&nbsp;", " ", "&lrm;", "\u200e", "&rlm;", "\u200f")
+
+func parseWebVTT(content string) (*Track, error) {
+	track := &Track{}
+
+	blocks := strings.Split(strings.TrimSpace(content), "\n\n")
+	for _, block := range blocks {
+		lines := strings.Split(strings.TrimSpace(block), "\n")
+
+		// Skip the header, NOTE, STYLE and REGION blocks
+		if len(lines) == 0 || strings.HasPrefix(lines[0], "WEBVTT") || strings.HasPrefix(lines[0], "NOTE") ||
+			strings.HasPrefix(lines[0], "STYLE") || strings.HasPrefix(lines[0], "REGION") {
+			continue
+		}
+
+		// The cue identifier is optional
+		timingIdx := -1
+		for i, line := range lines {
+			if strings.Contains(line, "-->") {
+				timingIdx = i
+				break
+			}
+		}
+		if timingIdx == -1 {
+			continue
+		}
+
+		start, end, settings, err := parseTimingLine(lines[timingIdx], parseTimestamp)
+		if err != nil {
+			return nil, err
+		}
+
+		cue := &Cue{
+			Start: start,
+			End:   end,
+		}
+		applyWebVTTSettings(cue, settings)
+
+		cue.Spans = parseMarkup(strings.Join(lines[timingIdx+1:], "\n"), webVTTUnescaper.Replace)
+		if len(cue.Spans) == 0 {
+			continue
+		}
+
+		track.Cues = append(track.Cues, cue)
+	}
+
+	return track, nil
+}
+
+// applyWebVTTSettings converts the cue settings (e.g. "line:10% position:20% align:left") to an alignment and a position.
+func applyWebVTTSettings(cue *Cue, settings string) {
+	if settings == "" {
+		return
+	}
+
+	vertical := 0   // 0 = bottom, 1 = middle, 2 = top
+	horizontal := 1 // 0 = left, 1 = center, 2 = right
+	var line, position *float64
+
+	for _, setting := range strings.Fields(settings) {
+		key, value, ok := strings.Cut(setting, ":")
+		if !ok {
+			continue
+		}
+		// Ignore the anchor (e.g. "line:10%,start")
+		value, _, _ = strings.Cut(value, ",")
+
+		switch key {
+		case "line":
+			if percent, ok := parsePercent(value); ok {
+				line = &percent
+				switch {
+				case percent < 33:
+					vertical = 2
+				case percent < 66:
+					vertical = 1
+				}
+			} else if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+				// Positive line numbers are counted from the top
+				vertical = 2
+			}
+		case "position":
+			if percent, ok := parsePercent(value); ok {
+				position = &percent
+			}
+		case "align":
+			switch value {
+			case "start", "left":
+				horizontal = 0
+			case "end", "right":
+				horizontal = 2
+			}
+		}
+	}
+
+	cue.Alignment = vertical*3 + horizontal + 1
+	if line != nil && position != nil {
+		cue.Position = &Position{X: *position, Y: *line}
+	}
+}
+
+func writeWebVTT(cues []*Cue) string {
+	var sb strings.Builder
+
+	sb.WriteString("WEBVTT\n\n")
+
+	for _, cue := range cues {
+		if len(cue.Spans) == 0 {
+			continue
+		}
+		sb.WriteString(formatWebVTTTimestamp(cue.Start))
+		sb.WriteString(" --> ")
+		sb.WriteString(formatWebVTTTimestamp(cue.End))
+		if settings := webVTTSettings(cue); settings != "" {
+			sb.WriteString(" ")
+			sb.WriteString(settings)
+		}
+		sb.WriteString("\n")
+		writeMarkup(&sb, cue.Spans, webVTTEscaper.Replace)
+		sb.WriteString("\n\n")
+	}
+
+	return sb.String()
+}
+
+// webVTTSettings converts the alignment and the position of a cue to WebVTT cue settings.
+func webVTTSettings(cue *Cue) string {
+	alignment := cue.Alignment
+	if alignment < 1 || alignment > 9 {
+		alignment = 2
+	}
+	vertical := (alignment - 1) / 3   // 0 = bottom, 1 = middle, 2 = top
+	horizontal := (alignment - 1) % 3 // 0 = left, 1 = center, 2 = right
+
+	lineAnchor := []string{"end", "center", "start"}[vertical]
+	positionAnchor := []string{"line-left", "center", "line-right"}[horizontal]
+	align := []string{"left", "center", "right"}[horizontal]
+
+	if cue.Position != nil {
+		return fmt.Sprintf("line:%s,%s position:%s,%s align:%s",
+			formatPercent(cue.Position.Y), lineAnchor, formatPercent(cue.Position.X), positionAnchor, align)
+	}
+
+	var settings []string
+	switch vertical {
+	case 1:
+		settings = append(settings, "line:50%,center")
+	case 2:
+		settings = append(settings, "line:5%,start")
+	}
+	switch horizontal {
+	case 0:
+		settings = append(settings, "position:5%,line-left", "align:left")
+	case 2:
+		settings = append(settings, "position:95%,line-right", "align:right")
+	}
+
+	return strings.Join(settings, " ")
+}
+
+func formatWebVTTTimestamp(d time.Duration) string {
+	h, m, s, ms := splitDuration(d)
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", h, m, s, ms)
+}
+
+func parsePercent(s string) (float64, bool) {
+	if !strings.HasSuffix(s, "%") {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil {
+		return 0, false
+	}
+	return f, true
+}
+
+func formatPercent(f float64) string {
+	f = min(max(f, 0), 100)
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64) + "%"
+}
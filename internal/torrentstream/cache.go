@@ -0,0 +1,436 @@
+package torrentstream
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"seanime/internal/database/db_bridge"
+	"seanime/internal/database/models"
+	"seanime/internal/events"
+	"seanime/internal/library/anime"
+	"seanime/internal/library/scanner"
+	"seanime/internal/util"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/anacrolix/torrent"
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+// The streaming cache keeps the data of streamed torrents in the download directory for instant re-watch and seeding.
+// Entries are evicted by last watched time when the cache exceeds [models.TorrentstreamSettings.CacheMaxSize].
+
+var (
+	ErrCacheEntryIncomplete = errors.New("torrentstream: the file has not been completely downloaded")
+	ErrCacheEntryStreaming  = errors.New("torrentstream: the file is being streamed, stop the stream first")
+)
+
+type (
+	// CacheEntry is a streamed episode kept in the download directory.
+	CacheEntry struct {
+		*models.TorrentstreamCacheEntry
+		// Size of the torrent directory
+		DiskSize int64 `json:"diskSize"`
+	}
+
+	// cacheGroup is the data of a torrent in the download directory.
+	cacheGroup struct {
+		InfoHash      string
+		Size          int64
+		LastWatchedAt time.Time
+	}
+
+	ImportToLibraryOptions struct {
+		CacheEntryId uint
+		// Library directory in which a folder is created for the media
+		LibraryPath string
+	}
+)
+
+func (r *Repository) cacheEnabled() bool {
+	settings, ok := r.settings.Get()
+	return ok && settings.CacheMaxSize > 0
+}
+
+func (r *Repository) torrentDir(infoHash string) string {
+	return filepath.Join(r.GetDownloadDir(), infoHash)
+}
+
+func (r *Repository) cacheEntryPath(entry *models.TorrentstreamCacheEntry) string {
+	return filepath.Join(r.torrentDir(entry.InfoHash), filepath.FromSlash(entry.FilePath))
+}
+
+// recordCacheEntry adds the streamed file to the cache.
+func (r *Repository) recordCacheEntry(mediaId int, episodeNumber int, t *torrent.Torrent, f *torrent.File) {
+	if !r.cacheEnabled() {
+		return
+	}
+
+	var buf bytes.Buffer
+	mi := t.Metainfo()
+	if err := mi.Write(&buf); err != nil {
+		r.logger.Warn().Err(err).Msg("torrentstream: Failed to encode metainfo for the cache")
+		return
+	}
+
+	_, err := r.db.UpsertTorrentstreamCacheEntry(&models.TorrentstreamCacheEntry{
+		InfoHash:      t.InfoHash().HexString(),
+		MediaId:       mediaId,
+		EpisodeNumber: episodeNumber,
+		FilePath:      f.Path(),
+		Size:          f.Length(),
+		Complete:      f.BytesCompleted() == f.Length(),
+		LastWatchedAt: time.Now(),
+		Metainfo:      buf.Bytes(),
+	})
+	if err != nil {
+		r.logger.Warn().Err(err).Msg("torrentstream: Failed to save cache entry")
+	}
+}
+
+// markCacheEntryComplete is called when the streamed file has been completely downloaded.
+func (r *Repository) markCacheEntryComplete(t *torrent.Torrent, f *torrent.File) {
+	if !r.cacheEnabled() {
+		return
+	}
+	_ = r.db.SetTorrentstreamCacheEntryComplete(t.InfoHash().HexString(), f.Path())
+}
+
+// findCachedTorrent returns the cached file of an episode, if it has been completely downloaded.
+// The torrent is added from the stored metainfo so no search is needed.
+func (r *Repository) findCachedTorrent(mediaId int, episodeNumber int) (*playbackTorrent, bool) {
+	if !r.cacheEnabled() {
+		return nil, false
+	}
+
+	entry, err := r.db.GetCompleteTorrentstreamCacheEntry(mediaId, episodeNumber)
+	if err != nil {
+		return nil, false
+	}
+
+	if info, err := os.Stat(r.cacheEntryPath(entry)); err != nil || info.Size() != entry.Size {
+		r.logger.Debug().Str("infoHash", entry.InfoHash).Msg("torrentstream: Cached file is missing, removing cache entry")
+		_ = r.db.DeleteTorrentstreamCacheEntry(entry.ID)
+		return nil, false
+	}
+
+	mi, err := metainfo.Load(bytes.NewReader(entry.Metainfo))
+	if err != nil {
+		return nil, false
+	}
+
+	t, err := r.client.addTorrentFromMetainfo(mi)
+	if err != nil {
+		r.logger.Warn().Err(err).Msg("torrentstream: Failed to add cached torrent")
+		return nil, false
+	}
+
+	var ret *playbackTorrent
+	for _, f := range t.Files() {
+		if f.Path() == entry.FilePath {
+			ret = &playbackTorrent{Torrent: t, File: f}
+			continue
+		}
+		if !r.client.isFileStreaming(f) {
+			f.SetPriority(torrent.PiecePriorityNone)
+		}
+	}
+	if ret == nil {
+		return nil, false
+	}
+
+	ret.File.Download()
+	r.setPriorityDownloadStrategy(t, ret.File)
+	r.logger.Info().Str("file", ret.File.DisplayPath()).Msg("torrentstream: Streaming episode from the cache")
+
+	return ret, true
+}
+
+// isCachedTorrentDir returns true if the directory of the download directory belongs to the cache.
+func (r *Repository) isCachedTorrentDir(name string, entries []*models.TorrentstreamCacheEntry) bool {
+	return slices.ContainsFunc(entries, func(e *models.TorrentstreamCacheEntry) bool {
+		return e.InfoHash == name
+	})
+}
+
+// GetCacheEntries returns the cached files, most recently watched first.
+func (r *Repository) GetCacheEntries() ([]*CacheEntry, error) {
+	entries, err := r.db.GetTorrentstreamCacheEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	sizes := make(map[string]int64)
+	ret := make([]*CacheEntry, 0, len(entries))
+	for _, entry := range entries {
+		if _, found := sizes[entry.InfoHash]; !found {
+			sizes[entry.InfoHash] = dirSize(r.torrentDir(entry.InfoHash))
+		}
+		ret = append(ret, &CacheEntry{TorrentstreamCacheEntry: entry, DiskSize: sizes[entry.InfoHash]})
+	}
+	return ret, nil
+}
+
+// DeleteCacheEntry removes a torrent from the cache and deletes its data.
+func (r *Repository) DeleteCacheEntry(id uint) error {
+	entry, err := r.db.GetTorrentstreamCacheEntry(id)
+	if err != nil {
+		return err
+	}
+	if r.isStreamingTorrent(entry.InfoHash) {
+		return ErrCacheEntryStreaming
+	}
+	r.removeCachedTorrent(entry.InfoHash)
+	return nil
+}
+
+// ClearCache removes all the torrents that are not being streamed from the cache.
+func (r *Repository) ClearCache() error {
+	entries, err := r.db.GetTorrentstreamCacheEntries()
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !r.isStreamingTorrent(entry.InfoHash) {
+			r.removeCachedTorrent(entry.InfoHash)
+		}
+	}
+	return nil
+}
+
+// evictCache removes the least recently watched torrents until the cache fits in the size limit.
+func (r *Repository) evictCache() {
+	defer util.HandlePanicInModuleThen("torrentstream/evictCache", func() {})
+
+	if !r.cacheEnabled() {
+		return
+	}
+
+	entries, err := r.db.GetTorrentstreamCacheEntries()
+	if err != nil {
+		return
+	}
+
+	groups := make(map[string]*cacheGroup)
+	for _, entry := range entries {
+		group, found := groups[entry.InfoHash]
+		if !found {
+			group = &cacheGroup{InfoHash: entry.InfoHash, Size: dirSize(r.torrentDir(entry.InfoHash))}
+			groups[entry.InfoHash] = group
+		}
+		if entry.LastWatchedAt.After(group.LastWatchedAt) {
+			group.LastWatchedAt = entry.LastWatchedAt
+		}
+	}
+
+	streaming := make([]string, 0)
+	for _, cs := range r.client.sessions.Values() {
+		streaming = append(streaming, cs.torrent.InfoHash().HexString())
+	}
+
+	maxSize := int64(r.settings.MustGet().CacheMaxSize) << 30
+	for _, infoHash := range selectEvictions(slices.Collect(maps.Values(groups)), maxSize, streaming) {
+		r.logger.Debug().Str("infoHash", infoHash).Msg("torrentstream: Evicting torrent from the cache")
+		r.removeCachedTorrent(infoHash)
+	}
+}
+
+// selectEvictions returns the torrents to remove, least recently watched first, so that the total size fits in maxSize.
+// The torrents being streamed are never evicted.
+func selectEvictions(groups []*cacheGroup, maxSize int64, keep []string) []string {
+	var total int64
+	for _, g := range groups {
+		total += g.Size
+	}
+
+	sorted := slices.SortedFunc(slices.Values(groups), func(a, b *cacheGroup) int {
+		return a.LastWatchedAt.Compare(b.LastWatchedAt)
+	})
+
+	ret := make([]string, 0)
+	for _, g := range sorted {
+		if total <= maxSize {
+			break
+		}
+		if slices.Contains(keep, g.InfoHash) {
+			continue
+		}
+		ret = append(ret, g.InfoHash)
+		total -= g.Size
+	}
+	return ret
+}
+
+func (r *Repository) removeCachedTorrent(infoHash string) {
+	if r.client.torrentClient.IsPresent() {
+		for _, t := range r.client.torrentClient.MustGet().Torrents() {
+			if t.InfoHash().HexString() == infoHash {
+				t.Drop()
+			}
+		}
+	}
+	_ = os.RemoveAll(r.torrentDir(infoHash))
+	_ = r.db.DeleteTorrentstreamCacheEntriesByInfoHash(infoHash)
+}
+
+func (r *Repository) isStreamingTorrent(infoHash string) bool {
+	return slices.ContainsFunc(r.client.sessions.Values(), func(cs *session) bool {
+		return cs.torrent.InfoHash().HexString() == infoHash
+	})
+}
+
+// isStreamingFile returns true if a client is streaming the file of the torrent.
+func (r *Repository) isStreamingFile(infoHash string, filePath string) bool {
+	return slices.ContainsFunc(r.client.sessions.Values(), func(cs *session) bool {
+		return cs.torrent.InfoHash().HexString() == infoHash && cs.file.Path() == filePath
+	})
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// ImportToLibrary moves a completely downloaded file of the cache into the library and adds it to the local files.
+// The moved file is scanned and matched to the media it was streamed for, the rest of the library is not scanned.
+func (r *Repository) ImportToLibrary(ctx context.Context, opts *ImportToLibraryOptions) (*anime.LocalFile, error) {
+	if opts.LibraryPath == "" {
+		return nil, errors.New("torrentstream: no library path")
+	}
+
+	entry, err := r.db.GetTorrentstreamCacheEntry(opts.CacheEntryId)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.isStreamingFile(entry.InfoHash, entry.FilePath) {
+		return nil, ErrCacheEntryStreaming
+	}
+	if !entry.Complete {
+		return nil, ErrCacheEntryIncomplete
+	}
+
+	media, _, err := r.GetMediaInfo(ctx, entry.MediaId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Stop seeding so the file can be moved
+	if r.client.torrentClient.IsPresent() && !r.isStreamingTorrent(entry.InfoHash) {
+		for _, t := range r.client.torrentClient.MustGet().Torrents() {
+			if t.InfoHash().HexString() == entry.InfoHash {
+				t.Drop()
+			}
+		}
+	}
+
+	src := r.cacheEntryPath(entry)
+	dest := filepath.Join(opts.LibraryPath, mediaFolderName(media.GetPreferredTitle()), filepath.Base(src))
+	if _, err := os.Stat(dest); err == nil {
+		return nil, fmt.Errorf("torrentstream: %s already exists", dest)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+		return nil, err
+	}
+	if err := moveFile(src, dest); err != nil {
+		return nil, err
+	}
+	r.logger.Info().Str("dest", dest).Msg("torrentstream: Imported cached file into the library")
+
+	// Scan the moved file only, the metadata of the stream is used if the scan fails
+	lf, err := scanner.ScanFile(&scanner.ScanFileOptions{
+		Path:             dest,
+		LibraryPaths:     []string{opts.LibraryPath},
+		Media:            media,
+		Platform:         r.platform,
+		MetadataProvider: r.metadataProvider,
+		Logger:           r.logger,
+	})
+	if err != nil || lf.Metadata == nil {
+		r.logger.Warn().Err(err).Str("dest", dest).Msg("torrentstream: Could not scan the imported file, using the metadata of the stream")
+		lf = anime.NewLocalFile(dest, opts.LibraryPath)
+		lf.MediaId = entry.MediaId
+		lf.Metadata = &anime.LocalFileMetadata{
+			Episode:      entry.EpisodeNumber,
+			AniDBEpisode: fmt.Sprintf("%d", entry.EpisodeNumber),
+			Type:         anime.LocalFileTypeMain,
+		}
+	}
+
+	lfs, lfsId, err := db_bridge.GetLocalFiles(r.db)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db_bridge.SaveLocalFiles(r.db, lfsId, append(lfs, lf)); err != nil {
+		return nil, err
+	}
+
+	_ = r.db.DeleteTorrentstreamCacheEntry(entry.ID)
+	if remaining, err := r.db.GetTorrentstreamCacheEntries(); err == nil && !r.isCachedTorrentDir(entry.InfoHash, remaining) && !r.isStreamingTorrent(entry.InfoHash) {
+		_ = os.RemoveAll(r.torrentDir(entry.InfoHash))
+	}
+
+	r.wsEventManager.SendEvent(events.InvalidateQueries, []string{events.GetLocalFilesEndpoint, events.GetAnimeEntryEndpoint, events.GetLibraryCollectionEndpoint, events.GetMissingEpisodesEndpoint})
+
+	return lf, nil
+}
+
+// mediaFolderName returns a folder name for the media that is valid on all platforms.
+func mediaFolderName(title string) string {
+	ret := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`<>:"/\|?*`, r) || r < 32 {
+			return ' '
+		}
+		return r
+	}, title)
+	ret = strings.TrimRight(strings.Join(strings.Fields(ret), " "), ".")
+	return cmp.Or(ret, "Unknown")
+}
+
+// moveFile renames the file, or copies it if the destination is on another device.
+func moveFile(src, dest string) error {
+	if err := os.Rename(src, dest); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		_ = os.Remove(dest)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		_ = os.Remove(dest)
+		return err
+	}
+	_ = in.Close()
+	return os.Remove(src)
+}
+
+func dirSize(dir string) int64 {
+	var ret int64
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			ret += info.Size()
+		}
+		return nil
+	})
+	return ret
+}
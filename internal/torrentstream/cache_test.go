@@ -0,0 +1,32 @@
+package torrentstream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectEvictions(t *testing.T) {
+	now := time.Now()
+	groups := []*cacheGroup{
+		{InfoHash: "a", Size: 4 << 30, LastWatchedAt: now.Add(-3 * time.Hour)},
+		{InfoHash: "b", Size: 2 << 30, LastWatchedAt: now.Add(-1 * time.Hour)},
+		{InfoHash: "c", Size: 1 << 30, LastWatchedAt: now.Add(-2 * time.Hour)},
+		{InfoHash: "d", Size: 1 << 30, LastWatchedAt: now},
+	}
+
+	// Under the limit
+	assert.Empty(t, selectEvictions(groups, 10<<30, nil))
+	// Least recently watched first
+	assert.Equal(t, []string{"a"}, selectEvictions(groups, 5<<30, nil))
+	assert.Equal(t, []string{"a", "c"}, selectEvictions(groups, 3<<30, nil))
+	// The torrents being streamed are kept even if they do not fit
+	assert.Equal(t, []string{"c", "b", "d"}, selectEvictions(groups, 1<<30, []string{"a"}))
+}
+
+func TestMediaFolderName(t *testing.T) {
+	assert.Equal(t, "Re Zero", mediaFolderName("Re: Zero"))
+	assert.Equal(t, "Fate Zero", mediaFolderName("Fate/Zero..."))
+	assert.Equal(t, "Unknown", mediaFolderName("???"))
+}
@@ -9,14 +9,19 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"seanime/internal/database/models"
 	"seanime/internal/mediaplayers/mediaplayer"
 	"seanime/internal/util"
+	"seanime/internal/util/result"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	alog "github.com/anacrolix/log"
 	"github.com/anacrolix/torrent"
+	"github.com/anacrolix/torrent/metainfo"
 	"github.com/anacrolix/torrent/storage"
 	"github.com/samber/mo"
 	"golang.org/x/time/rate"
@@ -26,19 +31,19 @@ type (
 	Client struct {
 		repository *Repository
 
-		torrentClient        mo.Option[*torrent.Client]
-		currentTorrent       mo.Option[*torrent.Torrent]
-		currentFile          mo.Option[*torrent.File]
-		currentTorrentStatus TorrentStatus
-		cancelFunc           context.CancelFunc
+		torrentClient mo.Option[*torrent.Client]
+		// Streams of each client, see [session]
+		sessions *result.Map[string, *session]
+		// Pieces wanted by the prefetchers of the sessions, see [piecePriorities]
+		piecePriorities *piecePriorities
+		cancelFunc      context.CancelFunc
+		// Session of the last stream sent to the desktop media player
+		mediaPlayerSession atomic.Pointer[session]
 
 		mu                          sync.Mutex
 		stopCh                      chan struct{}                    // Closed when the media player stops
 		mediaPlayerPlaybackStatusCh chan *mediaplayer.PlaybackStatus // Continuously receives playback status
 		timeSinceLoggedSeeding      time.Time
-		lastSpeedCheck              time.Time // Track the last time we checked speeds
-		lastBytesCompleted          int64     // Track the last bytes completed
-		lastBytesWrittenData        int64     // Track the last bytes written data
 	}
 
 	TorrentStatus struct {
@@ -60,8 +65,8 @@ func NewClient(repository *Repository) *Client {
 	ret := &Client{
 		repository:                  repository,
 		torrentClient:               mo.None[*torrent.Client](),
-		currentFile:                 mo.None[*torrent.File](),
-		currentTorrent:              mo.None[*torrent.Torrent](),
+		sessions:                    result.NewResultMap[string, *session](),
+		piecePriorities:             newPiecePriorities(),
 		stopCh:                      make(chan struct{}),
 		mediaPlayerPlaybackStatusCh: make(chan *mediaplayer.PlaybackStatus, 1),
 	}
@@ -70,7 +75,7 @@ func NewClient(repository *Repository) *Client {
 }
 
 // initializeClient will create and torrent client.
-// The client is designed to support only one torrent at a time, and seed it.
+// The client streams one torrent per client (see [session]) and seeds them.
 // Upon initialization, the client will drop all torrents.
 func (c *Client) initializeClient() error {
 	// Fail if no settings
@@ -128,6 +133,8 @@ func (c *Client) initializeClient() error {
 	c.dropTorrents()
 	c.mu.Unlock()
 
+	go c.repository.evictCache()
+
 	go func(ctx context.Context) {
 
 		for {
@@ -138,74 +145,19 @@ func (c *Client) initializeClient() error {
 
 			case status := <-c.mediaPlayerPlaybackStatusCh:
 				// DEVNOTE: When this is received, "default" case is executed right after
-				if status != nil && c.currentFile.IsPresent() && c.repository.playback.currentVideoDuration == 0 {
-					// If the stored video duration is 0 but the media player status shows a duration that is not 0
-					// we know that the video has been loaded and is playing
-					if c.repository.playback.currentVideoDuration == 0 && status.Duration > 0 {
-						// The media player has started playing the video
-						c.repository.logger.Debug().Msg("torrentstream: Media player started playing the video, sending event")
-						c.repository.sendStateEvent(eventTorrentStartedPlaying)
-						// Update the stored video duration
-						c.repository.playback.currentVideoDuration = status.Duration
-					}
+				// If the stored video duration is 0 but the media player status shows a duration that is not 0
+				// we know that the video has been loaded and is playing
+				if cs, ok := c.getMediaPlayerSession(); ok && status != nil && cs.setVideoDuration(status.Duration) {
+					// The media player has started playing the video
+					c.repository.logger.Debug().Msg("torrentstream: Media player started playing the video, sending event")
+					c.repository.sendStateEventTo(cs.clientId, eventTorrentStartedPlaying)
 				}
 			default:
 				c.mu.Lock()
-				if c.torrentClient.IsPresent() && c.currentTorrent.IsPresent() && c.currentFile.IsPresent() {
-					t := c.currentTorrent.MustGet()
-					f := c.currentFile.MustGet()
-
-					// Get the current time
-					now := time.Now()
-					elapsed := now.Sub(c.lastSpeedCheck).Seconds()
-
-					// downloadProgress is the number of bytes downloaded
-					downloadProgress := t.BytesCompleted()
-
-					downloadSpeed := ""
-					if elapsed > 0 {
-						bytesPerSecond := float64(downloadProgress-c.lastBytesCompleted) / elapsed
-						if bytesPerSecond > 0 {
-							downloadSpeed = fmt.Sprintf("%s/s", util.Bytes(uint64(bytesPerSecond)))
-						}
-					}
-					size := util.Bytes(uint64(f.Length()))
-
-					bytesWrittenData := t.Stats().BytesWrittenData
-					uploadSpeed := ""
-					if elapsed > 0 {
-						bytesPerSecond := float64((&bytesWrittenData).Int64()-c.lastBytesWrittenData) / elapsed
-						if bytesPerSecond > 0 {
-							uploadSpeed = fmt.Sprintf("%s/s", util.Bytes(uint64(bytesPerSecond)))
-						}
-					}
-
-					// Update the stored values for next calculation
-					c.lastBytesCompleted = downloadProgress
-					c.lastBytesWrittenData = (&bytesWrittenData).Int64()
-					c.lastSpeedCheck = now
-
-					if t.PeerConns() != nil {
-						c.currentTorrentStatus.Seeders = len(t.PeerConns())
-					}
-
-					c.currentTorrentStatus = TorrentStatus{
-						Size:               size,
-						UploadProgress:     (&bytesWrittenData).Int64() - c.currentTorrentStatus.UploadProgress,
-						DownloadSpeed:      downloadSpeed,
-						UploadSpeed:        uploadSpeed,
-						DownloadProgress:   downloadProgress,
-						ProgressPercentage: c.getTorrentPercentage(c.currentTorrent, c.currentFile),
-						Seeders:            t.Stats().ConnectedSeeders,
+				if c.torrentClient.IsPresent() {
+					for _, cs := range c.sessions.Values() {
+						c.updateSessionStatus(cs)
 					}
-					c.repository.sendStateEvent(eventTorrentStatus, c.currentTorrentStatus)
-					// Always log the progress so the user knows what's happening
-					c.repository.logger.Trace().Msgf("torrentstream: Progress: %.2f%%, Download speed: %s, Upload speed: %s, Size: %s",
-						c.currentTorrentStatus.ProgressPercentage,
-						c.currentTorrentStatus.DownloadSpeed,
-						c.currentTorrentStatus.UploadSpeed,
-						c.currentTorrentStatus.Size)
-					c.timeSinceLoggedSeeding = time.Now()
 				}
 				c.mu.Unlock()
 				if c.torrentClient.IsPresent() {
@@ -226,11 +178,82 @@ func (c *Client) initializeClient() error {
 	return nil
 }
 
-func (c *Client) GetStreamingUrl() string {
+// updateSessionStatus sends the download status of the file streamed by the client.
+func (c *Client) updateSessionStatus(cs *session) {
+	t := cs.torrent
+	f := cs.file
+
+	// Get the current time
+	now := time.Now()
+	elapsed := now.Sub(cs.lastSpeedCheck).Seconds()
+
+	// downloadProgress is the number of bytes downloaded
+	downloadProgress := t.BytesCompleted()
+
+	downloadSpeed := ""
+	downloadBytesPerSecond := 0.0
+	if elapsed > 0 {
+		downloadBytesPerSecond = float64(downloadProgress-cs.lastBytesCompleted) / elapsed
+		if downloadBytesPerSecond > 0 {
+			downloadSpeed = fmt.Sprintf("%s/s", util.Bytes(uint64(downloadBytesPerSecond)))
+		}
+	}
+	size := util.Bytes(uint64(f.Length()))
+
+	bytesWrittenData := t.Stats().BytesWrittenData
+	uploadSpeed := ""
+	if elapsed > 0 {
+		bytesPerSecond := float64((&bytesWrittenData).Int64()-cs.lastBytesWrittenData) / elapsed
+		if bytesPerSecond > 0 {
+			uploadSpeed = fmt.Sprintf("%s/s", util.Bytes(uint64(bytesPerSecond)))
+		}
+	}
+
+	// Update the stored values for next calculation
+	cs.lastBytesCompleted = downloadProgress
+	cs.lastBytesWrittenData = (&bytesWrittenData).Int64()
+	cs.lastSpeedCheck = now
+
+	cs.mu.Lock()
+	cs.status = TorrentStatus{
+		Size:               size,
+		UploadProgress:     (&bytesWrittenData).Int64() - cs.status.UploadProgress,
+		DownloadSpeed:      downloadSpeed,
+		UploadSpeed:        uploadSpeed,
+		DownloadProgress:   downloadProgress,
+		ProgressPercentage: getFilePercentage(f),
+		Seeders:            t.Stats().ConnectedSeeders,
+	}
+	status := cs.status
+	cs.mu.Unlock()
+	c.repository.sendStateEventTo(cs.clientId, eventTorrentStatus, status)
+
+	if !cs.fileComplete && f.BytesCompleted() == f.Length() {
+		cs.fileComplete = true
+		c.repository.markCacheEntryComplete(t, f)
+	}
+
+	// Adapt the prefetch window to the download rate and tell the client if playback might stall
+	cs.prefetcher.setDownloadRate(max(downloadBytesPerSecond, 0))
+	if health, ok := cs.prefetcher.bufferHealth(); ok {
+		c.repository.sendStateEventTo(cs.clientId, eventBufferHealth, health)
+	}
+	// Always log the progress so the user knows what's happening
+	c.repository.logger.Trace().Str("clientId", cs.clientId).Msgf("torrentstream: Progress: %.2f%%, Download speed: %s, Upload speed: %s, Size: %s",
+		status.ProgressPercentage,
+		status.DownloadSpeed,
+		status.UploadSpeed,
+		status.Size)
+	c.timeSinceLoggedSeeding = time.Now()
+}
+
+// GetStreamingUrl returns the URL of the file streamed by the client.
+func (c *Client) GetStreamingUrl(clientId string) string {
 	if c.torrentClient.IsAbsent() {
 		return ""
 	}
-	if c.currentFile.IsAbsent() {
+	cs, ok := c.getSession(clientId)
+	if !ok {
 		return ""
 	}
 	settings, ok := c.repository.settings.Get()
@@ -246,23 +269,27 @@ func (c *Client) GetStreamingUrl() string {
 	if settings.StreamUrlAddress != "" {
 		address = settings.StreamUrlAddress
 	}
-	ret := fmt.Sprintf("http://%s/api/v1/torrentstream/stream/%s", address, url.PathEscape(c.currentFile.MustGet().DisplayPath()))
+	ret := fmt.Sprintf("http://%s/api/v1/torrentstream/stream/%s", address, url.PathEscape(cs.file.DisplayPath()))
 	if strings.HasPrefix(ret, "http://http") {
 		ret = strings.Replace(ret, "http://http", "http", 1)
 	}
 	return ret
 }
 
+// AddTorrent adds a torrent and drops the others, except the ones streamed by clients or pre-buffered.
 func (c *Client) AddTorrent(id string) (*torrent.Torrent, error) {
 	if c.torrentClient.IsAbsent() {
 		return nil, errors.New("torrent client is not initialized")
 	}
 
-	// Drop all torrents
-	for _, t := range c.torrentClient.MustGet().Torrents() {
-		t.Drop()
-	}
+	c.dropUnusedTorrents()
+
+	return c.addTorrent(id)
+}
 
+// addTorrent adds a torrent without dropping the others.
+// Used to pre-buffer the next episode while the current one is streaming.
+func (c *Client) addTorrent(id string) (*torrent.Torrent, error) {
 	if strings.HasPrefix(id, "magnet") {
 		return c.addTorrentMagnet(id)
 	}
@@ -356,6 +383,23 @@ func (c *Client) addTorrentFromDownloadURL(url string) (*torrent.Torrent, error)
 	return t, nil
 }
 
+// addTorrentFromMetainfo adds a torrent stored by the cache, its data is verified against the download directory.
+func (c *Client) addTorrentFromMetainfo(mi *metainfo.MetaInfo) (*torrent.Torrent, error) {
+	if c.torrentClient.IsAbsent() {
+		return nil, errors.New("torrent client is not initialized")
+	}
+
+	c.dropUnusedTorrents()
+
+	t, err := c.torrentClient.MustGet().AddTorrent(mi)
+	if err != nil {
+		return nil, err
+	}
+	<-t.GotInfo()
+	c.repository.logger.Info().Msgf("torrentstream: Added cached torrent: %s", t.InfoHash().AsString())
+	return t, nil
+}
+
 // Shutdown closes the torrent client and drops all torrents.
 // This SHOULD NOT be called if you don't intend to reinitialize the client.
 func (c *Client) Shutdown() (errs []error) {
@@ -363,8 +407,6 @@ func (c *Client) Shutdown() (errs []error) {
 		return
 	}
 	c.dropTorrents()
-	c.currentTorrent = mo.None[*torrent.Torrent]()
-	c.currentTorrentStatus = TorrentStatus{}
 	c.repository.logger.Debug().Msg("torrentstream: Closing torrent client")
 	return c.torrentClient.MustGet().Close()
 }
@@ -411,13 +453,19 @@ func (c *Client) dropTorrents() {
 	for _, t := range c.torrentClient.MustGet().Torrents() {
 		t.Drop()
 	}
+	c.sessions.Clear()
+	c.piecePriorities.clear()
 
 	if c.repository.settings.IsPresent() {
-		// Delete all torrents
+		// Delete all torrents, except the ones kept by the streaming cache
+		var cached []*models.TorrentstreamCacheEntry
+		if c.repository.cacheEnabled() {
+			cached, _ = c.repository.db.GetTorrentstreamCacheEntries()
+		}
 		fe, err := os.ReadDir(c.repository.settings.MustGet().DownloadDir)
 		if err == nil {
 			for _, f := range fe {
-				if f.IsDir() {
+				if f.IsDir() && !c.repository.isCachedTorrentDir(f.Name(), cached) {
 					_ = os.RemoveAll(path.Join(c.repository.settings.MustGet().DownloadDir, f.Name()))
 				}
 			}
@@ -427,30 +475,57 @@ func (c *Client) dropTorrents() {
 	c.repository.logger.Debug().Msg("torrentstream: Dropped all torrents")
 }
 
-//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// dropUnusedTorrents drops the torrents that are not streamed by a client nor pre-buffered.
+func (c *Client) dropUnusedTorrents(keep ...*torrent.Torrent) {
+	if c.torrentClient.IsAbsent() {
+		return
+	}
 
-// getTorrentPercentage returns the percentage of the current torrent file
-// If no torrent is selected, it returns -1
-func (c *Client) getTorrentPercentage(t mo.Option[*torrent.Torrent], f mo.Option[*torrent.File]) float64 {
-	if t.IsAbsent() || f.IsAbsent() {
-		return -1
+	for _, t := range c.torrentClient.MustGet().Torrents() {
+		if !slices.Contains(keep, t) && !c.isTorrentInUse(t) {
+			t.Drop()
+		}
 	}
+}
 
-	if f.MustGet().Length() == 0 {
-		return 0
+// dropTorrent drops a torrent and deletes its data, unless it is kept by the streaming cache.
+// The torrent is kept if it is streamed by another client, only the file is unselected.
+func (c *Client) dropTorrent(t *torrent.Torrent, f *torrent.File) {
+	if c.isTorrentInUse(t) {
+		if !c.isFileStreaming(f) {
+			f.SetPriority(torrent.PiecePriorityNone)
+		}
+		return
+	}
+
+	infoHash := t.InfoHash().HexString()
+	t.Drop()
+
+	if c.repository.cacheEnabled() {
+		if cached, err := c.repository.db.GetTorrentstreamCacheEntries(); err == nil && c.repository.isCachedTorrentDir(infoHash, cached) {
+			return
+		}
 	}
+	_ = os.RemoveAll(c.repository.torrentDir(infoHash))
 
-	return float64(f.MustGet().BytesCompleted()) / float64(f.MustGet().Length()) * 100
+	c.repository.logger.Debug().Str("infoHash", infoHash).Msg("torrentstream: Dropped torrent")
 }
 
-// readyToStream determines if enough of the file has been downloaded to begin streaming
-// Uses both absolute size (minimum buffer) and a percentage-based approach
-func (c *Client) readyToStream() bool {
-	if c.currentTorrent.IsAbsent() || c.currentFile.IsAbsent() {
-		return false
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// getFilePercentage returns the downloaded percentage of a torrent file
+func getFilePercentage(f *torrent.File) float64 {
+	if f.Length() == 0 {
+		return 0
 	}
 
-	file := c.currentFile.MustGet()
+	return float64(f.BytesCompleted()) / float64(f.Length()) * 100
+}
+
+// readyToStream determines if enough of the file of the session has been downloaded to begin streaming
+// Uses both absolute size (minimum buffer) and a percentage-based approach
+func (c *Client) readyToStream(cs *session) bool {
+	file := cs.file
 
 	// Always need at least 1MB to start playback (typical header size for many formats)
 	const minimumBufferBytes int64 = 1 * 1024 * 1024 // 1MB
@@ -9,6 +9,7 @@ const (
 	eventTorrentStartedPlaying = "started-playing"
 	eventTorrentStatus         = "status"
 	eventTorrentStopped        = "stopped"
+	eventBufferHealth          = "buffer-health"
 )
 
 type TorrentLoadingStatusState string
@@ -42,6 +43,28 @@ func (r *Repository) sendStateEvent(event string, data ...interface{}) {
 	})
 }
 
+// sendStateEventTo sends the state of the stream to the client streaming it.
+// The event is sent to all clients if the client is unknown.
+func (r *Repository) sendStateEventTo(clientId string, event string, data ...interface{}) {
+	if clientId == "" {
+		r.sendStateEvent(event, data...)
+		return
+	}
+
+	var dataToSend interface{}
+
+	if len(data) > 0 {
+		dataToSend = data[0]
+	}
+	r.wsEventManager.SendEventTo(clientId, events.TorrentStreamState, struct {
+		State string      `json:"state"`
+		Data  interface{} `json:"data"`
+	}{
+		State: event,
+		Data:  dataToSend,
+	})
+}
+
 //func (r *Repository) sendTorrentLoadingStatus(event TorrentLoadingStatusState, checking string) {
 //	r.wsEventManager.SendEvent(eventTorrentLoadingStatus, &TorrentLoadingStatus{
 //		TorrentBeingChecked: checking,
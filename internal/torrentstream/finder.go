@@ -32,13 +32,31 @@ type (
 
 // setPriorityDownloadStrategy sets piece priorities for optimal streaming experience
 // This helps to optimize initial buffering, seeking, and end-of-file playback
+// Once the player reports its position, the prefetcher adapts the priorities to the playback (see prefetch.go)
 func (r *Repository) setPriorityDownloadStrategy(t *torrent.Torrent, file *torrent.File) {
 	torrentutil.PrioritizeDownloadPieces(t, file, r.logger)
 }
 
-func (r *Repository) findBestTorrent(media *anilist.CompleteAnime, aniDbEpisode string, episodeNumber int) (ret *playbackTorrent, err error) {
+func (r *Repository) findBestTorrent(media *anilist.CompleteAnime, aniDbEpisode string, episodeNumber int, clientId string) (ret *playbackTorrent, err error) {
+	return r.findTorrent(media, aniDbEpisode, episodeNumber, clientId, false)
+}
+
+// findTorrent searches and selects the torrent of an episode for a client.
+// In background mode, the torrent is added without dropping the current one, no state is sent to the client
+// and no piece is prioritized, see [Repository.preBufferNextEpisode].
+func (r *Repository) findTorrent(media *anilist.CompleteAnime, aniDbEpisode string, episodeNumber int, clientId string, background bool) (ret *playbackTorrent, err error) {
 	defer util.HandlePanicInModuleWithError("torrentstream/findBestTorrent", &err)
 
+	sendStateEvent := func(event string, data ...interface{}) {
+		if !background {
+			r.sendStateEventTo(clientId, event, data...)
+		}
+	}
+	addTorrent := r.client.AddTorrent
+	if background {
+		addTorrent = r.client.addTorrent
+	}
+
 	r.logger.Debug().Msgf("torrentstream: Finding best torrent for %s, Episode %d", media.GetTitleSafe(), episodeNumber)
 
 	providerId := itorrent.ProviderAnimeTosho // todo: get provider from settings
@@ -61,7 +79,7 @@ func (r *Repository) findBestTorrent(media *anilist.CompleteAnime, aniDbEpisode
 		searchBatch = true
 	}
 
-	r.sendStateEvent(eventLoading, TLSStateSearchingTorrents)
+	sendStateEvent(eventLoading, TLSStateSearchingTorrents)
 
 	var data *itorrent.SearchData
 	var currentProvider string = providerId
@@ -175,7 +193,7 @@ searchLoop:
 		if tries >= 2 {
 			break
 		}
-		r.sendStateEvent(eventLoading, struct {
+		sendStateEvent(eventLoading, struct {
 			State              any    `json:"state"`
 			TorrentBeingLoaded string `json:"torrentBeingLoaded"`
 		}{
@@ -191,14 +209,14 @@ searchLoop:
 		}
 		r.logger.Debug().Msgf("torrentstream: Adding torrent %s from magnet", searchT.Link)
 
-		t, err := r.client.AddTorrent(magnet)
+		t, err := addTorrent(magnet)
 		if err != nil {
 			r.logger.Warn().Err(err).Msgf("torrentstream: Error adding torrent %s", searchT.Link)
 			tries++
 			continue
 		}
 
-		r.sendStateEvent(eventLoading, struct {
+		sendStateEvent(eventLoading, struct {
 			State              any    `json:"state"`
 			TorrentBeingLoaded string `json:"torrentBeingLoaded"`
 		}{
@@ -209,8 +227,10 @@ searchLoop:
 		// If the torrent has only one file, return it
 		if len(t.Files()) == 1 {
 			tFile := t.Files()[0]
-			tFile.Download()
-			r.setPriorityDownloadStrategy(t, tFile)
+			if !background {
+				tFile.Download()
+				r.setPriorityDownloadStrategy(t, tFile)
+			}
 			r.logger.Debug().Msgf("torrentstream: Found single file torrent: %s", tFile.DisplayPath())
 
 			return &playbackTorrent{
@@ -219,7 +239,7 @@ searchLoop:
 			}, nil
 		}
 
-		r.sendStateEvent(eventLoading, TLSStateSelectingFile)
+		sendStateEvent(eventLoading, TLSStateSelectingFile)
 
 		// DEVNOTE: The gap between adding the torrent and file analysis causes some pieces to be downloaded
 		// We currently can't Pause/Resume torrents so :shrug:
@@ -250,9 +270,11 @@ searchLoop:
 		if err != nil {
 			r.logger.Warn().Err(err).Msg("torrentstream: Error analyzing torrent files")
 			// Remove torrent on failure
-			go func() {
-				_ = r.client.RemoveTorrent(t.InfoHash().AsString())
-			}()
+			if !r.isStreamingTorrent(t.InfoHash().HexString()) {
+				go func() {
+					_ = r.client.RemoveTorrent(t.InfoHash().AsString())
+				}()
+			}
 			tries++
 			continue
 		}
@@ -262,24 +284,29 @@ searchLoop:
 		if !found {
 			r.logger.Error().Msgf("torrentstream: Failed to auto-select episode from torrent %s", searchT.Link)
 			// Remove torrent on failure
-			go func() {
-				_ = r.client.RemoveTorrent(t.InfoHash().AsString())
-			}()
+			if !r.isStreamingTorrent(t.InfoHash().HexString()) {
+				go func() {
+					_ = r.client.RemoveTorrent(t.InfoHash().AsString())
+				}()
+			}
 			tries++
 			continue
 		}
 
 		r.logger.Debug().Msgf("torrentstream: Found corresponding file for episode %s: %s", aniDbEpisode, analysisFile.GetLocalFile().Name)
 
+		tFile := t.Files()[analysisFile.GetIndex()]
+		r.logger.Debug().Msgf("torrentstream: Selecting file %s", tFile.DisplayPath())
 		// Download the file and unselect the rest
-		for i, f := range t.Files() {
-			if i != analysisFile.GetIndex() {
-				f.SetPriority(torrent.PiecePriorityNone)
+		// The torrent might be the one being streamed if the episode is pre-buffered
+		if !background {
+			for i, f := range t.Files() {
+				if i != analysisFile.GetIndex() && !r.client.isFileStreaming(f) {
+					f.SetPriority(torrent.PiecePriorityNone)
+				}
 			}
+			r.setPriorityDownloadStrategy(t, tFile)
 		}
-		tFile := t.Files()[analysisFile.GetIndex()]
-		r.logger.Debug().Msgf("torrentstream: Selecting file %s", tFile.DisplayPath())
-		r.setPriorityDownloadStrategy(t, tFile)
 
 		selectedTorrent = t
 		selectedFile = tFile
@@ -394,7 +421,7 @@ func (r *Repository) findBestTorrentFromManualSelection(t *hibiketorrent.AnimeTo
 
 	// Download the file and unselect the rest
 	for i, f := range selectedTorrent.Files() {
-		if i != fileIndex {
+		if i != fileIndex && !r.client.isFileStreaming(f) {
 			f.SetPriority(torrent.PiecePriorityNone)
 		}
 	}
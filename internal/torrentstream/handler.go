@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"seanime/internal/util/torrentutil"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/anacrolix/torrent"
@@ -27,16 +28,24 @@ func newHandler(repository *Repository) *handler {
 func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.repository.logger.Trace().Str("range", r.Header.Get("Range")).Msg("torrentstream: Stream endpoint hit")
 
-	if h.repository.client.currentFile.IsAbsent() || h.repository.client.currentTorrent.IsAbsent() {
+	// The stream URL ends with the path of the file, see [Client.GetStreamingUrl]
+	displayPath := ""
+	if _, after, found := strings.Cut(r.URL.Path, "/torrentstream/stream/"); found {
+		displayPath = after
+	}
+
+	cs, ok := h.repository.client.getSessionByFile(displayPath)
+	if !ok {
 		h.repository.logger.Error().Msg("torrentstream: No torrent to stream")
 		http.Error(w, "No torrent to stream", http.StatusNotFound)
 		return
 	}
+	file := cs.file
 
 	if r.Method == http.MethodHead {
 		r.Response.Header.Set("Content-Type", "video/mp4")
-		r.Response.Header.Set("Content-Length", strconv.Itoa(int(h.repository.client.currentFile.MustGet().Length())))
-		r.Response.Header.Set("Content-Disposition", "inline; filename="+h.repository.client.currentFile.MustGet().DisplayPath())
+		r.Response.Header.Set("Content-Length", strconv.Itoa(int(file.Length())))
+		r.Response.Header.Set("Content-Disposition", "inline; filename="+file.DisplayPath())
 		r.Response.Header.Set("Accept-Ranges", "bytes")
 		r.Response.Header.Set("Cache-Control", "no-cache")
 		r.Response.Header.Set("Pragma", "no-cache")
@@ -48,7 +57,6 @@ func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	file := h.repository.client.currentFile.MustGet()
 	h.repository.logger.Trace().Str("file", file.DisplayPath()).Msg("torrentstream: New reader")
 	tr := file.NewReader()
 	defer func(tr torrent.Reader) {
@@ -63,10 +71,9 @@ func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// If this is a range request for a later part of the file, prioritize those pieces
 	rangeHeader := r.Header.Get("Range")
-	if rangeHeader != "" && h.repository.client.currentTorrent.IsPresent() {
-		t := h.repository.client.currentTorrent.MustGet()
+	if rangeHeader != "" {
 		// Attempt to prioritize the pieces requested in the range
-		torrentutil.PrioritizeRangeRequestPieces(rangeHeader, t, file, h.repository.logger)
+		torrentutil.PrioritizeRangeRequestPieces(rangeHeader, cs.torrent, file, h.repository.logger)
 	}
 
 	h.repository.logger.Trace().Str("file", file.DisplayPath()).Msg("torrentstream: Serving file content")
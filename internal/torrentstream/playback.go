@@ -9,9 +9,6 @@ import (
 type (
 	playback struct {
 		mediaPlayerCtxCancelFunc context.CancelFunc
-		// Stores the video duration returned by the media player
-		// When this is greater than 0, the video is considered to be playing
-		currentVideoDuration int
 	}
 )
 
@@ -34,12 +31,15 @@ func (r *Repository) listenToMediaPlayerEvents() {
 			case event := <-r.mediaPlayerRepositorySubscriber.EventCh:
 				switch e := event.(type) {
 				case mediaplayer.StreamingTrackingStartedEvent:
-					// Reset the current video duration, as the video has stopped
+					// Reset the video duration, as the video has stopped
 					// DEVNOTE: This is changed in client.go as well when the duration is updated over 0
-					r.playback.currentVideoDuration = 0
+					if cs, ok := r.client.getMediaPlayerSession(); ok {
+						cs.resetVideoDuration()
+					}
 				case mediaplayer.StreamingVideoCompletedEvent:
 				case mediaplayer.StreamingTrackingStoppedEvent:
-					if r.client.currentTorrent.IsPresent() {
+					// The media player only plays the stream of one client
+					if cs, ok := r.client.getMediaPlayerSession(); ok {
 						go func() {
 							defer func() {
 								if r := recover(); r != nil {
@@ -47,14 +47,17 @@ func (r *Repository) listenToMediaPlayerEvents() {
 							}()
 							r.logger.Debug().Msg("torrentstream: Media player stopped event received")
 							// Stop the stream
-							_ = r.StopStream()
+							_ = r.StopStream(cs.clientId)
 						}()
 					}
 				case mediaplayer.StreamingPlaybackStatusEvent:
+					cs, ok := r.client.getMediaPlayerSession()
+					if !ok || e.Status == nil {
+						continue
+					}
+					r.onPlaybackPosition(cs, e.Status.CurrentTimeInSeconds, e.Status.DurationInSeconds, e.Status.Playing)
 					go func() {
-						if e.Status != nil && r.client.currentTorrent.IsPresent() {
-							r.client.mediaPlayerPlaybackStatusCh <- e.Status
-						}
+						r.client.mediaPlayerPlaybackStatusCh <- e.Status
 					}()
 				}
 			}
@@ -74,37 +77,41 @@ func (r *Repository) listenToNativePlayerEvents() {
 					return
 				}
 
+				// Only handle the events of the client streaming the torrent to avoid unnecessary cleanup
+				cs, ok := r.client.getSession(event.GetClientId())
+				if !ok || cs.opts.PlaybackType != PlaybackTypeNativePlayer {
+					continue
+				}
+
 				switch event := event.(type) {
+				case *nativeplayer.VideoStatusEvent:
+					r.onPlaybackPosition(cs, event.Status.CurrentTime, event.Status.Duration, !event.Status.Paused)
+				case *nativeplayer.VideoSeekedEvent:
+					r.onPlaybackPosition(cs, event.CurrentTime, event.Duration, true)
 				case *nativeplayer.VideoLoadedMetadataEvent:
+					r.onPlaybackPosition(cs, event.CurrentTime, event.Duration, false)
 					go func() {
-						if r.client.currentFile.IsPresent() && r.playback.currentVideoDuration == 0 {
-							// If the stored video duration is 0 but the media player status shows a duration that is not 0
-							// we know that the video has been loaded and is playing
-							if r.playback.currentVideoDuration == 0 && event.Duration > 0 {
-								// The media player has started playing the video
-								r.logger.Debug().Msg("torrentstream: Media player started playing the video, sending event")
-								r.sendStateEvent(eventTorrentStartedPlaying)
-								// Update the stored video duration
-								r.playback.currentVideoDuration = int(event.Duration)
-							}
+						// If the stored video duration is 0 but the media player status shows a duration that is not 0
+						// we know that the video has been loaded and is playing
+						if cs.setVideoDuration(int(event.Duration)) {
+							// The media player has started playing the video
+							r.logger.Debug().Msg("torrentstream: Media player started playing the video, sending event")
+							r.sendStateEventTo(cs.clientId, eventTorrentStartedPlaying)
 						}
 					}()
 				case *nativeplayer.VideoTerminatedEvent:
 					r.logger.Debug().Msg("torrentstream: Native player terminated event received")
-					r.playback.currentVideoDuration = 0
-					// Only handle the event if we actually have a current torrent to avoid unnecessary cleanup
-					if r.client.currentTorrent.IsPresent() {
-						go func() {
-							defer func() {
-								if rec := recover(); rec != nil {
-									r.logger.Error().Msg("torrentstream: Recovered from panic in VideoTerminatedEvent handler")
-								}
-							}()
-							r.logger.Debug().Msg("torrentstream: Stopping stream due to native player termination")
-							// Stop the stream
-							_ = r.StopStream()
+					cs.resetVideoDuration()
+					go func() {
+						defer func() {
+							if rec := recover(); rec != nil {
+								r.logger.Error().Msg("torrentstream: Recovered from panic in VideoTerminatedEvent handler")
+							}
 						}()
-					}
+						r.logger.Debug().Msg("torrentstream: Stopping stream due to native player termination")
+						// Stop the stream
+						_ = r.StopStream(cs.clientId)
+					}()
 				}
 			}
 		}
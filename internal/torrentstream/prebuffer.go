@@ -0,0 +1,236 @@
+package torrentstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"seanime/internal/api/anilist"
+	torrentanalyzer "seanime/internal/torrents/analyzer"
+	"seanime/internal/util"
+	"seanime/internal/util/torrentutil"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/anacrolix/torrent"
+	"github.com/samber/lo"
+)
+
+const (
+	// Bytes downloaded from the start of the next episode
+	preBufferSize = 32 << 20
+)
+
+var errNoNextEpisode = errors.New("torrentstream: no next episode")
+
+type (
+	// preBuffer holds the next episode of each client, resolved and partially downloaded while the current one is streaming.
+	preBuffer struct {
+		mu sync.Mutex
+		// Key of the stream that triggered the pre-buffering, by client ID, it is only triggered once per stream
+		triggeredBy map[string]string
+		episodes    map[string]*preBufferedEpisode
+	}
+
+	preBufferedEpisode struct {
+		MediaId       int
+		EpisodeNumber int
+		*playbackTorrent
+	}
+)
+
+func newPreBuffer() *preBuffer {
+	return &preBuffer{
+		triggeredBy: make(map[string]string),
+		episodes:    make(map[string]*preBufferedEpisode),
+	}
+}
+
+// headPieces returns the range of pieces [start, end] covering the first bytes of a file.
+func headPieces(offset, length, pieceLength, size int64) (int, int) {
+	if pieceLength <= 0 || length <= 0 {
+		return 0, -1
+	}
+	size = min(size, length)
+	return int(offset / pieceLength), int((offset + size - 1) / pieceLength)
+}
+
+// onPlaybackPosition is called when the player of the client reports its position.
+func (r *Repository) onPlaybackPosition(cs *session, position, duration float64, playing bool) {
+	cs.prefetcher.updatePosition(position, duration, playing)
+
+	if torrentutil.ShouldPreBufferNextEpisode(position, duration) {
+		r.preBufferNextEpisode(cs)
+	}
+}
+
+// preBufferNextEpisode resolves the torrent of the next episode and downloads its first pieces,
+// so that playing the next episode starts instantly.
+// If the next episode is in the torrent being streamed (batch), its file is used.
+// Otherwise, the torrent is searched using the auto-selection, if the current episode was auto-selected.
+func (r *Repository) preBufferNextEpisode(cs *session) {
+	settings, ok := r.settings.Get()
+	if !ok || !settings.PreBufferNextEpisode {
+		return
+	}
+
+	opts, current := cs.opts, cs.torrent
+
+	key := fmt.Sprintf("%d-%d", opts.MediaId, opts.EpisodeNumber)
+	r.preBuffer.mu.Lock()
+	if r.preBuffer.triggeredBy[cs.clientId] == key {
+		r.preBuffer.mu.Unlock()
+		return
+	}
+	r.preBuffer.triggeredBy[cs.clientId] = key
+	r.preBuffer.mu.Unlock()
+
+	go func() {
+		defer util.HandlePanicInModuleThen("torrentstream/preBufferNextEpisode", func() {})
+
+		episode, err := r.resolveNextEpisode(opts, current)
+		if err != nil {
+			if !errors.Is(err, errNoNextEpisode) {
+				r.logger.Warn().Err(err).Msg("torrentstream: Could not pre-buffer the next episode")
+			}
+			return
+		}
+
+		start, end := headPieces(episode.File.Offset(), episode.File.Length(), episode.Torrent.Info().PieceLength, preBufferSize)
+		for idx := start; idx <= end && idx < episode.Torrent.NumPieces(); idx++ {
+			piece := episode.Torrent.Piece(idx)
+			if !piece.State().Complete && piece.State().Priority < torrent.PiecePriorityNormal {
+				piece.SetPriority(torrent.PiecePriorityNormal)
+			}
+		}
+
+		r.preBuffer.mu.Lock()
+		r.preBuffer.episodes[cs.clientId] = episode
+		r.preBuffer.mu.Unlock()
+
+		r.logger.Info().Str("file", episode.File.DisplayPath()).Msgf("torrentstream: Pre-buffering episode %d", episode.EpisodeNumber)
+	}()
+}
+
+func (r *Repository) resolveNextEpisode(opts *StartStreamOptions, current *torrent.Torrent) (*preBufferedEpisode, error) {
+	media, _, err := r.GetMediaInfo(context.Background(), opts.MediaId)
+	if err != nil {
+		return nil, err
+	}
+
+	episodeNumber, ok := torrentutil.NextEpisodeNumber(opts.EpisodeNumber, media.GetCurrentEpisodeCount())
+	if !ok {
+		return nil, errNoNextEpisode
+	}
+	aniDbEpisode := strconv.Itoa(episodeNumber)
+
+	// The next episode will be streamed from the cache
+	if r.cacheEnabled() {
+		if _, err := r.db.GetCompleteTorrentstreamCacheEntry(opts.MediaId, episodeNumber); err == nil {
+			return nil, errNoNextEpisode
+		}
+	}
+
+	ret := &preBufferedEpisode{MediaId: opts.MediaId, EpisodeNumber: episodeNumber}
+
+	if len(current.Files()) > 1 {
+		if f, found := r.findEpisodeFile(current, media, aniDbEpisode); found {
+			ret.playbackTorrent = &playbackTorrent{Torrent: current, File: f}
+			return ret, nil
+		}
+	}
+
+	// Manually selected torrents need the user
+	if !opts.AutoSelect {
+		return nil, errNoNextEpisode
+	}
+
+	pt, err := r.findTorrent(media, aniDbEpisode, episodeNumber, "", true)
+	if err != nil {
+		return nil, err
+	}
+	ret.playbackTorrent = pt
+	return ret, nil
+}
+
+// findEpisodeFile returns the file of the episode in a torrent.
+func (r *Repository) findEpisodeFile(t *torrent.Torrent, media *anilist.CompleteAnime, aniDbEpisode string) (*torrent.File, bool) {
+	analysis, err := torrentanalyzer.NewAnalyzer(&torrentanalyzer.NewAnalyzerOptions{
+		Logger: r.logger,
+		Filepaths: lo.Map(t.Files(), func(f *torrent.File, _ int) string {
+			return f.DisplayPath()
+		}),
+		Media:            media,
+		Platform:         r.platform,
+		MetadataProvider: r.metadataProvider,
+		ForceMatch:       true,
+	}).AnalyzeTorrentFiles()
+	if err != nil {
+		return nil, false
+	}
+
+	analysisFile, found := analysis.GetFileByAniDBEpisode(aniDbEpisode)
+	if !found {
+		return nil, false
+	}
+	return t.Files()[analysisFile.GetIndex()], true
+}
+
+// isPreBufferedTorrent returns true if the torrent holds the next episode of a client.
+func (r *Repository) isPreBufferedTorrent(t *torrent.Torrent) bool {
+	r.preBuffer.mu.Lock()
+	defer r.preBuffer.mu.Unlock()
+	for _, episode := range r.preBuffer.episodes {
+		if episode.Torrent == t {
+			return true
+		}
+	}
+	return false
+}
+
+// takePreBufferedEpisode returns the pre-buffered episode of the client if it is the one requested.
+// The unused torrents are dropped and the file is prioritized for streaming.
+func (r *Repository) takePreBufferedEpisode(opts *StartStreamOptions) (*playbackTorrent, bool) {
+	r.preBuffer.mu.Lock()
+	episode := r.preBuffer.episodes[opts.ClientId]
+	delete(r.preBuffer.episodes, opts.ClientId)
+	delete(r.preBuffer.triggeredBy, opts.ClientId)
+	r.preBuffer.mu.Unlock()
+
+	if episode == nil || episode.MediaId != opts.MediaId || episode.EpisodeNumber != opts.EpisodeNumber {
+		return nil, false
+	}
+
+	// Manual selection of another torrent or file
+	if !opts.AutoSelect {
+		if opts.Torrent == nil || !strings.EqualFold(opts.Torrent.InfoHash, episode.Torrent.InfoHash().HexString()) {
+			return nil, false
+		}
+		if opts.FileIndex != nil && (*opts.FileIndex < 0 || *opts.FileIndex >= len(episode.Torrent.Files()) || episode.Torrent.Files()[*opts.FileIndex] != episode.File) {
+			return nil, false
+		}
+	}
+
+	select {
+	case <-episode.Torrent.Closed():
+		return nil, false
+	default:
+	}
+
+	if r.client.torrentClient.IsAbsent() {
+		return nil, false
+	}
+	r.client.dropUnusedTorrents(episode.Torrent)
+
+	for _, f := range episode.Torrent.Files() {
+		if f != episode.File && !r.client.isFileStreaming(f) {
+			f.SetPriority(torrent.PiecePriorityNone)
+		}
+	}
+	episode.File.Download()
+	r.setPriorityDownloadStrategy(episode.Torrent, episode.File)
+
+	r.logger.Info().Str("file", episode.File.DisplayPath()).Msg("torrentstream: Streaming pre-buffered episode")
+
+	return episode.playbackTorrent, true
+}
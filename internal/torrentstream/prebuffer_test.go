@@ -0,0 +1,19 @@
+package torrentstream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeadPieces(t *testing.T) {
+	// File starting in the middle of piece 2
+	start, end := headPieces(5<<19, 100<<20, 1<<20, 32<<20)
+	assert.Equal(t, 2, start)
+	assert.Equal(t, 34, end)
+
+	// File smaller than the pre-buffer size
+	start, end = headPieces(0, 3<<20, 1<<20, 32<<20)
+	assert.Equal(t, 0, start)
+	assert.Equal(t, 2, end)
+}
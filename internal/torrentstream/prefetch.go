@@ -0,0 +1,371 @@
+package torrentstream
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/anacrolix/torrent"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// Seconds ahead of the playback position downloaded first
+	prefetchNowSeconds = 10.0
+	// Seconds ahead of the playback position downloaded next
+	prefetchNextSeconds = 30.0
+	// Bounds of the readahead window, it grows when the download is faster than the bitrate
+	prefetchMinReadaheadSeconds = 30.0
+	prefetchMaxReadaheadSeconds = 300.0
+	// Seconds behind the playback position kept for rewinds
+	prefetchBehindSeconds = 5.0
+	// A position that differs from the expected position by more than this is a seek
+	seekThresholdSeconds = 15.0
+)
+
+const (
+	BufferHealthGood     BufferHealthState = "good"
+	BufferHealthLow      BufferHealthState = "low"
+	BufferHealthStalling BufferHealthState = "stalling"
+)
+
+type (
+	BufferHealthState string
+
+	// BufferHealth is sent to the client with the torrent status.
+	BufferHealth struct {
+		// Playback position in seconds
+		Position float64 `json:"position"`
+		// Seconds downloaded contiguously ahead of the playback position
+		BufferedSeconds float64 `json:"bufferedSeconds"`
+		// Download rate in bytes per second
+		DownloadRate float64 `json:"downloadRate"`
+		// Average bitrate of the file in bytes per second, the download rate needed to play without stalling
+		NeededRate float64           `json:"neededRate"`
+		State      BufferHealthState `json:"state"`
+	}
+
+	// fileLayout maps playback positions to the pieces of a file, assuming a constant bitrate.
+	fileLayout struct {
+		offset      int64
+		length      int64
+		pieceLength int64
+		numPieces   int
+		duration    float64
+	}
+
+	// pieceWindow is a range of pieces [start, end] and their priority.
+	pieceWindow struct {
+		start, end int
+		priority   torrent.PiecePriority
+	}
+
+	// piecePriorities keeps the pieces wanted by the prefetchers of each torrent.
+	// Several sessions can stream the same torrent, a piece leaving the window of one session keeps
+	// the highest priority wanted by the other sessions and is only reset to normal when no session needs it.
+	piecePriorities struct {
+		mu     sync.Mutex
+		wanted map[*torrent.Torrent]map[*prefetcher]map[int]torrent.PiecePriority
+	}
+
+	// prefetcher prioritizes the pieces ahead of the playback position of the current file.
+	// The static strategy set by [Repository.setPriorityDownloadStrategy] is used until the player reports its position.
+	// All the fields are guarded by mu, the prefetcher is updated by the player events and the status loop.
+	prefetcher struct {
+		mu           sync.Mutex
+		torrent      *torrent.Torrent
+		file         *torrent.File
+		logger       *zerolog.Logger
+		layout       fileLayout
+		position     float64
+		positionAt   time.Time
+		playing      bool
+		downloadRate float64
+		// Shared by the prefetchers of the client
+		priorities *piecePriorities
+	}
+)
+
+func newPiecePriorities() *piecePriorities {
+	return &piecePriorities{
+		wanted: make(map[*torrent.Torrent]map[*prefetcher]map[int]torrent.PiecePriority),
+	}
+}
+
+// update sets the pieces wanted by the prefetcher and returns the new priority of the pieces it wanted or now wants.
+// Each piece gets the highest priority wanted by the prefetchers of the torrent, or normal if none wants it.
+func (pp *piecePriorities) update(t *torrent.Torrent, owner *prefetcher, needed map[int]torrent.PiecePriority) map[int]torrent.PiecePriority {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+
+	byOwner, ok := pp.wanted[t]
+	if !ok {
+		byOwner = make(map[*prefetcher]map[int]torrent.PiecePriority)
+		pp.wanted[t] = byOwner
+	}
+	previous := byOwner[owner]
+	if len(needed) > 0 {
+		byOwner[owner] = needed
+	} else {
+		delete(byOwner, owner)
+	}
+	if len(byOwner) == 0 {
+		delete(pp.wanted, t)
+	}
+
+	highest := func(idx int) torrent.PiecePriority {
+		ret := torrent.PiecePriorityNormal
+		for _, wanted := range byOwner {
+			if priority, found := wanted[idx]; found && priority > ret {
+				ret = priority
+			}
+		}
+		return ret
+	}
+
+	ret := make(map[int]torrent.PiecePriority, len(previous)+len(needed))
+	for idx := range previous {
+		ret[idx] = highest(idx)
+	}
+	for idx := range needed {
+		ret[idx] = highest(idx)
+	}
+	return ret
+}
+
+// clear forgets the pieces wanted by all the prefetchers, used when the torrents are dropped.
+func (pp *piecePriorities) clear() {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+	pp.wanted = make(map[*torrent.Torrent]map[*prefetcher]map[int]torrent.PiecePriority)
+}
+
+func newPrefetcher(t *torrent.Torrent, file *torrent.File, priorities *piecePriorities, logger *zerolog.Logger) *prefetcher {
+	ret := &prefetcher{
+		torrent:    t,
+		file:       file,
+		logger:     logger,
+		priorities: priorities,
+	}
+	ret.initLayout()
+	return ret
+}
+
+// initLayout sets the layout of the file once the info of the torrent is known.
+// Returns false if the info is not known yet.
+// Caller should hold the lock if the prefetcher is shared.
+func (p *prefetcher) initLayout() bool {
+	if p.layout.pieceLength > 0 {
+		return true
+	}
+	if p.torrent == nil || p.file == nil || p.torrent.Info() == nil {
+		return false
+	}
+	p.layout = fileLayout{
+		offset:      p.file.Offset(),
+		length:      p.file.Length(),
+		pieceLength: p.torrent.Info().PieceLength,
+		numPieces:   p.torrent.NumPieces(),
+		duration:    p.layout.duration,
+	}
+	return p.layout.pieceLength > 0
+}
+
+// bitrate returns the average bitrate of the file in bytes per second.
+func (l fileLayout) bitrate() float64 {
+	if l.duration <= 0 {
+		return 0
+	}
+	return float64(l.length) / l.duration
+}
+
+// pieceAt returns the index of the piece at the given playback position.
+func (l fileLayout) pieceAt(seconds float64) int {
+	seconds = max(0, min(seconds, l.duration))
+	pos := int64(seconds * l.bitrate())
+	pos = max(0, min(pos, l.length-1))
+	return int((l.offset + pos) / l.pieceLength)
+}
+
+// lastPiece returns the index of the last piece of the file.
+func (l fileLayout) lastPiece() int {
+	return int((l.offset + l.length - 1) / l.pieceLength)
+}
+
+// readaheadSeconds returns how far ahead of the playback position to download.
+// The window grows when the download is faster than the bitrate so the extra bandwidth builds a buffer,
+// and shrinks when it is slower so the bandwidth goes to the pieces needed soon.
+func readaheadSeconds(bitrate, downloadRate float64) float64 {
+	if bitrate <= 0 {
+		return prefetchMinReadaheadSeconds
+	}
+	return max(prefetchMinReadaheadSeconds, min(prefetchMaxReadaheadSeconds, 60*downloadRate/bitrate))
+}
+
+// windows returns the pieces to prioritize for the given playback position, highest priority first.
+func (l fileLayout) windows(position, readahead float64) []pieceWindow {
+	if l.pieceLength <= 0 || l.duration <= 0 {
+		return nil
+	}
+	current := l.pieceAt(position)
+	nowEnd := l.pieceAt(position + prefetchNowSeconds)
+	nextEnd := max(nowEnd, l.pieceAt(position+prefetchNextSeconds))
+	readaheadEnd := max(nextEnd, l.pieceAt(position+readahead))
+
+	ret := []pieceWindow{
+		{start: current, end: nowEnd, priority: torrent.PiecePriorityNow},
+		{start: l.pieceAt(position - prefetchBehindSeconds), end: current - 1, priority: torrent.PiecePriorityHigh},
+		{start: nowEnd + 1, end: nextEnd, priority: torrent.PiecePriorityNext},
+		{start: nextEnd + 1, end: readaheadEnd, priority: torrent.PiecePriorityReadahead},
+	}
+	return ret
+}
+
+// isSeek returns true if the position is not where playback would be since the last update.
+func isSeek(lastPosition float64, elapsed time.Duration, playing bool, position float64) bool {
+	expected := lastPosition
+	if playing {
+		expected += elapsed.Seconds()
+	}
+	return math.Abs(position-expected) > seekThresholdSeconds
+}
+
+// bufferHealthState returns whether playback is expected to stall.
+func bufferHealthState(bufferedSeconds, downloadRate, neededRate float64) BufferHealthState {
+	switch {
+	case bufferedSeconds >= prefetchNextSeconds:
+		return BufferHealthGood
+	case neededRate > 0 && downloadRate >= neededRate*1.1 && bufferedSeconds >= prefetchNowSeconds:
+		return BufferHealthGood
+	case bufferedSeconds < 5 && downloadRate < neededRate:
+		return BufferHealthStalling
+	default:
+		return BufferHealthLow
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// updatePosition is called when the player reports its position.
+func (p *prefetcher) updatePosition(position, duration float64, playing bool) {
+	if p == nil || duration <= 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.initLayout() {
+		return
+	}
+
+	now := time.Now()
+	// The pieces of the previous window are reset below
+	seeked := !p.positionAt.IsZero() && isSeek(p.position, now.Sub(p.positionAt), p.playing, position)
+	if seeked && p.logger != nil {
+		p.logger.Debug().Float64("from", p.position).Float64("to", position).Msg("torrentstream: Seek detected, re-prioritizing pieces")
+	}
+
+	p.layout.duration = duration
+	p.position = position
+	p.positionAt = now
+	p.playing = playing
+
+	p.prioritize()
+}
+
+// setDownloadRate is called periodically with the download rate of the torrent.
+func (p *prefetcher) setDownloadRate(bytesPerSecond float64) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.downloadRate = bytesPerSecond
+	if !p.positionAt.IsZero() && p.initLayout() {
+		// The readahead window depends on the download rate
+		p.prioritize()
+	}
+}
+
+// prioritize sets the priorities of the pieces around the playback position.
+// Pieces prioritized by the previous update that are no longer needed are reset to normal, unless another session needs them.
+// Caller should hold the lock.
+func (p *prefetcher) prioritize() {
+	readahead := readaheadSeconds(p.layout.bitrate(), p.downloadRate)
+	needed := make(map[int]torrent.PiecePriority)
+	for _, w := range p.layout.windows(p.position, readahead) {
+		for idx := max(w.start, 0); idx <= w.end && idx <= p.layout.lastPiece() && idx < p.layout.numPieces; idx++ {
+			if _, found := needed[idx]; !found {
+				needed[idx] = w.priority
+			}
+		}
+	}
+
+	p.applyPriorities(p.priorities.update(p.torrent, p, needed))
+}
+
+// release resets the pieces prioritized by the prefetcher, used when its session ends.
+func (p *prefetcher) release() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.applyPriorities(p.priorities.update(p.torrent, p, nil))
+}
+
+// applyPriorities sets the priorities of the pieces that are not downloaded yet.
+// Caller should hold the lock.
+func (p *prefetcher) applyPriorities(priorities map[int]torrent.PiecePriority) {
+	for idx, priority := range priorities {
+		piece := p.torrent.Piece(idx)
+		if piece.State().Complete {
+			continue
+		}
+		if piece.State().Priority != priority {
+			piece.SetPriority(priority)
+		}
+	}
+}
+
+// bufferHealth returns the buffer health at the playback position.
+func (p *prefetcher) bufferHealth() (*BufferHealth, bool) {
+	if p == nil {
+		return nil, false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.positionAt.IsZero() || p.layout.duration <= 0 || !p.initLayout() {
+		return nil, false
+	}
+
+	position := p.position
+	if p.playing {
+		position += time.Since(p.positionAt).Seconds()
+	}
+
+	// Count the completed pieces after the current one
+	bitrate := p.layout.bitrate()
+	posBytes := p.layout.offset + int64(position*bitrate)
+	end := posBytes
+	for idx := p.layout.pieceAt(position); idx <= p.layout.lastPiece(); idx++ {
+		if !p.torrent.Piece(idx).State().Complete {
+			break
+		}
+		end = int64(idx+1) * p.layout.pieceLength
+	}
+	end = min(end, p.layout.offset+p.layout.length)
+	buffered := float64(max(0, end-posBytes)) / bitrate
+
+	return &BufferHealth{
+		Position:        position,
+		BufferedSeconds: buffered,
+		DownloadRate:    p.downloadRate,
+		NeededRate:      bitrate,
+		State:           bufferHealthState(buffered, p.downloadRate, bitrate),
+	}, true
+}
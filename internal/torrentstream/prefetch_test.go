@@ -0,0 +1,96 @@
+package torrentstream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/anacrolix/torrent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefetchWindows(t *testing.T) {
+	// 1000 pieces of 1MB, 24 minute episode starting at piece 10
+	layout := fileLayout{
+		offset:      10 << 20,
+		length:      1000 << 20,
+		pieceLength: 1 << 20,
+		numPieces:   1010,
+		duration:    1440,
+	}
+	// ~0.69 pieces per second
+
+	assert.Equal(t, 10, layout.pieceAt(0))
+	assert.Equal(t, 1009, layout.lastPiece())
+	assert.Equal(t, 1009, layout.pieceAt(2000))
+
+	windows := layout.windows(720, 60)
+	require.Len(t, windows, 4)
+	assert.Equal(t, pieceWindow{start: 510, end: 516, priority: torrent.PiecePriorityNow}, windows[0])
+	assert.Equal(t, pieceWindow{start: 506, end: 509, priority: torrent.PiecePriorityHigh}, windows[1])
+	assert.Equal(t, pieceWindow{start: 517, end: 530, priority: torrent.PiecePriorityNext}, windows[2])
+	assert.Equal(t, pieceWindow{start: 531, end: 551, priority: torrent.PiecePriorityReadahead}, windows[3])
+
+	// Near the end of the file
+	windows = layout.windows(1435, 60)
+	assert.Equal(t, 1009, windows[3].end)
+
+	// Unknown duration
+	layout.duration = 0
+	assert.Nil(t, layout.windows(0, 60))
+}
+
+func TestReadaheadSeconds(t *testing.T) {
+	assert.Equal(t, prefetchMinReadaheadSeconds, readaheadSeconds(0, 1000))
+	// Download slower than the bitrate
+	assert.Equal(t, prefetchMinReadaheadSeconds, readaheadSeconds(1000, 200))
+	assert.Equal(t, 120.0, readaheadSeconds(1000, 2000))
+	assert.Equal(t, prefetchMaxReadaheadSeconds, readaheadSeconds(1000, 100000))
+}
+
+func TestIsSeek(t *testing.T) {
+	assert.False(t, isSeek(100, 5*time.Second, true, 105))
+	assert.True(t, isSeek(100, 5*time.Second, true, 300))
+	// Rewind
+	assert.True(t, isSeek(100, 5*time.Second, true, 60))
+	// Paused
+	assert.False(t, isSeek(100, 30*time.Second, false, 100))
+	assert.True(t, isSeek(100, 30*time.Second, false, 130))
+}
+
+func TestBufferHealthState(t *testing.T) {
+	assert.Equal(t, BufferHealthGood, bufferHealthState(45, 0, 1000))
+	assert.Equal(t, BufferHealthGood, bufferHealthState(12, 2000, 1000))
+	assert.Equal(t, BufferHealthLow, bufferHealthState(12, 500, 1000))
+	assert.Equal(t, BufferHealthStalling, bufferHealthState(2, 500, 1000))
+	assert.Equal(t, BufferHealthLow, bufferHealthState(2, 2000, 1000))
+}
+
+func TestPiecePriorities(t *testing.T) {
+	pp := newPiecePriorities()
+	tor := &torrent.Torrent{}
+	a, b := &prefetcher{}, &prefetcher{}
+
+	pp.update(tor, a, map[int]torrent.PiecePriority{1: torrent.PiecePriorityNow, 2: torrent.PiecePriorityNext})
+	changes := pp.update(tor, b, map[int]torrent.PiecePriority{2: torrent.PiecePriorityNow, 3: torrent.PiecePriorityReadahead})
+	assert.Equal(t, map[int]torrent.PiecePriority{2: torrent.PiecePriorityNow, 3: torrent.PiecePriorityReadahead}, changes)
+
+	// Pieces leaving the window of a are only reset if b does not need them
+	changes = pp.update(tor, a, map[int]torrent.PiecePriority{5: torrent.PiecePriorityNow})
+	assert.Equal(t, map[int]torrent.PiecePriority{
+		1: torrent.PiecePriorityNormal,
+		2: torrent.PiecePriorityNow,
+		5: torrent.PiecePriorityNow,
+	}, changes)
+
+	// Releasing b resets its pieces, the pieces of a are kept
+	changes = pp.update(tor, b, nil)
+	assert.Equal(t, map[int]torrent.PiecePriority{
+		2: torrent.PiecePriorityNormal,
+		3: torrent.PiecePriorityNormal,
+	}, changes)
+
+	changes = pp.update(tor, a, nil)
+	assert.Equal(t, map[int]torrent.PiecePriority{5: torrent.PiecePriorityNormal}, changes)
+	assert.Empty(t, pp.wanted)
+}
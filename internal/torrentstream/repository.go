@@ -53,6 +53,8 @@ type (
 		onEpisodeCollectionChanged func(ec *anime.EpisodeCollection)
 
 		previousStreamOptions mo.Option[*StartStreamOptions]
+		// Next episode downloaded in advance
+		preBuffer *preBuffer
 	}
 
 	Settings struct {
@@ -97,6 +99,7 @@ func NewRepository(opts *NewRepositoryOptions) *Repository {
 		directStreamManager:             opts.DirectStreamManager,
 		nativePlayer:                    opts.NativePlayer,
 		previousStreamOptions:           mo.None[*StartStreamOptions](),
+		preBuffer:                       newPreBuffer(),
 	}
 	ret.client = NewClient(ret)
 	ret.handler = newHandler(ret)
@@ -0,0 +1,126 @@
+package torrentstream
+
+import (
+	"sync"
+	"time"
+
+	"github.com/anacrolix/torrent"
+	"github.com/rs/zerolog"
+)
+
+type (
+	// session is the stream of a torrent file to a client.
+	// Each client streams one file at a time, several clients can stream at the same time.
+	session struct {
+		clientId string
+		opts     *StartStreamOptions
+		torrent  *torrent.Torrent
+		file     *torrent.File
+		// Guards status and videoDuration, they are updated by the status loop and the player events
+		mu     sync.Mutex
+		status TorrentStatus
+		// Follows the playback position of the client, set when the session is created
+		prefetcher *prefetcher
+		// Set once the file has been completely downloaded
+		fileComplete bool
+		// Stores the video duration returned by the player
+		// When this is greater than 0, the video is considered to be playing
+		videoDuration int
+
+		lastSpeedCheck       time.Time // Track the last time we checked speeds
+		lastBytesCompleted   int64     // Track the last bytes completed
+		lastBytesWrittenData int64     // Track the last bytes written data
+	}
+)
+
+func newSession(opts *StartStreamOptions, pt *playbackTorrent, priorities *piecePriorities, logger *zerolog.Logger) *session {
+	return &session{
+		clientId:   opts.ClientId,
+		opts:       opts,
+		torrent:    pt.Torrent,
+		file:       pt.File,
+		prefetcher: newPrefetcher(pt.Torrent, pt.File, priorities, logger),
+	}
+}
+
+func (cs *session) getStatus() TorrentStatus {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return cs.status
+}
+
+func (cs *session) resetVideoDuration() {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.videoDuration = 0
+}
+
+// setVideoDuration stores the duration returned by the player.
+// Returns true if the video just started playing.
+func (cs *session) setVideoDuration(duration int) bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.videoDuration != 0 || duration <= 0 {
+		return false
+	}
+	cs.videoDuration = duration
+	return true
+}
+
+func (c *Client) getSession(clientId string) (*session, bool) {
+	return c.sessions.Get(clientId)
+}
+
+// isSessionActive returns false once the session has been stopped or replaced by another stream of the client.
+func (c *Client) isSessionActive(cs *session) bool {
+	current, ok := c.sessions.Get(cs.clientId)
+	return ok && current == cs
+}
+
+// getSessionByFile returns the session streaming the file, the display path is used by the stream URL.
+// If no path is given, the session is returned only if it is the only one.
+func (c *Client) getSessionByFile(displayPath string) (*session, bool) {
+	sessions := c.sessions.Values()
+	if displayPath == "" {
+		if len(sessions) != 1 {
+			return nil, false
+		}
+		return sessions[0], true
+	}
+	for _, s := range sessions {
+		if s.file.DisplayPath() == displayPath {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// getMediaPlayerSession returns the session played by the desktop media player.
+// There is only one media player, it plays the last stream sent to it.
+func (c *Client) getMediaPlayerSession() (*session, bool) {
+	cs := c.mediaPlayerSession.Load()
+	if cs == nil || !c.isSessionActive(cs) {
+		return nil, false
+	}
+	return cs, true
+}
+
+// isTorrentInUse returns true if the torrent is streamed by a client or holds a pre-buffered episode.
+func (c *Client) isTorrentInUse(t *torrent.Torrent) bool {
+	for _, s := range c.sessions.Values() {
+		if s.torrent == t {
+			return true
+		}
+	}
+	return c.repository.isPreBufferedTorrent(t)
+}
+
+// isFileStreaming returns true if the file is streamed by a client.
+func (c *Client) isFileStreaming(f *torrent.File) bool {
+	for _, s := range c.sessions.Values() {
+		if s.file == f {
+			return true
+		}
+	}
+	return false
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"seanime/internal/api/anilist"
 	"seanime/internal/api/metadata"
+	"seanime/internal/continuity"
 	"seanime/internal/directstream"
 	"seanime/internal/events"
 	hibiketorrent "seanime/internal/extension/hibike/torrent"
@@ -14,7 +15,6 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/anacrolix/torrent"
 	"github.com/samber/mo"
 )
 
@@ -53,7 +53,19 @@ func (r *Repository) StartStream(ctx context.Context, opts *StartStreamOptions)
 		Any("playbackType", opts.PlaybackType).
 		Int("mediaId", opts.MediaId).Msgf("torrentstream: Starting stream for episode %s", opts.AniDBEpisode)
 
-	r.sendStateEvent(eventLoading)
+	r.sendStateEventTo(opts.ClientId, eventLoading)
+
+	// End the previous stream of the client, its torrent is released once the new stream is started
+	// so that it is kept if the new stream uses the same torrent
+	// Streams of other clients are not affected
+	if previous, ok := r.client.getSession(opts.ClientId); ok {
+		r.client.sessions.Delete(opts.ClientId)
+		defer func() {
+			r.client.mu.Lock()
+			r.releaseSession(previous)
+			r.client.mu.Unlock()
+		}()
+	}
 
 	if opts.PlaybackType == PlaybackTypeNativePlayer {
 		r.directStreamManager.PrepareNewStream(opts.ClientId, "Selecting torrent...")
@@ -74,11 +86,19 @@ func (r *Repository) StartStream(ctx context.Context, opts *StartStreamOptions)
 	// Find the best torrent / Select the torrent
 	//
 	var torrentToStream *playbackTorrent
-	if opts.AutoSelect {
-		torrentToStream, err = r.findBestTorrent(media, aniDbEpisode, episodeNumber)
-		if err != nil {
-			r.sendStateEvent(eventLoadingFailed)
-			return err
+	if preBuffered, found := r.takePreBufferedEpisode(opts); found {
+		torrentToStream = preBuffered
+	} else if opts.AutoSelect {
+		// Re-watch the cached episode if it has been completely downloaded
+		cached, found := r.findCachedTorrent(opts.MediaId, episodeNumber)
+		if found {
+			torrentToStream = cached
+		} else {
+			torrentToStream, err = r.findBestTorrent(media, aniDbEpisode, episodeNumber, opts.ClientId)
+			if err != nil {
+				r.sendStateEventTo(opts.ClientId, eventLoadingFailed)
+				return err
+			}
 		}
 	} else {
 		if opts.Torrent == nil {
@@ -86,27 +106,31 @@ func (r *Repository) StartStream(ctx context.Context, opts *StartStreamOptions)
 		}
 		torrentToStream, err = r.findBestTorrentFromManualSelection(opts.Torrent, media, aniDbEpisode, opts.FileIndex)
 		if err != nil {
-			r.sendStateEvent(eventLoadingFailed)
+			r.sendStateEventTo(opts.ClientId, eventLoadingFailed)
 			return err
 		}
 	}
 
 	if torrentToStream == nil {
-		r.sendStateEvent(eventLoadingFailed)
+		r.sendStateEventTo(opts.ClientId, eventLoadingFailed)
 		return fmt.Errorf("torrentstream: No torrent selected")
 	}
 
 	//
-	// Set current file & torrent
+	// Start the session of the client
 	//
-	r.client.currentFile = mo.Some(torrentToStream.File)
-	r.client.currentTorrent = mo.Some(torrentToStream.Torrent)
+	cs := newSession(opts, torrentToStream, r.client.piecePriorities, r.logger)
+	r.client.sessions.Set(opts.ClientId, cs)
+	if opts.PlaybackType == PlaybackTypeExternal {
+		r.client.mediaPlayerSession.Store(cs)
+	}
+	r.recordCacheEntry(opts.MediaId, episodeNumber, torrentToStream.Torrent, torrentToStream.File)
 
-	r.sendStateEvent(eventLoading, TLSStateSendingStreamToMediaPlayer)
+	r.sendStateEventTo(opts.ClientId, eventLoading, TLSStateSendingStreamToMediaPlayer)
 
 	go func() {
 		// Add the torrent to the history if it is a batch & manually selected
-		if len(r.client.currentTorrent.MustGet().Files()) > 1 && opts.Torrent != nil {
+		if len(cs.torrent.Files()) > 1 && opts.Torrent != nil {
 			r.AddBatchHistory(opts.MediaId, opts.Torrent) // ran in goroutine
 		}
 	}()
@@ -120,23 +144,26 @@ func (r *Repository) StartStream(ctx context.Context, opts *StartStreamOptions)
 			r.logger.Warn().Msg("torrentstream: Playback type is set to 'none'")
 			// Signal to the client that the torrent has started playing (remove loading status)
 			// There will be no tracking
-			r.sendStateEvent(eventTorrentStartedPlaying)
+			r.sendStateEventTo(opts.ClientId, eventTorrentStartedPlaying)
 		case PlaybackTypeNoneAndAwait:
 			r.logger.Warn().Msg("torrentstream: Playback type is set to 'noneAndAwait'")
 			// Signal to the client that the torrent has started playing (remove loading status)
 			// There will be no tracking
 			for {
-				if r.client.readyToStream() {
+				if r.client.readyToStream(cs) {
 					break
 				}
+				if !r.client.isSessionActive(cs) {
+					return
+				}
 				time.Sleep(3 * time.Second) // Wait for 3 secs before checking again
 			}
-			r.sendStateEvent(eventTorrentStartedPlaying)
+			r.sendStateEventTo(opts.ClientId, eventTorrentStartedPlaying)
 		//
 		// External player
 		//
 		case PlaybackTypeExternal, PlaybackTypeExternalPlayerLink:
-			r.sendStreamToExternalPlayer(opts, media, aniDbEpisode)
+			r.sendStreamToExternalPlayer(cs, media, aniDbEpisode)
 		//
 		// Direct stream
 		//
@@ -146,12 +173,12 @@ func (r *Repository) StartStream(ctx context.Context, opts *StartStreamOptions)
 				EpisodeNumber: opts.EpisodeNumber,
 				AnidbEpisode:  opts.AniDBEpisode,
 				Media:         media.ToBaseAnime(),
-				Torrent:       r.client.currentTorrent.MustGet(),
-				File:          r.client.currentFile.MustGet(),
+				Torrent:       cs.torrent,
+				File:          cs.file,
 			})
 			if err != nil {
 				r.logger.Error().Err(err).Msg("torrentstream: Failed to prepare new stream")
-				r.sendStateEvent(eventLoadingFailed)
+				r.sendStateEventTo(opts.ClientId, eventLoadingFailed)
 				return
 			}
 
@@ -161,11 +188,11 @@ func (r *Repository) StartStream(ctx context.Context, opts *StartStreamOptions)
 
 			// Make sure the client is ready and the torrent is partially downloaded
 			for {
-				if r.client.readyToStream() {
+				if r.client.readyToStream(cs) {
 					break
 				}
 				// If for some reason the torrent is dropped, we kill the goroutine
-				if r.client.torrentClient.IsAbsent() || r.client.currentTorrent.IsAbsent() {
+				if r.client.torrentClient.IsAbsent() || !r.client.isSessionActive(cs) {
 					return
 				}
 				r.logger.Debug().Msg("torrentstream: Waiting for playable threshold to be reached")
@@ -175,7 +202,7 @@ func (r *Repository) StartStream(ctx context.Context, opts *StartStreamOptions)
 		}
 	}()
 
-	r.sendStateEvent(eventTorrentLoaded)
+	r.sendStateEventTo(opts.ClientId, eventTorrentLoaded)
 	r.logger.Info().Msg("torrentstream: Stream started")
 
 	return nil
@@ -183,17 +210,18 @@ func (r *Repository) StartStream(ctx context.Context, opts *StartStreamOptions)
 
 // sendStreamToExternalPlayer sends the stream to the desktop player or external player link.
 // It blocks until the some pieces have been downloaded before sending the stream for faster playback.
-func (r *Repository) sendStreamToExternalPlayer(opts *StartStreamOptions, completeAnime *anilist.CompleteAnime, aniDbEpisode string) {
+func (r *Repository) sendStreamToExternalPlayer(cs *session, completeAnime *anilist.CompleteAnime, aniDbEpisode string) {
+	opts := cs.opts
 
 	baseAnime := completeAnime.ToBaseAnime()
 
 	// Make sure the client is ready and the torrent is partially downloaded
 	for {
-		if r.client.readyToStream() {
+		if r.client.readyToStream(cs) {
 			break
 		}
 		// If for some reason the torrent is dropped, we kill the goroutine
-		if r.client.torrentClient.IsAbsent() || r.client.currentTorrent.IsAbsent() {
+		if r.client.torrentClient.IsAbsent() || !r.client.isSessionActive(cs) {
 			return
 		}
 		r.logger.Debug().Msg("torrentstream: Waiting for playable threshold to be reached")
@@ -202,7 +230,7 @@ func (r *Repository) sendStreamToExternalPlayer(opts *StartStreamOptions, comple
 
 	event := &TorrentStreamSendStreamToMediaPlayerEvent{
 		WindowTitle:  "",
-		StreamURL:    r.client.GetStreamingUrl(),
+		StreamURL:    r.client.GetStreamingUrl(opts.ClientId),
 		Media:        baseAnime,
 		AniDbEpisode: aniDbEpisode,
 		PlaybackType: string(opts.PlaybackType),
@@ -230,14 +258,15 @@ func (r *Repository) sendStreamToExternalPlayer(opts *StartStreamOptions, comple
 	case PlaybackTypeExternal:
 		r.logger.Debug().Msgf("torrentstream: Starting the media player %s", streamURL)
 		err = r.playbackManager.StartStreamingUsingMediaPlayer(windowTitle, &playbackmanager.StartPlayingOptions{
-			Payload:   streamURL,
-			UserAgent: opts.UserAgent,
-			ClientId:  opts.ClientId,
+			Payload:     streamURL,
+			UserAgent:   opts.UserAgent,
+			ClientId:    opts.ClientId,
+			WatchSource: continuity.TorrentWatchSource,
 		}, baseAnime, aniDbEpisode)
 		if err != nil {
 			// Failed to start the stream, we'll drop the torrents and stop the server
-			r.sendStateEvent(eventLoadingFailed)
-			_ = r.StopStream()
+			r.sendStateEventTo(opts.ClientId, eventLoadingFailed)
+			_ = r.StopStream(opts.ClientId)
 			r.logger.Error().Err(err).Msg("torrentstream: Failed to start the stream")
 			r.wsEventManager.SendEventTo(opts.ClientId, events.ErrorToast, err.Error())
 		}
@@ -259,7 +288,7 @@ func (r *Repository) sendStreamToExternalPlayer(opts *StartStreamOptions, comple
 
 		// Signal to the client that the torrent has started playing (remove loading status)
 		// We can't know for sure
-		r.sendStateEvent(eventTorrentStartedPlaying)
+		r.sendStateEventTo(opts.ClientId, eventTorrentStartedPlaying)
 	}
 }
 
@@ -294,8 +323,8 @@ func (r *Repository) StartUntrackedStream(opts *StartUntrackedStreamOptions) (er
 		})
 		if err != nil {
 			// Failed to start the stream, we'll drop the torrents and stop the server
-			r.sendStateEvent(eventLoadingFailed)
-			_ = r.StopStream()
+			r.sendStateEventTo(opts.ClientId, eventLoadingFailed)
+			_ = r.StopStream(opts.ClientId)
 			r.logger.Error().Err(err).Msg("torrentstream: Failed to start the stream")
 			r.wsEventManager.SendEventTo(opts.ClientId, events.ErrorToast, err.Error())
 		}
@@ -305,62 +334,96 @@ func (r *Repository) StartUntrackedStream(opts *StartUntrackedStreamOptions) (er
 	//
 	case PlaybackTypeExternalPlayerLink:
 		// Send the external player link
-		r.sendStateEvent(events.ExternalPlayerOpenURL, struct {
+		r.sendStateEventTo(opts.ClientId, events.ExternalPlayerOpenURL, struct {
 			Url           string `json:"url"`
 			MediaId       int    `json:"mediaId"`
 			EpisodeNumber int    `json:"episodeNumber"`
 		}{
-			Url:           r.client.GetStreamingUrl(),
+			Url:           r.client.GetStreamingUrl(opts.ClientId),
 			MediaId:       0,
 			EpisodeNumber: 0,
 		})
 
 		// Signal to the client that the torrent has started playing (remove loading status)
 		// We can't know for sure
-		r.sendStateEvent(eventTorrentStartedPlaying)
+		r.sendStateEventTo(opts.ClientId, eventTorrentStartedPlaying)
 	}
 
 	return nil
 }
 
-func (r *Repository) StopStream() error {
+// StopStream stops the stream of the client and drops its torrent if it's below a threshold.
+// If no client is given, the streams of all clients are stopped.
+func (r *Repository) StopStream(clientId string) error {
 	defer func() {
 		if r := recover(); r != nil {
 		}
 	}()
-	r.logger.Info().Msg("torrentstream: Stopping stream")
+	r.logger.Info().Str("clientId", clientId).Msg("torrentstream: Stopping stream")
+
+	var sessions []*session
+	if clientId == "" {
+		sessions = r.client.sessions.Values()
+	} else if cs, ok := r.client.getSession(clientId); ok {
+		sessions = append(sessions, cs)
+	}
 
 	// Stop the client
 	// This will stop the stream and close the server
 	// This also sends the eventTorrentStopped event
 	r.client.mu.Lock()
-	//r.client.stopCh = make(chan struct{})
-	r.client.repository.logger.Debug().Msg("torrentstream: Handling media player stopped event")
-	// This is to prevent the client from downloading the whole torrent when the user stops watching
-	// Also, the torrent might be a batch - so we don't want to download the whole thing
-	if r.client.currentTorrent.IsPresent() {
-		if r.client.currentTorrentStatus.ProgressPercentage < 70 {
-			r.client.repository.logger.Debug().Msg("torrentstream: Dropping torrent, completion is less than 70%")
-			r.client.dropTorrents()
-		}
-		r.client.repository.logger.Debug().Msg("torrentstream: Resetting current torrent and status")
+	for _, cs := range sessions {
+		r.stopSession(cs)
+	}
+	if len(sessions) == 0 {
+		r.sendStateEventTo(clientId, eventTorrentStopped, nil) // Send torrent stopped event
 	}
-	r.client.currentTorrent = mo.None[*torrent.Torrent]()        // Reset the current torrent
-	r.client.currentFile = mo.None[*torrent.File]()              // Reset the current file
-	r.client.currentTorrentStatus = TorrentStatus{}              // Reset the torrent status
-	r.client.repository.sendStateEvent(eventTorrentStopped, nil) // Send torrent stopped event
-	r.client.repository.mediaPlayerRepository.Stop()             // Stop the media player gracefully if it's running
 	r.client.mu.Unlock()
 
-	go func() {
-		r.nativePlayer.Stop()
-	}()
+	go r.evictCache()
 
 	r.logger.Info().Msg("torrentstream: Stream stopped")
 
 	return nil
 }
 
+// stopSession ends the stream of a client.
+// Caller should lock the client.
+func (r *Repository) stopSession(cs *session) {
+	r.logger.Debug().Str("clientId", cs.clientId).Msg("torrentstream: Stopping session")
+
+	if r.client.isSessionActive(cs) {
+		r.client.sessions.Delete(cs.clientId)
+	}
+
+	r.releaseSession(cs)
+
+	r.sendStateEventTo(cs.clientId, eventTorrentStopped, nil) // Send torrent stopped event
+
+	switch cs.opts.PlaybackType {
+	case PlaybackTypeExternal:
+		r.mediaPlayerRepository.Stop() // Stop the media player gracefully if it's running
+	case PlaybackTypeNativePlayer:
+		go r.nativePlayer.Stop(cs.clientId)
+	}
+}
+
+// releaseSession drops the torrent of a session that was removed.
+// Caller should lock the client.
+func (r *Repository) releaseSession(cs *session) {
+	// Pieces that no other session needs go back to normal priority
+	cs.prefetcher.release()
+
+	// This is to prevent the client from downloading the whole torrent when the user stops watching
+	// Also, the torrent might be a batch - so we don't want to download the whole thing
+	if cs.getStatus().ProgressPercentage < 70 {
+		// The data of cached torrents is kept, see [Repository.evictCache]
+		// The torrent is kept if another client is streaming it
+		r.logger.Debug().Msg("torrentstream: Dropping torrent, completion is less than 70%")
+		r.client.dropTorrent(cs.torrent, cs.file)
+	}
+}
+
 func (r *Repository) DropTorrent() error {
 	r.logger.Info().Msg("torrentstream: Dropping last torrent")
 
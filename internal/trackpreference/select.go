@@ -0,0 +1,222 @@
+package trackpreference
+
+import (
+	"seanime/internal/mediastream/videofile"
+	"seanime/internal/mkvparser"
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+	"golang.org/x/text/language"
+)
+
+type (
+	// Track is an audio or subtitle track of a file, regardless of how the file was probed.
+	Track struct {
+		// Identifies the track in its source, i.e. the track number of an MKV file or the index of a track in videofile.MediaInfo
+		Index    int
+		Language string
+		Name     string
+		Default  bool
+		Forced   bool
+	}
+
+	// Selection is the result of the evaluation of a preference against the tracks of a file.
+	Selection struct {
+		// Index of the audio track to play, nil to keep the default track of the file
+		AudioIndex *int `json:"audioIndex,omitempty"`
+		// Index of the subtitle track to display, nil to keep the default track of the file
+		SubtitleIndex *int `json:"subtitleIndex,omitempty"`
+		// Subtitles should not be displayed
+		SubtitlesDisabled bool `json:"subtitlesDisabled"`
+	}
+)
+
+// Select picks the audio and subtitle tracks matching the preference.
+func (p *Preference) Select(audios []*Track, subtitles []*Track) *Selection {
+	ret := &Selection{}
+	if p == nil {
+		return ret
+	}
+
+	if audio, ok := selectByLanguage(audios, p.AudioLanguages); ok {
+		ret.AudioIndex = &audio.Index
+	}
+
+	if p.DisableSubtitles {
+		ret.SubtitlesDisabled = true
+		return ret
+	}
+
+	if subtitle, ok := p.selectSubtitle(subtitles); ok {
+		ret.SubtitleIndex = &subtitle.Index
+	}
+
+	return ret
+}
+
+// selectByLanguage returns the track in the language with the highest priority.
+// Default tracks are preferred when several tracks have the same language.
+func selectByLanguage(tracks []*Track, languages []string) (*Track, bool) {
+	for _, lang := range languages {
+		candidates := lo.Filter(tracks, func(t *Track, _ int) bool { return IsSameLanguage(t.Language, lang) })
+		if len(candidates) == 0 {
+			continue
+		}
+		if t, found := lo.Find(candidates, func(t *Track) bool { return t.Default }); found {
+			return t, true
+		}
+		return candidates[0], true
+	}
+	return nil, false
+}
+
+func (p *Preference) selectSubtitle(subtitles []*Track) (*Track, bool) {
+	candidates := subtitles
+	if len(p.SubtitleLanguages) > 0 {
+		candidates = nil
+		for _, lang := range p.SubtitleLanguages {
+			candidates = lo.Filter(subtitles, func(t *Track, _ int) bool { return IsSameLanguage(t.Language, lang) })
+			if len(candidates) > 0 {
+				break
+			}
+		}
+	} else if p.SubtitleType == SubtitleTypeAny && !p.AvoidForcedSubtitles {
+		// Nothing to choose from
+		return nil, false
+	}
+
+	if p.AvoidForcedSubtitles {
+		// Forced tracks are only kept if there is nothing else
+		if unforced := lo.Filter(candidates, func(t *Track, _ int) bool { return !t.Forced }); len(unforced) > 0 {
+			candidates = unforced
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, false
+	}
+
+	// Sort the candidates by how well they match the preferred subtitle type, then by their default flag
+	candidates = slices.Clone(candidates)
+	slices.SortStableFunc(candidates, func(a, b *Track) int {
+		if sa, sb := p.subtitleTypeScore(a), p.subtitleTypeScore(b); sa != sb {
+			return sb - sa
+		}
+		if a.Default != b.Default {
+			return lo.Ternary(a.Default, -1, 1)
+		}
+		return 0
+	})
+
+	return candidates[0], true
+}
+
+func (p *Preference) subtitleTypeScore(t *Track) int {
+	switch p.SubtitleType {
+	case SubtitleTypeSigns:
+		return lo.Ternary(IsSignsTrack(t), 1, 0)
+	case SubtitleTypeFull:
+		return lo.Ternary(IsSignsTrack(t), 0, 1)
+	}
+	return 0
+}
+
+var signsKeywords = []string{"sign", "song", "s&s", "forced", "karaoke"}
+
+// IsSignsTrack returns true if the subtitle track only contains signs and songs.
+func IsSignsTrack(t *Track) bool {
+	name := strings.ToLower(t.Name)
+	if strings.Contains(name, "full") || strings.Contains(name, "dialog") {
+		return false
+	}
+	if t.Forced {
+		return true
+	}
+	return lo.SomeBy(signsKeywords, func(k string) bool { return strings.Contains(name, k) })
+}
+
+// IsSameLanguage returns true if both language codes refer to the same language, e.g. "ja", "jpn" and "ja-JP".
+func IsSameLanguage(a string, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	baseA, okA := parseLanguage(a)
+	baseB, okB := parseLanguage(b)
+	if okA && okB {
+		return baseA == baseB
+	}
+	// Unknown codes are compared as they are
+	return !okA && !okB && strings.EqualFold(a, b) && !strings.EqualFold(a, "und")
+}
+
+// LanguageCodes returns the ISO 639-1 and ISO 639-2 codes of a language, e.g. "ja" and "jpn".
+// Media players match the language tags of the file as they are, so both forms are needed.
+func LanguageCodes(lang string) []string {
+	base, ok := parseLanguage(lang)
+	if !ok {
+		return []string{lang}
+	}
+	return lo.Uniq([]string{base.String(), base.ISO3()})
+}
+
+func parseLanguage(lang string) (language.Base, bool) {
+	tag, err := language.Parse(lang)
+	// The base of an undetermined tag is guessed, it should not match anything
+	if err != nil || tag == language.Und {
+		return language.Base{}, false
+	}
+	base, confidence := tag.Base()
+	if confidence == language.No {
+		return language.Base{}, false
+	}
+	return base, true
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// FromMkvTracks converts the tracks parsed by mkvparser, the index of a track is its track number.
+func FromMkvTracks(tracks []*mkvparser.TrackInfo) []*Track {
+	return lo.Map(tracks, func(t *mkvparser.TrackInfo, _ int) *Track {
+		return &Track{
+			Index:    int(t.Number),
+			Language: lo.CoalesceOrEmpty(t.LanguageIETF, t.Language),
+			Name:     t.Name,
+			Default:  t.Default,
+			Forced:   t.Forced,
+		}
+	})
+}
+
+func FromMediaInfoAudios(audios []videofile.Audio) []*Track {
+	return lo.Map(audios, func(a videofile.Audio, _ int) *Track {
+		return &Track{
+			Index:    int(a.Index),
+			Language: lo.FromPtr(a.Language),
+			Name:     lo.FromPtr(a.Title),
+			Default:  a.IsDefault,
+			Forced:   a.IsForced,
+		}
+	})
+}
+
+func FromMediaInfoSubtitles(subtitles []videofile.Subtitle) []*Track {
+	return lo.Map(subtitles, func(s videofile.Subtitle, _ int) *Track {
+		return &Track{
+			Index:    int(s.Index),
+			Language: lo.FromPtr(s.Language),
+			Name:     lo.FromPtr(s.Title),
+			Default:  s.IsDefault,
+			Forced:   s.IsForced,
+		}
+	})
+}
+
+// Position returns the position of the track with the given index, -1 if not found.
+// Media players identify tracks by their position among the tracks of the same type.
+func Position(tracks []*Track, index *int) int {
+	if index == nil {
+		return -1
+	}
+	return slices.IndexFunc(tracks, func(t *Track) bool { return t.Index == *index })
+}
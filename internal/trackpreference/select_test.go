@@ -0,0 +1,130 @@
+package trackpreference
+
+import (
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testAudios = []*Track{
+		{Index: 1, Language: "eng", Name: "English", Default: true},
+		{Index: 2, Language: "jpn", Name: "Japanese"},
+	}
+	testSubtitles = []*Track{
+		{Index: 3, Language: "eng", Name: "Signs & Songs", Default: true},
+		{Index: 4, Language: "eng", Name: "Full Subs"},
+		{Index: 5, Language: "eng", Name: "English", Forced: true},
+		{Index: 6, Language: "spa", Name: "Español"},
+	}
+)
+
+func TestPreference_Select(t *testing.T) {
+	tests := []struct {
+		name              string
+		pref              *Preference
+		expectedAudio     *int
+		expectedSubtitle  *int
+		subtitlesDisabled bool
+	}{
+		{
+			name: "Japanese audio with full English subtitles",
+			pref: &Preference{
+				AudioLanguages:    []string{"ja"},
+				SubtitleLanguages: []string{"en"},
+				SubtitleType:      SubtitleTypeFull,
+			},
+			expectedAudio:    lo.ToPtr(2),
+			expectedSubtitle: lo.ToPtr(4),
+		},
+		{
+			name: "English dub with signs and songs",
+			pref: &Preference{
+				AudioLanguages:    []string{"eng"},
+				SubtitleLanguages: []string{"eng"},
+				SubtitleType:      SubtitleTypeSigns,
+			},
+			expectedAudio:    lo.ToPtr(1),
+			expectedSubtitle: lo.ToPtr(3),
+		},
+		{
+			name: "Language priority falls back to the next language",
+			pref: &Preference{
+				AudioLanguages:    []string{"fre", "jpn"},
+				SubtitleLanguages: []string{"ger", "es"},
+			},
+			expectedAudio:    lo.ToPtr(2),
+			expectedSubtitle: lo.ToPtr(6),
+		},
+		{
+			name: "Default track is kept when the type does not matter",
+			pref: &Preference{
+				SubtitleLanguages: []string{"eng"},
+			},
+			expectedSubtitle: lo.ToPtr(3),
+		},
+		{
+			name: "No matching language keeps the default tracks",
+			pref: &Preference{
+				AudioLanguages:    []string{"kor"},
+				SubtitleLanguages: []string{"kor"},
+			},
+		},
+		{
+			name: "Subtitles disabled",
+			pref: &Preference{
+				AudioLanguages:   []string{"jpn"},
+				DisableSubtitles: true,
+			},
+			expectedAudio:     lo.ToPtr(2),
+			subtitlesDisabled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sel := tt.pref.Select(testAudios, testSubtitles)
+			require.NotNil(t, sel)
+			assert.Equal(t, tt.expectedAudio, sel.AudioIndex)
+			assert.Equal(t, tt.expectedSubtitle, sel.SubtitleIndex)
+			assert.Equal(t, tt.subtitlesDisabled, sel.SubtitlesDisabled)
+		})
+	}
+}
+
+func TestPreference_Select_AvoidForced(t *testing.T) {
+	subtitles := []*Track{
+		{Index: 1, Language: "eng", Name: "English", Forced: true, Default: true},
+		{Index: 2, Language: "eng", Name: "English"},
+	}
+
+	sel := (&Preference{SubtitleLanguages: []string{"eng"}, AvoidForcedSubtitles: true}).Select(nil, subtitles)
+	assert.Equal(t, lo.ToPtr(2), sel.SubtitleIndex)
+
+	// Forced tracks are selected if there is nothing else
+	sel = (&Preference{SubtitleLanguages: []string{"eng"}, AvoidForcedSubtitles: true}).Select(nil, subtitles[:1])
+	assert.Equal(t, lo.ToPtr(1), sel.SubtitleIndex)
+}
+
+func TestIsSameLanguage(t *testing.T) {
+	assert.True(t, IsSameLanguage("ja", "jpn"))
+	assert.True(t, IsSameLanguage("ja-JP", "jpn"))
+	assert.True(t, IsSameLanguage("ENG", "en"))
+	assert.True(t, IsSameLanguage("ger", "deu"))
+	assert.False(t, IsSameLanguage("jpn", "eng"))
+	assert.False(t, IsSameLanguage("und", "und"))
+	assert.False(t, IsSameLanguage("", "eng"))
+}
+
+func TestLanguageCodes(t *testing.T) {
+	assert.Equal(t, []string{"ja", "jpn"}, LanguageCodes("jpn"))
+	assert.Equal(t, []string{"en", "eng"}, LanguageCodes("en"))
+}
+
+func TestPosition(t *testing.T) {
+	assert.Equal(t, 1, Position(testAudios, lo.ToPtr(2)))
+	assert.Equal(t, -1, Position(testAudios, lo.ToPtr(9)))
+	assert.Equal(t, -1, Position(testAudios, nil))
+}
@@ -0,0 +1,291 @@
+package trackpreference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"seanime/internal/database/db"
+	"seanime/internal/database/db_bridge"
+	"seanime/internal/database/models"
+	"seanime/internal/mediastream/videofile"
+	"seanime/internal/mkvparser"
+	"seanime/internal/util"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/samber/lo"
+)
+
+const (
+	SubtitleTypeAny   SubtitleType = ""
+	SubtitleTypeFull  SubtitleType = "full"  // Full subtitles (dialogue, signs and songs)
+	SubtitleTypeSigns SubtitleType = "signs" // Signs & songs only, for dubs
+)
+
+type (
+	SubtitleType string
+
+	// Preference holds the rules used to select the audio and subtitle tracks of an episode.
+	Preference struct {
+		// AniList username of the user the preference applies to, empty for every user
+		Username string `json:"username"`
+		// 0 for the default preference
+		MediaId int `json:"mediaId"`
+		// Language codes sorted by priority, e.g. ["jpn", "eng"]
+		AudioLanguages    []string     `json:"audioLanguages"`
+		SubtitleLanguages []string     `json:"subtitleLanguages"`
+		SubtitleType      SubtitleType `json:"subtitleType"`
+		// Forced subtitle tracks are only selected if there are no other tracks
+		AvoidForcedSubtitles bool `json:"avoidForcedSubtitles"`
+		DisableSubtitles     bool `json:"disableSubtitles"`
+	}
+
+	// Manager stores the track preferences and evaluates them against the tracks of the files being played.
+	// The preference of a media overrides the default preference, and the preferences of the logged-in user override the ones of every user.
+	Manager struct {
+		logger *zerolog.Logger
+		db     *db.Database
+
+		mu       sync.RWMutex
+		username string
+	}
+
+	NewManagerOptions struct {
+		Logger   *zerolog.Logger
+		Database *db.Database
+	}
+)
+
+func NewManager(opts *NewManagerOptions) *Manager {
+	return &Manager{
+		logger: opts.Logger,
+		db:     opts.Database,
+	}
+}
+
+func IsValidSubtitleType(t SubtitleType) bool {
+	return t == SubtitleTypeAny || t == SubtitleTypeFull || t == SubtitleTypeSigns
+}
+
+// SetUsername should be called when the user logs in or out, the preferences of the user are used afterwards.
+func (m *Manager) SetUsername(username string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.username = username
+}
+
+func (m *Manager) getUsername() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.username
+}
+
+// GetPreference returns the preference of the media, or the default preference if the media has none.
+// The preferences of the logged-in user are checked before the ones of every user.
+// It returns nil if no preference has been set.
+func (m *Manager) GetPreference(mediaId int) *Preference {
+	if m == nil {
+		return nil
+	}
+
+	usernames := []string{""}
+	if username := m.getUsername(); username != "" {
+		usernames = []string{username, ""}
+	}
+
+	for _, id := range lo.Uniq([]int{mediaId, 0}) {
+		for _, username := range usernames {
+			if pref, found := m.db.GetTrackPreference(username, id); found {
+				return fromModel(pref)
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetPreferences returns the default preference and the preferences of each media.
+func (m *Manager) GetPreferences() ([]*Preference, error) {
+	prefs, err := m.db.GetTrackPreferences()
+	if err != nil {
+		return nil, err
+	}
+	return lo.Map(prefs, func(p *models.TrackPreference, _ int) *Preference { return fromModel(p) }), nil
+}
+
+// SavePreference saves the preference of a media, or the default preference if the media ID is 0.
+// The preference applies to every user if the username is empty.
+// Language codes are normalized to ISO 639-2 codes.
+func (m *Manager) SavePreference(pref *Preference) (*Preference, error) {
+	if !IsValidSubtitleType(pref.SubtitleType) {
+		return nil, errors.New("invalid subtitle type")
+	}
+
+	audioLanguages, err := normalizeLanguages(pref.AudioLanguages)
+	if err != nil {
+		return nil, err
+	}
+	subtitleLanguages, err := normalizeLanguages(pref.SubtitleLanguages)
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := m.db.UpsertTrackPreference(&models.TrackPreference{
+		Username:             pref.Username,
+		MediaID:              pref.MediaId,
+		AudioLanguages:       strings.Join(audioLanguages, ","),
+		SubtitleLanguages:    strings.Join(subtitleLanguages, ","),
+		SubtitleType:         string(pref.SubtitleType),
+		AvoidForcedSubtitles: pref.AvoidForcedSubtitles,
+		DisableSubtitles:     pref.DisableSubtitles,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.logger.Debug().Int("mediaId", pref.MediaId).Str("username", pref.Username).Msg("track preference: Saved preference")
+
+	return fromModel(saved), nil
+}
+
+func (m *Manager) DeletePreference(username string, mediaId int) error {
+	return m.db.DeleteTrackPreference(username, mediaId)
+}
+
+// SelectMkvTracks evaluates the preference of the media against the tracks of an MKV file.
+// The indexes of the selection are track numbers.
+func (m *Manager) SelectMkvTracks(mediaId int, metadata *mkvparser.Metadata) *Selection {
+	pref := m.GetPreference(mediaId)
+	if pref == nil || metadata == nil {
+		return nil
+	}
+	return pref.Select(FromMkvTracks(metadata.AudioTracks), FromMkvTracks(metadata.SubtitleTracks))
+}
+
+// SelectMediaInfoTracks evaluates the preference of the media against the tracks probed by FFprobe.
+// The indexes of the selection are the indexes of the tracks in the media info.
+func (m *Manager) SelectMediaInfoTracks(mediaId int, mediaInfo *videofile.MediaInfo) *Selection {
+	pref := m.GetPreference(mediaId)
+	if pref == nil || mediaInfo == nil {
+		return nil
+	}
+	return pref.Select(FromMediaInfoAudios(mediaInfo.Audios), FromMediaInfoSubtitles(mediaInfo.Subtitles))
+}
+
+// GetMediaIdByPath returns the media ID of a local file, 0 if the file is not in the library.
+func (m *Manager) GetMediaIdByPath(path string) int {
+	if m == nil {
+		return 0
+	}
+	lfs, _, err := db_bridge.GetLocalFiles(m.db)
+	if err != nil {
+		return 0
+	}
+	normalizedPath := util.NormalizePath(path)
+	for _, lf := range lfs {
+		if lf.GetNormalizedPath() == normalizedPath {
+			return lf.MediaId
+		}
+	}
+	return 0
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// PlayerTracks is the selection translated for media players that are launched with options (mpv, VLC).
+type PlayerTracks struct {
+	// Preferred languages, both ISO 639-1 and ISO 639-2 codes are included
+	AudioLanguages    []string
+	SubtitleLanguages []string
+	// Position of the audio/subtitle track among the tracks of the same type, -1 to let the player choose
+	AudioPosition    int
+	SubtitlePosition int
+	// Subtitles should not be displayed
+	SubtitlesDisabled bool
+}
+
+// GetPlayerTracks returns the tracks a media player should select for a file.
+// The tracks are only read from MKV files, the player selects the tracks by language for other files and streams.
+// Pass an empty path for streams.
+func (m *Manager) GetPlayerTracks(mediaId int, path string) *PlayerTracks {
+	pref := m.GetPreference(mediaId)
+	if pref == nil {
+		return nil
+	}
+
+	ret := &PlayerTracks{
+		AudioLanguages:    lo.FlatMap(pref.AudioLanguages, func(l string, _ int) []string { return LanguageCodes(l) }),
+		SubtitleLanguages: lo.FlatMap(pref.SubtitleLanguages, func(l string, _ int) []string { return LanguageCodes(l) }),
+		AudioPosition:     -1,
+		SubtitlePosition:  -1,
+		SubtitlesDisabled: pref.DisableSubtitles,
+	}
+
+	if path == "" || !strings.EqualFold(filepath.Ext(path), ".mkv") {
+		return ret
+	}
+
+	metadata, err := m.readMkvMetadata(path)
+	if err != nil {
+		m.logger.Warn().Err(err).Str("path", path).Msg("track preference: Could not read the tracks of the file")
+		return ret
+	}
+
+	audios, subtitles := FromMkvTracks(metadata.AudioTracks), FromMkvTracks(metadata.SubtitleTracks)
+	selection := pref.Select(audios, subtitles)
+	ret.AudioPosition = Position(audios, selection.AudioIndex)
+	ret.SubtitlePosition = Position(subtitles, selection.SubtitleIndex)
+
+	return ret
+}
+
+func (m *Manager) readMkvMetadata(path string) (*mkvparser.Metadata, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	metadata := mkvparser.NewMetadataParser(f, m.logger).GetMetadata(ctx)
+	if metadata.Error != nil {
+		return nil, metadata.Error
+	}
+	return metadata, nil
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func normalizeLanguages(languages []string) ([]string, error) {
+	ret := make([]string, 0, len(languages))
+	for _, lang := range languages {
+		lang = strings.TrimSpace(lang)
+		if lang == "" {
+			continue
+		}
+		base, ok := parseLanguage(lang)
+		if !ok {
+			return nil, fmt.Errorf("unknown language: %s", lang)
+		}
+		ret = append(ret, base.ISO3())
+	}
+	return lo.Uniq(ret), nil
+}
+
+func fromModel(p *models.TrackPreference) *Preference {
+	return &Preference{
+		Username:             p.Username,
+		MediaId:              p.MediaID,
+		AudioLanguages:       lo.Compact(strings.Split(p.AudioLanguages, ",")),
+		SubtitleLanguages:    lo.Compact(strings.Split(p.SubtitleLanguages, ",")),
+		SubtitleType:         SubtitleType(p.SubtitleType),
+		AvoidForcedSubtitles: p.AvoidForcedSubtitles,
+		DisableSubtitles:     p.DisableSubtitles,
+	}
+}
@@ -0,0 +1,54 @@
+package trackpreference
+
+import (
+	"seanime/internal/database/db"
+	"seanime/internal/util"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPreference(t *testing.T) {
+	logger := util.NewLogger()
+	database, err := db.NewDatabase(t.TempDir(), "seanime-test", logger)
+	require.NoError(t, err)
+
+	m := NewManager(&NewManagerOptions{Logger: logger, Database: database})
+
+	save := func(username string, mediaId int, audio string) {
+		_, err := m.SavePreference(&Preference{Username: username, MediaId: mediaId, AudioLanguages: []string{audio}})
+		require.NoError(t, err)
+	}
+	save("", 0, "jpn")
+	save("", 1, "eng")
+	save("alice", 0, "fra")
+	save("alice", 2, "deu")
+
+	tests := []struct {
+		name     string
+		username string
+		mediaId  int
+		expected string
+	}{
+		{name: "default", username: "", mediaId: 3, expected: "jpn"},
+		{name: "media", username: "", mediaId: 1, expected: "eng"},
+		{name: "user default", username: "alice", mediaId: 3, expected: "fra"},
+		{name: "user media", username: "alice", mediaId: 2, expected: "deu"},
+		{name: "media overrides user default", username: "alice", mediaId: 1, expected: "eng"},
+		{name: "other user", username: "bob", mediaId: 2, expected: "jpn"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m.SetUsername(tt.username)
+			pref := m.GetPreference(tt.mediaId)
+			require.NotNil(t, pref)
+			assert.Equal(t, []string{tt.expected}, pref.AudioLanguages)
+		})
+	}
+
+	require.NoError(t, m.DeletePreference("alice", 2))
+	m.SetUsername("alice")
+	assert.Equal(t, []string{"fra"}, m.GetPreference(2).AudioLanguages)
+}
@@ -0,0 +1,18 @@
+package torrentutil
+
+// PreBufferThreshold is the completion of the current episode after which the next one is pre-buffered.
+const PreBufferThreshold = 0.8
+
+// ShouldPreBufferNextEpisode returns true if the playback position is past the pre-buffer threshold.
+func ShouldPreBufferNextEpisode(position, duration float64) bool {
+	return duration > 0 && position/duration >= PreBufferThreshold
+}
+
+// NextEpisodeNumber returns the episode after the current one, if it has aired.
+// currentEpisodeCount is -1 if unknown.
+func NextEpisodeNumber(episodeNumber int, currentEpisodeCount int) (int, bool) {
+	if currentEpisodeCount >= 0 && episodeNumber >= currentEpisodeCount {
+		return 0, false
+	}
+	return episodeNumber + 1, true
+}
@@ -0,0 +1,28 @@
+package torrentutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextEpisodeNumber(t *testing.T) {
+	next, ok := NextEpisodeNumber(3, 12)
+	assert.True(t, ok)
+	assert.Equal(t, 4, next)
+
+	_, ok = NextEpisodeNumber(12, 12)
+	assert.False(t, ok)
+
+	// Unknown episode count
+	next, ok = NextEpisodeNumber(50, -1)
+	assert.True(t, ok)
+	assert.Equal(t, 51, next)
+}
+
+func TestShouldPreBufferNextEpisode(t *testing.T) {
+	assert.False(t, ShouldPreBufferNextEpisode(100, 1400))
+	assert.True(t, ShouldPreBufferNextEpisode(1200, 1400))
+	// Unknown duration
+	assert.False(t, ShouldPreBufferNextEpisode(1200, 0))
+}
@@ -15,10 +15,15 @@ import type {
     Anime_LocalFileMetadata,
     ChapterDownloader_DownloadID,
     Continuity_UpdateWatchHistoryItemOptions,
+    Continuity_WatchHistoryExport,
     Debrid_TorrentItem,
     DebridClient_CancelStreamOptions,
     DebridClient_StreamPlaybackType,
     HibikeTorrent_AnimeTorrent,
+    Listexport_Format,
+    Listexport_MediaType,
+    Local_OfflineDownloadSettings,
+    Local_SyncSettings,
     Mediastream_StreamType,
     Models_AnilistSettings,
     Models_DebridSettings,
@@ -33,12 +38,20 @@ import type {
     Models_TorrentSettings,
     Models_TorrentstreamSettings,
     Nakama_WatchPartySessionSettings,
+    Playbackqueue_Item,
+    Playbackqueue_ItemType,
+    Playbackqueue_OnlineStreamOptions,
+    Playbackqueue_PlayerType,
+    Playbackqueue_WatchOrder,
     Report_ClickLog,
     Report_ConsoleLog,
     Report_NetworkLog,
     Report_ReactQueryLog,
     RunPlaygroundCodeParams,
+    Scrobbler_TrackerName,
+    Skipsegments_SegmentType,
     Torrentstream_PlaybackType,
+    Trackpreference_Preference,
 } from "@/api/generated/types.ts"
 
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -420,6 +433,56 @@ export type GetContinuityWatchHistoryItem_Variables = {
     id: number
 }
 
+/**
+ * - Filepath: internal/handlers/continuity.go
+ * - Filename: continuity.go
+ * - Endpoint: /api/v1/continuity/events
+ * @description
+ * Route returns the watch event log.
+ */
+export type GetContinuityWatchEvents_Variables = {
+    mediaId: number
+    from?: string
+    to?: string
+}
+
+/**
+ * - Filepath: internal/handlers/continuity.go
+ * - Filename: continuity.go
+ * - Endpoint: /api/v1/continuity/events/{id}
+ * @description
+ * Route deletes an event from the watch event log.
+ */
+export type DeleteContinuityWatchEvent_Variables = {
+    /**
+     *  The watch event ID
+     */
+    id: number
+}
+
+/**
+ * - Filepath: internal/handlers/continuity.go
+ * - Filename: continuity.go
+ * - Endpoint: /api/v1/continuity/stats
+ * @description
+ * Route returns statistics computed from the watch event log.
+ */
+export type GetContinuityWatchStats_Variables = {
+    from?: string
+    to?: string
+}
+
+/**
+ * - Filepath: internal/handlers/continuity.go
+ * - Filename: continuity.go
+ * - Endpoint: /api/v1/continuity/import
+ * @description
+ * Route imports an exported watch history.
+ */
+export type ImportContinuityWatchHistory_Variables = {
+    export: Continuity_WatchHistoryExport
+}
+
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 // debrid
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -813,6 +876,55 @@ export type RemoveFileCacheBucket_Variables = {
     bucket: string
 }
 
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// list_export
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+/**
+ * - Filepath: internal/handlers/list_export.go
+ * - Filename: list_export.go
+ * - Endpoint: /api/v1/lists/export
+ * @description
+ * Route exports the anime or manga lists of the current account.
+ * The lists of the simulated account are exported when no account is connected.
+ * Format is "json" or "mal-xml". MyAnimeList XML files only contain the media of the given type ("anime" or "manga").
+ */
+export type ExportLists_Variables = {
+    format: Listexport_Format
+    type: Listexport_MediaType
+}
+
+/**
+ * - Filepath: internal/handlers/list_export.go
+ * - Filename: list_export.go
+ * - Endpoint: /api/v1/lists/import
+ * @description
+ * Route imports exported anime and manga lists into the current account.
+ * Format is "json" or "mal-xml". Entries of MyAnimeList exports are matched to AniList media.
+ * The entries are imported in the background, returns the number of entries found in the file.
+ */
+export type ImportLists_Variables = {
+    format: Listexport_Format
+    content: string
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// list_sync
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+/**
+ * - Filepath: internal/handlers/list_sync.go
+ * - Filename: list_sync.go
+ * - Endpoint: /api/v1/settings/list-sync
+ * @description
+ * Route updates the AniList and MyAnimeList synchronization settings.
+ * Origin is "anilist", "mal" or empty to keep the most recent change when both lists changed.
+ */
+export type SaveListSyncSettings_Variables = {
+    automatic: boolean
+    origin: string
+}
+
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 // local
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -880,6 +992,41 @@ export type LocalSetHasLocalChanges_Variables = {
     updated: boolean
 }
 
+/**
+ * - Filepath: internal/handlers/local.go
+ * - Filename: local.go
+ * - Endpoint: /api/v1/local/offline-downloads/settings
+ * @description
+ * Route updates the settings of the pre-download of upcoming episodes of tracked anime.
+ */
+export type LocalSaveOfflineDownloadSettings_Variables = {
+    settings?: Local_OfflineDownloadSettings
+}
+
+/**
+ * - Filepath: internal/handlers/local.go
+ * - Filename: local.go
+ * - Endpoint: /api/v1/local/sync/settings
+ * @description
+ * Route updates the sync profile used when synchronizing tracked media.
+ */
+export type LocalSaveSyncSettings_Variables = {
+    settings?: Local_SyncSettings
+}
+
+/**
+ * - Filepath: internal/handlers/local.go
+ * - Filename: local.go
+ * - Endpoint: /api/v1/local/track/priority
+ * @description
+ * Route sets the sync priority of a tracked media.
+ */
+export type LocalSetTrackedMediaPriority_Variables = {
+    mediaId: number
+    type: string
+    priority: number
+}
+
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 // localfiles
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -974,6 +1121,17 @@ export type EditMALListEntryProgress_Variables = {
     progress?: number
 }
 
+/**
+ * - Filepath: internal/handlers/mal.go
+ * - Filename: mal.go
+ * - Endpoint: /api/v1/mal/account-platform
+ * @description
+ * Route sets whether MyAnimeList is used as the account platform.
+ */
+export type SetMALAccountPlatform_Variables = {
+    enabled: boolean
+}
+
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 // manga
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -1254,6 +1412,17 @@ export type PreloadMediastreamMediaContainer_Variables = {
     audioStreamIndex: number
 }
 
+/**
+ * - Filepath: internal/handlers/mediastream.go
+ * - Filename: mediastream.go
+ * - Endpoint: /api/v1/mediastream/trickplay
+ * @description
+ * Route generates the seek preview thumbnails of the local files of an anime.
+ */
+export type GenerateMediastreamTrickplay_Variables = {
+    mediaId: number
+}
+
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 // metadata
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -1488,6 +1657,114 @@ export type PlaybackStartManualTracking_Variables = {
     clientId: string
 }
 
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// playback_queue
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+/**
+ * - Filepath: internal/handlers/playback_queue.go
+ * - Filename: playback_queue.go
+ * - Endpoint: /api/v1/playback-queue/items
+ * @description
+ * Route adds items to the playback queue.
+ */
+export type PlaybackQueueAddItems_Variables = {
+    items: Array<Playbackqueue_Item>
+    index?: number
+    next: boolean
+}
+
+/**
+ * - Filepath: internal/handlers/playback_queue.go
+ * - Filename: playback_queue.go
+ * - Endpoint: /api/v1/playback-queue/items
+ * @description
+ * Route removes an item from the playback queue.
+ */
+export type PlaybackQueueRemoveItem_Variables = {
+    itemId: string
+}
+
+/**
+ * - Filepath: internal/handlers/playback_queue.go
+ * - Filename: playback_queue.go
+ * - Endpoint: /api/v1/playback-queue/items/move
+ * @description
+ * Route moves an item of the playback queue to the given index.
+ */
+export type PlaybackQueueMoveItem_Variables = {
+    itemId: string
+    index: number
+}
+
+/**
+ * - Filepath: internal/handlers/playback_queue.go
+ * - Filename: playback_queue.go
+ * - Endpoint: /api/v1/playback-queue/play
+ * @description
+ * Route plays an item of the playback queue.
+ */
+export type PlaybackQueuePlay_Variables = {
+    itemId: string
+    player: Playbackqueue_PlayerType
+    clientId: string
+}
+
+/**
+ * - Filepath: internal/handlers/playback_queue.go
+ * - Filename: playback_queue.go
+ * - Endpoint: /api/v1/playback-queue/watch-order
+ * @description
+ * Route returns the episodes of the franchise of a media in the given watch order.
+ */
+export type PlaybackQueueGetWatchOrder_Variables = {
+    mediaId: number
+    order: Playbackqueue_WatchOrder
+    fallback: Playbackqueue_ItemType
+    onlineStream?: Playbackqueue_OnlineStreamOptions
+    replace: boolean
+}
+
+/**
+ * - Filepath: internal/handlers/playback_queue.go
+ * - Filename: playback_queue.go
+ * - Endpoint: /api/v1/playback-queue/playlists
+ * @description
+ * Route saves the items of the playback queue as a playlist.
+ */
+export type SavePlaybackQueuePlaylist_Variables = {
+    dbId: number
+    name: string
+}
+
+/**
+ * - Filepath: internal/handlers/playback_queue.go
+ * - Filename: playback_queue.go
+ * - Endpoint: /api/v1/playback-queue/playlists/{id}/load
+ * @description
+ * Route replaces the items of the playback queue with a saved playlist.
+ */
+export type LoadPlaybackQueuePlaylist_Variables = {
+    /**
+     *  Playlist database ID
+     */
+    id: number
+}
+
+/**
+ * - Filepath: internal/handlers/playback_queue.go
+ * - Filename: playback_queue.go
+ * - Endpoint: /api/v1/playback-queue/playlists/{id}
+ * @description
+ * Route deletes a saved playback queue playlist.
+ */
+export type DeletePlaybackQueuePlaylist_Variables = {
+    /**
+     *  Playlist database ID
+     */
+    id: number
+}
+
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 // playlist
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -1546,6 +1823,69 @@ export type GetPlaylistEpisodes_Variables = {
     progress: number
 }
 
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// profiles
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+/**
+ * - Filepath: internal/handlers/profiles.go
+ * - Filename: profiles.go
+ * - Endpoint: /api/v1/profiles
+ * @description
+ * Route creates a profile.
+ * The profile is not logged in to AniList, it uses its own simulated collections until an account is connected.
+ * The PIN is optional and must be made of 4 to 8 digits.
+ */
+export type CreateProfile_Variables = {
+    name: string
+    pin: string
+}
+
+/**
+ * - Filepath: internal/handlers/profiles.go
+ * - Filename: profiles.go
+ * - Endpoint: /api/v1/profiles
+ * @description
+ * Route updates the name or the PIN of a profile.
+ * The current PIN is required if the profile is protected.
+ * An empty PIN removes the protection, a null PIN keeps the current PIN.
+ * The main profile cannot be protected since it is used by clients that have not selected a profile.
+ */
+export type UpdateProfile_Variables = {
+    id: number
+    name: string
+    pin?: string
+    currentPin: string
+}
+
+/**
+ * - Filepath: internal/handlers/profiles.go
+ * - Filename: profiles.go
+ * - Endpoint: /api/v1/profiles
+ * @description
+ * Route deletes a profile with its watch history and simulated collections.
+ * The main profile cannot be deleted, the clients using the deleted profile go back to the main profile.
+ */
+export type DeleteProfile_Variables = {
+    id: number
+    pin: string
+}
+
+/**
+ * - Filepath: internal/handlers/profiles.go
+ * - Filename: profiles.go
+ * - Endpoint: /api/v1/profiles/select
+ * @description
+ * Route selects the profile of the client.
+ * The PIN is required if the profile is protected.
+ * Only the client that sent the request uses the profile: its account, collections, watch history, theme and playback settings.
+ * The library, torrent clients and caches are shared by all profiles.
+ */
+export type SelectProfile_Variables = {
+    id: number
+    pin: string
+}
+
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 // releases
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -1601,6 +1941,27 @@ export type ScanLocalFiles_Variables = {
 // scan_summary
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// scrobbler
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+/**
+ * - Filepath: internal/handlers/scrobbler.go
+ * - Filename: scrobbler.go
+ * - Endpoint: /api/v1/scrobbler/accounts
+ * @description
+ * Route connects a Kitsu, Shikimori or Simkl account.
+ */
+export type ConnectScrobblerAccount_Variables = {
+    tracker: Scrobbler_TrackerName
+    username: string
+    password: string
+    code: string
+    redirectUri: string
+    clientId: string
+    clientSecret: string
+}
+
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 // settings
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -1661,6 +2022,65 @@ export type SaveAutoDownloaderSettings_Variables = {
     useDebrid: boolean
 }
 
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// skip_segments
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+/**
+ * - Filepath: internal/handlers/skip_segments.go
+ * - Filename: skip_segments.go
+ * - Endpoint: /api/v1/skip-segments/{id}/{episodeNumber}
+ * @description
+ * Route returns the skip segments of an episode.
+ */
+export type GetSkipSegments_Variables = {
+    /**
+     *  AniList anime media ID
+     */
+    id: number
+    /**
+     *  Episode number
+     */
+    episodeNumber: number
+}
+
+/**
+ * - Filepath: internal/handlers/skip_segments.go
+ * - Filename: skip_segments.go
+ * - Endpoint: /api/v1/skip-segments
+ * @description
+ * Route saves a skip segment submitted by the user.
+ */
+export type SaveSkipSegment_Variables = {
+    mediaId: number
+    episodeNumber: number
+    type: Skipsegments_SegmentType
+    start: number
+    end: number
+}
+
+/**
+ * - Filepath: internal/handlers/skip_segments.go
+ * - Filename: skip_segments.go
+ * - Endpoint: /api/v1/skip-segments
+ * @description
+ * Route deletes a stored skip segment.
+ */
+export type DeleteSkipSegment_Variables = {
+    id: number
+}
+
+/**
+ * - Filepath: internal/handlers/skip_segments.go
+ * - Filename: skip_segments.go
+ * - Endpoint: /api/v1/skip-segments/detect
+ * @description
+ * Route detects the openings of the downloaded episodes of a media.
+ */
+export type DetectSkipSegments_Variables = {
+    mediaId: number
+}
+
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 // status
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -1820,6 +2240,17 @@ export type TorrentstreamStartStream_Variables = {
     clientId: string
 }
 
+/**
+ * - Filepath: internal/handlers/torrentstream.go
+ * - Filename: torrentstream.go
+ * - Endpoint: /api/v1/torrentstream/stop
+ * @description
+ * Route stop a torrent stream.
+ */
+export type TorrentstreamStopStream_Variables = {
+    clientId?: string
+}
+
 /**
  * - Filepath: internal/handlers/torrentstream.go
  * - Filename: torrentstream.go
@@ -1831,6 +2262,56 @@ export type GetTorrentstreamBatchHistory_Variables = {
     mediaId: number
 }
 
+/**
+ * - Filepath: internal/handlers/torrentstream.go
+ * - Filename: torrentstream.go
+ * - Endpoint: /api/v1/torrentstream/cache
+ * @description
+ * Route removes a torrent from the streaming cache.
+ */
+export type DeleteTorrentstreamCacheEntry_Variables = {
+    id: number
+}
+
+/**
+ * - Filepath: internal/handlers/torrentstream.go
+ * - Filename: torrentstream.go
+ * - Endpoint: /api/v1/torrentstream/cache/import
+ * @description
+ * Route moves a completely downloaded episode of the streaming cache into the library.
+ */
+export type ImportTorrentstreamCacheEntry_Variables = {
+    id: number
+    destination: string
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// track_preferences
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+/**
+ * - Filepath: internal/handlers/track_preferences.go
+ * - Filename: track_preferences.go
+ * - Endpoint: /api/v1/track-preferences
+ * @description
+ * Route saves the track preference of a media.
+ */
+export type SaveTrackPreference_Variables = {
+    preference: Trackpreference_Preference
+}
+
+/**
+ * - Filepath: internal/handlers/track_preferences.go
+ * - Filename: track_preferences.go
+ * - Endpoint: /api/v1/track-preferences
+ * @description
+ * Route deletes the track preference of a media.
+ */
+export type DeleteTrackPreference_Variables = {
+    username: string
+    mediaId: number
+}
+
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 // websocket
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
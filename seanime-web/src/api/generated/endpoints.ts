@@ -278,6 +278,7 @@ export const API_ENDPOINTS = {
          *  This is called when the JWT token is obtained from AniList after logging in with redirection on the client.
          *  It also fetches the Viewer data from AniList and saves it in the database.
          *  It creates a new handlers.Status and refreshes App modules.
+         *  The account of a profile other than the main profile is saved in the profile, App modules are not refreshed.
          */
         Login: {
             key: "AUTH-login",
@@ -289,6 +290,7 @@ export const API_ENDPOINTS = {
          *  Route logs out the user by removing JWT token from the database.
          *  It removes JWT token and Viewer data from the database.
          *  It creates a new handlers.Status and refreshes App modules.
+         *  A profile other than the main profile only has its own account removed.
          */
         Logout: {
             key: "AUTH-logout",
@@ -420,6 +422,59 @@ export const API_ENDPOINTS = {
             methods: ["GET"],
             endpoint: "/api/v1/continuity/history",
         },
+        /**
+         *  @description
+         *  Route returns the watch event log.
+         *  Each event is a viewing session of an episode, with the time actually spent watching it.
+         *  If mediaId is 0, the events of all media are returned. The time range defaults to the last 30 days.
+         */
+        GetContinuityWatchEvents: {
+            key: "CONTINUITY-get-continuity-watch-events",
+            methods: ["POST"],
+            endpoint: "/api/v1/continuity/events",
+        },
+        /**
+         *  @description
+         *  Route deletes an event from the watch event log.
+         */
+        DeleteContinuityWatchEvent: {
+            key: "CONTINUITY-delete-continuity-watch-event",
+            methods: ["DELETE"],
+            endpoint: "/api/v1/continuity/events/{id}",
+        },
+        /**
+         *  @description
+         *  Route returns statistics computed from the watch event log.
+         *  Time watched is aggregated per day, week, series, genre and source. Completed episodes that had already been completed are counted as rewatches.
+         *  Genres are taken from the anime collection, media that are not in the collection are not counted per genre.
+         *  The time range defaults to the last 30 days.
+         */
+        GetContinuityWatchStats: {
+            key: "CONTINUITY-get-continuity-watch-stats",
+            methods: ["POST"],
+            endpoint: "/api/v1/continuity/stats",
+        },
+        /**
+         *  @description
+         *  Route exports the watch history.
+         *  The export can be imported in another Seanime instance to keep the resume positions.
+         */
+        ExportContinuityWatchHistory: {
+            key: "CONTINUITY-export-continuity-watch-history",
+            methods: ["GET"],
+            endpoint: "/api/v1/continuity/export",
+        },
+        /**
+         *  @description
+         *  Route imports an exported watch history.
+         *  Imported items replace the existing ones only if they were updated more recently.
+         *  Returns the number of items that were imported.
+         */
+        ImportContinuityWatchHistory: {
+            key: "CONTINUITY-import-continuity-watch-history",
+            methods: ["POST"],
+            endpoint: "/api/v1/continuity/import",
+        },
     },
     DEBRID: {
         /**
@@ -687,6 +742,16 @@ export const API_ENDPOINTS = {
             methods: ["GET"],
             endpoint: "/api/v1/extensions/list/onlinestream-provider",
         },
+        /**
+         *  @description
+         *  Route returns the installed media player extensions.
+         *  The ID of a media player extension can be used as the default media player.
+         */
+        ListMediaPlayerExtensions: {
+            key: "EXTENSIONS-list-media-player-extensions",
+            methods: ["GET"],
+            endpoint: "/api/v1/extensions/list/mediaplayer",
+        },
         ListAnimeTorrentProviderExtensions: {
             key: "EXTENSIONS-list-anime-torrent-provider-extensions",
             methods: ["GET"],
@@ -776,6 +841,62 @@ export const API_ENDPOINTS = {
             endpoint: "/api/v1/filecache/mediastream/videofiles",
         },
     },
+    LIST_EXPORT: {
+        /**
+         *  @description
+         *  Route exports the anime or manga lists of the current account.
+         *  The lists of the simulated account are exported when no account is connected.
+         *  Format is "json" or "mal-xml". MyAnimeList XML files only contain the media of the given type ("anime" or "manga").
+         */
+        ExportLists: {
+            key: "LIST-EXPORT-export-lists",
+            methods: ["POST"],
+            endpoint: "/api/v1/lists/export",
+        },
+        /**
+         *  @description
+         *  Route imports exported anime and manga lists into the current account.
+         *  Format is "json" or "mal-xml". Entries of MyAnimeList exports are matched to AniList media.
+         *  The entries are imported in the background, returns the number of entries found in the file.
+         */
+        ImportLists: {
+            key: "LIST-EXPORT-import-lists",
+            methods: ["POST"],
+            endpoint: "/api/v1/lists/import",
+        },
+    },
+    LIST_SYNC: {
+        /**
+         *  @description
+         *  Route updates the AniList and MyAnimeList synchronization settings.
+         *  Origin is "anilist", "mal" or empty to keep the most recent change when both lists changed.
+         */
+        SaveListSyncSettings: {
+            key: "LIST-SYNC-save-list-sync-settings",
+            methods: ["PATCH"],
+            endpoint: "/api/v1/settings/list-sync",
+        },
+        /**
+         *  @description
+         *  Route returns the changes needed to synchronize the AniList and MyAnimeList lists.
+         *  The preview is kept so that the same changes are applied by HandleListSyncApply.
+         */
+        ListSyncPreview: {
+            key: "LIST-SYNC-list-sync-preview",
+            methods: ["POST"],
+            endpoint: "/api/v1/list-sync/preview",
+        },
+        /**
+         *  @description
+         *  Route synchronizes the AniList and MyAnimeList lists.
+         *  The last preview is applied, a new one is computed if it is outdated.
+         */
+        ListSyncApply: {
+            key: "LIST-SYNC-list-sync-apply",
+            methods: ["POST"],
+            endpoint: "/api/v1/list-sync/apply",
+        },
+    },
     LOCAL: {
         /**
          *  @description
@@ -852,6 +973,86 @@ export const API_ENDPOINTS = {
             methods: ["POST"],
             endpoint: "/api/v1/local/sync-simulated-to-anilist",
         },
+        LocalGetOfflineDownloadSettings: {
+            key: "LOCAL-local-get-offline-download-settings",
+            methods: ["GET"],
+            endpoint: "/api/v1/local/offline-downloads/settings",
+        },
+        /**
+         *  @description
+         *  Route updates the settings of the pre-download of upcoming episodes of tracked anime.
+         *  The upcoming episodes are queued in the background after the settings are saved. The folder cannot overlap the library, if it changed the episodes of the previous folder are removed and downloaded again.
+         */
+        LocalSaveOfflineDownloadSettings: {
+            key: "LOCAL-local-save-offline-download-settings",
+            methods: ["PATCH"],
+            endpoint: "/api/v1/local/offline-downloads/settings",
+        },
+        /**
+         *  @description
+         *  Route gets the pre-downloaded episodes of tracked anime.
+         *  This includes the episodes that are being downloaded.
+         */
+        LocalGetOfflineEpisodes: {
+            key: "LOCAL-local-get-offline-episodes",
+            methods: ["GET"],
+            endpoint: "/api/v1/local/offline-downloads",
+        },
+        /**
+         *  @description
+         *  Route checks the pre-downloaded episodes and queues the upcoming episodes of tracked anime.
+         *  This runs in the background, it is also run periodically.
+         */
+        LocalRunOfflineDownloader: {
+            key: "LOCAL-local-run-offline-downloader",
+            methods: ["POST"],
+            endpoint: "/api/v1/local/offline-downloads/run",
+        },
+        /**
+         *  @description
+         *  Route removes a pre-downloaded episode.
+         *  This stops the download and deletes the files of the episode.
+         */
+        LocalDeleteOfflineEpisode: {
+            key: "LOCAL-local-delete-offline-episode",
+            methods: ["DELETE"],
+            endpoint: "/api/v1/local/offline-downloads/{id}",
+        },
+        LocalGetSyncSettings: {
+            key: "LOCAL-local-get-sync-settings",
+            methods: ["GET"],
+            endpoint: "/api/v1/local/sync/settings",
+        },
+        /**
+         *  @description
+         *  Route updates the sync profile used when synchronizing tracked media.
+         *  The settings apply to the next synchronization.
+         */
+        LocalSaveSyncSettings: {
+            key: "LOCAL-local-save-sync-settings",
+            methods: ["PATCH"],
+            endpoint: "/api/v1/local/sync/settings",
+        },
+        /**
+         *  @description
+         *  Route estimates the space required by the next synchronization.
+         *  Nothing is downloaded. The media that don't fit in the storage budget are marked as skipped.
+         */
+        LocalEstimateSync: {
+            key: "LOCAL-local-estimate-sync",
+            methods: ["POST"],
+            endpoint: "/api/v1/local/sync/estimate",
+        },
+        /**
+         *  @description
+         *  Route sets the sync priority of a tracked media.
+         *  Media with a higher priority are synchronized first when the storage budget is limited.
+         */
+        LocalSetTrackedMediaPriority: {
+            key: "LOCAL-local-set-tracked-media-priority",
+            methods: ["PATCH"],
+            endpoint: "/api/v1/local/track/priority",
+        },
     },
     LOCALFILES: {
         /**
@@ -958,6 +1159,18 @@ export const API_ENDPOINTS = {
             methods: ["POST"],
             endpoint: "/api/v1/mal/logout",
         },
+        /**
+         *  @description
+         *  Route sets whether MyAnimeList is used as the account platform.
+         *  When enabled, the MyAnimeList lists are used as the anime and manga collections and list updates are sent to MyAnimeList.
+         *  Media data is still fetched from AniList. MyAnimeList must be connected.
+         *  The client should re-fetch the server status after this.
+         */
+        SetMALAccountPlatform: {
+            key: "MAL-set-mal-account-platform",
+            methods: ["POST"],
+            endpoint: "/api/v1/mal/account-platform",
+        },
     },
     MANGA: {
         GetAnilistMangaCollection: {
@@ -1220,6 +1433,16 @@ export const API_ENDPOINTS = {
             methods: ["POST"],
             endpoint: "/api/v1/media-player/start",
         },
+        /**
+         *  @description
+         *  Route returns the UPnP/DLNA media renderers found on the local network.
+         *  The location of a renderer is used as the 'upnpRendererLocation' media player setting.
+         */
+        DiscoverUpnpRenderers: {
+            key: "MEDIAPLAYER-discover-upnp-renderers",
+            methods: ["GET"],
+            endpoint: "/api/v1/media-player/upnp/renderers",
+        },
     },
     MEDIASTREAM: {
         /**
@@ -1274,6 +1497,16 @@ export const API_ENDPOINTS = {
             methods: ["POST"],
             endpoint: "/api/v1/mediastream/shutdown-transcode",
         },
+        /**
+         *  @description
+         *  Route generates the seek preview thumbnails of the local files of an anime.
+         *  The files are processed in the background, the thumbnails are referenced by the media container and the native player once generated.
+         */
+        GenerateMediastreamTrickplay: {
+            key: "MEDIASTREAM-generate-mediastream-trickplay",
+            methods: ["POST"],
+            endpoint: "/api/v1/mediastream/trickplay",
+        },
     },
     METADATA: {
         /**
@@ -1493,6 +1726,38 @@ export const API_ENDPOINTS = {
             endpoint: "/api/v1/onlinestream/remove-mapping",
         },
     },
+    OUTBOX: {
+        /**
+         *  @description
+         *  Route returns the list changes waiting to be sent to the account platform.
+         *  Changes are queued when AniList or MyAnimeList cannot be reached and sent once it is reachable again.
+         *  Failed changes and changes conflicting with the remote entry stay in the queue until they are retried or discarded.
+         */
+        GetOutboxItems: {
+            key: "OUTBOX-get-outbox-items",
+            methods: ["GET"],
+            endpoint: "/api/v1/outbox",
+        },
+        /**
+         *  @description
+         *  Route sends a queued list change again.
+         *  If the change conflicts with the remote entry, the remote entry is overwritten.
+         */
+        RetryOutboxItem: {
+            key: "OUTBOX-retry-outbox-item",
+            methods: ["POST"],
+            endpoint: "/api/v1/outbox/{id}/retry",
+        },
+        /**
+         *  @description
+         *  Route removes a queued list change without sending it.
+         */
+        DiscardOutboxItem: {
+            key: "OUTBOX-discard-outbox-item",
+            methods: ["DELETE"],
+            endpoint: "/api/v1/outbox/{id}",
+        },
+    },
     PLAYBACK_MANAGER: {
         /**
          *  @description
@@ -1612,6 +1877,120 @@ export const API_ENDPOINTS = {
             endpoint: "/api/v1/playback-manager/manual-tracking/cancel",
         },
     },
+    PLAYBACK_QUEUE: {
+        GetPlaybackQueue: {
+            key: "PLAYBACK-QUEUE-get-playback-queue",
+            methods: ["GET"],
+            endpoint: "/api/v1/playback-queue",
+        },
+        /**
+         *  @description
+         *  Route adds items to the playback queue.
+         *  Items are appended unless an index is given or 'next' is true, in which case they are added after the current item.
+         */
+        PlaybackQueueAddItems: {
+            key: "PLAYBACK-QUEUE-playback-queue-add-items",
+            methods: ["POST"],
+            endpoint: "/api/v1/playback-queue/items",
+        },
+        /**
+         *  @description
+         *  Route removes an item from the playback queue.
+         *  Removing the item being played plays the following item.
+         */
+        PlaybackQueueRemoveItem: {
+            key: "PLAYBACK-QUEUE-playback-queue-remove-item",
+            methods: ["DELETE"],
+            endpoint: "/api/v1/playback-queue/items",
+        },
+        /**
+         *  @description
+         *  Route moves an item of the playback queue to the given index.
+         *  This can be done while the queue is playing.
+         */
+        PlaybackQueueMoveItem: {
+            key: "PLAYBACK-QUEUE-playback-queue-move-item",
+            methods: ["POST"],
+            endpoint: "/api/v1/playback-queue/items/move",
+        },
+        PlaybackQueueClear: {
+            key: "PLAYBACK-QUEUE-playback-queue-clear",
+            methods: ["DELETE"],
+            endpoint: "/api/v1/playback-queue",
+        },
+        /**
+         *  @description
+         *  Route plays an item of the playback queue.
+         *  If no item is given, the next item is played.
+         *  The queue then drives the playback and plays the next item when the current one has been watched.
+         */
+        PlaybackQueuePlay: {
+            key: "PLAYBACK-QUEUE-playback-queue-play",
+            methods: ["POST"],
+            endpoint: "/api/v1/playback-queue/play",
+        },
+        /**
+         *  @description
+         *  Route plays the next item of the playback queue.
+         *  This is also called by the client when an online stream episode of the queue ends.
+         */
+        PlaybackQueueNext: {
+            key: "PLAYBACK-QUEUE-playback-queue-next",
+            methods: ["POST"],
+            endpoint: "/api/v1/playback-queue/next",
+        },
+        PlaybackQueuePrevious: {
+            key: "PLAYBACK-QUEUE-playback-queue-previous",
+            methods: ["POST"],
+            endpoint: "/api/v1/playback-queue/previous",
+        },
+        /**
+         *  @description
+         *  Route stops the playback queue from playing the next items.
+         *  The items are kept.
+         */
+        PlaybackQueueStop: {
+            key: "PLAYBACK-QUEUE-playback-queue-stop",
+            methods: ["POST"],
+            endpoint: "/api/v1/playback-queue/stop",
+        },
+        /**
+         *  @description
+         *  Route returns the episodes of the franchise of a media in the given watch order.
+         *  Episodes in the library are played from local files, the others use the fallback stream type.
+         *  If 'replace' is true, the queue is replaced by the episodes.
+         */
+        PlaybackQueueGetWatchOrder: {
+            key: "PLAYBACK-QUEUE-playback-queue-get-watch-order",
+            methods: ["POST"],
+            endpoint: "/api/v1/playback-queue/watch-order",
+        },
+        GetPlaybackQueuePlaylists: {
+            key: "PLAYBACK-QUEUE-get-playback-queue-playlists",
+            methods: ["GET"],
+            endpoint: "/api/v1/playback-queue/playlists",
+        },
+        /**
+         *  @description
+         *  Route saves the items of the playback queue as a playlist.
+         *  The playlist is replaced if a database ID is given.
+         */
+        SavePlaybackQueuePlaylist: {
+            key: "PLAYBACK-QUEUE-save-playback-queue-playlist",
+            methods: ["POST"],
+            endpoint: "/api/v1/playback-queue/playlists",
+        },
+        LoadPlaybackQueuePlaylist: {
+            key: "PLAYBACK-QUEUE-load-playback-queue-playlist",
+            methods: ["POST"],
+            endpoint: "/api/v1/playback-queue/playlists/{id}/load",
+        },
+        DeletePlaybackQueuePlaylist: {
+            key: "PLAYBACK-QUEUE-delete-playback-queue-playlist",
+            methods: ["DELETE"],
+            endpoint: "/api/v1/playback-queue/playlists/{id}",
+        },
+    },
     PLAYLIST: {
         /**
          *  @description
@@ -1650,6 +2029,64 @@ export const API_ENDPOINTS = {
             endpoint: "/api/v1/playlist/episodes/{id}/{progress}",
         },
     },
+    PROFILE: {
+        /**
+         *  @description
+         *  Route creates a profile.
+         *  The profile is not logged in to AniList, it uses its own simulated collections until an account is connected.
+         *  The PIN is optional and must be made of 4 to 8 digits.
+         */
+        CreateProfile: {
+            key: "PROFILE-create-profile",
+            methods: ["POST"],
+            endpoint: "/api/v1/profiles",
+        },
+        /**
+         *  @description
+         *  Route deletes a profile with its watch history and simulated collections.
+         *  The main profile cannot be deleted, the clients using the deleted profile go back to the main profile.
+         */
+        DeleteProfile: {
+            key: "PROFILE-delete-profile",
+            methods: ["DELETE"],
+            endpoint: "/api/v1/profiles",
+        },
+        /**
+         *  @description
+         *  Route returns the profiles of the server.
+         *  The profile selected by the client is marked as active.
+         *  The main profile is created from the current account if there are no profiles yet.
+         */
+        GetProfiles: {
+            key: "PROFILE-get-profiles",
+            methods: ["GET"],
+            endpoint: "/api/v1/profiles",
+        },
+        /**
+         *  @description
+         *  Route selects the profile of the client.
+         *  The PIN is required if the profile is protected.
+         *  Only the client that sent the request uses the profile: its account, collections, watch history, theme and playback settings.
+         *  The library, torrent clients and caches are shared by all profiles.
+         */
+        SelectProfile: {
+            key: "PROFILE-select-profile",
+            methods: ["POST"],
+            endpoint: "/api/v1/profiles/select",
+        },
+        /**
+         *  @description
+         *  Route updates the name or the PIN of a profile.
+         *  The current PIN is required if the profile is protected.
+         *  An empty PIN removes the protection, a null PIN keeps the current PIN.
+         *  The main profile cannot be protected since it is used by clients that have not selected a profile.
+         */
+        UpdateProfile: {
+            key: "PROFILE-update-profile",
+            methods: ["PATCH"],
+            endpoint: "/api/v1/profiles",
+        },
+    },
     RELEASES: {
         /**
          *  @description
@@ -1710,6 +2147,49 @@ export const API_ENDPOINTS = {
             endpoint: "/api/v1/library/scan-summaries",
         },
     },
+    SCROBBLER: {
+        /**
+         *  @description
+         *  Route returns the connected tracker accounts.
+         *  List updates made on AniList are mirrored to these accounts.
+         */
+        GetScrobblerAccounts: {
+            key: "SCROBBLER-get-scrobbler-accounts",
+            methods: ["GET"],
+            endpoint: "/api/v1/scrobbler/accounts",
+        },
+        /**
+         *  @description
+         *  Route connects a Kitsu, Shikimori or Simkl account.
+         *  Kitsu uses the username and password.
+         *  Shikimori and Simkl use the OAuth code and the credentials of the OAuth application that issued it.
+         *  The previous account of the tracker is replaced.
+         */
+        ConnectScrobblerAccount: {
+            key: "SCROBBLER-connect-scrobbler-account",
+            methods: ["POST"],
+            endpoint: "/api/v1/scrobbler/accounts",
+        },
+        /**
+         *  @description
+         *  Route removes a tracker account.
+         */
+        DisconnectScrobblerAccount: {
+            key: "SCROBBLER-disconnect-scrobbler-account",
+            methods: ["DELETE"],
+            endpoint: "/api/v1/scrobbler/accounts/{tracker}",
+        },
+        /**
+         *  @description
+         *  Route returns the latest update attempts of a tracker.
+         *  Most recent first.
+         */
+        GetScrobblerLogs: {
+            key: "SCROBBLER-get-scrobbler-logs",
+            methods: ["GET"],
+            endpoint: "/api/v1/scrobbler/logs/{tracker}",
+        },
+    },
     SETTINGS: {
         GetSettings: {
             key: "SETTINGS-get-settings",
@@ -1744,6 +2224,47 @@ export const API_ENDPOINTS = {
             endpoint: "/api/v1/settings/auto-downloader",
         },
     },
+    SKIP_SEGMENTS: {
+        /**
+         *  @description
+         *  Route returns the skip segments of an episode.
+         *  Segments derived from the chapters of the file are not included.
+         */
+        GetSkipSegments: {
+            key: "SKIP-SEGMENTS-get-skip-segments",
+            methods: ["GET"],
+            endpoint: "/api/v1/skip-segments/{id}/{episodeNumber}",
+        },
+        /**
+         *  @description
+         *  Route saves a skip segment submitted by the user.
+         *  It replaces the segment of the same type previously submitted by the user for the episode.
+         */
+        SaveSkipSegment: {
+            key: "SKIP-SEGMENTS-save-skip-segment",
+            methods: ["POST"],
+            endpoint: "/api/v1/skip-segments",
+        },
+        /**
+         *  @description
+         *  Route deletes a stored skip segment.
+         */
+        DeleteSkipSegment: {
+            key: "SKIP-SEGMENTS-delete-skip-segment",
+            methods: ["DELETE"],
+            endpoint: "/api/v1/skip-segments",
+        },
+        /**
+         *  @description
+         *  Route detects the openings of the downloaded episodes of a media.
+         *  The audio of the episodes is compared in the background, a toast is sent when the detection is done.
+         */
+        DetectSkipSegments: {
+            key: "SKIP-SEGMENTS-detect-skip-segments",
+            methods: ["POST"],
+            endpoint: "/api/v1/skip-segments/detect",
+        },
+    },
     STATUS: {
         /**
          *  @description
@@ -1948,6 +2469,72 @@ export const API_ENDPOINTS = {
             methods: ["POST"],
             endpoint: "/api/v1/torrentstream/batch-history",
         },
+        /**
+         *  @description
+         *  Route returns the episodes kept by the streaming cache.
+         *  The most recently watched episodes are returned first.
+         */
+        GetTorrentstreamCacheEntries: {
+            key: "TORRENTSTREAM-get-torrentstream-cache-entries",
+            methods: ["GET"],
+            endpoint: "/api/v1/torrentstream/cache",
+        },
+        /**
+         *  @description
+         *  Route removes a torrent from the streaming cache.
+         *  This deletes the downloaded data of the torrent the entry belongs to.
+         *  If no id is provided, all the torrents that are not being streamed are removed.
+         */
+        DeleteTorrentstreamCacheEntry: {
+            key: "TORRENTSTREAM-delete-torrentstream-cache-entry",
+            methods: ["DELETE"],
+            endpoint: "/api/v1/torrentstream/cache",
+        },
+        /**
+         *  @description
+         *  Route moves a completely downloaded episode of the streaming cache into the library.
+         *  The file is moved to a folder named after the media in the destination, which defaults to the library path and must be in the library.
+         *  Only the moved file is scanned before it is added to the local files.
+         */
+        ImportTorrentstreamCacheEntry: {
+            key: "TORRENTSTREAM-import-torrentstream-cache-entry",
+            methods: ["POST"],
+            endpoint: "/api/v1/torrentstream/cache/import",
+        },
+    },
+    TRACK_PREFERENCES: {
+        /**
+         *  @description
+         *  Route returns the track preferences.
+         *  The default preference has a media ID of 0.
+         *  Preferences with an empty username apply to every user.
+         */
+        GetTrackPreferences: {
+            key: "TRACK-PREFERENCES-get-track-preferences",
+            methods: ["GET"],
+            endpoint: "/api/v1/track-preferences",
+        },
+        /**
+         *  @description
+         *  Route saves the track preference of a media.
+         *  Set the media ID to 0 to save the default preference, which applies to media without a preference.
+         *  Set the username to the one of the logged-in user to save a preference that only applies to them, or leave it empty for every user.
+         */
+        SaveTrackPreference: {
+            key: "TRACK-PREFERENCES-save-track-preference",
+            methods: ["POST"],
+            endpoint: "/api/v1/track-preferences",
+        },
+        /**
+         *  @description
+         *  Route deletes the track preference of a media.
+         *  The default preference is used for the media afterwards.
+         */
+        DeleteTrackPreference: {
+            key: "TRACK-PREFERENCES-delete-track-preference",
+            methods: ["DELETE"],
+            endpoint: "/api/v1/track-preferences",
+        },
     },
 } satisfies ApiEndpoints
 
@@ -1709,6 +1709,26 @@ export type ChapterDownloader_DownloadID = {
  */
 export type Continuity_Kind = "onlinestream" | "mediastream" | "external_player"
 
+/**
+ * - Filepath: internal/continuity/watch_events.go
+ * - Filename: watch_events.go
+ * - Package: continuity
+ * @description
+ *  TrackWatchEventOptions is a progress report of the player.
+ */
+export type Continuity_TrackWatchEventOptions = {
+    mediaId: number
+    episodeNumber: number
+    currentTime: number
+    duration: number
+    player: string
+    source: Continuity_WatchSource
+    /**
+     * Client playing the episode, used to tell apart the players of different clients (e.g. native player)
+     */
+    clientId?: string
+}
+
 /**
  * - Filepath: internal/continuity/history.go
  * - Filename: history.go
@@ -1730,6 +1750,20 @@ export type Continuity_UpdateWatchHistoryItemOptions = {
  */
 export type Continuity_WatchHistory = Record<number, Continuity_WatchHistoryItem>
 
+/**
+ * - Filepath: internal/continuity/sync.go
+ * - Filename: sync.go
+ * - Package: continuity
+ * @description
+ *  WatchHistoryExport is the portable format of the watch history.
+ *  It is used to move resume positions between Seanime instances.
+ */
+export type Continuity_WatchHistoryExport = {
+    version: number
+    exportedAt?: string
+    items?: Array<Continuity_WatchHistoryItem>
+}
+
 /**
  * - Filepath: internal/continuity/history.go
  * - Filename: history.go
@@ -1756,6 +1790,94 @@ export type Continuity_WatchHistoryItemResponse = {
     found: boolean
 }
 
+/**
+ * - Filepath: internal/continuity/watch_events.go
+ * - Filename: watch_events.go
+ * - Package: continuity
+ * @description
+ *  WatchSource is where the episode is played from.
+ */
+export type Continuity_WatchSource = "local" | "torrent" | "debrid" | "online"
+
+/**
+ * - Filepath: internal/continuity/watch_stats.go
+ * - Filename: watch_stats.go
+ * - Package: continuity
+ * @description
+ *  WatchStats aggregates the watch event log.
+ */
+export type Continuity_WatchStats = {
+    totalSeconds: number
+    eventCount: number
+    /**
+     * Number of completed episodes
+     */
+    completedEpisodes: number
+    /**
+     * Number of completed episodes that had already been completed before
+     */
+    rewatches: number
+    /**
+     * Time watched per day, oldest first
+     */
+    days?: Array<Continuity_WatchStatsPeriod>
+    /**
+     * Time watched per week (starting on Monday), oldest first
+     */
+    weeks?: Array<Continuity_WatchStatsPeriod>
+    /**
+     * Time watched per series, most watched first
+     */
+    series?: Array<Continuity_WatchStatsSeries>
+    /**
+     * Time watched per genre, most watched first
+     */
+    genres?: Array<Continuity_WatchStatsGroup>
+    /**
+     * Time watched per source, most watched first
+     */
+    sources?: Array<Continuity_WatchStatsGroup>
+}
+
+/**
+ * - Filepath: internal/continuity/watch_stats.go
+ * - Filename: watch_stats.go
+ * - Package: continuity
+ */
+export type Continuity_WatchStatsGroup = {
+    name: string
+    seconds: number
+}
+
+/**
+ * - Filepath: internal/continuity/watch_stats.go
+ * - Filename: watch_stats.go
+ * - Package: continuity
+ */
+export type Continuity_WatchStatsPeriod = {
+    /**
+     * Start of the period, in the server's time zone
+     */
+    start?: string
+    seconds: number
+}
+
+/**
+ * - Filepath: internal/continuity/watch_stats.go
+ * - Filename: watch_stats.go
+ * - Package: continuity
+ */
+export type Continuity_WatchStatsSeries = {
+    mediaId: number
+    seconds: number
+    /**
+     * Number of distinct episodes completed
+     */
+    completedEpisodes: number
+    rewatches: number
+    lastWatchedAt?: string
+}
+
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 // Core
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -1908,6 +2030,7 @@ export type Debrid_TorrentItemStatus = "downloading" |
  */
 export type DebridClient_CancelStreamOptions = {
     removeTorrent: boolean
+    clientId: string
 }
 
 /**
@@ -2154,7 +2277,7 @@ export type Extension_SavedUserConfig = {
  * - Filename: extension.go
  * - Package: extension
  */
-export type Extension_Type = "anime-torrent-provider" | "manga-provider" | "onlinestream-provider" | "plugin"
+export type Extension_Type = "anime-torrent-provider" | "manga-provider" | "onlinestream-provider" | "mediaplayer" | "plugin"
 
 /**
  * - Filepath: internal/extension/extension.go
@@ -2259,6 +2382,17 @@ export type ExtensionRepo_MangaProviderExtensionItem = {
     settings?: HibikeManga_Settings
 }
 
+/**
+ * - Filepath: internal/extension_repo/repository.go
+ * - Filename: repository.go
+ * - Package: extension_repo
+ */
+export type ExtensionRepo_MediaPlayerExtensionItem = {
+    id: string
+    name: string
+    settings: HibikeMediaPlayer_Settings
+}
+
 /**
  * - Filepath: internal/extension_repo/repository.go
  * - Filename: repository.go
@@ -2465,6 +2599,10 @@ export type Status = {
     featureFlags?: INTERNAL_FeatureFlags
     serverReady: boolean
     serverHasPassword: boolean
+    /**
+     * The MyAnimeList lists are used instead of the AniList collections
+     */
+    isMalAccountPlatform: boolean
 }
 
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -2527,6 +2665,28 @@ export type HibikeManga_Settings = {
     supportsMultiLanguage: boolean
 }
 
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Hibikemediaplayer
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+/**
+ * - Filepath: internal/extension/hibike/mediaplayer/types.go
+ * - Filename: types.go
+ * - Package: hibikemediaplayer
+ */
+export type HibikeMediaPlayer_Settings = {
+    /**
+     * Whether GetPlaybackStatus is implemented.
+     * If false, the progress of the media player is not tracked.
+     */
+    canTrackProgress: boolean
+    /**
+     * Whether the media player can only open URLs (e.g. remote devices, URL schemes).
+     * If true, local files are sent as URLs served by Seanime.
+     */
+    requiresUrl: boolean
+}
+
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 // Hibikeonlinestream
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -2606,10 +2766,202 @@ export type HibikeTorrent_AnimeTorrent = {
     confirmed: boolean
 }
 
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Listexport
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+/**
+ * - Filepath: internal/listexport/listexport.go
+ * - Filename: listexport.go
+ * - Package: listexport
+ * @description
+ *  File is an exported list ready to be downloaded.
+ */
+export type Listexport_File = {
+    name: string
+    content: string
+    /**
+     * Number of exported entries
+     */
+    count: number
+    /**
+     * Number of entries left out because the format cannot represent them (e.g. media without a MyAnimeList ID)
+     */
+    skipped: number
+}
+
+/**
+ * - Filepath: internal/listexport/listexport.go
+ * - Filename: listexport.go
+ * - Package: listexport
+ */
+export type Listexport_Format = "json" | "mal-xml"
+
+/**
+ * - Filepath: internal/listexport/listexport.go
+ * - Filename: listexport.go
+ * - Package: listexport
+ */
+export type Listexport_MediaType = "anime" | "manga"
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Listsync
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+/**
+ * - Filepath: internal/listsync/diff.go
+ * - Filename: diff.go
+ * - Package: listsync
+ * @description
+ *  Diff is a change needed to bring an entry in sync.
+ */
+export type Listsync_Diff = {
+    mediaType: Listsync_MediaType
+    /**
+     * 0 if the entry is only on MyAnimeList, the AniList ID is looked up when the change is applied
+     */
+    anilistId: number
+    malId: number
+    title: string
+    /**
+     * List the change is applied to
+     */
+    target: Listsync_Target
+    /**
+     * The entry is added to the target list
+     */
+    isNew: boolean
+    anilist?: Listsync_Entry
+    mal?: Listsync_Entry
+}
+
+/**
+ * - Filepath: internal/listsync/entry.go
+ * - Filename: entry.go
+ * - Package: listsync
+ * @description
+ *  Entry is a list entry in a form that both AniList and MyAnimeList can represent.
+ */
+export type Listsync_Entry = {
+    status: AL_MediaListStatus
+    progress: number
+    /**
+     * Score out of 10, 0 if not scored. AniList scores are rounded since MyAnimeList only supports whole numbers.
+     */
+    score: number
+}
+
+/**
+ * - Filepath: internal/listsync/entry.go
+ * - Filename: entry.go
+ * - Package: listsync
+ */
+export type Listsync_MediaType = "anime" | "manga"
+
+/**
+ * - Filepath: internal/listsync/listsync.go
+ * - Filename: listsync.go
+ * - Package: listsync
+ * @description
+ *  Preview holds the changes needed to bring both lists in sync.
+ */
+export type Listsync_Preview = {
+    diffs?: Array<Listsync_Diff>
+    /**
+     * Number of entries that are already in sync
+     */
+    inSyncCount: number
+    createdAt?: string
+}
+
+/**
+ * - Filepath: internal/listsync/listsync.go
+ * - Filename: listsync.go
+ * - Package: listsync
+ * @description
+ *  Result is the outcome of a synchronization.
+ */
+export type Listsync_Result = {
+    applied: number
+    failed: number
+    errors?: Array<string>
+}
+
+/**
+ * - Filepath: internal/listsync/entry.go
+ * - Filename: entry.go
+ * - Package: listsync
+ * @description
+ *  Target is the list a change is applied to.
+ */
+export type Listsync_Target = "anilist" | "mal"
+
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 // Local
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
+/**
+ * - Filepath: internal/local/database_models.go
+ * - Filename: database_models.go
+ * - Package: local
+ */
+export type Local_OfflineDownloadSettings = {
+    enabled: boolean
+    /**
+     * "torrent" or "debrid"
+     */
+    source: string
+    /**
+     * Number of upcoming episodes to download for each tracked anime
+     */
+    episodeCount: number
+    /**
+     * Maximum size of the offline folder in GiB
+     */
+    maxSize: number
+    /**
+     * Preferred resolution, e.g. "1080p", empty for any
+     */
+    resolution: string
+    /**
+     * Folder in which the episodes are downloaded, defaults to a folder in the local data directory
+     */
+    dir: string
+}
+
+/**
+ * - Filepath: internal/local/database_models.go
+ * - Filename: database_models.go
+ * - Package: local
+ * @description
+ *  OfflineEpisode is an upcoming episode of a tracked anime downloaded to the offline folder.
+ *  Completed episodes are added to the local files so that they appear in the offline collection.
+ */
+export type Local_OfflineEpisode = {
+    mediaId: number
+    episodeNumber: number
+    source: string
+    status: string
+    torrentName: string
+    infoHash: string
+    /**
+     * ID of the torrent on the debrid service
+     */
+    debridItemId: string
+    /**
+     * Folder of the episode in the offline folder
+     */
+    dir: string
+    /**
+     * Video file, set once the download is complete
+     */
+    path: string
+    size: number
+    id: number
+    createdAt?: string
+    updatedAt?: string
+}
+
 /**
  * - Filepath: internal/local/sync.go
  * - Filename: sync.go
@@ -2630,6 +2982,73 @@ export type Local_QueueMediaTask = {
 export type Local_QueueState = {
     animeTasks?: Record<number, Local_QueueMediaTask>
     mangaTasks?: Record<number, Local_QueueMediaTask>
+    /**
+     * Media that were not queued because they don't fit in the storage budget
+     */
+    skippedTasks?: Array<Local_QueueMediaTask>
+}
+
+/**
+ * - Filepath: internal/local/sync_profile.go
+ * - Filename: sync_profile.go
+ * - Package: local
+ * @description
+ *  SyncEstimate is the result of a dry run of the synchronization.
+ */
+export type Local_SyncEstimate = {
+    /**
+     * Size of the local storage in bytes
+     */
+    currentSize: number
+    /**
+     * Storage budget in bytes, 0 if there is no limit
+     */
+    budget: number
+    /**
+     * Estimated size of the media that will be synchronized in bytes
+     */
+    requiredSize: number
+    /**
+     * Media that need to be synchronized, ordered by priority
+     */
+    items?: Array<Local_SyncEstimateItem>
+}
+
+/**
+ * - Filepath: internal/local/sync_profile.go
+ * - Filename: sync_profile.go
+ * - Package: local
+ */
+export type Local_SyncEstimateItem = {
+    mediaId: number
+    type: string
+    title: string
+    image: string
+    priority: number
+    /**
+     * Estimated size of the downloaded images in bytes
+     */
+    size: number
+    /**
+     * Whether the media is skipped because it doesn't fit in the storage budget
+     */
+    skipped: boolean
+}
+
+/**
+ * - Filepath: internal/local/database_models.go
+ * - Filename: database_models.go
+ * - Package: local
+ */
+export type Local_SyncSettings = {
+    /**
+     * Maximum size of the local storage in MiB, 0 for no limit
+     */
+    storageBudget: number
+    /**
+     * "cover", "banner" or "all"
+     */
+    imageQuality: string
 }
 
 /**
@@ -2640,6 +3059,7 @@ export type Local_QueueState = {
 export type Local_TrackedMediaItem = {
     mediaId: number
     type: string
+    priority: number
     animeEntry?: AL_AnimeListEntry
     mangaEntry?: AL_MangaListEntry
 }
@@ -2868,6 +3288,20 @@ export type Mediastream_MediaContainer = {
      */
     streamUrl: string
     mediaInfo?: MediaInfo
+    /**
+     * The index of the subtitle track burned into the video (transcode only).
+     */
+    burnInSubtitleIndex?: number
+    /**
+     * The relative endpoint of the WebVTT file referencing the seek preview thumbnails.
+     * Empty if the thumbnails have not been generated yet.
+     */
+    trickplayUrl?: string
+    /**
+     * Tracks selected by the track preference of the media, the indexes are those of MediaInfo.
+     * nil if no preference is set.
+     */
+    trackSelection?: Trackpreference_Selection
 }
 
 /**
@@ -3332,7 +3766,7 @@ export type Models_MangaSettings = {
  */
 export type Models_MediaPlayerSettings = {
     /**
-     * "vlc" or "mpc-hc"
+     * "vlc", "mpc-hc", "mpv", "iina", "kodi" or "upnp"
      */
     defaultPlayer: string
     host: string
@@ -3348,6 +3782,25 @@ export type Models_MediaPlayerSettings = {
     iinaSocket: string
     iinaPath: string
     iinaArgs: string
+    /**
+     * Kodi JSON-RPC (web server), uses Host
+     */
+    kodiPort: number
+    kodiUsername: string
+    kodiPassword: string
+    /**
+     * Device description URL of the UPnP/DLNA renderer
+     */
+    upnpRendererLocation: string
+    /**
+     * URL at which network players (Kodi, DLNA renderers) can reach the server, detected if empty
+     */
+    networkServerUrl: string
+    /**
+     * Expose the library as a DLNA media server on the local network
+     */
+    dlnaServerEnabled: boolean
+    dlnaServerName: string
 }
 
 /**
@@ -3367,9 +3820,17 @@ export type Models_MediastreamSettings = {
     ffmpegPath: string
     ffprobePath: string
     transcodeHwAccelCustomSettings: string
-    id: number
-    createdAt?: string
-    updatedAt?: string
+    /**
+     * Generate seek preview thumbnails for local files
+     */
+    trickplayEnabled: boolean
+    /**
+     * Number of seconds between two thumbnails
+     */
+    trickplayInterval: number
+    id: number
+    createdAt?: string
+    updatedAt?: string
 }
 
 /**
@@ -3401,6 +3862,32 @@ export type Models_NotificationSettings = {
     disableAutoScannerNotifications: boolean
 }
 
+/**
+ * - Filepath: internal/database/models/models.go
+ * - Filename: models.go
+ * - Package: models
+ * @description
+ *  ScrobbleLog records an attempt to mirror a list update to a tracker.
+ */
+export type Models_ScrobbleLog = {
+    tracker: string
+    mediaId: number
+    mediaType: string
+    /**
+     * AniList list status
+     */
+    status: string
+    progress: number
+    attempt: number
+    /**
+     * Empty if the update succeeded
+     */
+    error: string
+    id: number
+    createdAt?: string
+    updatedAt?: string
+}
+
 /**
  * - Filepath: internal/database/models/models.go
  * - Filename: models.go
@@ -3536,6 +4023,53 @@ export type Models_TorrentstreamSettings = {
     includeInLibrary: boolean
     streamUrlAddress: string
     slowSeeding: boolean
+    /**
+     * Maximum size of the streaming cache in GB, 0 disables the cache
+     */
+    cacheMaxSize: number
+    id: number
+    createdAt?: string
+    updatedAt?: string
+}
+
+/**
+ * - Filepath: internal/database/models/models.go
+ * - Filename: models.go
+ * - Package: models
+ * @description
+ *  WatchEvent records one viewing session of an episode.
+ *  Unlike the continuity watch history, events are never merged or trimmed.
+ */
+export type Models_WatchEvent = {
+    profileId: number
+    mediaId: number
+    episodeNumber: number
+    startedAt?: string
+    endedAt?: string
+    /**
+     * Seconds of the episode actually played, seeking and pausing are not counted
+     */
+    watchedSeconds: number
+    /**
+     * Duration of the episode in seconds
+     */
+    duration: number
+    /**
+     * e.g. "mpv", "vlc", "native", "onlinestream"
+     */
+    player: string
+    /**
+     * "local", "torrent", "debrid" or "online"
+     */
+    source: string
+    /**
+     * Whether the episode was played past the completion threshold
+     */
+    completed: boolean
+    /**
+     * Whether the episode had already been completed before this event
+     */
+    rewatch: boolean
     id: number
     createdAt?: string
     updatedAt?: string
@@ -3745,6 +4279,18 @@ export type NativePlayer_PlaybackInfo = {
     entryListData?: Anime_EntryListData
     episode?: Anime_Episode
     media?: AL_BaseAnime
+    /**
+     * Skippable ranges of the episode (opening, ending, etc.)
+     */
+    skipSegments?: Array<Skipsegments_Segment>
+    /**
+     * URL of the WebVTT file referencing the seek preview thumbnails, empty if not generated
+     */
+    trickplayUrl?: string
+    /**
+     * Tracks selected by the track preference of the media, the indexes are track numbers. nil if no preference is set
+     */
+    trackSelection?: Trackpreference_Selection
 }
 
 /**
@@ -3839,6 +4385,226 @@ export type Onlinestream_VideoSource = {
     quality: string
 }
 
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Outbox
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+/**
+ * - Filepath: internal/outbox/mutation.go
+ * - Filename: mutation.go
+ * - Package: outbox
+ * @description
+ *  EntryState is the part of a remote entry used to detect conflicts.
+ */
+export type Outbox_EntryState = {
+    exists: boolean
+    status: AL_MediaListStatus
+    progress: number
+}
+
+/**
+ * - Filepath: internal/outbox/outbox.go
+ * - Filename: outbox.go
+ * - Package: outbox
+ * @description
+ *  Item is a queued mutation.
+ */
+export type Outbox_Item = {
+    id: number
+    platform: string
+    mediaId: number
+    mutation?: Outbox_Mutation
+    status: string
+    attempts: number
+    nextAttemptAt?: string
+    error: string
+    createdAt?: string
+}
+
+/**
+ * - Filepath: internal/outbox/mutation.go
+ * - Filename: mutation.go
+ * - Package: outbox
+ * @description
+ *  Mutation is a change to a list entry.
+ *  Nil fields are not changed.
+ */
+export type Outbox_Mutation = {
+    /**
+     * The entry is deleted, the other changes are dropped
+     */
+    delete: boolean
+    /**
+     * ID passed to platform.Platform.DeleteEntry
+     */
+    entryId?: number
+    status?: AL_MediaListStatus
+    scoreRaw?: number
+    progress?: number
+    totalCount?: number
+    startedAt?: AL_FuzzyDateInput
+    completedAt?: AL_FuzzyDateInput
+    repeat?: number
+    /**
+     * State of the remote entry before the first queued change, nil if it was unknown
+     */
+    base?: Outbox_EntryState
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Playbackqueue
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+/**
+ * - Filepath: internal/library/playbackqueue/queue.go
+ * - Filename: queue.go
+ * - Package: playbackqueue
+ * @description
+ *  Item is an episode in the queue.
+ */
+export type Playbackqueue_Item = {
+    id: string
+    type: Playbackqueue_ItemType
+    mediaId: number
+    episodeNumber: number
+    /**
+     * AniDB episode, used by torrent and debrid streams, e.g. "1", "S1"
+     */
+    aniDbEpisode?: string
+    /**
+     * Path of the local file, ItemTypeLocalFile only
+     */
+    path?: string
+    /**
+     * ItemTypeOnlineStream only
+     */
+    onlineStream?: Playbackqueue_OnlineStreamOptions
+    /**
+     * Hydrated when the item is added
+     */
+    media?: AL_BaseAnime
+}
+
+/**
+ * - Filepath: internal/library/playbackqueue/queue.go
+ * - Filename: queue.go
+ * - Package: playbackqueue
+ */
+export type Playbackqueue_ItemType = "localfile" | "torrentstream" | "debridstream" | "onlinestream"
+
+/**
+ * - Filepath: internal/library/playbackqueue/queue.go
+ * - Filename: queue.go
+ * - Package: playbackqueue
+ */
+export type Playbackqueue_OnlineStreamOptions = {
+    provider: string
+    server?: string
+    dubbed: boolean
+    quality?: string
+}
+
+/**
+ * - Filepath: internal/library/playbackqueue/manager.go
+ * - Filename: manager.go
+ * - Package: playbackqueue
+ * @description
+ *  PlayOnlineStreamPayload is sent to the client to play an online stream item.
+ */
+export type Playbackqueue_PlayOnlineStreamPayload = {
+    item?: Playbackqueue_Item
+}
+
+/**
+ * - Filepath: internal/library/playbackqueue/manager.go
+ * - Filename: manager.go
+ * - Package: playbackqueue
+ */
+export type Playbackqueue_PlayerType = "external" | "nativeplayer"
+
+/**
+ * - Filepath: internal/library/playbackqueue/playlist.go
+ * - Filename: playlist.go
+ * - Package: playbackqueue
+ * @description
+ *  Playlist is a saved queue.
+ */
+export type Playbackqueue_Playlist = {
+    dbId: number
+    name: string
+    items?: Array<Playbackqueue_Item>
+}
+
+/**
+ * - Filepath: internal/library/playbackqueue/queue.go
+ * - Filename: queue.go
+ * - Package: playbackqueue
+ * @description
+ *  Queue is an ordered list of items and the position of the item being played.
+ *  It is not safe for concurrent use.
+ */
+export type Playbackqueue_Queue = {
+    items?: Array<Playbackqueue_Item>
+    /**
+     * ID of the item being played, empty if playback has not started
+     */
+    currentId: string
+}
+
+/**
+ * - Filepath: internal/library/playbackqueue/manager.go
+ * - Filename: manager.go
+ * - Package: playbackqueue
+ * @description
+ *  State is sent to the client when the queue changes.
+ */
+export type Playbackqueue_State = {
+    queue?: Playbackqueue_Queue
+    player: Playbackqueue_PlayerType
+    clientId: string
+    /**
+     * Whether the queue is driving the playback
+     */
+    active: boolean
+}
+
+/**
+ * - Filepath: internal/library/playbackqueue/watch_order.go
+ * - Filename: watch_order.go
+ * - Package: playbackqueue
+ */
+export type Playbackqueue_WatchOrder = "series" | "chronological" | "release"
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Profile
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+/**
+ * - Filepath: internal/profile/profile.go
+ * - Filename: profile.go
+ * - Package: profile
+ * @description
+ *  Profile is the data of a profile sent to the client.
+ */
+export type Profile_Profile = {
+    id: number
+    name: string
+    hasPin: boolean
+    /**
+     * True if the profile is the one selected by the client
+     */
+    active: boolean
+    /**
+     * True for the profile used by clients that have not selected a profile
+     */
+    isMain: boolean
+    /**
+     * AniList username, empty if the profile is not logged in
+     */
+    username: string
+    avatar: string
+}
+
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 // Report
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -3932,6 +4698,76 @@ export type Report_UnlockedLocalFile = {
     mediaId: number
 }
 
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Scrobbler
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+/**
+ * - Filepath: internal/scrobbler/scrobbler.go
+ * - Filename: scrobbler.go
+ * - Package: scrobbler
+ * @description
+ *  Account is a connected tracker account.
+ */
+export type Scrobbler_Account = {
+    tracker: Scrobbler_TrackerName
+    username: string
+    connectedAt?: string
+    /**
+     * Number of failed updates waiting to be retried
+     */
+    pendingRetries: number
+}
+
+/**
+ * - Filepath: internal/scrobbler/tracker.go
+ * - Filename: tracker.go
+ * - Package: scrobbler
+ */
+export type Scrobbler_TrackerName = "kitsu" | "shikimori" | "simkl"
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Skipsegments
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+/**
+ * - Filepath: internal/skipsegments/skipsegments.go
+ * - Filename: skipsegments.go
+ * - Package: skipsegments
+ * @description
+ *  Segment is a skippable range of an episode.
+ */
+export type Skipsegments_Segment = {
+    /**
+     * ID of the stored segment, 0 for chapter-based segments
+     */
+    id?: number
+    type: Skipsegments_SegmentType
+    source: Skipsegments_Source
+    /**
+     * Start time in seconds
+     */
+    start: number
+    /**
+     * End time in seconds
+     */
+    end: number
+}
+
+/**
+ * - Filepath: internal/skipsegments/skipsegments.go
+ * - Filename: skipsegments.go
+ * - Package: skipsegments
+ */
+export type Skipsegments_SegmentType = "opening" | "ending" | "recap" | "preview"
+
+/**
+ * - Filepath: internal/skipsegments/skipsegments.go
+ * - Filename: skipsegments.go
+ * - Package: skipsegments
+ */
+export type Skipsegments_Source = "user" | "fingerprint" | "chapters"
+
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 // Summary
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -4094,6 +4930,67 @@ export type Torrentstream_BatchHistoryResponse = {
     torrent?: HibikeTorrent_AnimeTorrent
 }
 
+/**
+ * - Filepath: internal/torrentstream/prefetch.go
+ * - Filename: prefetch.go
+ * - Package: torrentstream
+ * @description
+ *  BufferHealth is sent to the client with the torrent status.
+ */
+export type Torrentstream_BufferHealth = {
+    /**
+     * Playback position in seconds
+     */
+    position: number
+    /**
+     * Seconds downloaded contiguously ahead of the playback position
+     */
+    bufferedSeconds: number
+    /**
+     * Download rate in bytes per second
+     */
+    downloadRate: number
+    /**
+     * Average bitrate of the file in bytes per second, the download rate needed to play without stalling
+     */
+    neededRate: number
+    state: Torrentstream_BufferHealthState
+}
+
+/**
+ * - Filepath: internal/torrentstream/prefetch.go
+ * - Filename: prefetch.go
+ * - Package: torrentstream
+ */
+export type Torrentstream_BufferHealthState = "good" | "low" | "stalling"
+
+/**
+ * - Filepath: internal/torrentstream/cache.go
+ * - Filename: cache.go
+ * - Package: torrentstream
+ * @description
+ *  CacheEntry is a streamed episode kept in the download directory.
+ */
+export type Torrentstream_CacheEntry = {
+    /**
+     * Size of the torrent directory
+     */
+    diskSize: number
+    infoHash: string
+    mediaId: number
+    episodeNumber: number
+    /**
+     * Path of the file in the torrent
+     */
+    filePath: string
+    size: number
+    complete: boolean
+    lastWatchedAt?: string
+    id: number
+    createdAt?: string
+    updatedAt?: string
+}
+
 /**
  * - Filepath: internal/torrentstream/previews.go
  * - Filename: previews.go
@@ -4131,6 +5028,64 @@ export type Torrentstream_TorrentStatus = {
     seeders: number
 }
 
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Trackpreference
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+/**
+ * - Filepath: internal/trackpreference/trackpreference.go
+ * - Filename: trackpreference.go
+ * - Package: trackpreference
+ */
+export type Trackpreference_Preference = {
+    /**
+     * AniList username of the user the preference applies to, empty for every user
+     */
+    username: string
+    /**
+     * 0 for the default preference
+     */
+    mediaId: number
+    /**
+     * Language codes sorted by priority, e.g. ["jpn", "eng"]
+     */
+    audioLanguages?: Array<string>
+    subtitleLanguages?: Array<string>
+    subtitleType: Trackpreference_SubtitleType
+    /**
+     * Forced subtitle tracks are only selected if there are no other tracks
+     */
+    avoidForcedSubtitles: boolean
+    disableSubtitles: boolean
+}
+
+/**
+ * - Filepath: internal/trackpreference/select.go
+ * - Filename: select.go
+ * - Package: trackpreference
+ */
+export type Trackpreference_Selection = {
+    /**
+     * Index of the audio track to play, nil to keep the default track of the file
+     */
+    audioIndex?: number
+    /**
+     * Index of the subtitle track to display, nil to keep the default track of the file
+     */
+    subtitleIndex?: number
+    /**
+     * Subtitles should not be displayed
+     */
+    subtitlesDisabled: boolean
+}
+
+/**
+ * - Filepath: internal/trackpreference/trackpreference.go
+ * - Filename: trackpreference.go
+ * - Package: trackpreference
+ */
+export type Trackpreference_SubtitleType = "" | "full" | "signs"
+
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 // Tvdb
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -4294,6 +5249,22 @@ export type Updater_Update = {
     type: string
 }
 
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Upnp
+//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+/**
+ * - Filepath: internal/mediaplayers/upnp/upnp.go
+ * - Filename: upnp.go
+ * - Package: upnp
+ */
+export type Upnp_DiscoveredRenderer = {
+    name: string
+    location: string
+    manufacturer: string
+    model: string
+}
+
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 // User
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -1,7 +1,20 @@
 import { useServerMutation, useServerQuery } from "@/api/client/requests"
-import { GetContinuityWatchHistoryItem_Variables, UpdateContinuityWatchHistoryItem_Variables } from "@/api/generated/endpoint.types"
+import {
+    GetContinuityWatchEvents_Variables,
+    GetContinuityWatchHistoryItem_Variables,
+    GetContinuityWatchStats_Variables,
+    ImportContinuityWatchHistory_Variables,
+    UpdateContinuityWatchHistoryItem_Variables,
+} from "@/api/generated/endpoint.types"
 import { API_ENDPOINTS } from "@/api/generated/endpoints"
-import { Continuity_WatchHistory, Continuity_WatchHistoryItemResponse, Nullish } from "@/api/generated/types"
+import {
+    Continuity_WatchHistory,
+    Continuity_WatchHistoryExport,
+    Continuity_WatchHistoryItemResponse,
+    Continuity_WatchStats,
+    Models_WatchEvent,
+    Nullish,
+} from "@/api/generated/types"
 import { useServerStatus } from "@/app/(main)/_hooks/use-server-status"
 import { logger } from "@/lib/helpers/debug"
 import { useQueryClient } from "@tanstack/react-query"
@@ -39,7 +52,61 @@ export function useGetContinuityWatchHistory() {
     })
 }
 
-export function getEpisodePercentageComplete(history: Nullish<Continuity_WatchHistory>, mediaId: number, progressNumber: number) {
+export function useGetContinuityWatchEvents(variables: GetContinuityWatchEvents_Variables) {
+    return useServerQuery<Array<Models_WatchEvent>, GetContinuityWatchEvents_Variables>({
+        endpoint: API_ENDPOINTS.CONTINUITY.GetContinuityWatchEvents.endpoint,
+        method: API_ENDPOINTS.CONTINUITY.GetContinuityWatchEvents.methods[0],
+        queryKey: [API_ENDPOINTS.CONTINUITY.GetContinuityWatchEvents.key, JSON.stringify(variables)],
+        data: variables,
+        enabled: true,
+    })
+}
+
+export function useDeleteContinuityWatchEvent(id: number) {
+    const qc = useQueryClient()
+    return useServerMutation<boolean>({
+        endpoint: API_ENDPOINTS.CONTINUITY.DeleteContinuityWatchEvent.endpoint.replace("{id}", String(id)),
+        method: API_ENDPOINTS.CONTINUITY.DeleteContinuityWatchEvent.methods[0],
+        mutationKey: [API_ENDPOINTS.CONTINUITY.DeleteContinuityWatchEvent.key, String(id)],
+        onSuccess: async () => {
+            await qc.invalidateQueries({ queryKey: [API_ENDPOINTS.CONTINUITY.GetContinuityWatchEvents.key] })
+            await qc.invalidateQueries({ queryKey: [API_ENDPOINTS.CONTINUITY.GetContinuityWatchStats.key] })
+        },
+    })
+}
+
+export function useGetContinuityWatchStats(variables: GetContinuityWatchStats_Variables) {
+    return useServerQuery<Continuity_WatchStats, GetContinuityWatchStats_Variables>({
+        endpoint: API_ENDPOINTS.CONTINUITY.GetContinuityWatchStats.endpoint,
+        method: API_ENDPOINTS.CONTINUITY.GetContinuityWatchStats.methods[0],
+        queryKey: [API_ENDPOINTS.CONTINUITY.GetContinuityWatchStats.key, JSON.stringify(variables)],
+        data: variables,
+        enabled: true,
+    })
+}
+
+export function useExportContinuityWatchHistory() {
+    return useServerMutation<Continuity_WatchHistoryExport>({
+        endpoint: API_ENDPOINTS.CONTINUITY.ExportContinuityWatchHistory.endpoint,
+        method: API_ENDPOINTS.CONTINUITY.ExportContinuityWatchHistory.methods[0],
+        mutationKey: [API_ENDPOINTS.CONTINUITY.ExportContinuityWatchHistory.key],
+    })
+}
+
+export function useImportContinuityWatchHistory() {
+    const qc = useQueryClient()
+    return useServerMutation<number, ImportContinuityWatchHistory_Variables>({
+        endpoint: API_ENDPOINTS.CONTINUITY.ImportContinuityWatchHistory.endpoint,
+        method: API_ENDPOINTS.CONTINUITY.ImportContinuityWatchHistory.methods[0],
+        mutationKey: [API_ENDPOINTS.CONTINUITY.ImportContinuityWatchHistory.key],
+        onSuccess: async () => {
+            await qc.invalidateQueries({ queryKey: [API_ENDPOINTS.CONTINUITY.GetContinuityWatchHistory.key] })
+            await qc.invalidateQueries({ queryKey: [API_ENDPOINTS.CONTINUITY.GetContinuityWatchHistoryItem.key] })
+        },
+    })
+}
+
+history: Nullish<Continuity_WatchHistory>, mediaId: number, progressNumber: number) {
     if (!history) return 0
     const item = history[mediaId]
     if (!item || !item.currentTime || !item.duration) return 0
@@ -19,6 +19,7 @@ import {
     ExtensionRepo_ExtensionInstallResponse,
     ExtensionRepo_ExtensionUserConfig,
     ExtensionRepo_MangaProviderExtensionItem,
+    ExtensionRepo_MediaPlayerExtensionItem,
     ExtensionRepo_OnlinestreamProviderExtensionItem,
     ExtensionRepo_StoredPluginSettingsData,
     ExtensionRepo_UpdateData,
@@ -134,6 +135,15 @@ export function useListOnlinestreamProviderExtensions() {
     })
 }
 
+export function useListMediaPlayerExtensions() {
+    return useServerQuery<Array<ExtensionRepo_MediaPlayerExtensionItem>>({
+        endpoint: API_ENDPOINTS.EXTENSIONS.ListMediaPlayerExtensions.endpoint,
+        method: API_ENDPOINTS.EXTENSIONS.ListMediaPlayerExtensions.methods[0],
+        queryKey: [API_ENDPOINTS.EXTENSIONS.ListMediaPlayerExtensions.key],
+        enabled: true,
+    })
+}
+
 export function useAnimeListTorrentProviderExtensions() {
     return useServerQuery<Array<ExtensionRepo_AnimeTorrentProviderExtensionItem>>({
         endpoint: API_ENDPOINTS.EXTENSIONS.ListAnimeTorrentProviderExtensions.endpoint,
@@ -0,0 +1,24 @@
+import { useServerMutation } from "@/api/client/requests"
+import { ExportLists_Variables, ImportLists_Variables } from "@/api/generated/endpoint.types"
+import { API_ENDPOINTS } from "@/api/generated/endpoints"
+import { Listexport_File } from "@/api/generated/types"
+import { toast } from "sonner"
+
+export function useExportLists() {
+    return useServerMutation<Listexport_File, ExportLists_Variables>({
+        endpoint: API_ENDPOINTS.LIST_EXPORT.ExportLists.endpoint,
+        method: API_ENDPOINTS.LIST_EXPORT.ExportLists.methods[0],
+        mutationKey: [API_ENDPOINTS.LIST_EXPORT.ExportLists.key],
+    })
+}
+
+export function useImportLists() {
+    return useServerMutation<number, ImportLists_Variables>({
+        endpoint: API_ENDPOINTS.LIST_EXPORT.ImportLists.endpoint,
+        method: API_ENDPOINTS.LIST_EXPORT.ImportLists.methods[0],
+        mutationKey: [API_ENDPOINTS.LIST_EXPORT.ImportLists.key],
+        onSuccess: async (count) => {
+            toast.info(`Importing ${count} entries...`)
+        },
+    })
+}
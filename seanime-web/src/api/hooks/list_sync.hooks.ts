@@ -0,0 +1,43 @@
+import { useServerMutation } from "@/api/client/requests"
+import { SaveListSyncSettings_Variables } from "@/api/generated/endpoint.types"
+import { API_ENDPOINTS } from "@/api/generated/endpoints"
+import { Listsync_Preview, Listsync_Result } from "@/api/generated/types"
+import { useQueryClient } from "@tanstack/react-query"
+import { toast } from "sonner"
+
+export function useSaveListSyncSettings() {
+    const queryClient = useQueryClient()
+
+    return useServerMutation<boolean, SaveListSyncSettings_Variables>({
+        endpoint: API_ENDPOINTS.LIST_SYNC.SaveListSyncSettings.endpoint,
+        method: API_ENDPOINTS.LIST_SYNC.SaveListSyncSettings.methods[0],
+        mutationKey: [API_ENDPOINTS.LIST_SYNC.SaveListSyncSettings.key],
+        onSuccess: async () => {
+            await queryClient.invalidateQueries({ queryKey: [API_ENDPOINTS.SETTINGS.GetSettings.key] })
+            toast.success("Settings saved")
+        },
+    })
+}
+
+export function useListSyncPreview() {
+    return useServerMutation<Listsync_Preview>({
+        endpoint: API_ENDPOINTS.LIST_SYNC.ListSyncPreview.endpoint,
+        method: API_ENDPOINTS.LIST_SYNC.ListSyncPreview.methods[0],
+        mutationKey: [API_ENDPOINTS.LIST_SYNC.ListSyncPreview.key],
+    })
+}
+
+export function useListSyncApply() {
+    const queryClient = useQueryClient()
+
+    return useServerMutation<Listsync_Result>({
+        endpoint: API_ENDPOINTS.LIST_SYNC.ListSyncApply.endpoint,
+        method: API_ENDPOINTS.LIST_SYNC.ListSyncApply.methods[0],
+        mutationKey: [API_ENDPOINTS.LIST_SYNC.ListSyncApply.key],
+        onSuccess: async () => {
+            await queryClient.invalidateQueries({ queryKey: [API_ENDPOINTS.ANIME_COLLECTION.GetLibraryCollection.key] })
+            await queryClient.invalidateQueries({ queryKey: [API_ENDPOINTS.ANILIST.GetAnimeCollection.key] })
+            await queryClient.invalidateQueries({ queryKey: [API_ENDPOINTS.MANGA.GetAnilistMangaCollection.key] })
+        },
+    })
+}
@@ -1,12 +1,22 @@
 import { useServerMutation, useServerQuery } from "@/api/client/requests"
 import { API_ENDPOINTS } from "@/api/generated/endpoints"
-import { Local_QueueState, Local_TrackedMediaItem } from "@/api/generated/types"
+import {
+    Local_OfflineDownloadSettings,
+    Local_OfflineEpisode,
+    Local_QueueState,
+    Local_SyncEstimate,
+    Local_SyncSettings,
+    Local_TrackedMediaItem,
+} from "@/api/generated/types"
 import { useQueryClient } from "@tanstack/react-query"
 import { toast } from "sonner"
 import {
     LocalAddTrackedMedia_Variables,
     LocalRemoveTrackedMedia_Variables,
+    LocalSaveOfflineDownloadSettings_Variables,
+    LocalSaveSyncSettings_Variables,
     LocalSetHasLocalChanges_Variables,
+    LocalSetTrackedMediaPriority_Variables,
     SetOfflineMode_Variables,
 } from "../generated/endpoint.types"
 
@@ -163,3 +173,102 @@ export function useSetOfflineMode() {
         },
     })
 }
+
+export function useLocalGetOfflineDownloadSettings() {
+    return useServerQuery<Local_OfflineDownloadSettings>({
+        endpoint: API_ENDPOINTS.LOCAL.LocalGetOfflineDownloadSettings.endpoint,
+        method: API_ENDPOINTS.LOCAL.LocalGetOfflineDownloadSettings.methods[0],
+        queryKey: [API_ENDPOINTS.LOCAL.LocalGetOfflineDownloadSettings.key],
+        enabled: true,
+    })
+}
+
+export function useLocalSaveOfflineDownloadSettings() {
+    const qc = useQueryClient()
+    return useServerMutation<Local_OfflineDownloadSettings, LocalSaveOfflineDownloadSettings_Variables>({
+        endpoint: API_ENDPOINTS.LOCAL.LocalSaveOfflineDownloadSettings.endpoint,
+        method: API_ENDPOINTS.LOCAL.LocalSaveOfflineDownloadSettings.methods[0],
+        mutationKey: [API_ENDPOINTS.LOCAL.LocalSaveOfflineDownloadSettings.key],
+        onSuccess: async () => {
+            await qc.invalidateQueries({ queryKey: [API_ENDPOINTS.LOCAL.LocalGetOfflineDownloadSettings.key] })
+            toast.success("Settings saved")
+        },
+    })
+}
+
+export function useLocalGetOfflineEpisodes() {
+    return useServerQuery<Array<Local_OfflineEpisode>>({
+        endpoint: API_ENDPOINTS.LOCAL.LocalGetOfflineEpisodes.endpoint,
+        method: API_ENDPOINTS.LOCAL.LocalGetOfflineEpisodes.methods[0],
+        queryKey: [API_ENDPOINTS.LOCAL.LocalGetOfflineEpisodes.key],
+        enabled: true,
+    })
+}
+
+export function useLocalRunOfflineDownloader() {
+    return useServerMutation<boolean>({
+        endpoint: API_ENDPOINTS.LOCAL.LocalRunOfflineDownloader.endpoint,
+        method: API_ENDPOINTS.LOCAL.LocalRunOfflineDownloader.methods[0],
+        mutationKey: [API_ENDPOINTS.LOCAL.LocalRunOfflineDownloader.key],
+        onSuccess: async () => {
+            toast.info("Checking upcoming episodes...")
+        },
+    })
+}
+
+export function useLocalDeleteOfflineEpisode(id: number) {
+    const qc = useQueryClient()
+    return useServerMutation<boolean>({
+        endpoint: API_ENDPOINTS.LOCAL.LocalDeleteOfflineEpisode.endpoint.replace("{id}", String(id)),
+        method: API_ENDPOINTS.LOCAL.LocalDeleteOfflineEpisode.methods[0],
+        mutationKey: [API_ENDPOINTS.LOCAL.LocalDeleteOfflineEpisode.key, id],
+        onSuccess: async () => {
+            await qc.invalidateQueries({ queryKey: [API_ENDPOINTS.LOCAL.LocalGetOfflineEpisodes.key] })
+            await qc.invalidateQueries({ queryKey: [API_ENDPOINTS.ANIME_COLLECTION.GetLibraryCollection.key] })
+            await qc.invalidateQueries({ queryKey: [API_ENDPOINTS.ANIME_ENTRIES.GetAnimeEntry.key] })
+            toast.success("Episode removed")
+        },
+    })
+}
+
+export function useLocalGetSyncSettings() {
+    return useServerQuery<Local_SyncSettings>({
+        endpoint: API_ENDPOINTS.LOCAL.LocalGetSyncSettings.endpoint,
+        method: API_ENDPOINTS.LOCAL.LocalGetSyncSettings.methods[0],
+        queryKey: [API_ENDPOINTS.LOCAL.LocalGetSyncSettings.key],
+        enabled: true,
+    })
+}
+
+export function useLocalSaveSyncSettings() {
+    const qc = useQueryClient()
+    return useServerMutation<Local_SyncSettings, LocalSaveSyncSettings_Variables>({
+        endpoint: API_ENDPOINTS.LOCAL.LocalSaveSyncSettings.endpoint,
+        method: API_ENDPOINTS.LOCAL.LocalSaveSyncSettings.methods[0],
+        mutationKey: [API_ENDPOINTS.LOCAL.LocalSaveSyncSettings.key],
+        onSuccess: async () => {
+            await qc.invalidateQueries({ queryKey: [API_ENDPOINTS.LOCAL.LocalGetSyncSettings.key] })
+            toast.success("Settings saved")
+        },
+    })
+}
+
+export function useLocalEstimateSync() {
+    return useServerMutation<Local_SyncEstimate>({
+        endpoint: API_ENDPOINTS.LOCAL.LocalEstimateSync.endpoint,
+        method: API_ENDPOINTS.LOCAL.LocalEstimateSync.methods[0],
+        mutationKey: [API_ENDPOINTS.LOCAL.LocalEstimateSync.key],
+    })
+}
+
+export function useLocalSetTrackedMediaPriority() {
+    const qc = useQueryClient()
+    return useServerMutation<boolean, LocalSetTrackedMediaPriority_Variables>({
+        endpoint: API_ENDPOINTS.LOCAL.LocalSetTrackedMediaPriority.endpoint,
+        method: API_ENDPOINTS.LOCAL.LocalSetTrackedMediaPriority.methods[0],
+        mutationKey: [API_ENDPOINTS.LOCAL.LocalSetTrackedMediaPriority.key],
+        onSuccess: async () => {
+            await qc.invalidateQueries({ queryKey: [API_ENDPOINTS.LOCAL.LocalGetTrackedMediaItems.key] })
+        },
+    })
+}
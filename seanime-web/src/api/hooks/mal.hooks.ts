@@ -1,7 +1,7 @@
 import { useServerMutation, useServerQuery } from "@/api/client/requests"
-import { EditMALListEntryProgress_Variables, MALAuth_Variables } from "@/api/generated/endpoint.types"
+import { EditMALListEntryProgress_Variables, MALAuth_Variables, SetMALAccountPlatform_Variables } from "@/api/generated/endpoint.types"
 import { API_ENDPOINTS } from "@/api/generated/endpoints"
-import { MalAuthResponse } from "@/api/generated/types"
+import { MalAuthResponse, Status } from "@/api/generated/types"
 import { useQueryClient } from "@tanstack/react-query"
 import { toast } from "sonner"
 
@@ -40,3 +40,19 @@ export function useMALLogout() {
     })
 }
 
+
+export function useSetMALAccountPlatform() {
+    const queryClient = useQueryClient()
+
+    return useServerMutation<Status, SetMALAccountPlatform_Variables>({
+        endpoint: API_ENDPOINTS.MAL.SetMALAccountPlatform.endpoint,
+        method: API_ENDPOINTS.MAL.SetMALAccountPlatform.methods[0],
+        mutationKey: [API_ENDPOINTS.MAL.SetMALAccountPlatform.key],
+        onSuccess: async () => {
+            await queryClient.invalidateQueries({ queryKey: [API_ENDPOINTS.STATUS.GetStatus.key] })
+            await queryClient.invalidateQueries({ queryKey: [API_ENDPOINTS.ANIME_COLLECTION.GetLibraryCollection.key] })
+            await queryClient.invalidateQueries({ queryKey: [API_ENDPOINTS.ANILIST.GetAnimeCollection.key] })
+            await queryClient.invalidateQueries({ queryKey: [API_ENDPOINTS.MANGA.GetAnilistMangaCollection.key] })
+        },
+    })
+}
@@ -1,5 +1,6 @@
-import { useServerMutation } from "@/api/client/requests"
+import { useServerMutation, useServerQuery } from "@/api/client/requests"
 import { API_ENDPOINTS } from "@/api/generated/endpoints"
+import { Upnp_DiscoveredRenderer } from "@/api/generated/types"
 
 export function useStartDefaultMediaPlayer() {
     return useServerMutation<boolean>({
@@ -12,3 +13,12 @@ export function useStartDefaultMediaPlayer() {
     })
 }
 
+export function useDiscoverUpnpRenderers(enabled: boolean) {
+    return useServerQuery<Array<Upnp_DiscoveredRenderer>>({
+        endpoint: API_ENDPOINTS.MEDIAPLAYER.DiscoverUpnpRenderers.endpoint,
+        method: API_ENDPOINTS.MEDIAPLAYER.DiscoverUpnpRenderers.methods[0],
+        queryKey: [API_ENDPOINTS.MEDIAPLAYER.DiscoverUpnpRenderers.key],
+        enabled: enabled,
+    })
+}
+
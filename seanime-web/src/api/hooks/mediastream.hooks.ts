@@ -1,5 +1,6 @@
 import { useServerMutation, useServerQuery } from "@/api/client/requests"
 import {
+    GenerateMediastreamTrickplay_Variables,
     PreloadMediastreamMediaContainer_Variables,
     RequestMediastreamMediaContainer_Variables,
     SaveMediastreamSettings_Variables,
@@ -64,3 +65,14 @@ export function useMediastreamShutdownTranscodeStream() {
         },
     })
 }
+
+export function useGenerateMediastreamTrickplay() {
+    return useServerMutation<boolean, GenerateMediastreamTrickplay_Variables>({
+        endpoint: API_ENDPOINTS.MEDIASTREAM.GenerateMediastreamTrickplay.endpoint,
+        method: API_ENDPOINTS.MEDIASTREAM.GenerateMediastreamTrickplay.methods[0],
+        mutationKey: [API_ENDPOINTS.MEDIASTREAM.GenerateMediastreamTrickplay.key],
+        onSuccess: async () => {
+            toast.info("Generating seek preview thumbnails in the background")
+        },
+    })
+}
@@ -0,0 +1,39 @@
+import { useServerMutation, useServerQuery } from "@/api/client/requests"
+import { API_ENDPOINTS } from "@/api/generated/endpoints"
+import { Outbox_Item } from "@/api/generated/types"
+import { useQueryClient } from "@tanstack/react-query"
+
+export function useGetOutboxItems() {
+    return useServerQuery<Array<Outbox_Item>>({
+        endpoint: API_ENDPOINTS.OUTBOX.GetOutboxItems.endpoint,
+        method: API_ENDPOINTS.OUTBOX.GetOutboxItems.methods[0],
+        queryKey: [API_ENDPOINTS.OUTBOX.GetOutboxItems.key],
+        enabled: true,
+    })
+}
+
+export function useRetryOutboxItem(id: number) {
+    const queryClient = useQueryClient()
+
+    return useServerMutation<boolean>({
+        endpoint: API_ENDPOINTS.OUTBOX.RetryOutboxItem.endpoint.replace("{id}", String(id)),
+        method: API_ENDPOINTS.OUTBOX.RetryOutboxItem.methods[0],
+        mutationKey: [API_ENDPOINTS.OUTBOX.RetryOutboxItem.key, String(id)],
+        onSuccess: async () => {
+            await queryClient.invalidateQueries({ queryKey: [API_ENDPOINTS.OUTBOX.GetOutboxItems.key] })
+        },
+    })
+}
+
+export function useDiscardOutboxItem(id: number) {
+    const queryClient = useQueryClient()
+
+    return useServerMutation<boolean>({
+        endpoint: API_ENDPOINTS.OUTBOX.DiscardOutboxItem.endpoint.replace("{id}", String(id)),
+        method: API_ENDPOINTS.OUTBOX.DiscardOutboxItem.methods[0],
+        mutationKey: [API_ENDPOINTS.OUTBOX.DiscardOutboxItem.key, String(id)],
+        onSuccess: async () => {
+            await queryClient.invalidateQueries({ queryKey: [API_ENDPOINTS.OUTBOX.GetOutboxItems.key] })
+        },
+    })
+}
@@ -0,0 +1,182 @@
+import { useServerMutation, useServerQuery } from "@/api/client/requests"
+import {
+    PlaybackQueueAddItems_Variables,
+    PlaybackQueueGetWatchOrder_Variables,
+    PlaybackQueueMoveItem_Variables,
+    PlaybackQueuePlay_Variables,
+    PlaybackQueueRemoveItem_Variables,
+    SavePlaybackQueuePlaylist_Variables,
+} from "@/api/generated/endpoint.types"
+import { API_ENDPOINTS } from "@/api/generated/endpoints"
+import { Playbackqueue_Item, Playbackqueue_Playlist, Playbackqueue_State } from "@/api/generated/types"
+import { useQueryClient } from "@tanstack/react-query"
+import { toast } from "sonner"
+
+export function useGetPlaybackQueue() {
+    return useServerQuery<Playbackqueue_State>({
+        endpoint: API_ENDPOINTS.PLAYBACK_QUEUE.GetPlaybackQueue.endpoint,
+        method: API_ENDPOINTS.PLAYBACK_QUEUE.GetPlaybackQueue.methods[0],
+        queryKey: [API_ENDPOINTS.PLAYBACK_QUEUE.GetPlaybackQueue.key],
+        enabled: true,
+    })
+}
+
+export function usePlaybackQueueAddItems() {
+    const queryClient = useQueryClient()
+
+    return useServerMutation<Playbackqueue_State, PlaybackQueueAddItems_Variables>({
+        endpoint: API_ENDPOINTS.PLAYBACK_QUEUE.PlaybackQueueAddItems.endpoint,
+        method: API_ENDPOINTS.PLAYBACK_QUEUE.PlaybackQueueAddItems.methods[0],
+        mutationKey: [API_ENDPOINTS.PLAYBACK_QUEUE.PlaybackQueueAddItems.key],
+        onSuccess: async () => {
+            await queryClient.invalidateQueries({ queryKey: [API_ENDPOINTS.PLAYBACK_QUEUE.GetPlaybackQueue.key] })
+            toast.success("Added to queue")
+        },
+    })
+}
+
+export function usePlaybackQueueRemoveItem() {
+    const queryClient = useQueryClient()
+
+    return useServerMutation<Playbackqueue_State, PlaybackQueueRemoveItem_Variables>({
+        endpoint: API_ENDPOINTS.PLAYBACK_QUEUE.PlaybackQueueRemoveItem.endpoint,
+        method: API_ENDPOINTS.PLAYBACK_QUEUE.PlaybackQueueRemoveItem.methods[0],
+        mutationKey: [API_ENDPOINTS.PLAYBACK_QUEUE.PlaybackQueueRemoveItem.key],
+        onSuccess: async () => {
+            await queryClient.invalidateQueries({ queryKey: [API_ENDPOINTS.PLAYBACK_QUEUE.GetPlaybackQueue.key] })
+        },
+    })
+}
+
+export function usePlaybackQueueMoveItem() {
+    const queryClient = useQueryClient()
+
+    return useServerMutation<Playbackqueue_State, PlaybackQueueMoveItem_Variables>({
+        endpoint: API_ENDPOINTS.PLAYBACK_QUEUE.PlaybackQueueMoveItem.endpoint,
+        method: API_ENDPOINTS.PLAYBACK_QUEUE.PlaybackQueueMoveItem.methods[0],
+        mutationKey: [API_ENDPOINTS.PLAYBACK_QUEUE.PlaybackQueueMoveItem.key],
+        onSuccess: async () => {
+            await queryClient.invalidateQueries({ queryKey: [API_ENDPOINTS.PLAYBACK_QUEUE.GetPlaybackQueue.key] })
+        },
+    })
+}
+
+export function usePlaybackQueueClear() {
+    const queryClient = useQueryClient()
+
+    return useServerMutation<boolean>({
+        endpoint: API_ENDPOINTS.PLAYBACK_QUEUE.PlaybackQueueClear.endpoint,
+        method: API_ENDPOINTS.PLAYBACK_QUEUE.PlaybackQueueClear.methods[0],
+        mutationKey: [API_ENDPOINTS.PLAYBACK_QUEUE.PlaybackQueueClear.key],
+        onSuccess: async () => {
+            await queryClient.invalidateQueries({ queryKey: [API_ENDPOINTS.PLAYBACK_QUEUE.GetPlaybackQueue.key] })
+        },
+    })
+}
+
+export function usePlaybackQueuePlay() {
+    return useServerMutation<boolean, PlaybackQueuePlay_Variables>({
+        endpoint: API_ENDPOINTS.PLAYBACK_QUEUE.PlaybackQueuePlay.endpoint,
+        method: API_ENDPOINTS.PLAYBACK_QUEUE.PlaybackQueuePlay.methods[0],
+        mutationKey: [API_ENDPOINTS.PLAYBACK_QUEUE.PlaybackQueuePlay.key],
+        onSuccess: async () => {
+
+        },
+    })
+}
+
+export function usePlaybackQueueNext() {
+    return useServerMutation<boolean>({
+        endpoint: API_ENDPOINTS.PLAYBACK_QUEUE.PlaybackQueueNext.endpoint,
+        method: API_ENDPOINTS.PLAYBACK_QUEUE.PlaybackQueueNext.methods[0],
+        mutationKey: [API_ENDPOINTS.PLAYBACK_QUEUE.PlaybackQueueNext.key],
+        onSuccess: async () => {
+
+        },
+    })
+}
+
+export function usePlaybackQueuePrevious() {
+    return useServerMutation<boolean>({
+        endpoint: API_ENDPOINTS.PLAYBACK_QUEUE.PlaybackQueuePrevious.endpoint,
+        method: API_ENDPOINTS.PLAYBACK_QUEUE.PlaybackQueuePrevious.methods[0],
+        mutationKey: [API_ENDPOINTS.PLAYBACK_QUEUE.PlaybackQueuePrevious.key],
+        onSuccess: async () => {
+
+        },
+    })
+}
+
+export function usePlaybackQueueStop() {
+    return useServerMutation<boolean>({
+        endpoint: API_ENDPOINTS.PLAYBACK_QUEUE.PlaybackQueueStop.endpoint,
+        method: API_ENDPOINTS.PLAYBACK_QUEUE.PlaybackQueueStop.methods[0],
+        mutationKey: [API_ENDPOINTS.PLAYBACK_QUEUE.PlaybackQueueStop.key],
+        onSuccess: async () => {
+
+        },
+    })
+}
+
+export function usePlaybackQueueGetWatchOrder() {
+    const queryClient = useQueryClient()
+
+    return useServerMutation<Array<Playbackqueue_Item>, PlaybackQueueGetWatchOrder_Variables>({
+        endpoint: API_ENDPOINTS.PLAYBACK_QUEUE.PlaybackQueueGetWatchOrder.endpoint,
+        method: API_ENDPOINTS.PLAYBACK_QUEUE.PlaybackQueueGetWatchOrder.methods[0],
+        mutationKey: [API_ENDPOINTS.PLAYBACK_QUEUE.PlaybackQueueGetWatchOrder.key],
+        onSuccess: async () => {
+            await queryClient.invalidateQueries({ queryKey: [API_ENDPOINTS.PLAYBACK_QUEUE.GetPlaybackQueue.key] })
+        },
+    })
+}
+
+export function useGetPlaybackQueuePlaylists() {
+    return useServerQuery<Array<Playbackqueue_Playlist>>({
+        endpoint: API_ENDPOINTS.PLAYBACK_QUEUE.GetPlaybackQueuePlaylists.endpoint,
+        method: API_ENDPOINTS.PLAYBACK_QUEUE.GetPlaybackQueuePlaylists.methods[0],
+        queryKey: [API_ENDPOINTS.PLAYBACK_QUEUE.GetPlaybackQueuePlaylists.key],
+        enabled: true,
+    })
+}
+
+export function useSavePlaybackQueuePlaylist() {
+    const queryClient = useQueryClient()
+
+    return useServerMutation<Playbackqueue_Playlist, SavePlaybackQueuePlaylist_Variables>({
+        endpoint: API_ENDPOINTS.PLAYBACK_QUEUE.SavePlaybackQueuePlaylist.endpoint,
+        method: API_ENDPOINTS.PLAYBACK_QUEUE.SavePlaybackQueuePlaylist.methods[0],
+        mutationKey: [API_ENDPOINTS.PLAYBACK_QUEUE.SavePlaybackQueuePlaylist.key],
+        onSuccess: async () => {
+            await queryClient.invalidateQueries({ queryKey: [API_ENDPOINTS.PLAYBACK_QUEUE.GetPlaybackQueuePlaylists.key] })
+            toast.success("Playlist saved")
+        },
+    })
+}
+
+export function useLoadPlaybackQueuePlaylist(id: number) {
+    const queryClient = useQueryClient()
+
+    return useServerMutation<Playbackqueue_State>({
+        endpoint: API_ENDPOINTS.PLAYBACK_QUEUE.LoadPlaybackQueuePlaylist.endpoint.replace("{id}", String(id)),
+        method: API_ENDPOINTS.PLAYBACK_QUEUE.LoadPlaybackQueuePlaylist.methods[0],
+        mutationKey: [API_ENDPOINTS.PLAYBACK_QUEUE.LoadPlaybackQueuePlaylist.key, String(id)],
+        onSuccess: async () => {
+            await queryClient.invalidateQueries({ queryKey: [API_ENDPOINTS.PLAYBACK_QUEUE.GetPlaybackQueue.key] })
+        },
+    })
+}
+
+export function useDeletePlaybackQueuePlaylist(id: number) {
+    const queryClient = useQueryClient()
+
+    return useServerMutation<boolean>({
+        endpoint: API_ENDPOINTS.PLAYBACK_QUEUE.DeletePlaybackQueuePlaylist.endpoint.replace("{id}", String(id)),
+        method: API_ENDPOINTS.PLAYBACK_QUEUE.DeletePlaybackQueuePlaylist.methods[0],
+        mutationKey: [API_ENDPOINTS.PLAYBACK_QUEUE.DeletePlaybackQueuePlaylist.key, String(id)],
+        onSuccess: async () => {
+            await queryClient.invalidateQueries({ queryKey: [API_ENDPOINTS.PLAYBACK_QUEUE.GetPlaybackQueuePlaylists.key] })
+            toast.success("Playlist deleted")
+        },
+    })
+}
@@ -0,0 +1,80 @@
+import { useServerMutation, useServerQuery } from "@/api/client/requests"
+import { CreateProfile_Variables, DeleteProfile_Variables, SelectProfile_Variables, UpdateProfile_Variables } from "@/api/generated/endpoint.types"
+import { API_ENDPOINTS } from "@/api/generated/endpoints"
+import { Profile_Profile, Status } from "@/api/generated/types"
+import { useSetServerStatus } from "@/app/(main)/_hooks/use-server-status"
+import { useQueryClient } from "@tanstack/react-query"
+import { useRouter } from "next/navigation"
+import { toast } from "sonner"
+
+export function useGetProfiles() {
+    return useServerQuery<Array<Profile_Profile>>({
+        endpoint: API_ENDPOINTS.PROFILE.GetProfiles.endpoint,
+        method: API_ENDPOINTS.PROFILE.GetProfiles.methods[0],
+        queryKey: [API_ENDPOINTS.PROFILE.GetProfiles.key],
+        enabled: true,
+    })
+}
+
+export function useCreateProfile() {
+    const queryClient = useQueryClient()
+
+    return useServerMutation<Profile_Profile, CreateProfile_Variables>({
+        endpoint: API_ENDPOINTS.PROFILE.CreateProfile.endpoint,
+        method: API_ENDPOINTS.PROFILE.CreateProfile.methods[0],
+        mutationKey: [API_ENDPOINTS.PROFILE.CreateProfile.key],
+        onSuccess: async () => {
+            toast.success("Profile created")
+            await queryClient.invalidateQueries({ queryKey: [API_ENDPOINTS.PROFILE.GetProfiles.key] })
+        },
+    })
+}
+
+export function useUpdateProfile() {
+    const queryClient = useQueryClient()
+
+    return useServerMutation<Profile_Profile, UpdateProfile_Variables>({
+        endpoint: API_ENDPOINTS.PROFILE.UpdateProfile.endpoint,
+        method: API_ENDPOINTS.PROFILE.UpdateProfile.methods[0],
+        mutationKey: [API_ENDPOINTS.PROFILE.UpdateProfile.key],
+        onSuccess: async () => {
+            toast.success("Profile updated")
+            await queryClient.invalidateQueries({ queryKey: [API_ENDPOINTS.PROFILE.GetProfiles.key] })
+        },
+    })
+}
+
+export function useDeleteProfile() {
+    const queryClient = useQueryClient()
+
+    return useServerMutation<boolean, DeleteProfile_Variables>({
+        endpoint: API_ENDPOINTS.PROFILE.DeleteProfile.endpoint,
+        method: API_ENDPOINTS.PROFILE.DeleteProfile.methods[0],
+        mutationKey: [API_ENDPOINTS.PROFILE.DeleteProfile.key],
+        onSuccess: async () => {
+            toast.success("Profile deleted")
+            await queryClient.invalidateQueries({ queryKey: [API_ENDPOINTS.PROFILE.GetProfiles.key] })
+        },
+    })
+}
+
+export function useSelectProfile() {
+    const queryClient = useQueryClient()
+    const router = useRouter()
+    const setServerStatus = useSetServerStatus()
+
+    return useServerMutation<Status, SelectProfile_Variables>({
+        endpoint: API_ENDPOINTS.PROFILE.SelectProfile.endpoint,
+        method: API_ENDPOINTS.PROFILE.SelectProfile.methods[0],
+        mutationKey: [API_ENDPOINTS.PROFILE.SelectProfile.key],
+        onSuccess: async data => {
+            if (data) {
+                toast.success("Profile selected")
+                setServerStatus(data)
+                router.push("/")
+                // The data of every page depends on the profile
+                await queryClient.invalidateQueries()
+            }
+        },
+    })
+}
@@ -0,0 +1,52 @@
+import { useServerMutation, useServerQuery } from "@/api/client/requests"
+import { ConnectScrobblerAccount_Variables } from "@/api/generated/endpoint.types"
+import { API_ENDPOINTS } from "@/api/generated/endpoints"
+import { Models_ScrobbleLog, Scrobbler_Account, Scrobbler_TrackerName } from "@/api/generated/types"
+import { useQueryClient } from "@tanstack/react-query"
+import { toast } from "sonner"
+
+export function useGetScrobblerAccounts() {
+    return useServerQuery<Array<Scrobbler_Account>>({
+        endpoint: API_ENDPOINTS.SCROBBLER.GetScrobblerAccounts.endpoint,
+        method: API_ENDPOINTS.SCROBBLER.GetScrobblerAccounts.methods[0],
+        queryKey: [API_ENDPOINTS.SCROBBLER.GetScrobblerAccounts.key],
+        enabled: true,
+    })
+}
+
+export function useConnectScrobblerAccount() {
+    const queryClient = useQueryClient()
+
+    return useServerMutation<Scrobbler_Account, ConnectScrobblerAccount_Variables>({
+        endpoint: API_ENDPOINTS.SCROBBLER.ConnectScrobblerAccount.endpoint,
+        method: API_ENDPOINTS.SCROBBLER.ConnectScrobblerAccount.methods[0],
+        mutationKey: [API_ENDPOINTS.SCROBBLER.ConnectScrobblerAccount.key],
+        onSuccess: async () => {
+            await queryClient.invalidateQueries({ queryKey: [API_ENDPOINTS.SCROBBLER.GetScrobblerAccounts.key] })
+            toast.success("Account connected")
+        },
+    })
+}
+
+export function useDisconnectScrobblerAccount(tracker: Scrobbler_TrackerName) {
+    const queryClient = useQueryClient()
+
+    return useServerMutation<boolean>({
+        endpoint: API_ENDPOINTS.SCROBBLER.DisconnectScrobblerAccount.endpoint.replace("{tracker}", tracker),
+        method: API_ENDPOINTS.SCROBBLER.DisconnectScrobblerAccount.methods[0],
+        mutationKey: [API_ENDPOINTS.SCROBBLER.DisconnectScrobblerAccount.key, tracker],
+        onSuccess: async () => {
+            await queryClient.invalidateQueries({ queryKey: [API_ENDPOINTS.SCROBBLER.GetScrobblerAccounts.key] })
+            toast.success("Account disconnected")
+        },
+    })
+}
+
+export function useGetScrobblerLogs(tracker: Scrobbler_TrackerName, enabled: boolean) {
+    return useServerQuery<Array<Models_ScrobbleLog>>({
+        endpoint: API_ENDPOINTS.SCROBBLER.GetScrobblerLogs.endpoint.replace("{tracker}", tracker),
+        method: API_ENDPOINTS.SCROBBLER.GetScrobblerLogs.methods[0],
+        queryKey: [API_ENDPOINTS.SCROBBLER.GetScrobblerLogs.key, tracker],
+        enabled: enabled,
+    })
+}
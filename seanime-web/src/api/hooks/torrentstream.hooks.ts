@@ -1,12 +1,22 @@
 import { useServerMutation, useServerQuery } from "@/api/client/requests"
 import {
+    DeleteTorrentstreamCacheEntry_Variables,
     GetTorrentstreamBatchHistory_Variables,
     GetTorrentstreamTorrentFilePreviews_Variables,
+    ImportTorrentstreamCacheEntry_Variables,
     SaveTorrentstreamSettings_Variables,
     TorrentstreamStartStream_Variables,
+    TorrentstreamStopStream_Variables,
 } from "@/api/generated/endpoint.types"
 import { API_ENDPOINTS } from "@/api/generated/endpoints"
-import { Models_TorrentstreamSettings, Nullish, Torrentstream_BatchHistoryResponse, Torrentstream_FilePreview } from "@/api/generated/types"
+import {
+    Anime_LocalFile,
+    Models_TorrentstreamSettings,
+    Nullish,
+    Torrentstream_BatchHistoryResponse,
+    Torrentstream_CacheEntry,
+    Torrentstream_FilePreview,
+} from "@/api/generated/types"
 import { useQueryClient } from "@tanstack/react-query"
 import { toast } from "sonner"
 
@@ -44,7 +54,7 @@ export function useTorrentstreamStartStream() {
 }
 
 export function useTorrentstreamStopStream() {
-    return useServerMutation<boolean>({
+    return useServerMutation<boolean, TorrentstreamStopStream_Variables>({
         endpoint: API_ENDPOINTS.TORRENTSTREAM.TorrentstreamStopStream.endpoint,
         method: API_ENDPOINTS.TORRENTSTREAM.TorrentstreamStopStream.methods[0],
         mutationKey: [API_ENDPOINTS.TORRENTSTREAM.TorrentstreamStopStream.key],
@@ -86,3 +96,40 @@ export function useGetTorrentstreamBatchHistory(mediaId: Nullish<string | number
         enabled: !!mediaId,
     })
 }
+
+export function useGetTorrentstreamCacheEntries(enabled: boolean) {
+    return useServerQuery<Array<Torrentstream_CacheEntry>>({
+        endpoint: API_ENDPOINTS.TORRENTSTREAM.GetTorrentstreamCacheEntries.endpoint,
+        method: API_ENDPOINTS.TORRENTSTREAM.GetTorrentstreamCacheEntries.methods[0],
+        queryKey: [API_ENDPOINTS.TORRENTSTREAM.GetTorrentstreamCacheEntries.key],
+        enabled: enabled,
+    })
+}
+
+export function useDeleteTorrentstreamCacheEntry() {
+    const qc = useQueryClient()
+    return useServerMutation<boolean, DeleteTorrentstreamCacheEntry_Variables>({
+        endpoint: API_ENDPOINTS.TORRENTSTREAM.DeleteTorrentstreamCacheEntry.endpoint,
+        method: API_ENDPOINTS.TORRENTSTREAM.DeleteTorrentstreamCacheEntry.methods[0],
+        mutationKey: [API_ENDPOINTS.TORRENTSTREAM.DeleteTorrentstreamCacheEntry.key],
+        onSuccess: async () => {
+            await qc.invalidateQueries({ queryKey: [API_ENDPOINTS.TORRENTSTREAM.GetTorrentstreamCacheEntries.key] })
+            toast.success("Removed from cache")
+        },
+    })
+}
+
+export function useImportTorrentstreamCacheEntry() {
+    const qc = useQueryClient()
+    return useServerMutation<Anime_LocalFile, ImportTorrentstreamCacheEntry_Variables>({
+        endpoint: API_ENDPOINTS.TORRENTSTREAM.ImportTorrentstreamCacheEntry.endpoint,
+        method: API_ENDPOINTS.TORRENTSTREAM.ImportTorrentstreamCacheEntry.methods[0],
+        mutationKey: [API_ENDPOINTS.TORRENTSTREAM.ImportTorrentstreamCacheEntry.key],
+        onSuccess: async () => {
+            await qc.invalidateQueries({ queryKey: [API_ENDPOINTS.TORRENTSTREAM.GetTorrentstreamCacheEntries.key] })
+            await qc.invalidateQueries({ queryKey: [API_ENDPOINTS.ANIME_COLLECTION.GetLibraryCollection.key] })
+            await qc.invalidateQueries({ queryKey: [API_ENDPOINTS.LOCALFILES.GetLocalFiles.key] })
+            toast.success("Episode added to the library")
+        },
+    })
+}
@@ -0,0 +1,41 @@
+import { useServerMutation, useServerQuery } from "@/api/client/requests"
+import { DeleteTrackPreference_Variables, SaveTrackPreference_Variables } from "@/api/generated/endpoint.types"
+import { API_ENDPOINTS } from "@/api/generated/endpoints"
+import { Trackpreference_Preference } from "@/api/generated/types"
+import { useQueryClient } from "@tanstack/react-query"
+import { toast } from "sonner"
+
+export function useGetTrackPreferences() {
+    return useServerQuery<Array<Trackpreference_Preference>>({
+        endpoint: API_ENDPOINTS.TRACK_PREFERENCES.GetTrackPreferences.endpoint,
+        method: API_ENDPOINTS.TRACK_PREFERENCES.GetTrackPreferences.methods[0],
+        queryKey: [API_ENDPOINTS.TRACK_PREFERENCES.GetTrackPreferences.key],
+        enabled: true,
+    })
+}
+
+export function useSaveTrackPreference() {
+    const qc = useQueryClient()
+    return useServerMutation<Trackpreference_Preference, SaveTrackPreference_Variables>({
+        endpoint: API_ENDPOINTS.TRACK_PREFERENCES.SaveTrackPreference.endpoint,
+        method: API_ENDPOINTS.TRACK_PREFERENCES.SaveTrackPreference.methods[0],
+        mutationKey: [API_ENDPOINTS.TRACK_PREFERENCES.SaveTrackPreference.key],
+        onSuccess: async () => {
+            await qc.invalidateQueries({ queryKey: [API_ENDPOINTS.TRACK_PREFERENCES.GetTrackPreferences.key] })
+            toast.success("Track preference saved")
+        },
+    })
+}
+
+export function useDeleteTrackPreference() {
+    const qc = useQueryClient()
+    return useServerMutation<boolean, DeleteTrackPreference_Variables>({
+        endpoint: API_ENDPOINTS.TRACK_PREFERENCES.DeleteTrackPreference.endpoint,
+        method: API_ENDPOINTS.TRACK_PREFERENCES.DeleteTrackPreference.methods[0],
+        mutationKey: [API_ENDPOINTS.TRACK_PREFERENCES.DeleteTrackPreference.key],
+        onSuccess: async () => {
+            await qc.invalidateQueries({ queryKey: [API_ENDPOINTS.TRACK_PREFERENCES.GetTrackPreferences.key] })
+            toast.success("Track preference removed")
+        },
+    })
+}
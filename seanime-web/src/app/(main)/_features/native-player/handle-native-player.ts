@@ -207,6 +207,17 @@ export class StreamSubtitleManager {
             return
         }
 
+        // Tracks selected by the track preference of the media
+        const trackSelection = this.playbackInfo.trackSelection
+        if (trackSelection?.subtitlesDisabled) {
+            this.setNoTrack()
+            return
+        }
+        if (trackSelection?.subtitleIndex !== undefined && tracks.some(t => t.info.number === trackSelection.subtitleIndex)) {
+            this.selectTrack(trackSelection.subtitleIndex)
+            return
+        }
+
         if (tracks.length === 1) {
             this.selectTrack(tracks[0].info.number)
             return
@@ -307,7 +318,7 @@ export class StreamSubtitleManager {
         })
 
         this.fonts = this.playbackInfo.mkvMetadata?.attachments?.filter(a => a.type === "font")
-            ?.map(a => `${getServerBaseUrl()}/api/v1/directstream/att/${a.filename}`) || []
+            ?.map(a => `${getServerBaseUrl()}/api/v1/directstream/att/${a.filename}?id=${this.playbackInfo.id}`) || []
 
         this.fonts = [defaultFontUrl, ...this.fonts]
 
@@ -398,6 +409,13 @@ export class StreamAudioManager {
     }
 
     _selectDefaultTrack() {
+        // Track selected by the track preference of the media
+        const audioIndex = this.playbackInfo.trackSelection?.audioIndex
+        if (audioIndex !== undefined && this.playbackInfo.mkvMetadata?.audioTracks?.some?.(t => t.number === audioIndex)) {
+            this.selectTrack(audioIndex)
+            return
+        }
+
         const foundTracks = this.playbackInfo.mkvMetadata?.audioTracks?.filter?.(t => (t.language || "eng") === this.settings.preferredAudioLanguage)
         if (foundTracks?.length) {
             // Find default or forced track
@@ -222,3 +222,108 @@ type Job = {
     execute: () => void
     promise: Promise<string | undefined>
 }
+
+type TrickplayCue = {
+    startTime: number
+    endTime: number
+    spriteUrl: string
+    x: number
+    y: number
+    width: number
+    height: number
+}
+
+/**
+ * Retrieves the seek preview thumbnails generated by the server.
+ * The WebVTT file maps time ranges to regions of sprite sheets (e.g. "sprite-0.jpg#xywh=0,0,320,180").
+ */
+export class TrickplayPreviewManager {
+    private cues: TrickplayCue[] = []
+    private readonly cuesPromise: Promise<void>
+    private previewCache: Map<number, string> = new Map()
+    private spriteCache: Map<string, Promise<HTMLImageElement>> = new Map()
+
+    private readonly _offscreenCanvas = new OffscreenCanvas(0, 0)
+    private readonly _drawingContext = this._offscreenCanvas.getContext("2d")!
+
+    constructor(vttUrl: string) {
+        // No previews are returned if the thumbnails cannot be loaded
+        this.cuesPromise = this.loadCues(vttUrl).catch(() => {})
+    }
+
+    async retrievePreviewAtTime(time: number): Promise<string | undefined> {
+        await this.cuesPromise
+
+        const cueIndex = this.cues.findIndex(cue => time >= cue.startTime && time < cue.endTime)
+        if (cueIndex === -1) return undefined
+
+        const cachedPreview = this.previewCache.get(cueIndex)
+        if (cachedPreview) return cachedPreview
+
+        const cue = this.cues[cueIndex]
+        const sprite = await this.loadSprite(cue.spriteUrl)
+
+        this._offscreenCanvas.width = cue.width
+        this._offscreenCanvas.height = cue.height
+        this._drawingContext.drawImage(sprite, cue.x, cue.y, cue.width, cue.height, 0, 0, cue.width, cue.height)
+
+        const imageBlob = await this._offscreenCanvas.convertToBlob({ type: "image/webp", quality: 0.8 })
+        const previewUrl = URL.createObjectURL(imageBlob)
+
+        this.previewCache.set(cueIndex, previewUrl)
+        return previewUrl
+    }
+
+    cleanup(): void {
+        this.previewCache.forEach(previewUrl => URL.revokeObjectURL(previewUrl))
+        this.previewCache.clear()
+        this.spriteCache.clear()
+        this.cues = []
+    }
+
+    private async loadCues(vttUrl: string): Promise<void> {
+        const res = await fetch(vttUrl)
+        if (!res.ok) throw new Error(`Failed to fetch thumbnails: ${res.status}`)
+        const content = await res.text()
+
+        for (const block of content.split(/\r?\n\r?\n/)) {
+            const lines = block.trim().split(/\r?\n/)
+            const timingIndex = lines.findIndex(line => line.includes("-->"))
+            if (timingIndex === -1 || !lines[timingIndex + 1]) continue
+
+            const [start, end] = lines[timingIndex].split("-->").map(n => parseVttTimestamp(n.trim()))
+            const [path, fragment] = lines[timingIndex + 1].trim().split("#xywh=")
+            const [x, y, width, height] = (fragment ?? "").split(",").map(Number)
+            if (isNaN(start) || isNaN(end) || [x, y, width, height].some(n => n === undefined || isNaN(n))) continue
+
+            this.cues.push({
+                startTime: start,
+                endTime: end,
+                spriteUrl: new URL(path, vttUrl).toString(),
+                x, y, width, height,
+            })
+        }
+    }
+
+    private loadSprite(url: string): Promise<HTMLImageElement> {
+        let sprite = this.spriteCache.get(url)
+        if (!sprite) {
+            sprite = new Promise((resolve, reject) => {
+                const image = new Image()
+                image.crossOrigin = "anonymous"
+                image.onload = () => resolve(image)
+                image.onerror = () => reject(new Error(`Failed to load sprite sheet: ${url}`))
+                image.src = url
+            })
+            // Retry on the next request if the sprite sheet could not be loaded
+            sprite.catch(() => this.spriteCache.delete(url))
+            this.spriteCache.set(url, sprite)
+        }
+        return sprite
+    }
+}
+
+function parseVttTimestamp(timestamp: string): number {
+    const parts = timestamp.split(":").map(Number)
+    return parts.reduce((acc, n) => acc * 60 + n, 0)
+}
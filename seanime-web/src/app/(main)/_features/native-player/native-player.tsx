@@ -69,13 +69,14 @@ import {
     NativePlayerKeybindingsModal,
     nativePlayerKeybindingsModalAtom,
 } from "./native-player-keybindings"
-import { StreamPreviewCaptureIntervalSeconds, StreamPreviewManager } from "./native-player-preview"
+import { StreamPreviewCaptureIntervalSeconds, StreamPreviewManager, TrickplayPreviewManager } from "./native-player-preview"
 import { nativePlayer_settingsAtom, nativePlayer_stateAtom, nativePlayerKeybindingsAtom } from "./native-player.atoms"
 import {
     detectSubtitleType,
     isSubtitleFile,
     nativeplayer_createChapterCues,
     nativeplayer_createChaptersFromAniSkip,
+    nativeplayer_getSkipDataFromSegments,
     nativeplayer_createChapterVTT,
 } from "./native-player.utils"
 
@@ -131,8 +132,15 @@ export function NativePlayer() {
     // Continuity
     const { watchHistory, waitForWatchHistory, getEpisodeContinuitySeekTo } = useHandleCurrentMediaContinuity(state?.playbackInfo?.media?.id)
 
-    // AniSkip
-    const { data: aniSkipData } = useSkipData(state?.playbackInfo?.media?.idMal, state?.playbackInfo?.episode?.progressNumber ?? -1)
+    // Skip segments of the episode (submitted, detected or from the chapters of the file)
+    const skipSegmentsData = useMemo(() => nativeplayer_getSkipDataFromSegments(state.playbackInfo?.skipSegments, duration),
+        [state.playbackInfo?.skipSegments, duration])
+
+    // AniSkip, only used if the episode has no skip segments
+    const { data: aniSkipData } = useSkipData(!skipSegmentsData ? state?.playbackInfo?.media?.idMal : null,
+        state?.playbackInfo?.episode?.progressNumber ?? -1)
+
+    const skipData = skipSegmentsData ?? aniSkipData
 
     // Keybindings
     const keybindings = useAtomValue(nativePlayerKeybindingsAtom)
@@ -141,6 +149,7 @@ export function NativePlayer() {
     const subtitleManagerRef = useRef<StreamSubtitleManager | null>(null)
     const audioManagerRef = useRef<StreamAudioManager | null>(null)
     const previewManagerRef = useRef<StreamPreviewManager | null>(null)
+    const trickplayManagerRef = useRef<TrickplayPreviewManager | null>(null)
 
     // Handle thumbnail preview updates
     const [previewThumbnail, setPreviewThumbnail] = useState<string | undefined>(undefined)
@@ -167,11 +176,11 @@ export function NativePlayer() {
         }
     }, [state.playbackInfo?.mkvMetadata?.chapters, duration])
 
-    // If there are no chapters but we have AniSkip data, create a chapter track
+    // If there are no chapters but we have skip data, create a chapter track
     useEffect(() => {
-            log.info("AniSkip data", aniSkipData)
-            if (!!state.playbackInfo && !state.playbackInfo?.mkvMetadata?.chapters?.length && !!aniSkipData?.op?.interval) {
-                const chapters = nativeplayer_createChaptersFromAniSkip(aniSkipData, duration, state?.playbackInfo?.media?.format)
+            log.info("Skip data", skipData)
+            if (!!state.playbackInfo && !state.playbackInfo?.mkvMetadata?.chapters?.length && !!skipData?.op?.interval) {
+                const chapters = nativeplayer_createChaptersFromAniSkip(skipData, duration, state?.playbackInfo?.media?.format)
                 if (chapters.length === 0) return
 
                 const vttContent = nativeplayer_createChapterVTT(chapters, duration)
@@ -184,7 +193,7 @@ export function NativePlayer() {
                 }
             }
         },
-        [videoRef.current, state.playbackInfo?.mkvMetadata?.chapters, aniSkipData?.op?.interval, aniSkipData?.ed?.interval, duration,
+        [videoRef.current, state.playbackInfo?.mkvMetadata?.chapters, skipData?.op?.interval, skipData?.ed?.interval, duration,
             state?.playbackInfo?.media?.format])
 
     //
@@ -241,6 +250,8 @@ export function NativePlayer() {
             subtitleManagerRef.current?.terminate()
             previewManagerRef.current?.cleanup()
             previewManagerRef.current = null
+            trickplayManagerRef.current?.cleanup()
+            trickplayManagerRef.current = null
             setPreviewThumbnail(undefined)
             streamLoadedRef.current = null
         }
@@ -357,31 +368,31 @@ export function NativePlayer() {
         }
 
         /**
-         * AniSkip
+         * Skip intro/ending
          */
         if (
-            aniSkipData?.op?.interval &&
+            skipData?.op?.interval &&
             !!e.currentTarget.currentTime &&
-            e.currentTarget.currentTime >= aniSkipData.op.interval.startTime &&
-            e.currentTarget.currentTime <= aniSkipData.op.interval.endTime
+            e.currentTarget.currentTime >= skipData.op.interval.startTime &&
+            e.currentTarget.currentTime <= skipData.op.interval.endTime
         ) {
             setShowSkipIntroButton(true)
             if (autoSkipIntroOutro) {
-                seekTo(aniSkipData?.op?.interval?.endTime || 0)
+                seekTo(skipData?.op?.interval?.endTime || 0)
             }
         } else {
             setShowSkipIntroButton(false)
         }
         if (
-            aniSkipData?.ed?.interval &&
-            Math.abs(aniSkipData.ed.interval.startTime - (aniSkipData?.ed?.episodeLength)) < 500 &&
+            skipData?.ed?.interval &&
+            Math.abs(skipData.ed.interval.startTime - (skipData?.ed?.episodeLength)) < 500 &&
             !!e.currentTarget.currentTime &&
-            e.currentTarget.currentTime >= aniSkipData.ed.interval.startTime &&
-            e.currentTarget.currentTime <= aniSkipData.ed.interval.endTime
+            e.currentTarget.currentTime >= skipData.ed.interval.startTime &&
+            e.currentTarget.currentTime <= skipData.ed.interval.endTime
         ) {
             setShowSkipEndingButton(true)
             if (autoSkipIntroOutro) {
-                seekTo(aniSkipData?.ed?.interval?.endTime || 0)
+                seekTo(skipData?.ed?.interval?.endTime || 0)
             }
         } else {
             setShowSkipEndingButton(false)
@@ -541,6 +552,13 @@ export function NativePlayer() {
             })
         }
 
+        // Use the thumbnails generated by the server if available
+        trickplayManagerRef.current?.cleanup()
+        trickplayManagerRef.current = null
+        if (state.playbackInfo?.trickplayUrl) {
+            trickplayManagerRef.current = new TrickplayPreviewManager(state.playbackInfo.trickplayUrl.replace("{{SERVER_URL}}", getServerBaseUrl()))
+        }
+
         // Initialize thumbnailer
         if (state.playbackInfo?.streamUrl && state.playbackInfo.streamType === "localfile") {
             const streamUrl = state.playbackInfo.streamUrl.replace("{{SERVER_URL}}", getServerBaseUrl())
@@ -817,7 +835,7 @@ export function NativePlayer() {
     }
 
     const handleTimeRangePreview = useCallback(async (event: MouseEvent) => {
-        if (!previewManagerRef.current || !duration) {
+        if ((!previewManagerRef.current && !trickplayManagerRef.current) || !duration) {
             return
         }
 
@@ -834,7 +852,9 @@ export function NativePlayer() {
             const thumbnailIndex = Math.floor(previewTime / StreamPreviewCaptureIntervalSeconds)
 
             try {
-                const thumbnail = await previewManagerRef.current.retrievePreviewForSegment(thumbnailIndex)
+                // Fall back to capturing frames from the stream if there are no thumbnails
+                const thumbnail = await trickplayManagerRef.current?.retrievePreviewAtTime(previewTime)
+                    ?? await previewManagerRef.current?.retrievePreviewForSegment(thumbnailIndex)
                 if (thumbnail) {
                     timeRange.setAttribute("mediapreviewimage", thumbnail)
                     setPreviewThumbnail(thumbnail)
@@ -876,17 +896,17 @@ export function NativePlayer() {
                 return cues
             }
 
-            // Otherwise, create chapters from AniSkip data if available
-            if (!!aniSkipData?.op?.interval && duration > 0) {
-                const chapters = nativeplayer_createChaptersFromAniSkip(aniSkipData, duration, state?.playbackInfo?.media?.format)
+            // Otherwise, create chapters from the skip data if available
+            if (!!skipData?.op?.interval && duration > 0) {
+                const chapters = nativeplayer_createChaptersFromAniSkip(skipData, duration, state?.playbackInfo?.media?.format)
                 const cues = nativeplayer_createChapterCues(chapters, duration)
-                log.info("Chapter cues from AniSkip", cues)
+                log.info("Chapter cues from skip data", cues)
                 return cues
             }
 
             return []
         },
-        [state.playbackInfo?.mkvMetadata?.chapters, aniSkipData?.op?.interval, aniSkipData?.ed?.interval, duration,
+        [state.playbackInfo?.mkvMetadata?.chapters, skipData?.op?.interval, skipData?.ed?.interval, duration,
             state?.playbackInfo?.media?.format])
 
     const [keybindingsModalOpen, setKeybindingsModalOpen] = useAtom(nativePlayerKeybindingsModalAtom)
@@ -1029,8 +1049,8 @@ export function NativePlayer() {
                                         muted,
                                         subtitleManagerRef,
                                         audioManagerRef,
-                                        introStartTime: aniSkipData?.op?.interval?.startTime,
-                                        introEndTime: aniSkipData?.op?.interval?.endTime,
+                                        introStartTime: skipData?.op?.interval?.startTime,
+                                        introEndTime: skipData?.op?.interval?.endTime,
                                     }}
                                 />
 
@@ -1046,22 +1066,22 @@ export function NativePlayer() {
                                 />
 
                                 {/* Skip Intro/Ending Buttons */}
-                                {showSkipIntroButton && !state.miniPlayer && !state.playbackInfo?.mkvMetadata?.chapters?.length && (
+                                {showSkipIntroButton && !state.miniPlayer && (!!skipSegmentsData || !state.playbackInfo?.mkvMetadata?.chapters?.length) && (
                                     <div className="absolute left-8 bottom-24 z-[60] native-player-hide-on-fullscreen">
                                         <button
                                             className="bg-white/90 hover:bg-white text-black px-4 py-2 rounded-md font-medium text-sm transition-all duration-200 shadow-lg"
-                                            onClick={() => seekTo(aniSkipData?.op?.interval?.endTime || 0)}
+                                            onClick={() => seekTo(skipData?.op?.interval?.endTime || 0)}
                                         >
                                             Skip Intro
                                         </button>
                                     </div>
                                 )}
 
-                                {showSkipEndingButton && !state.miniPlayer && !state.playbackInfo?.mkvMetadata?.chapters?.length && (
+                                {showSkipEndingButton && !state.miniPlayer && (!!skipSegmentsData || !state.playbackInfo?.mkvMetadata?.chapters?.length) && (
                                     <div className="absolute right-8 bottom-24 z-[60] native-player-hide-on-fullscreen">
                                         <button
                                             className="bg-white/90 hover:bg-white text-black px-4 py-2 rounded-md font-medium text-sm transition-all duration-200 shadow-lg"
-                                            onClick={() => seekTo(aniSkipData?.ed?.interval?.endTime || 0)}
+                                            onClick={() => seekTo(skipData?.ed?.interval?.endTime || 0)}
                                         >
                                             Skip Ending
                                         </button>
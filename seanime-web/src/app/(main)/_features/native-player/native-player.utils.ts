@@ -1,4 +1,4 @@
-import { MKVParser_ChapterInfo } from "@/api/generated/types"
+import { MKVParser_ChapterInfo, Skipsegments_Segment } from "@/api/generated/types"
 
 export const nativeplayer_createChapterCues = (chapters: Array<MKVParser_ChapterInfo> | undefined, duration: number) => {
     if (!chapters || chapters.length === 0 || duration === 0) {
@@ -92,6 +92,26 @@ export function detectSubtitleType(content: string): "ass" | "vtt" | "ttml" | "s
     return "unknown"
 }
 
+/**
+ * Returns the opening and ending of the skip segments in the same shape as the AniSkip data.
+ * Returns undefined if the episode has no opening or ending segment.
+ */
+export function nativeplayer_getSkipDataFromSegments(segments: Array<Skipsegments_Segment> | undefined, duration: number) {
+    const opening = segments?.find(segment => segment.type === "opening")
+    const ending = segments?.find(segment => segment.type === "ending")
+    if (!opening && !ending) return undefined
+
+    const toSkipTime = (segment: Skipsegments_Segment | undefined) => segment ? {
+        interval: { startTime: segment.start, endTime: segment.end },
+        episodeLength: duration,
+    } : null
+
+    return {
+        op: toSkipTime(opening),
+        ed: toSkipTime(ending),
+    }
+}
+
 export function nativeplayer_createChaptersFromAniSkip(
     aniSkipData: {
         op: { interval: { startTime: number; endTime: number } } | null;
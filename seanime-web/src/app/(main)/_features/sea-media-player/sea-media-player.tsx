@@ -70,6 +70,8 @@ export type SeaMediaPlayerProps = {
     onDurationChange?: (detail: number, e: MediaDurationChangeEvent) => void
     tracks?: TrackProps[]
     chapters?: ChapterProps[]
+    // URL of the WebVTT file referencing the seek preview thumbnails
+    thumbnails?: string
     videoLayoutSlots?: Omit<DefaultVideoLayoutProps["slots"], "settingsMenuEndItems">
     settingsItems?: React.ReactElement
     loadingText?: React.ReactNode
@@ -93,6 +95,7 @@ export function SeaMediaPlayer(props: SeaMediaPlayerProps) {
         playerRef,
         tracks = [],
         chapters = [],
+        thumbnails,
         videoLayoutSlots,
         loadingText,
         onCanPlay: _onCanPlay,
@@ -580,6 +583,7 @@ export function SeaMediaPlayer(props: SeaMediaPlayerProps) {
                         </div>
                         <DefaultVideoLayout
                             icons={vidstackLayoutIcons}
+                            thumbnails={thumbnails}
                             slots={{
                                 ...videoLayoutSlots,
                                 settingsMenuEndItems: <>
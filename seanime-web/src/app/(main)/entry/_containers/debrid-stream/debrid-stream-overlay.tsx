@@ -2,6 +2,7 @@ import { DebridClient_StreamState } from "@/api/generated/types"
 import { useDebridCancelStream } from "@/api/hooks/debrid.hooks"
 import { PlaybackManager_PlaybackState } from "@/app/(main)/_features/progress-tracking/_lib/playback-manager.types"
 import { useWebsocketMessageListener } from "@/app/(main)/_hooks/handle-websockets"
+import { clientIdAtom } from "@/app/websocket-provider"
 import { ConfirmationDialog, useConfirmationDialog } from "@/components/shared/confirmation-dialog"
 import { AppLayoutStack } from "@/components/ui/app-layout"
 import { Button } from "@/components/ui/button"
@@ -10,7 +11,7 @@ import { Modal } from "@/components/ui/modal"
 import { ProgressBar } from "@/components/ui/progress-bar"
 import { WSEvents } from "@/lib/server/ws-events"
 import { atom } from "jotai/index"
-import { useAtom } from "jotai/react"
+import { useAtom, useAtomValue } from "jotai/react"
 import React from "react"
 import { HiOutlineServerStack } from "react-icons/hi2"
 import { toast } from "sonner"
@@ -26,6 +27,7 @@ export const __debridstream_stateAtom = atom<DebridClient_StreamState | null>(nu
 export function DebridStreamOverlay() {
 
     const [state, setState] = useAtom(__debridstream_stateAtom)
+    const clientId = useAtomValue(clientIdAtom)
 
     const { mutate: cancelStream, isPending: isCancelling } = useDebridCancelStream()
 
@@ -82,6 +84,7 @@ export function DebridStreamOverlay() {
             cancelStream({
                 options: {
                     removeTorrent: true,
+                    clientId: clientId || "",
                 },
             }, {
                 onSuccess: () => {
@@ -98,6 +101,7 @@ export function DebridStreamOverlay() {
             cancelStream({
                 options: {
                     removeTorrent: false,
+                    clientId: clientId || "",
                 },
             }, {
                 onSuccess: () => {
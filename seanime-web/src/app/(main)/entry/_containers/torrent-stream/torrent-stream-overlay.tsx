@@ -1,9 +1,10 @@
 "use client"
-import { Torrentstream_TorrentStatus } from "@/api/generated/types"
+import { Torrentstream_BufferHealth, Torrentstream_TorrentStatus } from "@/api/generated/types"
 import { useTorrentstreamStopStream } from "@/api/hooks/torrentstream.hooks"
 import { nativePlayer_stateAtom } from "@/app/(main)/_features/native-player/native-player.atoms"
 
 import { useWebsocketMessageListener } from "@/app/(main)/_hooks/handle-websockets"
+import { clientIdAtom } from "@/app/websocket-provider"
 import { IconButton } from "@/components/ui/button"
 import { cn } from "@/components/ui/core/styling"
 import { Spinner } from "@/components/ui/loading-spinner"
@@ -11,10 +12,10 @@ import { ProgressBar } from "@/components/ui/progress-bar"
 import { Tooltip } from "@/components/ui/tooltip"
 import { WSEvents } from "@/lib/server/ws-events"
 import { atom } from "jotai"
-import { useAtom } from "jotai/react"
+import { useAtom, useAtomValue } from "jotai/react"
 import { Inter } from "next/font/google"
 import React, { useState } from "react"
-import { BiDownArrow, BiGroup, BiStop, BiUpArrow } from "react-icons/bi"
+import { BiDownArrow, BiError, BiGroup, BiStop, BiUpArrow } from "react-icons/bi"
 
 const inter = Inter({ subsets: ["latin"] })
 
@@ -26,6 +27,7 @@ const enum TorrentStreamEvents {
     TorrentStartedPlaying = "started-playing",
     TorrentStatus = "status",
     TorrentStopped = "stopped",
+    BufferHealth = "buffer-health",
 }
 
 export const __torrentstream__loadingStateAtom = atom<string | null>(null)
@@ -43,9 +45,11 @@ export function TorrentStreamOverlay({ isNativePlayerComponent = false }: { isNa
     const [isLoaded, setIsLoaded] = useAtom(__torrentstream__isLoadedAtom)
 
     const [status, setStatus] = useState<Torrentstream_TorrentStatus | null>(null)
+    const [bufferHealth, setBufferHealth] = useState<Torrentstream_BufferHealth | null>(null)
     const [torrentBeingLoaded, setTorrentBeingLoaded] = useState<string | null>(null)
     const [mediaPlayerStartedPlaying, setMediaPlayerStartedPlaying] = useState<boolean>(false)
 
+    const clientId = useAtomValue(clientIdAtom)
     const { mutate: stop, isPending } = useTorrentstreamStopStream()
 
     useWebsocketMessageListener({
@@ -57,6 +61,7 @@ export function TorrentStreamOverlay({ isNativePlayerComponent = false }: { isNa
                         setTimeout(() => {
                             setLoadingState("SEARCHING_TORRENTS")
                             setStatus(null)
+                            setBufferHealth(null)
                             setMediaPlayerStartedPlaying(false)
                         }, 500)
                     } else {
@@ -68,6 +73,7 @@ export function TorrentStreamOverlay({ isNativePlayerComponent = false }: { isNa
                 case TorrentStreamEvents.TorrentLoadingFailed:
                     setLoadingState(null)
                     setStatus(null)
+                    setBufferHealth(null)
                     setMediaPlayerStartedPlaying(false)
                     break
                 case TorrentStreamEvents.TorrentLoaded:
@@ -84,12 +90,16 @@ export function TorrentStreamOverlay({ isNativePlayerComponent = false }: { isNa
                     setLoadingState(null)
                     setIsLoaded(false)
                     setStatus(null)
+                    setBufferHealth(null)
                     setMediaPlayerStartedPlaying(false)
                     break
                 case TorrentStreamEvents.TorrentStatus:
                     setIsLoaded(true)
                     setStatus(data)
                     break
+                case TorrentStreamEvents.BufferHealth:
+                    setBufferHealth(data)
+                    break
             }
         },
     })
@@ -123,9 +133,11 @@ export function TorrentStreamOverlay({ isNativePlayerComponent = false }: { isNa
                                 {status.uploadSpeed !== "" ? status.uploadSpeed : "0 B/s"}
                             </div>
 
+                            <BufferHealthIndicator bufferHealth={bufferHealth} />
+
                             <Tooltip
                                 trigger={<IconButton
-                                    onClick={() => stop()}
+                                    onClick={() => stop({ clientId: clientId || undefined })}
                                     loading={isPending}
                                     intent="alert-basic"
                                     icon={<BiStop />}
@@ -186,9 +198,11 @@ export function TorrentStreamOverlay({ isNativePlayerComponent = false }: { isNa
                             {status.uploadSpeed !== "" ? status.uploadSpeed : "0 B/s"}
                         </div>
 
+                        <BufferHealthIndicator bufferHealth={bufferHealth} />
+
                         <Tooltip
                             trigger={<IconButton
-                                onClick={() => stop()}
+                                onClick={() => stop({ clientId: clientId || undefined })}
                                 loading={isPending}
                                 intent="alert-basic"
                                 icon={<BiStop />}
@@ -226,3 +240,23 @@ export function TorrentStreamOverlay({ isNativePlayerComponent = false }: { isNa
     return null
 
 }
+
+// Shown when the download might not keep up with the playback
+function BufferHealthIndicator({ bufferHealth }: { bufferHealth: Torrentstream_BufferHealth | null }) {
+    if (!bufferHealth || bufferHealth.state === "good") return null
+
+    return (
+        <Tooltip
+            trigger={<div
+                className={cn("space-x-1", bufferHealth.state === "stalling" ? "text-red-300 animate-pulse" : "text-orange-300")}
+            >
+                <BiError className="inline-block mr-1" />
+                <span>{Math.floor(bufferHealth.bufferedSeconds)}s</span>
+            </div>}
+        >
+            {bufferHealth.state === "stalling"
+                ? "The download is too slow, playback will stall"
+                : "The download is slower than the video, playback might stall"}
+        </Tooltip>
+    )
+}
@@ -1,5 +1,5 @@
 import { getServerBaseUrl } from "@/api/client/server-url"
-import { Anime_Episode, Mediastream_StreamType, Nullish } from "@/api/generated/types"
+import { Anime_Episode, Mediastream_StreamType, Nullish, Subtitle } from "@/api/generated/types"
 import { useHandleContinuityWithMediaPlayer, useHandleCurrentMediaContinuity } from "@/api/hooks/continuity.hooks"
 import { useGetMediastreamSettings, useMediastreamShutdownTranscodeStream, useRequestMediastreamMediaContainer } from "@/api/hooks/mediastream.hooks"
 import { useIsCodecSupported } from "@/app/(main)/_features/sea-media-player/hooks"
@@ -436,6 +436,34 @@ export function useHandleMediastream(props: HandleMediastreamProps) {
         logger("MEDIASTREAM").info("[onCanPlay] called", e)
         preloadedNextFileForRef.current = undefined
         setDuration(e.duration)
+        selectPreferredAudioTrack()
+    }
+
+    /**
+     * Selects the audio track chosen by the track preference of the media.
+     * The audio tracks of the player are in the same order as the audio tracks of the media info.
+     */
+    const selectPreferredAudioTrack = () => {
+        const audioIndex = mediaContainer?.trackSelection?.audioIndex
+        if (audioIndex === undefined || !playerRef.current) return
+        const position = mediaContainer?.mediaInfo?.audios?.findIndex(a => a.index === audioIndex) ?? -1
+        const track = position >= 0 ? playerRef.current.audioTracks[position] : undefined
+        if (track && !track.selected) {
+            logger("MEDIASTREAM").info("[selectPreferredAudioTrack] Selecting audio track", position)
+            track.selected = true
+        }
+    }
+
+    /**
+     * Returns true if the subtitle track should be displayed by default.
+     * The track preference of the media takes precedence over the default flag of the tracks.
+     */
+    const isDefaultSubtitle = (sub: Subtitle) => {
+        const trackSelection = mediaContainer?.trackSelection
+        if (trackSelection?.subtitlesDisabled) return false
+        if (trackSelection?.subtitleIndex !== undefined) return sub.index === trackSelection.subtitleIndex
+        const subtitles = mediaContainer?.mediaInfo?.subtitles ?? []
+        return sub.isDefault || (!subtitles.some(n => n.isDefault) && !!sub.language?.startsWith("en"))
     }
 
     const playNextEpisode = () => {
@@ -506,6 +534,7 @@ export function useHandleMediastream(props: HandleMediastreamProps) {
             changeUrl(undefined)
         },
         onCanPlay,
+        isDefaultSubtitle,
         playNextEpisode,
         onProviderChange,
         onProviderSetup,
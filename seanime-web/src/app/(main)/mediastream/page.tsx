@@ -1,4 +1,5 @@
 "use client"
+import { getServerBaseUrl } from "@/api/client/server-url"
 import { useGetAnimeEntry } from "@/api/hooks/anime_entries.hooks"
 import { EpisodeGridItem } from "@/app/(main)/_features/anime/_components/episode-grid-item"
 import { MediaEntryPageSmallBanner } from "@/app/(main)/_features/media/_components/media-entry-page-small-banner"
@@ -63,6 +64,7 @@ export default function Page() {
         onProviderChange,
         onProviderSetup,
         onCanPlay,
+        isDefaultSubtitle,
         playNextEpisode,
         onPlayFile,
         isCodecSupported,
@@ -250,9 +252,10 @@ export default function Page() {
                                 lang: sub.language,
                                 type: (sub.extension?.replace(".", "") || "ass") as CaptionsFileFormat,
                                 kind: "subtitles",
-                                default: sub.isDefault || (!subtitles.some(n => n.isDefault) && sub.language?.startsWith("en")),
+                                default: isDefaultSubtitle(sub),
                             }))}
                             mediaInfoDuration={mediaContainer?.mediaInfo?.duration}
+                            thumbnails={mediaContainer?.trickplayUrl ? `${getServerBaseUrl()}${mediaContainer.trickplayUrl}` : undefined}
                             loadingText={<>
                                 <p>Extracting video metadata...</p>
                                 <p>This might take a while.</p>
@@ -12,6 +12,7 @@ import { logger } from "@/lib/helpers/debug"
 import { ANILIST_OAUTH_URL, ANILIST_PIN_URL } from "@/lib/server/config"
 import { WSEvents } from "@/lib/server/ws-events"
 import { __isDesktop__ } from "@/types/constants"
+import { useQueryClient } from "@tanstack/react-query"
 import { useAtom } from "jotai"
 import Link from "next/link"
 import { usePathname, useRouter } from "next/navigation"
@@ -37,6 +38,7 @@ export function ServerDataWrapper(props: ServerDataWrapperProps) {
     const setServerStatus = useSetServerStatus()
     const password = useAtom(serverAuthTokenAtom)
     const { data: _serverStatus, isLoading, refetch } = useGetStatus()
+    const queryClient = useQueryClient()
 
     React.useEffect(() => {
         if (_serverStatus) {
@@ -53,6 +55,14 @@ export function ServerDataWrapper(props: ServerDataWrapperProps) {
         },
     })
 
+    useWebsocketMessageListener({
+        type: WSEvents.PROFILE_CHANGED,
+        onMessage: () => {
+            logger("Data Wrapper").info("Profile changed, refetching all data")
+            queryClient.invalidateQueries()
+        },
+    })
+
     React.useEffect(() => {
         if (!!serverStatus && serverStatus?.serverHasPassword && !password && pathname !== "/public/auth") {
             window.location.href = "/public/auth"
@@ -1,19 +1,22 @@
+import { useDiscoverUpnpRenderers } from "@/api/hooks/mediaplayer.hooks"
+import { useListMediaPlayerExtensions } from "@/api/hooks/extensions.hooks"
 import { useExternalPlayerLink } from "@/app/(main)/_atoms/playback.atoms"
 import { useServerStatus } from "@/app/(main)/_hooks/use-server-status"
 import { SettingsCard, SettingsPageHeader } from "@/app/(main)/settings/_components/settings-card"
 import { SettingsSubmitButton } from "@/app/(main)/settings/_components/settings-submit-button"
 import { Accordion, AccordionContent, AccordionItem, AccordionTrigger } from "@/components/ui/accordion"
 import { Alert } from "@/components/ui/alert"
+import { Button } from "@/components/ui/button"
 import { Field } from "@/components/ui/form"
 import { Switch } from "@/components/ui/switch"
 import { TextInput } from "@/components/ui/text-input"
 import { getDefaultIinaSocket, getDefaultMpvSocket } from "@/lib/server/settings"
 import React from "react"
-import { useWatch } from "react-hook-form"
+import { useFormContext, useWatch } from "react-hook-form"
 import { FcClapperboard, FcVideoCall, FcVlc } from "react-icons/fc"
 import { HiPlay } from "react-icons/hi"
 import { IoPlayForwardCircleSharp } from "react-icons/io5"
-import { LuExternalLink, LuLaptop } from "react-icons/lu"
+import { LuCast, LuExternalLink, LuLaptop, LuTv } from "react-icons/lu"
 import { RiSettings3Fill } from "react-icons/ri"
 
 type MediaplayerSettingsProps = {
@@ -28,12 +31,17 @@ export function MediaplayerSettings(props: MediaplayerSettingsProps) {
 
     const serverStatus = useServerStatus()
     const selectedPlayer = useWatch({ name: "defaultPlayer" })
+    const { setValue } = useFormContext()
+    const { data: mediaPlayerExtensions } = useListMediaPlayerExtensions()
+
+    const [discoverRenderers, setDiscoverRenderers] = React.useState(false)
+    const { data: renderers, isFetching: isDiscovering, refetch: refetchRenderers } = useDiscoverUpnpRenderers(discoverRenderers)
 
     return (
         <>
             <SettingsPageHeader
                 title="Desktop Media Player"
-                description="Seanime has built-in support for MPV, VLC, IINA, and MPC-HC."
+                description="Seanime has built-in support for MPV, VLC, IINA, MPC-HC, Kodi and UPnP/DLNA renderers."
                 icon={LuLaptop}
             />
 
@@ -47,6 +55,9 @@ export function MediaplayerSettings(props: MediaplayerSettingsProps) {
                         { label: "VLC", value: "vlc" },
                         { label: "MPC-HC (Windows)", value: "mpc-hc" },
                         { label: "IINA (macOS)", value: "iina" },
+                        { label: "Kodi", value: "kodi" },
+                        { label: "UPnP/DLNA renderer", value: "upnp" },
+                        ...(mediaPlayerExtensions ?? []).map(ext => ({ label: `${ext.name} (Extension)`, value: ext.id })),
                     ]}
                     help="Player that will be used to open files and track your progress automatically."
                 />
@@ -68,13 +79,34 @@ export function MediaplayerSettings(props: MediaplayerSettingsProps) {
                 />
             </SettingsCard>
 
+            <SettingsCard title="DLNA media server">
+                <Field.Switch
+                    side="right"
+                    name="dlnaServerEnabled"
+                    label="Share the library on the local network"
+                    help="If enabled, DLNA clients (smart TVs, consoles) on the local network can browse and play your library."
+                />
+                <Field.Text
+                    name="dlnaServerName"
+                    label="Server name"
+                    placeholder="Seanime"
+                />
+            </SettingsCard>
+
             <SettingsCard title="Configuration">
 
 
                 <Field.Text
                     name="mediaPlayerHost"
                     label="Host"
-                    help="VLC/MPC-HC"
+                    help="VLC/MPC-HC/Kodi"
+                />
+
+                <Field.Text
+                    name="networkServerUrl"
+                    label="Network server URL"
+                    placeholder="e.g. http://192.168.1.10:43211"
+                    help="URL at which network players (Kodi, DLNA renderers) can reach Seanime. Detected automatically if empty."
                 />
 
                 <Accordion
@@ -195,6 +227,85 @@ export function MediaplayerSettings(props: MediaplayerSettingsProps) {
                             </div>
                         </AccordionContent>
                     </AccordionItem>
+
+                    <AccordionItem value="kodi">
+                        <AccordionTrigger>
+                            <h4 className="flex gap-2 items-center"><LuTv className="mr-1 text-blue-200" /> Kodi</h4>
+                        </AccordionTrigger>
+                        <AccordionContent>
+                            <div className="flex flex-col md:flex-row gap-4">
+                                <Field.Text
+                                    name="kodiUsername"
+                                    label="Username"
+                                />
+                                <Field.Text
+                                    name="kodiPassword"
+                                    label="Password"
+                                />
+                                <Field.Number
+                                    name="kodiPort"
+                                    label="Port"
+                                    formatOptions={{
+                                        useGrouping: false,
+                                    }}
+                                    hideControls
+                                    help="Port of the Kodi web server (JSON-RPC)."
+                                />
+                            </div>
+                        </AccordionContent>
+                    </AccordionItem>
+
+                    <AccordionItem value="upnp">
+                        <AccordionTrigger>
+                            <h4 className="flex gap-2 items-center"><LuCast className="mr-1 text-green-200" /> UPnP/DLNA</h4>
+                        </AccordionTrigger>
+                        <AccordionContent className="space-y-4">
+                            <Field.Text
+                                name="upnpRendererLocation"
+                                label="Renderer location"
+                                placeholder="e.g. http://192.168.1.20:49152/description.xml"
+                                help="Device description URL of the renderer."
+                            />
+                            <Button
+                                intent="gray-outline"
+                                size="sm"
+                                loading={isDiscovering}
+                                onClick={() => {
+                                    if (!discoverRenderers) {
+                                        setDiscoverRenderers(true)
+                                    } else {
+                                        refetchRenderers()
+                                    }
+                                }}
+                            >
+                                Discover renderers
+                            </Button>
+                            {discoverRenderers && !isDiscovering && !renderers?.length && (
+                                <p className="text-sm text-[--muted]">No renderers found on the local network.</p>
+                            )}
+                            {!!renderers?.length && <div className="space-y-2">
+                                {renderers.map(renderer => (
+                                    <div
+                                        key={renderer.location}
+                                        className="flex items-center justify-between gap-2 p-2 border rounded-[--radius-md]"
+                                    >
+                                        <div>
+                                            <p className="font-medium">{renderer.name}</p>
+                                            <p className="text-xs text-[--muted]">{[renderer.manufacturer, renderer.model].filter(Boolean)
+                                                .join(" · ")}</p>
+                                        </div>
+                                        <Button
+                                            intent="primary-subtle"
+                                            size="sm"
+                                            onClick={() => setValue("upnpRendererLocation", renderer.location, { shouldDirty: true })}
+                                        >
+                                            Select
+                                        </Button>
+                                    </div>
+                                ))}
+                            </div>}
+                        </AccordionContent>
+                    </AccordionItem>
                 </Accordion>
             </SettingsCard>
 
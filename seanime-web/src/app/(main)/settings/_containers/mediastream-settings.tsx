@@ -20,6 +20,8 @@ const mediastreamSchema = defineSchema(({ z }) => z.object({
     ffmpegPath: z.string().min(0),
     ffprobePath: z.string().min(0),
     transcodeHwAccelCustomSettings: z.string().min(0),
+    trickplayEnabled: z.boolean(),
+    trickplayInterval: z.number().min(1),
 }))
 
 const MEDIASTREAM_HW_ACCEL_OPTIONS = [
@@ -97,6 +99,8 @@ export function MediastreamSettings(props: MediastreamSettingsProps) {
                     directPlayOnly: settings?.directPlayOnly ?? false,
                     ffmpegPath: settings?.ffmpegPath || "",
                     ffprobePath: settings?.ffprobePath || "",
+                    trickplayEnabled: settings?.trickplayEnabled ?? false,
+                    trickplayInterval: settings?.trickplayInterval || 10,
                     transcodeHwAccelCustomSettings: settings?.transcodeHwAccelCustomSettings || "{\n	\"name\": \"\",\n	\"decodeFlags\": [\n		\"-hwaccel\", \"\",\n		\"-hwaccel_output_format\", \"\",\n	],\n	\"encodeFlags\": [\n		\"-c:v\", \"\",\n		\"-preset\", \"\",\n		\"-pix_fmt\", \"yuv420p\",\n	],\n	\"scaleFilter\": \"scale=%d:%d\"\n}",
                 }}
                 stackClass="space-y-4"
@@ -187,6 +191,23 @@ export function MediastreamSettings(props: MediastreamSettingsProps) {
                             />
                         </SettingsCard>
 
+                        <SettingsCard title="Seek previews">
+                            <Field.Switch
+                                side="right"
+                                name="trickplayEnabled"
+                                label="Generate thumbnails"
+                                help="Generate preview thumbnails of local files in the background when they are played. They are shown when seeking."
+                            />
+
+                            <Field.Number
+                                name="trickplayInterval"
+                                label="Interval"
+                                help="Number of seconds between two thumbnails. Lower values take more time and disk space."
+                                min={1}
+                                formatOptions={{ useGrouping: false }}
+                            />
+                        </SettingsCard>
+
                         <SettingsCard title="FFmpeg">
 
                             <div className="flex gap-3 items-center">
@@ -0,0 +1,104 @@
+import { Outbox_Item, Outbox_Mutation } from "@/api/generated/types"
+import { useDiscardOutboxItem, useGetOutboxItems, useRetryOutboxItem } from "@/api/hooks/outbox.hooks"
+import { SettingsCard } from "@/app/(main)/settings/_components/settings-card"
+import { Badge } from "@/components/ui/badge"
+import { Button } from "@/components/ui/button"
+import { LoadingSpinner } from "@/components/ui/loading-spinner"
+import React from "react"
+
+const PLATFORM_NAMES: Record<string, string> = {
+    anilist: "AniList",
+    mal: "MyAnimeList",
+}
+
+function describeMutation(mutation?: Outbox_Mutation) {
+    if (!mutation) return "-"
+    if (mutation.delete) return "Delete entry"
+
+    const changes: string[] = []
+    if (mutation.status) changes.push(`Status: ${mutation.status}`)
+    if (mutation.progress !== undefined && mutation.progress !== null) changes.push(`Progress: ${mutation.progress}`)
+    if (mutation.scoreRaw !== undefined && mutation.scoreRaw !== null) changes.push(`Score: ${mutation.scoreRaw}`)
+    if (mutation.startedAt) changes.push("Start date")
+    if (mutation.completedAt) changes.push("Completion date")
+    return changes.join(", ") || "-"
+}
+
+type OutboxSettingsProps = {
+    children?: React.ReactNode
+}
+
+export function OutboxSettings(props: OutboxSettingsProps) {
+
+    const {
+        children,
+        ...rest
+    } = props
+
+    const { data: items, isLoading } = useGetOutboxItems()
+
+    if (isLoading) return <LoadingSpinner />
+
+    return (
+        <SettingsCard
+            title="Pending list changes"
+            description="Changes that could not be sent to your account are sent again automatically once it can be reached."
+        >
+            {!items?.length ? (
+                <p className="text-sm text-[--muted]">No pending changes</p>
+            ) : (
+                <div className="space-y-2">
+                    {items.map(item => (
+                        <OutboxItemRow key={item.id} item={item} />
+                    ))}
+                </div>
+            )}
+        </SettingsCard>
+    )
+}
+
+function OutboxItemRow({ item }: { item: Outbox_Item }) {
+
+    const { mutate: retry, isPending: isRetrying } = useRetryOutboxItem(item.id)
+
+    const { mutate: discard, isPending: isDiscarding } = useDiscardOutboxItem(item.id)
+
+    return (
+        <div className="flex items-center justify-between gap-2 text-sm">
+            <div className="space-y-1">
+                <p className="flex items-center gap-2">
+                    <span className="font-medium">{item.mediaId}</span>
+                    <span className="text-[--muted]">{PLATFORM_NAMES[item.platform] ?? item.platform}</span>
+                    <Badge
+                        size="sm"
+                        intent={item.status === "pending" ? "gray" : item.status === "conflict" ? "warning" : "alert"}
+                    >
+                        {item.status}
+                    </Badge>
+                </p>
+                <p className="text-[--muted]">{describeMutation(item.mutation)}</p>
+                {!!item.error && <p className="text-[--muted] text-xs line-clamp-2">{item.error}</p>}
+            </div>
+            <div className="flex gap-2 flex-none">
+                <Button
+                    type="button"
+                    intent="primary-subtle"
+                    size="sm"
+                    onClick={() => retry()}
+                    disabled={isRetrying || isDiscarding}
+                >
+                    Retry
+                </Button>
+                <Button
+                    type="button"
+                    intent="alert-subtle"
+                    size="sm"
+                    onClick={() => discard()}
+                    disabled={isRetrying || isDiscarding}
+                >
+                    Discard
+                </Button>
+            </div>
+        </div>
+    )
+}
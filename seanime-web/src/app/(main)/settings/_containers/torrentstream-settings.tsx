@@ -1,13 +1,20 @@
 import { Models_TorrentstreamSettings } from "@/api/generated/types"
-import { useSaveTorrentstreamSettings, useTorrentstreamDropTorrent } from "@/api/hooks/torrentstream.hooks"
+import {
+    useDeleteTorrentstreamCacheEntry,
+    useGetTorrentstreamCacheEntries,
+    useImportTorrentstreamCacheEntry,
+    useSaveTorrentstreamSettings,
+    useTorrentstreamDropTorrent,
+} from "@/api/hooks/torrentstream.hooks"
 import { SettingsCard } from "@/app/(main)/settings/_components/settings-card"
 import { SettingsIsDirty, SettingsSubmitButton } from "@/app/(main)/settings/_components/settings-submit-button"
 import { Accordion, AccordionContent, AccordionItem, AccordionTrigger } from "@/components/ui/accordion"
-import { Button } from "@/components/ui/button"
+import { Button, IconButton } from "@/components/ui/button"
 import { defineSchema, Field, Form } from "@/components/ui/form"
 import React from "react"
 import { UseFormReturn } from "react-hook-form"
 import { FcFolder } from "react-icons/fc"
+import { LuFolderInput, LuTrash } from "react-icons/lu"
 import { SiBittorrent } from "react-icons/si"
 
 const torrentstreamSchema = defineSchema(({ z }) => z.object({
@@ -24,6 +31,7 @@ const torrentstreamSchema = defineSchema(({ z }) => z.object({
     includeInLibrary: z.boolean(),
     streamUrlAddress: z.string().optional().default(""),
     slowSeeding: z.boolean().optional().default(false),
+    cacheMaxSize: z.number().min(0).optional().default(0),
 }))
 
 
@@ -84,6 +92,7 @@ export function TorrentstreamSettings(props: TorrentstreamSettingsProps) {
                     includeInLibrary: settings.includeInLibrary,
                     streamUrlAddress: settings.streamUrlAddress || "",
                     slowSeeding: settings.slowSeeding,
+                    cacheMaxSize: settings.cacheMaxSize ?? 0,
                 }}
                 stackClass="space-y-4"
             >
@@ -212,6 +221,13 @@ export function TorrentstreamSettings(props: TorrentstreamSettingsProps) {
                                 help="Where the torrents will be downloaded to while streaming. Leave empty to use the default cache directory."
                                 shouldExist
                             />
+
+                            <Field.Number
+                                name="cacheMaxSize"
+                                label="Cache size (GB)"
+                                min={0}
+                                help="Keep streamed episodes in the cache directory up to this size. The least recently watched torrents are removed first. 0 disables the cache."
+                            />
                         </AccordionContent>
                     </AccordionItem>
                 </Accordion>
@@ -225,6 +241,51 @@ export function TorrentstreamSettings(props: TorrentstreamSettingsProps) {
                     </Button>
                 </div>
             </Form>
+
+            {!!settings.cacheMaxSize && <TorrentstreamCacheEntries />}
         </>
     )
 }
+
+function TorrentstreamCacheEntries() {
+    const { data: entries } = useGetTorrentstreamCacheEntries(true)
+    const { mutate: deleteEntry, isPending: isDeleting } = useDeleteTorrentstreamCacheEntry()
+    const { mutate: importEntry, isPending: isImporting } = useImportTorrentstreamCacheEntry()
+
+    return (
+        <SettingsCard title="Streaming cache">
+            {!entries?.length && <p className="text-sm text-[--muted]">No episodes in the cache.</p>}
+            {entries?.map(entry => (
+                <div key={entry.id} className="flex items-center justify-between gap-2 p-2 border rounded-[--radius-md]">
+                    <div className="min-w-0">
+                        <p className="font-medium truncate">{entry.filePath.split("/").pop()}</p>
+                        <p className="text-xs text-[--muted]">
+                            Episode {entry.episodeNumber} · {(entry.diskSize / 1024 / 1024 / 1024).toFixed(2)} GB{!entry.complete && " · Incomplete"}
+                        </p>
+                    </div>
+                    <div className="flex gap-1">
+                        <IconButton
+                            intent="gray-subtle"
+                            size="sm"
+                            icon={<LuFolderInput />}
+                            title="Move to library"
+                            disabled={!entry.complete || isImporting}
+                            onClick={() => importEntry({ id: entry.id, destination: "" })}
+                        />
+                        <IconButton
+                            intent="alert-subtle"
+                            size="sm"
+                            icon={<LuTrash />}
+                            title="Remove"
+                            disabled={isDeleting}
+                            onClick={() => deleteEntry({ id: entry.id })}
+                        />
+                    </div>
+                </div>
+            ))}
+            {!!entries?.length && <Button intent="alert-subtle" size="sm" disabled={isDeleting} onClick={() => deleteEntry({ id: 0 })}>
+                Clear cache
+            </Button>}
+        </SettingsCard>
+    )
+}
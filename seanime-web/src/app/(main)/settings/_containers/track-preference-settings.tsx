@@ -0,0 +1,187 @@
+import { Trackpreference_SubtitleType } from "@/api/generated/types"
+import { useDeleteTrackPreference, useGetTrackPreferences, useSaveTrackPreference } from "@/api/hooks/track_preferences.hooks"
+import { useCurrentUser } from "@/app/(main)/_hooks/use-server-status"
+import { SettingsCard } from "@/app/(main)/settings/_components/settings-card"
+import { SettingsIsDirty, SettingsSubmitButton } from "@/app/(main)/settings/_components/settings-submit-button"
+import { Button } from "@/components/ui/button"
+import { defineSchema, Field, Form } from "@/components/ui/form"
+import { LoadingSpinner } from "@/components/ui/loading-spinner"
+import { Select } from "@/components/ui/select"
+import React from "react"
+import { UseFormReturn } from "react-hook-form"
+
+const trackPreferenceSchema = defineSchema(({ z }) => z.object({
+    audioLanguages: z.string(),
+    subtitleLanguages: z.string(),
+    subtitleType: z.string(),
+    avoidForcedSubtitles: z.boolean(),
+    disableSubtitles: z.boolean(),
+}))
+
+const SUBTITLE_TYPE_OPTIONS = [
+    { label: "Any", value: "any" },
+    { label: "Full subtitles", value: "full" },
+    { label: "Signs & Songs", value: "signs" },
+]
+
+// Select value of the preferences that apply to every user
+const EVERYONE = "-"
+
+function splitLanguages(value: string) {
+    return value.split(",").map(l => l.trim()).filter(Boolean)
+}
+
+type TrackPreferenceSettingsProps = {
+    children?: React.ReactNode
+}
+
+export function TrackPreferenceSettings(props: TrackPreferenceSettingsProps) {
+
+    const {
+        children,
+        ...rest
+    } = props
+
+    const { data: preferences, isLoading } = useGetTrackPreferences()
+
+    const { mutate, isPending } = useSaveTrackPreference()
+
+    const { mutate: deletePreference, isPending: isDeleting } = useDeleteTrackPreference()
+
+    const user = useCurrentUser()
+    const currentUsername = (!user?.isSimulated && user?.viewer?.name) || ""
+
+    // Username of the preferences being edited, empty for every user
+    const [username, setUsername] = React.useState("")
+
+    const formRef = React.useRef<UseFormReturn<any>>(null)
+
+    const defaultPreference = preferences?.find(p => p.mediaId === 0 && p.username === username)
+    const mediaPreferences = preferences?.filter(p => p.mediaId !== 0) ?? []
+
+    if (isLoading) return <LoadingSpinner />
+
+    return (
+        <>
+            <Form
+                key={username}
+                schema={trackPreferenceSchema}
+                mRef={formRef}
+                onSubmit={data => {
+                    mutate({
+                            preference: {
+                                username: username,
+                                mediaId: 0,
+                                audioLanguages: splitLanguages(data.audioLanguages),
+                                subtitleLanguages: splitLanguages(data.subtitleLanguages),
+                                subtitleType: (data.subtitleType === "any" ? "" : data.subtitleType) as Trackpreference_SubtitleType,
+                                avoidForcedSubtitles: data.avoidForcedSubtitles,
+                                disableSubtitles: data.disableSubtitles,
+                            },
+                        },
+                        {
+                            onSuccess: () => {
+                                formRef.current?.reset(formRef.current.getValues())
+                            },
+                        },
+                    )
+                }}
+                defaultValues={{
+                    audioLanguages: defaultPreference?.audioLanguages?.join(", ") ?? "",
+                    subtitleLanguages: defaultPreference?.subtitleLanguages?.join(", ") ?? "",
+                    subtitleType: defaultPreference?.subtitleType || "any",
+                    avoidForcedSubtitles: defaultPreference?.avoidForcedSubtitles ?? false,
+                    disableSubtitles: defaultPreference?.disableSubtitles ?? false,
+                }}
+                stackClass="space-y-4"
+            >
+                {(f) => (
+                    <>
+                        <SettingsIsDirty />
+                        <SettingsCard
+                            title="Track preferences"
+                            description="Audio and subtitle tracks selected automatically by the built-in players, MPV and VLC."
+                        >
+                            {!!currentUsername && (
+                                <Select
+                                    label="Applies to"
+                                    value={username || EVERYONE}
+                                    onValueChange={v => setUsername(v === EVERYONE ? "" : v)}
+                                    options={[
+                                        { label: "Everyone", value: EVERYONE },
+                                        { label: `Only ${currentUsername}`, value: currentUsername },
+                                    ]}
+                                    help="Your preferences override the ones set for everyone."
+                                />
+                            )}
+
+                            <Field.Text
+                                name="audioLanguages"
+                                label="Audio languages"
+                                placeholder="jpn, eng"
+                                help="Language codes sorted by priority, separated by commas."
+                            />
+
+                            <Field.Text
+                                name="subtitleLanguages"
+                                label="Subtitle languages"
+                                placeholder="eng"
+                                help="Language codes sorted by priority, separated by commas."
+                            />
+
+                            <Field.Select
+                                name="subtitleType"
+                                label="Subtitle type"
+                                options={SUBTITLE_TYPE_OPTIONS}
+                                help="Signs & Songs tracks only translate on-screen text and songs, they are useful for dubs."
+                            />
+
+                            <Field.Switch
+                                side="right"
+                                name="avoidForcedSubtitles"
+                                label="Avoid forced subtitles"
+                                help="Forced tracks are only selected if there are no other tracks in the preferred language."
+                            />
+
+                            <Field.Switch
+                                side="right"
+                                name="disableSubtitles"
+                                label="Disable subtitles"
+                            />
+                        </SettingsCard>
+
+                        <SettingsSubmitButton isPending={isPending} />
+                    </>
+                )}
+            </Form>
+
+            {mediaPreferences.length > 0 && (
+                <SettingsCard title="Anime preferences" description="These preferences override the default preference.">
+                    <div className="space-y-2">
+                        {mediaPreferences.map(pref => (
+                            <div key={`${pref.username}-${pref.mediaId}`} className="flex items-center justify-between gap-2 text-sm">
+                                <p>
+                                    <span className="font-medium">{pref.mediaId}</span>
+                                    {!!pref.username && <span className="text-[--muted]"> ({pref.username})</span>}
+                                    <span className="text-[--muted]">
+                                        {" "}— Audio: {pref.audioLanguages?.join(", ") || "-"}, Subtitles: {pref.disableSubtitles
+                                        ? "disabled"
+                                        : pref.subtitleLanguages?.join(", ") || "-"}
+                                    </span>
+                                </p>
+                                <Button
+                                    intent="alert-subtle"
+                                    size="sm"
+                                    onClick={() => deletePreference({ username: pref.username, mediaId: pref.mediaId })}
+                                    disabled={isDeleting}
+                                >
+                                    Remove
+                                </Button>
+                            </div>
+                        ))}
+                    </div>
+                </SettingsCard>
+            )}
+        </>
+    )
+}
@@ -16,8 +16,10 @@ import { LibrarySettings } from "@/app/(main)/settings/_containers/library-setti
 import { LogsSettings } from "@/app/(main)/settings/_containers/logs-settings"
 import { MangaSettings } from "@/app/(main)/settings/_containers/manga-settings"
 import { MediastreamSettings } from "@/app/(main)/settings/_containers/mediastream-settings"
+import { OutboxSettings } from "@/app/(main)/settings/_containers/outbox-settings"
 import { ServerSettings } from "@/app/(main)/settings/_containers/server-settings"
 import { TorrentstreamSettings } from "@/app/(main)/settings/_containers/torrentstream-settings"
+import { TrackPreferenceSettings } from "@/app/(main)/settings/_containers/track-preference-settings"
 import { UISettings } from "@/app/(main)/settings/_containers/ui-settings"
 import { PageWrapper } from "@/components/shared/page-wrapper"
 import { SeaLink } from "@/components/shared/sea-link"
@@ -43,7 +45,7 @@ import { HiOutlineServerStack } from "react-icons/hi2"
 import { ImDownload } from "react-icons/im"
 import { IoLibrary, IoPlayBackCircleSharp } from "react-icons/io5"
 import { LuBookKey, LuExternalLink, LuLaptop, LuLibrary, LuPalette, LuWandSparkles } from "react-icons/lu"
-import { MdOutlineBroadcastOnHome, MdOutlineConnectWithoutContact, MdOutlineDownloading, MdOutlinePalette } from "react-icons/md"
+import { MdOutlineBroadcastOnHome, MdOutlineConnectWithoutContact, MdOutlineDownloading, MdOutlineOutbox, MdOutlinePalette } from "react-icons/md"
 import { RiFolderDownloadFill } from "react-icons/ri"
 import { SiBittorrent } from "react-icons/si"
 import { TbDatabaseExclamation } from "react-icons/tb"
@@ -257,7 +259,7 @@ export default function Page() {
                                 <TabsTrigger
                                     value="logs"
                                     className="group"
-                                ><LuBookKey className="text-lg mr-3 transition-transform duration-200" /> Logs & Cache</TabsTrigger>
+                                ><LuBookKey className="text-lg mr-3 transition-transform duration-200" /> Logs, Cache & Outbox</TabsTrigger>
                             </div>
                         </SettingsNavCard>
 
@@ -338,6 +340,13 @@ export default function Page() {
                                         iinaSocket: data.iinaSocket || "",
                                         iinaPath: data.iinaPath || "",
                                         iinaArgs: data.iinaArgs || "",
+                                        kodiPort: data.kodiPort || 8080,
+                                        kodiUsername: data.kodiUsername || "",
+                                        kodiPassword: data.kodiPassword || "",
+                                        upnpRendererLocation: data.upnpRendererLocation || "",
+                                        networkServerUrl: data.networkServerUrl || "",
+                                        dlnaServerEnabled: data.dlnaServerEnabled ?? false,
+                                        dlnaServerName: data.dlnaServerName || "",
                                     },
                                     torrent: {
                                         defaultTorrentClient: data.defaultTorrentClient,
@@ -398,6 +407,13 @@ export default function Page() {
                                 iinaSocket: status?.settings?.mediaPlayer?.iinaSocket,
                                 iinaPath: status?.settings?.mediaPlayer?.iinaPath,
                                 iinaArgs: status?.settings?.mediaPlayer?.iinaArgs,
+                                kodiPort: status?.settings?.mediaPlayer?.kodiPort || 8080,
+                                kodiUsername: status?.settings?.mediaPlayer?.kodiUsername,
+                                kodiPassword: status?.settings?.mediaPlayer?.kodiPassword,
+                                upnpRendererLocation: status?.settings?.mediaPlayer?.upnpRendererLocation,
+                                networkServerUrl: status?.settings?.mediaPlayer?.networkServerUrl,
+                                dlnaServerEnabled: status?.settings?.mediaPlayer?.dlnaServerEnabled ?? false,
+                                dlnaServerName: status?.settings?.mediaPlayer?.dlnaServerName,
                                 defaultTorrentClient: status?.settings?.torrent?.defaultTorrentClient || DEFAULT_TORRENT_CLIENT, // (Backwards
                                 // compatibility)
                                 hideTorrentList: status?.settings?.torrent?.hideTorrentList ?? false,
@@ -621,6 +637,7 @@ export default function Page() {
 
                                     <TabsContent value="playback" className={tabContentClass}>
                                         <PlaybackSettings />
+                                        <TrackPreferenceSettings />
                                     </TabsContent>
 
                                     <TabsContent value="torrent-client" className={tabContentClass}>
@@ -823,6 +840,16 @@ export default function Page() {
 
                             <FilecacheSettings />
 
+                            <Separator />
+
+                            <SettingsPageHeader
+                                title="Outbox"
+                                description="List changes waiting to be sent"
+                                icon={MdOutlineOutbox}
+                            />
+
+                            <OutboxSettings />
+
                         </TabsContent>
 
 
@@ -47,6 +47,13 @@ export const settingsSchema = z.object({
     iinaSocket: z.string().optional().default(""),
     iinaPath: z.string().optional().default(""),
     iinaArgs: z.string().optional().default(""),
+    kodiPort: z.number().optional().default(8080),
+    kodiUsername: z.string().optional().default(""),
+    kodiPassword: z.string().optional().default(""),
+    upnpRendererLocation: z.string().optional().default(""),
+    networkServerUrl: z.string().optional().default(""),
+    dlnaServerEnabled: z.boolean().optional().default(false),
+    dlnaServerName: z.string().optional().default(""),
     defaultTorrentClient: z.string().optional().default(DEFAULT_TORRENT_CLIENT),
     hideTorrentList: z.boolean().optional().default(false),
     qbittorrentPath: z.string().optional().default(""),
@@ -163,6 +170,13 @@ export const getDefaultSettings = (data: z.infer<typeof gettingStartedSchema>):
         iinaSocket: data.iinaSocket || "",
         iinaPath: data.iinaPath || "",
         iinaArgs: "",
+        kodiPort: 8080,
+        kodiUsername: "",
+        kodiPassword: "",
+        upnpRendererLocation: "",
+        networkServerUrl: "",
+        dlnaServerEnabled: false,
+        dlnaServerName: "",
     },
     discord: {
         enableRichPresence: data.enableRichPresence,
@@ -18,6 +18,8 @@ export const enum WSEvents {
     PLAYBACK_MANAGER_MANUAL_TRACKING_PLAYBACK_STATE = "playback-manager-manual-tracking-playback-state",
     EXTERNAL_PLAYER_OPEN_URL = "external-player-open-url",
     PLAYBACK_MANAGER_MANUAL_TRACKING_STOPPED = "playback-manager-manual-tracking-stopped",
+    PLAYBACK_QUEUE_STATE = "playback-queue-state",
+    PLAYBACK_QUEUE_PLAY_ONLINE_STREAM = "playback-queue-play-online-stream",
     ERROR_TOAST = "error-toast",
     SUCCESS_TOAST = "success-toast",
     INFO_TOAST = "info-toast",
@@ -34,6 +36,7 @@ export const enum WSEvents {
     SYNC_LOCAL_QUEUE_STATE = "sync-local-queue-state",
     SYNC_LOCAL_FINISHED = "sync-local-finished",
     SYNC_ANILIST_FINISHED = "sync-anilist-finished",
+    PROFILE_CHANGED = "profile-changed",
     TORRENTSTREAM_STATE = "torrentstream-state",
     DEBRID_DOWNLOAD_PROGRESS = "debrid-download-progress",
     DEBRID_STREAM_STATE = "debrid-stream-state",